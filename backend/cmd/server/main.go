@@ -18,6 +18,11 @@ import (
 //
 // @BasePath  /api
 //
+// @securityDefinitions.apikey  AdminAPIKey
+// @in                          header
+// @name                        X-API-Key
+// @description                 Required by the admin endpoints if ADMIN_API_KEY is set.
+//
 // @tag.name        Chats
 // @tag.description Endpoints for creating, retrieving, and managing conversations.
 //
@@ -30,5 +35,8 @@ import (
 func main() {
 	// The main package is a thin wrapper around the app package,
 	// making the core application logic importable and testable.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(app.RunMigrate(os.Args[2:]))
+	}
 	os.Exit(app.Run())
 }
@@ -22,26 +22,86 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/chats": {
+        "/v1/admin/backup": {
+            "post": {
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ],
+                "description": "Writes a consistent snapshot of the SQLite database to the backup directory (` + "`" + `BACKUP_DIR` + "`" + `) while the server keeps running, and deletes the oldest snapshots beyond ` + "`" + `BACKUP_KEEP` + "`" + `. The snapshot is a regular SQLite file that can replace the database to restore it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Back up the database",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Backup"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Another backup is running",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Backups are disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/backups": {
             "get": {
-                "description": "Retrieves a list of all chats, sorted by the most recently updated.",
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ],
+                "description": "Lists the snapshots in the backup directory, newest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chats"
+                    "Admin"
                 ],
-                "summary": "List all chats",
+                "summary": "List the database backups",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/flow-ai_backend_internal_model.Chat"
+                                "$ref": "#/definitions/flow-ai_backend_internal_service.Backup"
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -51,39 +111,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/chats/messages": {
+        "/v1/admin/maintenance": {
             "post": {
-                "description": "Sends a new message and initiates a real-time stream of the assistant's response.\nSends a new message and initiates a real-time stream of the assistant's response (SSE).",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
                 ],
+                "description": "Checkpoints the SQLite WAL file into the database and truncates it, then refreshes the query planner statistics. The WAL file otherwise keeps growing during long uptimes. A ` + "`" + `busy` + "`" + ` checkpoint could not complete because of concurrent requests and can simply be retried.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chats"
-                ],
-                "summary": "Create a message and stream the response",
-                "parameters": [
-                    {
-                        "description": "Message Request",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_service.CreateMessageRequest"
-                        }
-                    }
+                    "Admin"
                 ],
+                "summary": "Run database maintenance",
                 "responses": {
                     "200": {
-                        "description": "Stream of response chunks",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.MaintenanceResult"
                         }
                     },
-                    "400": {
-                        "description": "Sent as a stream error event",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
@@ -91,67 +148,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/chats/{chatID}": {
+        "/v1/admin/retention": {
             "get": {
-                "description": "Retrieves the full history for a single chat's active branch.",
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ],
+                "description": "Reports whether old chats are pruned in the background and the outcome of the last run. The policy itself is configured with the ` + "`" + `retention_days` + "`" + ` and ` + "`" + `retention_max_chats` + "`" + ` settings.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chats"
-                ],
-                "summary": "Get a single chat",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Chat ID",
-                        "name": "chatID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Admin"
                 ],
+                "summary": "Get the chat retention status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_model.FullChat"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.RetentionStatus"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Permanently deletes a chat and all its associated messages.",
+            }
+        },
+        "/v1/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ],
+                "description": "Returns the number of chats and messages over all chats, the active and inactive (regenerated or edited away) messages, and the messages of each model, for an admin dashboard.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chats"
-                ],
-                "summary": "Delete a chat",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Chat ID",
-                        "name": "chatID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Admin"
                 ],
+                "summary": "Count chats and messages",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.StatusResponse"
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.GlobalStats"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
@@ -165,69 +216,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/chats/{chatID}/messages/{messageID}/regenerate": {
-            "post": {
-                "description": "Creates a new response for a previous user prompt.\nCreates a new response for a previous user prompt (SSE).",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/chats": {
+            "get": {
+                "description": "Retrieves a list of chats. By default all chats are returned, sorted by the most recently updated. Pinned chats always come first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Chats"
                 ],
-                "summary": "Regenerate a message",
+                "summary": "List all chats",
                 "parameters": [
                     {
+                        "enum": [
+                            "created_at",
+                            "updated_at",
+                            "title"
+                        ],
                         "type": "string",
-                        "description": "Chat ID",
-                        "name": "chatID",
-                        "in": "path",
-                        "required": true
+                        "default": "updated_at",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
                     },
                     {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
                         "type": "string",
-                        "description": "The ID of the assistant message to regenerate",
-                        "name": "messageID",
-                        "in": "path",
-                        "required": true
+                        "default": "desc",
+                        "description": "Sort direction",
+                        "name": "order",
+                        "in": "query"
                     },
                     {
-                        "description": "Regeneration options",
-                        "name": "regenRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_service.RegenerateMessageRequest"
-                        }
+                        "type": "string",
+                        "description": "Only list chats that used this model",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only list chats with this tag",
+                        "name": "tag",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Stream of new response chunks",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_model.Chat"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Sent as a stream error event",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Sent as a stream error event",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/v1/chats/{chatID}/title": {
-            "put": {
-                "description": "Manually renames a chat.",
+            },
+            "post": {
+                "description": "Creates a chat without sending a message, so that its title, model and system prompt can be set before the first prompt.",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,30 +298,23 @@ const docTemplate = `{
                 "tags": [
                     "Chats"
                 ],
-                "summary": "Update a chat's title",
+                "summary": "Create an empty chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Chat ID",
-                        "name": "chatID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "New title",
-                        "name": "title",
+                        "description": "Chat options (all fields optional)",
+                        "name": "chat",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_api.UpdateTitleRequest"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CreateChatRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.StatusResponse"
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Chat"
                         }
                     },
                     "400": {
@@ -269,12 +323,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/internal_api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -284,21 +332,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/models": {
-            "get": {
-                "description": "Gets a list of all models available locally in Ollama.",
+        "/v1/chats/bulk-delete": {
+            "post": {
+                "description": "Permanently deletes the given chats and their messages in a single transaction. IDs of chats that don't exist are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Models"
+                    "Chats"
+                ],
+                "summary": "Delete several chats",
+                "parameters": [
+                    {
+                        "description": "Chat IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.DeleteChatsRequest"
+                        }
+                    }
                 ],
-                "summary": "List local models",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ListModelsResponse"
+                            "$ref": "#/definitions/internal_api.DeleteChatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     },
                     "500": {
@@ -308,9 +376,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a model from the local Ollama storage.",
+            }
+        },
+        "/v1/chats/estimate": {
+            "post": {
+                "description": "Returns an approximate token count for sending a message, including the system prompt and the chat's active history. The value is a heuristic, not an exact tokenizer count.",
                 "consumes": [
                     "application/json"
                 ],
@@ -318,17 +388,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Models"
+                    "Chats"
                 ],
-                "summary": "Delete a local model",
+                "summary": "Estimate prompt tokens",
                 "parameters": [
                     {
-                        "description": "Model Name to Delete",
-                        "name": "modelRequest",
+                        "description": "Message that would be sent",
+                        "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.DeleteModelRequest"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CreateMessageRequest"
                         }
                     }
                 ],
@@ -336,7 +406,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.StatusResponse"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.TokenEstimate"
                         }
                     },
                     "400": {
@@ -350,19 +420,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/internal_api.ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/v1/models/pull": {
+        "/v1/chats/messages": {
             "post": {
-                "description": "Downloads a model from the Ollama registry. This is a streaming endpoint.\nDownloads a model from the Ollama registry. This is a streaming endpoint (SSE).",
+                "description": "Sends a new message and initiates a real-time stream of the assistant's response.\nSends a new message and initiates a real-time stream of the assistant's response (SSE).",
                 "consumes": [
                     "application/json"
                 ],
@@ -370,25 +434,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Models"
+                    "Chats"
                 ],
-                "summary": "Pull a new model",
+                "summary": "Create a message and stream the response",
                 "parameters": [
                     {
-                        "description": "Model Name to Pull",
-                        "name": "modelRequest",
+                        "description": "Message Request",
+                        "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullModelRequest"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CreateMessageRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Repeats with the same key return the original answer instead of generating again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Stream of progress status",
+                        "description": "Stream of response chunks",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullStatus"
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
                         }
                     },
                     "400": {
@@ -400,27 +470,149 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/models/show": {
-            "post": {
-                "description": "Retrieves detailed information about a specific model.",
-                "consumes": [
+        "/v1/chats/{chatID}": {
+            "get": {
+                "description": "Retrieves a chat's metadata and the most recent page of its active branch. ` + "`" + `has_more` + "`" + ` indicates older messages, available from the messages endpoint.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Get a single chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.FullChat"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Permanently deletes a chat and all its associated messages.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Models"
+                    "Chats"
                 ],
-                "summary": "Show model info",
+                "summary": "Delete a chat",
                 "parameters": [
                     {
-                        "description": "Model Name",
-                        "name": "modelRequest",
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/clone": {
+            "post": {
+                "description": "Copies a chat and its active messages into a new, independent chat titled \"Copy of ...\". Inactive branches are not copied.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Clone a chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Chat"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/collection": {
+            "put": {
+                "description": "Enables retrieval from a document collection for the chat's messages. An empty collection ID disables it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Bind a chat to a document collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Collection ID",
+                        "name": "collection",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ShowModelRequest"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.UpdateChatCollectionRequest"
                         }
                     }
                 ],
@@ -428,7 +620,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ModelInfo"
+                            "$ref": "#/definitions/internal_api.StatusResponse"
                         }
                     },
                     "400": {
@@ -442,37 +634,72 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/settings": {
+        "/v1/chats/{chatID}/messages": {
             "get": {
-                "description": "Retrieves the current global settings for the application.",
+                "description": "Returns a page of the chat's active messages, newest first. Pass the ID of the oldest message received as ` + "`" + `before` + "`" + ` to get the next page.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Chats"
+                ],
+                "summary": "List a chat's messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return messages older than the message with this ID",
+                        "name": "before",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get application settings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.MessagePage"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/chats/{chatID}/messages/raw": {
             "post": {
-                "description": "Updates the global settings. Models must be available in Ollama.",
+                "description": "Appends a message with the given role to the chat's active branch without calling the LLM. Useful for importing transcripts or seeding few-shot examples.",
                 "consumes": [
                     "application/json"
                 ],
@@ -480,25 +707,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Chats"
                 ],
-                "summary": "Update application settings",
+                "summary": "Insert a message without generating a response",
                 "parameters": [
                     {
-                        "description": "New settings to apply",
-                        "name": "settings",
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message to insert",
+                        "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.AddMessageRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.StatusResponse"
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
                         }
                     },
                     "400": {
@@ -507,6 +741,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -515,318 +755,4058 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "flow-ai_backend_internal_llm.DeleteModelRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string",
-                    "example": "mistral:7b"
-                }
-            }
         },
-        "flow-ai_backend_internal_llm.ListModelsResponse": {
-            "type": "object",
-            "properties": {
-                "models": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/flow-ai_backend_internal_llm.Model"
+        "/v1/chats/{chatID}/messages/{messageID}": {
+            "get": {
+                "description": "Retrieves one message of a chat, e.g. to refetch an answer after it was regenerated. Inactive messages can be retrieved as well.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Get a single message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "The message does not exist or belongs to another chat",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "flow-ai_backend_internal_llm.Model": {
-            "type": "object",
-            "properties": {
-                "modified_at": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "size": {
-                    "type": "integer"
-                }
-            }
-        },
-        "flow-ai_backend_internal_llm.ModelInfo": {
+        "/v1/chats/{chatID}/messages/{messageID}/activate": {
+            "post": {
+                "description": "Sets a specific message and its branch as the active one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Switch active branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target Message ID to activate",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/messages/{messageID}/attachments/{attachmentID}": {
+            "get": {
+                "description": "Serves the binary content of an attachment (e.g. an image) of a message.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Download a message attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/messages/{messageID}/compare": {
+            "post": {
+                "description": "Answers a user message of the active branch with 2 to 4 models at once (SSE). The events of all models are interleaved and tagged with their ` + "`" + `model` + "`" + `; the first event of each model carries the ` + "`" + `message_id` + "`" + ` its answer will be stored with. Each complete answer is stored as a branch of the user message, and the answer of the first model becomes the active branch. A model that fails sends an error event without stopping the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Compare the answers of several models",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The ID of the user message to answer",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Models to compare",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CompareMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Interleaved stream of the answers",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/messages/{messageID}/continue": {
+            "post": {
+                "description": "Continues the latest assistant message of a chat where it stopped, e.g. because it reached ` + "`" + `num_predict` + "`" + ` (SSE). The new content is appended to the same message; only the new content is streamed. Any other message is rejected with a stream error event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Continue a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The ID of the assistant message to continue",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Continuation options",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ContinueMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of the new content",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/messages/{messageID}/regenerate": {
+            "post": {
+                "description": "Creates a new response for a previous user prompt (SSE). The first event carries the ` + "`" + `message_id` + "`" + ` of the new response and the ` + "`" + `replaced_message_id` + "`" + ` of the one it replaces.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Regenerate a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The ID of the assistant message to regenerate",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Regeneration options",
+                        "name": "regenRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.RegenerateMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of new response chunks",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.StreamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/pin": {
+            "put": {
+                "description": "Pinned chats are listed before all others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Pin or unpin a chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pin state",
+                        "name": "pin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.UpdateChatPinnedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/regenerate-title": {
+            "post": {
+                "description": "Generates a new title from the chat's first exchange using the support model and saves it. If the support model is not configured or not available, the existing title is returned with ` + "`" + `regenerated` + "`" + ` set to false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Regenerate a chat's title",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.RegenerateTitleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The chat has no answered message",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/tags": {
+            "put": {
+                "description": "Replaces the tags of a chat. Tags are 1-32 lowercase letters, digits, '-' or '_'; duplicates are removed. An empty list removes all tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Set the tags of a chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tags",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.UpdateChatTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The stored tags",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.UpdateChatTagsRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/title": {
+            "put": {
+                "description": "Manually renames a chat.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Update a chat's title",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New title",
+                        "name": "title",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.UpdateTitleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chats/{chatID}/tree": {
+            "get": {
+                "description": "Retrieves all messages for a chat, including inactive branches. By default the messages are a flat list ordered by time; with ` + "`" + `nested=true` + "`" + ` they are nested under ` + "`" + `roots` + "`" + ` by their parent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "Get full chat tree",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the messages as a tree",
+                        "name": "nested",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include messages created at or after this RFC 3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of tree levels to include (0 = all)",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.ChatTree"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/collections": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "List document collections",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_model.Collection"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates an empty collection. Documents uploaded to it are embedded with the collection's embedding model.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Create a document collection",
+                "parameters": [
+                    {
+                        "description": "Collection",
+                        "name": "collection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CreateCollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Collection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/collections/{collectionID}/documents": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "List documents of a collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "collectionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_model.Document"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Splits a plain-text document into chunks, embeds them and adds them to the collection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Upload a document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "collectionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Document",
+                        "name": "document",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.UploadDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Document"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/complete": {
+            "post": {
+                "description": "Generates the text between ` + "`" + `prompt` + "`" + ` and ` + "`" + `suffix` + "`" + ` (fill-in-middle), e.g. for code completion. Without a suffix the prompt is continued. A suffix needs a model that supports fill-in-middle and is sent to Ollama's /api/generate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Complete text",
+                "parameters": [
+                    {
+                        "description": "Model, prompt and suffix",
+                        "name": "completeRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.GenerateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/embeddings": {
+            "post": {
+                "description": "Computes an embedding vector for each text of ` + "`" + `input` + "`" + ` with an embedding model, e.g. for semantic search. The vectors are returned in the order of the input.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Compute embeddings",
+                "parameters": [
+                    {
+                        "description": "Model and texts",
+                        "name": "embedRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.EmbedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.EmbeddingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models": {
+            "get": {
+                "description": "Gets a list of all models available locally in Ollama, with their digest, details such as family, parameter size and quantization level, and capabilities such as ` + "`" + `vision` + "`" + `, ` + "`" + `tools` + "`" + ` or ` + "`" + `embedding` + "`" + `.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "List local models",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ListModelsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a model from the local Ollama storage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Delete a local model",
+                "parameters": [
+                    {
+                        "description": "Model Name to Delete",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.DeleteModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/aliases": {
+            "get": {
+                "description": "Lists the aliases, such as \"fast\" or \"smart\", that can be used instead of a model tag.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "List model aliases",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_service.ModelAlias"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/aliases/{name}": {
+            "put": {
+                "description": "Points an alias at a model tag or at another alias. Chains must end at a local model and must not loop. Requests, chats and settings may use the alias wherever a model is expected; a model tag is never taken for an alias.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Create or update a model alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alias name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target model; the name is taken from the path",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelAlias"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelAlias"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "An alias that is still used by the settings or by another alias cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Delete a model alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alias name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/blobs/{digest}": {
+            "post": {
+                "description": "Uploads the raw request body, such as a GGUF model, as a blob. The digest must be the SHA-256 of the content; a mismatch is a 400. Uploading an existing blob again is harmless.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Upload a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SHA-256 digest of the file, e.g. sha256:432f31...",
+                        "name": "digest",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Reports whether a file, such as a GGUF model, has been uploaded as a blob: 200 if it exists, 404 if it does not. A model can be created from uploaded files with the ` + "`" + `files` + "`" + ` of ` + "`" + `/models/create` + "`" + `.",
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Check an uploaded file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SHA-256 digest of the file, e.g. sha256:432f31...",
+                        "name": "digest",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/copy": {
+            "post": {
+                "description": "Copies a local model under a new name, e.g. to keep a snapshot before editing its Modelfile.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Copy a local model",
+                "parameters": [
+                    {
+                        "description": "Source and destination model names",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.CopyModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/create": {
+            "post": {
+                "description": "Creates a model from a Modelfile, from an existing model with its own system prompt and parameters, or from uploaded ` + "`" + `files` + "`" + ` such as a GGUF file. This is a streaming endpoint (SSE); an invalid Modelfile or a file that was not uploaded is reported as a status with ` + "`" + `error` + "`" + ` set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Create a custom model",
+                "parameters": [
+                    {
+                        "description": "Model to create",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.CreateModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of progress status",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/pull": {
+            "post": {
+                "description": "Downloads a model from the Ollama registry. This is a streaming endpoint.\nDownloads a model from the Ollama registry. This is a streaming endpoint (SSE).\nThe download runs in the background: disconnecting only stops the stream, and pulling the same model again reattaches to it, unless PULL_CANCEL_ON_DISCONNECT is set.\nThe last event is a ` + "`" + `PullSummary` + "`" + ` with ` + "`" + `done` + "`" + ` set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Pull a new model",
+                "parameters": [
+                    {
+                        "description": "Model Name to Pull",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of progress status",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Sent as a stream error event",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/pull-batch": {
+            "post": {
+                "description": "Pulls a list of models one after another, e.g. to set up a new machine. This is a streaming endpoint (SSE).\nProgress events are tagged with their ` + "`" + `model` + "`" + `. When a model is finished, its ` + "`" + `PullSummary` + "`" + ` is sent; a failed model does not stop the batch. The last event is a ` + "`" + `PullBatchSummary` + "`" + ` with the results of all models.\nDisconnecting stops the batch; a model that is being pulled finishes in the background like a single pull.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Pull several models",
+                "parameters": [
+                    {
+                        "description": "Names of the models to pull",
+                        "name": "batchRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.PullBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of progress status",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.BatchPullStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/pull/cancel": {
+            "post": {
+                "description": "Aborts the in-flight download of a model. Every stream of that pull ends with a ` + "`" + `cancelled` + "`" + ` status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Cancel a model pull",
+                "parameters": [
+                    {
+                        "description": "Name of the model being pulled",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.CancelPullRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The model is not being pulled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/pull/status": {
+            "get": {
+                "description": "Lists the models that are currently being pulled with their latest progress. A client can attach to a listed pull by pulling the same model again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "List in-flight model pulls",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_service.PullJobStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/running": {
+            "get": {
+                "description": "Gets the models Ollama currently holds in memory, with their memory and VRAM usage and when they will be unloaded. A model that is not listed is loaded on its next use, which makes that request slower. If the main model is preloaded, the state of its preload is included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "List running models",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.RunningModels"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/search": {
+            "get": {
+                "description": "Searches the public Ollama library for models to pull. Each result lists the tags it is published in; the name to pull is \"\u003cname\u003e:\u003ctag\u003e\". Results are cached for a few minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Search the model library",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term; empty lists the most popular models",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_llm.RegistryModel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "The library could not be reached",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/show": {
+            "post": {
+                "description": "Retrieves detailed information about a specific model.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Show model info",
+                "parameters": [
+                    {
+                        "description": "Model Name",
+                        "name": "modelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ShowModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.ModelInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/storage": {
+            "get": {
+                "description": "Sums the size of all local models and lists them largest first. If OLLAMA_MODELS_PATH is configured, the free space on the models' volume is included, to help decide what to delete when pulls fail for lack of space.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Model disk usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelStorage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/updates": {
+            "get": {
+                "description": "Gets the result of the last check of the installed models for newer versions in the registry. The models are checked in the background once a day (MODEL_UPDATE_CHECK_INTERVAL). A model that could not be checked, e.g. one created locally, carries an ` + "`" + `error` + "`" + `.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "List model updates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelUpdates"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/{name}/benchmark": {
+            "post": {
+                "description": "Runs a fixed short prompt through a local model several times and returns the medians of the generation speed, the load time and the time to the first token. The body is optional. Only one benchmark runs at a time; a second request waits for the first one. Closing the connection cancels the benchmark. The result is stored, and the model list shows the speed of the last benchmark.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Benchmark a model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name, URL-encoded (e.g. qwen3%3A14b)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Benchmark options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.BenchmarkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.ModelBenchmark"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The model is not available locally",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/{name}/defaults": {
+            "get": {
+                "description": "Returns the generation options applied to every request for the model. A model without defaults has empty options.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Get the default options of a model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name, URL-encoded (e.g. qwen3%3A14b)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelDefaults"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the generation options applied to every request for the model, e.g. its temperature or context size. Options of a request take precedence over the model's defaults, which take precedence over the global ` + "`" + `default_options` + "`" + ` setting. An empty object removes all defaults.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Set the default options of a model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name, URL-encoded (e.g. qwen3%3A14b)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Default options",
+                        "name": "options",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.ModelDefaults"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The model is not available locally",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/{name}/update": {
+            "post": {
+                "description": "Pulls the latest version of an installed model. This is a streaming endpoint (SSE) that behaves like a pull of the model, including the summary as the last event.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Update a model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name, e.g. qwen3:8b (URL-encoded)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of progress status",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.PullStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/options/schema": {
+            "get": {
+                "description": "Describes the generation options accepted in ` + "`" + `options` + "`" + `, the model defaults and the ` + "`" + `default_options` + "`" + ` setting: their type, bounds, allowed values, Ollama's default and a description, so that a client can render an input for each of them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Models"
+                ],
+                "summary": "Generation option schema",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_llm.OptionSchema"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/prompts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "List prompt templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/flow-ai_backend_internal_model.Prompt"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores a reusable prompt. The body may contain ` + "`" + `{{variable}}` + "`" + ` placeholders (Go text/template syntax), which are filled from the ` + "`" + `variables` + "`" + ` of a message that uses the prompt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Create a prompt template",
+                "parameters": [
+                    {
+                        "description": "Prompt",
+                        "name": "prompt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.SavePromptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Prompt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/prompts/{promptID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Get a prompt template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prompt ID",
+                        "name": "promptID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Prompt"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Replace a prompt template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prompt ID",
+                        "name": "promptID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Prompt",
+                        "name": "prompt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.SavePromptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_model.Prompt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Delete a prompt template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prompt ID",
+                        "name": "promptID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/settings": {
+            "get": {
+                "description": "Retrieves the current global settings for the application.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get application settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Updates the global settings. Models must be available in Ollama.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update application settings",
+                "parameters": [
+                    {
+                        "description": "New settings to apply",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes only the settings given in the body, e.g. just the system prompt, and returns the updated settings. The result is validated like a full update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update application settings partially",
+                "parameters": [
+                    {
+                        "description": "The settings to change",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.SettingsPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/settings/refresh": {
+            "post": {
+                "description": "Runs the model discovery that otherwise happens on the next read of the settings: an empty main model is set to the most recently modified model and an empty support model to the main model. The result is saved and returned, e.g. right after the first model was pulled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Discover models for the settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Settings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/system/health": {
+            "get": {
+                "description": "Pings the database and the LLM provider, each with a short timeout, and reports the state and latency of each. Unlike ` + "`" + `/healthz` + "`" + `, which only tells that the process is up, it returns 503 with ` + "`" + `status` + "`" + ` \"degraded\" if a dependency is down.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Dependency health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Health"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.Health"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/system/ollama": {
+            "get": {
+                "description": "Reports the configured Ollama URL, whether Ollama is reachable, its version and the latency of a version request. An unreachable Ollama is reported with ` + "`" + `connected` + "`" + ` set to false and the ` + "`" + `error` + "`" + `, not as a failed request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Ollama connection status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.OllamaStatus"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "flow-ai_backend_internal_llm.CopyModelRequest": {
+            "type": "object",
+            "required": [
+                "destination",
+                "source"
+            ],
+            "properties": {
+                "destination": {
+                    "type": "string",
+                    "example": "qwen3:8b-backup"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.CreateModelRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "files": {
+                    "description": "Files maps file names to the digests of uploaded blobs, e.g. to import a\nGGUF file as {\"model.gguf\": \"sha256:...\"}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "description": "From names the model the new model is based on.",
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "modelfile": {
+                    "description": "Modelfile is the complete Modelfile of the new model.",
+                    "type": "string",
+                    "example": "FROM qwen3:8b\nSYSTEM You are a senior database administrator."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "sql-expert"
+                },
+                "parameters": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "quantize": {
+                    "type": "string",
+                    "example": "q4_K_M"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "system": {
+                    "type": "string",
+                    "example": "You are a senior database administrator."
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.DeleteModelRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "mistral:7b"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.EmbeddingsResponse": {
+            "type": "object",
+            "properties": {
+                "embeddings": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float32"
+                        }
+                    }
+                },
+                "model": {
+                    "type": "string"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.GenerateResponse": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "string"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.ListModelsResponse": {
+            "type": "object",
+            "properties": {
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_llm.Model"
+                    }
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.Model": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "description": "Capabilities lists the features of the model, such as \"completion\",\n\"vision\", \"tools\" or \"embedding\". Ollama does not list them with the models;\nthey are filled in by the model service.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "completion",
+                        "vision"
+                    ]
+                },
+                "details": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_llm.ModelDetails"
+                },
+                "digest": {
+                    "description": "Digest identifies the exact model version, e.g. to detect an updated tag.",
+                    "type": "string",
+                    "example": "500a1f067a9f782620b40bee6f7b0c89e17ae61f686b92c24933e4ca4b2b8b41"
+                },
+                "modified_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00.123456789+02:00"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "provider": {
+                    "description": "Provider is the provider that serves the model, e.g. \"ollama\" or \"openai\".\nIt is filled in by the provider registry.",
+                    "type": "string",
+                    "example": "ollama"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 5225388164
+                },
+                "tokens_per_second": {
+                    "description": "TokensPerSecond is the generation speed measured by the last benchmark of\nthe model, if it was benchmarked. It is filled in by the model service.",
+                    "type": "number",
+                    "example": 42.5
+                },
+                "update_available": {
+                    "description": "UpdateAvailable reports that the last update check found a newer version\nof the model in the registry.",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.ModelDetails": {
+            "type": "object",
+            "properties": {
+                "families": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "qwen3"
+                    ]
+                },
+                "family": {
+                    "type": "string",
+                    "example": "qwen3"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "gguf"
+                },
+                "parameter_size": {
+                    "type": "string",
+                    "example": "8.2B"
+                },
+                "quantization_level": {
+                    "type": "string",
+                    "example": "Q4_K_M"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.ModelInfo": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "description": "Capabilities is only reported by newer versions of Ollama.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "completion",
+                        "vision"
+                    ]
+                },
+                "details": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_llm.ModelDetails"
+                },
+                "modelfile": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.OptionSchema": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default is the value Ollama uses if the option is not set, if it has a fixed one.",
+                    "type": "string",
+                    "example": "0.8"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Randomness of the answer; higher values are more creative."
+                },
+                "enum": {
+                    "description": "Enum lists the allowed values of an option that only takes a few.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "type": "number",
+                    "example": 2
+                },
+                "min": {
+                    "description": "Min and Max are the inclusive bounds of a numeric option; a missing bound\nmeans there is none.",
+                    "type": "number",
+                    "example": 0
+                },
+                "name": {
+                    "description": "Name is the key of the option in ` + "`" + `options` + "`" + `.",
+                    "type": "string",
+                    "example": "temperature"
+                },
+                "type": {
+                    "description": "Type is \"number\", \"integer\", \"string\" or \"array\" (of strings).",
+                    "type": "string",
+                    "example": "number"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.PullModelRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "mistral:7b"
+                },
+                "stream": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.PullStatus": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "digest": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.RegistryModel": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "description": "Capabilities lists features such as \"tools\", \"vision\" or \"thinking\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tools"
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Llama 3.1 is a new state-of-the-art model from Meta."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "llama3.1"
+                },
+                "pulls": {
+                    "description": "Pulls is the approximate number of downloads, e.g. 93500000 for \"93.5M\".",
+                    "type": "integer",
+                    "example": 93500000
+                },
+                "tags": {
+                    "description": "Tags are the sizes the model is published in; the name to pull is \"\u003cname\u003e:\u003ctag\u003e\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "8b",
+                        "70b",
+                        "405b"
+                    ]
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.RequestOptions": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "description": "Format constrains the output; \"json\" makes the model answer with valid JSON.\nLike KeepAlive, it is sent as a top-level field of the Ollama request.",
+                    "type": "string",
+                    "enum": [
+                        "json"
+                    ],
+                    "example": "json"
+                },
+                "keep_alive": {
+                    "description": "KeepAlive overrides the ` + "`" + `keep_alive` + "`" + ` setting for this request. It is sent\nas a top-level field of the Ollama request, not as a model option.",
+                    "type": "string",
+                    "example": "5m"
+                },
+                "min_p": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.05
+                },
+                "mirostat": {
+                    "description": "Mirostat enables Mirostat sampling: 0 = off, 1 = Mirostat, 2 = Mirostat 2.0.",
+                    "type": "integer",
+                    "enum": [
+                        0,
+                        1,
+                        2
+                    ],
+                    "example": 0
+                },
+                "mirostat_eta": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.1
+                },
+                "mirostat_tau": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5
+                },
+                "num_ctx": {
+                    "description": "NumCtx is the size of the context window in tokens. It is capped at 1M\ntokens, beyond what any current model supports, so that a typo cannot make\nOllama try to allocate the memory for it.",
+                    "type": "integer",
+                    "maximum": 1048576,
+                    "minimum": 1,
+                    "example": 4096
+                },
+                "num_gpu": {
+                    "description": "NumGPU is the number of layers offloaded to the GPU; -1 lets Ollama decide.",
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": -1
+                },
+                "num_predict": {
+                    "description": "NumPredict caps the number of generated tokens; -1 means no limit and\n-2 fills the context.",
+                    "type": "integer",
+                    "minimum": -2,
+                    "example": 512
+                },
+                "repeat_last_n": {
+                    "description": "RepeatLastN is how far back the model looks to prevent repetition; -1 means num_ctx.",
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 64
+                },
+                "repeat_penalty": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1.1
+                },
+                "seed": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "stop": {
+                    "description": "Stop lists sequences at which the model stops generating.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "###"
+                    ]
+                },
+                "system": {
+                    "type": "string",
+                    "example": "You are a senior database administrator."
+                },
+                "temperature": {
+                    "type": "number",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 0.7
+                },
+                "top_k": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 40
+                },
+                "top_p": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.9
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.RunningModel": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt is when the model will be unloaded unless it is used again.",
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 6654289920
+                },
+                "size_vram": {
+                    "type": "integer",
+                    "example": 6654289920
+                }
+            }
+        },
+        "flow-ai_backend_internal_llm.ShowModelRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Attachment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9f8e7d6c-5b4a-3210-fedc-ba9876543210"
+                },
+                "mime_type": {
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 48213
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Chat": {
+            "type": "object",
+            "properties": {
+                "collection_id": {
+                    "description": "CollectionID, if set, enables retrieval from the given document collection.",
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen:0.5b"
+                },
+                "pinned": {
+                    "description": "Pinned chats are listed before all others.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "system_prompt": {
+                    "description": "SystemPrompt overrides the global system prompt for this chat when set.",
+                    "type": "string",
+                    "example": "You are a senior Go developer."
+                },
+                "tags": {
+                    "description": "Tags group chats by topic, sorted alphabetically. They are filled in chat listings.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "golang"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "History of the Roman Empire"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.ChatTree": {
+            "type": "object",
+            "properties": {
+                "collection_id": {
+                    "description": "CollectionID, if set, enables retrieval from the given document collection.",
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
+                    }
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen:0.5b"
+                },
+                "pinned": {
+                    "description": "Pinned chats are listed before all others.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "roots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.MessageNode"
+                    }
+                },
+                "system_prompt": {
+                    "description": "SystemPrompt overrides the global system prompt for this chat when set.",
+                    "type": "string",
+                    "example": "You are a senior Go developer."
+                },
+                "tags": {
+                    "description": "Tags group chats by topic, sorted alphabetically. They are filled in chat listings.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "golang"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "History of the Roman Empire"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.CheckpointResult": {
+            "type": "object",
+            "properties": {
+                "busy": {
+                    "description": "Busy is true if the checkpoint could not complete because of concurrent\nreaders or writers; it is retried on the next run.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "checkpointed_frames": {
+                    "description": "CheckpointedFrames is the number of frames written back to the database.",
+                    "type": "integer",
+                    "example": 1532
+                },
+                "log_frames": {
+                    "description": "LogFrames is the number of frames in the WAL file before the checkpoint.",
+                    "type": "integer",
+                    "example": 1532
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Collection": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "embedding_model": {
+                    "description": "EmbeddingModel is the model used for all chunks of the collection; vectors\nfrom different models are not comparable.",
+                    "type": "string",
+                    "example": "nomic-embed-text"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Project docs"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Document": {
+            "type": "object",
+            "properties": {
+                "chunk_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "collection_id": {
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5e4d3c2b-1a0f-9e8d-7c6b-5a4f3e2d1c0b"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "README.md"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.FullChat": {
+            "type": "object",
+            "properties": {
+                "collection_id": {
+                    "description": "CollectionID, if set, enables retrieval from the given document collection.",
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "has_more": {
+                    "description": "HasMore is true if older messages exist; fetch them with ` + "`" + `GET /chats/{chatID}/messages` + "`" + `.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
+                    }
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen:0.5b"
+                },
+                "pinned": {
+                    "description": "Pinned chats are listed before all others.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "system_prompt": {
+                    "description": "SystemPrompt overrides the global system prompt for this chat when set.",
+                    "type": "string",
+                    "example": "You are a senior Go developer."
+                },
+                "tags": {
+                    "description": "Tags group chats by topic, sorted alphabetically. They are filled in chat listings.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "golang"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "History of the Roman Empire"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.GlobalStats": {
+            "type": "object",
+            "properties": {
+                "active_messages": {
+                    "description": "ActiveMessages are on the current branch of their chat; InactiveMessages\nare on branches that were regenerated or edited away.",
+                    "type": "integer",
+                    "example": 1100
+                },
+                "chats": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "inactive_messages": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "messages": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "messages_by_model": {
+                    "description": "MessagesByModel counts the messages of each model, the most used first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.ModelMessageCount"
+                    }
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Message": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "description": "Attachments are references to binary files (e.g. images) sent with the message.\nTheir content is served by a separate endpoint.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.Attachment"
+                    }
+                },
+                "content": {
+                    "type": "string",
+                    "example": "The Roman Empire fell in 476 AD."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen:0.5b"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "f0e9d8c7-b6a5-4321-fedc-ba9876543210"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "assistant"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.MessageNode": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "description": "Attachments are references to binary files (e.g. images) sent with the message.\nTheir content is served by a separate endpoint.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.Attachment"
+                    }
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.MessageNode"
+                    }
+                },
+                "content": {
+                    "type": "string",
+                    "example": "The Roman Empire fell in 476 AD."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen:0.5b"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "f0e9d8c7-b6a5-4321-fedc-ba9876543210"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "assistant"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.MessagePage": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "description": "HasMore is true if messages older than the last one of this page exist.",
+                    "type": "boolean"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
+                    }
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.ModelBenchmark": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                },
+                "first_token_ms": {
+                    "type": "number",
+                    "example": 85.1
+                },
+                "load_ms": {
+                    "type": "number",
+                    "example": 12.3
+                },
+                "model": {
+                    "type": "string",
+                    "example": "gemma3:4b"
+                },
+                "runs": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "tokens_per_second": {
+                    "type": "number",
+                    "example": 42.5
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.ModelMessageCount": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.Prompt": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Review the following {{language}} code:"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Reviews a diff for bugs and style issues."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c6b5a4f-3e2d-1c0b-9a8f-7e6d5c4b3a29"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Code review"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                },
+                "variables": {
+                    "description": "Variables lists the placeholders of the body. It is derived, not stored.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "language"
+                    ]
+                }
+            }
+        },
+        "flow-ai_backend_internal_model.StreamResponse": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "Hello"
+                },
+                "done": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "MessageID and ReplacedMessageID are set in the first event of a\nregeneration: the ID the new answer will be stored with, and the ID of the\nanswer it replaces, which stays available as an inactive branch.",
+                    "type": "string",
+                    "example": "8f14e45f-ceea-467a-9b36-8a2f1c6d5e7b"
+                },
+                "model": {
+                    "description": "Model tags the events of a comparison with the model whose answer they\nbelong to, as the answers of several models are interleaved.",
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "phase": {
+                    "description": "Phase is set by an event that reports the progress of an answer:\n\"queued\" while it waits for other generations to finish, \"loading\" while\nthe model loads and reads the prompt, and \"generating\" once the first\ntoken has arrived.",
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "loading",
+                        "generating"
+                    ],
+                    "example": "loading"
+                },
+                "reasoning": {
+                    "description": "Reasoning carries the model's \u003cthink\u003e output, if the client asked to see it.",
+                    "type": "string",
+                    "example": "The user greets me, so I greet back."
+                },
+                "replaced_message_id": {
+                    "type": "string",
+                    "example": "1f0e3dad-9990-4c45-8f2b-4a3f5c6d7e8f"
+                },
+                "warning": {
+                    "description": "Warning reports a problem with a completed answer that did not stop the\nstream, e.g. invalid JSON in JSON mode.",
+                    "type": "string"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.AddMessageRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "role"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Paris is the capital of France."
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "assistant",
+                        "system"
+                    ],
+                    "example": "assistant"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.Backup": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "flow-20250908-140000.000.db"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/data/backups/flow-20250908-140000.000.db"
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.BenchmarkRequest": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "description": "Runs is the number of generations; the result is the median over all of\nthem. Defaults to 3.",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1,
+                    "example": 3
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CancelPullRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "llama3:70b"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CompareMessageRequest": {
+            "type": "object",
+            "required": [
+                "models"
+            ],
+            "properties": {
+                "models": {
+                    "type": "array",
+                    "maxItems": 4,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "qwen3:8b",
+                        "gemma3:4b"
+                    ]
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CompleteRequest": {
+            "type": "object",
+            "required": [
+                "model",
+                "prompt"
+            ],
+            "properties": {
+                "model": {
+                    "type": "string",
+                    "example": "qwen2.5-coder:7b"
+                },
+                "options": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                },
+                "prompt": {
+                    "type": "string",
+                    "example": "def fib(n):"
+                },
+                "suffix": {
+                    "description": "Suffix is the text after the completion; empty completes the prompt.",
+                    "type": "string",
+                    "example": "    return fib(n - 1) + fib(n - 2)"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ContinueMessageRequest": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "description": "Included for client-side context.",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "Allows overriding generation parameters, e.g. a larger ` + "`" + `num_predict` + "`" + `.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                        }
+                    ]
+                },
+                "show_reasoning": {
+                    "description": "ShowReasoning overrides the ` + "`" + `show_reasoning` + "`" + ` setting for this request.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CreateChatRequest": {
+            "type": "object",
+            "properties": {
+                "collection_id": {
+                    "description": "CollectionID enables retrieval from a document collection for this chat.",
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "system_prompt": {
+                    "type": "string",
+                    "example": "You are a meticulous code reviewer."
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Code review session"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CreateCollectionRequest": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "modelfile": {
-                    "type": "string"
+                "embedding_model": {
+                    "type": "string",
+                    "example": "nomic-embed-text"
                 },
-                "parameters": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Project docs"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.CreateMessageRequest": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "What is the difference between SQL and NoSQL databases?"
+                },
+                "dry_run": {
+                    "description": "DryRun streams the answer without persisting anything: neither the chat of\na new conversation, nor the messages, nor a generated title. It is meant\nfor previewing a prompt. The idempotency key is ignored.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "images": {
+                    "description": "Images are base64-encoded images for vision models (e.g. llava).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "options": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                },
+                "prompt_id": {
+                    "description": "PromptID names a prompt template that is rendered with ` + "`" + `Variables` + "`" + ` to form\nthe message. Any ` + "`" + `Content` + "`" + ` is appended to the rendered prompt.",
+                    "type": "string",
+                    "example": "7c6b5a4f-3e2d-1c0b-9a8f-7e6d5c4b3a29"
+                },
+                "response_format": {
+                    "description": "ResponseFormat is \"json\" to make the model answer with valid JSON. It takes\nprecedence over ` + "`" + `options.format` + "`" + `.",
+                    "type": "string",
+                    "enum": [
+                        "json"
+                    ],
+                    "example": "json"
+                },
+                "show_reasoning": {
+                    "description": "ShowReasoning overrides the ` + "`" + `show_reasoning` + "`" + ` setting for this request.",
+                    "type": "boolean"
+                },
+                "support_model": {
                     "type": "string"
                 },
-                "template": {
+                "system_prompt": {
                     "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "flow-ai_backend_internal_llm.PullModelRequest": {
+        "flow-ai_backend_internal_service.DeleteChatsRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "3f2b8c1e-5d4a-4b6f-9e7d-1a2b3c4d5e6f"
+                    ]
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.DependencyHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is why the check failed.",
+                    "type": "string",
+                    "example": "LLM backend unreachable: dial tcp 127.0.0.1:11434: connect: connection refused"
+                },
+                "healthy": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "latency_ms": {
+                    "description": "LatencyMs is the time the check took in milliseconds.",
+                    "type": "number",
+                    "example": 2.4
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.EmbedRequest": {
+            "type": "object",
+            "required": [
+                "input",
+                "model"
+            ],
+            "properties": {
+                "input": {
+                    "description": "Input is a batch of texts, each of which gets its own vector.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Why is the sky blue?"
+                    ]
+                },
+                "model": {
+                    "type": "string",
+                    "example": "nomic-embed-text"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.Health": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_service.DependencyHealth"
+                },
+                "llm": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_service.DependencyHealth"
+                },
+                "status": {
+                    "description": "Status is \"ok\" if all dependencies are healthy and \"degraded\" otherwise.",
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "degraded"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.MaintenanceResult": {
+            "type": "object",
+            "properties": {
+                "checkpoint": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_model.CheckpointResult"
+                },
+                "duration": {
+                    "description": "Duration is the time the run took, e.g. \"12.5ms\".",
+                    "type": "string",
+                    "example": "12.5ms"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ModelAlias": {
             "type": "object",
+            "required": [
+                "model"
+            ],
             "properties": {
+                "model": {
+                    "description": "Model is the model tag, or another alias, the name resolves to.",
+                    "type": "string",
+                    "example": "qwen3:14b"
+                },
                 "name": {
                     "type": "string",
-                    "example": "mistral:7b"
+                    "example": "smart"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ModelDefaults": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:14b"
                 },
-                "stream": {
-                    "type": "boolean"
+                "options": {
+                    "description": "Options is empty if no defaults are stored for the model.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
                 }
             }
         },
-        "flow-ai_backend_internal_llm.PullStatus": {
+        "flow-ai_backend_internal_service.ModelStorage": {
             "type": "object",
             "properties": {
-                "completed": {
-                    "type": "integer"
+                "free_bytes": {
+                    "description": "FreeBytes is the free space on the volume of the models. It is omitted if\nOLLAMA_MODELS_PATH is not configured or the space cannot be determined.",
+                    "type": "integer",
+                    "example": 52613349376
                 },
-                "digest": {
-                    "type": "string"
+                "models": {
+                    "description": "Models lists the local models, largest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_service.ModelStorageEntry"
+                    }
+                },
+                "total_bytes": {
+                    "description": "TotalBytes is the sum of the model sizes. Models that share layers are\ncounted in full, so the actual usage on disk can be lower.",
+                    "type": "integer",
+                    "example": 14753165312
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ModelStorageEntry": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "qwen3:14b"
                 },
+                "size": {
+                    "type": "integer",
+                    "example": 9276198565
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ModelUpdate": {
+            "type": "object",
+            "properties": {
                 "error": {
+                    "description": "Error is why the model could not be checked, e.g. because it was created\nlocally and is not in the registry.",
                     "type": "string"
                 },
+                "local_digest": {
+                    "description": "LocalDigest is the digest of the installed version when it was checked.",
+                    "type": "string",
+                    "example": "500a1f067a9f782620b40bee6f7b0c89e17ae61f686b92c24933e4ca4b2b8b41"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "remote_digest": {
+                    "description": "RemoteDigest is the digest of the version published in the registry.",
+                    "type": "string",
+                    "example": "2bada8a7450677000f678be90653b85d364de7db25eb5ea54136ada5f3933730"
+                },
+                "update_available": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.ModelUpdates": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "description": "CheckedAt is omitted before the first check.",
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_service.ModelUpdate"
+                    }
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.OllamaStatus": {
+            "type": "object",
+            "properties": {
+                "connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error": {
+                    "description": "Error is why Ollama could not be reached.",
+                    "type": "string",
+                    "example": "dial tcp: lookup ollama: no such host"
+                },
+                "latency_ms": {
+                    "description": "LatencyMs is the round trip time of the version request in milliseconds.",
+                    "type": "number",
+                    "example": 1.8
+                },
+                "url": {
+                    "description": "URL is the configured address of Ollama, without any credentials.",
+                    "type": "string",
+                    "example": "http://ollama:11434"
+                },
+                "version": {
+                    "description": "Version is the version of the Ollama server; it is empty if Ollama is unreachable.",
+                    "type": "string",
+                    "example": "0.6.2"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.PreloadStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "model requires more system memory"
+                },
+                "finished_at": {
+                    "description": "FinishedAt is unset while the model is loading.",
+                    "type": "string",
+                    "example": "2025-09-08T14:05:12Z"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "qwen3:8b"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:05:00Z"
+                },
+                "state": {
+                    "description": "State is \"loading\", \"loaded\" or \"failed\".",
+                    "type": "string",
+                    "example": "loaded"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.PullJobStatus": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "name": {
+                    "type": "string",
+                    "example": "llama3:70b"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
+                },
                 "status": {
-                    "type": "string"
+                    "description": "Status, Total and Completed are taken from the latest progress update; they\nare empty until Ollama reported the first one.",
+                    "type": "string",
+                    "example": "pulling 6a0746a1ec1a"
+                },
+                "subscribers": {
+                    "description": "Subscribers is the number of clients currently streaming the pull's progress.",
+                    "type": "integer",
+                    "example": 1
                 },
                 "total": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 39969745184
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.RegenerateMessageRequest": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "description": "Included for client-side context.",
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "mistral:7b"
+                },
+                "options": {
+                    "description": "Allows overriding generation parameters, e.g., for a more creative response.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                        }
+                    ]
+                },
+                "show_reasoning": {
+                    "description": "ShowReasoning overrides the ` + "`" + `show_reasoning` + "`" + ` setting for this request.",
+                    "type": "boolean"
+                },
+                "system_prompt": {
+                    "type": "string"
                 }
             }
         },
-        "flow-ai_backend_internal_llm.RequestOptions": {
+        "flow-ai_backend_internal_service.RegenerateTitleResponse": {
             "type": "object",
             "properties": {
-                "repeat_penalty": {
-                    "type": "number",
-                    "example": 1.1
+                "regenerated": {
+                    "description": "Regenerated is false if the support model could not produce a title, in\nwhich case Title is the chat's existing title.",
+                    "type": "boolean",
+                    "example": true
                 },
-                "seed": {
+                "title": {
+                    "type": "string",
+                    "example": "History of the Roman Empire"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.RetentionStatus": {
+            "type": "object",
+            "properties": {
+                "deleted_chats": {
+                    "description": "DeletedChats is the number of chats deleted by the last run.",
                     "type": "integer",
-                    "example": 42
+                    "example": 12
                 },
-                "system": {
-                    "type": "string",
-                    "example": "You are a senior database administrator."
+                "enabled": {
+                    "description": "Enabled reports whether the background janitor is running.",
+                    "type": "boolean",
+                    "example": true
                 },
-                "temperature": {
-                    "type": "number",
-                    "example": 0.7
+                "interval": {
+                    "description": "Interval is the time between two runs, e.g. \"1h0m0s\".",
+                    "type": "string",
+                    "example": "1h0m0s"
                 },
-                "top_k": {
-                    "type": "integer",
-                    "example": 40
+                "last_error": {
+                    "description": "LastError is the error that ended the last run early, if any.",
+                    "type": "string"
                 },
-                "top_p": {
-                    "type": "number",
-                    "example": 0.9
+                "last_run_at": {
+                    "description": "LastRunAt is the start of the last run; it is omitted before the first run.",
+                    "type": "string",
+                    "example": "2025-09-08T14:00:00Z"
                 }
             }
         },
-        "flow-ai_backend_internal_llm.ShowModelRequest": {
+        "flow-ai_backend_internal_service.RunningModels": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string",
-                    "example": "qwen3:8b"
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/flow-ai_backend_internal_llm.RunningModel"
+                    }
+                },
+                "preload": {
+                    "description": "Preload is unset if the main model was not preloaded.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/flow-ai_backend_internal_service.PreloadStatus"
+                        }
+                    ]
                 }
             }
         },
-        "flow-ai_backend_internal_model.Chat": {
+        "flow-ai_backend_internal_service.SavePromptRequest": {
             "type": "object",
+            "required": [
+                "body",
+                "name"
+            ],
             "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2025-09-08T14:00:00Z"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
-                },
-                "model": {
+                "body": {
                     "type": "string",
-                    "example": "qwen:0.5b"
+                    "example": "Review the following {{language}} code:"
                 },
-                "title": {
+                "description": {
                     "type": "string",
-                    "example": "History of the Roman Empire"
+                    "maxLength": 500,
+                    "example": "Reviews a diff for bugs and style issues."
                 },
-                "updated_at": {
+                "name": {
                     "type": "string",
-                    "example": "2025-09-08T14:05:00Z"
+                    "maxLength": 100,
+                    "example": "Code review"
                 }
             }
         },
-        "flow-ai_backend_internal_model.FullChat": {
+        "flow-ai_backend_internal_service.Settings": {
             "type": "object",
+            "required": [
+                "main_model"
+            ],
             "properties": {
-                "created_at": {
+                "default_options": {
+                    "description": "DefaultOptions are the generation options of every request, e.g. a lower\ntemperature. The defaults of a model and the options of a request take precedence.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
+                        }
+                    ]
+                },
+                "enable_prompt_templates": {
+                    "description": "EnablePromptTemplates renders system prompts as templates, so that they can\nuse variables such as {{.Date}}, {{.Time}}, {{.UserID}} and {{.Model}}.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "keep_alive": {
+                    "description": "KeepAlive is how long Ollama keeps a model loaded after a request, e.g. \"5m\",\n\"0\" or \"-1\"; empty uses Ollama's default.",
                     "type": "string",
-                    "example": "2025-09-08T14:00:00Z"
+                    "example": "5m"
                 },
-                "id": {
+                "main_model": {
+                    "description": "The primary model for new chats. Must be an available local model.",
                     "type": "string",
-                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
+                    "example": "qwen3:8b"
                 },
-                "messages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/flow-ai_backend_internal_model.Message"
-                    }
+                "preload_main_model": {
+                    "description": "PreloadMainModel loads the main model into memory at startup and whenever\nthe main model changes, so that the first chat does not wait for it to load.",
+                    "type": "boolean",
+                    "example": false
                 },
-                "model": {
+                "retention_days": {
+                    "description": "RetentionDays deletes unpinned chats not updated for this many days; 0 keeps them forever.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 90
+                },
+                "retention_max_chats": {
+                    "description": "RetentionMaxChats deletes the oldest unpinned chats beyond this count; 0 means no limit.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 500
+                },
+                "show_reasoning": {
+                    "description": "ShowReasoning streams the \u003cthink\u003e reasoning of models that emit it to the client.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "support_model": {
+                    "description": "A model for background tasks like title generation. Can be the same as the main model.",
                     "type": "string",
-                    "example": "qwen:0.5b"
+                    "example": "gemma3:4b"
                 },
-                "title": {
+                "system_prompt": {
                     "type": "string",
-                    "example": "History of the Roman Empire"
+                    "example": "You are a helpful assistant that always answers in Markdown format."
                 },
-                "updated_at": {
+                "title_prompt_template": {
+                    "description": "TitlePromptTemplate is the text/template of the prompt that asks the support\nmodel for a chat title, with the first exchange as {{.User}} and\n{{.Assistant}}, e.g. to get titles in another language. Empty uses the\nbuilt-in English prompt.",
                     "type": "string",
-                    "example": "2025-09-08T14:05:00Z"
+                    "example": "Give this conversation a title of at most five German words. Answer with JSON: {\"title\": \"...\"}\n\nUser: {{.User}}\nAssistant: {{.Assistant}}"
                 }
             }
         },
-        "flow-ai_backend_internal_model.Message": {
+        "flow-ai_backend_internal_service.SettingsPatch": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "The Roman Empire fell in 476 AD."
+                "default_options": {
+                    "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
                 },
-                "id": {
-                    "type": "string",
-                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                "enable_prompt_templates": {
+                    "type": "boolean"
                 },
-                "metadata": {
-                    "type": "object"
+                "keep_alive": {
+                    "type": "string"
                 },
-                "model": {
+                "main_model": {
                     "type": "string",
-                    "example": "qwen:0.5b"
+                    "example": "qwen3:8b"
                 },
-                "parent_id": {
-                    "type": "string",
-                    "example": "f0e9d8c7-b6a5-4321-fedc-ba9876543210"
+                "preload_main_model": {
+                    "type": "boolean"
                 },
-                "role": {
-                    "type": "string",
-                    "example": "assistant"
+                "retention_days": {
+                    "type": "integer"
                 },
-                "timestamp": {
+                "retention_max_chats": {
+                    "type": "integer"
+                },
+                "show_reasoning": {
+                    "type": "boolean"
+                },
+                "support_model": {
                     "type": "string",
-                    "example": "2025-09-08T14:05:00Z"
+                    "example": "gemma3:4b"
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "title_prompt_template": {
+                    "type": "string"
                 }
             }
         },
-        "flow-ai_backend_internal_model.StreamResponse": {
+        "flow-ai_backend_internal_service.TokenEstimate": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "Hello"
+                "estimated_tokens": {
+                    "description": "EstimatedTokens is a tokenizer-agnostic approximation; see llm.EstimateTokens.",
+                    "type": "integer",
+                    "example": 512
                 },
-                "context": {
-                    "type": "object"
+                "last_prompt_eval_count": {
+                    "description": "LastPromptEvalCount is the real prompt token count Ollama reported for the\nlatest assistant reply in the chat, if known. It can be used to calibrate the estimate.",
+                    "type": "integer",
+                    "example": 480
                 },
-                "done": {
+                "message_count": {
+                    "description": "MessageCount is the number of messages (including the system prompt) that were counted.",
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.UpdateChatCollectionRequest": {
+            "type": "object",
+            "properties": {
+                "collection_id": {
+                    "type": "string",
+                    "example": "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.UpdateChatPinnedRequest": {
+            "type": "object",
+            "properties": {
+                "pinned": {
                     "type": "boolean",
-                    "example": false
-                },
-                "error": {
-                    "type": "string"
+                    "example": true
                 }
             }
         },
-        "flow-ai_backend_internal_service.CreateMessageRequest": {
+        "flow-ai_backend_internal_service.UpdateChatTagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "golang"
+                    ]
+                }
+            }
+        },
+        "flow-ai_backend_internal_service.UploadDocumentRequest": {
             "type": "object",
             "required": [
-                "content"
+                "content",
+                "name"
             ],
             "properties": {
-                "chat_id": {
-                    "type": "string",
-                    "example": "4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"
-                },
                 "content": {
                     "type": "string",
-                    "minLength": 1,
-                    "example": "What is the difference between SQL and NoSQL databases?"
+                    "example": "Flow AI is a self-hosted chat UI for Ollama."
                 },
-                "model": {
+                "name": {
                     "type": "string",
-                    "example": "qwen3:8b"
-                },
-                "options": {
-                    "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
-                },
-                "support_model": {
-                    "type": "string"
-                },
-                "system_prompt": {
-                    "type": "string"
+                    "maxLength": 255,
+                    "example": "README.md"
                 }
             }
         },
-        "flow-ai_backend_internal_service.RegenerateMessageRequest": {
+        "internal_api.BatchPullStatus": {
             "type": "object",
             "properties": {
-                "chat_id": {
-                    "description": "Included for client-side context.",
+                "completed": {
+                    "type": "integer"
+                },
+                "digest": {
+                    "type": "string"
+                },
+                "error": {
                     "type": "string"
                 },
                 "model": {
                     "type": "string",
-                    "example": "mistral:7b"
-                },
-                "options": {
-                    "description": "Allows overriding generation parameters, e.g., for a more creative response.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/flow-ai_backend_internal_llm.RequestOptions"
-                        }
-                    ]
+                    "example": "qwen3:8b"
                 },
-                "system_prompt": {
+                "status": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "flow-ai_backend_internal_service.Settings": {
+        "internal_api.DeleteChatsResponse": {
             "type": "object",
-            "required": [
-                "main_model"
-            ],
             "properties": {
-                "main_model": {
-                    "description": "The primary model for new chats. Must be an available local model.",
-                    "type": "string",
-                    "example": "qwen3:8b"
-                },
-                "support_model": {
-                    "description": "A model for background tasks like title generation. Can be the same as the main model.",
-                    "type": "string",
-                    "example": "gemma3:4b"
-                },
-                "system_prompt": {
-                    "type": "string",
-                    "example": "You are a helpful assistant that always answers in Markdown format."
+                "deleted": {
+                    "description": "Deleted is the number of chats that existed and were deleted.",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
         "internal_api.ErrorResponse": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
                 "error": {
                     "type": "string"
                 }
             }
         },
+        "internal_api.PullBatchRequest": {
+            "type": "object",
+            "required": [
+                "names"
+            ],
+            "properties": {
+                "names": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "qwen3:8b",
+                        "nomic-embed-text"
+                    ]
+                }
+            }
+        },
         "internal_api.StatusResponse": {
             "type": "object",
             "properties": {
@@ -850,20 +4830,14 @@ const docTemplate = `{
             }
         }
     },
-    "tags": [
-        {
-            "description": "Endpoints for creating, retrieving, and managing conversations.",
-            "name": "Chats"
-        },
-        {
-            "description": "Endpoints for listing, downloading, and managing local Ollama models.",
-            "name": "Models"
-        },
-        {
-            "description": "Endpoints for managing global application settings.",
-            "name": "Settings"
+    "securityDefinitions": {
+        "AdminAPIKey": {
+            "description": "Required by the admin endpoints if ADMIN_API_KEY is set.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
-    ]
+    }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
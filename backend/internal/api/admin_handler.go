@@ -0,0 +1,124 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/interfaces"
+	_ "flow-ai/backend/internal/service" // Resolves the types of the swag annotations.
+)
+
+// AdminHandlerConfig holds the static configuration of the AdminHandler.
+type AdminHandlerConfig struct {
+	// APIKey protects the admin endpoints; empty leaves them open, as the rest of
+	// the single-user API.
+	APIKey string
+}
+
+// AdminHandler handles HTTP requests for maintenance tasks of the instance.
+type AdminHandler struct {
+	retention   interfaces.RetentionService
+	maintenance interfaces.MaintenanceService
+	backup      interfaces.BackupService
+	cfg         AdminHandlerConfig
+}
+
+// NewAdminHandler creates a new instance of AdminHandler.
+func NewAdminHandler(retention interfaces.RetentionService, maintenance interfaces.MaintenanceService, backup interfaces.BackupService, cfg AdminHandlerConfig) *AdminHandler {
+	return &AdminHandler{retention: retention, maintenance: maintenance, backup: backup, cfg: cfg}
+}
+
+// RequireAPIKey is a middleware that rejects requests without the configured admin
+// API key, sent either as `Authorization: Bearer <key>` or as `X-API-Key: <key>`.
+func (h *AdminHandler) RequireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.APIKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(h.cfg.APIKey)) != 1 {
+			respondWithError(w, fmt.Errorf("%w: missing or invalid admin API key", app_errors.ErrPermission))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetRetentionStatus godoc
+// @Summary      Get the chat retention status
+// @Description  Reports whether old chats are pruned in the background and the outcome of the last run. The policy itself is configured with the `retention_days` and `retention_max_chats` settings.
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminAPIKey
+// @Success      200  {object}  service.RetentionStatus
+// @Failure      403  {object}  ErrorResponse
+// @Router       /v1/admin/retention [get]
+func (h *AdminHandler) GetRetentionStatus(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.retention.Status())
+}
+
+// HandleMaintenance godoc
+// @Summary      Run database maintenance
+// @Description  Checkpoints the SQLite WAL file into the database and truncates it, then refreshes the query planner statistics. The WAL file otherwise keeps growing during long uptimes. A `busy` checkpoint could not complete because of concurrent requests and can simply be retried.
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminAPIKey
+// @Success      200  {object}  service.MaintenanceResult
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/admin/maintenance [post]
+func (h *AdminHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	result, err := h.maintenance.Run(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// HandleBackup godoc
+// @Summary      Back up the database
+// @Description  Writes a consistent snapshot of the SQLite database to the backup directory (`BACKUP_DIR`) while the server keeps running, and deletes the oldest snapshots beyond `BACKUP_KEEP`. The snapshot is a regular SQLite file that can replace the database to restore it.
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminAPIKey
+// @Success      201  {object}  service.Backup
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse  "Another backup is running"
+// @Failure      501  {object}  ErrorResponse  "Backups are disabled"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/admin/backup [post]
+func (h *AdminHandler) HandleBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.backup.Backup(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, backup)
+}
+
+// ListBackups godoc
+// @Summary      List the database backups
+// @Description  Lists the snapshots in the backup directory, newest first.
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminAPIKey
+// @Success      200  {array}   service.Backup
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/admin/backups [get]
+func (h *AdminHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.backup.ListBackups(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, backups)
+}
@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/api"
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/interfaces/mocks"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
+)
+
+// TestAdminHandler_GetRetentionStatus tests the GET /v1/admin/retention endpoint.
+func TestAdminHandler_GetRetentionStatus(t *testing.T) {
+	mockSvc := mocks.NewMockRetentionService(t)
+	lastRun := time.Date(2025, 9, 8, 14, 0, 0, 0, time.UTC)
+	mockSvc.On("Status").Return(service.RetentionStatus{Enabled: true, Interval: "1h0m0s", LastRunAt: &lastRun, DeletedChats: 3}).Once()
+	handler := api.NewAdminHandler(mockSvc, mocks.NewMockMaintenanceService(t), mocks.NewMockBackupService(t), api.AdminHandlerConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/retention", nil)
+	rr := httptest.NewRecorder()
+	handler.GetRetentionStatus(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "2025-09-08T14:00:00Z", resp["last_run_at"])
+	assert.EqualValues(t, 3, resp["deleted_chats"])
+	assert.Equal(t, true, resp["enabled"])
+}
+
+// TestAdminHandler_HandleMaintenance tests the POST /v1/admin/maintenance endpoint.
+func TestAdminHandler_HandleMaintenance(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSvc := mocks.NewMockMaintenanceService(t)
+		mockSvc.On("Run", mock.Anything).Return(&service.MaintenanceResult{
+			Checkpoint: model.CheckpointResult{LogFrames: 120, CheckpointedFrames: 120},
+			Duration:   "3ms",
+		}, nil).Once()
+		handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mockSvc, mocks.NewMockBackupService(t), api.AdminHandlerConfig{})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/maintenance", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleMaintenance(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"checkpoint": {"busy": false, "log_frames": 120, "checkpointed_frames": 120}, "duration": "3ms"}`, rr.Body.String())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockSvc := mocks.NewMockMaintenanceService(t)
+		mockSvc.On("Run", mock.Anything).Return(nil, errors.New("database is locked")).Once()
+		handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mockSvc, mocks.NewMockBackupService(t), api.AdminHandlerConfig{})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/maintenance", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleMaintenance(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+// TestAdminHandler_HandleBackup tests the POST /v1/admin/backup endpoint.
+func TestAdminHandler_HandleBackup(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSvc := mocks.NewMockBackupService(t)
+		mockSvc.On("Backup", mock.Anything).Return(&service.Backup{
+			Name:      "flow-20250908-140000.000.db",
+			Path:      "/data/backups/flow-20250908-140000.000.db",
+			SizeBytes: 4096,
+			CreatedAt: time.Date(2025, 9, 8, 14, 0, 0, 0, time.UTC),
+		}, nil).Once()
+		handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mocks.NewMockMaintenanceService(t), mockSvc, api.AdminHandlerConfig{})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleBackup(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"name": "flow-20250908-140000.000.db", "path": "/data/backups/flow-20250908-140000.000.db", "size_bytes": 4096, "created_at": "2025-09-08T14:00:00Z"}`, rr.Body.String())
+	})
+
+	t.Run("Another backup is running", func(t *testing.T) {
+		mockSvc := mocks.NewMockBackupService(t)
+		mockSvc.On("Backup", mock.Anything).Return(nil, fmt.Errorf("%w: a backup is already running", app_errors.ErrConflict)).Once()
+		handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mocks.NewMockMaintenanceService(t), mockSvc, api.AdminHandlerConfig{})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleBackup(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+// TestAdminHandler_ListBackups tests the GET /v1/admin/backups endpoint.
+func TestAdminHandler_ListBackups(t *testing.T) {
+	mockSvc := mocks.NewMockBackupService(t)
+	mockSvc.On("ListBackups", mock.Anything).Return([]service.Backup{}, nil).Once()
+	handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mocks.NewMockMaintenanceService(t), mockSvc, api.AdminHandlerConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/backups", nil)
+	rr := httptest.NewRecorder()
+	handler.ListBackups(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
+
+// TestAdminHandler_RequireAPIKey verifies that admin endpoints require the
+// configured API key, and stay open if none is configured.
+func TestAdminHandler_RequireAPIKey(t *testing.T) {
+	testCases := []struct {
+		name         string
+		apiKey       string
+		headers      map[string]string
+		expectedCode int
+	}{
+		{name: "No key configured", expectedCode: http.StatusOK},
+		{name: "Missing key", apiKey: "secret", expectedCode: http.StatusForbidden},
+		{name: "Wrong key", apiKey: "secret", headers: map[string]string{"X-API-Key": "guess"}, expectedCode: http.StatusForbidden},
+		{name: "X-API-Key header", apiKey: "secret", headers: map[string]string{"X-API-Key": "secret"}, expectedCode: http.StatusOK},
+		{name: "Bearer token", apiKey: "secret", headers: map[string]string{"Authorization": "Bearer secret"}, expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := api.NewAdminHandler(mocks.NewMockRetentionService(t), mocks.NewMockMaintenanceService(t), mocks.NewMockBackupService(t), api.AdminHandlerConfig{APIKey: tc.apiKey})
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/maintenance", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			handler.RequireAPIKey(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			if tc.expectedCode == http.StatusForbidden {
+				assertErrorCode(t, rr, api.ErrorCodePermission)
+			}
+		})
+	}
+}
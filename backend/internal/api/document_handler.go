@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+
+	"flow-ai/backend/internal/interfaces"
+	_ "flow-ai/backend/internal/model" // Resolves the types of the swag annotations.
+	"flow-ai/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DocumentHandler handles HTTP requests for document collections used for
+// retrieval-augmented generation.
+type DocumentHandler struct {
+	service interfaces.DocumentService
+}
+
+// NewDocumentHandler creates a new instance of DocumentHandler.
+func NewDocumentHandler(svc interfaces.DocumentService) *DocumentHandler {
+	return &DocumentHandler{service: svc}
+}
+
+// HandleCreateCollection godoc
+// @Summary      Create a document collection
+// @Description  Creates an empty collection. Documents uploaded to it are embedded with the collection's embedding model.
+// @Tags         Documents
+// @Accept       json
+// @Produce      json
+// @Param        collection  body      service.CreateCollectionRequest  true  "Collection"
+// @Success      201         {object}  model.Collection
+// @Failure      400         {object}  ErrorResponse
+// @Failure      500         {object}  ErrorResponse
+// @Router       /v1/collections [post]
+func (h *DocumentHandler) HandleCreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateCollectionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	collection, err := h.service.CreateCollection(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, collection)
+}
+
+// HandleListCollections godoc
+// @Summary      List document collections
+// @Tags         Documents
+// @Produce      json
+// @Success      200  {array}   model.Collection
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/collections [get]
+func (h *DocumentHandler) HandleListCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.service.ListCollections(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, collections)
+}
+
+// HandleUploadDocument godoc
+// @Summary      Upload a document
+// @Description  Splits a plain-text document into chunks, embeds them and adds them to the collection.
+// @Tags         Documents
+// @Accept       json
+// @Produce      json
+// @Param        collectionID  path      string                         true  "Collection ID"
+// @Param        document      body      service.UploadDocumentRequest  true  "Document"
+// @Success      201           {object}  model.Document
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /v1/collections/{collectionID}/documents [post]
+func (h *DocumentHandler) HandleUploadDocument(w http.ResponseWriter, r *http.Request) {
+	collectionID := chi.URLParam(r, "collectionID")
+	var req service.UploadDocumentRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	document, err := h.service.UploadDocument(r.Context(), collectionID, &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, document)
+}
+
+// HandleListDocuments godoc
+// @Summary      List documents of a collection
+// @Tags         Documents
+// @Produce      json
+// @Param        collectionID  path      string  true  "Collection ID"
+// @Success      200           {array}   model.Document
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /v1/collections/{collectionID}/documents [get]
+func (h *DocumentHandler) HandleListDocuments(w http.ResponseWriter, r *http.Request) {
+	collectionID := chi.URLParam(r, "collectionID")
+	documents, err := h.service.ListDocuments(r.Context(), collectionID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, documents)
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"flow-ai/backend/internal/api"
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/interfaces/mocks"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
+)
+
+func setupDocumentHandler(t *testing.T) (*api.DocumentHandler, *mocks.MockDocumentService) {
+	mockDocSvc := mocks.NewMockDocumentService(t)
+	return api.NewDocumentHandler(mockDocSvc), mockDocSvc
+}
+
+// TestDocumentHandler_HandleCreateCollection tests the POST /v1/collections endpoint.
+func TestDocumentHandler_HandleCreateCollection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupDocumentHandler(t)
+		mockSvc.On("CreateCollection", mock.Anything, &service.CreateCollectionRequest{Name: "Docs"}).
+			Return(&model.Collection{ID: "col1", Name: "Docs"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/collections", strings.NewReader(`{"name": "Docs"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateCollection(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var resp model.Collection
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "col1", resp.ID)
+	})
+
+	t.Run("Failure - Missing name", func(t *testing.T) {
+		handler, mockSvc := setupDocumentHandler(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/collections", strings.NewReader(`{}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateCollection(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+		mockSvc.AssertNotCalled(t, "CreateCollection", mock.Anything, mock.Anything)
+	})
+}
+
+// TestDocumentHandler_HandleUploadDocument tests the POST /v1/collections/{collectionID}/documents endpoint.
+func TestDocumentHandler_HandleUploadDocument(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupDocumentHandler(t)
+		mockSvc.On("UploadDocument", mock.Anything, "col1", &service.UploadDocumentRequest{Name: "a.txt", Content: "text"}).
+			Return(&model.Document{ID: "doc1", CollectionID: "col1", ChunkCount: 1}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/collections/col1/documents", strings.NewReader(`{"name": "a.txt", "content": "text"}`))
+		req = addChiURLParams(req, map[string]string{"collectionID": "col1"})
+		rr := httptest.NewRecorder()
+		handler.HandleUploadDocument(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Failure - Collection not found", func(t *testing.T) {
+		handler, mockSvc := setupDocumentHandler(t)
+		mockSvc.On("UploadDocument", mock.Anything, "missing", mock.Anything).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/collections/missing/documents", strings.NewReader(`{"name": "a.txt", "content": "text"}`))
+		req = addChiURLParams(req, map[string]string{"collectionID": "missing"})
+		rr := httptest.NewRecorder()
+		handler.HandleUploadDocument(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestDocumentHandler_HandleListDocuments tests the GET /v1/collections/{collectionID}/documents endpoint.
+func TestDocumentHandler_HandleListDocuments(t *testing.T) {
+	handler, mockSvc := setupDocumentHandler(t)
+	mockSvc.On("ListDocuments", mock.Anything, "col1").Return([]*model.Document{{ID: "doc1"}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/collections/col1/documents", nil)
+	req = addChiURLParams(req, map[string]string{"collectionID": "col1"})
+	rr := httptest.NewRecorder()
+	handler.HandleListDocuments(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "doc1")
+}
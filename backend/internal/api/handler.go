@@ -1,9 +1,12 @@
 package api
 
 import (
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/interfaces"
@@ -18,13 +21,28 @@ import (
 type ChatHandler struct {
 	chatService     interfaces.ChatService
 	settingsService interfaces.SettingsService
+	cfg             ChatHandlerConfig
 }
 
+// ChatHandlerConfig holds the transport-level options of the ChatHandler.
+type ChatHandlerConfig struct {
+	// HeartbeatInterval is the longest silence on an SSE stream before a keep-alive
+	// comment is sent. Zero selects the default; a negative value disables heartbeats.
+	HeartbeatInterval time.Duration
+}
+
+// defaultHeartbeatInterval stays below the 30-60s idle timeouts common in proxies.
+const defaultHeartbeatInterval = 15 * time.Second
+
 // NewChatHandler creates a new instance of ChatHandler with its required service dependencies.
-func NewChatHandler(chatSvc interfaces.ChatService, settingsSvc interfaces.SettingsService) *ChatHandler {
+func NewChatHandler(chatSvc interfaces.ChatService, settingsSvc interfaces.SettingsService, cfg ChatHandlerConfig) *ChatHandler {
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = defaultHeartbeatInterval
+	}
 	return &ChatHandler{
 		chatService:     chatSvc,
 		settingsService: settingsSvc,
+		cfg:             cfg,
 	}
 }
 
@@ -60,9 +78,9 @@ func (h *ChatHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
 // @Router       /v1/settings [post]
 func (h *ChatHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 	var newSettings service.Settings
-	if err := json.NewDecoder(r.Body).Decode(&newSettings); err != nil {
+	if err := decodeJSON(r, &newSettings); err != nil {
 		// If JSON decoding fails, it's a client-side malformed request.
-		respondWithError(w, app_errors.ErrValidation)
+		respondWithError(w, err)
 		return
 	}
 
@@ -81,19 +99,156 @@ func (h *ChatHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
 }
 
+// RefreshSettings godoc
+// @Summary      Discover models for the settings
+// @Description  Runs the model discovery that otherwise happens on the next read of the settings: an empty main model is set to the most recently modified model and an empty support model to the main model. The result is saved and returned, e.g. right after the first model was pulled.
+// @Tags         Settings
+// @Produce      json
+// @Success      200  {object}  service.Settings
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/settings/refresh [post]
+func (h *ChatHandler) RefreshSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.settingsService.Refresh(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// PatchSettings godoc
+// @Summary      Update application settings partially
+// @Description  Changes only the settings given in the body, e.g. just the system prompt, and returns the updated settings. The result is validated like a full update.
+// @Tags         Settings
+// @Accept       json
+// @Produce      json
+// @Param        settings  body      service.SettingsPatch  true  "The settings to change"
+// @Success      200       {object}  service.Settings
+// @Failure      400       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /v1/settings [patch]
+func (h *ChatHandler) PatchSettings(w http.ResponseWriter, r *http.Request) {
+	var patch service.SettingsPatch
+	if err := decodeJSON(r, &patch); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	settings, err := h.settingsService.Get(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	patch.Apply(settings)
+
+	// The merged settings are validated as a whole, so that a patch cannot
+	// e.g. clear the main model.
+	if err := validateRequest(settings); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := h.settingsService.Save(r.Context(), settings); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	slog.Info("Application settings updated", "main_model", settings.MainModel, "support_model", settings.SupportModel)
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// HandleListModelAliases godoc
+// @Summary      List model aliases
+// @Description  Lists the aliases, such as "fast" or "smart", that can be used instead of a model tag.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {array}   service.ModelAlias
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/models/aliases [get]
+func (h *ChatHandler) HandleListModelAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.settingsService.ListModelAliases(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, aliases)
+}
+
+// HandleSetModelAlias godoc
+// @Summary      Create or update a model alias
+// @Description  Points an alias at a model tag or at another alias. Chains must end at a local model and must not loop. Requests, chats and settings may use the alias wherever a model is expected; a model tag is never taken for an alias.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        name   path      string              true  "Alias name"
+// @Param        alias  body      service.ModelAlias  true  "Target model; the name is taken from the path"
+// @Success      200    {object}  service.ModelAlias
+// @Failure      400    {object}  ErrorResponse
+// @Failure      500    {object}  ErrorResponse
+// @Router       /v1/models/aliases/{name} [put]
+func (h *ChatHandler) HandleSetModelAlias(w http.ResponseWriter, r *http.Request) {
+	var req service.ModelAlias
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	req.Name = chi.URLParam(r, "name")
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := h.settingsService.SetModelAlias(r.Context(), &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	slog.Info("Model alias set", "alias", req.Name, "model", req.Model)
+	respondWithJSON(w, http.StatusOK, req)
+}
+
+// HandleDeleteModelAlias godoc
+// @Summary      Delete a model alias
+// @Description  An alias that is still used by the settings or by another alias cannot be deleted.
+// @Tags         Models
+// @Produce      json
+// @Param        name  path      string  true  "Alias name"
+// @Success      200   {object}  StatusResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      409   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /v1/models/aliases/{name} [delete]
+func (h *ChatHandler) HandleDeleteModelAlias(w http.ResponseWriter, r *http.Request) {
+	if err := h.settingsService.DeleteModelAlias(r.Context(), chi.URLParam(r, "name")); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
+
 // GetChats godoc
 // @Summary      List all chats
-// @Description  Retrieves a list of all chats, sorted by the most recently updated.
+// @Description  Retrieves a list of chats. By default all chats are returned, sorted by the most recently updated. Pinned chats always come first.
 // @Tags         Chats
 // @Produce      json
+// @Param        sort   query     string  false  "Sort field"       Enums(created_at, updated_at, title)  default(updated_at)
+// @Param        order  query     string  false  "Sort direction"   Enums(asc, desc)                      default(desc)
+// @Param        model  query     string  false  "Only list chats that used this model"
+// @Param        tag    query     string  false  "Only list chats with this tag"
 // @Success      200  {array}   model.Chat
+// @Failure      400  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /v1/chats [get]
 func (h *ChatHandler) GetChats(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseChatListOptions(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
 	// In the current single-user model, we fetch all available chats.
 	// When authentication is added, user identity will be extracted from the
 	// request context (e.g., from a JWT middleware) and passed to the service layer.
-	chats, err := h.chatService.ListChats(r.Context())
+	chats, err := h.chatService.ListChats(r.Context(), opts)
 	if err != nil {
 		respondWithError(w, err)
 		return
@@ -101,9 +256,71 @@ func (h *ChatHandler) GetChats(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, chats)
 }
 
+// parseChatListOptions reads and validates the chat listing query parameters.
+// Only allowlisted values are accepted so they can never reach SQL unchecked.
+// Omitted parameters keep the historical defaults (updated_at, desc).
+func parseChatListOptions(r *http.Request) (model.ChatListOptions, error) {
+	query := r.URL.Query()
+	opts := model.ChatListOptions{
+		SortBy: query.Get("sort"),
+		Order:  strings.ToLower(query.Get("order")),
+		Model:  query.Get("model"),
+		Tag:    query.Get("tag"),
+	}
+
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = "updated_at"
+	case "created_at", "updated_at", "title":
+	default:
+		return opts, fmt.Errorf("%w: invalid sort field '%s', expected one of created_at, updated_at, title", app_errors.ErrValidation, opts.SortBy)
+	}
+
+	switch opts.Order {
+	case "":
+		opts.Order = "desc"
+	case "asc", "desc":
+	default:
+		return opts, fmt.Errorf("%w: invalid order '%s', expected asc or desc", app_errors.ErrValidation, opts.Order)
+	}
+
+	return opts, nil
+}
+
+// HandleCreateChat godoc
+// @Summary      Create an empty chat
+// @Description  Creates a chat without sending a message, so that its title, model and system prompt can be set before the first prompt.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        chat  body      service.CreateChatRequest  true  "Chat options (all fields optional)"
+// @Success      201   {object}  model.Chat
+// @Failure      400   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /v1/chats [post]
+func (h *ChatHandler) HandleCreateChat(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateChatRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	chat, err := h.chatService.CreateChat(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, chat)
+}
+
 // GetChat godoc
 // @Summary      Get a single chat
-// @Description  Retrieves the full history for a single chat's active branch.
+// @Description  Retrieves a chat's metadata and the most recent page of its active branch. `has_more` indicates older messages, available from the messages endpoint.
 // @Tags         Chats
 // @Produce      json
 // @Param        chatID  path      string  true  "Chat ID"
@@ -120,6 +337,71 @@ func (h *ChatHandler) GetChat(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, fullChat)
 }
 
+// GetChatMessages godoc
+// @Summary      List a chat's messages
+// @Description  Returns a page of the chat's active messages, newest first. Pass the ID of the oldest message received as `before` to get the next page.
+// @Tags         Chats
+// @Produce      json
+// @Param        chatID  path      string  true   "Chat ID"
+// @Param        limit   query     int     false  "Page size (default 50, max 200)"
+// @Param        before  query     string  false  "Only return messages older than the message with this ID"
+// @Success      200     {object}  model.MessagePage
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/messages [get]
+func (h *ChatHandler) GetChatMessages(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	query := r.URL.Query()
+
+	var limit int
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, fmt.Errorf("%w: limit must be a positive integer", app_errors.ErrValidation))
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.chatService.GetChatMessages(r.Context(), chatID, limit, query.Get("before"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// HandleEstimateTokens godoc
+// @Summary      Estimate prompt tokens
+// @Description  Returns an approximate token count for sending a message, including the system prompt and the chat's active history. The value is a heuristic, not an exact tokenizer count.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        message  body      service.CreateMessageRequest  true  "Message that would be sent"
+// @Success      200      {object}  service.TokenEstimate
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Router       /v1/chats/estimate [post]
+func (h *ChatHandler) HandleEstimateTokens(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateMessageRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	estimate, err := h.chatService.EstimateTokens(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, estimate)
+}
+
 // HandleStreamMessage godoc
 // @Summary      Create a message and stream the response
 // @Description  Sends a new message and initiates a real-time stream of the assistant's response.
@@ -127,7 +409,8 @@ func (h *ChatHandler) GetChat(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      application/json
 // @Description  Sends a new message and initiates a real-time stream of the assistant's response (SSE).
-// @Param        message  body  service.CreateMessageRequest  true  "Message Request"
+// @Param        message          body    service.CreateMessageRequest  true   "Message Request"
+// @Param        Idempotency-Key  header  string                        false  "Repeats with the same key return the original answer instead of generating again"
 // @Success      200      {object} model.StreamResponse "Stream of response chunks"
 // @Failure      400      {object} ErrorResponse "Sent as a stream error event"
 // @Router       /v1/chats/messages [post]
@@ -138,17 +421,18 @@ func (h *ChatHandler) HandleStreamMessage(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Connection", "keep-alive")
 
 	var req service.CreateMessageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(r, &req); err != nil {
 		slog.Error("Error decoding stream request body", "error", err)
-		sendStreamError(w, "Invalid request body")
+		sendDecodeStreamError(w, err, "Invalid request body")
 		return
 	}
+	req.IdempotencyKey = r.Header.Get("Idempotency-Key")
 
 	// For streaming endpoints, validation errors are also sent over the event stream
 	// to ensure a consistent communication channel with the client.
 	if err := validateRequest(&req); err != nil {
 		slog.Warn("Stream request validation failed", "error", err)
-		sendStreamError(w, err.Error())
+		sendStreamError(w, ErrorCodeValidation, err.Error())
 		return
 	}
 
@@ -156,17 +440,13 @@ func (h *ChatHandler) HandleStreamMessage(w http.ResponseWriter, r *http.Request
 	// Launch the business logic in a separate goroutine to not block the handler.
 	go h.chatService.HandleNewMessage(r.Context(), &req, streamChan)
 
-	// Loop and send stream chunks to the client as they arrive.
-	for chunk := range streamChan {
-		// The client context is checked to detect if the client has disconnected.
+	// Send stream chunks to the client as they arrive, with heartbeats in between.
+	if err := streamEvents(r.Context(), w, streamChan, h.cfg.HeartbeatInterval); err != nil {
 		if r.Context().Err() != nil {
 			slog.Info("Client disconnected, stopping stream.")
-			break
-		}
-		if err := writeStreamEvent(w, chunk); err != nil {
+		} else {
 			// This error typically means the client closed the connection.
 			slog.Warn("Could not write to stream, client likely disconnected.", "error", err)
-			break
 		}
 	}
 
@@ -175,11 +455,10 @@ func (h *ChatHandler) HandleStreamMessage(w http.ResponseWriter, r *http.Request
 
 // HandleRegenerateMessage godoc
 // @Summary      Regenerate a message
-// @Description  Creates a new response for a previous user prompt.
+// @Description  Creates a new response for a previous user prompt (SSE). The first event carries the `message_id` of the new response and the `replaced_message_id` of the one it replaces.
 // @Tags         Chats
 // @Accept       json
 // @Produce      application/json
-// @Description  Creates a new response for a previous user prompt (SSE).
 // @Param        chatID    path      string                              true  "Chat ID"
 // @Param        messageID path      string                              true  "The ID of the assistant message to regenerate"
 // @Param        regenRequest body   service.RegenerateMessageRequest    true  "Regeneration options"
@@ -196,24 +475,25 @@ func (h *ChatHandler) HandleRegenerateMessage(w http.ResponseWriter, r *http.Req
 	messageID := chi.URLParam(r, "messageID")
 
 	var req service.RegenerateMessageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendStreamError(w, "Invalid request payload")
+	if err := decodeJSON(r, &req); err != nil {
+		sendDecodeStreamError(w, err, "Invalid request payload")
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		sendStreamError(w, ErrorCodeValidation, err.Error())
 		return
 	}
 
 	streamChan := make(chan model.StreamResponse)
 	go h.chatService.RegenerateMessage(r.Context(), chatID, messageID, &req, streamChan)
 
-	for chunk := range streamChan {
+	if err := streamEvents(r.Context(), w, streamChan, h.cfg.HeartbeatInterval); err != nil {
 		if r.Context().Err() != nil {
 			// #nosec G706 -- slog provides structured logging which automatically escapes control characters.
 			slog.Info("Client disconnected during regeneration.", "chatID", chatID)
-			break
-		}
-		if err := writeStreamEvent(w, chunk); err != nil {
+		} else {
 			// #nosec G706 -- slog provides structured logging which automatically escapes control characters.
 			slog.Warn("Could not write to regeneration stream, client likely disconnected.", "error", err, "chatID", chatID)
-			break
 		}
 	}
 
@@ -221,6 +501,170 @@ func (h *ChatHandler) HandleRegenerateMessage(w http.ResponseWriter, r *http.Req
 	slog.Debug("Finished streaming regenerated response.", "chatID", chatID)
 }
 
+// HandleContinueMessage godoc
+// @Summary      Continue a message
+// @Description  Continues the latest assistant message of a chat where it stopped, e.g. because it reached `num_predict` (SSE). The new content is appended to the same message; only the new content is streamed. Any other message is rejected with a stream error event.
+// @Tags         Chats
+// @Accept       json
+// @Produce      application/json
+// @Param        chatID    path      string                          true  "Chat ID"
+// @Param        messageID path      string                          true  "The ID of the assistant message to continue"
+// @Param        request   body      service.ContinueMessageRequest  true  "Continuation options"
+// @Success      200       {object}  model.StreamResponse "Stream of the new content"
+// @Failure      400       {object}  ErrorResponse "Sent as a stream error event"
+// @Router       /v1/chats/{chatID}/messages/{messageID}/continue [post]
+func (h *ChatHandler) HandleContinueMessage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	chatID := chi.URLParam(r, "chatID")
+	messageID := chi.URLParam(r, "messageID")
+
+	var req service.ContinueMessageRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		sendDecodeStreamError(w, err, "Invalid request payload")
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		sendStreamError(w, ErrorCodeValidation, err.Error())
+		return
+	}
+
+	streamChan := make(chan model.StreamResponse)
+	go h.chatService.ContinueMessage(r.Context(), chatID, messageID, &req, streamChan)
+
+	if err := streamEvents(r.Context(), w, streamChan, h.cfg.HeartbeatInterval); err != nil {
+		// #nosec G706 -- slog provides structured logging which automatically escapes control characters.
+		slog.Info("Could not write to continuation stream, client likely disconnected.", "error", err, "chatID", chatID)
+	}
+}
+
+// HandleCompareMessage godoc
+// @Summary      Compare the answers of several models
+// @Description  Answers a user message of the active branch with 2 to 4 models at once (SSE). The events of all models are interleaved and tagged with their `model`; the first event of each model carries the `message_id` its answer will be stored with. Each complete answer is stored as a branch of the user message, and the answer of the first model becomes the active branch. A model that fails sends an error event without stopping the others.
+// @Tags         Chats
+// @Accept       json
+// @Produce      application/json
+// @Param        chatID    path      string                         true  "Chat ID"
+// @Param        messageID path      string                         true  "The ID of the user message to answer"
+// @Param        request   body      service.CompareMessageRequest  true  "Models to compare"
+// @Success      200       {object}  model.StreamResponse "Interleaved stream of the answers"
+// @Failure      400       {object}  ErrorResponse "Sent as a stream error event"
+// @Router       /v1/chats/{chatID}/messages/{messageID}/compare [post]
+func (h *ChatHandler) HandleCompareMessage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	chatID := chi.URLParam(r, "chatID")
+	messageID := chi.URLParam(r, "messageID")
+
+	var req service.CompareMessageRequest
+	if err := decodeJSON(r, &req); err != nil {
+		sendDecodeStreamError(w, err, "Invalid request payload")
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		sendStreamError(w, ErrorCodeValidation, err.Error())
+		return
+	}
+
+	streamChan := make(chan model.StreamResponse)
+	go h.chatService.GenerateAlternatives(r.Context(), chatID, messageID, req.Models, streamChan)
+
+	if err := streamEvents(r.Context(), w, streamChan, h.cfg.HeartbeatInterval); err != nil {
+		// #nosec G706 -- slog provides structured logging which automatically escapes control characters.
+		slog.Info("Could not write to comparison stream, client likely disconnected.", "error", err, "chatID", chatID)
+	}
+}
+
+// HandleAddRawMessage godoc
+// @Summary      Insert a message without generating a response
+// @Description  Appends a message with the given role to the chat's active branch without calling the LLM. Useful for importing transcripts or seeding few-shot examples.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        chatID   path      string                     true  "Chat ID"
+// @Param        message  body      service.AddMessageRequest  true  "Message to insert"
+// @Success      201      {object}  model.Message
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/messages/raw [post]
+func (h *ChatHandler) HandleAddRawMessage(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	var req service.AddMessageRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	message, err := h.chatService.AddRawMessage(r.Context(), chatID, &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, message)
+}
+
+// GetMessage godoc
+// @Summary      Get a single message
+// @Description  Retrieves one message of a chat, e.g. to refetch an answer after it was regenerated. Inactive messages can be retrieved as well.
+// @Tags         Chats
+// @Produce      json
+// @Param        chatID     path      string  true  "Chat ID"
+// @Param        messageID  path      string  true  "Message ID"
+// @Success      200        {object}  model.Message
+// @Failure      404        {object}  ErrorResponse "The message does not exist or belongs to another chat"
+// @Failure      500        {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/messages/{messageID} [get]
+func (h *ChatHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	msg, err := h.chatService.GetMessage(r.Context(), chi.URLParam(r, "chatID"), chi.URLParam(r, "messageID"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, msg)
+}
+
+// GetAttachment godoc
+// @Summary      Download a message attachment
+// @Description  Serves the binary content of an attachment (e.g. an image) of a message.
+// @Tags         Chats
+// @Produce      octet-stream
+// @Param        chatID        path      string  true  "Chat ID"
+// @Param        messageID     path      string  true  "Message ID"
+// @Param        attachmentID  path      string  true  "Attachment ID"
+// @Success      200           {file}    binary
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/messages/{messageID}/attachments/{attachmentID} [get]
+func (h *ChatHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	messageID := chi.URLParam(r, "messageID")
+	attachmentID := chi.URLParam(r, "attachmentID")
+
+	attachment, err := h.chatService.GetAttachment(r.Context(), chatID, messageID, attachmentID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(attachment.Data); err != nil {
+		slog.Warn("Could not write attachment, client likely disconnected.", "attachment_id", attachmentID, "error", err)
+	}
+}
+
 // UpdateChatTitle godoc
 // @Summary      Update a chat's title
 // @Description  Manually renames a chat.
@@ -237,8 +681,8 @@ func (h *ChatHandler) HandleRegenerateMessage(w http.ResponseWriter, r *http.Req
 func (h *ChatHandler) UpdateChatTitle(w http.ResponseWriter, r *http.Request) {
 	chatID := chi.URLParam(r, "chatID")
 	var req UpdateTitleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, app_errors.ErrValidation)
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
 		return
 	}
 
@@ -255,6 +699,94 @@ func (h *ChatHandler) UpdateChatTitle(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
 }
 
+// UpdateChatCollection godoc
+// @Summary      Bind a chat to a document collection
+// @Description  Enables retrieval from a document collection for the chat's messages. An empty collection ID disables it.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        chatID      path      string                                true  "Chat ID"
+// @Param        collection  body      service.UpdateChatCollectionRequest  true  "Collection ID"
+// @Success      200         {object}  StatusResponse
+// @Failure      400         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      500         {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/collection [put]
+func (h *ChatHandler) UpdateChatCollection(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	var req service.UpdateChatCollectionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := h.chatService.SetChatCollection(r.Context(), chatID, req.CollectionID); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
+
+// UpdateChatPinned godoc
+// @Summary      Pin or unpin a chat
+// @Description  Pinned chats are listed before all others.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        chatID  path      string                           true  "Chat ID"
+// @Param        pin     body      service.UpdateChatPinnedRequest  true  "Pin state"
+// @Success      200     {object}  StatusResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/pin [put]
+func (h *ChatHandler) UpdateChatPinned(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	var req service.UpdateChatPinnedRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := h.chatService.SetChatPinned(r.Context(), chatID, req.Pinned); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
+
+// UpdateChatTags godoc
+// @Summary      Set the tags of a chat
+// @Description  Replaces the tags of a chat. Tags are 1-32 lowercase letters, digits, '-' or '_'; duplicates are removed. An empty list removes all tags.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        chatID  path      string                         true  "Chat ID"
+// @Param        tags    body      service.UpdateChatTagsRequest  true  "New tags"
+// @Success      200     {object}  service.UpdateChatTagsRequest  "The stored tags"
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/tags [put]
+func (h *ChatHandler) UpdateChatTags(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	var req service.UpdateChatTagsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	tags, err := h.chatService.SetChatTags(r.Context(), chatID, req.Tags)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, service.UpdateChatTagsRequest{Tags: tags})
+}
+
 // HandleDeleteChat godoc
 // @Summary      Delete a chat
 // @Description  Permanently deletes a chat and all its associated messages.
@@ -274,23 +806,150 @@ func (h *ChatHandler) HandleDeleteChat(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
 }
 
+// HandleCloneChat godoc
+// @Summary      Clone a chat
+// @Description  Copies a chat and its active messages into a new, independent chat titled "Copy of ...". Inactive branches are not copied.
+// @Tags         Chats
+// @Produce      json
+// @Param        chatID  path      string  true  "Chat ID"
+// @Success      201     {object}  model.Chat
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/clone [post]
+func (h *ChatHandler) HandleCloneChat(w http.ResponseWriter, r *http.Request) {
+	clone, err := h.chatService.CloneChat(r.Context(), chi.URLParam(r, "chatID"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, clone)
+}
+
+// HandleRegenerateTitle godoc
+// @Summary      Regenerate a chat's title
+// @Description  Generates a new title from the chat's first exchange using the support model and saves it. If the support model is not configured or not available, the existing title is returned with `regenerated` set to false.
+// @Tags         Chats
+// @Produce      json
+// @Param        chatID  path      string  true  "Chat ID"
+// @Success      200     {object}  service.RegenerateTitleResponse
+// @Failure      400     {object}  ErrorResponse "The chat has no answered message"
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/chats/{chatID}/regenerate-title [post]
+func (h *ChatHandler) HandleRegenerateTitle(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.chatService.RegenerateTitle(r.Context(), chi.URLParam(r, "chatID"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// HandleDeleteChats godoc
+// @Summary      Delete several chats
+// @Description  Permanently deletes the given chats and their messages in a single transaction. IDs of chats that don't exist are skipped.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.DeleteChatsRequest  true  "Chat IDs"
+// @Success      200      {object}  DeleteChatsResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /v1/chats/bulk-delete [post]
+func (h *ChatHandler) HandleDeleteChats(w http.ResponseWriter, r *http.Request) {
+	var req service.DeleteChatsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	deleted, err := h.chatService.DeleteChats(r.Context(), req.IDs)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, DeleteChatsResponse{Deleted: deleted})
+}
+
+// GetGlobalStats godoc
+// @Summary      Count chats and messages
+// @Description  Returns the number of chats and messages over all chats, the active and inactive (regenerated or edited away) messages, and the messages of each model, for an admin dashboard.
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminAPIKey
+// @Success      200  {object}  model.GlobalStats
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/admin/stats [get]
+func (h *ChatHandler) GetGlobalStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.chatService.GetGlobalStats(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
 // GetChatTree godoc
 // @Summary      Get full chat tree
-// @Description  Retrieves all messages for a chat, including inactive branches.
+// @Description  Retrieves all messages for a chat, including inactive branches. By default the messages are a flat list ordered by time; with `nested=true` they are nested under `roots` by their parent.
 // @Tags         Chats
 // @Produce      json
-// @Param        chatID  path      string  true  "Chat ID"
-// @Success      200     {object}  model.FullChat
+// @Param        chatID  path      string  true   "Chat ID"
+// @Param        nested  query     bool    false  "Return the messages as a tree"
+// @Param        since   query     string  false  "Only include messages created at or after this RFC 3339 timestamp"
+// @Param        depth   query     int     false  "Maximum number of tree levels to include (0 = all)"
+// @Success      200     {object}  model.ChatTree
+// @Failure      400     {object}  ErrorResponse
 // @Failure      404     {object}  ErrorResponse
 // @Router       /v1/chats/{chatID}/tree [get]
 func (h *ChatHandler) GetChatTree(w http.ResponseWriter, r *http.Request) {
 	chatID := chi.URLParam(r, "chatID")
-	fullChat, err := h.chatService.GetChatTree(r.Context(), chatID)
+	opts, err := parseChatTreeOptions(r)
 	if err != nil {
 		respondWithError(w, err)
 		return
 	}
-	respondWithJSON(w, http.StatusOK, fullChat)
+
+	tree, err := h.chatService.GetChatTree(r.Context(), chatID, opts)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tree)
+}
+
+// parseChatTreeOptions reads the optional `nested`, `since` and `depth` query parameters.
+func parseChatTreeOptions(r *http.Request) (model.ChatTreeOptions, error) {
+	query := r.URL.Query()
+	var opts model.ChatTreeOptions
+
+	if raw := query.Get("nested"); raw != "" {
+		nested, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("%w: nested must be true or false", app_errors.ErrValidation)
+		}
+		opts.Nested = nested
+	}
+	if raw := query.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return opts, fmt.Errorf("%w: since must be an RFC 3339 timestamp", app_errors.ErrValidation)
+		}
+		opts.Since = &since
+	}
+	if raw := query.Get("depth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 0 {
+			return opts, fmt.Errorf("%w: depth must be a non-negative integer", app_errors.ErrValidation)
+		}
+		opts.Depth = depth
+	}
+	return opts, nil
 }
 
 // HandleSwitchBranch godoc
@@ -8,20 +8,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"flow-ai/backend/internal/api"
 	app_errors "flow-ai/backend/internal/errors"
 
 	// We import the generated mocks for our service interfaces.
 	"flow-ai/backend/internal/interfaces/mocks"
+	"flow-ai/backend/internal/llm"
 	"flow-ai/backend/internal/model"
 	"flow-ai/backend/internal/service"
 )
@@ -34,7 +38,7 @@ import (
 func setupChatHandler(t *testing.T) (*api.ChatHandler, *mocks.MockChatService, *mocks.MockSettingsService) {
 	mockChatSvc := mocks.NewMockChatService(t)
 	mockSettingsSvc := mocks.NewMockSettingsService(t)
-	handler := api.NewChatHandler(mockChatSvc, mockSettingsSvc)
+	handler := api.NewChatHandler(mockChatSvc, mockSettingsSvc, api.ChatHandlerConfig{})
 	return handler, mockChatSvc, mockSettingsSvc
 }
 
@@ -52,6 +56,15 @@ func addChiURLParams(req *http.Request, params map[string]string) *http.Request
 	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
 }
 
+// assertErrorCode checks the machine-readable code of a JSON error response.
+func assertErrorCode(t *testing.T, rr *httptest.ResponseRecorder, expected string) {
+	t.Helper()
+	var resp api.ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp.Code)
+	assert.NotEmpty(t, resp.Error)
+}
+
 // TestChatHandler_GetSettings tests the GET /v1/settings endpoint.
 //
 // GOAL: Verify the handler correctly calls the SettingsService and translates
@@ -85,6 +98,7 @@ func TestChatHandler_GetSettings(t *testing.T) {
 
 		// ASSERT: Verify the handler correctly maps the internal error to a 500 status.
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeInternal)
 		mockSettingsSvc.AssertExpectations(t)
 	})
 }
@@ -95,7 +109,9 @@ func TestChatHandler_GetChats(t *testing.T) {
 		// ARRANGE
 		handler, mockChatSvc, _ := setupChatHandler(t)
 		expectedChats := []*model.Chat{{ID: "chat1", Title: "Test Chat"}}
-		mockChatSvc.On("ListChats", mock.Anything).Return(expectedChats, nil).Once()
+		// Without query parameters the historical defaults must be applied.
+		defaultOpts := model.ChatListOptions{SortBy: "updated_at", Order: "desc"}
+		mockChatSvc.On("ListChats", mock.Anything, defaultOpts).Return(expectedChats, nil).Once()
 
 		// ACT
 		req := httptest.NewRequest(http.MethodGet, "/v1/chats", nil)
@@ -116,7 +132,7 @@ func TestChatHandler_GetChats(t *testing.T) {
 	t.Run("Failure - Service returns error", func(t *testing.T) {
 		// ARRANGE
 		handler, mockChatSvc, _ := setupChatHandler(t)
-		mockChatSvc.On("ListChats", mock.Anything).Return(nil, errors.New("internal error")).Once()
+		mockChatSvc.On("ListChats", mock.Anything, mock.Anything).Return(nil, errors.New("internal error")).Once()
 
 		// ACT
 		req := httptest.NewRequest(http.MethodGet, "/v1/chats", nil)
@@ -125,8 +141,55 @@ func TestChatHandler_GetChats(t *testing.T) {
 
 		// ASSERT
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeInternal)
 		assert.Contains(t, rr.Body.String(), "internal server error")
 	})
+
+	t.Run("Success - Sort, order and model filter", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		expectedOpts := model.ChatListOptions{SortBy: "title", Order: "asc", Model: "qwen3:8b"}
+		mockChatSvc.On("ListChats", mock.Anything, expectedOpts).Return([]*model.Chat{}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats?sort=title&order=ASC&model=qwen3:8b", nil)
+		rr := httptest.NewRecorder()
+		handler.GetChats(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockChatSvc.AssertExpectations(t)
+	})
+
+	t.Run("Success - Tag filter", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		expectedOpts := model.ChatListOptions{SortBy: "updated_at", Order: "desc", Tag: "work"}
+		mockChatSvc.On("ListChats", mock.Anything, expectedOpts).
+			Return([]*model.Chat{{ID: "chat1", Tags: []string{"work"}}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats?tag=work", nil)
+		rr := httptest.NewRecorder()
+		handler.GetChats(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"tags":["work"]`)
+	})
+
+	t.Run("Failure - Invalid sort field", func(t *testing.T) {
+		// GOAL: Values outside the allowlist must be rejected before reaching the service.
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats?sort=id%3BDROP%20TABLE%20chats", nil)
+		rr := httptest.NewRecorder()
+		handler.GetChats(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Failure - Invalid order", func(t *testing.T) {
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats?order=sideways", nil)
+		rr := httptest.NewRecorder()
+		handler.GetChats(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
 }
 
 // TestChatHandler_GetChat tests the GET /v1/chats/{chatID} endpoint.
@@ -163,10 +226,51 @@ func TestChatHandler_GetChat(t *testing.T) {
 
 		// ASSERT: Verify the handler correctly maps `ErrNotFound` to a 404 status.
 		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
 		mockChatSvc.AssertExpectations(t)
 	})
 }
 
+// TestChatHandler_GetMessage tests the GET /v1/chats/{chatID}/messages/{messageID} endpoint.
+func TestChatHandler_GetMessage(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		modelName := "qwen3:8b"
+		mockChatSvc.On("GetMessage", mock.Anything, "chat1", "msg1").
+			Return(&model.Message{ID: "msg1", ChatID: "chat1", Role: "assistant", Content: "Hi!", Model: &modelName, IsActive: true}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/messages/msg1", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1", "messageID": "msg1"})
+		rr := httptest.NewRecorder()
+		handler.GetMessage(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var msg model.Message
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &msg))
+		assert.Equal(t, "msg1", msg.ID)
+		assert.Equal(t, "Hi!", msg.Content)
+	})
+
+	t.Run("Failure - Message of another chat", func(t *testing.T) {
+		// ARRANGE: The service reports a message of another chat as not found.
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("GetMessage", mock.Anything, "chat2", "msg1").Return(nil, app_errors.ErrNotFound).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat2/messages/msg1", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "chat2", "messageID": "msg1"})
+		rr := httptest.NewRecorder()
+		handler.GetMessage(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
 // TestChatHandler_UpdateSettings tests the POST /v1/settings endpoint.
 // GOAL: Verify JSON parsing, validation logic, and service invocation.
 func TestChatHandler_UpdateSettings(t *testing.T) {
@@ -195,6 +299,7 @@ func TestChatHandler_UpdateSettings(t *testing.T) {
 		rr := httptest.NewRecorder()
 		handler.UpdateSettings(rr, req)
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
 	})
 
 	t.Run("Failure - Validation Error", func(t *testing.T) {
@@ -207,8 +312,223 @@ func TestChatHandler_UpdateSettings(t *testing.T) {
 
 		handler.UpdateSettings(rr, req)
 
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+		assert.Contains(t, rr.Body.String(), "Field 'MainModel' failed on the 'required' tag")
+	})
+
+	t.Run("Failure - Invalid keep_alive", func(t *testing.T) {
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		settingsJSON := `{"main_model":"model1","keep_alive":"5 min"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/settings", strings.NewReader(settingsJSON))
+		rr := httptest.NewRecorder()
+
+		handler.UpdateSettings(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Field 'KeepAlive' failed on the 'keep_alive' tag")
+		mockSettingsSvc.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Invalid default options", func(t *testing.T) {
+		// WHY: Default options are validated like the options of a request, so that
+		// an invalid value cannot break every later message.
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		settingsJSON := `{"main_model":"model1","default_options":{"temperature":3}}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/settings", strings.NewReader(settingsJSON))
+		rr := httptest.NewRecorder()
+
+		handler.UpdateSettings(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Field 'Temperature' failed on the 'lte' tag")
+		mockSettingsSvc.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatHandler_RefreshSettings tests the POST /v1/settings/refresh endpoint.
+func TestChatHandler_RefreshSettings(t *testing.T) {
+	t.Run("Success - Returns the settings with the discovered models", func(t *testing.T) {
+		// ARRANGE
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		updated := &service.Settings{SystemPrompt: "prompt", MainModel: "qwen3:8b", SupportModel: "qwen3:8b"}
+		mockSettingsSvc.On("Refresh", mock.Anything).Return(updated, nil).Once()
+		rr := httptest.NewRecorder()
+
+		// ACT
+		handler.RefreshSettings(rr, httptest.NewRequest(http.MethodPost, "/v1/settings/refresh", nil))
+
+		// ASSERT
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body service.Settings
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, *updated, body)
+	})
+
+	t.Run("Failure - The models cannot be listed", func(t *testing.T) {
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("Refresh", mock.Anything).Return(nil, errors.New("could not list models for discovery: connection refused")).Once()
+		rr := httptest.NewRecorder()
+
+		handler.RefreshSettings(rr, httptest.NewRequest(http.MethodPost, "/v1/settings/refresh", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+// TestChatHandler_PatchSettings verifies that a partial update changes only the
+// given fields and that the merged settings are validated.
+func TestChatHandler_PatchSettings(t *testing.T) {
+	temperature := float32(0.2)
+	current := func() *service.Settings {
+		return &service.Settings{
+			SystemPrompt:   "old prompt",
+			MainModel:      "model1",
+			SupportModel:   "model2",
+			RetentionDays:  30,
+			DefaultOptions: &llm.RequestOptions{Temperature: &temperature},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		body     string
+		expected func(s *service.Settings)
+	}{
+		{
+			name:     "Only the system prompt",
+			body:     `{"system_prompt":"new prompt"}`,
+			expected: func(s *service.Settings) { s.SystemPrompt = "new prompt" },
+		},
+		{
+			name:     "Only the support model",
+			body:     `{"support_model":"model3"}`,
+			expected: func(s *service.Settings) { s.SupportModel = "model3" },
+		},
+		{
+			name:     "Zero values and a null default_options are applied",
+			body:     `{"retention_days":0,"default_options":null}`,
+			expected: func(s *service.Settings) { s.RetentionDays = 0; s.DefaultOptions = nil },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE
+			handler, _, mockSettingsSvc := setupChatHandler(t)
+			expected := current()
+			tc.expected(expected)
+			mockSettingsSvc.On("Get", mock.Anything).Return(current(), nil).Once()
+			mockSettingsSvc.On("Save", mock.Anything, expected).Return(nil).Once()
+			req := httptest.NewRequest(http.MethodPatch, "/v1/settings", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			// ACT
+			handler.PatchSettings(rr, req)
+
+			// ASSERT
+			require.Equal(t, http.StatusOK, rr.Code)
+			var body service.Settings
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, *expected, body)
+		})
+	}
+
+	t.Run("Failure - The merged settings are invalid", func(t *testing.T) {
+		// WHY: The main model is required, so a patch cannot clear it.
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("Get", mock.Anything).Return(current(), nil).Once()
+		req := httptest.NewRequest(http.MethodPatch, "/v1/settings", strings.NewReader(`{"main_model":""}`))
+		rr := httptest.NewRecorder()
+
+		handler.PatchSettings(rr, req)
+
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
 		assert.Contains(t, rr.Body.String(), "Field 'MainModel' failed on the 'required' tag")
+		mockSettingsSvc.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Invalid JSON", func(t *testing.T) {
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPatch, "/v1/settings", strings.NewReader(`{"system_prompt":1}`))
+		rr := httptest.NewRecorder()
+
+		handler.PatchSettings(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+}
+
+// TestChatHandler_ModelAliases tests the /v1/models/aliases endpoints.
+func TestChatHandler_ModelAliases(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		// ARRANGE
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("ListModelAliases", mock.Anything).Return([]service.ModelAlias{{Name: "smart", Model: "qwen3:14b"}}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/aliases", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListModelAliases(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[{"name": "smart", "model": "qwen3:14b"}]`, rr.Body.String())
+	})
+
+	t.Run("Set - Name is taken from the path", func(t *testing.T) {
+		// ARRANGE
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("SetModelAlias", mock.Anything, &service.ModelAlias{Name: "smart", Model: "qwen3:14b"}).Return(nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/aliases/smart", strings.NewReader(`{"name": "ignored", "model": "qwen3:14b"}`))
+		req = addChiURLParams(req, map[string]string{"name": "smart"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelAlias(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"name": "smart", "model": "qwen3:14b"}`, rr.Body.String())
+	})
+
+	t.Run("Set - Missing model", func(t *testing.T) {
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/aliases/smart", strings.NewReader(`{}`))
+		req = addChiURLParams(req, map[string]string{"name": "smart"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelAlias(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockSettingsSvc.AssertNotCalled(t, "SetModelAlias", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Set - Loop is rejected", func(t *testing.T) {
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("SetModelAlias", mock.Anything, mock.Anything).
+			Return(fmt.Errorf("%w: alias 'fast' would create a loop", app_errors.ErrValidation)).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/aliases/fast", strings.NewReader(`{"model": "smart"}`))
+		req = addChiURLParams(req, map[string]string{"name": "fast"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelAlias(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Delete - Alias in use", func(t *testing.T) {
+		handler, _, mockSettingsSvc := setupChatHandler(t)
+		mockSettingsSvc.On("DeleteModelAlias", mock.Anything, "smart").Return(app_errors.ErrConflict).Once()
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/models/aliases/smart", nil)
+		req = addChiURLParams(req, map[string]string{"name": "smart"})
+		rr := httptest.NewRecorder()
+		handler.HandleDeleteModelAlias(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
 	})
 }
 
@@ -237,6 +557,7 @@ func TestChatHandler_UpdateChatTitle(t *testing.T) {
 		rr := httptest.NewRecorder()
 		handler.UpdateChatTitle(rr, req)
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
 		assert.Contains(t, rr.Body.String(), "Field 'Title' failed on the 'required' tag")
 	})
 
@@ -248,6 +569,7 @@ func TestChatHandler_UpdateChatTitle(t *testing.T) {
 		rr := httptest.NewRecorder()
 		handler.UpdateChatTitle(rr, req)
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
 	})
 }
 
@@ -274,10 +596,131 @@ func TestChatHandler_HandleDeleteChat(t *testing.T) {
 		rr := httptest.NewRecorder()
 		handler.HandleDeleteChat(rr, req)
 		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
 		mockChatSvc.AssertExpectations(t)
 	})
 }
 
+// TestChatHandler_HandleCloneChat tests the POST /v1/chats/{chatID}/clone endpoint.
+func TestChatHandler_HandleCloneChat(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("CloneChat", mock.Anything, "chat1").Return(&model.Chat{ID: "clone1", Title: "Copy of Chat"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/clone", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.HandleCloneChat(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var resp model.Chat
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "clone1", resp.ID)
+	})
+
+	t.Run("Failure - Not Found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("CloneChat", mock.Anything, "missing").Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/missing/clone", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "missing"})
+		rr := httptest.NewRecorder()
+		handler.HandleCloneChat(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestChatHandler_HandleRegenerateTitle tests the POST /v1/chats/{chatID}/regenerate-title endpoint.
+func TestChatHandler_HandleRegenerateTitle(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("RegenerateTitle", mock.Anything, "chat1").Return(&service.RegenerateTitleResponse{Title: "Fall of Rome", Regenerated: true}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/regenerate-title", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.HandleRegenerateTitle(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"title": "Fall of Rome", "regenerated": true}`, rr.Body.String())
+	})
+
+	t.Run("Failure - Chat without messages", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("RegenerateTitle", mock.Anything, "chat1").Return(nil, fmt.Errorf("%w: chat has no answered message", app_errors.ErrValidation)).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/regenerate-title", nil)
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.HandleRegenerateTitle(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+}
+
+// TestChatHandler_GetGlobalStats tests the GET /v1/admin/stats endpoint.
+func TestChatHandler_GetGlobalStats(t *testing.T) {
+	handler, mockChatSvc, _ := setupChatHandler(t)
+	stats := &model.GlobalStats{
+		Chats: 2, Messages: 5, ActiveMessages: 4, InactiveMessages: 1,
+		MessagesByModel: []model.ModelMessageCount{{Model: "qwen3:8b", Messages: 2}},
+	}
+	mockChatSvc.On("GetGlobalStats", mock.Anything).Return(stats, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/stats", nil)
+	rr := httptest.NewRecorder()
+	handler.GetGlobalStats(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"chats": 2, "messages": 5, "active_messages": 4, "inactive_messages": 1,
+		"messages_by_model": [{"model": "qwen3:8b", "messages": 2}]
+	}`, rr.Body.String())
+}
+
+// TestChatHandler_HandleDeleteChats tests the POST /v1/chats/bulk-delete endpoint.
+func TestChatHandler_HandleDeleteChats(t *testing.T) {
+	t.Run("Success - Missing IDs are skipped", func(t *testing.T) {
+		// ARRANGE: Only two of the three chats exist.
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		ids := []string{"chat1", "missing", "chat2"}
+		mockChatSvc.On("DeleteChats", mock.Anything, ids).Return(2, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/bulk-delete", strings.NewReader(`{"ids": ["chat1", "missing", "chat2"]}`))
+		rr := httptest.NewRecorder()
+		handler.HandleDeleteChats(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"deleted": 2}`, rr.Body.String())
+	})
+
+	t.Run("Failure - Validation", func(t *testing.T) {
+		testCases := map[string]string{
+			"No IDs":   `{"ids": []}`,
+			"Empty ID": `{"ids": ["chat1", ""]}`,
+			"Bad JSON": `{"ids": "chat1"}`,
+		}
+		for name, body := range testCases {
+			t.Run(name, func(t *testing.T) {
+				handler, mockChatSvc, _ := setupChatHandler(t)
+
+				req := httptest.NewRequest(http.MethodPost, "/v1/chats/bulk-delete", strings.NewReader(body))
+				rr := httptest.NewRecorder()
+				handler.HandleDeleteChats(rr, req)
+
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assertErrorCode(t, rr, api.ErrorCodeValidation)
+				mockChatSvc.AssertNotCalled(t, "DeleteChats", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
 // TestChatHandler_HandleStreamMessage tests the streaming POST /v1/chats/messages endpoint.
 //
 // GOAL: Verify that the handler correctly sets up the stream, validates the
@@ -306,6 +749,24 @@ func TestChatHandler_HandleStreamMessage(t *testing.T) {
 		mockChatSvc.AssertExpectations(t)
 	})
 
+	t.Run("Success - Idempotency key header is passed to the service", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/messages", strings.NewReader(`{"content": "hello"}`))
+		req.Header.Set("Idempotency-Key", "key-1")
+		rr := httptest.NewRecorder()
+
+		mockChatSvc.On("HandleNewMessage", mock.Anything, mock.MatchedBy(func(r *service.CreateMessageRequest) bool {
+			return r.IdempotencyKey == "key-1"
+		}), mock.Anything).
+			Run(func(args mock.Arguments) {
+				close(args.Get(2).(chan<- model.StreamResponse))
+			}).Once()
+
+		handler.HandleStreamMessage(rr, req)
+
+		mockChatSvc.AssertExpectations(t)
+	})
+
 	t.Run("Failure - Invalid JSON", func(t *testing.T) {
 		handler, _, _ := setupChatHandler(t)
 		reqBody := `{"content":`
@@ -317,6 +778,7 @@ func TestChatHandler_HandleStreamMessage(t *testing.T) {
 		// For streaming endpoints, errors are sent over the stream itself.
 		// We assert that the response body contains the error event.
 		assert.Contains(t, rr.Body.String(), "Invalid request body")
+		assert.Contains(t, rr.Body.String(), `"code":"validation_failed"`)
 	})
 
 	t.Run("Failure - Validation Error", func(t *testing.T) {
@@ -327,6 +789,516 @@ func TestChatHandler_HandleStreamMessage(t *testing.T) {
 
 		handler.HandleStreamMessage(rr, req)
 
-		assert.Contains(t, rr.Body.String(), "Field 'Content' failed on the 'required' tag")
+		assert.Contains(t, rr.Body.String(), "Field 'Content' failed on the 'required_without' tag")
+	})
+
+	for _, body := range []string{
+		`{"content": "hi", "options": {"temperature": 2.5}}`,
+		`{"content": "hi", "options": {"num_ctx": -1}}`,
+		`{"content": "hi", "options": {"num_ctx": 2097152}}`,
+		`{"content": "hi", "options": {"num_predict": -3}}`,
+		`{"content": "hi", "options": {"mirostat": 3}}`,
+		`{"content": "hi", "options": {"keep_alive": "five minutes"}}`,
+		`{"content": "hi", "options": {"format": "yaml"}}`,
+		`{"content": "hi", "response_format": "xml"}`,
+	} {
+		t.Run("Failure - Invalid options "+body, func(t *testing.T) {
+			handler, mockChatSvc, _ := setupChatHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/chats/messages", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			handler.HandleStreamMessage(rr, req)
+
+			assert.Contains(t, rr.Body.String(), `"code":"validation_failed"`)
+			mockChatSvc.AssertNotCalled(t, "HandleNewMessage", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Failure - Invalid options on regenerate", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/msg1/regenerate", strings.NewReader(`{"options": {"top_p": 1.5}}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1", "messageID": "msg1"})
+		rr := httptest.NewRecorder()
+
+		handler.HandleRegenerateMessage(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "TopP")
+		mockChatSvc.AssertNotCalled(t, "RegenerateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatHandler_HandleCompareMessage tests the POST
+// /v1/chats/{chatID}/messages/{messageID}/compare endpoint.
+func TestChatHandler_HandleCompareMessage(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("GenerateAlternatives", mock.Anything, "chat1", "msg1", []string{"qwen3:8b", "gemma3:4b"}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(4).(chan<- model.StreamResponse)
+				streamChan <- model.StreamResponse{ChatID: "chat1", Model: "qwen3:8b", Content: "Hi", Done: true}
+				close(streamChan)
+			}).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/msg1/compare", strings.NewReader(`{"models": ["qwen3:8b", "gemma3:4b"]}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1", "messageID": "msg1"})
+		rr := httptest.NewRecorder()
+		handler.HandleCompareMessage(rr, req)
+
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), `"model":"qwen3:8b"`)
+	})
+
+	for _, body := range []string{
+		`{"models": ["qwen3:8b"]}`,
+		`{"models": ["qwen3:8b", "qwen3:8b"]}`,
+		`{"models": ["a", "b", "c", "d", "e"]}`,
+		`{"models": ["qwen3:8b", ""]}`,
+	} {
+		t.Run("Failure - Invalid models "+body, func(t *testing.T) {
+			handler, mockChatSvc, _ := setupChatHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/msg1/compare", strings.NewReader(body))
+			req = addChiURLParams(req, map[string]string{"chatID": "chat1", "messageID": "msg1"})
+			rr := httptest.NewRecorder()
+
+			handler.HandleCompareMessage(rr, req)
+
+			assert.Contains(t, rr.Body.String(), `"code":"validation_failed"`)
+			mockChatSvc.AssertNotCalled(t, "GenerateAlternatives", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestChatHandler_HandleEstimateTokens tests the POST /v1/chats/estimate endpoint.
+func TestChatHandler_HandleEstimateTokens(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		expected := &service.TokenEstimate{EstimatedTokens: 42, MessageCount: 2}
+		mockChatSvc.On("EstimateTokens", mock.Anything, mock.MatchedBy(func(r *service.CreateMessageRequest) bool {
+			return r.Content == "hello" && r.ChatID == "chat1"
+		})).Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/estimate", strings.NewReader(`{"chat_id": "chat1", "content": "hello"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleEstimateTokens(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp service.TokenEstimate
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, 42, resp.EstimatedTokens)
+		mockChatSvc.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Validation Error", func(t *testing.T) {
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/estimate", strings.NewReader(`{"content": ""}`))
+		rr := httptest.NewRecorder()
+		handler.HandleEstimateTokens(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Failure - Chat Not Found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("EstimateTokens", mock.Anything, mock.Anything).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/estimate", strings.NewReader(`{"chat_id": "missing", "content": "hello"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleEstimateTokens(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestChatHandler_HandleCreateChat tests the POST /v1/chats endpoint.
+func TestChatHandler_HandleCreateChat(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		expected := &model.Chat{ID: "chat1", Title: "Review", Model: "m1", SystemPrompt: "Be strict."}
+		mockChatSvc.On("CreateChat", mock.Anything, mock.MatchedBy(func(r *service.CreateChatRequest) bool {
+			return r.Title == "Review" && r.Model == "m1" && r.SystemPrompt == "Be strict."
+		})).Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(`{"title": "Review", "model": "m1", "system_prompt": "Be strict."}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateChat(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var resp model.Chat
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "chat1", resp.ID)
+		assert.Equal(t, "Be strict.", resp.SystemPrompt)
+		mockChatSvc.AssertExpectations(t)
+	})
+
+	t.Run("Success - Empty body fields use defaults", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("CreateChat", mock.Anything, &service.CreateChatRequest{}).
+			Return(&model.Chat{ID: "chat2", Title: "New Chat", Model: "default"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(`{}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateChat(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Failure - Title too long", func(t *testing.T) {
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(`{"title": "`+strings.Repeat("a", 101)+`"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateChat(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Failure - Unavailable model", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("CreateChat", mock.Anything, mock.Anything).Return(nil, app_errors.ErrValidation).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(`{"model": "missing"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreateChat(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+}
+
+// TestChatHandler_HandleAddRawMessage tests the POST /v1/chats/{chatID}/messages/raw endpoint.
+func TestChatHandler_HandleAddRawMessage(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		expected := &model.Message{ID: "msg1", Role: "assistant", Content: "Paris."}
+		mockChatSvc.On("AddRawMessage", mock.Anything, "chat1", &service.AddMessageRequest{Role: "assistant", Content: "Paris."}).
+			Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/raw", strings.NewReader(`{"role": "assistant", "content": "Paris."}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.HandleAddRawMessage(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var resp model.Message
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "msg1", resp.ID)
+	})
+
+	validationCases := []struct {
+		name string
+		body string
+	}{
+		{name: "Failure - Role not allowed", body: `{"role": "tool", "content": "hi"}`},
+		{name: "Failure - Missing role", body: `{"content": "hi"}`},
+		{name: "Failure - Empty content", body: `{"role": "user", "content": ""}`},
+	}
+	for _, tc := range validationCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, _, _ := setupChatHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/raw", strings.NewReader(tc.body))
+			req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+			rr := httptest.NewRecorder()
+			handler.HandleAddRawMessage(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+		})
+	}
+
+	t.Run("Failure - Chat Not Found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("AddRawMessage", mock.Anything, "missing", mock.Anything).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/missing/messages/raw", strings.NewReader(`{"role": "user", "content": "hi"}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "missing"})
+		rr := httptest.NewRecorder()
+		handler.HandleAddRawMessage(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestChatHandler_GetAttachment tests the GET /v1/chats/{chatID}/messages/{messageID}/attachments/{attachmentID} endpoint.
+func TestChatHandler_GetAttachment(t *testing.T) {
+	params := map[string]string{"chatID": "chat1", "messageID": "msg1", "attachmentID": "att1"}
+
+	t.Run("Success - Serves the binary content", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		data := []byte("\x89PNG\r\n\x1a\n")
+		mockChatSvc.On("GetAttachment", mock.Anything, "chat1", "msg1", "att1").
+			Return(&model.Attachment{ID: "att1", MimeType: "image/png", SizeBytes: int64(len(data)), Data: data}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/messages/msg1/attachments/att1", nil)
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.GetAttachment(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, data, rr.Body.Bytes())
+	})
+
+	t.Run("Failure - Not Found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("GetAttachment", mock.Anything, "chat1", "msg1", "att1").Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/messages/msg1/attachments/att1", nil)
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.GetAttachment(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestChatHandler_UpdateChatCollection tests the PUT /v1/chats/{chatID}/collection endpoint.
+func TestChatHandler_UpdateChatCollection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatCollection", mock.Anything, "chat1", "col1").Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/collection", strings.NewReader(`{"collection_id": "col1"}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.UpdateChatCollection(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Failure - Unknown collection", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatCollection", mock.Anything, "chat1", "missing").Return(app_errors.ErrValidation).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/collection", strings.NewReader(`{"collection_id": "missing"}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1"})
+		rr := httptest.NewRecorder()
+		handler.UpdateChatCollection(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+}
+
+// TestChatHandler_UpdateChatPinned tests the PUT /v1/chats/{chatID}/pin endpoint.
+func TestChatHandler_UpdateChatPinned(t *testing.T) {
+	params := map[string]string{"chatID": "chat1"}
+
+	t.Run("Success", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatPinned", mock.Anything, "chat1", true).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/pin", strings.NewReader(`{"pinned": true}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatPinned(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatPinned", mock.Anything, "chat1", false).Return(app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/pin", strings.NewReader(`{"pinned": false}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatPinned(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+
+	t.Run("Failure - Malformed body", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/pin", strings.NewReader(`{"pinned": "yes"}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatPinned(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockChatSvc.AssertNotCalled(t, "SetChatPinned", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatHandler_UpdateChatTags tests the PUT /v1/chats/{chatID}/tags endpoint.
+func TestChatHandler_UpdateChatTags(t *testing.T) {
+	params := map[string]string{"chatID": "chat1"}
+
+	t.Run("Success - Returns the stored tags", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatTags", mock.Anything, "chat1", []string{"work", "golang", "work"}).
+			Return([]string{"golang", "work"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/tags", strings.NewReader(`{"tags": ["work", "golang", "work"]}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatTags(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"tags": ["golang", "work"]}`, rr.Body.String())
+	})
+
+	t.Run("Failure - Invalid tag", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatTags", mock.Anything, "chat1", []string{"Not Valid"}).
+			Return(nil, fmt.Errorf("%w: invalid tag", app_errors.ErrValidation)).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/tags", strings.NewReader(`{"tags": ["Not Valid"]}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatTags(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("SetChatTags", mock.Anything, "chat1", []string{"work"}).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/tags", strings.NewReader(`{"tags": ["work"]}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatTags(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+
+	t.Run("Failure - Malformed body", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/chats/chat1/tags", strings.NewReader(`{"tags": "work"}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.UpdateChatTags(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockChatSvc.AssertNotCalled(t, "SetChatTags", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatHandler_GetChatMessages tests the GET /v1/chats/{chatID}/messages endpoint.
+func TestChatHandler_GetChatMessages(t *testing.T) {
+	params := map[string]string{"chatID": "chat1"}
+
+	t.Run("Success - Query parameters are parsed", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		mockChatSvc.On("GetChatMessages", mock.Anything, "chat1", 20, "m21").
+			Return(&model.MessagePage{Messages: []model.Message{{ID: "m1"}}, HasMore: true}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/messages?limit=20&before=m21", nil)
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.GetChatMessages(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var page model.MessagePage
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		assert.True(t, page.HasMore)
+	})
+
+	for _, query := range []string{"limit=abc", "limit=-1"} {
+		t.Run("Failure - Invalid query "+query, func(t *testing.T) {
+			handler, mockChatSvc, _ := setupChatHandler(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/messages?"+query, nil)
+			req = addChiURLParams(req, params)
+			rr := httptest.NewRecorder()
+			handler.GetChatMessages(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+			mockChatSvc.AssertNotCalled(t, "GetChatMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestChatHandler_GetChatTree(t *testing.T) {
+	params := map[string]string{"chatID": "chat1"}
+
+	t.Run("Success - Query parameters are parsed", func(t *testing.T) {
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		opts := model.ChatTreeOptions{Nested: true, Since: &since, Depth: 3}
+		tree := &model.ChatTree{Chat: model.Chat{ID: "chat1"}, Roots: []model.MessageNode{{Message: model.Message{ID: "m1"}}}}
+		mockChatSvc.On("GetChatTree", mock.Anything, "chat1", opts).Return(tree, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/tree?nested=true&since=2024-01-01T12:00:00Z&depth=3", nil)
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.GetChatTree(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got model.ChatTree
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		require.Len(t, got.Roots, 1)
+		assert.Equal(t, "m1", got.Roots[0].ID)
+	})
+
+	for _, query := range []string{"nested=maybe", "depth=-1", "depth=abc", "since=yesterday"} {
+		t.Run("Failure - Invalid query "+query, func(t *testing.T) {
+			handler, mockChatSvc, _ := setupChatHandler(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/chats/chat1/tree?"+query, nil)
+			req = addChiURLParams(req, params)
+			rr := httptest.NewRecorder()
+			handler.GetChatTree(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+			mockChatSvc.AssertNotCalled(t, "GetChatTree", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestChatHandler_StreamHeartbeats verifies that keep-alive comments fill the silence
+// before the first chunk and stop once the stream is complete.
+//
+// TECHNIQUE: The mocked service waits several heartbeat intervals before sending
+// its only chunk, simulating a model that is still loading.
+func TestChatHandler_StreamHeartbeats(t *testing.T) {
+	slowStream := func(args mock.Arguments) {
+		streamChan := args.Get(2).(chan<- model.StreamResponse)
+		time.Sleep(60 * time.Millisecond)
+		streamChan <- model.StreamResponse{Content: "Hello", Done: true}
+		close(streamChan)
+	}
+
+	t.Run("Heartbeat is sent while waiting for the first chunk", func(t *testing.T) {
+		mockChatSvc := mocks.NewMockChatService(t)
+		handler := api.NewChatHandler(mockChatSvc, mocks.NewMockSettingsService(t), api.ChatHandlerConfig{HeartbeatInterval: 10 * time.Millisecond})
+		mockChatSvc.On("HandleNewMessage", mock.Anything, mock.Anything, mock.Anything).Run(slowStream).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/messages", strings.NewReader(`{"content": "hello"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleStreamMessage(rr, req)
+
+		body := rr.Body.String()
+		heartbeat := strings.Index(body, ": keep-alive\n\n")
+		data := strings.Index(body, "data: ")
+		require.GreaterOrEqual(t, heartbeat, 0, "expected a heartbeat, got %q", body)
+		assert.Less(t, heartbeat, data)
+		assert.True(t, strings.HasSuffix(body, "\"done\":true}\n\n"), "no heartbeat may follow the last chunk")
+	})
+
+	t.Run("Heartbeats can be disabled", func(t *testing.T) {
+		mockChatSvc := mocks.NewMockChatService(t)
+		handler := api.NewChatHandler(mockChatSvc, mocks.NewMockSettingsService(t), api.ChatHandlerConfig{HeartbeatInterval: -1})
+		mockChatSvc.On("RegenerateMessage", mock.Anything, "chat1", "msg1", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(4).(chan<- model.StreamResponse)
+				time.Sleep(30 * time.Millisecond)
+				close(streamChan)
+			}).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/chat1/messages/msg1/regenerate", strings.NewReader(`{}`))
+		req = addChiURLParams(req, map[string]string{"chatID": "chat1", "messageID": "msg1"})
+		rr := httptest.NewRecorder()
+		handler.HandleRegenerateMessage(rr, req)
+
+		assert.NotContains(t, rr.Body.String(), "keep-alive")
 	})
 }
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// LimitRequestBody is a middleware that caps request bodies at limit bytes, so
+// that a huge body cannot exhaust memory while it is decoded. Handlers decode
+// with decodeJSON, which reports a body over the limit as ErrTooLarge; a limit
+// of 0 or less disables the cap.
+func LimitRequestBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limit > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decodeJSON decodes a JSON request body into v. A body over the limit of
+// LimitRequestBody is ErrTooLarge and any other failure ErrValidation.
+func decodeJSON(r *http.Request, v any) error {
+	return decodeBody(r, v, false)
+}
+
+// decodeOptionalJSON is decodeJSON for a body that may be left out, which
+// leaves v unchanged.
+func decodeOptionalJSON(r *http.Request, v any) error {
+	return decodeBody(r, v, true)
+}
+
+func decodeBody(r *http.Request, v any, optional bool) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil || (optional && errors.Is(err, io.EOF)) {
+		return nil
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: the request body exceeds %d bytes", app_errors.ErrTooLarge, tooLarge.Limit)
+	}
+	return app_errors.ErrValidation
+}
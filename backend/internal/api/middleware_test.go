@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"flow-ai/backend/internal/api"
+)
+
+// TestLimitRequestBody verifies that a body over the limit is rejected with a
+// 413 before it reaches a service.
+func TestLimitRequestBody(t *testing.T) {
+	oversized := `{"content": "` + strings.Repeat("a", 100) + `"}`
+
+	t.Run("JSON route", func(t *testing.T) {
+		// ARRANGE: The mocks have no expectations, so a call to a service fails the test.
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(oversized))
+		rr := httptest.NewRecorder()
+
+		// ACT
+		api.LimitRequestBody(64)(http.HandlerFunc(handler.HandleCreateChat)).ServeHTTP(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeTooLarge)
+	})
+
+	t.Run("SSE route", func(t *testing.T) {
+		handler, _, _ := setupChatHandler(t)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/messages", strings.NewReader(oversized))
+		rr := httptest.NewRecorder()
+
+		api.LimitRequestBody(64)(http.HandlerFunc(handler.HandleStreamMessage)).ServeHTTP(rr, req)
+
+		// ASSERT: The error is also sent as a stream event, for EventSource clients.
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Contains(t, rr.Body.String(), "event: error")
+		assert.Contains(t, rr.Body.String(), api.ErrorCodeTooLarge)
+	})
+
+	t.Run("A body within the limit passes", func(t *testing.T) {
+		var received string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct{ Content string }
+			if assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+				received = body.Content
+			}
+		})
+		req := httptest.NewRequest(http.MethodPost, "/v1/chats/messages", strings.NewReader(`{"content": "hi"}`))
+
+		api.LimitRequestBody(64)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "hi", received)
+	})
+}
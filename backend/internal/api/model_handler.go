@@ -1,13 +1,20 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 
 	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/interfaces"
 	"flow-ai/backend/internal/llm"
+	_ "flow-ai/backend/internal/model" // Resolves the types of the swag annotations.
+	"flow-ai/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
 )
 
 // ModelHandler handles HTTP requests for managing local Ollama models.
@@ -23,7 +30,7 @@ func NewModelHandler(svc interfaces.ModelService) *ModelHandler {
 
 // HandleListModels godoc
 // @Summary      List local models
-// @Description  Gets a list of all models available locally in Ollama.
+// @Description  Gets a list of all models available locally in Ollama, with their digest, details such as family, parameter size and quantization level, and capabilities such as `vision`, `tools` or `embedding`.
 // @Tags         Models
 // @Produce      json
 // @Success      200  {object}  llm.ListModelsResponse
@@ -38,6 +45,86 @@ func (h *ModelHandler) HandleListModels(w http.ResponseWriter, r *http.Request)
 	respondWithJSON(w, http.StatusOK, models)
 }
 
+// HandleListRunningModels godoc
+// @Summary      List running models
+// @Description  Gets the models Ollama currently holds in memory, with their memory and VRAM usage and when they will be unloaded. A model that is not listed is loaded on its next use, which makes that request slower. If the main model is preloaded, the state of its preload is included.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {object}  service.RunningModels
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/models/running [get]
+func (h *ModelHandler) HandleListRunningModels(w http.ResponseWriter, r *http.Request) {
+	models, err := h.service.ListRunning(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, models)
+}
+
+// HandleSearchModels godoc
+// @Summary      Search the model library
+// @Description  Searches the public Ollama library for models to pull. Each result lists the tags it is published in; the name to pull is "<name>:<tag>". Results are cached for a few minutes.
+// @Tags         Models
+// @Produce      json
+// @Param        q    query     string  false  "Search term; empty lists the most popular models"
+// @Success      200  {array}   llm.RegistryModel
+// @Failure      400  {object}  ErrorResponse
+// @Failure      502  {object}  ErrorResponse "The library could not be reached"
+// @Router       /v1/models/search [get]
+func (h *ModelHandler) HandleSearchModels(w http.ResponseWriter, r *http.Request) {
+	req := service.SearchModelsRequest{Query: r.URL.Query().Get("q")}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	models, err := h.service.Search(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, models)
+}
+
+// HandleOptionsSchema godoc
+// @Summary      Generation option schema
+// @Description  Describes the generation options accepted in `options`, the model defaults and the `default_options` setting: their type, bounds, allowed values, Ollama's default and a description, so that a client can render an input for each of them.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {array}   llm.OptionSchema
+// @Router       /v1/options/schema [get]
+func (h *ModelHandler) HandleOptionsSchema(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, llm.OptionsSchema())
+}
+
+// HandleOllamaStatus godoc
+// @Summary      Ollama connection status
+// @Description  Reports the configured Ollama URL, whether Ollama is reachable, its version and the latency of a version request. An unreachable Ollama is reported with `connected` set to false and the `error`, not as a failed request.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  service.OllamaStatus
+// @Router       /v1/system/ollama [get]
+func (h *ModelHandler) HandleOllamaStatus(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.service.OllamaStatus(r.Context()))
+}
+
+// HandleModelStorage godoc
+// @Summary      Model disk usage
+// @Description  Sums the size of all local models and lists them largest first. If OLLAMA_MODELS_PATH is configured, the free space on the models' volume is included, to help decide what to delete when pulls fail for lack of space.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {object}  service.ModelStorage
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/models/storage [get]
+func (h *ModelHandler) HandleModelStorage(w http.ResponseWriter, r *http.Request) {
+	storage, err := h.service.Storage(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, storage)
+}
+
 // HandleShowModel godoc
 // @Summary      Show model info
 // @Description  Retrieves detailed information about a specific model.
@@ -51,8 +138,8 @@ func (h *ModelHandler) HandleListModels(w http.ResponseWriter, r *http.Request)
 // @Router       /v1/models/show [post]
 func (h *ModelHandler) HandleShowModel(w http.ResponseWriter, r *http.Request) {
 	var req llm.ShowModelRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, app_errors.ErrValidation)
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
 		return
 	}
 	// Note: Validation for the model name itself happens within the Ollama provider,
@@ -65,6 +152,65 @@ func (h *ModelHandler) HandleShowModel(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, info)
 }
 
+// HandleEmbed godoc
+// @Summary      Compute embeddings
+// @Description  Computes an embedding vector for each text of `input` with an embedding model, e.g. for semantic search. The vectors are returned in the order of the input.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        embedRequest  body      service.EmbedRequest  true  "Model and texts"
+// @Success      200           {object}  llm.EmbeddingsResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Router       /v1/embeddings [post]
+func (h *ModelHandler) HandleEmbed(w http.ResponseWriter, r *http.Request) {
+	var req service.EmbedRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	resp, err := h.service.Embed(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// HandleComplete godoc
+// @Summary      Complete text
+// @Description  Generates the text between `prompt` and `suffix` (fill-in-middle), e.g. for code completion. Without a suffix the prompt is continued. A suffix needs a model that supports fill-in-middle and is sent to Ollama's /api/generate.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        completeRequest  body      service.CompleteRequest  true  "Model, prompt and suffix"
+// @Success      200              {object}  llm.GenerateResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      501              {object}  ErrorResponse
+// @Router       /v1/complete [post]
+func (h *ModelHandler) HandleComplete(w http.ResponseWriter, r *http.Request) {
+	var req service.CompleteRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	resp, err := h.service.Complete(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
 // HandleDeleteModel godoc
 // @Summary      Delete a local model
 // @Description  Deletes a model from the local Ollama storage.
@@ -79,8 +225,8 @@ func (h *ModelHandler) HandleShowModel(w http.ResponseWriter, r *http.Request) {
 // @Router       /v1/models [delete]
 func (h *ModelHandler) HandleDeleteModel(w http.ResponseWriter, r *http.Request) {
 	var req llm.DeleteModelRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, app_errors.ErrValidation)
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
 		return
 	}
 	if err := h.service.Delete(r.Context(), &req); err != nil {
@@ -90,6 +236,197 @@ func (h *ModelHandler) HandleDeleteModel(w http.ResponseWriter, r *http.Request)
 	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
 }
 
+// HandleCopyModel godoc
+// @Summary      Copy a local model
+// @Description  Copies a local model under a new name, e.g. to keep a snapshot before editing its Modelfile.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        modelRequest  body      llm.CopyModelRequest  true  "Source and destination model names"
+// @Success      200           {object}  StatusResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /v1/models/copy [post]
+func (h *ModelHandler) HandleCopyModel(w http.ResponseWriter, r *http.Request) {
+	var req llm.CopyModelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := h.service.Copy(r.Context(), &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
+
+// HandleCheckBlob godoc
+// @Summary      Check an uploaded file
+// @Description  Reports whether a file, such as a GGUF model, has been uploaded as a blob: 200 if it exists, 404 if it does not. A model can be created from uploaded files with the `files` of `/models/create`.
+// @Tags         Models
+// @Param        digest  path  string  true  "SHA-256 digest of the file, e.g. sha256:432f31..."
+// @Success      200
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /v1/models/blobs/{digest} [head]
+func (h *ModelHandler) HandleCheckBlob(w http.ResponseWriter, r *http.Request) {
+	digest := chi.URLParam(r, "digest")
+	exists, err := h.service.CheckBlob(r.Context(), digest)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if !exists {
+		respondWithError(w, fmt.Errorf("%w: blob '%s'", app_errors.ErrNotFound, digest))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandlePushBlob godoc
+// @Summary      Upload a file
+// @Description  Uploads the raw request body, such as a GGUF model, as a blob. The digest must be the SHA-256 of the content; a mismatch is a 400. Uploading an existing blob again is harmless.
+// @Tags         Models
+// @Accept       octet-stream
+// @Produce      json
+// @Param        digest  path      string  true  "SHA-256 digest of the file, e.g. sha256:432f31..."
+// @Success      201     {object}  StatusResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/models/blobs/{digest} [post]
+func (h *ModelHandler) HandlePushBlob(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.PushBlob(r.Context(), chi.URLParam(r, "digest"), r.Body); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, StatusResponse{Status: "ok"})
+}
+
+// HandleGetModelDefaults godoc
+// @Summary      Get the default options of a model
+// @Description  Returns the generation options applied to every request for the model. A model without defaults has empty options.
+// @Tags         Models
+// @Produce      json
+// @Param        name  path      string  true  "Model name, URL-encoded (e.g. qwen3%3A14b)"
+// @Success      200   {object}  service.ModelDefaults
+// @Failure      500   {object}  ErrorResponse
+// @Router       /v1/models/{name}/defaults [get]
+func (h *ModelHandler) HandleGetModelDefaults(w http.ResponseWriter, r *http.Request) {
+	name, err := modelNameParam(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	defaults, err := h.service.GetDefaults(r.Context(), name)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, defaults)
+}
+
+// HandleSetModelDefaults godoc
+// @Summary      Set the default options of a model
+// @Description  Replaces the generation options applied to every request for the model, e.g. its temperature or context size. Options of a request take precedence over the model's defaults, which take precedence over the global `default_options` setting. An empty object removes all defaults.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        name     path      string              true  "Model name, URL-encoded (e.g. qwen3%3A14b)"
+// @Param        options  body      llm.RequestOptions  true  "Default options"
+// @Success      200      {object}  service.ModelDefaults
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse "The model is not available locally"
+// @Failure      500      {object}  ErrorResponse
+// @Router       /v1/models/{name}/defaults [put]
+func (h *ModelHandler) HandleSetModelDefaults(w http.ResponseWriter, r *http.Request) {
+	name, err := modelNameParam(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	var opts llm.RequestOptions
+	if err := decodeJSON(r, &opts); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&opts); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	defaults, err := h.service.SetDefaults(r.Context(), name, &opts)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, defaults)
+}
+
+// HandleBenchmarkModel godoc
+// @Summary      Benchmark a model
+// @Description  Runs a fixed short prompt through a local model several times and returns the medians of the generation speed, the load time and the time to the first token. The body is optional. Only one benchmark runs at a time; a second request waits for the first one. Closing the connection cancels the benchmark. The result is stored, and the model list shows the speed of the last benchmark.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        name     path      string                    true   "Model name, URL-encoded (e.g. qwen3%3A14b)"
+// @Param        request  body      service.BenchmarkRequest  false  "Benchmark options"
+// @Success      200      {object}  model.ModelBenchmark
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse "The model is not available locally"
+// @Failure      500      {object}  ErrorResponse
+// @Router       /v1/models/{name}/benchmark [post]
+func (h *ModelHandler) HandleBenchmarkModel(w http.ResponseWriter, r *http.Request) {
+	name, err := modelNameParam(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	var req service.BenchmarkRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	result, err := h.service.Benchmark(r.Context(), name, &req)
+	if err != nil {
+		if r.Context().Err() != nil {
+			slog.Info("Model benchmark cancelled by the client", "model", name)
+			return
+		}
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// modelNameParam reads the `{name}` URL parameter. Names of models from other
+// registries contain slashes, which clients have to encode.
+func modelNameParam(r *http.Request) (string, error) {
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || name == "" {
+		return "", fmt.Errorf("%w: invalid model name", app_errors.ErrValidation)
+	}
+	return name, nil
+}
+
+// PullSummary is the last event of a model pull stream. It tells the client how
+// the pull ended, as the progress events alone do not distinguish a finished
+// download from one that failed.
+type PullSummary struct {
+	Done  bool   `json:"done" example:"true"`
+	Model string `json:"model" example:"mistral:7b"`
+	// Status is "success", "error" or "cancelled".
+	Status string `json:"status" example:"success"`
+	Error  string `json:"error,omitempty"`
+}
+
 // HandlePullModel godoc
 // @Summary      Pull a new model
 // @Description  Downloads a model from the Ollama registry. This is a streaming endpoint.
@@ -97,6 +434,8 @@ func (h *ModelHandler) HandleDeleteModel(w http.ResponseWriter, r *http.Request)
 // @Accept       json
 // @Produce      application/json
 // @Description  Downloads a model from the Ollama registry. This is a streaming endpoint (SSE).
+// @Description  The download runs in the background: disconnecting only stops the stream, and pulling the same model again reattaches to it, unless PULL_CANCEL_ON_DISCONNECT is set.
+// @Description  The last event is a `PullSummary` with `done` set.
 // @Param        modelRequest  body      llm.PullModelRequest  true  "Model Name to Pull"
 // @Success      200           {object}  llm.PullStatus "Stream of progress status"
 // @Failure      400           {object}  ErrorResponse "Sent as a stream error event"
@@ -107,40 +446,305 @@ func (h *ModelHandler) HandlePullModel(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 
 	var req llm.PullModelRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(r, &req); err != nil {
 		slog.Error("Error decoding request body for model pull", "error", err)
-		sendStreamError(w, "Invalid request body")
+		sendDecodeStreamError(w, err, "Invalid request body")
+		return
+	}
+
+	h.streamPull(w, r, req.Name, func(ctx context.Context, ch chan<- llm.PullStatus) error {
+		return h.service.Pull(ctx, &req, ch)
+	})
+}
+
+// streamPull streams the progress of a pull of a model, followed by a summary. A
+// model that is not found before anything was streamed is reported as a 404.
+func (h *ModelHandler) streamPull(w http.ResponseWriter, r *http.Request, modelName string, pull func(ctx context.Context, ch chan<- llm.PullStatus) error) {
+	outcome, ok := h.forwardPull(w, r, modelName, pull, false)
+	if !ok {
 		return
 	}
+	if !outcome.streamed && errors.Is(outcome.err, app_errors.ErrNotFound) {
+		respondWithError(w, outcome.err)
+		return
+	}
+	if err := writeStreamEvent(w, outcome.summary); err != nil {
+		slog.Warn("Could not write model pull summary, client likely disconnected.", "error", err)
+		return
+	}
+	slog.Info("Finished streaming model pull.", "model", modelName, "status", outcome.summary.Status)
+}
+
+// pullOutcome is how the pull of a single model ended.
+type pullOutcome struct {
+	summary PullSummary
+	// streamed reports whether any progress event was sent.
+	streamed bool
+	err      error
+}
 
+// forwardPull streams the progress events of a pull of a model and returns how
+// it ended. With tagged set, each event carries the name of the model. It returns
+// false if the client disconnected.
+func (h *ModelHandler) forwardPull(w http.ResponseWriter, r *http.Request, modelName string, pull func(ctx context.Context, ch chan<- llm.PullStatus) error, tagged bool) (pullOutcome, bool) {
 	streamChan := make(chan llm.PullStatus)
+	errChan := make(chan error, 1)
 	// The service call is launched in a goroutine to allow the handler to immediately
-	// start listening for and processing stream events.
+	// start listening for and processing stream events. It is bound to the request,
+	// so that a disconnecting client detaches from the pull.
 	go func() {
-		// Errors from the service are logged here, as they are also propagated
-		// through the stream channel to the client.
-		if err := h.service.Pull(r.Context(), &req, streamChan); err != nil {
-			slog.Error("Error from model pull service", "model", req.Name, "error", err)
+		err := pull(r.Context(), streamChan)
+		switch {
+		case err == nil:
+		case r.Context().Err() != nil:
+			slog.Info("Detached from model pull.", "model", modelName)
+		default:
+			slog.Error("Error from model pull service", "model", modelName, "error", err)
 		}
+		errChan <- err
 	}()
 
+	var last llm.PullStatus
+	streamed := false
 	for chunk := range streamChan {
 		if r.Context().Err() != nil {
-			slog.Info("Client disconnected during model pull.", "model", req.Name)
-			break
+			slog.Info("Client disconnected during model pull.", "model", modelName)
+			return pullOutcome{}, false
 		}
 
 		// The stream itself can contain error messages from the provider.
 		// These are logged for visibility on the server-side.
 		if chunk.Error != "" {
-			slog.Warn("Received an error in the pull stream", "model", req.Name, "error", chunk.Error)
+			slog.Warn("Received an error in the pull stream", "model", modelName, "error", chunk.Error)
 		}
 
-		if err := writeStreamEvent(w, chunk); err != nil {
+		last, streamed = chunk, true
+		var event any = chunk
+		if tagged {
+			event = BatchPullStatus{Model: modelName, PullStatus: chunk}
+		}
+		if err := writeStreamEvent(w, event); err != nil {
 			slog.Warn("Could not write to model pull stream, client likely disconnected.", "error", err)
+			return pullOutcome{}, false
+		}
+	}
+
+	err := <-errChan
+	if r.Context().Err() != nil {
+		return pullOutcome{}, false
+	}
+	return pullOutcome{summary: pullSummary(modelName, last, err), streamed: streamed, err: err}, true
+}
+
+// PullBatchRequest is the DTO for pulling several models at once.
+type PullBatchRequest struct {
+	Names []string `json:"names" validate:"required,min=1,max=20,unique,dive,required" example:"qwen3:8b,nomic-embed-text"`
+}
+
+// BatchPullStatus is a progress event of a batch pull, tagged with the model it
+// belongs to.
+type BatchPullStatus struct {
+	Model string `json:"model" example:"qwen3:8b"`
+	llm.PullStatus
+}
+
+// PullBatchSummary is the last event of a batch pull.
+type PullBatchSummary struct {
+	BatchDone bool `json:"batch_done" example:"true"`
+	// Results holds the summary of each model, in the order of the request.
+	Results []PullSummary `json:"results"`
+}
+
+// HandlePullBatch godoc
+// @Summary      Pull several models
+// @Description  Pulls a list of models one after another, e.g. to set up a new machine. This is a streaming endpoint (SSE).
+// @Description  Progress events are tagged with their `model`. When a model is finished, its `PullSummary` is sent; a failed model does not stop the batch. The last event is a `PullBatchSummary` with the results of all models.
+// @Description  Disconnecting stops the batch; a model that is being pulled finishes in the background like a single pull.
+// @Tags         Models
+// @Accept       json
+// @Produce      application/json
+// @Param        batchRequest  body      PullBatchRequest  true  "Names of the models to pull"
+// @Success      200           {object}  BatchPullStatus "Stream of progress status"
+// @Failure      400           {object}  ErrorResponse
+// @Router       /v1/models/pull-batch [post]
+func (h *ModelHandler) HandlePullBatch(w http.ResponseWriter, r *http.Request) {
+	var req PullBatchRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	summary := PullBatchSummary{BatchDone: true, Results: make([]PullSummary, 0, len(req.Names))}
+	for _, name := range req.Names {
+		// Each model goes through the regular pull, so that it shares a download
+		// that is already running for the same model.
+		outcome, ok := h.forwardPull(w, r, name, func(ctx context.Context, ch chan<- llm.PullStatus) error {
+			return h.service.Pull(ctx, &llm.PullModelRequest{Name: name}, ch)
+		}, true)
+		if !ok {
+			slog.Info("Client disconnected during batch pull, skipping the remaining models.", "model", name)
+			return
+		}
+		if err := writeStreamEvent(w, outcome.summary); err != nil {
+			slog.Warn("Could not write model pull summary, client likely disconnected.", "error", err)
+			return
+		}
+		summary.Results = append(summary.Results, outcome.summary)
+	}
+	if err := writeStreamEvent(w, summary); err != nil {
+		slog.Warn("Could not write batch pull summary, client likely disconnected.", "error", err)
+		return
+	}
+	slog.Info("Finished streaming batch pull.", "models", len(req.Names))
+}
+
+// HandleListModelUpdates godoc
+// @Summary      List model updates
+// @Description  Gets the result of the last check of the installed models for newer versions in the registry. The models are checked in the background once a day (MODEL_UPDATE_CHECK_INTERVAL). A model that could not be checked, e.g. one created locally, carries an `error`.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {object}  service.ModelUpdates
+// @Router       /v1/models/updates [get]
+func (h *ModelHandler) HandleListModelUpdates(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.service.Updates(r.Context()))
+}
+
+// HandleUpdateModel godoc
+// @Summary      Update a model
+// @Description  Pulls the latest version of an installed model. This is a streaming endpoint (SSE) that behaves like a pull of the model, including the summary as the last event.
+// @Tags         Models
+// @Produce      application/json
+// @Param        name  path      string  true  "Model name, e.g. qwen3:8b (URL-encoded)"
+// @Success      200   {object}  llm.PullStatus "Stream of progress status"
+// @Failure      404   {object}  ErrorResponse
+// @Router       /v1/models/{name}/update [post]
+func (h *ModelHandler) HandleUpdateModel(w http.ResponseWriter, r *http.Request) {
+	name, err := modelNameParam(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	h.streamPull(w, r, name, func(ctx context.Context, ch chan<- llm.PullStatus) error {
+		return h.service.Update(ctx, name, ch)
+	})
+}
+
+// pullSummary describes how a pull ended from its last progress event and the
+// error of the service.
+func pullSummary(model string, last llm.PullStatus, err error) PullSummary {
+	summary := PullSummary{Done: true, Model: model, Status: "success"}
+	switch {
+	case last.Status == service.PullCancelledStatus:
+		summary.Status = "cancelled"
+	case last.Error != "":
+		summary.Status, summary.Error = "error", last.Error
+	case err != nil:
+		summary.Status, summary.Error = "error", err.Error()
+	}
+	return summary
+}
+
+// HandleListPulls godoc
+// @Summary      List in-flight model pulls
+// @Description  Lists the models that are currently being pulled with their latest progress. A client can attach to a listed pull by pulling the same model again.
+// @Tags         Models
+// @Produce      json
+// @Success      200  {array}   service.PullJobStatus
+// @Router       /v1/models/pull/status [get]
+func (h *ModelHandler) HandleListPulls(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.service.ListPulls(r.Context()))
+}
+
+// HandleCancelPull godoc
+// @Summary      Cancel a model pull
+// @Description  Aborts the in-flight download of a model. Every stream of that pull ends with a `cancelled` status.
+// @Tags         Models
+// @Accept       json
+// @Produce      json
+// @Param        modelRequest  body      service.CancelPullRequest  true  "Name of the model being pulled"
+// @Success      200           {object}  StatusResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse "The model is not being pulled"
+// @Router       /v1/models/pull/cancel [post]
+func (h *ModelHandler) HandleCancelPull(w http.ResponseWriter, r *http.Request) {
+	var req service.CancelPullRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := h.service.CancelPull(r.Context(), &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
+
+// HandleCreateModel godoc
+// @Summary      Create a custom model
+// @Description  Creates a model from a Modelfile, from an existing model with its own system prompt and parameters, or from uploaded `files` such as a GGUF file. This is a streaming endpoint (SSE); an invalid Modelfile or a file that was not uploaded is reported as a status with `error` set.
+// @Tags         Models
+// @Accept       json
+// @Produce      application/json
+// @Param        modelRequest  body      llm.CreateModelRequest  true  "Model to create"
+// @Success      200           {object}  llm.PullStatus "Stream of progress status"
+// @Failure      400           {object}  ErrorResponse "Sent as a stream error event"
+// @Router       /v1/models/create [post]
+func (h *ModelHandler) HandleCreateModel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var req llm.CreateModelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		slog.Error("Error decoding request body for model create", "error", err)
+		sendDecodeStreamError(w, err, "Invalid request body")
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		sendStreamError(w, ErrorCodeValidation, err.Error())
+		return
+	}
+
+	streamChan := make(chan llm.PullStatus)
+	go func() {
+		// The provider sends its errors through the stream as well, so they are
+		// only logged here.
+		if err := h.service.Create(r.Context(), &req, streamChan); err != nil {
+			slog.Error("Error from model create service", "model", req.Name, "error", err)
+		}
+	}()
+
+	for chunk := range streamChan {
+		if r.Context().Err() != nil {
+			slog.Info("Client disconnected during model create.", "model", req.Name)
+			break
+		}
+
+		if chunk.Error != "" {
+			slog.Warn("Received an error in the create stream", "model", req.Name, "error", chunk.Error)
+		}
+
+		if err := writeStreamEvent(w, chunk); err != nil {
+			slog.Warn("Could not write to model create stream, client likely disconnected.", "error", err)
 			break
 		}
 	}
 
-	slog.Info("Finished streaming model pull.", "model", req.Name)
+	slog.Info("Finished streaming model create.", "model", req.Name)
 }
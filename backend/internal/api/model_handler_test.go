@@ -1,20 +1,27 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"flow-ai/backend/internal/api"
 	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/interfaces/mocks"
 	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
 )
 
 // setupModelHandler is a test helper that provides a ModelHandler instance
@@ -36,7 +43,11 @@ func TestModelHandler_HandleListModels(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// ARRANGE: Set up the handler and configure the mock service to return a sample response.
 		handler, mockSvc := setupModelHandler(t)
-		expectedResp := &llm.ListModelsResponse{Models: []llm.Model{{Name: "test-model"}}}
+		expectedResp := &llm.ListModelsResponse{Models: []llm.Model{{
+			Name:    "test-model",
+			Digest:  "500a1f06",
+			Details: llm.ModelDetails{Family: "qwen3", ParameterSize: "8.2B", QuantizationLevel: "Q4_K_M"},
+		}}}
 		mockSvc.On("List", mock.Anything).Return(expectedResp, nil).Once()
 
 		// ACT: Simulate an incoming HTTP request.
@@ -49,7 +60,7 @@ func TestModelHandler_HandleListModels(t *testing.T) {
 		var resp llm.ListModelsResponse
 		err := json.Unmarshal(rr.Body.Bytes(), &resp)
 		assert.NoError(t, err)
-		assert.Equal(t, "test-model", resp.Models[0].Name)
+		assert.Equal(t, expectedResp.Models, resp.Models)
 		mockSvc.AssertExpectations(t)
 	})
 
@@ -65,10 +76,154 @@ func TestModelHandler_HandleListModels(t *testing.T) {
 
 		// ASSERT: Verify the handler returns a 500 Internal Server Error.
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeInternal)
 		mockSvc.AssertExpectations(t)
 	})
 }
 
+// TestModelHandler_HandleModelStorage tests the GET /v1/models/storage endpoint.
+func TestModelHandler_HandleModelStorage(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		free := uint64(5000)
+		mockSvc.On("Storage", mock.Anything).Return(&service.ModelStorage{
+			TotalBytes: 9000,
+			FreeBytes:  &free,
+			Models:     []service.ModelStorageEntry{{Name: "big", Size: 6000}, {Name: "small", Size: 3000}},
+		}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/storage", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleModelStorage(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"total_bytes": 9000, "free_bytes": 5000, "models": [{"name": "big", "size": 6000}, {"name": "small", "size": 3000}]}`, rr.Body.String())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Storage", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/storage", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleModelStorage(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+// TestModelHandler_HandleSearchModels tests the GET /v1/models/search endpoint.
+func TestModelHandler_HandleSearchModels(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Search", mock.Anything, &service.SearchModelsRequest{Query: "llama"}).Return([]llm.RegistryModel{
+			{Name: "llama3.1", Description: "Llama 3.1", Pulls: 93500000, Tags: []string{"8b"}, Capabilities: []string{"tools"}},
+		}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/search?q=llama", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleSearchModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[{"name": "llama3.1", "description": "Llama 3.1", "pulls": 93500000, "tags": ["8b"], "capabilities": ["tools"]}]`, rr.Body.String())
+	})
+
+	t.Run("Failure - Query too long", func(t *testing.T) {
+		// ARRANGE: The mock has no expectations; the service must not be called.
+		handler, _ := setupModelHandler(t)
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/search?q="+strings.Repeat("a", 101), nil)
+		rr := httptest.NewRecorder()
+		handler.HandleSearchModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Failure - Registry unavailable", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Search", mock.Anything, mock.Anything).Return(nil, app_errors.ErrUnavailable).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/search", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleSearchModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusBadGateway, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"upstream_unavailable"`)
+	})
+}
+
+// TestModelHandler_HandleListRunningModels tests the GET /v1/models/running endpoint.
+func TestModelHandler_HandleListRunningModels(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		expiresAt := time.Date(2025, 9, 8, 14, 5, 0, 0, time.UTC)
+		mockSvc.On("ListRunning", mock.Anything).Return(&service.RunningModels{
+			Models: []llm.RunningModel{{Name: "qwen3:8b", Size: 6000, SizeVRAM: 4000, ExpiresAt: expiresAt}},
+		}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/running", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListRunningModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"models": [{"name": "qwen3:8b", "size": 6000, "size_vram": 4000, "expires_at": "2025-09-08T14:05:00Z"}]}`, rr.Body.String())
+	})
+
+	t.Run("Success - With the preload of the main model", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		startedAt := time.Date(2025, 9, 8, 14, 5, 0, 0, time.UTC)
+		mockSvc.On("ListRunning", mock.Anything).Return(&service.RunningModels{
+			Models: []llm.RunningModel{},
+			Preload: &service.PreloadStatus{
+				Model: "qwen3:8b", State: service.PreloadFailed, Error: "out of memory", StartedAt: startedAt, FinishedAt: &startedAt,
+			},
+		}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/running", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListRunningModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"models": [], "preload": {"model": "qwen3:8b", "state": "failed", "error": "out of memory",
+			"started_at": "2025-09-08T14:05:00Z", "finished_at": "2025-09-08T14:05:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("ListRunning", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/running", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListRunningModels(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeInternal)
+	})
+}
+
 // TestModelHandler_HandleDeleteModel tests the DELETE /v1/models endpoint.
 //
 // GOAL: Verify the handler correctly parses the request body and calls the
@@ -99,6 +254,72 @@ func TestModelHandler_HandleDeleteModel(t *testing.T) {
 		rr := httptest.NewRecorder()
 		handler.HandleDeleteModel(rr, req)
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+
+	t.Run("Failure - Not supported by the provider", func(t *testing.T) {
+		// GOAL: An OpenAI-compatible API cannot delete models, which is a 501, not a 500.
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Delete", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: deleting models", llm.ErrUnsupported)).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleDeleteModel(rr, httptest.NewRequest(http.MethodDelete, "/v1/models", strings.NewReader(`{"name": "gpt-4o"}`)))
+
+		assert.Equal(t, http.StatusNotImplemented, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeUnsupported)
+	})
+}
+
+// TestModelHandler_HandleCopyModel tests the POST /v1/models/copy endpoint.
+func TestModelHandler_HandleCopyModel(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		reqBody := `{"source": "qwen3:8b", "destination": "qwen3:8b-backup"}`
+		mockSvc.On("Copy", mock.Anything, mock.MatchedBy(func(r *llm.CopyModelRequest) bool {
+			return r.Source == "qwen3:8b" && r.Destination == "qwen3:8b-backup"
+		})).Return(nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/copy", strings.NewReader(reqBody))
+		rr := httptest.NewRecorder()
+		handler.HandleCopyModel(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"status": "ok"}`, rr.Body.String())
+		mockSvc.AssertExpectations(t)
+	})
+
+	for _, body := range []string{
+		`{"source": "qwen3:8b"}`,
+		`{"destination": "qwen3:8b-backup"}`,
+		`{"source": "qwen3:8b", "destination": "qwen3:8b"}`,
+		`{"source":`,
+	} {
+		t.Run("Failure - Invalid request "+body, func(t *testing.T) {
+			handler, mockSvc := setupModelHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/models/copy", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			handler.HandleCopyModel(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+			mockSvc.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Failure - Source not found", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Copy", mock.Anything, mock.Anything).Return(app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/copy", strings.NewReader(`{"source": "missing", "destination": "copy"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCopyModel(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
 	})
 }
 
@@ -141,10 +362,117 @@ func TestModelHandler_HandleShowModel(t *testing.T) {
 
 		// ASSERT: Verify the handler correctly maps the domain error to a 404 Not Found status.
 		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
 		mockSvc.AssertExpectations(t)
 	})
 }
 
+// TestModelHandler_HandleModelDefaults tests the GET and PUT
+// /v1/models/{name}/defaults endpoints.
+func TestModelHandler_HandleModelDefaults(t *testing.T) {
+	t.Run("Get - Encoded model name", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		temperature := float32(0.2)
+		mockSvc.On("GetDefaults", mock.Anything, "qwen3:14b").
+			Return(&service.ModelDefaults{Model: "qwen3:14b", Options: llm.RequestOptions{Temperature: &temperature}}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/qwen3%3A14b/defaults", nil)
+		req = addChiURLParams(req, map[string]string{"name": "qwen3%3A14b"})
+		rr := httptest.NewRecorder()
+		handler.HandleGetModelDefaults(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"model": "qwen3:14b", "options": {"temperature": 0.2}}`, rr.Body.String())
+	})
+
+	t.Run("Set - Success", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("SetDefaults", mock.Anything, "qwen3:14b", mock.MatchedBy(func(o *llm.RequestOptions) bool {
+			return o.NumCtx != nil && *o.NumCtx == 8192
+		})).Return(&service.ModelDefaults{Model: "qwen3:14b"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/qwen3:14b/defaults", strings.NewReader(`{"num_ctx": 8192}`))
+		req = addChiURLParams(req, map[string]string{"name": "qwen3:14b"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelDefaults(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Set - Invalid options", func(t *testing.T) {
+		// ARRANGE: The mock has no expectations; the service must not be called.
+		handler, _ := setupModelHandler(t)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/qwen3:14b/defaults", strings.NewReader(`{"num_ctx": 0}`))
+		req = addChiURLParams(req, map[string]string{"name": "qwen3:14b"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelDefaults(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Set - Unknown model", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("SetDefaults", mock.Anything, "missing", mock.Anything).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/models/missing/defaults", strings.NewReader(`{}`))
+		req = addChiURLParams(req, map[string]string{"name": "missing"})
+		rr := httptest.NewRecorder()
+		handler.HandleSetModelDefaults(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestModelHandler_HandleBenchmarkModel tests the POST /v1/models/{name}/benchmark endpoint.
+func TestModelHandler_HandleBenchmarkModel(t *testing.T) {
+	t.Run("Success - Without a body", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Benchmark", mock.Anything, "gemma3:4b", &service.BenchmarkRequest{}).
+			Return(&model.ModelBenchmark{Model: "gemma3:4b", Runs: 3, TokensPerSecond: 42.5, LoadMs: 12, FirstTokenMs: 85}, nil).Once()
+
+		// ACT
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/gemma3%3A4b/benchmark", nil)
+		req = addChiURLParams(req, map[string]string{"name": "gemma3%3A4b"})
+		rr := httptest.NewRecorder()
+		handler.HandleBenchmarkModel(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"tokens_per_second":42.5`)
+	})
+
+	t.Run("Failure - Too many runs", func(t *testing.T) {
+		// ARRANGE: The mock has no expectations; the service must not be called.
+		handler, _ := setupModelHandler(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/gemma3:4b/benchmark", strings.NewReader(`{"runs": 100}`))
+		req = addChiURLParams(req, map[string]string{"name": "gemma3:4b"})
+		rr := httptest.NewRecorder()
+		handler.HandleBenchmarkModel(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Failure - Unknown model", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Benchmark", mock.Anything, "missing", &service.BenchmarkRequest{Runs: 2}).Return(nil, app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/missing/benchmark", strings.NewReader(`{"runs": 2}`))
+		req = addChiURLParams(req, map[string]string{"name": "missing"})
+		rr := httptest.NewRecorder()
+		handler.HandleBenchmarkModel(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
 // TestModelHandler_HandlePullModel tests the streaming POST /v1/models/pull endpoint.
 func TestModelHandler_HandlePullModel(t *testing.T) {
 	t.Run("Success - Service is called", func(t *testing.T) {
@@ -167,6 +495,80 @@ func TestModelHandler_HandlePullModel(t *testing.T) {
 		mockSvc.AssertExpectations(t)
 	})
 
+	t.Run("The last event summarizes the pull", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			statuses []llm.PullStatus
+			err      error
+			summary  string
+		}{
+			{"Success", []llm.PullStatus{{Status: "success"}}, nil, `{"done": true, "model": "test-model", "status": "success"}`},
+			{"Error event", []llm.PullStatus{{Error: "file does not exist"}}, errors.New("file does not exist"), `{"done": true, "model": "test-model", "status": "error", "error": "file does not exist"}`},
+			{"Connection error", nil, errors.New("connection refused"), `{"done": true, "model": "test-model", "status": "error", "error": "connection refused"}`},
+			{"Cancelled", []llm.PullStatus{{Status: "cancelled"}}, context.Canceled, `{"done": true, "model": "test-model", "status": "cancelled"}`},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ARRANGE
+				handler, mockSvc := setupModelHandler(t)
+				mockSvc.On("Pull", mock.Anything, mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						streamChan := args.Get(2).(chan<- llm.PullStatus)
+						for _, status := range tc.statuses {
+							streamChan <- status
+						}
+						close(streamChan)
+					}).Return(tc.err).Once()
+
+				// ACT
+				req := httptest.NewRequest(http.MethodPost, "/v1/models/pull", strings.NewReader(`{"name": "test-model"}`))
+				rr := httptest.NewRecorder()
+				handler.HandlePullModel(rr, req)
+
+				// ASSERT
+				events := strings.Split(strings.TrimSpace(rr.Body.String()), "\n\n")
+				require.Len(t, events, len(tc.statuses)+1)
+				assert.JSONEq(t, tc.summary, strings.TrimPrefix(events[len(events)-1], "data: "))
+			})
+		}
+	})
+
+	t.Run("Client disconnect cancels the service call", func(t *testing.T) {
+		// ARRANGE: The service blocks until the context it was given is cancelled,
+		// like the request to Ollama does.
+		handler, mockSvc := setupModelHandler(t)
+		serviceCancelled := make(chan struct{})
+		mockSvc.On("Pull", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				ctx := args.Get(0).(context.Context)
+				streamChan := args.Get(2).(chan<- llm.PullStatus)
+				defer close(streamChan)
+				streamChan <- llm.PullStatus{Status: "downloading"}
+				<-ctx.Done()
+				close(serviceCancelled)
+			}).Return(context.Canceled).Once()
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/pull", strings.NewReader(`{"name": "test-model"}`)).WithContext(ctx)
+		rr := httptest.NewRecorder()
+
+		// ACT: The client disconnects while the pull is running.
+		done := make(chan struct{})
+		go func() {
+			handler.HandlePullModel(rr, req)
+			close(done)
+		}()
+		cancel()
+
+		// ASSERT
+		select {
+		case <-serviceCancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the service call was not cancelled")
+		}
+		<-done
+		assert.NotContains(t, rr.Body.String(), `"done"`, "no summary is written to a disconnected client")
+	})
+
 	t.Run("Failure - Invalid JSON", func(t *testing.T) {
 		handler, _ := setupModelHandler(t)
 		reqBody := `{"name":`
@@ -179,3 +581,387 @@ func TestModelHandler_HandlePullModel(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Invalid request body")
 	})
 }
+
+// TestModelHandler_HandleOptionsSchema tests the GET /v1/options/schema endpoint.
+func TestModelHandler_HandleOptionsSchema(t *testing.T) {
+	handler, _ := setupModelHandler(t)
+
+	rr := httptest.NewRecorder()
+	handler.HandleOptionsSchema(rr, httptest.NewRequest(http.MethodGet, "/v1/options/schema", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var schema []map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &schema))
+	require.NotEmpty(t, schema)
+	assert.Equal(t, "temperature", schema[0]["name"])
+	assert.Equal(t, "number", schema[0]["type"])
+	assert.EqualValues(t, 0, schema[0]["min"])
+	assert.EqualValues(t, 2, schema[0]["max"])
+}
+
+// TestModelHandler_HandleEmbed tests the POST /v1/embeddings endpoint.
+func TestModelHandler_HandleEmbed(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Embed", mock.Anything, &service.EmbedRequest{Model: "nomic-embed-text", Input: []string{"a", "b"}}).
+			Return(&llm.EmbeddingsResponse{Model: "nomic-embed-text", Embeddings: [][]float32{{0.1, 0.2}, {0.3, 0.4}}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleEmbed(rr, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model": "nomic-embed-text", "input": ["a", "b"]}`)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"model": "nomic-embed-text", "embeddings": [[0.1, 0.2], [0.3, 0.4]]}`, rr.Body.String())
+	})
+
+	t.Run("Unknown model", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Embed", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: model 'missing'", app_errors.ErrNotFound)).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleEmbed(rr, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model": "missing", "input": ["a"]}`)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+
+	// WHY: The service is never called for an invalid request; the mock would fail on it.
+	for name, body := range map[string]string{
+		"Missing input":  `{"model": "nomic-embed-text"}`,
+		"Empty input":    `{"model": "nomic-embed-text", "input": []}`,
+		"Empty text":     `{"model": "nomic-embed-text", "input": ["a", ""]}`,
+		"Missing model":  `{"input": ["a"]}`,
+		"Malformed JSON": `{"model":`,
+	} {
+		t.Run("Failure - "+name, func(t *testing.T) {
+			handler, _ := setupModelHandler(t)
+
+			rr := httptest.NewRecorder()
+			handler.HandleEmbed(rr, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+		})
+	}
+}
+
+// TestModelHandler_HandleComplete tests the POST /v1/complete endpoint.
+func TestModelHandler_HandleComplete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Complete", mock.Anything, &service.CompleteRequest{Model: "qwen2.5-coder", Prompt: "def f(n):", Suffix: "# end"}).
+			Return(&llm.GenerateResponse{Model: "qwen2.5-coder", Response: "return n", Done: true}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleComplete(rr, httptest.NewRequest(http.MethodPost, "/v1/complete", strings.NewReader(`{"model": "qwen2.5-coder", "prompt": "def f(n):", "suffix": "# end"}`)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"model": "qwen2.5-coder", "response": "return n", "done": true}`, rr.Body.String())
+	})
+
+	t.Run("Unsupported by the provider", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Complete", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("could not complete: %w", llm.ErrUnsupported)).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleComplete(rr, httptest.NewRequest(http.MethodPost, "/v1/complete", strings.NewReader(`{"model": "gpt-4o", "prompt": "a", "suffix": "b"}`)))
+
+		assert.Equal(t, http.StatusNotImplemented, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeUnsupported)
+	})
+
+	// WHY: The service is never called for an invalid request; the mock would fail on it.
+	for name, body := range map[string]string{
+		"Missing prompt": `{"model": "qwen2.5-coder", "suffix": "# end"}`,
+		"Missing model":  `{"prompt": "def f(n):"}`,
+	} {
+		t.Run("Failure - "+name, func(t *testing.T) {
+			handler, _ := setupModelHandler(t)
+
+			rr := httptest.NewRecorder()
+			handler.HandleComplete(rr, httptest.NewRequest(http.MethodPost, "/v1/complete", strings.NewReader(body)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+		})
+	}
+}
+
+// TestModelHandler_ModelUpdates tests the GET /v1/models/updates and the streaming
+// POST /v1/models/{name}/update endpoints.
+func TestModelHandler_ModelUpdates(t *testing.T) {
+	t.Run("List the last check", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		checkedAt := time.Date(2025, 9, 8, 14, 0, 0, 0, time.UTC)
+		mockSvc.On("Updates", mock.Anything).Return(&service.ModelUpdates{
+			CheckedAt: &checkedAt,
+			Models:    []service.ModelUpdate{{Model: "qwen3:8b", LocalDigest: "old", RemoteDigest: "new", UpdateAvailable: true}},
+		}).Once()
+
+		rr := httptest.NewRecorder()
+		handler.HandleListModelUpdates(rr, httptest.NewRequest(http.MethodGet, "/v1/models/updates", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"checked_at": "2025-09-08T14:00:00Z", "models": [
+			{"model": "qwen3:8b", "local_digest": "old", "remote_digest": "new", "update_available": true}]}`, rr.Body.String())
+	})
+
+	t.Run("Update streams the pull", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Update", mock.Anything, "library/qwen3:8b", mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(2).(chan<- llm.PullStatus)
+				streamChan <- llm.PullStatus{Status: "success"}
+				close(streamChan)
+			}).Return(nil).Once()
+		req := addChiURLParams(httptest.NewRequest(http.MethodPost, "/v1/models/library%2Fqwen3:8b/update", nil),
+			map[string]string{"name": "library%2Fqwen3:8b"})
+		rr := httptest.NewRecorder()
+
+		// ACT
+		handler.HandleUpdateModel(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		events := strings.Split(strings.TrimSpace(rr.Body.String()), "\n\n")
+		require.Len(t, events, 2)
+		assert.JSONEq(t, `{"done": true, "model": "library/qwen3:8b", "status": "success"}`, strings.TrimPrefix(events[1], "data: "))
+	})
+
+	t.Run("Update of a model that is not installed", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Update", mock.Anything, "llama3:70b", mock.Anything).
+			Run(func(args mock.Arguments) { close(args.Get(2).(chan<- llm.PullStatus)) }).
+			Return(fmt.Errorf("%w: model 'llama3:70b'", app_errors.ErrNotFound)).Once()
+		req := addChiURLParams(httptest.NewRequest(http.MethodPost, "/v1/models/llama3:70b/update", nil), map[string]string{"name": "llama3:70b"})
+		rr := httptest.NewRecorder()
+
+		handler.HandleUpdateModel(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+}
+
+// TestModelHandler_HandlePullBatch tests the streaming POST /v1/models/pull-batch endpoint.
+func TestModelHandler_HandlePullBatch(t *testing.T) {
+	t.Run("A failed model does not stop the batch", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("Pull", mock.Anything, &llm.PullModelRequest{Name: "missing:1b"}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(2).(chan<- llm.PullStatus)
+				streamChan <- llm.PullStatus{Error: "pull model manifest: file does not exist"}
+				close(streamChan)
+			}).Return(errors.New("pull model manifest: file does not exist")).Once()
+		mockSvc.On("Pull", mock.Anything, &llm.PullModelRequest{Name: "qwen3:8b"}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(2).(chan<- llm.PullStatus)
+				streamChan <- llm.PullStatus{Status: "downloading", Total: 100, Completed: 50}
+				streamChan <- llm.PullStatus{Status: "success"}
+				close(streamChan)
+			}).Return(nil).Once()
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/pull-batch", strings.NewReader(`{"names": ["missing:1b", "qwen3:8b"]}`))
+		rr := httptest.NewRecorder()
+
+		// ACT
+		handler.HandlePullBatch(rr, req)
+
+		// ASSERT: Progress is tagged with the model, and each model is summarized on its own.
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		events := strings.Split(strings.TrimSpace(rr.Body.String()), "\n\n")
+		expected := []string{
+			`{"model": "missing:1b", "status": "", "error": "pull model manifest: file does not exist"}`,
+			`{"done": true, "model": "missing:1b", "status": "error", "error": "pull model manifest: file does not exist"}`,
+			`{"model": "qwen3:8b", "status": "downloading", "total": 100, "completed": 50}`,
+			`{"model": "qwen3:8b", "status": "success"}`,
+			`{"done": true, "model": "qwen3:8b", "status": "success"}`,
+			`{"batch_done": true, "results": [
+				{"done": true, "model": "missing:1b", "status": "error", "error": "pull model manifest: file does not exist"},
+				{"done": true, "model": "qwen3:8b", "status": "success"}]}`,
+		}
+		require.Len(t, events, len(expected))
+		for i, event := range events {
+			assert.JSONEq(t, expected[i], strings.TrimPrefix(event, "data: "))
+		}
+	})
+
+	for name, body := range map[string]string{
+		"No names":       `{"names": []}`,
+		"Empty name":     `{"names": ["qwen3:8b", ""]}`,
+		"Duplicate name": `{"names": ["qwen3:8b", "qwen3:8b"]}`,
+		"Malformed JSON": `{"names":`,
+	} {
+		t.Run("Failure - "+name, func(t *testing.T) {
+			handler, _ := setupModelHandler(t)
+			rr := httptest.NewRecorder()
+
+			handler.HandlePullBatch(rr, httptest.NewRequest(http.MethodPost, "/v1/models/pull-batch", strings.NewReader(body)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assertErrorCode(t, rr, api.ErrorCodeValidation)
+		})
+	}
+}
+
+// TestModelHandler_HandleCreateModel tests the streaming POST /v1/models/create endpoint.
+func TestModelHandler_HandleCreateModel(t *testing.T) {
+	t.Run("Success - Progress and errors are streamed", func(t *testing.T) {
+		// ARRANGE
+		handler, mockSvc := setupModelHandler(t)
+		reqBody := `{"name": "sql-expert", "modelfile": "FROM qwen3:8b"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/create", strings.NewReader(reqBody))
+		rr := httptest.NewRecorder()
+
+		mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(r *llm.CreateModelRequest) bool {
+			return r.Name == "sql-expert" && r.Modelfile == "FROM qwen3:8b"
+		}), mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(2).(chan<- llm.PullStatus)
+				streamChan <- llm.PullStatus{Status: "parsing modelfile"}
+				streamChan <- llm.PullStatus{Error: "invalid model reference"}
+				close(streamChan)
+			}).Return(errors.New("invalid model reference")).Once()
+
+		// ACT
+		handler.HandleCreateModel(rr, req)
+
+		// ASSERT
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), `"status":"parsing modelfile"`)
+		assert.Contains(t, rr.Body.String(), `"error":"invalid model reference"`)
+		mockSvc.AssertExpectations(t)
+	})
+
+	for _, body := range []string{`{"name":`, `{"modelfile": "FROM qwen3:8b"}`, `{"name": "sql-expert"}`} {
+		t.Run("Failure - Invalid request "+body, func(t *testing.T) {
+			handler, mockSvc := setupModelHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/models/create", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			handler.HandleCreateModel(rr, req)
+
+			assert.Contains(t, rr.Body.String(), "event: error")
+			assert.Contains(t, rr.Body.String(), `"code":"validation_failed"`)
+			mockSvc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestModelHandler_HandleCancelPull tests the POST /v1/models/pull/cancel endpoint.
+func TestModelHandler_HandleCancelPull(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("CancelPull", mock.Anything, &service.CancelPullRequest{Name: "huge-model"}).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/pull/cancel", strings.NewReader(`{"name": "huge-model"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCancelPull(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Not pulling", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("CancelPull", mock.Anything, mock.Anything).Return(app_errors.ErrNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/pull/cancel", strings.NewReader(`{"name": "huge-model"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCancelPull(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeNotFound)
+	})
+
+	for _, body := range []string{`{"name":`, `{}`} {
+		t.Run("Failure - Invalid request "+body, func(t *testing.T) {
+			handler, mockSvc := setupModelHandler(t)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/models/pull/cancel", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+			handler.HandleCancelPull(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockSvc.AssertNotCalled(t, "CancelPull", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestModelHandler_HandleListPulls tests the GET /v1/models/pull/status endpoint.
+func TestModelHandler_HandleListPulls(t *testing.T) {
+	handler, mockSvc := setupModelHandler(t)
+	startedAt := time.Date(2025, 9, 8, 14, 0, 0, 0, time.UTC)
+	mockSvc.On("ListPulls", mock.Anything).Return([]service.PullJobStatus{
+		{Name: "huge-model", StartedAt: startedAt, Subscribers: 1, Status: "pulling abc", Total: 100, Completed: 40},
+	}).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models/pull/status", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleListPulls(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"name": "huge-model", "started_at": "2025-09-08T14:00:00Z", "subscribers": 1, "status": "pulling abc", "total": 100, "completed": 40}]`, rr.Body.String())
+}
+
+// TestModelHandler_HandleOllamaStatus verifies that an unreachable Ollama is
+// TestModelHandler_Blobs tests the HEAD and POST /v1/models/blobs/{digest} endpoints.
+func TestModelHandler_Blobs(t *testing.T) {
+	const digest = "sha256:432f310a77f4650a88d0fd59ecdd7cebed8d684bafea53cbff0473542964f0c3"
+
+	t.Run("Present blob", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("CheckBlob", mock.Anything, digest).Return(true, nil).Once()
+
+		req := addChiURLParams(httptest.NewRequest(http.MethodHead, "/v1/models/blobs/"+digest, nil), map[string]string{"digest": digest})
+		rr := httptest.NewRecorder()
+		handler.HandleCheckBlob(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Absent blob", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("CheckBlob", mock.Anything, digest).Return(false, nil).Once()
+
+		req := addChiURLParams(httptest.NewRequest(http.MethodHead, "/v1/models/blobs/"+digest, nil), map[string]string{"digest": digest})
+		rr := httptest.NewRecorder()
+		handler.HandleCheckBlob(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		handler, mockSvc := setupModelHandler(t)
+		mockSvc.On("PushBlob", mock.Anything, digest, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				body, err := io.ReadAll(args.Get(2).(io.Reader))
+				require.NoError(t, err)
+				assert.Equal(t, "GGUF", string(body))
+			}).Once()
+
+		req := addChiURLParams(httptest.NewRequest(http.MethodPost, "/v1/models/blobs/"+digest, strings.NewReader("GGUF")), map[string]string{"digest": digest})
+		rr := httptest.NewRecorder()
+		handler.HandlePushBlob(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"status": "ok"}`, rr.Body.String())
+	})
+}
+
+// reported as a status rather than as a failed request.
+func TestModelHandler_HandleOllamaStatus(t *testing.T) {
+	handler, mockSvc := setupModelHandler(t)
+	mockSvc.On("OllamaStatus", mock.Anything).
+		Return(&service.OllamaStatus{URL: "http://ollama:11434", LatencyMs: 3000, Error: "context deadline exceeded"}).Once()
+
+	rr := httptest.NewRecorder()
+	handler.HandleOllamaStatus(rr, httptest.NewRequest(http.MethodGet, "/v1/system/ollama", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"url": "http://ollama:11434", "connected": false, "latency_ms": 3000, "error": "context deadline exceeded"}`, rr.Body.String())
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+
+	"flow-ai/backend/internal/interfaces"
+	_ "flow-ai/backend/internal/model" // Resolves the types of the swag annotations.
+	"flow-ai/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PromptHandler handles HTTP requests for the library of reusable prompt templates.
+type PromptHandler struct {
+	service interfaces.PromptService
+}
+
+// NewPromptHandler creates a new instance of PromptHandler.
+func NewPromptHandler(svc interfaces.PromptService) *PromptHandler {
+	return &PromptHandler{service: svc}
+}
+
+// HandleCreatePrompt godoc
+// @Summary      Create a prompt template
+// @Description  Stores a reusable prompt. The body may contain `{{variable}}` placeholders (Go text/template syntax), which are filled from the `variables` of a message that uses the prompt.
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Param        prompt  body      service.SavePromptRequest  true  "Prompt"
+// @Success      201     {object}  model.Prompt
+// @Failure      400     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /v1/prompts [post]
+func (h *PromptHandler) HandleCreatePrompt(w http.ResponseWriter, r *http.Request) {
+	var req service.SavePromptRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	prompt, err := h.service.CreatePrompt(r.Context(), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, prompt)
+}
+
+// HandleListPrompts godoc
+// @Summary      List prompt templates
+// @Tags         Prompts
+// @Produce      json
+// @Success      200  {array}   model.Prompt
+// @Failure      500  {object}  ErrorResponse
+// @Router       /v1/prompts [get]
+func (h *PromptHandler) HandleListPrompts(w http.ResponseWriter, r *http.Request) {
+	prompts, err := h.service.ListPrompts(r.Context())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prompts)
+}
+
+// HandleGetPrompt godoc
+// @Summary      Get a prompt template
+// @Tags         Prompts
+// @Produce      json
+// @Param        promptID  path      string  true  "Prompt ID"
+// @Success      200       {object}  model.Prompt
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /v1/prompts/{promptID} [get]
+func (h *PromptHandler) HandleGetPrompt(w http.ResponseWriter, r *http.Request) {
+	prompt, err := h.service.GetPrompt(r.Context(), chi.URLParam(r, "promptID"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prompt)
+}
+
+// HandleUpdatePrompt godoc
+// @Summary      Replace a prompt template
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Param        promptID  path      string                     true  "Prompt ID"
+// @Param        prompt    body      service.SavePromptRequest  true  "Prompt"
+// @Success      200       {object}  model.Prompt
+// @Failure      400       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /v1/prompts/{promptID} [put]
+func (h *PromptHandler) HandleUpdatePrompt(w http.ResponseWriter, r *http.Request) {
+	var req service.SavePromptRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	prompt, err := h.service.UpdatePrompt(r.Context(), chi.URLParam(r, "promptID"), &req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prompt)
+}
+
+// HandleDeletePrompt godoc
+// @Summary      Delete a prompt template
+// @Tags         Prompts
+// @Produce      json
+// @Param        promptID  path      string  true  "Prompt ID"
+// @Success      200       {object}  StatusResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /v1/prompts/{promptID} [delete]
+func (h *PromptHandler) HandleDeletePrompt(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePrompt(r.Context(), chi.URLParam(r, "promptID")); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "ok"})
+}
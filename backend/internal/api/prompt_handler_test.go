@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"flow-ai/backend/internal/api"
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/interfaces/mocks"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
+)
+
+func setupPromptHandler(t *testing.T) (*api.PromptHandler, *mocks.MockPromptService) {
+	mockSvc := mocks.NewMockPromptService(t)
+	return api.NewPromptHandler(mockSvc), mockSvc
+}
+
+// TestPromptHandler_HandleCreatePrompt tests the POST /v1/prompts endpoint.
+func TestPromptHandler_HandleCreatePrompt(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupPromptHandler(t)
+		mockSvc.On("CreatePrompt", mock.Anything, &service.SavePromptRequest{Name: "Review", Body: "Review {{code}}"}).
+			Return(&model.Prompt{ID: "p1", Name: "Review", Variables: []string{"code"}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/prompts", strings.NewReader(`{"name": "Review", "body": "Review {{code}}"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreatePrompt(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var resp model.Prompt
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"code"}, resp.Variables)
+	})
+
+	t.Run("Failure - Missing body", func(t *testing.T) {
+		handler, mockSvc := setupPromptHandler(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/prompts", strings.NewReader(`{"name": "Review"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleCreatePrompt(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+		mockSvc.AssertNotCalled(t, "CreatePrompt", mock.Anything, mock.Anything)
+	})
+}
+
+// TestPromptHandler_HandleUpdatePrompt tests the PUT /v1/prompts/{promptID} endpoint.
+func TestPromptHandler_HandleUpdatePrompt(t *testing.T) {
+	params := map[string]string{"promptID": "p1"}
+
+	t.Run("Success", func(t *testing.T) {
+		handler, mockSvc := setupPromptHandler(t)
+		mockSvc.On("UpdatePrompt", mock.Anything, "p1", &service.SavePromptRequest{Name: "Review", Body: "text"}).
+			Return(&model.Prompt{ID: "p1"}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/prompts/p1", strings.NewReader(`{"name": "Review", "body": "text"}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.HandleUpdatePrompt(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Failure - Invalid template", func(t *testing.T) {
+		handler, mockSvc := setupPromptHandler(t)
+		mockSvc.On("UpdatePrompt", mock.Anything, "p1", mock.Anything).Return(nil, app_errors.ErrValidation).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/prompts/p1", strings.NewReader(`{"name": "Review", "body": "{{end}}"}`))
+		req = addChiURLParams(req, params)
+		rr := httptest.NewRecorder()
+		handler.HandleUpdatePrompt(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assertErrorCode(t, rr, api.ErrorCodeValidation)
+	})
+}
+
+// TestPromptHandler_HandleDeletePrompt tests the DELETE /v1/prompts/{promptID} endpoint.
+func TestPromptHandler_HandleDeletePrompt(t *testing.T) {
+	handler, mockSvc := setupPromptHandler(t)
+	mockSvc.On("DeletePrompt", mock.Anything, "missing").Return(app_errors.ErrNotFound).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/prompts/missing", nil)
+	req = addChiURLParams(req, map[string]string{"promptID": "missing"})
+	rr := httptest.NewRecorder()
+	handler.HandleDeletePrompt(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assertErrorCode(t, rr, api.ErrorCodeNotFound)
+}
@@ -1,29 +1,53 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/model"
 )
 
 // This file contains shared DTOs (Data Transfer Objects) for API responses
 // and helper functions for sending consistent HTTP responses.
 
 // ErrorResponse defines the standard JSON structure for error messages.
+// `Error` is meant for humans, while `Code` is stable and lets clients branch on
+// the kind of error without parsing the message.
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code" example:"not_found"`
 }
 
+// Machine-readable error codes sent in ErrorResponse.Code.
+const (
+	ErrorCodeNotFound    = "not_found"
+	ErrorCodeValidation  = "validation_failed"
+	ErrorCodeConflict    = "conflict"
+	ErrorCodePermission  = "permission_denied"
+	ErrorCodeTooLarge    = "request_too_large"
+	ErrorCodeUnavailable = "upstream_unavailable"
+	ErrorCodeUnsupported = "not_supported"
+	ErrorCodeInternal    = "internal"
+)
+
 // StatusResponse defines a generic success response, typically for operations
 // like POST, PUT, DELETE that don't need to return a full resource.
 type StatusResponse struct {
 	Status string `json:"status"`
 }
 
+// DeleteChatsResponse reports the result of a bulk delete.
+type DeleteChatsResponse struct {
+	// Deleted is the number of chats that existed and were deleted.
+	Deleted int `json:"deleted" example:"3"`
+}
+
 // UpdateTitleRequest is the DTO for the manual chat title update endpoint.
 // It includes validation tags to enforce business rules at the API boundary.
 type UpdateTitleRequest struct {
@@ -35,27 +59,44 @@ type UpdateTitleRequest struct {
 // a standard JSON error response.
 func respondWithError(w http.ResponseWriter, err error) {
 	var statusCode int
-	var message string
+	var code, message string
 
 	switch {
 	case errors.Is(err, app_errors.ErrNotFound):
 		statusCode = http.StatusNotFound
+		code = ErrorCodeNotFound
 		message = "The requested resource was not found."
 	case errors.Is(err, app_errors.ErrValidation):
 		statusCode = http.StatusBadRequest
+		code = ErrorCodeValidation
 		// For validation errors, the error message from the service layer
 		// is already descriptive and user-friendly.
 		message = err.Error()
 	case errors.Is(err, app_errors.ErrConflict):
 		statusCode = http.StatusConflict
+		code = ErrorCodeConflict
 		message = "A conflict occurred with the current state of the resource."
 	case errors.Is(err, app_errors.ErrPermission):
 		statusCode = http.StatusForbidden
+		code = ErrorCodePermission
 		message = "You do not have permission to perform this action."
+	case errors.Is(err, app_errors.ErrTooLarge):
+		statusCode = http.StatusRequestEntityTooLarge
+		code = ErrorCodeTooLarge
+		message = err.Error()
+	case errors.Is(err, app_errors.ErrUnavailable):
+		statusCode = http.StatusBadGateway
+		code = ErrorCodeUnavailable
+		message = "An external service is currently unavailable. Please try again later."
+	case errors.Is(err, app_errors.ErrNotSupported):
+		statusCode = http.StatusNotImplemented
+		code = ErrorCodeUnsupported
+		message = "This operation is not supported by the configured LLM provider."
 	default:
 		// Any unhandled error is considered an internal server error.
 		// This prevents leaking implementation details to the client.
 		statusCode = http.StatusInternalServerError
+		code = ErrorCodeInternal
 		message = "An unexpected internal server error occurred."
 	}
 
@@ -63,9 +104,9 @@ func respondWithError(w http.ResponseWriter, err error) {
 	// while a generic message is sent to the client.
 	// #nosec G706 -- slog provides structured logging which automatically escapes control characters in strings,
 	// preventing log injection vulnerabilities.
-	slog.Warn("Responding with error", "status_code", statusCode, "client_message", message, "internal_error", err)
+	slog.Warn("Responding with error", "status_code", statusCode, "error_code", code, "client_message", message, "internal_error", err)
 
-	respondWithJSON(w, statusCode, ErrorResponse{Error: message})
+	respondWithJSON(w, statusCode, ErrorResponse{Error: message, Code: code})
 }
 
 // respondWithJSON is a low-level helper for marshaling a payload to JSON
@@ -87,9 +128,9 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 
 // sendStreamError sends a structured error message over a Server-Sent Events (SSE) stream.
 // This ensures that clients consuming streams can handle errors gracefully.
-func sendStreamError(w http.ResponseWriter, message string) {
-	slog.Warn("Sending stream error to client", "message", message)
-	errorPayload := ErrorResponse{Error: message}
+func sendStreamError(w http.ResponseWriter, code, message string) {
+	slog.Warn("Sending stream error to client", "code", code, "message", message)
+	errorPayload := ErrorResponse{Error: message, Code: code}
 
 	jsonData, err := json.Marshal(errorPayload)
 	if err != nil {
@@ -111,6 +152,18 @@ func sendStreamError(w http.ResponseWriter, message string) {
 	}
 }
 
+// sendDecodeStreamError sends the stream error for a request body decodeJSON
+// rejected. The stream has not started yet, so a body over the limit also gets
+// the 413 status.
+func sendDecodeStreamError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, app_errors.ErrTooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		sendStreamError(w, ErrorCodeTooLarge, err.Error())
+		return
+	}
+	sendStreamError(w, ErrorCodeValidation, message)
+}
+
 // writeStreamEvent is a generic helper to marshal data and write it to an SSE stream.
 // It returns an error on write failure, which is a signal that the client has disconnected.
 func writeStreamEvent(w http.ResponseWriter, data interface{}) error {
@@ -132,3 +185,61 @@ func writeStreamEvent(w http.ResponseWriter, data interface{}) error {
 	}
 	return nil
 }
+
+// writeHeartbeat writes an SSE comment line. Clients ignore comments, but the
+// traffic keeps idle proxies from closing the connection.
+func writeHeartbeat(w http.ResponseWriter) error {
+	if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+		return fmt.Errorf("failed to write heartbeat to stream: %w", err)
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// streamEvents forwards chunks from the service to the client until the channel is
+// closed. Whenever no chunk arrives for `heartbeat` (e.g. while the model is still
+// loading), a heartbeat comment is written instead; a non-positive interval disables
+// heartbeats. It returns the context error if the client went away, or the write
+// error if the connection broke.
+func streamEvents(ctx context.Context, w http.ResponseWriter, streamChan <-chan model.StreamResponse, heartbeat time.Duration) error {
+	// A nil channel never fires, which disables heartbeats.
+	var tick <-chan time.Time
+	var timer *time.Timer
+	if heartbeat > 0 {
+		timer = time.NewTimer(heartbeat)
+		defer timer.Stop()
+		tick = timer.C
+	}
+
+	for {
+		var err error
+		select {
+		case chunk, ok := <-streamChan:
+			if !ok {
+				return nil
+			}
+			if err = ctx.Err(); err == nil {
+				err = writeStreamEvent(w, chunk)
+			}
+		case <-tick:
+			err = writeHeartbeat(w)
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			// Keep receiving in the background so the service is not blocked on a
+			// send nobody reads, and can still finish its work.
+			go func() {
+				for range streamChan {
+				}
+			}()
+			return err
+		}
+		// The timer restarts after every write, so heartbeats only fill gaps.
+		if timer != nil {
+			timer.Reset(heartbeat)
+		}
+	}
+}
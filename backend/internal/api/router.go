@@ -6,6 +6,7 @@ import (
 
 	// This blank import is required by swaggo to find the API definitions.
 	_ "flow-ai/backend/docs"
+	"flow-ai/backend/internal/metrics"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -13,7 +14,11 @@ import (
 )
 
 // NewRouter creates and configures a new chi router with all the application's routes.
-func NewRouter(chatHandler *ChatHandler, modelHandler *ModelHandler) *chi.Mux {
+// JSON request bodies are limited to maxBodyBytes; 0 disables the limit. JSON
+// responses are gzipped for clients that accept it if compress is set. The
+// frontend is served from frontendDir; empty serves no frontend. Requests are
+// recorded in m, which is served at /metrics; nil disables both.
+func NewRouter(chatHandler *ChatHandler, modelHandler *ModelHandler, documentHandler *DocumentHandler, promptHandler *PromptHandler, adminHandler *AdminHandler, systemHandler *SystemHandler, m *metrics.Metrics, maxBodyBytes int64, compress bool, frontendDir string) *chi.Mux {
 	r := chi.NewRouter()
 
 	// --- Global Middleware ---
@@ -21,6 +26,10 @@ func NewRouter(chatHandler *ChatHandler, modelHandler *ModelHandler) *chi.Mux {
 	r.Use(middleware.RequestID) // Injects a unique request ID into the context.
 	r.Use(middleware.RealIP)    // Sets the remote address to the real IP from proxy headers.
 	r.Use(middleware.Logger)    // Logs the start and end of each request with useful info.
+	if m != nil {
+		// Before the Recoverer, so that a panic is recorded as the 500 it becomes.
+		r.Use(m.Middleware)
+	}
 	r.Use(middleware.Recoverer) // Recovers from panics and returns a 500 error.
 
 	// --- Public Routes ---
@@ -38,6 +47,11 @@ func NewRouter(chatHandler *ChatHandler, modelHandler *ModelHandler) *chi.Mux {
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Prometheus metrics of the LLM calls, HTTP requests and database queries.
+	if m != nil {
+		r.Get("/metrics", m.Handler().ServeHTTP)
+	}
+
 	// --- API Version 1 Routes ---
 	// All primary API endpoints are grouped under the /api/v1 prefix.
 	r.Route("/api/v1", func(r chi.Router) {
@@ -46,39 +60,112 @@ func NewRouter(chatHandler *ChatHandler, modelHandler *ModelHandler) *chi.Mux {
 		// to prevent client connections from hanging indefinitely.
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.Timeout(60 * time.Second))
+			r.Use(LimitRequestBody(maxBodyBytes))
+			// Only this group is compressed: gzip buffers its output, which
+			// would hold back the events of the streaming routes below.
+			if compress {
+				r.Use(middleware.Compress(5, "application/json"))
+			}
 
 			// --- Settings ---
 			r.Get("/settings", chatHandler.GetSettings)
 			r.Post("/settings", chatHandler.UpdateSettings)
+			r.Patch("/settings", chatHandler.PatchSettings)
+			r.Post("/settings/refresh", chatHandler.RefreshSettings)
 
 			// --- Chats ---
 			r.Get("/chats", chatHandler.GetChats)
+			r.Post("/chats", chatHandler.HandleCreateChat)
+			r.Post("/chats/estimate", chatHandler.HandleEstimateTokens)
+			r.Post("/chats/bulk-delete", chatHandler.HandleDeleteChats)
 			r.Get("/chats/{chatID}", chatHandler.GetChat)
 			r.Get("/chats/{chatID}/tree", chatHandler.GetChatTree)
+			r.Get("/chats/{chatID}/messages", chatHandler.GetChatMessages)
 			r.Put("/chats/{chatID}/title", chatHandler.UpdateChatTitle)
+			r.Post("/chats/{chatID}/regenerate-title", chatHandler.HandleRegenerateTitle)
 			r.Delete("/chats/{chatID}", chatHandler.HandleDeleteChat)
+			r.Post("/chats/{chatID}/clone", chatHandler.HandleCloneChat)
+			r.Put("/chats/{chatID}/collection", chatHandler.UpdateChatCollection)
+			r.Put("/chats/{chatID}/tags", chatHandler.UpdateChatTags)
+			r.Put("/chats/{chatID}/pin", chatHandler.UpdateChatPinned)
+			r.Post("/chats/{chatID}/messages/raw", chatHandler.HandleAddRawMessage)
+			r.Get("/chats/{chatID}/messages/{messageID}", chatHandler.GetMessage)
 			r.Post("/chats/{chatID}/messages/{messageID}/activate", chatHandler.HandleSwitchBranch)
+			r.Get("/chats/{chatID}/messages/{messageID}/attachments/{attachmentID}", chatHandler.GetAttachment)
 
 			// --- Models ---
 			r.Get("/models", modelHandler.HandleListModels)
+			r.Get("/models/running", modelHandler.HandleListRunningModels)
+			r.Get("/models/storage", modelHandler.HandleModelStorage)
+			r.Get("/models/search", modelHandler.HandleSearchModels)
+			r.Get("/models/updates", modelHandler.HandleListModelUpdates)
+			r.Get("/models/aliases", chatHandler.HandleListModelAliases)
+			r.Put("/models/aliases/{name}", chatHandler.HandleSetModelAlias)
+			r.Delete("/models/aliases/{name}", chatHandler.HandleDeleteModelAlias)
 			r.Post("/models/show", modelHandler.HandleShowModel)
 			r.Delete("/models", modelHandler.HandleDeleteModel)
+			r.Post("/models/copy", modelHandler.HandleCopyModel)
+			r.Head("/models/blobs/{digest}", modelHandler.HandleCheckBlob)
+			r.Get("/models/{name}/defaults", modelHandler.HandleGetModelDefaults)
+			r.Put("/models/{name}/defaults", modelHandler.HandleSetModelDefaults)
+			r.Post("/models/pull/cancel", modelHandler.HandleCancelPull)
+			r.Get("/models/pull/status", modelHandler.HandleListPulls)
+			r.Get("/options/schema", modelHandler.HandleOptionsSchema)
+			r.Post("/embeddings", modelHandler.HandleEmbed)
+			r.Post("/complete", modelHandler.HandleComplete)
+			r.Get("/system/ollama", modelHandler.HandleOllamaStatus)
+			r.Get("/system/health", systemHandler.HandleHealth)
+
+			// --- Document collections ---
+			r.Get("/collections", documentHandler.HandleListCollections)
+			r.Post("/collections", documentHandler.HandleCreateCollection)
+			r.Get("/collections/{collectionID}/documents", documentHandler.HandleListDocuments)
+			r.Post("/collections/{collectionID}/documents", documentHandler.HandleUploadDocument)
+
+			// --- Prompt templates ---
+			r.Get("/prompts", promptHandler.HandleListPrompts)
+			r.Post("/prompts", promptHandler.HandleCreatePrompt)
+			r.Get("/prompts/{promptID}", promptHandler.HandleGetPrompt)
+			r.Put("/prompts/{promptID}", promptHandler.HandleUpdatePrompt)
+			r.Delete("/prompts/{promptID}", promptHandler.HandleDeletePrompt)
+
+			// --- Admin ---
+			r.Group(func(r chi.Router) {
+				r.Use(adminHandler.RequireAPIKey)
+				r.Get("/admin/retention", adminHandler.GetRetentionStatus)
+				r.Get("/admin/stats", chatHandler.GetGlobalStats)
+				r.Post("/admin/maintenance", adminHandler.HandleMaintenance)
+				r.Post("/admin/backup", adminHandler.HandleBackup)
+				r.Get("/admin/backups", adminHandler.ListBackups)
+			})
 		})
 
 		// Group for long-running, streaming endpoints. These routes must NOT have a timeout,
 		// as they are designed to hold a connection open for an extended period.
 		r.Group(func(r chi.Router) {
+			r.Use(LimitRequestBody(maxBodyBytes))
 			r.Post("/chats/messages", chatHandler.HandleStreamMessage)
 			r.Post("/chats/{chatID}/messages/{messageID}/regenerate", chatHandler.HandleRegenerateMessage)
+			r.Post("/chats/{chatID}/messages/{messageID}/continue", chatHandler.HandleContinueMessage)
+			r.Post("/chats/{chatID}/messages/{messageID}/compare", chatHandler.HandleCompareMessage)
 			r.Post("/models/pull", modelHandler.HandlePullModel)
+			r.Post("/models/pull-batch", modelHandler.HandlePullBatch)
+			r.Post("/models/create", modelHandler.HandleCreateModel)
+			r.Post("/models/{name}/benchmark", modelHandler.HandleBenchmarkModel)
+			r.Post("/models/{name}/update", modelHandler.HandleUpdateModel)
 		})
+
+		// Blobs are model files of many gigabytes that are streamed to Ollama,
+		// so their upload has neither a timeout nor a size limit.
+		r.Post("/models/blobs/{digest}", modelHandler.HandlePushBlob)
 	})
 
 	// --- Frontend File Server ---
 	// This serves the static React frontend. In a typical production deployment,
 	// this would be handled by Nginx, but it's useful for simplified local development.
-	fileServer := http.FileServer(http.Dir("./frontend/dist"))
-	r.Handle("/*", http.StripPrefix("/", fileServer))
+	if frontendDir != "" {
+		r.Handle("/*", newSPAHandler(frontendDir))
+	}
 
 	return r
 }
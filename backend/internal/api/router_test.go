@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/api"
+	"flow-ai/backend/internal/model"
+)
+
+// TestRouter_Compression verifies that JSON responses are gzipped for clients
+// that accept it, while the events of a stream are sent uncompressed.
+func TestRouter_Compression(t *testing.T) {
+	t.Run("A chat is compressed", func(t *testing.T) {
+		// ARRANGE
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		router := api.NewRouter(handler, nil, nil, nil, nil, nil, nil, 0, true, "")
+		// WHY: The middleware leaves responses below its minimum size alone.
+		long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+		mockChatSvc.On("GetFullChat", mock.Anything, "chat1").
+			Return(&model.FullChat{Chat: model.Chat{ID: "chat1"}, Messages: []model.Message{{ID: "m1", Role: "user", Content: long}}}, nil).Once()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/chats/chat1", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		// ACT
+		router.ServeHTTP(rr, req)
+
+		// ASSERT
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+		assert.Less(t, rr.Body.Len(), len(long))
+		reader, err := gzip.NewReader(rr.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), long)
+	})
+
+	t.Run("A stream is not compressed", func(t *testing.T) {
+		// ARRANGE
+		handler, mockChatSvc, _ := setupChatHandler(t)
+		router := api.NewRouter(handler, nil, nil, nil, nil, nil, nil, 0, true, "")
+		mockChatSvc.On("HandleNewMessage", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				streamChan := args.Get(2).(chan<- model.StreamResponse)
+				streamChan <- model.StreamResponse{ChatID: "chat1", Content: strings.Repeat("token ", 500), Done: true}
+				close(streamChan)
+			}).Once()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chats/messages", strings.NewReader(`{"content": "Hi"}`))
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		// ACT
+		router.ServeHTTP(rr, req)
+
+		// ASSERT
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Encoding"))
+		assert.Contains(t, rr.Body.String(), "data: ")
+	})
+}
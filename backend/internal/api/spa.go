@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// spaHandler serves the built frontend, a single-page application. Paths that
+// are not files, such as "/chats/123" after a page reload, are client-side
+// routes and are answered with index.html, so that the frontend's router can
+// handle them.
+type spaHandler struct {
+	dir        string
+	root       http.FileSystem
+	fileServer http.Handler
+}
+
+func newSPAHandler(dir string) *spaHandler {
+	root := http.Dir(dir)
+	return &spaHandler{dir: dir, root: root, fileServer: http.FileServer(root)}
+}
+
+func (h *spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+
+	// An unknown API route must not look like a successful response.
+	if p == "/api" || strings.HasPrefix(p, "/api/") || p == "/healthz" {
+		respondWithError(w, fmt.Errorf("%w: no route for %s", app_errors.ErrNotFound, p))
+		return
+	}
+
+	if h.isFile(p) {
+		h.fileServer.ServeHTTP(w, r)
+		return
+	}
+	// A missing asset, e.g. a script of an older build, is a real 404 rather
+	// than a page the browser would fail to parse.
+	if path.Ext(p) != "" {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(h.dir, "index.html"))
+}
+
+// isFile reports whether p names a regular file of the frontend.
+func (h *spaHandler) isFile(p string) bool {
+	f, err := h.root.Open(p)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	return err == nil && !info.IsDir()
+}
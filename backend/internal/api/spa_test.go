@@ -0,0 +1,57 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/api"
+)
+
+// TestRouter_Frontend verifies that the router serves the frontend's files, answers
+// client-side routes with index.html, and still reports unknown API routes and
+// missing assets as 404.
+func TestRouter_Frontend(t *testing.T) {
+	// ARRANGE: A minimal frontend build. The handlers are not needed, as no API
+	// route is matched.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>app</html>"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "app.js"), []byte("console.log('app')"), 0o644))
+	router := api.NewRouter(nil, nil, nil, nil, nil, nil, nil, 0, false, dir)
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"Existing asset", "/assets/app.js", http.StatusOK, "console.log('app')"},
+		{"Root", "/", http.StatusOK, "<html>app</html>"},
+		{"Client-side route", "/chats/123", http.StatusOK, "<html>app</html>"},
+		{"Missing asset", "/assets/old.js", http.StatusNotFound, ""},
+		{"Unknown API route", "/api/v1/unknown", http.StatusNotFound, ""},
+		{"Unknown API version", "/api/v2/chats", http.StatusNotFound, ""},
+		{"Path traversal", "/../../etc/passwd", http.StatusBadRequest, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// ASSERT
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, rr.Body.String())
+			} else {
+				assert.NotContains(t, rr.Body.String(), "<html>app</html>")
+			}
+		})
+	}
+}
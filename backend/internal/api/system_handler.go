@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"flow-ai/backend/internal/interfaces"
+	"flow-ai/backend/internal/service"
+)
+
+// SystemHandler handles HTTP requests about the state of the backend itself.
+type SystemHandler struct {
+	health interfaces.HealthService
+}
+
+// NewSystemHandler creates a new instance of SystemHandler.
+func NewSystemHandler(health interfaces.HealthService) *SystemHandler {
+	return &SystemHandler{health: health}
+}
+
+// HandleHealth godoc
+// @Summary      Dependency health
+// @Description  Pings the database and the LLM provider, each with a short timeout, and reports the state and latency of each. Unlike `/healthz`, which only tells that the process is up, it returns 503 with `status` "degraded" if a dependency is down.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  service.Health
+// @Failure      503  {object}  service.Health
+// @Router       /v1/system/health [get]
+func (h *SystemHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	health := h.health.Check(r.Context())
+	status := http.StatusOK
+	if health.Status != service.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, health)
+}
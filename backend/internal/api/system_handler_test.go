@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/api"
+	"flow-ai/backend/internal/interfaces/mocks"
+	"flow-ai/backend/internal/service"
+)
+
+// TestSystemHandler_HandleHealth tests the GET /v1/system/health endpoint.
+func TestSystemHandler_HandleHealth(t *testing.T) {
+	t.Run("Healthy", func(t *testing.T) {
+		mockSvc := mocks.NewMockHealthService(t)
+		mockSvc.On("Check", mock.Anything).Return(&service.Health{
+			Status:   service.HealthOK,
+			Database: service.DependencyHealth{Healthy: true, LatencyMs: 0.2},
+			LLM:      service.DependencyHealth{Healthy: true, LatencyMs: 4.1},
+		}).Once()
+
+		rr := httptest.NewRecorder()
+		api.NewSystemHandler(mockSvc).HandleHealth(rr, httptest.NewRequest(http.MethodGet, "/v1/system/health", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"status": "ok",
+			"database": {"healthy": true, "latency_ms": 0.2},
+			"llm": {"healthy": true, "latency_ms": 4.1}
+		}`, rr.Body.String())
+	})
+
+	t.Run("Degraded", func(t *testing.T) {
+		mockSvc := mocks.NewMockHealthService(t)
+		mockSvc.On("Check", mock.Anything).Return(&service.Health{
+			Status:   service.HealthDegraded,
+			Database: service.DependencyHealth{Healthy: true},
+			LLM:      service.DependencyHealth{Error: "LLM backend unreachable: connection refused"},
+		}).Once()
+
+		rr := httptest.NewRecorder()
+		api.NewSystemHandler(mockSvc).HandleHealth(rr, httptest.NewRequest(http.MethodGet, "/v1/system/health", nil))
+
+		// WHY: The status is reported as a 503, so that probes and monitors see it
+		// without parsing the body, which still tells which dependency is down.
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		var resp service.Health
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, service.HealthDegraded, resp.Status)
+		assert.False(t, resp.LLM.Healthy)
+	})
+}
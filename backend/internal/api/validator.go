@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -26,6 +27,10 @@ var (
 func getInstance() *validator.Validate {
 	once.Do(func() {
 		validate = validator.New()
+		// `keep_alive` accepts the duration formats understood by Ollama.
+		_ = validate.RegisterValidation("keep_alive", func(fl validator.FieldLevel) bool {
+			return llm.KeepAlive(fl.Field().String()).Valid()
+		})
 	})
 	return validate
 }
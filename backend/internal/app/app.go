@@ -6,10 +6,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/viper"
@@ -18,6 +22,7 @@ import (
 	"flow-ai/backend/internal/config"
 	"flow-ai/backend/internal/database"
 	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/metrics"
 	"flow-ai/backend/internal/repository"
 	"flow-ai/backend/internal/service"
 )
@@ -32,8 +37,20 @@ type App struct {
 	Config *config.Config
 	DB     *sql.DB
 	Server *http.Server
+
+	// stopBackground cancels background jobs such as the retention janitor.
+	stopBackground context.CancelFunc
+	// modelService owns the background model pulls, which are stopped on Close.
+	modelService *service.ModelService
+	// chatService owns the background title generations, which are given
+	// titleShutdownTimeout to finish on Close.
+	chatService *service.ChatService
 }
 
+// titleShutdownTimeout is how long Close waits for title generations in
+// progress before cancelling them.
+const titleShutdownTimeout = 5 * time.Second
+
 // NewApp creates and wires up all application components based on the provided config.
 // It initializes dependencies in a specific order: DB -> Repository -> Services -> API Handlers.
 //
@@ -44,24 +61,66 @@ type App struct {
 // can now call `NewApp` to verify that the entire application can be initialized
 // without errors, giving us high confidence and test coverage for this critical path.
 func NewApp(cfg *config.Config) (*App, error) {
+	// Validate the listen address first, so that a typo fails immediately
+	// instead of after waiting for Ollama and running migrations.
+	addr, err := cfg.ListenAddr()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.ValidateBackend(cfg.StorageBackend); err != nil {
+		return nil, fmt.Errorf("STORAGE_BACKEND: %w", err)
+	}
+	llmProvider, err := newLLMProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Wait for the external Ollama service to be available before proceeding.
 	// This prevents the application from starting in a broken state if its
-	// core dependency is not ready.
-	waitForOllama(cfg.OllamaURL)
+	// core dependency is not ready. A hosted API, the fake provider, or Ollama
+	// as an additional provider, is not waited for.
+	if defaultProviderName(cfg) == llm.ProviderOllama {
+		ollamaCfg, err := ollamaConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		waitForOllama(cfg.OllamaURL, ollamaCfg)
+	}
+	usesOllama := slices.Contains(llmProvider.Names(), llm.ProviderOllama)
 
-	db, err := database.InitDB(cfg.DatabasePath)
+	dbConfig := database.Config{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		BusyTimeout:     cfg.DBBusyTimeout,
+	}
+	// The services see the provider through the metrics decorator, so that every
+	// LLM call is recorded.
+	var appMetrics *metrics.Metrics
+	var provider llm.LLMProvider = llmProvider
+	if cfg.MetricsEnabled {
+		appMetrics = metrics.New()
+		dbConfig.ObserveQuery = appMetrics.ObserveQuery
+		provider = metrics.InstrumentLLM(llmProvider, appMetrics)
+	}
+
+	storage, err := repository.OpenStorage(repository.StorageConfig{
+		Backend:      cfg.StorageBackend,
+		DatabasePath: cfg.DatabasePath,
+		Database:     dbConfig,
+	})
 	if err != nil {
 		return nil, err
 	}
-	slog.Info("Successfully connected to SQLite database.")
+	slog.Info("Successfully connected to the storage backend.", "backend", cfg.StorageBackend)
 
 	// --- Dependency Injection ---
 	// Create concrete implementations of our interfaces.
-	repo := repository.NewSQLiteRepository(db)
-	ollamaProvider := llm.NewOllamaProvider(cfg.OllamaURL)
+	db, repo := storage.DB, storage.Repository
 
 	// Services are instantiated with their dependencies.
-	settingsService := service.NewSettingsService(db, ollamaProvider)
+	preloader := service.NewModelPreloader(provider)
+	settingsService := service.NewSettingsService(db, provider, preloader)
 
 	// Initialize settings on first run, which is a critical startup step.
 	// If this fails, we can't proceed, so we close the DB and return the error.
@@ -73,22 +132,82 @@ func NewApp(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 	slog.Info("Loaded application settings", "main_model", appSettings.MainModel)
+	// Loading the main model happens in the background and must not delay startup.
+	settingsService.PreloadMainModel(appSettings)
 
 	// The ChatService depends on the SettingsService, demonstrating inter-service dependency.
-	chatService := service.NewChatService(repo, ollamaProvider, settingsService)
-	modelService := service.NewModelService(ollamaProvider)
+	documentService := service.NewDocumentService(repo, provider, service.DocumentServiceConfig{
+		EmbeddingModel: cfg.EmbeddingModel,
+		ChunkSize:      cfg.RAGChunkSize,
+		ChunkOverlap:   cfg.RAGChunkOverlap,
+		TopK:           cfg.RAGTopK,
+	})
+	chatService := service.NewChatService(repo, provider, settingsService, documentService, service.ChatServiceConfig{
+		DefaultUserID:      cfg.DefaultUserID,
+		TitlePreviewLength: cfg.TitlePreviewLength,
+		TitleMaxLength:     cfg.TitleMaxLength,
+		TitleTimeout:       cfg.TitleTimeout,
+		MaxImageBytes:      cfg.MaxImageBytes,
+		IdempotencyTTL:     cfg.IdempotencyTTL,
+		ContentFilter:      newContentFilter(cfg),
+		FilterResponses:    cfg.ContentFilterResponses,
+		ResponseCacheSize:  cfg.ResponseCacheSize,
+
+		MaxConcurrentGenerations: cfg.MaxConcurrentGenerations,
+		GenerationQueueTimeout:   cfg.GenerationQueueTimeout,
+	})
+	// Only Ollama's models are in its registry, so without Ollama there is nothing to check for updates.
+	updateCheckInterval := cfg.ModelUpdateCheckInterval
+	if !usesOllama {
+		updateCheckInterval = 0
+	}
+	modelService := service.NewModelService(repo, provider, llm.NewOllamaRegistry(cfg.RegistryURL, llm.RegistryConfig{
+		Timeout:     cfg.RegistryTimeout,
+		CacheTTL:    cfg.RegistryCacheTTL,
+		ManifestURL: cfg.RegistryManifestURL,
+	}), service.ModelServiceConfig{
+		OllamaURL:           cfg.OllamaURL,
+		ModelsPath:          cfg.OllamaModelsPath,
+		CancelOnDisconnect:  cfg.PullCancelOnDisconnect,
+		UpdateCheckInterval: updateCheckInterval,
+		Preloader:           preloader,
+	})
 
 	// API Handlers are instantiated with the services they depend on.
 	// Go automatically recognizes that concrete types like `*service.ChatService`
 	// satisfy the `interfaces.ChatService` expected by `NewChatHandler`.
-	chatHandler := api.NewChatHandler(chatService, settingsService)
+	chatHandler := api.NewChatHandler(chatService, settingsService, api.ChatHandlerConfig{
+		HeartbeatInterval: cfg.SSEHeartbeatInterval,
+	})
 	modelHandler := api.NewModelHandler(modelService)
+	documentHandler := api.NewDocumentHandler(documentService)
+	promptHandler := api.NewPromptHandler(service.NewPromptService(repo))
+
+	// The retention janitor prunes old chats in the background until the app is closed.
+	retentionService := service.NewRetentionService(repo, settingsService, service.RetentionServiceConfig{
+		Interval:  cfg.RetentionInterval,
+		BatchSize: cfg.RetentionBatchSize,
+	})
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	retentionService.Start(backgroundCtx)
+	modelService.StartUpdateChecks(backgroundCtx)
+	healthService := service.NewHealthService(db, provider)
+	healthService.Watch(backgroundCtx, cfg.HealthCheckInterval)
+	backupConfig := service.BackupServiceConfig{Dir: cfg.BackupDir, Keep: cfg.BackupKeep}
+	if strings.EqualFold(strings.TrimSpace(cfg.StorageBackend), repository.BackendMemory) {
+		// The database of the memory backend holds only the settings, so a
+		// snapshot of it would look like a backup without being one.
+		backupConfig.Dir = ""
+	}
+	adminHandler := api.NewAdminHandler(retentionService, service.NewMaintenanceService(repo), service.NewBackupService(db, backupConfig), api.AdminHandlerConfig{
+		APIKey: cfg.AdminAPIKey,
+	})
 
 	// The router ties HTTP routes to specific handler methods.
-	router := api.NewRouter(chatHandler, modelHandler)
+	router := api.NewRouter(chatHandler, modelHandler, documentHandler, promptHandler, adminHandler, api.NewSystemHandler(healthService), appMetrics, cfg.MaxRequestBodyBytes, cfg.EnableCompression, cfg.FrontendDir)
 
 	server := &http.Server{
-		Addr:              ":8000",
+		Addr:              addr,
 		Handler:           router,
 		ReadHeaderTimeout: 20 * time.Second,
 		WriteTimeout:      0, // Disabled for streaming endpoints like chat messages.
@@ -97,12 +216,32 @@ func NewApp(cfg *config.Config) (*App, error) {
 
 	// Return the fully constructed (but not yet running) application.
 	return &App{
-		Config: cfg,
-		DB:     db,
-		Server: server,
+		Config:         cfg,
+		DB:             db,
+		Server:         server,
+		stopBackground: stopBackground,
+		modelService:   modelService,
+		chatService:    chatService,
 	}, nil
 }
 
+// Close stops the background jobs and closes the database connection.
+func (a *App) Close() error {
+	if a.stopBackground != nil {
+		a.stopBackground()
+	}
+	if a.modelService != nil {
+		a.modelService.Close()
+	}
+	if a.chatService != nil {
+		// The titles are saved to the database, so they must be done before it is closed.
+		ctx, cancel := context.WithTimeout(context.Background(), titleShutdownTimeout)
+		a.chatService.Close(ctx)
+		cancel()
+	}
+	return a.DB.Close()
+}
+
 // Run is the main entry point for the application. It orchestrates the entire lifecycle:
 // configuration loading, application setup, and starting the HTTP server.
 func Run() int {
@@ -124,23 +263,56 @@ func Run() int {
 		slog.Error("Failed to initialize application", "error", err)
 		return 1
 	}
-	// Ensure the database connection is gracefully closed on exit.
+	// Ensure background jobs are stopped and the database connection is gracefully closed on exit.
 	defer func() {
-		if err := app.DB.Close(); err != nil {
+		if err := app.Close(); err != nil {
 			slog.Error("Failed to close database connection", "error", err)
 		}
 	}()
 
-	// 4. Start the server and block until it's closed.
-	slog.Info("Starting server", "port", 8000)
-	if err := app.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		slog.Error("Server failed", "error", err)
-		return 1
+	// 4. Start the server and block until it fails or the process is asked to stop.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "addr", app.Server.Addr)
+		serverErr <- app.Server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed", "error", err)
+			return 1
+		}
+	case <-ctx.Done():
+		// Let in-flight requests finish; the deferred Close then stops the
+		// background jobs, including model pulls that have no client attached.
+		slog.Info("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := app.Server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Server shutdown failed", "error", err)
+		}
 	}
 
 	return 0
 }
 
+// shutdownTimeout bounds how long the server waits for in-flight requests on shutdown.
+// Streaming requests rarely finish in time; they are cut off when it expires.
+const shutdownTimeout = 10 * time.Second
+
+// newContentFilter builds the content filter configured for the deployment.
+func newContentFilter(cfg *config.Config) service.ContentFilter {
+	if len(cfg.ContentFilterBannedSubstrings) == 0 {
+		return service.NoopContentFilter{}
+	}
+	slog.Info("Content filter enabled", "banned_substrings", len(cfg.ContentFilterBannedSubstrings), "filter_responses", cfg.ContentFilterResponses)
+	return service.NewBannedSubstringFilter(cfg.ContentFilterBannedSubstrings)
+}
+
 // logConfigSource logs whether the configuration was loaded from a file or from defaults/env vars.
 // This is useful for debugging configuration issues.
 func logConfigSource() {
@@ -172,13 +344,100 @@ func setupLogger(logLevel string) {
 	slog.SetDefault(logger)
 }
 
+// newLLMProvider creates the provider selected by LLM_PROVIDER, which serves
+// unprefixed models, and registers the providers listed in LLM_PROVIDERS, whose
+// models are addressed as "provider/model". An empty LLM_PROVIDER selects Ollama.
+func newLLMProvider(cfg *config.Config) (*llm.ProviderRegistry, error) {
+	defaultName := defaultProviderName(cfg)
+	defaultProvider, err := newProvider(cfg, defaultName)
+	if err != nil {
+		return nil, fmt.Errorf("LLM_PROVIDER: %w", err)
+	}
+	registry := llm.NewProviderRegistry(defaultName, defaultProvider)
+	for _, name := range cfg.LLMProviders {
+		name = strings.TrimSpace(name)
+		if name == "" || name == defaultName {
+			continue
+		}
+		provider, err := newProvider(cfg, name)
+		if err != nil {
+			return nil, fmt.Errorf("LLM_PROVIDERS: %w", err)
+		}
+		registry.Register(name, provider)
+	}
+	return registry, nil
+}
+
+func defaultProviderName(cfg *config.Config) string {
+	if cfg.LLMProvider == "" {
+		return llm.ProviderOllama
+	}
+	return cfg.LLMProvider
+}
+
+// newProvider creates a single provider by name.
+func newProvider(cfg *config.Config, name string) (llm.LLMProvider, error) {
+	switch name {
+	case llm.ProviderOllama:
+		ollamaCfg, err := ollamaConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return llm.NewOllamaProvider(cfg.OllamaURL, ollamaCfg), nil
+	case llm.ProviderOpenAI:
+		return llm.NewOpenAIProvider(cfg.OpenAIBaseURL, llm.OpenAIConfig{APIKey: cfg.OpenAIAPIKey}), nil
+	case llm.ProviderAnthropic:
+		if cfg.AnthropicAPIKey == "" {
+			return nil, errors.New("ANTHROPIC_API_KEY is required for the anthropic provider")
+		}
+		return llm.NewAnthropicProvider(cfg.AnthropicBaseURL, llm.AnthropicConfig{APIKey: cfg.AnthropicAPIKey}), nil
+	case llm.ProviderFake:
+		return llm.NewFakeProvider(llm.FakeConfig{TokensPerSecond: cfg.FakeTokensPerSecond}), nil
+	default:
+		return nil, fmt.Errorf("invalid provider %q: must be %q, %q, %q or %q", name, llm.ProviderOllama, llm.ProviderOpenAI, llm.ProviderAnthropic, llm.ProviderFake)
+	}
+}
+
+// ollamaConfig returns the settings of the Ollama provider. The TLS files are
+// read here, so that a wrong path fails the startup rather than the first chat.
+func ollamaConfig(cfg *config.Config) (llm.OllamaConfig, error) {
+	tlsConfig, err := llm.TLSFiles{
+		CAFile:     cfg.OllamaTLSCAFile,
+		CertFile:   cfg.OllamaTLSCertFile,
+		KeyFile:    cfg.OllamaTLSKeyFile,
+		SkipVerify: cfg.OllamaTLSSkipVerify,
+	}.Load()
+	if err != nil {
+		return llm.OllamaConfig{}, fmt.Errorf("invalid Ollama TLS settings: %w", err)
+	}
+	if cfg.OllamaTLSSkipVerify {
+		slog.Warn("OLLAMA_TLS_SKIP_VERIFY is set: the certificate of Ollama is not verified")
+	}
+	return llm.OllamaConfig{
+		LogPayloads:           cfg.LogLLMPayloads,
+		PayloadLogMaxChars:    cfg.LLMPayloadLogMaxChars,
+		Headers:               cfg.OllamaHeaders,
+		APIKey:                cfg.OllamaAPIKey,
+		BasicAuth:             cfg.OllamaBasicAuth,
+		ConnectTimeout:        cfg.OllamaConnectTimeout,
+		ResponseHeaderTimeout: cfg.OllamaResponseHeaderTimeout,
+		RequestTimeout:        cfg.OllamaRequestTimeout,
+		RetryAttempts:         cfg.OllamaRetryAttempts,
+		RetryBackoff:          cfg.OllamaRetryBackoff,
+		TLS:                   tlsConfig,
+	}, nil
+}
+
 // waitForOllama is a simple blocking health check. It ensures that the application
-// does not start until its critical dependency (Ollama) is responsive.
-func waitForOllama(ollamaURL string) {
+// does not start until its critical dependency (Ollama) is responsive. It
+// connects with the provider's transport and credentials, so that an Ollama
+// behind an authenticating or HTTPS proxy is not reported as down.
+func waitForOllama(ollamaURL string, ollamaCfg llm.OllamaConfig) {
 	slog.Info("Waiting for Ollama to be ready...")
-	client := &http.Client{Timeout: 2 * time.Second}
+	header := ollamaCfg.Header()
+	client := &http.Client{Timeout: 2 * time.Second, Transport: ollamaCfg.Transport()}
 	for {
-		resp, err := client.Get(ollamaURL)
+		resp, err := ollamaHealthCheck(client, ollamaURL, header)
 		if err == nil && resp.StatusCode == http.StatusOK {
 			if bErr := resp.Body.Close(); bErr != nil {
 				slog.Warn("Failed to close response body in ollama health check", "error", bErr)
@@ -197,3 +456,13 @@ func waitForOllama(ollamaURL string) {
 		time.Sleep(3 * time.Second)
 	}
 }
+
+// ollamaHealthCheck requests the root of Ollama with the given header.
+func ollamaHealthCheck(client *http.Client, ollamaURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, ollamaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+	return client.Do(req)
+}
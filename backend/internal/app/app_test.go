@@ -1,10 +1,14 @@
 package app
 
 import (
+	"encoding/pem"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -50,6 +54,8 @@ func TestNewApp(t *testing.T) {
 		DatabasePath: dbFile.Name(),
 		OllamaURL:    ollamaServer.URL,
 		LogLevel:     "DEBUG",
+		Host:         "127.0.0.1",
+		AppPort:      8123,
 	}
 
 	// ACT: Call the function we are testing.
@@ -64,9 +70,263 @@ func TestNewApp(t *testing.T) {
 
 	// Clean up the resources created by `NewApp`.
 	// This is crucial to prevent leaking database connections.
-	defer func() { require.NoError(t, app.DB.Close()) }()
+	defer func() { require.NoError(t, app.Close()) }()
 
 	// Finally, assert that the core components within the App struct were initialized.
 	assert.NotNil(t, app.DB)
 	assert.NotNil(t, app.Server)
+	// The server binds to the configured address.
+	assert.Equal(t, "127.0.0.1:8123", app.Server.Addr)
+}
+
+// TestNewApp_InvalidPort verifies that an out-of-range port is rejected before
+// any dependency is touched.
+//
+// WHY: No Ollama server is running here, so a call that got as far as
+// `waitForOllama` would block forever instead of failing.
+func TestNewApp_InvalidPort(t *testing.T) {
+	for _, port := range []int{0, -1, 65536} {
+		cfg := &config.Config{
+			DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+			OllamaURL:    "http://127.0.0.1:1",
+			AppPort:      port,
+		}
+
+		app, err := NewApp(cfg)
+
+		require.Error(t, err, "port %d", port)
+		assert.Contains(t, err.Error(), "SERVER_PORT")
+		assert.Nil(t, app)
+	}
+}
+
+// TestNewApp_InvalidLLMProvider verifies that an unknown LLM_PROVIDER is rejected
+// before any dependency is touched.
+func TestNewApp_InvalidLLMProvider(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:    "http://127.0.0.1:1",
+		LLMProvider:  "llamafile",
+		AppPort:      8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LLM_PROVIDER")
+	assert.Nil(t, app)
+}
+
+// TestNewApp_InvalidOllamaTLS verifies that a wrong certificate path fails the
+// startup before Ollama is waited for.
+func TestNewApp_InvalidOllamaTLS(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath:    filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:       "https://127.0.0.1:1",
+		OllamaTLSCAFile: "/missing/ca.pem",
+		AppPort:         8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/missing/ca.pem")
+	assert.Nil(t, app)
+}
+
+// TestNewApp_InvalidStorageBackend verifies that an unknown STORAGE_BACKEND is
+// rejected before Ollama is waited for or a database is created.
+func TestNewApp_InvalidStorageBackend(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath:   filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:      "http://127.0.0.1:1",
+		StorageBackend: "postgres",
+		AppPort:        8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "STORAGE_BACKEND")
+	assert.Contains(t, err.Error(), `"postgres"`)
+	assert.Nil(t, app)
+	assert.NoFileExists(t, cfg.DatabasePath)
+}
+
+// TestNewApp_InvalidAdditionalProvider verifies that every entry of LLM_PROVIDERS
+// must be a known provider.
+func TestNewApp_InvalidAdditionalProvider(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:    "http://127.0.0.1:1",
+		LLMProvider:  "openai",
+		LLMProviders: []string{"ollama", "llamafile"},
+		AppPort:      8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LLM_PROVIDERS")
+	assert.Nil(t, app)
+}
+
+// TestNewApp_OpenAIProvider verifies that the application starts against an
+// OpenAI-compatible API without waiting for Ollama.
+//
+// WHY: OLLAMA_URL points at a closed port, so waiting for Ollama would block forever.
+func TestNewApp_OpenAIProvider(t *testing.T) {
+	openaiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "gpt-4o-mini", "object": "model", "created": 1721172741}]}`))
+	}))
+	defer openaiServer.Close()
+	cfg := &config.Config{
+		DatabasePath:  filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:     "http://127.0.0.1:1",
+		LLMProvider:   "openai",
+		OpenAIBaseURL: openaiServer.URL,
+		Host:          "127.0.0.1",
+		AppPort:       8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.NoError(t, err)
+	defer func() { require.NoError(t, app.Close()) }()
+	assert.NotNil(t, app.Server)
+}
+
+// TestNewApp_FakeProvider verifies that the application starts without any
+// model backend when the fake provider is selected.
+//
+// WHY: OLLAMA_URL points at a closed port, so waiting for Ollama would block forever.
+func TestNewApp_FakeProvider(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:    "http://127.0.0.1:1",
+		LLMProvider:  "fake",
+		Host:         "127.0.0.1",
+		AppPort:      8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.NoError(t, err)
+	defer func() { require.NoError(t, app.Close()) }()
+	assert.NotNil(t, app.Server)
+}
+
+// TestNewApp_DemoMode verifies that the fake provider and the memory backend
+// start the application without Ollama and without writing a database file.
+func TestNewApp_DemoMode(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath:   filepath.Join(t.TempDir(), "test.db"),
+		OllamaURL:      "http://127.0.0.1:1",
+		LLMProvider:    "fake",
+		StorageBackend: "memory",
+		Host:           "127.0.0.1",
+		AppPort:        8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.NoError(t, err)
+	defer func() { require.NoError(t, app.Close()) }()
+	assert.NotNil(t, app.Server)
+	assert.NoFileExists(t, cfg.DatabasePath)
+}
+
+// TestNewApp_Metrics verifies that /metrics is served only when enabled and
+// that it reports the database queries made during startup.
+func TestNewApp_Metrics(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			cfg := &config.Config{
+				DatabasePath:   filepath.Join(t.TempDir(), "test.db"),
+				LLMProvider:    "fake",
+				MetricsEnabled: enabled,
+				AppPort:        8123,
+			}
+			app, err := NewApp(cfg)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, app.Close()) }()
+
+			rr := httptest.NewRecorder()
+			app.Server.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			if !enabled {
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `flowai_db_query_duration_seconds_count{statement="SELECT"}`)
+		})
+	}
+}
+
+// TestNewApp_AnthropicWithoutKey verifies that the Anthropic provider requires an API key.
+func TestNewApp_AnthropicWithoutKey(t *testing.T) {
+	cfg := &config.Config{
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+		LLMProvider:  "anthropic",
+		AppPort:      8123,
+	}
+
+	app, err := NewApp(cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ANTHROPIC_API_KEY")
+	assert.Nil(t, app)
+}
+
+// TestWaitForOllama_Header verifies that the startup check sends the same
+// credentials as the provider, so that an authenticating proxy lets it through.
+func TestWaitForOllama_Header(t *testing.T) {
+	// ARRANGE
+	var received string
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ollamaServer.Close()
+	cfg := &config.Config{OllamaURL: ollamaServer.URL, OllamaAPIKey: "secret"}
+
+	ollamaCfg, err := ollamaConfig(cfg)
+	require.NoError(t, err)
+
+	// ACT
+	waitForOllama(cfg.OllamaURL, ollamaCfg)
+
+	// ASSERT
+	assert.Equal(t, "Bearer secret", received)
+}
+
+// TestWaitForOllama_TLS verifies that the startup check trusts the configured CA
+// like the provider does; otherwise it would wait forever.
+func TestWaitForOllama_TLS(t *testing.T) {
+	// ARRANGE
+	ollamaServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ollamaServer.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ollamaServer.Certificate().Raw}), 0o600))
+	cfg := &config.Config{OllamaURL: ollamaServer.URL, OllamaTLSCAFile: caFile}
+	ollamaCfg, err := ollamaConfig(cfg)
+	require.NoError(t, err)
+
+	// ACT
+	done := make(chan struct{})
+	go func() {
+		waitForOllama(cfg.OllamaURL, ollamaCfg)
+		close(done)
+	}()
+
+	// ASSERT
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForOllama did not accept the certificate of the configured CA")
+	}
 }
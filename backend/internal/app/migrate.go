@@ -0,0 +1,98 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"flow-ai/backend/internal/config"
+	"flow-ai/backend/internal/database"
+)
+
+const migrateUsage = "usage: server migrate up | down [steps] | version | force <version>"
+
+// errMigrateUsage reports a malformed `migrate` command line.
+var errMigrateUsage = errors.New(migrateUsage)
+
+// RunMigrate is the entry point of the `migrate` subcommand. It runs a single
+// migration command against the configured database without starting the HTTP
+// server, and returns the process exit code.
+func RunMigrate(args []string) int {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		return 1
+	}
+	setupLogger(cfg.LogLevel)
+
+	m, err := database.NewMigrator(cfg.DatabasePath)
+	if err != nil {
+		slog.Error("Failed to open database", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := m.Close(); err != nil {
+			slog.Error("Failed to close database connection", "error", err)
+		}
+	}()
+
+	if err := runMigrateCommand(m, args, os.Stdout); err != nil {
+		if errors.Is(err, errMigrateUsage) {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		slog.Error("Migration command failed", "command", args, "error", err)
+		return 1
+	}
+	return 0
+}
+
+// runMigrateCommand executes the command in args and prints the resulting
+// schema version to out.
+func runMigrateCommand(m *database.Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	switch cmd, rest := args[0], args[1:]; {
+	case cmd == "up" && len(rest) == 0:
+		if err := m.Up(); err != nil {
+			return err
+		}
+	case cmd == "down" && len(rest) <= 1:
+		// Roll back a single migration unless told otherwise, so that a slip of
+		// the keyboard never wipes the whole schema.
+		steps := 1
+		if len(rest) == 1 {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil {
+				return fmt.Errorf("%w: invalid number of steps %q", errMigrateUsage, rest[0])
+			}
+			steps = n
+		}
+		if err := m.Down(steps); err != nil {
+			return err
+		}
+	case cmd == "force" && len(rest) == 1:
+		version, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return fmt.Errorf("%w: invalid version %q", errMigrateUsage, rest[0])
+		}
+		if err := m.Force(version); err != nil {
+			return err
+		}
+	case cmd == "version" && len(rest) == 0:
+	default:
+		return errMigrateUsage
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "version %d (dirty: %t)\n", version, dirty)
+	return err
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/database"
+)
+
+// TestRunMigrateCommand verifies the parsing of the `migrate` subcommand.
+func TestRunMigrateCommand(t *testing.T) {
+	m, err := database.NewMigrator(filepath.Join(t.TempDir(), "cli.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runMigrateCommand(m, args, &out)
+		return out.String(), err
+	}
+
+	out, err := run("version")
+	require.NoError(t, err)
+	assert.Equal(t, "version 0 (dirty: false)\n", out)
+
+	_, err = run("up")
+	require.NoError(t, err)
+	latest, _, err := m.Version()
+	require.NoError(t, err)
+
+	out, err = run("down", "2")
+	require.NoError(t, err)
+	version, _, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest-2, version)
+	assert.Contains(t, out, "dirty: false")
+
+	_, err = run("down")
+	require.NoError(t, err)
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest-3, version, "down without steps rolls back one migration")
+
+	for _, args := range [][]string{{}, {"sideways"}, {"up", "1"}, {"down", "x"}, {"force"}, {"force", "x"}} {
+		_, err := run(args...)
+		assert.ErrorIs(t, err, errMigrateUsage, "args %v", args)
+	}
+}
@@ -1,25 +1,233 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	AppPort             int    `mapstructure:"APP_PORT"`
-	DatabasePath        string `mapstructure:"DATABASE_PATH"`
-	OllamaURL           string `mapstructure:"OLLAMA_URL"`
+	// Host and AppPort are the address the HTTP server listens on; an empty host
+	// listens on all interfaces. They are read from SERVER_HOST and SERVER_PORT
+	// because APP_PORT is the port docker compose publishes on the host.
+	Host    string `mapstructure:"SERVER_HOST"`
+	AppPort int    `mapstructure:"SERVER_PORT"`
+	// StorageBackend selects the implementation of the repository: "sqlite", or
+	// "memory" for a demo that keeps nothing.
+	StorageBackend string `mapstructure:"STORAGE_BACKEND"`
+	DatabasePath   string `mapstructure:"DATABASE_PATH"`
+	// FrontendDir holds the built frontend that is served for all paths outside
+	// the API; empty serves no frontend.
+	FrontendDir string `mapstructure:"FRONTEND_DIR"`
+	// DBMaxOpenConns, DBMaxIdleConns and DBConnMaxLifetime configure the SQLite
+	// connection pool; 0 keeps the database/sql defaults.
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	// DBBusyTimeout is how long a write waits for the database lock before failing.
+	DBBusyTimeout time.Duration `mapstructure:"DB_BUSY_TIMEOUT"`
+	// LLMProvider selects the API models are served by: "ollama", "openai" for
+	// any OpenAI-compatible API, such as OpenRouter or llama.cpp's server,
+	// "anthropic", or "fake", which answers with lorem ipsum without a model.
+	LLMProvider string `mapstructure:"LLM_PROVIDER"`
+	// LLMProviders is a comma-separated list of additional providers, whose
+	// models are addressed as "provider/model", e.g. "openai/gpt-4o".
+	LLMProviders []string `mapstructure:"LLM_PROVIDERS"`
+	// OpenAIBaseURL and OpenAIAPIKey configure the OpenAI-compatible provider.
+	OpenAIBaseURL string `mapstructure:"OPENAI_BASE_URL"`
+	OpenAIAPIKey  string `mapstructure:"OPENAI_API_KEY"`
+	// AnthropicBaseURL and AnthropicAPIKey configure the Anthropic provider.
+	AnthropicBaseURL string `mapstructure:"ANTHROPIC_BASE_URL"`
+	AnthropicAPIKey  string `mapstructure:"ANTHROPIC_API_KEY"`
+	// FakeTokensPerSecond is the speed at which the fake provider streams its
+	// answers; 0 streams them without delay.
+	FakeTokensPerSecond float64 `mapstructure:"FAKE_TOKENS_PER_SECOND"`
+	OllamaURL           string  `mapstructure:"OLLAMA_URL"`
+	// OllamaHeaders are sent with every request to Ollama, e.g. for an auth proxy
+	// in front of it. They are read from OLLAMA_HEADERS as a comma-separated list
+	// of "Name: value" pairs.
+	OllamaHeaders map[string]string `mapstructure:"-"`
+	// OllamaAPIKey is sent as a bearer token and OllamaBasicAuth, given as
+	// "user:password", as basic credentials to a remote or proxied Ollama. At
+	// most one of them may be set.
+	OllamaAPIKey    string `mapstructure:"OLLAMA_API_KEY"`
+	OllamaBasicAuth string `mapstructure:"OLLAMA_BASIC_AUTH"`
+	// OllamaConnectTimeout, OllamaResponseHeaderTimeout and OllamaRequestTimeout
+	// bound connecting to Ollama, waiting for its response and a whole request
+	// that is not streamed; 0 disables a timeout.
+	OllamaConnectTimeout        time.Duration `mapstructure:"OLLAMA_CONNECT_TIMEOUT"`
+	OllamaResponseHeaderTimeout time.Duration `mapstructure:"OLLAMA_RESPONSE_HEADER_TIMEOUT"`
+	OllamaRequestTimeout        time.Duration `mapstructure:"OLLAMA_REQUEST_TIMEOUT"`
+	// OllamaRetryAttempts is how often a request that is safe to repeat is sent
+	// to Ollama, waiting OllamaRetryBackoff before the first retry and twice as
+	// long before each further one.
+	OllamaRetryAttempts int           `mapstructure:"OLLAMA_RETRY_ATTEMPTS"`
+	OllamaRetryBackoff  time.Duration `mapstructure:"OLLAMA_RETRY_BACKOFF"`
+	// OllamaTLSCAFile is a PEM bundle of CAs trusted for an Ollama served over
+	// HTTPS, and OllamaTLSCertFile and OllamaTLSKeyFile are a client certificate
+	// for mutual TLS. OllamaTLSSkipVerify accepts any certificate.
+	OllamaTLSCAFile     string `mapstructure:"OLLAMA_TLS_CA_FILE"`
+	OllamaTLSCertFile   string `mapstructure:"OLLAMA_TLS_CERT_FILE"`
+	OllamaTLSKeyFile    string `mapstructure:"OLLAMA_TLS_KEY_FILE"`
+	OllamaTLSSkipVerify bool   `mapstructure:"OLLAMA_TLS_SKIP_VERIFY"`
+	// HealthCheckInterval is how often the LLM provider is checked in the
+	// background to log when it goes down or comes back; 0 disables the checks.
+	HealthCheckInterval time.Duration `mapstructure:"HEALTH_CHECK_INTERVAL"`
+	// MetricsEnabled serves Prometheus metrics of the LLM calls, HTTP requests
+	// and database queries at /metrics.
+	MetricsEnabled bool `mapstructure:"METRICS_ENABLED"`
+	// EnableCompression gzips the JSON responses of clients that accept it.
+	// Streamed responses are never compressed.
+	EnableCompression   bool   `mapstructure:"ENABLE_COMPRESSION"`
 	InitialSystemPrompt string `mapstructure:"INITIAL_SYSTEM_PROMPT"`
 	LogLevel            string `mapstructure:"LOG_LEVEL"`
+	// LogLLMPayloads logs full LLM requests and responses. It only takes effect
+	// together with LOG_LEVEL=DEBUG.
+	LogLLMPayloads bool `mapstructure:"LOG_LLM_PAYLOADS"`
+	// LLMPayloadLogMaxChars truncates logged payloads; 0 disables truncation.
+	LLMPayloadLogMaxChars int `mapstructure:"LLM_PAYLOAD_LOG_MAX_CHARS"`
+	// DefaultUserID owns every chat while the application is single-user.
+	DefaultUserID string `mapstructure:"DEFAULT_USER_ID"`
+	// TitlePreviewLength is the number of characters of the first message used as
+	// a chat's temporary title until a proper one is generated.
+	TitlePreviewLength int `mapstructure:"TITLE_PREVIEW_LENGTH"`
+	// TitleMaxLength caps the length of titles generated by the support model.
+	TitleMaxLength int `mapstructure:"TITLE_MAX_LENGTH"`
+	// TitleTimeout bounds each attempt of the support model to generate a title.
+	TitleTimeout time.Duration `mapstructure:"TITLE_TIMEOUT"`
+	// MaxImageBytes is the maximum size of a single image attached to a message.
+	MaxImageBytes int64 `mapstructure:"MAX_IMAGE_BYTES"`
+	// MaxRequestBodyBytes is the maximum size of a JSON request body, which must
+	// leave room for base64-encoded images; 0 disables the limit.
+	MaxRequestBodyBytes int64 `mapstructure:"MAX_REQUEST_BODY_BYTES"`
+	// SSEHeartbeatInterval is the longest silence on a message stream before a
+	// keep-alive comment is sent; a negative value disables heartbeats.
+	SSEHeartbeatInterval time.Duration `mapstructure:"SSE_HEARTBEAT_INTERVAL"`
+	// IdempotencyTTL is how long message requests are deduplicated by their Idempotency-Key header.
+	IdempotencyTTL time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	// EmbeddingModel embeds document collections that don't name their own model.
+	EmbeddingModel string `mapstructure:"EMBEDDING_MODEL"`
+	// RAGChunkSize and RAGChunkOverlap control how uploaded documents are split, in characters.
+	RAGChunkSize    int `mapstructure:"RAG_CHUNK_SIZE"`
+	RAGChunkOverlap int `mapstructure:"RAG_CHUNK_OVERLAP"`
+	// RAGTopK is the number of document chunks added to a prompt.
+	RAGTopK int `mapstructure:"RAG_TOP_K"`
+	// RetentionInterval is how often old chats are pruned according to the
+	// retention settings; 0 disables the background janitor.
+	RetentionInterval time.Duration `mapstructure:"RETENTION_INTERVAL"`
+	// RetentionBatchSize is the number of chats deleted per database transaction.
+	RetentionBatchSize int `mapstructure:"RETENTION_BATCH_SIZE"`
+	// ContentFilterBannedSubstrings is a comma-separated list of case-insensitive
+	// substrings that block a message; empty disables content filtering.
+	ContentFilterBannedSubstrings []string `mapstructure:"CONTENT_FILTER_BANNED_SUBSTRINGS"`
+	// ContentFilterResponses also applies the content filter to model answers.
+	ContentFilterResponses bool `mapstructure:"CONTENT_FILTER_RESPONSES"`
+	// ResponseCacheSize is the number of answers to deterministic requests (fixed
+	// seed, temperature 0) kept in memory; 0 disables the response cache.
+	ResponseCacheSize int `mapstructure:"RESPONSE_CACHE_SIZE"`
+	// MaxConcurrentGenerations limits the answers generated at once; 0 is no
+	// limit. A generation beyond it waits up to GenerationQueueTimeout for a free
+	// slot, and fails at once if that is 0.
+	MaxConcurrentGenerations int           `mapstructure:"MAX_CONCURRENT_GENERATIONS"`
+	GenerationQueueTimeout   time.Duration `mapstructure:"GENERATION_QUEUE_TIMEOUT"`
+	// OllamaModelsPath is a path on the volume that holds Ollama's models, as seen
+	// by the backend. It is used to report free disk space; empty disables that.
+	OllamaModelsPath string `mapstructure:"OLLAMA_MODELS_PATH"`
+	// PullCancelOnDisconnect cancels a model download once no client streams its
+	// progress anymore, instead of finishing it in the background.
+	PullCancelOnDisconnect bool `mapstructure:"PULL_CANCEL_ON_DISCONNECT"`
+	// RegistryURL is the public model library searched for models to pull.
+	RegistryURL string `mapstructure:"REGISTRY_URL"`
+	// RegistryTimeout bounds a single search of the model library.
+	RegistryTimeout time.Duration `mapstructure:"REGISTRY_TIMEOUT"`
+	// RegistryCacheTTL is how long library search results are reused; 0 disables the cache.
+	RegistryCacheTTL time.Duration `mapstructure:"REGISTRY_CACHE_TTL"`
+	// RegistryManifestURL is the registry that serves the manifests of the models,
+	// which are compared with the installed models to find updates.
+	RegistryManifestURL string `mapstructure:"REGISTRY_MANIFEST_URL"`
+	// ModelUpdateCheckInterval is how often the installed models are checked for
+	// updates; 0 disables the checks.
+	ModelUpdateCheckInterval time.Duration `mapstructure:"MODEL_UPDATE_CHECK_INTERVAL"`
+	// AdminAPIKey protects the /admin endpoints; empty leaves them open.
+	AdminAPIKey string `mapstructure:"ADMIN_API_KEY"`
+	// BackupDir is the directory POST /admin/backup writes database snapshots to;
+	// empty disables backups.
+	BackupDir string `mapstructure:"BACKUP_DIR"`
+	// BackupKeep is the number of snapshots kept; 0 keeps all of them.
+	BackupKeep int `mapstructure:"BACKUP_KEEP"`
 }
 
 func LoadConfig() (*Config, error) {
-	viper.SetDefault("APP_PORT", 3000)
+	viper.SetDefault("SERVER_HOST", "")
+	viper.SetDefault("SERVER_PORT", 8000)
+	viper.SetDefault("STORAGE_BACKEND", "sqlite")
 	viper.SetDefault("DATABASE_PATH", "/data/flow.db")
+	viper.SetDefault("FRONTEND_DIR", "./frontend/dist")
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 0)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 2)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "0s")
+	viper.SetDefault("DB_BUSY_TIMEOUT", "5s")
+	viper.SetDefault("LLM_PROVIDER", "ollama")
+	viper.SetDefault("LLM_PROVIDERS", "")
+	viper.SetDefault("OPENAI_BASE_URL", "https://api.openai.com/v1")
+	viper.SetDefault("OPENAI_API_KEY", "")
+	viper.SetDefault("ANTHROPIC_BASE_URL", "https://api.anthropic.com")
+	viper.SetDefault("ANTHROPIC_API_KEY", "")
+	viper.SetDefault("FAKE_TOKENS_PER_SECOND", 20)
 	viper.SetDefault("OLLAMA_URL", "http://ollama:11434")
+	viper.SetDefault("OLLAMA_HEADERS", "")
+	viper.SetDefault("OLLAMA_API_KEY", "")
+	viper.SetDefault("OLLAMA_BASIC_AUTH", "")
+	viper.SetDefault("OLLAMA_CONNECT_TIMEOUT", "10s")
+	viper.SetDefault("OLLAMA_RESPONSE_HEADER_TIMEOUT", "10m")
+	viper.SetDefault("OLLAMA_REQUEST_TIMEOUT", "10m")
+	viper.SetDefault("OLLAMA_RETRY_ATTEMPTS", 3)
+	viper.SetDefault("OLLAMA_RETRY_BACKOFF", "500ms")
+	viper.SetDefault("OLLAMA_TLS_CA_FILE", "")
+	viper.SetDefault("OLLAMA_TLS_CERT_FILE", "")
+	viper.SetDefault("OLLAMA_TLS_KEY_FILE", "")
+	viper.SetDefault("OLLAMA_TLS_SKIP_VERIFY", false)
+	viper.SetDefault("HEALTH_CHECK_INTERVAL", "30s")
+	viper.SetDefault("METRICS_ENABLED", true)
+	viper.SetDefault("ENABLE_COMPRESSION", true)
 	viper.SetDefault("INITIAL_SYSTEM_PROMPT", "You are a helpful assistant.")
 	viper.SetDefault("LOG_LEVEL", "INFO")
+	viper.SetDefault("LOG_LLM_PAYLOADS", false)
+	viper.SetDefault("LLM_PAYLOAD_LOG_MAX_CHARS", 4000)
+	viper.SetDefault("DEFAULT_USER_ID", "default-user")
+	viper.SetDefault("TITLE_PREVIEW_LENGTH", 50)
+	viper.SetDefault("TITLE_MAX_LENGTH", 60)
+	viper.SetDefault("TITLE_TIMEOUT", "30s")
+	viper.SetDefault("MAX_IMAGE_BYTES", 10<<20)
+	viper.SetDefault("MAX_REQUEST_BODY_BYTES", 32<<20)
+	viper.SetDefault("SSE_HEARTBEAT_INTERVAL", "15s")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("EMBEDDING_MODEL", "nomic-embed-text")
+	viper.SetDefault("RAG_CHUNK_SIZE", 1000)
+	viper.SetDefault("RAG_CHUNK_OVERLAP", 200)
+	viper.SetDefault("RAG_TOP_K", 4)
+	viper.SetDefault("RETENTION_INTERVAL", "1h")
+	viper.SetDefault("RETENTION_BATCH_SIZE", 100)
+	viper.SetDefault("CONTENT_FILTER_BANNED_SUBSTRINGS", "")
+	viper.SetDefault("CONTENT_FILTER_RESPONSES", false)
+	viper.SetDefault("RESPONSE_CACHE_SIZE", 0)
+	viper.SetDefault("MAX_CONCURRENT_GENERATIONS", 0)
+	viper.SetDefault("GENERATION_QUEUE_TIMEOUT", "2m")
+	viper.SetDefault("OLLAMA_MODELS_PATH", "")
+	viper.SetDefault("PULL_CANCEL_ON_DISCONNECT", false)
+	viper.SetDefault("ADMIN_API_KEY", "")
+	viper.SetDefault("BACKUP_DIR", "/data/backups")
+	viper.SetDefault("BACKUP_KEEP", 7)
+	viper.SetDefault("REGISTRY_URL", "https://ollama.com")
+	viper.SetDefault("REGISTRY_TIMEOUT", "10s")
+	viper.SetDefault("REGISTRY_CACHE_TTL", "10m")
+	viper.SetDefault("REGISTRY_MANIFEST_URL", "https://registry.ollama.ai")
+	viper.SetDefault("MODEL_UPDATE_CHECK_INTERVAL", "24h")
 
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
@@ -40,6 +248,46 @@ func LoadConfig() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	headers, err := parseHeaders(viper.GetString("OLLAMA_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OLLAMA_HEADERS: %w", err)
+	}
+	cfg.OllamaHeaders = headers
+	if cfg.OllamaAPIKey != "" && cfg.OllamaBasicAuth != "" {
+		return nil, errors.New("OLLAMA_API_KEY and OLLAMA_BASIC_AUTH cannot both be set")
+	}
+	if cfg.OllamaBasicAuth != "" && !strings.Contains(cfg.OllamaBasicAuth, ":") {
+		return nil, errors.New("invalid OLLAMA_BASIC_AUTH: must be of the form \"user:password\"")
+	}
+	if (cfg.OllamaTLSCertFile == "") != (cfg.OllamaTLSKeyFile == "") {
+		return nil, errors.New("OLLAMA_TLS_CERT_FILE and OLLAMA_TLS_KEY_FILE must be set together")
+	}
 
 	return &cfg, nil
 }
+
+// parseHeaders parses a comma-separated list of "Name: value" pairs.
+func parseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not of the form \"Name: value\"", strings.TrimSpace(pair))
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// ListenAddr returns the address the HTTP server binds to, or an error if the
+// configured port is out of range.
+func (c *Config) ListenAddr() (string, error) {
+	if c.AppPort < 1 || c.AppPort > 65535 {
+		return "", fmt.Errorf("invalid SERVER_PORT %d: must be between 1 and 65535", c.AppPort)
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.AppPort)), nil
+}
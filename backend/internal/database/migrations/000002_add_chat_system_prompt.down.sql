@@ -0,0 +1 @@
+ALTER TABLE chats DROP COLUMN system_prompt;
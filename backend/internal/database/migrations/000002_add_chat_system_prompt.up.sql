@@ -0,0 +1,2 @@
+-- Chats can be pre-created with their own system prompt before the first message.
+ALTER TABLE chats ADD COLUMN system_prompt TEXT NOT NULL DEFAULT '';
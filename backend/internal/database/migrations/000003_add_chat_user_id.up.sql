@@ -0,0 +1,2 @@
+-- Chats are owned by a user; existing rows belong to the single default user.
+ALTER TABLE chats ADD COLUMN user_id TEXT NOT NULL DEFAULT 'default-user';
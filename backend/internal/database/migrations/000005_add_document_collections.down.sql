@@ -0,0 +1,9 @@
+ALTER TABLE chats DROP COLUMN collection_id;
+
+DROP INDEX IF EXISTS idx_document_chunks_collection_id;
+DROP TABLE IF EXISTS document_chunks;
+
+DROP INDEX IF EXISTS idx_documents_collection_id;
+DROP TABLE IF EXISTS documents;
+
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,32 @@
+-- Document collections for retrieval-augmented generation (RAG).
+CREATE TABLE IF NOT EXISTS collections (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    embedding_model TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+    id TEXT PRIMARY KEY,
+    collection_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    chunk_count INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_documents_collection_id ON documents(collection_id);
+
+-- Embeddings are stored as little-endian float32 arrays.
+CREATE TABLE IF NOT EXISTS document_chunks (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL,
+    collection_id TEXT NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    embedding BLOB NOT NULL,
+    FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_document_chunks_collection_id ON document_chunks(collection_id);
+
+-- A chat with a collection retrieves context from it for every message.
+ALTER TABLE chats ADD COLUMN collection_id TEXT NOT NULL DEFAULT '';
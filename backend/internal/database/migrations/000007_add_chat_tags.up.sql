@@ -0,0 +1,8 @@
+-- Tags group chats by topic. A chat can have many tags and a tag many chats.
+CREATE TABLE IF NOT EXISTS chat_tags (
+    chat_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (chat_id, tag),
+    FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_chat_tags_tag ON chat_tags(tag);
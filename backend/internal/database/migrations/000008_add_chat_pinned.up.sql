@@ -0,0 +1,2 @@
+-- Pinned chats are listed before all others.
+ALTER TABLE chats ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- Default generation options per model, stored as the JSON of `llm.RequestOptions`.
+CREATE TABLE IF NOT EXISTS model_defaults (
+    model TEXT PRIMARY KEY,
+    options TEXT NOT NULL,
+    updated_at DATETIME NOT NULL
+);
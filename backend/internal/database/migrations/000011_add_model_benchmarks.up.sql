@@ -0,0 +1,9 @@
+-- The result of the last benchmark of each model.
+CREATE TABLE IF NOT EXISTS model_benchmarks (
+    model TEXT PRIMARY KEY,
+    runs INTEGER NOT NULL,
+    tokens_per_second REAL NOT NULL,
+    load_ms REAL NOT NULL,
+    first_token_ms REAL NOT NULL,
+    created_at DATETIME NOT NULL
+);
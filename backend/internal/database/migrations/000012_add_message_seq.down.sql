@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_chat_id_seq;
+ALTER TABLE messages DROP COLUMN seq;
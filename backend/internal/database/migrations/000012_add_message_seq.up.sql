@@ -0,0 +1,11 @@
+-- Messages created in the same instant, such as a user message and a quick
+-- assistant answer, cannot be ordered by timestamp. seq numbers the messages of
+-- each chat in the order they were added; timestamp is kept for display.
+ALTER TABLE messages ADD COLUMN seq INTEGER NOT NULL DEFAULT 0;
+UPDATE messages SET seq = ordered.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY chat_id ORDER BY timestamp, rowid) AS seq
+    FROM messages
+) AS ordered
+WHERE messages.id = ordered.id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_chat_id_seq ON messages(chat_id, seq);
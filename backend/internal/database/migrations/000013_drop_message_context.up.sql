@@ -0,0 +1,3 @@
+-- The Ollama context of a message was never filled: /api/chat does not return
+-- one. The conversation is carried by the message history instead.
+ALTER TABLE messages DROP COLUMN context;
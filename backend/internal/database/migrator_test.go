@@ -0,0 +1,118 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrator_UpDown applies all migrations to a fresh database, then rolls the
+// latest one back and re-applies it.
+//
+// WHY: Every `.down.sql` must undo its `.up.sql`, or a rollback in production
+// would leave the schema in a state no migration expects.
+func TestMigrator_UpDown(t *testing.T) {
+	// ARRANGE
+	m, err := NewMigrator(filepath.Join(t.TempDir(), "migrate.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+
+	version, _, err := m.Version()
+	require.NoError(t, err)
+	assert.Zero(t, version, "a new database has no migrations")
+
+	// ACT & ASSERT: Up applies everything.
+	require.NoError(t, m.Up())
+	latest, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	require.Greater(t, latest, uint(1))
+
+	// Down rolls back exactly one migration.
+	require.NoError(t, m.Down(1))
+	version, dirty, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest-1, version)
+	assert.False(t, dirty)
+
+	// The rolled-back migration can be applied again.
+	require.NoError(t, m.Up())
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	// Up is idempotent.
+	assert.NoError(t, m.Up())
+}
+
+// TestMigrator_MessageSeqBackfill verifies that the migration adding `seq`
+// numbers the existing messages of each chat in timestamp order.
+func TestMigrator_MessageSeqBackfill(t *testing.T) {
+	// ARRANGE: A database just before the migration, with messages inserted out
+	// of timestamp order.
+	path := filepath.Join(t.TempDir(), "seq.db")
+	m, err := NewMigrator(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+	require.NoError(t, m.Up())
+	latest, _, err := m.Version()
+	require.NoError(t, err)
+	require.NoError(t, m.Down(int(latest)-11))
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(`
+		INSERT INTO chats (id, title, model, created_at, updated_at) VALUES
+			('chat1', 'One', 'm', '2024-01-01', '2024-01-01'),
+			('chat2', 'Two', 'm', '2024-01-01', '2024-01-01');
+		INSERT INTO messages (id, chat_id, role, content, timestamp) VALUES
+			('b', 'chat1', 'assistant', 'x', '2024-01-01 12:01:00'),
+			('a', 'chat1', 'user', 'x', '2024-01-01 12:00:00'),
+			('c', 'chat2', 'user', 'x', '2024-01-01 11:00:00');
+	`)
+	require.NoError(t, err)
+
+	// ACT
+	require.NoError(t, m.Up())
+
+	// ASSERT: Each chat is numbered from 1.
+	seqs := map[string]int{}
+	rows, err := db.Query("SELECT id, seq FROM messages")
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id string
+		var seq int
+		require.NoError(t, rows.Scan(&id, &seq))
+		seqs[id] = seq
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 1}, seqs)
+}
+
+// TestMigrator_Force verifies that forcing a version clears the dirty flag.
+func TestMigrator_Force(t *testing.T) {
+	m, err := NewMigrator(filepath.Join(t.TempDir(), "force.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+	require.NoError(t, m.Up())
+
+	require.NoError(t, m.Force(2))
+
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.False(t, dirty)
+}
+
+func TestMigrator_DownRejectsInvalidSteps(t *testing.T) {
+	m, err := NewMigrator(filepath.Join(t.TempDir(), "steps.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+
+	assert.Error(t, m.Down(0))
+}
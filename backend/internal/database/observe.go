@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	gosqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// QueryObserver is told how long each statement took. The statement is the
+// leading SQL keyword, e.g. "SELECT" or "INSERT", so that it can be used as a
+// metric label without one series per query.
+type QueryObserver func(statement string, duration time.Duration)
+
+// observedConnector opens SQLite connections that report their statements to
+// an observer.
+type observedConnector struct {
+	dsn     string
+	driver  *gosqlite3.SQLiteDriver
+	observe QueryObserver
+}
+
+func (c *observedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConn{conn: conn.(*gosqlite3.SQLiteConn), observe: c.observe}, nil
+}
+
+func (c *observedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// observedConn times the statements executed directly on a connection, which
+// covers db.Exec and db.Query as well as the statements of a transaction. A
+// query is timed until its first row is ready, not until its rows are read.
+type observedConn struct {
+	conn    *gosqlite3.SQLiteConn
+	observe QueryObserver
+}
+
+func (c *observedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.conn.PrepareContext(ctx, query)
+}
+
+func (c *observedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *observedConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.conn.BeginTx(ctx, opts)
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	defer c.time(query, time.Now())
+	return c.conn.ExecContext(ctx, query, args)
+}
+
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	defer c.time(query, time.Now())
+	return c.conn.QueryContext(ctx, query, args)
+}
+
+func (c *observedConn) time(query string, started time.Time) {
+	c.observe(statementKind(query), time.Since(started))
+}
+
+// statementKind returns the leading keyword of a query in upper case.
+func statementKind(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(strings.TrimSuffix(fields[0], ";"))
+}
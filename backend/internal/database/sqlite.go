@@ -8,29 +8,62 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 
 	// Blank import for the file source driver used by golang-migrate.
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	// Blank import for the CGo-based SQLite driver.
-	_ "github.com/mattn/go-sqlite3"
+	// The CGo-based SQLite driver, which also registers itself as "sqlite3".
+	gosqlite3 "github.com/mattn/go-sqlite3"
 )
 
+// Config holds the connection pool settings. The zero value keeps the defaults
+// of database/sql and SQLite.
+type Config struct {
+	// MaxOpenConns limits the number of open connections; 0 means no limit. SQLite
+	// allows a single writer at a time, so 1 avoids lock contention entirely at
+	// the cost of serializing reads as well.
+	MaxOpenConns int
+	// MaxIdleConns is the number of idle connections kept in the pool; 0 keeps
+	// the database/sql default of 2.
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections after this long; 0 reuses them forever.
+	ConnMaxLifetime time.Duration
+	// BusyTimeout is how long a connection waits for a lock held by another
+	// connection before failing with "database is locked"; 0 fails immediately.
+	BusyTimeout time.Duration
+	// ObserveQuery, if set, is called with the duration of every statement,
+	// e.g. to export it as a metric.
+	ObserveQuery QueryObserver
+}
+
 // InitDB initializes the database connection, enables WAL mode, and applies all
 // pending database migrations. It's the single entry point for database setup.
-func InitDB(dataSourceName string) (*sql.DB, error) {
+func InitDB(dataSourceName string, cfg Config) (*sql.DB, error) {
 	// Ensure the parent directory for the database file exists.
 	dir := filepath.Dir(dataSourceName)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", dataSourceName)
+	// PRAGMA busy_timeout is per connection, so it is passed in the DSN, which the
+	// driver applies to every connection it opens.
+	dsn := dataSourceName
+	if cfg.BusyTimeout > 0 {
+		dsn = fmt.Sprintf("%s?_busy_timeout=%d", dataSourceName, cfg.BusyTimeout.Milliseconds())
+	}
+
+	db, err := openDB(dsn, cfg.ObserveQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -49,31 +82,23 @@ func InitDB(dataSourceName string) (*sql.DB, error) {
 	return db, nil
 }
 
+// openDB opens the database, with connections that report their statements to
+// observe if it is set.
+func openDB(dsn string, observe QueryObserver) (*sql.DB, error) {
+	if observe == nil {
+		return sql.Open("sqlite3", dsn)
+	}
+	return sql.OpenDB(&observedConnector{dsn: dsn, driver: &gosqlite3.SQLiteDriver{}, observe: observe}), nil
+}
+
 // runMigrations orchestrates the database schema migration process. It ensures the
 // database schema is always up-to-date with the version defined in the SQL files.
 func runMigrations(db *sql.DB) error {
-	// Create a migration driver instance for SQLite.
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create sqlite migration driver: %w", err)
-	}
-
-	// Reliably locate the migrations directory regardless of the execution context.
-	migrationsPath, err := getMigrationsPath()
+	m, err := newMigrate(db)
 	if err != nil {
 		return err
 	}
 
-	// Initialize the migrate instance with the file source and database driver.
-	m, err := migrate.NewWithDatabaseInstance(
-		migrationsPath,
-		"sqlite3",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
-	}
-
 	slog.Info("Applying database migrations...")
 	// The `Up` command is idempotent; it applies only the migrations that haven't
 	// been applied yet. `migrate.ErrNoChange` is not a critical error.
@@ -89,11 +114,38 @@ func runMigrations(db *sql.DB) error {
 
 	slog.Info("Database migration process complete", "version", version, "is_dirty", dirty)
 	if dirty {
-		slog.Error("DATABASE IS DIRTY. This indicates a failed migration and requires manual intervention.")
+		slog.Error("DATABASE IS DIRTY. This indicates a failed migration and requires manual intervention, see `server migrate force`.")
 	}
 	return nil
 }
 
+// newMigrate creates a golang-migrate instance for db that reads the migrations
+// from the migrations directory.
+func newMigrate(db *sql.DB) (*migrate.Migrate, error) {
+	// Create a migration driver instance for SQLite.
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("could not create sqlite migration driver: %w", err)
+	}
+
+	// Reliably locate the migrations directory regardless of the execution context.
+	migrationsPath, err := getMigrationsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the migrate instance with the file source and database driver.
+	m, err := migrate.NewWithDatabaseInstance(
+		migrationsPath,
+		"sqlite3",
+		driver,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
 // getMigrationsPath dynamically finds the path to the migrations directory.
 // This robust approach handles different execution contexts: running from source
 // via `go run`, running tests via `go test`, or running in the final Docker container.
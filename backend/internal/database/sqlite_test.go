@@ -0,0 +1,74 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInitDB_PoolSettings verifies that the pool configuration is applied to the
+// returned handle and that the busy timeout reaches SQLite.
+func TestInitDB_PoolSettings(t *testing.T) {
+	// ARRANGE
+	cfg := Config{MaxOpenConns: 1, MaxIdleConns: 1, ConnMaxLifetime: time.Hour, BusyTimeout: 3 * time.Second}
+
+	// ACT
+	db, err := InitDB(filepath.Join(t.TempDir(), "pool.db"), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	// ASSERT
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+	var busyTimeout int
+	require.NoError(t, db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout))
+	assert.Equal(t, 3000, busyTimeout)
+}
+
+// TestInitDB_DefaultPoolSettings verifies that the zero Config keeps the defaults.
+func TestInitDB_DefaultPoolSettings(t *testing.T) {
+	db, err := InitDB(filepath.Join(t.TempDir(), "default.db"), Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	assert.Equal(t, 0, db.Stats().MaxOpenConnections)
+}
+
+// TestInitDB_ObserveQuery verifies that the observer is told about statements
+// run directly and within transactions, by their leading keyword.
+func TestInitDB_ObserveQuery(t *testing.T) {
+	// ARRANGE
+	var mu sync.Mutex
+	observed := map[string]int{}
+	cfg := Config{BusyTimeout: time.Second, ObserveQuery: func(statement string, duration time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed[statement]++
+	}}
+	db, err := InitDB(filepath.Join(t.TempDir(), "observed.db"), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	mu.Lock()
+	clear(observed)
+	mu.Unlock()
+
+	// ACT
+	var busyTimeout int
+	require.NoError(t, db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout))
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO settings (key, value) VALUES ('observed', '1')")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	_, err = db.Exec("  delete FROM settings WHERE key = 'observed'")
+	require.NoError(t, err)
+
+	// ASSERT: The DSN still reaches SQLite through the observed connector.
+	assert.Equal(t, 1000, busyTimeout)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{"PRAGMA": 1, "INSERT": 1, "DELETE": 1}, observed)
+}
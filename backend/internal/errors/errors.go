@@ -29,6 +29,20 @@ var (
 	// This is typically mapped to a 403 Forbidden HTTP status.
 	ErrPermission = errors.New("permission denied")
 
+	// ErrTooLarge signifies that a request body exceeds the configured size limit.
+	// This is typically mapped to a 413 Content Too Large HTTP status.
+	ErrTooLarge = errors.New("request body too large")
+
+	// ErrUnavailable signifies that an external service the request depends on,
+	// such as the public model registry, could not be reached.
+	// This is typically mapped to a 502 Bad Gateway HTTP status.
+	ErrUnavailable = errors.New("upstream service unavailable")
+
+	// ErrNotSupported signifies that the operation is not available in this
+	// deployment, e.g. pulling a model when the LLM provider cannot manage models.
+	// This is typically mapped to a 501 Not Implemented HTTP status.
+	ErrNotSupported = errors.New("operation not supported")
+
 	// ErrInternal signifies an unexpected error on the server. This is a generic
 	// error used to prevent leaking sensitive implementation details to the client.
 	// This is typically mapped to a 500 Internal Server Error HTTP status.
@@ -22,6 +22,7 @@ package interfaces
 
 import (
 	"context"
+	"io"
 
 	"flow-ai/backend/internal/llm"
 	"flow-ai/backend/internal/model"
@@ -32,26 +33,99 @@ import (
 // Any struct that implements all these methods is considered a `ChatService`.
 type ChatService interface {
 	UpdateChatTitle(ctx context.Context, chatID, newTitle string) error
+	RegenerateTitle(ctx context.Context, chatID string) (*service.RegenerateTitleResponse, error)
 	DeleteChat(ctx context.Context, chatID string) error
-	ListChats(ctx context.Context) ([]*model.Chat, error)
+	DeleteChats(ctx context.Context, chatIDs []string) (int, error)
+	GetGlobalStats(ctx context.Context) (*model.GlobalStats, error)
+	CloneChat(ctx context.Context, chatID string) (*model.Chat, error)
+	ListChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error)
+	CreateChat(ctx context.Context, req *service.CreateChatRequest) (*model.Chat, error)
 	GetFullChat(ctx context.Context, chatID string) (*model.FullChat, error)
+	GetChatMessages(ctx context.Context, chatID string, limit int, before string) (*model.MessagePage, error)
 	// HandleNewMessage is designed for concurrent operation. It accepts a write-only
 	// channel and is expected to run its logic (e.g., call the LLM) in a goroutine,
 	// sending results back through the channel.
 	HandleNewMessage(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse)
 	RegenerateMessage(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse)
+	ContinueMessage(ctx context.Context, chatID string, messageID string, req *service.ContinueMessageRequest, streamChan chan<- model.StreamResponse)
+	GenerateAlternatives(ctx context.Context, chatID string, parentUserMessageID string, models []string, streamChan chan<- model.StreamResponse)
+	AddRawMessage(ctx context.Context, chatID string, req *service.AddMessageRequest) (*model.Message, error)
+	SetChatCollection(ctx context.Context, chatID, collectionID string) error
+	SetChatPinned(ctx context.Context, chatID string, pinned bool) error
+	SetChatTags(ctx context.Context, chatID string, tags []string) ([]string, error)
 	SwitchBranch(ctx context.Context, chatID string, targetMessageID string) error
-	GetChatTree(ctx context.Context, chatID string) (*model.FullChat, error)
+	GetChatTree(ctx context.Context, chatID string, opts model.ChatTreeOptions) (*model.ChatTree, error)
+	GetMessage(ctx context.Context, chatID, messageID string) (*model.Message, error)
+	GetAttachment(ctx context.Context, chatID, messageID, attachmentID string) (*model.Attachment, error)
+	EstimateTokens(ctx context.Context, req *service.CreateMessageRequest) (*service.TokenEstimate, error)
 }
 
 // ModelService defines the contract for all business logic related to managing
 // local Ollama models.
 type ModelService interface {
 	List(ctx context.Context) (*llm.ListModelsResponse, error)
+	ListRunning(ctx context.Context) (*service.RunningModels, error)
+	Storage(ctx context.Context) (*service.ModelStorage, error)
+	Search(ctx context.Context, req *service.SearchModelsRequest) ([]llm.RegistryModel, error)
 	// Pull accepts a channel to stream progress updates back to the caller.
 	Pull(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error
+	CancelPull(ctx context.Context, req *service.CancelPullRequest) error
+	ListPulls(ctx context.Context) []service.PullJobStatus
+	Updates(ctx context.Context) *service.ModelUpdates
+	// Update pulls the latest version of an installed model, streaming like Pull.
+	Update(ctx context.Context, modelName string, ch chan<- llm.PullStatus) error
 	Delete(ctx context.Context, req *llm.DeleteModelRequest) error
+	Copy(ctx context.Context, req *llm.CopyModelRequest) error
+	// Create accepts a channel to stream progress updates back to the caller.
+	Create(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error
+	CheckBlob(ctx context.Context, digest string) (bool, error)
+	PushBlob(ctx context.Context, digest string, r io.Reader) error
 	Show(ctx context.Context, req *llm.ShowModelRequest) (*llm.ModelInfo, error)
+	Embed(ctx context.Context, req *service.EmbedRequest) (*llm.EmbeddingsResponse, error)
+	Complete(ctx context.Context, req *service.CompleteRequest) (*llm.GenerateResponse, error)
+	OllamaStatus(ctx context.Context) *service.OllamaStatus
+	GetDefaults(ctx context.Context, modelName string) (*service.ModelDefaults, error)
+	SetDefaults(ctx context.Context, modelName string, opts *llm.RequestOptions) (*service.ModelDefaults, error)
+	Benchmark(ctx context.Context, modelName string, req *service.BenchmarkRequest) (*model.ModelBenchmark, error)
+}
+
+// DocumentService defines the contract for managing document collections used
+// for retrieval-augmented generation.
+type DocumentService interface {
+	CreateCollection(ctx context.Context, req *service.CreateCollectionRequest) (*model.Collection, error)
+	ListCollections(ctx context.Context) ([]*model.Collection, error)
+	UploadDocument(ctx context.Context, collectionID string, req *service.UploadDocumentRequest) (*model.Document, error)
+	ListDocuments(ctx context.Context, collectionID string) ([]*model.Document, error)
+}
+
+// PromptService defines the contract for managing reusable prompt templates.
+type PromptService interface {
+	CreatePrompt(ctx context.Context, req *service.SavePromptRequest) (*model.Prompt, error)
+	ListPrompts(ctx context.Context) ([]*model.Prompt, error)
+	GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error)
+	UpdatePrompt(ctx context.Context, promptID string, req *service.SavePromptRequest) (*model.Prompt, error)
+	DeletePrompt(ctx context.Context, promptID string) error
+}
+
+// RetentionService defines the contract for inspecting the chat retention janitor.
+type RetentionService interface {
+	Status() service.RetentionStatus
+}
+
+// MaintenanceService defines the contract for on-demand database maintenance.
+type MaintenanceService interface {
+	Run(ctx context.Context) (*service.MaintenanceResult, error)
+}
+
+// BackupService defines the contract for database snapshots.
+type BackupService interface {
+	Backup(ctx context.Context) (*service.Backup, error)
+	ListBackups(ctx context.Context) ([]service.Backup, error)
+}
+
+// HealthService defines the contract for checking the backend's dependencies.
+type HealthService interface {
+	Check(ctx context.Context) *service.Health
 }
 
 // SettingsService defines the contract for managing global application settings.
@@ -62,4 +136,9 @@ type SettingsService interface {
 	InitAndGet(ctx context.Context, defaultSystemPrompt string) (*service.Settings, error)
 	Get(ctx context.Context) (*service.Settings, error)
 	Save(ctx context.Context, settings *service.Settings) error
+	// Refresh runs the model discovery and persists what it found.
+	Refresh(ctx context.Context) (*service.Settings, error)
+	ListModelAliases(ctx context.Context) ([]service.ModelAlias, error)
+	SetModelAlias(ctx context.Context, alias *service.ModelAlias) error
+	DeleteModelAlias(ctx context.Context, name string) error
 }
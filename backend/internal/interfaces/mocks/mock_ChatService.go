@@ -39,36 +39,48 @@ func (_m *MockChatService) EXPECT() *MockChatService_Expecter {
 	return &MockChatService_Expecter{mock: &_m.Mock}
 }
 
-// DeleteChat provides a mock function for the type MockChatService
-func (_mock *MockChatService) DeleteChat(ctx context.Context, chatID string) error {
-	ret := _mock.Called(ctx, chatID)
+// AddRawMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) AddRawMessage(ctx context.Context, chatID string, req *service.AddMessageRequest) (*model.Message, error) {
+	ret := _mock.Called(ctx, chatID, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteChat")
+		panic("no return value specified for AddRawMessage")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = returnFunc(ctx, chatID)
+	var r0 *model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *service.AddMessageRequest) (*model.Message, error)); ok {
+		return returnFunc(ctx, chatID, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *service.AddMessageRequest) *model.Message); ok {
+		r0 = returnFunc(ctx, chatID, req)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *service.AddMessageRequest) error); ok {
+		r1 = returnFunc(ctx, chatID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockChatService_DeleteChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChat'
-type MockChatService_DeleteChat_Call struct {
+// MockChatService_AddRawMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddRawMessage'
+type MockChatService_AddRawMessage_Call struct {
 	*mock.Call
 }
 
-// DeleteChat is a helper method to define mock.On call
+// AddRawMessage is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockChatService_Expecter) DeleteChat(ctx interface{}, chatID interface{}) *MockChatService_DeleteChat_Call {
-	return &MockChatService_DeleteChat_Call{Call: _e.mock.On("DeleteChat", ctx, chatID)}
+//   - req *service.AddMessageRequest
+func (_e *MockChatService_Expecter) AddRawMessage(ctx interface{}, chatID interface{}, req interface{}) *MockChatService_AddRawMessage_Call {
+	return &MockChatService_AddRawMessage_Call{Call: _e.mock.On("AddRawMessage", ctx, chatID, req)}
 }
 
-func (_c *MockChatService_DeleteChat_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_DeleteChat_Call {
+func (_c *MockChatService_AddRawMessage_Call) Run(run func(ctx context.Context, chatID string, req *service.AddMessageRequest)) *MockChatService_AddRawMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -78,42 +90,47 @@ func (_c *MockChatService_DeleteChat_Call) Run(run func(ctx context.Context, cha
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 *service.AddMessageRequest
+		if args[2] != nil {
+			arg2 = args[2].(*service.AddMessageRequest)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockChatService_DeleteChat_Call) Return(err error) *MockChatService_DeleteChat_Call {
-	_c.Call.Return(err)
+func (_c *MockChatService_AddRawMessage_Call) Return(message *model.Message, err error) *MockChatService_AddRawMessage_Call {
+	_c.Call.Return(message, err)
 	return _c
 }
 
-func (_c *MockChatService_DeleteChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) error) *MockChatService_DeleteChat_Call {
+func (_c *MockChatService_AddRawMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string, req *service.AddMessageRequest) (*model.Message, error)) *MockChatService_AddRawMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetChatTree provides a mock function for the type MockChatService
-func (_mock *MockChatService) GetChatTree(ctx context.Context, chatID string) (*model.FullChat, error) {
+// CloneChat provides a mock function for the type MockChatService
+func (_mock *MockChatService) CloneChat(ctx context.Context, chatID string) (*model.Chat, error) {
 	ret := _mock.Called(ctx, chatID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetChatTree")
+		panic("no return value specified for CloneChat")
 	}
 
-	var r0 *model.FullChat
+	var r0 *model.Chat
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.FullChat, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Chat, error)); ok {
 		return returnFunc(ctx, chatID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.FullChat); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Chat); ok {
 		r0 = returnFunc(ctx, chatID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.FullChat)
+			r0 = ret.Get(0).(*model.Chat)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -124,19 +141,19 @@ func (_mock *MockChatService) GetChatTree(ctx context.Context, chatID string) (*
 	return r0, r1
 }
 
-// MockChatService_GetChatTree_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatTree'
-type MockChatService_GetChatTree_Call struct {
+// MockChatService_CloneChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloneChat'
+type MockChatService_CloneChat_Call struct {
 	*mock.Call
 }
 
-// GetChatTree is a helper method to define mock.On call
+// CloneChat is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockChatService_Expecter) GetChatTree(ctx interface{}, chatID interface{}) *MockChatService_GetChatTree_Call {
-	return &MockChatService_GetChatTree_Call{Call: _e.mock.On("GetChatTree", ctx, chatID)}
+func (_e *MockChatService_Expecter) CloneChat(ctx interface{}, chatID interface{}) *MockChatService_CloneChat_Call {
+	return &MockChatService_CloneChat_Call{Call: _e.mock.On("CloneChat", ctx, chatID)}
 }
 
-func (_c *MockChatService_GetChatTree_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_GetChatTree_Call {
+func (_c *MockChatService_CloneChat_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_CloneChat_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -154,57 +171,178 @@ func (_c *MockChatService_GetChatTree_Call) Run(run func(ctx context.Context, ch
 	return _c
 }
 
-func (_c *MockChatService_GetChatTree_Call) Return(fullChat *model.FullChat, err error) *MockChatService_GetChatTree_Call {
-	_c.Call.Return(fullChat, err)
+func (_c *MockChatService_CloneChat_Call) Return(chat *model.Chat, err error) *MockChatService_CloneChat_Call {
+	_c.Call.Return(chat, err)
 	return _c
 }
 
-func (_c *MockChatService_GetChatTree_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.FullChat, error)) *MockChatService_GetChatTree_Call {
+func (_c *MockChatService_CloneChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.Chat, error)) *MockChatService_CloneChat_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetFullChat provides a mock function for the type MockChatService
-func (_mock *MockChatService) GetFullChat(ctx context.Context, chatID string) (*model.FullChat, error) {
-	ret := _mock.Called(ctx, chatID)
+// ContinueMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) ContinueMessage(ctx context.Context, chatID string, messageID string, req *service.ContinueMessageRequest, streamChan chan<- model.StreamResponse) {
+	_mock.Called(ctx, chatID, messageID, req, streamChan)
+	return
+}
+
+// MockChatService_ContinueMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ContinueMessage'
+type MockChatService_ContinueMessage_Call struct {
+	*mock.Call
+}
+
+// ContinueMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - messageID string
+//   - req *service.ContinueMessageRequest
+//   - streamChan chan<- model.StreamResponse
+func (_e *MockChatService_Expecter) ContinueMessage(ctx interface{}, chatID interface{}, messageID interface{}, req interface{}, streamChan interface{}) *MockChatService_ContinueMessage_Call {
+	return &MockChatService_ContinueMessage_Call{Call: _e.mock.On("ContinueMessage", ctx, chatID, messageID, req, streamChan)}
+}
+
+func (_c *MockChatService_ContinueMessage_Call) Run(run func(ctx context.Context, chatID string, messageID string, req *service.ContinueMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_ContinueMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 *service.ContinueMessageRequest
+		if args[3] != nil {
+			arg3 = args[3].(*service.ContinueMessageRequest)
+		}
+		var arg4 chan<- model.StreamResponse
+		if args[4] != nil {
+			arg4 = args[4].(chan<- model.StreamResponse)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_ContinueMessage_Call) Return() *MockChatService_ContinueMessage_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockChatService_ContinueMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string, messageID string, req *service.ContinueMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_ContinueMessage_Call {
+	_c.Run(run)
+	return _c
+}
+
+// CreateChat provides a mock function for the type MockChatService
+func (_mock *MockChatService) CreateChat(ctx context.Context, req *service.CreateChatRequest) (*model.Chat, error) {
+	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetFullChat")
+		panic("no return value specified for CreateChat")
 	}
 
-	var r0 *model.FullChat
+	var r0 *model.Chat
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.FullChat, error)); ok {
-		return returnFunc(ctx, chatID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CreateChatRequest) (*model.Chat, error)); ok {
+		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.FullChat); ok {
-		r0 = returnFunc(ctx, chatID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CreateChatRequest) *model.Chat); ok {
+		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.FullChat)
+			r0 = ret.Get(0).(*model.Chat)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, chatID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.CreateChatRequest) error); ok {
+		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockChatService_GetFullChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFullChat'
-type MockChatService_GetFullChat_Call struct {
+// MockChatService_CreateChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateChat'
+type MockChatService_CreateChat_Call struct {
 	*mock.Call
 }
 
-// GetFullChat is a helper method to define mock.On call
+// CreateChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *service.CreateChatRequest
+func (_e *MockChatService_Expecter) CreateChat(ctx interface{}, req interface{}) *MockChatService_CreateChat_Call {
+	return &MockChatService_CreateChat_Call{Call: _e.mock.On("CreateChat", ctx, req)}
+}
+
+func (_c *MockChatService_CreateChat_Call) Run(run func(ctx context.Context, req *service.CreateChatRequest)) *MockChatService_CreateChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.CreateChatRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.CreateChatRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_CreateChat_Call) Return(chat *model.Chat, err error) *MockChatService_CreateChat_Call {
+	_c.Call.Return(chat, err)
+	return _c
+}
+
+func (_c *MockChatService_CreateChat_Call) RunAndReturn(run func(ctx context.Context, req *service.CreateChatRequest) (*model.Chat, error)) *MockChatService_CreateChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteChat provides a mock function for the type MockChatService
+func (_mock *MockChatService) DeleteChat(ctx context.Context, chatID string) error {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteChat")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_DeleteChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChat'
+type MockChatService_DeleteChat_Call struct {
+	*mock.Call
+}
+
+// DeleteChat is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockChatService_Expecter) GetFullChat(ctx interface{}, chatID interface{}) *MockChatService_GetFullChat_Call {
-	return &MockChatService_GetFullChat_Call{Call: _e.mock.On("GetFullChat", ctx, chatID)}
+func (_e *MockChatService_Expecter) DeleteChat(ctx interface{}, chatID interface{}) *MockChatService_DeleteChat_Call {
+	return &MockChatService_DeleteChat_Call{Call: _e.mock.On("DeleteChat", ctx, chatID)}
 }
 
-func (_c *MockChatService_GetFullChat_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_GetFullChat_Call {
+func (_c *MockChatService_DeleteChat_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_DeleteChat_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -222,152 +360,172 @@ func (_c *MockChatService_GetFullChat_Call) Run(run func(ctx context.Context, ch
 	return _c
 }
 
-func (_c *MockChatService_GetFullChat_Call) Return(fullChat *model.FullChat, err error) *MockChatService_GetFullChat_Call {
-	_c.Call.Return(fullChat, err)
+func (_c *MockChatService_DeleteChat_Call) Return(err error) *MockChatService_DeleteChat_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockChatService_GetFullChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.FullChat, error)) *MockChatService_GetFullChat_Call {
+func (_c *MockChatService_DeleteChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) error) *MockChatService_DeleteChat_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// HandleNewMessage provides a mock function for the type MockChatService
-func (_mock *MockChatService) HandleNewMessage(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse) {
-	_mock.Called(ctx, req, streamChan)
-	return
+// DeleteChats provides a mock function for the type MockChatService
+func (_mock *MockChatService) DeleteChats(ctx context.Context, chatIDs []string) (int, error) {
+	ret := _mock.Called(ctx, chatIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteChats")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (int, error)); ok {
+		return returnFunc(ctx, chatIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) int); ok {
+		r0 = returnFunc(ctx, chatIDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, chatIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockChatService_HandleNewMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleNewMessage'
-type MockChatService_HandleNewMessage_Call struct {
+// MockChatService_DeleteChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChats'
+type MockChatService_DeleteChats_Call struct {
 	*mock.Call
 }
 
-// HandleNewMessage is a helper method to define mock.On call
+// DeleteChats is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *service.CreateMessageRequest
-//   - streamChan chan<- model.StreamResponse
-func (_e *MockChatService_Expecter) HandleNewMessage(ctx interface{}, req interface{}, streamChan interface{}) *MockChatService_HandleNewMessage_Call {
-	return &MockChatService_HandleNewMessage_Call{Call: _e.mock.On("HandleNewMessage", ctx, req, streamChan)}
+//   - chatIDs []string
+func (_e *MockChatService_Expecter) DeleteChats(ctx interface{}, chatIDs interface{}) *MockChatService_DeleteChats_Call {
+	return &MockChatService_DeleteChats_Call{Call: _e.mock.On("DeleteChats", ctx, chatIDs)}
 }
 
-func (_c *MockChatService_HandleNewMessage_Call) Run(run func(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_HandleNewMessage_Call {
+func (_c *MockChatService_DeleteChats_Call) Run(run func(ctx context.Context, chatIDs []string)) *MockChatService_DeleteChats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *service.CreateMessageRequest
+		var arg1 []string
 		if args[1] != nil {
-			arg1 = args[1].(*service.CreateMessageRequest)
-		}
-		var arg2 chan<- model.StreamResponse
-		if args[2] != nil {
-			arg2 = args[2].(chan<- model.StreamResponse)
+			arg1 = args[1].([]string)
 		}
 		run(
 			arg0,
 			arg1,
-			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockChatService_HandleNewMessage_Call) Return() *MockChatService_HandleNewMessage_Call {
-	_c.Call.Return()
+func (_c *MockChatService_DeleteChats_Call) Return(n int, err error) *MockChatService_DeleteChats_Call {
+	_c.Call.Return(n, err)
 	return _c
 }
 
-func (_c *MockChatService_HandleNewMessage_Call) RunAndReturn(run func(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_HandleNewMessage_Call {
-	_c.Run(run)
+func (_c *MockChatService_DeleteChats_Call) RunAndReturn(run func(ctx context.Context, chatIDs []string) (int, error)) *MockChatService_DeleteChats_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
-// ListChats provides a mock function for the type MockChatService
-func (_mock *MockChatService) ListChats(ctx context.Context) ([]*model.Chat, error) {
-	ret := _mock.Called(ctx)
+// EstimateTokens provides a mock function for the type MockChatService
+func (_mock *MockChatService) EstimateTokens(ctx context.Context, req *service.CreateMessageRequest) (*service.TokenEstimate, error) {
+	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListChats")
+		panic("no return value specified for EstimateTokens")
 	}
 
-	var r0 []*model.Chat
+	var r0 *service.TokenEstimate
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*model.Chat, error)); ok {
-		return returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CreateMessageRequest) (*service.TokenEstimate, error)); ok {
+		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) []*model.Chat); ok {
-		r0 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CreateMessageRequest) *service.TokenEstimate); ok {
+		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model.Chat)
+			r0 = ret.Get(0).(*service.TokenEstimate)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.CreateMessageRequest) error); ok {
+		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockChatService_ListChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListChats'
-type MockChatService_ListChats_Call struct {
+// MockChatService_EstimateTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EstimateTokens'
+type MockChatService_EstimateTokens_Call struct {
 	*mock.Call
 }
 
-// ListChats is a helper method to define mock.On call
+// EstimateTokens is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockChatService_Expecter) ListChats(ctx interface{}) *MockChatService_ListChats_Call {
-	return &MockChatService_ListChats_Call{Call: _e.mock.On("ListChats", ctx)}
+//   - req *service.CreateMessageRequest
+func (_e *MockChatService_Expecter) EstimateTokens(ctx interface{}, req interface{}) *MockChatService_EstimateTokens_Call {
+	return &MockChatService_EstimateTokens_Call{Call: _e.mock.On("EstimateTokens", ctx, req)}
 }
 
-func (_c *MockChatService_ListChats_Call) Run(run func(ctx context.Context)) *MockChatService_ListChats_Call {
+func (_c *MockChatService_EstimateTokens_Call) Run(run func(ctx context.Context, req *service.CreateMessageRequest)) *MockChatService_EstimateTokens_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
+		var arg1 *service.CreateMessageRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.CreateMessageRequest)
+		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockChatService_ListChats_Call) Return(chats []*model.Chat, err error) *MockChatService_ListChats_Call {
-	_c.Call.Return(chats, err)
+func (_c *MockChatService_EstimateTokens_Call) Return(tokenEstimate *service.TokenEstimate, err error) *MockChatService_EstimateTokens_Call {
+	_c.Call.Return(tokenEstimate, err)
 	return _c
 }
 
-func (_c *MockChatService_ListChats_Call) RunAndReturn(run func(ctx context.Context) ([]*model.Chat, error)) *MockChatService_ListChats_Call {
+func (_c *MockChatService_EstimateTokens_Call) RunAndReturn(run func(ctx context.Context, req *service.CreateMessageRequest) (*service.TokenEstimate, error)) *MockChatService_EstimateTokens_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RegenerateMessage provides a mock function for the type MockChatService
-func (_mock *MockChatService) RegenerateMessage(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse) {
-	_mock.Called(ctx, chatID, originalAssistantMessageID, req, streamChan)
+// GenerateAlternatives provides a mock function for the type MockChatService
+func (_mock *MockChatService) GenerateAlternatives(ctx context.Context, chatID string, parentUserMessageID string, models []string, streamChan chan<- model.StreamResponse) {
+	_mock.Called(ctx, chatID, parentUserMessageID, models, streamChan)
 	return
 }
 
-// MockChatService_RegenerateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateMessage'
-type MockChatService_RegenerateMessage_Call struct {
+// MockChatService_GenerateAlternatives_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateAlternatives'
+type MockChatService_GenerateAlternatives_Call struct {
 	*mock.Call
 }
 
-// RegenerateMessage is a helper method to define mock.On call
+// GenerateAlternatives is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-//   - originalAssistantMessageID string
-//   - req *service.RegenerateMessageRequest
+//   - parentUserMessageID string
+//   - models []string
 //   - streamChan chan<- model.StreamResponse
-func (_e *MockChatService_Expecter) RegenerateMessage(ctx interface{}, chatID interface{}, originalAssistantMessageID interface{}, req interface{}, streamChan interface{}) *MockChatService_RegenerateMessage_Call {
-	return &MockChatService_RegenerateMessage_Call{Call: _e.mock.On("RegenerateMessage", ctx, chatID, originalAssistantMessageID, req, streamChan)}
+func (_e *MockChatService_Expecter) GenerateAlternatives(ctx interface{}, chatID interface{}, parentUserMessageID interface{}, models interface{}, streamChan interface{}) *MockChatService_GenerateAlternatives_Call {
+	return &MockChatService_GenerateAlternatives_Call{Call: _e.mock.On("GenerateAlternatives", ctx, chatID, parentUserMessageID, models, streamChan)}
 }
 
-func (_c *MockChatService_RegenerateMessage_Call) Run(run func(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_RegenerateMessage_Call {
+func (_c *MockChatService_GenerateAlternatives_Call) Run(run func(ctx context.Context, chatID string, parentUserMessageID string, models []string, streamChan chan<- model.StreamResponse)) *MockChatService_GenerateAlternatives_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -381,9 +539,9 @@ func (_c *MockChatService_RegenerateMessage_Call) Run(run func(ctx context.Conte
 		if args[2] != nil {
 			arg2 = args[2].(string)
 		}
-		var arg3 *service.RegenerateMessageRequest
+		var arg3 []string
 		if args[3] != nil {
-			arg3 = args[3].(*service.RegenerateMessageRequest)
+			arg3 = args[3].([]string)
 		}
 		var arg4 chan<- model.StreamResponse
 		if args[4] != nil {
@@ -400,16 +558,906 @@ func (_c *MockChatService_RegenerateMessage_Call) Run(run func(ctx context.Conte
 	return _c
 }
 
-func (_c *MockChatService_RegenerateMessage_Call) Return() *MockChatService_RegenerateMessage_Call {
+func (_c *MockChatService_GenerateAlternatives_Call) Return() *MockChatService_GenerateAlternatives_Call {
 	_c.Call.Return()
 	return _c
 }
 
-func (_c *MockChatService_RegenerateMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_RegenerateMessage_Call {
+func (_c *MockChatService_GenerateAlternatives_Call) RunAndReturn(run func(ctx context.Context, chatID string, parentUserMessageID string, models []string, streamChan chan<- model.StreamResponse)) *MockChatService_GenerateAlternatives_Call {
 	_c.Run(run)
 	return _c
 }
 
+// GetAttachment provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetAttachment(ctx context.Context, chatID string, messageID string, attachmentID string) (*model.Attachment, error) {
+	ret := _mock.Called(ctx, chatID, messageID, attachmentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachment")
+	}
+
+	var r0 *model.Attachment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*model.Attachment, error)); ok {
+		return returnFunc(ctx, chatID, messageID, attachmentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *model.Attachment); ok {
+		r0 = returnFunc(ctx, chatID, messageID, attachmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Attachment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, chatID, messageID, attachmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachment'
+type MockChatService_GetAttachment_Call struct {
+	*mock.Call
+}
+
+// GetAttachment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - messageID string
+//   - attachmentID string
+func (_e *MockChatService_Expecter) GetAttachment(ctx interface{}, chatID interface{}, messageID interface{}, attachmentID interface{}) *MockChatService_GetAttachment_Call {
+	return &MockChatService_GetAttachment_Call{Call: _e.mock.On("GetAttachment", ctx, chatID, messageID, attachmentID)}
+}
+
+func (_c *MockChatService_GetAttachment_Call) Run(run func(ctx context.Context, chatID string, messageID string, attachmentID string)) *MockChatService_GetAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetAttachment_Call) Return(attachment *model.Attachment, err error) *MockChatService_GetAttachment_Call {
+	_c.Call.Return(attachment, err)
+	return _c
+}
+
+func (_c *MockChatService_GetAttachment_Call) RunAndReturn(run func(ctx context.Context, chatID string, messageID string, attachmentID string) (*model.Attachment, error)) *MockChatService_GetAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatMessages provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetChatMessages(ctx context.Context, chatID string, limit int, before string) (*model.MessagePage, error) {
+	ret := _mock.Called(ctx, chatID, limit, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatMessages")
+	}
+
+	var r0 *model.MessagePage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) (*model.MessagePage, error)); ok {
+		return returnFunc(ctx, chatID, limit, before)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) *model.MessagePage); ok {
+		r0 = returnFunc(ctx, chatID, limit, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.MessagePage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = returnFunc(ctx, chatID, limit, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetChatMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatMessages'
+type MockChatService_GetChatMessages_Call struct {
+	*mock.Call
+}
+
+// GetChatMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - limit int
+//   - before string
+func (_e *MockChatService_Expecter) GetChatMessages(ctx interface{}, chatID interface{}, limit interface{}, before interface{}) *MockChatService_GetChatMessages_Call {
+	return &MockChatService_GetChatMessages_Call{Call: _e.mock.On("GetChatMessages", ctx, chatID, limit, before)}
+}
+
+func (_c *MockChatService_GetChatMessages_Call) Run(run func(ctx context.Context, chatID string, limit int, before string)) *MockChatService_GetChatMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetChatMessages_Call) Return(messagePage *model.MessagePage, err error) *MockChatService_GetChatMessages_Call {
+	_c.Call.Return(messagePage, err)
+	return _c
+}
+
+func (_c *MockChatService_GetChatMessages_Call) RunAndReturn(run func(ctx context.Context, chatID string, limit int, before string) (*model.MessagePage, error)) *MockChatService_GetChatMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatTree provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetChatTree(ctx context.Context, chatID string, opts model.ChatTreeOptions) (*model.ChatTree, error) {
+	ret := _mock.Called(ctx, chatID, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatTree")
+	}
+
+	var r0 *model.ChatTree
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, model.ChatTreeOptions) (*model.ChatTree, error)); ok {
+		return returnFunc(ctx, chatID, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, model.ChatTreeOptions) *model.ChatTree); ok {
+		r0 = returnFunc(ctx, chatID, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ChatTree)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, model.ChatTreeOptions) error); ok {
+		r1 = returnFunc(ctx, chatID, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetChatTree_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatTree'
+type MockChatService_GetChatTree_Call struct {
+	*mock.Call
+}
+
+// GetChatTree is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - opts model.ChatTreeOptions
+func (_e *MockChatService_Expecter) GetChatTree(ctx interface{}, chatID interface{}, opts interface{}) *MockChatService_GetChatTree_Call {
+	return &MockChatService_GetChatTree_Call{Call: _e.mock.On("GetChatTree", ctx, chatID, opts)}
+}
+
+func (_c *MockChatService_GetChatTree_Call) Run(run func(ctx context.Context, chatID string, opts model.ChatTreeOptions)) *MockChatService_GetChatTree_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 model.ChatTreeOptions
+		if args[2] != nil {
+			arg2 = args[2].(model.ChatTreeOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetChatTree_Call) Return(chatTree *model.ChatTree, err error) *MockChatService_GetChatTree_Call {
+	_c.Call.Return(chatTree, err)
+	return _c
+}
+
+func (_c *MockChatService_GetChatTree_Call) RunAndReturn(run func(ctx context.Context, chatID string, opts model.ChatTreeOptions) (*model.ChatTree, error)) *MockChatService_GetChatTree_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFullChat provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetFullChat(ctx context.Context, chatID string) (*model.FullChat, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFullChat")
+	}
+
+	var r0 *model.FullChat
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.FullChat, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.FullChat); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.FullChat)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetFullChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFullChat'
+type MockChatService_GetFullChat_Call struct {
+	*mock.Call
+}
+
+// GetFullChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockChatService_Expecter) GetFullChat(ctx interface{}, chatID interface{}) *MockChatService_GetFullChat_Call {
+	return &MockChatService_GetFullChat_Call{Call: _e.mock.On("GetFullChat", ctx, chatID)}
+}
+
+func (_c *MockChatService_GetFullChat_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_GetFullChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetFullChat_Call) Return(fullChat *model.FullChat, err error) *MockChatService_GetFullChat_Call {
+	_c.Call.Return(fullChat, err)
+	return _c
+}
+
+func (_c *MockChatService_GetFullChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.FullChat, error)) *MockChatService_GetFullChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGlobalStats provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetGlobalStats(ctx context.Context) (*model.GlobalStats, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGlobalStats")
+	}
+
+	var r0 *model.GlobalStats
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*model.GlobalStats, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *model.GlobalStats); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GlobalStats)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetGlobalStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGlobalStats'
+type MockChatService_GetGlobalStats_Call struct {
+	*mock.Call
+}
+
+// GetGlobalStats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockChatService_Expecter) GetGlobalStats(ctx interface{}) *MockChatService_GetGlobalStats_Call {
+	return &MockChatService_GetGlobalStats_Call{Call: _e.mock.On("GetGlobalStats", ctx)}
+}
+
+func (_c *MockChatService_GetGlobalStats_Call) Run(run func(ctx context.Context)) *MockChatService_GetGlobalStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetGlobalStats_Call) Return(globalStats *model.GlobalStats, err error) *MockChatService_GetGlobalStats_Call {
+	_c.Call.Return(globalStats, err)
+	return _c
+}
+
+func (_c *MockChatService_GetGlobalStats_Call) RunAndReturn(run func(ctx context.Context) (*model.GlobalStats, error)) *MockChatService_GetGlobalStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetMessage(ctx context.Context, chatID string, messageID string) (*model.Message, error) {
+	ret := _mock.Called(ctx, chatID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*model.Message, error)); ok {
+		return returnFunc(ctx, chatID, messageID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *model.Message); ok {
+		r0 = returnFunc(ctx, chatID, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, chatID, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessage'
+type MockChatService_GetMessage_Call struct {
+	*mock.Call
+}
+
+// GetMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - messageID string
+func (_e *MockChatService_Expecter) GetMessage(ctx interface{}, chatID interface{}, messageID interface{}) *MockChatService_GetMessage_Call {
+	return &MockChatService_GetMessage_Call{Call: _e.mock.On("GetMessage", ctx, chatID, messageID)}
+}
+
+func (_c *MockChatService_GetMessage_Call) Run(run func(ctx context.Context, chatID string, messageID string)) *MockChatService_GetMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetMessage_Call) Return(message *model.Message, err error) *MockChatService_GetMessage_Call {
+	_c.Call.Return(message, err)
+	return _c
+}
+
+func (_c *MockChatService_GetMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string, messageID string) (*model.Message, error)) *MockChatService_GetMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HandleNewMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) HandleNewMessage(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse) {
+	_mock.Called(ctx, req, streamChan)
+	return
+}
+
+// MockChatService_HandleNewMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleNewMessage'
+type MockChatService_HandleNewMessage_Call struct {
+	*mock.Call
+}
+
+// HandleNewMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *service.CreateMessageRequest
+//   - streamChan chan<- model.StreamResponse
+func (_e *MockChatService_Expecter) HandleNewMessage(ctx interface{}, req interface{}, streamChan interface{}) *MockChatService_HandleNewMessage_Call {
+	return &MockChatService_HandleNewMessage_Call{Call: _e.mock.On("HandleNewMessage", ctx, req, streamChan)}
+}
+
+func (_c *MockChatService_HandleNewMessage_Call) Run(run func(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_HandleNewMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.CreateMessageRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.CreateMessageRequest)
+		}
+		var arg2 chan<- model.StreamResponse
+		if args[2] != nil {
+			arg2 = args[2].(chan<- model.StreamResponse)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_HandleNewMessage_Call) Return() *MockChatService_HandleNewMessage_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockChatService_HandleNewMessage_Call) RunAndReturn(run func(ctx context.Context, req *service.CreateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_HandleNewMessage_Call {
+	_c.Run(run)
+	return _c
+}
+
+// ListChats provides a mock function for the type MockChatService
+func (_mock *MockChatService) ListChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error) {
+	ret := _mock.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChats")
+	}
+
+	var r0 []*model.Chat
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatListOptions) ([]*model.Chat, error)); ok {
+		return returnFunc(ctx, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatListOptions) []*model.Chat); ok {
+		r0 = returnFunc(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Chat)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ChatListOptions) error); ok {
+		r1 = returnFunc(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_ListChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListChats'
+type MockChatService_ListChats_Call struct {
+	*mock.Call
+}
+
+// ListChats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts model.ChatListOptions
+func (_e *MockChatService_Expecter) ListChats(ctx interface{}, opts interface{}) *MockChatService_ListChats_Call {
+	return &MockChatService_ListChats_Call{Call: _e.mock.On("ListChats", ctx, opts)}
+}
+
+func (_c *MockChatService_ListChats_Call) Run(run func(ctx context.Context, opts model.ChatListOptions)) *MockChatService_ListChats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 model.ChatListOptions
+		if args[1] != nil {
+			arg1 = args[1].(model.ChatListOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_ListChats_Call) Return(chats []*model.Chat, err error) *MockChatService_ListChats_Call {
+	_c.Call.Return(chats, err)
+	return _c
+}
+
+func (_c *MockChatService_ListChats_Call) RunAndReturn(run func(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error)) *MockChatService_ListChats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegenerateMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) RegenerateMessage(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse) {
+	_mock.Called(ctx, chatID, originalAssistantMessageID, req, streamChan)
+	return
+}
+
+// MockChatService_RegenerateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateMessage'
+type MockChatService_RegenerateMessage_Call struct {
+	*mock.Call
+}
+
+// RegenerateMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - originalAssistantMessageID string
+//   - req *service.RegenerateMessageRequest
+//   - streamChan chan<- model.StreamResponse
+func (_e *MockChatService_Expecter) RegenerateMessage(ctx interface{}, chatID interface{}, originalAssistantMessageID interface{}, req interface{}, streamChan interface{}) *MockChatService_RegenerateMessage_Call {
+	return &MockChatService_RegenerateMessage_Call{Call: _e.mock.On("RegenerateMessage", ctx, chatID, originalAssistantMessageID, req, streamChan)}
+}
+
+func (_c *MockChatService_RegenerateMessage_Call) Run(run func(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_RegenerateMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 *service.RegenerateMessageRequest
+		if args[3] != nil {
+			arg3 = args[3].(*service.RegenerateMessageRequest)
+		}
+		var arg4 chan<- model.StreamResponse
+		if args[4] != nil {
+			arg4 = args[4].(chan<- model.StreamResponse)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_RegenerateMessage_Call) Return() *MockChatService_RegenerateMessage_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockChatService_RegenerateMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string, originalAssistantMessageID string, req *service.RegenerateMessageRequest, streamChan chan<- model.StreamResponse)) *MockChatService_RegenerateMessage_Call {
+	_c.Run(run)
+	return _c
+}
+
+// RegenerateTitle provides a mock function for the type MockChatService
+func (_mock *MockChatService) RegenerateTitle(ctx context.Context, chatID string) (*service.RegenerateTitleResponse, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegenerateTitle")
+	}
+
+	var r0 *service.RegenerateTitleResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*service.RegenerateTitleResponse, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *service.RegenerateTitleResponse); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RegenerateTitleResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_RegenerateTitle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateTitle'
+type MockChatService_RegenerateTitle_Call struct {
+	*mock.Call
+}
+
+// RegenerateTitle is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockChatService_Expecter) RegenerateTitle(ctx interface{}, chatID interface{}) *MockChatService_RegenerateTitle_Call {
+	return &MockChatService_RegenerateTitle_Call{Call: _e.mock.On("RegenerateTitle", ctx, chatID)}
+}
+
+func (_c *MockChatService_RegenerateTitle_Call) Run(run func(ctx context.Context, chatID string)) *MockChatService_RegenerateTitle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_RegenerateTitle_Call) Return(regenerateTitleResponse *service.RegenerateTitleResponse, err error) *MockChatService_RegenerateTitle_Call {
+	_c.Call.Return(regenerateTitleResponse, err)
+	return _c
+}
+
+func (_c *MockChatService_RegenerateTitle_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*service.RegenerateTitleResponse, error)) *MockChatService_RegenerateTitle_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetChatCollection provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetChatCollection(ctx context.Context, chatID string, collectionID string) error {
+	ret := _mock.Called(ctx, chatID, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetChatCollection")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, chatID, collectionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_SetChatCollection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetChatCollection'
+type MockChatService_SetChatCollection_Call struct {
+	*mock.Call
+}
+
+// SetChatCollection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - collectionID string
+func (_e *MockChatService_Expecter) SetChatCollection(ctx interface{}, chatID interface{}, collectionID interface{}) *MockChatService_SetChatCollection_Call {
+	return &MockChatService_SetChatCollection_Call{Call: _e.mock.On("SetChatCollection", ctx, chatID, collectionID)}
+}
+
+func (_c *MockChatService_SetChatCollection_Call) Run(run func(ctx context.Context, chatID string, collectionID string)) *MockChatService_SetChatCollection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetChatCollection_Call) Return(err error) *MockChatService_SetChatCollection_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_SetChatCollection_Call) RunAndReturn(run func(ctx context.Context, chatID string, collectionID string) error) *MockChatService_SetChatCollection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetChatPinned provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetChatPinned(ctx context.Context, chatID string, pinned bool) error {
+	ret := _mock.Called(ctx, chatID, pinned)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetChatPinned")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = returnFunc(ctx, chatID, pinned)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_SetChatPinned_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetChatPinned'
+type MockChatService_SetChatPinned_Call struct {
+	*mock.Call
+}
+
+// SetChatPinned is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - pinned bool
+func (_e *MockChatService_Expecter) SetChatPinned(ctx interface{}, chatID interface{}, pinned interface{}) *MockChatService_SetChatPinned_Call {
+	return &MockChatService_SetChatPinned_Call{Call: _e.mock.On("SetChatPinned", ctx, chatID, pinned)}
+}
+
+func (_c *MockChatService_SetChatPinned_Call) Run(run func(ctx context.Context, chatID string, pinned bool)) *MockChatService_SetChatPinned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetChatPinned_Call) Return(err error) *MockChatService_SetChatPinned_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_SetChatPinned_Call) RunAndReturn(run func(ctx context.Context, chatID string, pinned bool) error) *MockChatService_SetChatPinned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetChatTags provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetChatTags(ctx context.Context, chatID string, tags []string) ([]string, error) {
+	ret := _mock.Called(ctx, chatID, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetChatTags")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) ([]string, error)); ok {
+		return returnFunc(ctx, chatID, tags)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) []string); ok {
+		r0 = returnFunc(ctx, chatID, tags)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = returnFunc(ctx, chatID, tags)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_SetChatTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetChatTags'
+type MockChatService_SetChatTags_Call struct {
+	*mock.Call
+}
+
+// SetChatTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - tags []string
+func (_e *MockChatService_Expecter) SetChatTags(ctx interface{}, chatID interface{}, tags interface{}) *MockChatService_SetChatTags_Call {
+	return &MockChatService_SetChatTags_Call{Call: _e.mock.On("SetChatTags", ctx, chatID, tags)}
+}
+
+func (_c *MockChatService_SetChatTags_Call) Run(run func(ctx context.Context, chatID string, tags []string)) *MockChatService_SetChatTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetChatTags_Call) Return(ss []string, err error) *MockChatService_SetChatTags_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockChatService_SetChatTags_Call) RunAndReturn(run func(ctx context.Context, chatID string, tags []string) ([]string, error)) *MockChatService_SetChatTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SwitchBranch provides a mock function for the type MockChatService
 func (_mock *MockChatService) SwitchBranch(ctx context.Context, chatID string, targetMessageID string) error {
 	ret := _mock.Called(ctx, chatID, targetMessageID)
@@ -7,6 +7,9 @@ package mocks
 import (
 	"context"
 	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
+	"io"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -38,168 +41,1062 @@ func (_m *MockModelService) EXPECT() *MockModelService_Expecter {
 	return &MockModelService_Expecter{mock: &_m.Mock}
 }
 
-// Delete provides a mock function for the type MockModelService
-func (_mock *MockModelService) Delete(ctx context.Context, req *llm.DeleteModelRequest) error {
+// Benchmark provides a mock function for the type MockModelService
+func (_mock *MockModelService) Benchmark(ctx context.Context, modelName string, req *service.BenchmarkRequest) (*model.ModelBenchmark, error) {
+	ret := _mock.Called(ctx, modelName, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Benchmark")
+	}
+
+	var r0 *model.ModelBenchmark
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *service.BenchmarkRequest) (*model.ModelBenchmark, error)); ok {
+		return returnFunc(ctx, modelName, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *service.BenchmarkRequest) *model.ModelBenchmark); ok {
+		r0 = returnFunc(ctx, modelName, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ModelBenchmark)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *service.BenchmarkRequest) error); ok {
+		r1 = returnFunc(ctx, modelName, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_Benchmark_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Benchmark'
+type MockModelService_Benchmark_Call struct {
+	*mock.Call
+}
+
+// Benchmark is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelName string
+//   - req *service.BenchmarkRequest
+func (_e *MockModelService_Expecter) Benchmark(ctx interface{}, modelName interface{}, req interface{}) *MockModelService_Benchmark_Call {
+	return &MockModelService_Benchmark_Call{Call: _e.mock.On("Benchmark", ctx, modelName, req)}
+}
+
+func (_c *MockModelService_Benchmark_Call) Run(run func(ctx context.Context, modelName string, req *service.BenchmarkRequest)) *MockModelService_Benchmark_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *service.BenchmarkRequest
+		if args[2] != nil {
+			arg2 = args[2].(*service.BenchmarkRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Benchmark_Call) Return(modelBenchmark *model.ModelBenchmark, err error) *MockModelService_Benchmark_Call {
+	_c.Call.Return(modelBenchmark, err)
+	return _c
+}
+
+func (_c *MockModelService_Benchmark_Call) RunAndReturn(run func(ctx context.Context, modelName string, req *service.BenchmarkRequest) (*model.ModelBenchmark, error)) *MockModelService_Benchmark_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelPull provides a mock function for the type MockModelService
+func (_mock *MockModelService) CancelPull(ctx context.Context, req *service.CancelPullRequest) error {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelPull")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CancelPullRequest) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_CancelPull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelPull'
+type MockModelService_CancelPull_Call struct {
+	*mock.Call
+}
+
+// CancelPull is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *service.CancelPullRequest
+func (_e *MockModelService_Expecter) CancelPull(ctx interface{}, req interface{}) *MockModelService_CancelPull_Call {
+	return &MockModelService_CancelPull_Call{Call: _e.mock.On("CancelPull", ctx, req)}
+}
+
+func (_c *MockModelService_CancelPull_Call) Run(run func(ctx context.Context, req *service.CancelPullRequest)) *MockModelService_CancelPull_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.CancelPullRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.CancelPullRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_CancelPull_Call) Return(err error) *MockModelService_CancelPull_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_CancelPull_Call) RunAndReturn(run func(ctx context.Context, req *service.CancelPullRequest) error) *MockModelService_CancelPull_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CheckBlob provides a mock function for the type MockModelService
+func (_mock *MockModelService) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	ret := _mock.Called(ctx, digest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckBlob")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, digest)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, digest)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, digest)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_CheckBlob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckBlob'
+type MockModelService_CheckBlob_Call struct {
+	*mock.Call
+}
+
+// CheckBlob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - digest string
+func (_e *MockModelService_Expecter) CheckBlob(ctx interface{}, digest interface{}) *MockModelService_CheckBlob_Call {
+	return &MockModelService_CheckBlob_Call{Call: _e.mock.On("CheckBlob", ctx, digest)}
+}
+
+func (_c *MockModelService_CheckBlob_Call) Run(run func(ctx context.Context, digest string)) *MockModelService_CheckBlob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_CheckBlob_Call) Return(b bool, err error) *MockModelService_CheckBlob_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockModelService_CheckBlob_Call) RunAndReturn(run func(ctx context.Context, digest string) (bool, error)) *MockModelService_CheckBlob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function for the type MockModelService
+func (_mock *MockModelService) Complete(ctx context.Context, req *service.CompleteRequest) (*llm.GenerateResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 *llm.GenerateResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CompleteRequest) (*llm.GenerateResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.CompleteRequest) *llm.GenerateResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*llm.GenerateResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.CompleteRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type MockModelService_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *service.CompleteRequest
+func (_e *MockModelService_Expecter) Complete(ctx interface{}, req interface{}) *MockModelService_Complete_Call {
+	return &MockModelService_Complete_Call{Call: _e.mock.On("Complete", ctx, req)}
+}
+
+func (_c *MockModelService_Complete_Call) Run(run func(ctx context.Context, req *service.CompleteRequest)) *MockModelService_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.CompleteRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.CompleteRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Complete_Call) Return(generateResponse *llm.GenerateResponse, err error) *MockModelService_Complete_Call {
+	_c.Call.Return(generateResponse, err)
+	return _c
+}
+
+func (_c *MockModelService_Complete_Call) RunAndReturn(run func(ctx context.Context, req *service.CompleteRequest) (*llm.GenerateResponse, error)) *MockModelService_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Copy provides a mock function for the type MockModelService
+func (_mock *MockModelService) Copy(ctx context.Context, req *llm.CopyModelRequest) error {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Delete")
+		panic("no return value specified for Copy")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.CopyModelRequest) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_Copy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Copy'
+type MockModelService_Copy_Call struct {
+	*mock.Call
+}
+
+// Copy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.CopyModelRequest
+func (_e *MockModelService_Expecter) Copy(ctx interface{}, req interface{}) *MockModelService_Copy_Call {
+	return &MockModelService_Copy_Call{Call: _e.mock.On("Copy", ctx, req)}
+}
+
+func (_c *MockModelService_Copy_Call) Run(run func(ctx context.Context, req *llm.CopyModelRequest)) *MockModelService_Copy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.CopyModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.CopyModelRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Copy_Call) Return(err error) *MockModelService_Copy_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_Copy_Call) RunAndReturn(run func(ctx context.Context, req *llm.CopyModelRequest) error) *MockModelService_Copy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function for the type MockModelService
+func (_mock *MockModelService) Create(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error {
+	ret := _mock.Called(ctx, req, ch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.CreateModelRequest, chan<- llm.PullStatus) error); ok {
+		r0 = returnFunc(ctx, req, ch)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockModelService_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.CreateModelRequest
+//   - ch chan<- llm.PullStatus
+func (_e *MockModelService_Expecter) Create(ctx interface{}, req interface{}, ch interface{}) *MockModelService_Create_Call {
+	return &MockModelService_Create_Call{Call: _e.mock.On("Create", ctx, req, ch)}
+}
+
+func (_c *MockModelService_Create_Call) Run(run func(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus)) *MockModelService_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.CreateModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.CreateModelRequest)
+		}
+		var arg2 chan<- llm.PullStatus
+		if args[2] != nil {
+			arg2 = args[2].(chan<- llm.PullStatus)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Create_Call) Return(err error) *MockModelService_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_Create_Call) RunAndReturn(run func(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error) *MockModelService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockModelService
+func (_mock *MockModelService) Delete(ctx context.Context, req *llm.DeleteModelRequest) error {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.DeleteModelRequest) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockModelService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.DeleteModelRequest
+func (_e *MockModelService_Expecter) Delete(ctx interface{}, req interface{}) *MockModelService_Delete_Call {
+	return &MockModelService_Delete_Call{Call: _e.mock.On("Delete", ctx, req)}
+}
+
+func (_c *MockModelService_Delete_Call) Run(run func(ctx context.Context, req *llm.DeleteModelRequest)) *MockModelService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.DeleteModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.DeleteModelRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Delete_Call) Return(err error) *MockModelService_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_Delete_Call) RunAndReturn(run func(ctx context.Context, req *llm.DeleteModelRequest) error) *MockModelService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Embed provides a mock function for the type MockModelService
+func (_mock *MockModelService) Embed(ctx context.Context, req *service.EmbedRequest) (*llm.EmbeddingsResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Embed")
+	}
+
+	var r0 *llm.EmbeddingsResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.EmbedRequest) (*llm.EmbeddingsResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.EmbedRequest) *llm.EmbeddingsResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*llm.EmbeddingsResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.EmbedRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_Embed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Embed'
+type MockModelService_Embed_Call struct {
+	*mock.Call
+}
+
+// Embed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *service.EmbedRequest
+func (_e *MockModelService_Expecter) Embed(ctx interface{}, req interface{}) *MockModelService_Embed_Call {
+	return &MockModelService_Embed_Call{Call: _e.mock.On("Embed", ctx, req)}
+}
+
+func (_c *MockModelService_Embed_Call) Run(run func(ctx context.Context, req *service.EmbedRequest)) *MockModelService_Embed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.EmbedRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.EmbedRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Embed_Call) Return(embeddingsResponse *llm.EmbeddingsResponse, err error) *MockModelService_Embed_Call {
+	_c.Call.Return(embeddingsResponse, err)
+	return _c
+}
+
+func (_c *MockModelService_Embed_Call) RunAndReturn(run func(ctx context.Context, req *service.EmbedRequest) (*llm.EmbeddingsResponse, error)) *MockModelService_Embed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDefaults provides a mock function for the type MockModelService
+func (_mock *MockModelService) GetDefaults(ctx context.Context, modelName string) (*service.ModelDefaults, error) {
+	ret := _mock.Called(ctx, modelName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDefaults")
+	}
+
+	var r0 *service.ModelDefaults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*service.ModelDefaults, error)); ok {
+		return returnFunc(ctx, modelName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *service.ModelDefaults); ok {
+		r0 = returnFunc(ctx, modelName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ModelDefaults)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, modelName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_GetDefaults_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDefaults'
+type MockModelService_GetDefaults_Call struct {
+	*mock.Call
+}
+
+// GetDefaults is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelName string
+func (_e *MockModelService_Expecter) GetDefaults(ctx interface{}, modelName interface{}) *MockModelService_GetDefaults_Call {
+	return &MockModelService_GetDefaults_Call{Call: _e.mock.On("GetDefaults", ctx, modelName)}
+}
+
+func (_c *MockModelService_GetDefaults_Call) Run(run func(ctx context.Context, modelName string)) *MockModelService_GetDefaults_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_GetDefaults_Call) Return(modelDefaults *service.ModelDefaults, err error) *MockModelService_GetDefaults_Call {
+	_c.Call.Return(modelDefaults, err)
+	return _c
+}
+
+func (_c *MockModelService_GetDefaults_Call) RunAndReturn(run func(ctx context.Context, modelName string) (*service.ModelDefaults, error)) *MockModelService_GetDefaults_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockModelService
+func (_mock *MockModelService) List(ctx context.Context) (*llm.ListModelsResponse, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *llm.ListModelsResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*llm.ListModelsResponse, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *llm.ListModelsResponse); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*llm.ListModelsResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockModelService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) List(ctx interface{}) *MockModelService_List_Call {
+	return &MockModelService_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockModelService_List_Call) Run(run func(ctx context.Context)) *MockModelService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_List_Call) Return(listModelsResponse *llm.ListModelsResponse, err error) *MockModelService_List_Call {
+	_c.Call.Return(listModelsResponse, err)
+	return _c
+}
+
+func (_c *MockModelService_List_Call) RunAndReturn(run func(ctx context.Context) (*llm.ListModelsResponse, error)) *MockModelService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPulls provides a mock function for the type MockModelService
+func (_mock *MockModelService) ListPulls(ctx context.Context) []service.PullJobStatus {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPulls")
+	}
+
+	var r0 []service.PullJobStatus
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []service.PullJobStatus); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.PullJobStatus)
+		}
+	}
+	return r0
+}
+
+// MockModelService_ListPulls_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPulls'
+type MockModelService_ListPulls_Call struct {
+	*mock.Call
+}
+
+// ListPulls is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) ListPulls(ctx interface{}) *MockModelService_ListPulls_Call {
+	return &MockModelService_ListPulls_Call{Call: _e.mock.On("ListPulls", ctx)}
+}
+
+func (_c *MockModelService_ListPulls_Call) Run(run func(ctx context.Context)) *MockModelService_ListPulls_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_ListPulls_Call) Return(pullJobStatuss []service.PullJobStatus) *MockModelService_ListPulls_Call {
+	_c.Call.Return(pullJobStatuss)
+	return _c
+}
+
+func (_c *MockModelService_ListPulls_Call) RunAndReturn(run func(ctx context.Context) []service.PullJobStatus) *MockModelService_ListPulls_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRunning provides a mock function for the type MockModelService
+func (_mock *MockModelService) ListRunning(ctx context.Context) (*service.RunningModels, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRunning")
+	}
+
+	var r0 *service.RunningModels
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*service.RunningModels, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *service.RunningModels); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RunningModels)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_ListRunning_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRunning'
+type MockModelService_ListRunning_Call struct {
+	*mock.Call
+}
+
+// ListRunning is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) ListRunning(ctx interface{}) *MockModelService_ListRunning_Call {
+	return &MockModelService_ListRunning_Call{Call: _e.mock.On("ListRunning", ctx)}
+}
+
+func (_c *MockModelService_ListRunning_Call) Run(run func(ctx context.Context)) *MockModelService_ListRunning_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_ListRunning_Call) Return(runningModels *service.RunningModels, err error) *MockModelService_ListRunning_Call {
+	_c.Call.Return(runningModels, err)
+	return _c
+}
+
+func (_c *MockModelService_ListRunning_Call) RunAndReturn(run func(ctx context.Context) (*service.RunningModels, error)) *MockModelService_ListRunning_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OllamaStatus provides a mock function for the type MockModelService
+func (_mock *MockModelService) OllamaStatus(ctx context.Context) *service.OllamaStatus {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OllamaStatus")
+	}
+
+	var r0 *service.OllamaStatus
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *service.OllamaStatus); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.OllamaStatus)
+		}
+	}
+	return r0
+}
+
+// MockModelService_OllamaStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OllamaStatus'
+type MockModelService_OllamaStatus_Call struct {
+	*mock.Call
+}
+
+// OllamaStatus is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) OllamaStatus(ctx interface{}) *MockModelService_OllamaStatus_Call {
+	return &MockModelService_OllamaStatus_Call{Call: _e.mock.On("OllamaStatus", ctx)}
+}
+
+func (_c *MockModelService_OllamaStatus_Call) Run(run func(ctx context.Context)) *MockModelService_OllamaStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_OllamaStatus_Call) Return(ollamaStatus *service.OllamaStatus) *MockModelService_OllamaStatus_Call {
+	_c.Call.Return(ollamaStatus)
+	return _c
+}
+
+func (_c *MockModelService_OllamaStatus_Call) RunAndReturn(run func(ctx context.Context) *service.OllamaStatus) *MockModelService_OllamaStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pull provides a mock function for the type MockModelService
+func (_mock *MockModelService) Pull(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error {
+	ret := _mock.Called(ctx, req, ch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pull")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.PullModelRequest, chan<- llm.PullStatus) error); ok {
+		r0 = returnFunc(ctx, req, ch)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_Pull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pull'
+type MockModelService_Pull_Call struct {
+	*mock.Call
+}
+
+// Pull is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.PullModelRequest
+//   - ch chan<- llm.PullStatus
+func (_e *MockModelService_Expecter) Pull(ctx interface{}, req interface{}, ch interface{}) *MockModelService_Pull_Call {
+	return &MockModelService_Pull_Call{Call: _e.mock.On("Pull", ctx, req, ch)}
+}
+
+func (_c *MockModelService_Pull_Call) Run(run func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus)) *MockModelService_Pull_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.PullModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.PullModelRequest)
+		}
+		var arg2 chan<- llm.PullStatus
+		if args[2] != nil {
+			arg2 = args[2].(chan<- llm.PullStatus)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Pull_Call) Return(err error) *MockModelService_Pull_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_Pull_Call) RunAndReturn(run func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error) *MockModelService_Pull_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PushBlob provides a mock function for the type MockModelService
+func (_mock *MockModelService) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	ret := _mock.Called(ctx, digest, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PushBlob")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.DeleteModelRequest) error); ok {
-		r0 = returnFunc(ctx, req)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, io.Reader) error); ok {
+		r0 = returnFunc(ctx, digest, r)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockModelService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
-type MockModelService_Delete_Call struct {
+// MockModelService_PushBlob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PushBlob'
+type MockModelService_PushBlob_Call struct {
 	*mock.Call
 }
 
-// Delete is a helper method to define mock.On call
+// PushBlob is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *llm.DeleteModelRequest
-func (_e *MockModelService_Expecter) Delete(ctx interface{}, req interface{}) *MockModelService_Delete_Call {
-	return &MockModelService_Delete_Call{Call: _e.mock.On("Delete", ctx, req)}
+//   - digest string
+//   - r io.Reader
+func (_e *MockModelService_Expecter) PushBlob(ctx interface{}, digest interface{}, r interface{}) *MockModelService_PushBlob_Call {
+	return &MockModelService_PushBlob_Call{Call: _e.mock.On("PushBlob", ctx, digest, r)}
 }
 
-func (_c *MockModelService_Delete_Call) Run(run func(ctx context.Context, req *llm.DeleteModelRequest)) *MockModelService_Delete_Call {
+func (_c *MockModelService_PushBlob_Call) Run(run func(ctx context.Context, digest string, r io.Reader)) *MockModelService_PushBlob_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *llm.DeleteModelRequest
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(*llm.DeleteModelRequest)
+			arg1 = args[1].(string)
+		}
+		var arg2 io.Reader
+		if args[2] != nil {
+			arg2 = args[2].(io.Reader)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockModelService_Delete_Call) Return(err error) *MockModelService_Delete_Call {
+func (_c *MockModelService_PushBlob_Call) Return(err error) *MockModelService_PushBlob_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockModelService_Delete_Call) RunAndReturn(run func(ctx context.Context, req *llm.DeleteModelRequest) error) *MockModelService_Delete_Call {
+func (_c *MockModelService_PushBlob_Call) RunAndReturn(run func(ctx context.Context, digest string, r io.Reader) error) *MockModelService_PushBlob_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// List provides a mock function for the type MockModelService
-func (_mock *MockModelService) List(ctx context.Context) (*llm.ListModelsResponse, error) {
-	ret := _mock.Called(ctx)
+// Search provides a mock function for the type MockModelService
+func (_mock *MockModelService) Search(ctx context.Context, req *service.SearchModelsRequest) ([]llm.RegistryModel, error) {
+	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for List")
+		panic("no return value specified for Search")
 	}
 
-	var r0 *llm.ListModelsResponse
+	var r0 []llm.RegistryModel
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) (*llm.ListModelsResponse, error)); ok {
-		return returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SearchModelsRequest) ([]llm.RegistryModel, error)); ok {
+		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) *llm.ListModelsResponse); ok {
-		r0 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SearchModelsRequest) []llm.RegistryModel); ok {
+		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*llm.ListModelsResponse)
+			r0 = ret.Get(0).([]llm.RegistryModel)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SearchModelsRequest) error); ok {
+		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockModelService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
-type MockModelService_List_Call struct {
+// MockModelService_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockModelService_Search_Call struct {
 	*mock.Call
 }
 
-// List is a helper method to define mock.On call
+// Search is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockModelService_Expecter) List(ctx interface{}) *MockModelService_List_Call {
-	return &MockModelService_List_Call{Call: _e.mock.On("List", ctx)}
+//   - req *service.SearchModelsRequest
+func (_e *MockModelService_Expecter) Search(ctx interface{}, req interface{}) *MockModelService_Search_Call {
+	return &MockModelService_Search_Call{Call: _e.mock.On("Search", ctx, req)}
 }
 
-func (_c *MockModelService_List_Call) Run(run func(ctx context.Context)) *MockModelService_List_Call {
+func (_c *MockModelService_Search_Call) Run(run func(ctx context.Context, req *service.SearchModelsRequest)) *MockModelService_Search_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
+		var arg1 *service.SearchModelsRequest
+		if args[1] != nil {
+			arg1 = args[1].(*service.SearchModelsRequest)
+		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockModelService_List_Call) Return(listModelsResponse *llm.ListModelsResponse, err error) *MockModelService_List_Call {
-	_c.Call.Return(listModelsResponse, err)
+func (_c *MockModelService_Search_Call) Return(registryModels []llm.RegistryModel, err error) *MockModelService_Search_Call {
+	_c.Call.Return(registryModels, err)
 	return _c
 }
 
-func (_c *MockModelService_List_Call) RunAndReturn(run func(ctx context.Context) (*llm.ListModelsResponse, error)) *MockModelService_List_Call {
+func (_c *MockModelService_Search_Call) RunAndReturn(run func(ctx context.Context, req *service.SearchModelsRequest) ([]llm.RegistryModel, error)) *MockModelService_Search_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Pull provides a mock function for the type MockModelService
-func (_mock *MockModelService) Pull(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error {
-	ret := _mock.Called(ctx, req, ch)
+// SetDefaults provides a mock function for the type MockModelService
+func (_mock *MockModelService) SetDefaults(ctx context.Context, modelName string, opts *llm.RequestOptions) (*service.ModelDefaults, error) {
+	ret := _mock.Called(ctx, modelName, opts)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Pull")
+		panic("no return value specified for SetDefaults")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.PullModelRequest, chan<- llm.PullStatus) error); ok {
-		r0 = returnFunc(ctx, req, ch)
+	var r0 *service.ModelDefaults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *llm.RequestOptions) (*service.ModelDefaults, error)); ok {
+		return returnFunc(ctx, modelName, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *llm.RequestOptions) *service.ModelDefaults); ok {
+		r0 = returnFunc(ctx, modelName, opts)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ModelDefaults)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *llm.RequestOptions) error); ok {
+		r1 = returnFunc(ctx, modelName, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockModelService_Pull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pull'
-type MockModelService_Pull_Call struct {
+// MockModelService_SetDefaults_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDefaults'
+type MockModelService_SetDefaults_Call struct {
 	*mock.Call
 }
 
-// Pull is a helper method to define mock.On call
+// SetDefaults is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *llm.PullModelRequest
-//   - ch chan<- llm.PullStatus
-func (_e *MockModelService_Expecter) Pull(ctx interface{}, req interface{}, ch interface{}) *MockModelService_Pull_Call {
-	return &MockModelService_Pull_Call{Call: _e.mock.On("Pull", ctx, req, ch)}
+//   - modelName string
+//   - opts *llm.RequestOptions
+func (_e *MockModelService_Expecter) SetDefaults(ctx interface{}, modelName interface{}, opts interface{}) *MockModelService_SetDefaults_Call {
+	return &MockModelService_SetDefaults_Call{Call: _e.mock.On("SetDefaults", ctx, modelName, opts)}
 }
 
-func (_c *MockModelService_Pull_Call) Run(run func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus)) *MockModelService_Pull_Call {
+func (_c *MockModelService_SetDefaults_Call) Run(run func(ctx context.Context, modelName string, opts *llm.RequestOptions)) *MockModelService_SetDefaults_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *llm.PullModelRequest
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(*llm.PullModelRequest)
+			arg1 = args[1].(string)
 		}
-		var arg2 chan<- llm.PullStatus
+		var arg2 *llm.RequestOptions
 		if args[2] != nil {
-			arg2 = args[2].(chan<- llm.PullStatus)
+			arg2 = args[2].(*llm.RequestOptions)
 		}
 		run(
 			arg0,
@@ -210,12 +1107,12 @@ func (_c *MockModelService_Pull_Call) Run(run func(ctx context.Context, req *llm
 	return _c
 }
 
-func (_c *MockModelService_Pull_Call) Return(err error) *MockModelService_Pull_Call {
-	_c.Call.Return(err)
+func (_c *MockModelService_SetDefaults_Call) Return(modelDefaults *service.ModelDefaults, err error) *MockModelService_SetDefaults_Call {
+	_c.Call.Return(modelDefaults, err)
 	return _c
 }
 
-func (_c *MockModelService_Pull_Call) RunAndReturn(run func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error) *MockModelService_Pull_Call {
+func (_c *MockModelService_SetDefaults_Call) RunAndReturn(run func(ctx context.Context, modelName string, opts *llm.RequestOptions) (*service.ModelDefaults, error)) *MockModelService_SetDefaults_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -287,3 +1184,181 @@ func (_c *MockModelService_Show_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// Storage provides a mock function for the type MockModelService
+func (_mock *MockModelService) Storage(ctx context.Context) (*service.ModelStorage, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Storage")
+	}
+
+	var r0 *service.ModelStorage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*service.ModelStorage, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *service.ModelStorage); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ModelStorage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockModelService_Storage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Storage'
+type MockModelService_Storage_Call struct {
+	*mock.Call
+}
+
+// Storage is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) Storage(ctx interface{}) *MockModelService_Storage_Call {
+	return &MockModelService_Storage_Call{Call: _e.mock.On("Storage", ctx)}
+}
+
+func (_c *MockModelService_Storage_Call) Run(run func(ctx context.Context)) *MockModelService_Storage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Storage_Call) Return(modelStorage *service.ModelStorage, err error) *MockModelService_Storage_Call {
+	_c.Call.Return(modelStorage, err)
+	return _c
+}
+
+func (_c *MockModelService_Storage_Call) RunAndReturn(run func(ctx context.Context) (*service.ModelStorage, error)) *MockModelService_Storage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockModelService
+func (_mock *MockModelService) Update(ctx context.Context, modelName string, ch chan<- llm.PullStatus) error {
+	ret := _mock.Called(ctx, modelName, ch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, chan<- llm.PullStatus) error); ok {
+		r0 = returnFunc(ctx, modelName, ch)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockModelService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockModelService_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelName string
+//   - ch chan<- llm.PullStatus
+func (_e *MockModelService_Expecter) Update(ctx interface{}, modelName interface{}, ch interface{}) *MockModelService_Update_Call {
+	return &MockModelService_Update_Call{Call: _e.mock.On("Update", ctx, modelName, ch)}
+}
+
+func (_c *MockModelService_Update_Call) Run(run func(ctx context.Context, modelName string, ch chan<- llm.PullStatus)) *MockModelService_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 chan<- llm.PullStatus
+		if args[2] != nil {
+			arg2 = args[2].(chan<- llm.PullStatus)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Update_Call) Return(err error) *MockModelService_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockModelService_Update_Call) RunAndReturn(run func(ctx context.Context, modelName string, ch chan<- llm.PullStatus) error) *MockModelService_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Updates provides a mock function for the type MockModelService
+func (_mock *MockModelService) Updates(ctx context.Context) *service.ModelUpdates {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Updates")
+	}
+
+	var r0 *service.ModelUpdates
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *service.ModelUpdates); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ModelUpdates)
+		}
+	}
+	return r0
+}
+
+// MockModelService_Updates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Updates'
+type MockModelService_Updates_Call struct {
+	*mock.Call
+}
+
+// Updates is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockModelService_Expecter) Updates(ctx interface{}) *MockModelService_Updates_Call {
+	return &MockModelService_Updates_Call{Call: _e.mock.On("Updates", ctx)}
+}
+
+func (_c *MockModelService_Updates_Call) Run(run func(ctx context.Context)) *MockModelService_Updates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockModelService_Updates_Call) Return(modelUpdates *service.ModelUpdates) *MockModelService_Updates_Call {
+	_c.Call.Return(modelUpdates)
+	return _c
+}
+
+func (_c *MockModelService_Updates_Call) RunAndReturn(run func(ctx context.Context) *service.ModelUpdates) *MockModelService_Updates_Call {
+	_c.Call.Return(run)
+	return _c
+}
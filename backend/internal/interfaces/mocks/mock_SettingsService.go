@@ -38,6 +38,63 @@ func (_m *MockSettingsService) EXPECT() *MockSettingsService_Expecter {
 	return &MockSettingsService_Expecter{mock: &_m.Mock}
 }
 
+// DeleteModelAlias provides a mock function for the type MockSettingsService
+func (_mock *MockSettingsService) DeleteModelAlias(ctx context.Context, name string) error {
+	ret := _mock.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteModelAlias")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSettingsService_DeleteModelAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteModelAlias'
+type MockSettingsService_DeleteModelAlias_Call struct {
+	*mock.Call
+}
+
+// DeleteModelAlias is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockSettingsService_Expecter) DeleteModelAlias(ctx interface{}, name interface{}) *MockSettingsService_DeleteModelAlias_Call {
+	return &MockSettingsService_DeleteModelAlias_Call{Call: _e.mock.On("DeleteModelAlias", ctx, name)}
+}
+
+func (_c *MockSettingsService_DeleteModelAlias_Call) Run(run func(ctx context.Context, name string)) *MockSettingsService_DeleteModelAlias_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSettingsService_DeleteModelAlias_Call) Return(err error) *MockSettingsService_DeleteModelAlias_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSettingsService_DeleteModelAlias_Call) RunAndReturn(run func(ctx context.Context, name string) error) *MockSettingsService_DeleteModelAlias_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function for the type MockSettingsService
 func (_mock *MockSettingsService) Get(ctx context.Context) (*service.Settings, error) {
 	ret := _mock.Called(ctx)
@@ -168,6 +225,130 @@ func (_c *MockSettingsService_InitAndGet_Call) RunAndReturn(run func(ctx context
 	return _c
 }
 
+// ListModelAliases provides a mock function for the type MockSettingsService
+func (_mock *MockSettingsService) ListModelAliases(ctx context.Context) ([]service.ModelAlias, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListModelAliases")
+	}
+
+	var r0 []service.ModelAlias
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]service.ModelAlias, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []service.ModelAlias); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.ModelAlias)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSettingsService_ListModelAliases_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListModelAliases'
+type MockSettingsService_ListModelAliases_Call struct {
+	*mock.Call
+}
+
+// ListModelAliases is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSettingsService_Expecter) ListModelAliases(ctx interface{}) *MockSettingsService_ListModelAliases_Call {
+	return &MockSettingsService_ListModelAliases_Call{Call: _e.mock.On("ListModelAliases", ctx)}
+}
+
+func (_c *MockSettingsService_ListModelAliases_Call) Run(run func(ctx context.Context)) *MockSettingsService_ListModelAliases_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSettingsService_ListModelAliases_Call) Return(modelAliass []service.ModelAlias, err error) *MockSettingsService_ListModelAliases_Call {
+	_c.Call.Return(modelAliass, err)
+	return _c
+}
+
+func (_c *MockSettingsService_ListModelAliases_Call) RunAndReturn(run func(ctx context.Context) ([]service.ModelAlias, error)) *MockSettingsService_ListModelAliases_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Refresh provides a mock function for the type MockSettingsService
+func (_mock *MockSettingsService) Refresh(ctx context.Context) (*service.Settings, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *service.Settings
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*service.Settings, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *service.Settings); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.Settings)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSettingsService_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
+type MockSettingsService_Refresh_Call struct {
+	*mock.Call
+}
+
+// Refresh is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSettingsService_Expecter) Refresh(ctx interface{}) *MockSettingsService_Refresh_Call {
+	return &MockSettingsService_Refresh_Call{Call: _e.mock.On("Refresh", ctx)}
+}
+
+func (_c *MockSettingsService_Refresh_Call) Run(run func(ctx context.Context)) *MockSettingsService_Refresh_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSettingsService_Refresh_Call) Return(settings *service.Settings, err error) *MockSettingsService_Refresh_Call {
+	_c.Call.Return(settings, err)
+	return _c
+}
+
+func (_c *MockSettingsService_Refresh_Call) RunAndReturn(run func(ctx context.Context) (*service.Settings, error)) *MockSettingsService_Refresh_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Save provides a mock function for the type MockSettingsService
 func (_mock *MockSettingsService) Save(ctx context.Context, settings *service.Settings) error {
 	ret := _mock.Called(ctx, settings)
@@ -224,3 +405,60 @@ func (_c *MockSettingsService_Save_Call) RunAndReturn(run func(ctx context.Conte
 	_c.Call.Return(run)
 	return _c
 }
+
+// SetModelAlias provides a mock function for the type MockSettingsService
+func (_mock *MockSettingsService) SetModelAlias(ctx context.Context, alias *service.ModelAlias) error {
+	ret := _mock.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetModelAlias")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.ModelAlias) error); ok {
+		r0 = returnFunc(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSettingsService_SetModelAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetModelAlias'
+type MockSettingsService_SetModelAlias_Call struct {
+	*mock.Call
+}
+
+// SetModelAlias is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias *service.ModelAlias
+func (_e *MockSettingsService_Expecter) SetModelAlias(ctx interface{}, alias interface{}) *MockSettingsService_SetModelAlias_Call {
+	return &MockSettingsService_SetModelAlias_Call{Call: _e.mock.On("SetModelAlias", ctx, alias)}
+}
+
+func (_c *MockSettingsService_SetModelAlias_Call) Run(run func(ctx context.Context, alias *service.ModelAlias)) *MockSettingsService_SetModelAlias_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.ModelAlias
+		if args[1] != nil {
+			arg1 = args[1].(*service.ModelAlias)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSettingsService_SetModelAlias_Call) Return(err error) *MockSettingsService_SetModelAlias_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSettingsService_SetModelAlias_Call) RunAndReturn(run func(ctx context.Context, alias *service.ModelAlias) error) *MockSettingsService_SetModelAlias_Call {
+	_c.Call.Return(run)
+	return _c
+}
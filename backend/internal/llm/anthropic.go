@@ -0,0 +1,376 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// anthropicVersion is the version of the Messages API the requests are written for.
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens is the answer length used when `num_predict` does not
+	// set one; unlike Ollama, the Messages API requires a limit.
+	anthropicMaxTokens = 4096
+)
+
+// AnthropicConfig holds the settings of the Anthropic provider.
+type AnthropicConfig struct {
+	APIKey string
+}
+
+// anthropicProvider talks to Anthropic's Messages API. Like the other hosted
+// APIs, it only generates and lists models.
+type anthropicProvider struct {
+	unmanagedModels
+	client *http.Client
+	url    string
+	cfg    AnthropicConfig
+}
+
+// NewAnthropicProvider creates a provider for the API at baseURL, e.g.
+// "https://api.anthropic.com".
+func NewAnthropicProvider(baseURL string, cfg AnthropicConfig) LLMProvider {
+	return &anthropicProvider{
+		client: &http.Client{},
+		url:    strings.TrimSuffix(baseURL, "/"),
+		cfg:    cfg,
+	}
+}
+
+// --- Anthropic Structs ---
+
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Stream        bool               `json:"stream"`
+	Temperature   *float32           `json:"temperature,omitempty"`
+	TopP          *float32           `json:"top_p,omitempty"`
+	TopK          *int               `json:"top_k,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+}
+
+// anthropicMessage holds either a plain string or, for messages with images, a
+// list of content blocks.
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type   string                `json:"type"`
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Model   string                  `json:"model"`
+	Content []anthropicContentBlock `json:"content"`
+	// StopReason is "end_turn", "stop_sequence", "max_tokens" or "tool_use".
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+// anthropicEvent is the data of a streamed event. Each type fills other fields:
+// message_start the message with the input tokens, content_block_delta the
+// delta with text, message_delta the delta with the stop reason and the usage
+// with the output tokens, and error the error.
+type anthropicEvent struct {
+	Type    string            `json:"type"`
+	Message anthropicResponse `json:"message"`
+	Delta   struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// --- anthropicProvider methods ---
+
+// do sends a request with the API key and version headers.
+func (p *anthropicProvider) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("x-api-key", p.cfg.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	resp, err := p.client.Do(req)
+	return resp, unreachable(req.Context(), err)
+}
+
+// postMessages sends a request to the Messages API and returns the response if
+// its status is 200.
+func (p *anthropicProvider) postMessages(ctx context.Context, payload *anthropicRequest) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrModelNotFound, string(bodyBytes))
+		}
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return resp, nil
+}
+
+// messagesRequest converts a request to the Messages API, where the system
+// prompt is a top-level field rather than a message. Options without an
+// equivalent, such as seed or num_ctx, are dropped.
+func messagesRequest(req *GenerateRequest, stream bool) *anthropicRequest {
+	msgReq := &anthropicRequest{Model: req.Model, MaxTokens: anthropicMaxTokens, Stream: stream}
+	var system []string
+	for _, m := range req.Messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		msgReq.Messages = append(msgReq.Messages, anthropicMessageFrom(m))
+	}
+	msgReq.System = strings.Join(system, "\n\n")
+	if len(req.Messages) == 0 && req.Prompt != "" {
+		msgReq.Messages = []anthropicMessage{{Role: "user", Content: req.Prompt}}
+	}
+	if opts := req.Options; opts != nil {
+		msgReq.Temperature, msgReq.TopP, msgReq.TopK, msgReq.StopSequences = opts.Temperature, opts.TopP, opts.TopK, opts.Stop
+		if opts.NumPredict != nil && *opts.NumPredict > 0 {
+			msgReq.MaxTokens = *opts.NumPredict
+		}
+	}
+	return msgReq
+}
+
+// anthropicMessageFrom converts a message, passing its images as base64 blocks.
+func anthropicMessageFrom(m Message) anthropicMessage {
+	if len(m.Images) == 0 {
+		return anthropicMessage{Role: m.Role, Content: m.Content}
+	}
+	var blocks []anthropicContentBlock
+	for _, image := range m.Images {
+		blocks = append(blocks, anthropicContentBlock{Type: "image", Source: &anthropicImageSource{
+			Type:      "base64",
+			MediaType: imageMediaType(image),
+			Data:      image,
+		}})
+	}
+	blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+	return anthropicMessage{Role: m.Role, Content: blocks}
+}
+
+// doneReason maps a stop reason of the Messages API to Ollama's done reasons.
+func doneReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "max_tokens":
+		return "length"
+	default:
+		return stopReason
+	}
+}
+
+func (p *anthropicProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	if req.Suffix != "" {
+		return nil, fmt.Errorf("%w: fill-in-middle completion", ErrUnsupported)
+	}
+	// A request without a prompt or messages only loads a model in Ollama; there
+	// is nothing to load here.
+	if len(req.Messages) == 0 && req.Prompt == "" {
+		return &GenerateResponse{Model: req.Model, Done: true}, nil
+	}
+
+	started := time.Now()
+	resp, err := p.postMessages(ctx, messagesRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in Generate", "error", err)
+		}
+	}()
+
+	var msgResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&msgResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range msgResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	elapsed := time.Since(started).Nanoseconds()
+	return &GenerateResponse{
+		Model:    msgResp.Model,
+		Response: text.String(),
+		Done:     true,
+		Stats: &GenerationStats{
+			TotalDuration:   elapsed,
+			EvalDuration:    elapsed,
+			PromptEvalCount: msgResp.Usage.InputTokens,
+			EvalCount:       msgResp.Usage.OutputTokens,
+			DoneReason:      doneReason(msgResp.StopReason),
+		},
+	}, nil
+}
+
+// GenerateStream streams a message. The token counts arrive in message_start
+// and message_delta; the durations are measured here like for OpenAI-compatible APIs.
+func (p *anthropicProvider) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
+	defer close(ch)
+	started := time.Now()
+	resp, err := p.postMessages(ctx, messagesRequest(req, true))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in GenerateStream", "error", err)
+		}
+	}()
+
+	send := func(streamResp StreamResponse) error {
+		select {
+		case ch <- streamResp:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	stats := &GenerationStats{}
+	var firstToken time.Time
+	err = readSSE(ctx, resp.Body, func(data string) (bool, error) {
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			slog.Warn("Failed to unmarshal stream event from Anthropic", "error", err, "line", data)
+			return false, send(StreamResponse{Error: "Failed to decode stream chunk"})
+		}
+		switch event.Type {
+		case "message_start":
+			stats.PromptEvalCount = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return false, nil
+			}
+			if firstToken.IsZero() {
+				firstToken = time.Now()
+			}
+			return false, send(StreamResponse{Content: event.Delta.Text})
+		case "message_delta":
+			stats.EvalCount = event.Usage.OutputTokens
+			stats.DoneReason = doneReason(event.Delta.StopReason)
+		case "message_stop":
+			return true, nil
+		case "error":
+			return false, fmt.Errorf("api reported an error: %s: %s", event.Error.Type, event.Error.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	finished := time.Now()
+	if firstToken.IsZero() {
+		firstToken = finished
+	}
+	stats.TotalDuration = finished.Sub(started).Nanoseconds()
+	stats.PromptEvalDuration = firstToken.Sub(started).Nanoseconds()
+	stats.EvalDuration = finished.Sub(firstToken).Nanoseconds()
+	return send(StreamResponse{Done: true, Stats: stats})
+}
+
+// ListModels lists the models available to the API key, following the pages of
+// the listing.
+func (p *anthropicProvider) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+	models := []Model{}
+	query := url.Values{"limit": {"1000"}}
+	for {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/v1/models?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not create request: %w", err)
+		}
+		page, err := p.listModelsPage(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			models = append(models, Model{Name: m.ID, ModifiedAt: m.CreatedAt})
+		}
+		if !page.HasMore || page.LastID == "" {
+			return &ListModelsResponse{Models: models}, nil
+		}
+		query.Set("after_id", page.LastID)
+	}
+}
+
+type anthropicModelsPage struct {
+	Data []struct {
+		ID        string `json:"id"`
+		CreatedAt string `json:"created_at"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+func (p *anthropicProvider) listModelsPage(httpReq *http.Request) (*anthropicModelsPage, error) {
+	resp, err := p.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in ListModels", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var page anthropicModelsPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	return &page, nil
+}
+
+// Embeddings is not offered by Anthropic.
+func (p *anthropicProvider) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	return nil, fmt.Errorf("%w: computing embeddings", ErrUnsupported)
+}
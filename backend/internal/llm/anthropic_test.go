@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnthropicProvider uses a mock Messages API to verify that the system prompt
+// is moved out of the messages and that streamed events, stop reasons and usage
+// are mapped like Ollama's.
+func TestAnthropicProvider(t *testing.T) {
+	ctx := context.Background()
+	var capturedPath string
+	var capturedHeader http.Header
+	var capturedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		capturedHeader = r.Header
+		capturedBody, _ = io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/v1/messages":
+			var req anthropicRequest
+			require.NoError(t, json.Unmarshal(capturedBody, &req))
+			if !req.Stream {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"model": "claude-sonnet-4-5", "content": [{"type": "text", "text": "Hello!"}], "stop_reason": "max_tokens", "usage": {"input_tokens": 12, "output_tokens": 5}}`))
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("event: message_start\n" +
+				`data: {"type": "message_start", "message": {"model": "claude-sonnet-4-5", "content": [], "usage": {"input_tokens": 12, "output_tokens": 1}}}` + "\n\n" +
+				"event: content_block_start\n" +
+				`data: {"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}` + "\n\n" +
+				"event: ping\n" +
+				`data: {"type": "ping"}` + "\n\n" +
+				"event: content_block_delta\n" +
+				`data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Hel"}}` + "\n\n" +
+				"event: content_block_delta\n" +
+				`data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "lo!"}}` + "\n\n" +
+				"event: content_block_stop\n" +
+				`data: {"type": "content_block_stop", "index": 0}` + "\n\n" +
+				"event: message_delta\n" +
+				`data: {"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 5}}` + "\n\n" +
+				"event: message_stop\n" +
+				`data: {"type": "message_stop"}` + "\n\n"))
+		case "/v1/models":
+			w.Header().Set("Content-Type", "application/json")
+			// The listing is paged; the second page follows the last ID of the first.
+			if r.URL.Query().Get("after_id") == "" {
+				_, _ = w.Write([]byte(`{"data": [{"id": "claude-sonnet-4-5", "created_at": "2025-09-29T00:00:00Z"}], "has_more": true, "last_id": "claude-sonnet-4-5"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": [{"id": "claude-haiku-4-5", "created_at": "2025-10-15T00:00:00Z"}], "has_more": false, "last_id": "claude-haiku-4-5"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	provider := NewAnthropicProvider(server.URL, AnthropicConfig{APIKey: "sk-ant-test"})
+
+	t.Run("GenerateStream", func(t *testing.T) {
+		// ARRANGE
+		req := &GenerateRequest{
+			Model:    "claude-sonnet-4-5",
+			Messages: []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi"}},
+		}
+		ch := make(chan StreamResponse, 10)
+
+		// ACT
+		err := provider.GenerateStream(ctx, req, ch)
+
+		// ASSERT: The system prompt is a top-level field and a limit is always sent.
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/messages", capturedPath)
+		assert.Equal(t, "sk-ant-test", capturedHeader.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, capturedHeader.Get("anthropic-version"))
+		assert.JSONEq(t, `{
+			"model": "claude-sonnet-4-5",
+			"system": "Be brief.",
+			"messages": [{"role": "user", "content": "Hi"}],
+			"max_tokens": 4096,
+			"stream": true
+		}`, string(capturedBody))
+
+		var responses []StreamResponse
+		for resp := range ch {
+			responses = append(responses, resp)
+		}
+		require.Len(t, responses, 3)
+		assert.Equal(t, "Hel", responses[0].Content)
+		assert.Equal(t, "lo!", responses[1].Content)
+		final := responses[2]
+		assert.True(t, final.Done)
+		require.NotNil(t, final.Stats)
+		assert.Equal(t, 12, final.Stats.PromptEvalCount)
+		assert.Equal(t, 5, final.Stats.EvalCount)
+		assert.Equal(t, "stop", final.Stats.DoneReason)
+	})
+
+	t.Run("GenerateStream reports an error event", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("event: error\n" + `data: {"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}` + "\n\n"))
+		}))
+		defer failing.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewAnthropicProvider(failing.URL, AnthropicConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m", Prompt: "Hi"}, ch)
+
+		assert.ErrorContains(t, err, "Overloaded")
+	})
+
+	t.Run("Generate", func(t *testing.T) {
+		numPredict, topK := 100, 20
+		resp, err := provider.Generate(ctx, &GenerateRequest{Model: "claude-sonnet-4-5", Prompt: "Hi", Options: &RequestOptions{NumPredict: &numPredict, TopK: &topK}})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"model": "claude-sonnet-4-5",
+			"messages": [{"role": "user", "content": "Hi"}],
+			"max_tokens": 100,
+			"top_k": 20,
+			"stream": false
+		}`, string(capturedBody))
+		assert.Equal(t, "Hello!", resp.Response)
+		require.NotNil(t, resp.Stats)
+		assert.Equal(t, 5, resp.Stats.EvalCount)
+		assert.Equal(t, "length", resp.Stats.DoneReason)
+	})
+
+	t.Run("Images are sent as base64 blocks", func(t *testing.T) {
+		msg := anthropicMessageFrom(Message{Role: "user", Content: "What is this?", Images: []string{"iVBORw0KGgoAAAANSUhEUg=="}})
+
+		body, err := json.Marshal(msg)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role": "user", "content": [
+			{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgoAAAANSUhEUg=="}},
+			{"type": "text", "text": "What is this?"}
+		]}`, string(body))
+	})
+
+	t.Run("ListModels follows the pages", func(t *testing.T) {
+		resp, err := provider.ListModels(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, resp.Models, 2)
+		assert.Equal(t, "claude-sonnet-4-5", resp.Models[0].Name)
+		assert.Equal(t, "claude-haiku-4-5", resp.Models[1].Name)
+	})
+
+	t.Run("Embeddings are not supported", func(t *testing.T) {
+		_, err := provider.Embeddings(ctx, &EmbeddingsRequest{Model: "m", Input: []string{"a"}})
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+}
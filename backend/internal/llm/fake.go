@@ -0,0 +1,272 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeConfig configures the fake provider.
+type FakeConfig struct {
+	// TokensPerSecond is the speed at which answers are streamed; 0 streams
+	// them without delay.
+	TokensPerSecond float64
+}
+
+// fakeModels are the models the fake provider starts with.
+var fakeModels = []string{"fake-chat:latest", "fake-embed:latest"}
+
+// fakeAnswer is the text of every answer; it is cut to `num_predict` words.
+const fakeAnswer = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod " +
+	"tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis " +
+	"nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis " +
+	"aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat " +
+	"nulla pariatur."
+
+// fakeEmbeddingDimensions is the length of the fake provider's embedding vectors.
+const fakeEmbeddingDimensions = 8
+
+// fakeProvider serves canned answers without a model, so that the backend runs
+// without Ollama or a GPU, e.g. for frontend development or integration tests.
+// Any model name can be chatted with. The model list is kept in memory: pulls,
+// copies and creations succeed instantly and add a model.
+type fakeProvider struct {
+	cfg FakeConfig
+
+	mu     sync.Mutex
+	models map[string]time.Time // name -> modified at
+	blobs  map[string]bool
+}
+
+// NewFakeProvider creates a provider that answers with lorem ipsum.
+func NewFakeProvider(cfg FakeConfig) LLMProvider {
+	p := &fakeProvider{cfg: cfg, models: map[string]time.Time{}, blobs: map[string]bool{}}
+	now := time.Now().UTC()
+	for _, name := range fakeModels {
+		p.models[name] = now
+	}
+	return p
+}
+
+// answerWords returns the words of the answer to req.
+func answerWords(req *GenerateRequest) (words []string, doneReason string) {
+	words = strings.Fields(fakeAnswer)
+	if req.Options != nil && req.Options.NumPredict != nil && *req.Options.NumPredict >= 0 && *req.Options.NumPredict < len(words) {
+		return words[:*req.Options.NumPredict], "length"
+	}
+	return words, "stop"
+}
+
+// promptTokens approximates the prompt length of req by its number of words.
+func promptTokens(req *GenerateRequest) int {
+	n := len(strings.Fields(req.Prompt))
+	for _, m := range req.Messages {
+		n += len(strings.Fields(m.Content))
+	}
+	return n
+}
+
+func (p *fakeProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	words, doneReason := answerWords(req)
+	response := strings.Join(words, " ")
+	if req.Format == "json" {
+		response = fmt.Sprintf(`{"text": %q}`, response)
+	}
+	return &GenerateResponse{
+		Model:    req.Model,
+		Response: response,
+		Done:     true,
+		Stats:    &GenerationStats{PromptEvalCount: promptTokens(req), EvalCount: len(words), DoneReason: doneReason},
+	}, nil
+}
+
+// GenerateStream streams the answer word by word at the configured speed.
+func (p *fakeProvider) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
+	defer close(ch)
+	started := time.Now()
+	var delay time.Duration
+	if p.cfg.TokensPerSecond > 0 {
+		delay = time.Duration(float64(time.Second) / p.cfg.TokensPerSecond)
+	}
+
+	words, doneReason := answerWords(req)
+	for i, word := range words {
+		if i > 0 {
+			word = " " + word
+		}
+		if delay > 0 && !sleep(ctx, delay) {
+			return ctx.Err()
+		}
+		select {
+		case ch <- StreamResponse{Content: word}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	elapsed := time.Since(started).Nanoseconds()
+	final := StreamResponse{Done: true, Stats: &GenerationStats{
+		TotalDuration:   elapsed,
+		PromptEvalCount: promptTokens(req),
+		EvalCount:       len(words),
+		EvalDuration:    elapsed,
+		DoneReason:      doneReason,
+	}}
+	select {
+	case ch <- final:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *fakeProvider) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.models))
+	for name := range p.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &ListModelsResponse{Models: make([]Model, 0, len(names))}
+	for _, name := range names {
+		resp.Models = append(resp.Models, Model{
+			Name:       name,
+			ModifiedAt: p.models[name].Format(time.RFC3339Nano),
+			Digest:     fakeDigest(name),
+			Details:    ModelDetails{Format: "gguf", Family: "fake"},
+		})
+	}
+	return resp, nil
+}
+
+// fakeDigest derives a stable digest from a model name.
+func fakeDigest(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// addModel adds a model to the list, as a completed pull or creation would.
+func (p *fakeProvider) addModel(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models[name] = time.Now().UTC()
+}
+
+func (p *fakeProvider) hasModel(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.models[name]
+	return ok
+}
+
+// sendStatuses sends the statuses of an instant pull or creation to ch.
+func sendStatuses(ctx context.Context, ch chan<- PullStatus, statuses ...string) error {
+	for _, status := range statuses {
+		select {
+		case ch <- PullStatus{Status: status}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (p *fakeProvider) PullModel(ctx context.Context, req *PullModelRequest, ch chan<- PullStatus) error {
+	defer close(ch)
+	if err := sendStatuses(ctx, ch, "pulling manifest", "success"); err != nil {
+		return err
+	}
+	p.addModel(req.Name)
+	return nil
+}
+
+func (p *fakeProvider) DeleteModel(ctx context.Context, req *DeleteModelRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.models[req.Name]; !ok {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, req.Name)
+	}
+	delete(p.models, req.Name)
+	return nil
+}
+
+func (p *fakeProvider) ShowModelInfo(ctx context.Context, req *ShowModelRequest) (*ModelInfo, error) {
+	if !p.hasModel(req.Name) {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, req.Name)
+	}
+	capabilities := []string{"completion"}
+	if strings.Contains(req.Name, "embed") {
+		capabilities = []string{"embedding"}
+	}
+	return &ModelInfo{
+		Modelfile:    "FROM " + req.Name,
+		Details:      ModelDetails{Format: "gguf", Family: "fake"},
+		Capabilities: capabilities,
+	}, nil
+}
+
+// Embeddings returns a vector derived from the hash of each input, so that
+// equal texts get equal vectors.
+func (p *fakeProvider) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	resp := &EmbeddingsResponse{Model: req.Model, Embeddings: make([][]float32, len(req.Input))}
+	for i, input := range req.Input {
+		sum := sha256.Sum256([]byte(input))
+		vector := make([]float32, fakeEmbeddingDimensions)
+		for j := range vector {
+			vector[j] = float32(sum[j])/255*2 - 1
+		}
+		resp.Embeddings[i] = vector
+	}
+	return resp, nil
+}
+
+// RunningModels reports no loaded models, as the fake provider loads none.
+func (p *fakeProvider) RunningModels(ctx context.Context) (*RunningModelsResponse, error) {
+	return &RunningModelsResponse{Models: []RunningModel{}}, nil
+}
+
+func (p *fakeProvider) CopyModel(ctx context.Context, req *CopyModelRequest) error {
+	if !p.hasModel(req.Source) {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, req.Source)
+	}
+	p.addModel(req.Destination)
+	return nil
+}
+
+func (p *fakeProvider) CreateModel(ctx context.Context, req *CreateModelRequest, ch chan<- PullStatus) error {
+	defer close(ch)
+	if err := sendStatuses(ctx, ch, "creating model", "success"); err != nil {
+		return err
+	}
+	p.addModel(req.Name)
+	return nil
+}
+
+func (p *fakeProvider) Version(ctx context.Context) (string, error) {
+	return "0.0.0-fake", nil
+}
+
+func (p *fakeProvider) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.blobs[digest], nil
+}
+
+// PushBlob reads and discards the blob; only its digest is remembered.
+func (p *fakeProvider) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.blobs[digest] = true
+	return nil
+}
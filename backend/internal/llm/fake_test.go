@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFakeProvider verifies that the fake provider streams its canned answer
+// and keeps its model list in memory.
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GenerateStream streams the answer word by word", func(t *testing.T) {
+		// ARRANGE
+		numPredict := 3
+		req := &GenerateRequest{Model: "any", Messages: []Message{{Role: "user", Content: "Hi there"}}, Options: &RequestOptions{NumPredict: &numPredict}}
+		ch := make(chan StreamResponse, 10)
+
+		// ACT
+		err := NewFakeProvider(FakeConfig{}).GenerateStream(ctx, req, ch)
+
+		// ASSERT
+		require.NoError(t, err)
+		var content strings.Builder
+		var final StreamResponse
+		for resp := range ch {
+			content.WriteString(resp.Content)
+			final = resp
+		}
+		assert.Equal(t, "Lorem ipsum dolor", content.String())
+		assert.True(t, final.Done)
+		require.NotNil(t, final.Stats)
+		assert.Equal(t, 3, final.Stats.EvalCount)
+		assert.Equal(t, 2, final.Stats.PromptEvalCount)
+		assert.Equal(t, "length", final.Stats.DoneReason)
+	})
+
+	t.Run("GenerateStream is paced and stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		ch := make(chan StreamResponse, 100)
+
+		err := NewFakeProvider(FakeConfig{TokensPerSecond: 10}).GenerateStream(ctx, &GenerateRequest{Model: "any"}, ch)
+
+		// WHY: At 10 tokens per second, the answer cannot be complete after 50ms.
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		_, open := <-ch
+		assert.False(t, open, "the channel must be closed")
+	})
+
+	t.Run("Pulled models are listed and can be deleted", func(t *testing.T) {
+		provider := NewFakeProvider(FakeConfig{})
+		ch := make(chan PullStatus, 10)
+
+		require.NoError(t, provider.PullModel(ctx, &PullModelRequest{Name: "gemma3:270m"}, ch))
+
+		var statuses []string
+		for status := range ch {
+			statuses = append(statuses, status.Status)
+		}
+		assert.Equal(t, []string{"pulling manifest", "success"}, statuses)
+		list, err := provider.ListModels(ctx)
+		require.NoError(t, err)
+		var names []string
+		for _, m := range list.Models {
+			names = append(names, m.Name)
+		}
+		assert.Equal(t, []string{"fake-chat:latest", "fake-embed:latest", "gemma3:270m"}, names)
+
+		require.NoError(t, provider.DeleteModel(ctx, &DeleteModelRequest{Name: "gemma3:270m"}))
+		assert.ErrorIs(t, provider.DeleteModel(ctx, &DeleteModelRequest{Name: "gemma3:270m"}), ErrModelNotFound)
+	})
+
+	t.Run("Embeddings are deterministic", func(t *testing.T) {
+		provider := NewFakeProvider(FakeConfig{})
+
+		resp, err := provider.Embeddings(ctx, &EmbeddingsRequest{Model: "fake-embed:latest", Input: []string{"a", "b", "a"}})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Embeddings, 3)
+		assert.Len(t, resp.Embeddings[0], fakeEmbeddingDimensions)
+		assert.Equal(t, resp.Embeddings[0], resp.Embeddings[2])
+		assert.NotEqual(t, resp.Embeddings[0], resp.Embeddings[1])
+	})
+}
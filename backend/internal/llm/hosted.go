@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// ErrUnsupported is returned for operations the configured provider cannot
+// perform, such as pulling a model from a hosted API.
+var ErrUnsupported = fmt.Errorf("%w by the LLM provider", app_errors.ErrNotSupported)
+
+// unmanagedModels implements the model management methods of LLMProvider for
+// hosted APIs, which serve a fixed set of models and leave managing them to
+// their operator. Every method fails with ErrUnsupported.
+type unmanagedModels struct{}
+
+func (unmanagedModels) PullModel(ctx context.Context, req *PullModelRequest, ch chan<- PullStatus) error {
+	close(ch)
+	return fmt.Errorf("%w: pulling models", ErrUnsupported)
+}
+
+func (unmanagedModels) DeleteModel(ctx context.Context, req *DeleteModelRequest) error {
+	return fmt.Errorf("%w: deleting models", ErrUnsupported)
+}
+
+func (unmanagedModels) ShowModelInfo(ctx context.Context, req *ShowModelRequest) (*ModelInfo, error) {
+	return nil, fmt.Errorf("%w: showing model details", ErrUnsupported)
+}
+
+func (unmanagedModels) RunningModels(ctx context.Context) (*RunningModelsResponse, error) {
+	return nil, fmt.Errorf("%w: listing running models", ErrUnsupported)
+}
+
+func (unmanagedModels) CopyModel(ctx context.Context, req *CopyModelRequest) error {
+	return fmt.Errorf("%w: copying models", ErrUnsupported)
+}
+
+func (unmanagedModels) CreateModel(ctx context.Context, req *CreateModelRequest, ch chan<- PullStatus) error {
+	close(ch)
+	return fmt.Errorf("%w: creating models", ErrUnsupported)
+}
+
+func (unmanagedModels) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	return false, ErrUnsupported
+}
+
+func (unmanagedModels) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	return ErrUnsupported
+}
+
+func (unmanagedModels) Version(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("%w: reporting a version", ErrUnsupported)
+}
+
+// imageMediaType sniffs the MIME type of a base64-encoded image. Ollama takes
+// bare base64, while the hosted APIs need the type along with the data.
+func imageMediaType(image string) string {
+	head, _ := base64.StdEncoding.DecodeString(image[:min(len(image), 64)])
+	return http.DetectContentType(head)
+}
+
+// readSSE calls handle with the data of every server-sent event until handle
+// reports that the stream is done or fails. Event names, comments and
+// keep-alives are skipped; the hosted APIs repeat the event type in the data.
+func readSSE(ctx context.Context, r io.Reader, handle func(data string) (done bool, err error)) error {
+	scanner, scanErr := newStreamScanner(ctx, r)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		done, err := handle(strings.TrimSpace(data))
+		if err != nil || done {
+			return err
+		}
+	}
+	return scanErr()
+}
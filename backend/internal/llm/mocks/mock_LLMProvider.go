@@ -7,6 +7,7 @@ package mocks
 import (
 	"context"
 	"flow-ai/backend/internal/llm"
+	"io"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -38,6 +39,192 @@ func (_m *MockLLMProvider) EXPECT() *MockLLMProvider_Expecter {
 	return &MockLLMProvider_Expecter{mock: &_m.Mock}
 }
 
+// CheckBlob provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	ret := _mock.Called(ctx, digest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckBlob")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, digest)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, digest)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, digest)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLLMProvider_CheckBlob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckBlob'
+type MockLLMProvider_CheckBlob_Call struct {
+	*mock.Call
+}
+
+// CheckBlob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - digest string
+func (_e *MockLLMProvider_Expecter) CheckBlob(ctx interface{}, digest interface{}) *MockLLMProvider_CheckBlob_Call {
+	return &MockLLMProvider_CheckBlob_Call{Call: _e.mock.On("CheckBlob", ctx, digest)}
+}
+
+func (_c *MockLLMProvider_CheckBlob_Call) Run(run func(ctx context.Context, digest string)) *MockLLMProvider_CheckBlob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_CheckBlob_Call) Return(b bool, err error) *MockLLMProvider_CheckBlob_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockLLMProvider_CheckBlob_Call) RunAndReturn(run func(ctx context.Context, digest string) (bool, error)) *MockLLMProvider_CheckBlob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CopyModel provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) CopyModel(ctx context.Context, req *llm.CopyModelRequest) error {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyModel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.CopyModelRequest) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLLMProvider_CopyModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CopyModel'
+type MockLLMProvider_CopyModel_Call struct {
+	*mock.Call
+}
+
+// CopyModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.CopyModelRequest
+func (_e *MockLLMProvider_Expecter) CopyModel(ctx interface{}, req interface{}) *MockLLMProvider_CopyModel_Call {
+	return &MockLLMProvider_CopyModel_Call{Call: _e.mock.On("CopyModel", ctx, req)}
+}
+
+func (_c *MockLLMProvider_CopyModel_Call) Run(run func(ctx context.Context, req *llm.CopyModelRequest)) *MockLLMProvider_CopyModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.CopyModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.CopyModelRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_CopyModel_Call) Return(err error) *MockLLMProvider_CopyModel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLLMProvider_CopyModel_Call) RunAndReturn(run func(ctx context.Context, req *llm.CopyModelRequest) error) *MockLLMProvider_CopyModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateModel provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) CreateModel(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error {
+	ret := _mock.Called(ctx, req, ch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateModel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.CreateModelRequest, chan<- llm.PullStatus) error); ok {
+		r0 = returnFunc(ctx, req, ch)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLLMProvider_CreateModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateModel'
+type MockLLMProvider_CreateModel_Call struct {
+	*mock.Call
+}
+
+// CreateModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.CreateModelRequest
+//   - ch chan<- llm.PullStatus
+func (_e *MockLLMProvider_Expecter) CreateModel(ctx interface{}, req interface{}, ch interface{}) *MockLLMProvider_CreateModel_Call {
+	return &MockLLMProvider_CreateModel_Call{Call: _e.mock.On("CreateModel", ctx, req, ch)}
+}
+
+func (_c *MockLLMProvider_CreateModel_Call) Run(run func(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus)) *MockLLMProvider_CreateModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.CreateModelRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.CreateModelRequest)
+		}
+		var arg2 chan<- llm.PullStatus
+		if args[2] != nil {
+			arg2 = args[2].(chan<- llm.PullStatus)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_CreateModel_Call) Return(err error) *MockLLMProvider_CreateModel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLLMProvider_CreateModel_Call) RunAndReturn(run func(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error) *MockLLMProvider_CreateModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteModel provides a mock function for the type MockLLMProvider
 func (_mock *MockLLMProvider) DeleteModel(ctx context.Context, req *llm.DeleteModelRequest) error {
 	ret := _mock.Called(ctx, req)
@@ -95,6 +282,74 @@ func (_c *MockLLMProvider_DeleteModel_Call) RunAndReturn(run func(ctx context.Co
 	return _c
 }
 
+// Embeddings provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) Embeddings(ctx context.Context, req *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Embeddings")
+	}
+
+	var r0 *llm.EmbeddingsResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *llm.EmbeddingsRequest) *llm.EmbeddingsResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*llm.EmbeddingsResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *llm.EmbeddingsRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLLMProvider_Embeddings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Embeddings'
+type MockLLMProvider_Embeddings_Call struct {
+	*mock.Call
+}
+
+// Embeddings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *llm.EmbeddingsRequest
+func (_e *MockLLMProvider_Expecter) Embeddings(ctx interface{}, req interface{}) *MockLLMProvider_Embeddings_Call {
+	return &MockLLMProvider_Embeddings_Call{Call: _e.mock.On("Embeddings", ctx, req)}
+}
+
+func (_c *MockLLMProvider_Embeddings_Call) Run(run func(ctx context.Context, req *llm.EmbeddingsRequest)) *MockLLMProvider_Embeddings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *llm.EmbeddingsRequest
+		if args[1] != nil {
+			arg1 = args[1].(*llm.EmbeddingsRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_Embeddings_Call) Return(embeddingsResponse *llm.EmbeddingsResponse, err error) *MockLLMProvider_Embeddings_Call {
+	_c.Call.Return(embeddingsResponse, err)
+	return _c
+}
+
+func (_c *MockLLMProvider_Embeddings_Call) RunAndReturn(run func(ctx context.Context, req *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error)) *MockLLMProvider_Embeddings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Generate provides a mock function for the type MockLLMProvider
 func (_mock *MockLLMProvider) Generate(ctx context.Context, req *llm.GenerateRequest) (*llm.GenerateResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -351,6 +606,131 @@ func (_c *MockLLMProvider_PullModel_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// PushBlob provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	ret := _mock.Called(ctx, digest, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PushBlob")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, io.Reader) error); ok {
+		r0 = returnFunc(ctx, digest, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLLMProvider_PushBlob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PushBlob'
+type MockLLMProvider_PushBlob_Call struct {
+	*mock.Call
+}
+
+// PushBlob is a helper method to define mock.On call
+//   - ctx context.Context
+//   - digest string
+//   - r io.Reader
+func (_e *MockLLMProvider_Expecter) PushBlob(ctx interface{}, digest interface{}, r interface{}) *MockLLMProvider_PushBlob_Call {
+	return &MockLLMProvider_PushBlob_Call{Call: _e.mock.On("PushBlob", ctx, digest, r)}
+}
+
+func (_c *MockLLMProvider_PushBlob_Call) Run(run func(ctx context.Context, digest string, r io.Reader)) *MockLLMProvider_PushBlob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 io.Reader
+		if args[2] != nil {
+			arg2 = args[2].(io.Reader)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_PushBlob_Call) Return(err error) *MockLLMProvider_PushBlob_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLLMProvider_PushBlob_Call) RunAndReturn(run func(ctx context.Context, digest string, r io.Reader) error) *MockLLMProvider_PushBlob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RunningModels provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) RunningModels(ctx context.Context) (*llm.RunningModelsResponse, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunningModels")
+	}
+
+	var r0 *llm.RunningModelsResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*llm.RunningModelsResponse, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *llm.RunningModelsResponse); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*llm.RunningModelsResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLLMProvider_RunningModels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunningModels'
+type MockLLMProvider_RunningModels_Call struct {
+	*mock.Call
+}
+
+// RunningModels is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockLLMProvider_Expecter) RunningModels(ctx interface{}) *MockLLMProvider_RunningModels_Call {
+	return &MockLLMProvider_RunningModels_Call{Call: _e.mock.On("RunningModels", ctx)}
+}
+
+func (_c *MockLLMProvider_RunningModels_Call) Run(run func(ctx context.Context)) *MockLLMProvider_RunningModels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_RunningModels_Call) Return(runningModelsResponse *llm.RunningModelsResponse, err error) *MockLLMProvider_RunningModels_Call {
+	_c.Call.Return(runningModelsResponse, err)
+	return _c
+}
+
+func (_c *MockLLMProvider_RunningModels_Call) RunAndReturn(run func(ctx context.Context) (*llm.RunningModelsResponse, error)) *MockLLMProvider_RunningModels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ShowModelInfo provides a mock function for the type MockLLMProvider
 func (_mock *MockLLMProvider) ShowModelInfo(ctx context.Context, req *llm.ShowModelRequest) (*llm.ModelInfo, error) {
 	ret := _mock.Called(ctx, req)
@@ -418,3 +798,63 @@ func (_c *MockLLMProvider_ShowModelInfo_Call) RunAndReturn(run func(ctx context.
 	_c.Call.Return(run)
 	return _c
 }
+
+// Version provides a mock function for the type MockLLMProvider
+func (_mock *MockLLMProvider) Version(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Version")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLLMProvider_Version_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Version'
+type MockLLMProvider_Version_Call struct {
+	*mock.Call
+}
+
+// Version is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockLLMProvider_Expecter) Version(ctx interface{}) *MockLLMProvider_Version_Call {
+	return &MockLLMProvider_Version_Call{Call: _e.mock.On("Version", ctx)}
+}
+
+func (_c *MockLLMProvider_Version_Call) Run(run func(ctx context.Context)) *MockLLMProvider_Version_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLLMProvider_Version_Call) Return(s string, err error) *MockLLMProvider_Version_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockLLMProvider_Version_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *MockLLMProvider_Version_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -1,14 +1,22 @@
 package llm
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
 )
 
 // GenerationStats holds the statistics returned by Ollama after generation.
@@ -19,13 +27,18 @@ type GenerationStats struct {
 	PromptEvalDuration int64 `json:"prompt_eval_duration"`
 	EvalCount          int   `json:"eval_count"`
 	EvalDuration       int64 `json:"eval_duration"`
+	// DoneReason is why the generation ended: "stop" for a natural end or a stop
+	// sequence, "length" when the token limit was reached.
+	DoneReason string `json:"done_reason,omitempty"`
+	// TimeToFirstToken is the time in nanoseconds from sending the request to
+	// the first streamed token. It is measured by the chat service, not the provider.
+	TimeToFirstToken int64 `json:"time_to_first_token,omitempty"`
 }
 
 // StreamResponse is updated to include the final stats.
 type StreamResponse struct {
 	Content string
 	Done    bool
-	Context json.RawMessage
 	Error   string
 	Stats   *GenerationStats `json:"stats,omitempty"` // NEW FIELD
 }
@@ -38,49 +51,300 @@ type LLMProvider interface {
 	PullModel(ctx context.Context, req *PullModelRequest, ch chan<- PullStatus) error
 	DeleteModel(ctx context.Context, req *DeleteModelRequest) error
 	ShowModelInfo(ctx context.Context, req *ShowModelRequest) (*ModelInfo, error)
+	Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error)
+	RunningModels(ctx context.Context) (*RunningModelsResponse, error)
+	CopyModel(ctx context.Context, req *CopyModelRequest) error
+	CreateModel(ctx context.Context, req *CreateModelRequest, ch chan<- PullStatus) error
+	Version(ctx context.Context) (string, error)
+	CheckBlob(ctx context.Context, digest string) (bool, error)
+	PushBlob(ctx context.Context, digest string, r io.Reader) error
+}
+
+// ErrModelNotFound is returned when Ollama reports that a model does not exist.
+// It is an app_errors.ErrNotFound, so that the API layer answers with a 404.
+var ErrModelNotFound = fmt.Errorf("model %w", app_errors.ErrNotFound)
+
+// ErrInvalidBlob is returned when Ollama rejects an uploaded blob, e.g. because
+// its content does not match the digest.
+var ErrInvalidBlob = errors.New("invalid blob")
+
+// apiError turns a response with an error status into an error. Ollama answers
+// a request for a missing model with a 404 and a body like
+// {"error": "model 'x' not found"}, which becomes ErrModelNotFound. Anything else,
+// including a 404 without such a body, e.g. from a proxy, is an internal error
+// that keeps Ollama's message for the logs; the API layer does not expose it.
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrModelNotFound, apiErr.Error)
+		}
+		return fmt.Errorf("%w: ollama returned status %d: %s", app_errors.ErrInternal, resp.StatusCode, apiErr.Error)
+	}
+	return fmt.Errorf("%w: ollama returned status %d: %s", app_errors.ErrInternal, resp.StatusCode, strings.TrimSpace(string(body)))
 }
 
 type ollamaProvider struct {
 	client *http.Client
 	url    string
+	cfg    OllamaConfig
+	header http.Header
+}
+
+// OllamaConfig holds optional settings of the Ollama provider.
+type OllamaConfig struct {
+	// LogPayloads logs the full request sent to Ollama and the assembled response
+	// at DEBUG level. It is off by default because payloads contain user content.
+	LogPayloads bool
+	// PayloadLogMaxChars truncates logged payloads to this many characters; 0 disables truncation.
+	PayloadLogMaxChars int
+	// Headers are added to every request, e.g. the credentials of an auth proxy
+	// in front of Ollama.
+	Headers map[string]string
+	// APIKey is sent as a bearer token to an Ollama behind an authenticating
+	// proxy or a hosted Ollama API.
+	APIKey string
+	// BasicAuth is sent as HTTP basic credentials, given as "user:password".
+	BasicAuth string
+	// ConnectTimeout bounds establishing a connection to Ollama.
+	ConnectTimeout time.Duration
+	// ResponseHeaderTimeout bounds waiting for the response headers once the
+	// request is sent. Ollama only answers a chat once the model is loaded, so
+	// it must allow for loading the largest model.
+	ResponseHeaderTimeout time.Duration
+	// RequestTimeout bounds a whole request that is not streamed, including
+	// reading the response. Streamed responses are never cut off once data
+	// flows.
+	RequestTimeout time.Duration
+	// RetryAttempts is how often a request that is safe to repeat is sent before
+	// a connection error or a gateway error is returned; 0 and 1 do not retry.
+	RetryAttempts int
+	// RetryBackoff is the wait before the first retry, doubled for every further one.
+	RetryBackoff time.Duration
+	// TLS configures HTTPS connections, e.g. to trust a private CA; nil uses the
+	// system's roots. See TLSFiles.
+	TLS *tls.Config
+}
+
+// Header returns the headers sent with every request to Ollama. The
+// Authorization header built from APIKey or BasicAuth replaces one given in
+// Headers.
+func (c OllamaConfig) Header() http.Header {
+	header := http.Header{}
+	for key, value := range c.Headers {
+		header.Set(key, value)
+	}
+	switch {
+	case c.APIKey != "":
+		header.Set("Authorization", "Bearer "+c.APIKey)
+	case c.BasicAuth != "":
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.BasicAuth)))
+	}
+	return header
+}
+
+// Transport returns the transport of connections to Ollama with the configured
+// connect and response header timeouts and TLS settings. Everything that talks
+// to Ollama should use it, so that it connects the same way as the provider.
+func (c OllamaConfig) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: c.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	if c.TLS != nil {
+		transport.TLSClientConfig = c.TLS.Clone()
+	}
+	return transport
 }
 
-func NewOllamaProvider(url string) LLMProvider {
+func NewOllamaProvider(url string, cfg OllamaConfig) LLMProvider {
 	return &ollamaProvider{
-		client: &http.Client{},
+		// WHY: The client has no overall timeout, which would also cut off a
+		// streamed generation; RequestTimeout is applied per request instead.
+		client: &http.Client{Transport: cfg.Transport()},
 		url:    url,
+		cfg:    cfg,
+		header: cfg.Header(),
 	}
 }
 
+// callOptions describe how a request is sent to Ollama.
+type callOptions struct {
+	// stream is set for requests whose response is streamed or whose body is
+	// uploaded, which RequestTimeout must not cut off.
+	stream bool
+	// retry is set for requests that are safe to send again. A streamed request
+	// is only retried until its response starts.
+	retry bool
+}
+
+// do sends a request to Ollama with the configured headers, timeouts and
+// retries.
+func (p *ollamaProvider) do(req *http.Request, opts callOptions) (*http.Response, error) {
+	for key, values := range p.header {
+		req.Header[key] = values
+	}
+	attempts := 1
+	if opts.retry && p.cfg.RetryAttempts > 1 {
+		attempts = p.cfg.RetryAttempts
+	}
+
+	ctx := req.Context()
+	backoff := p.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := p.send(req, opts)
+		if attempt == attempts || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, unreachable(ctx, err)
+		}
+		if resp != nil {
+			if closeErr := resp.Body.Close(); closeErr != nil {
+				slog.Warn("Failed to close response body before a retry", "error", closeErr)
+			}
+		}
+		slog.Debug("Retrying Ollama request", "method", req.Method, "path", req.URL.Path, "attempt", attempt+1, "error", err)
+		if !sleep(ctx, backoff) {
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends a request once, bounding it by RequestTimeout unless it streams.
+func (p *ollamaProvider) send(req *http.Request, opts callOptions) (*http.Response, error) {
+	if opts.stream || p.cfg.RequestTimeout <= 0 {
+		return p.client.Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), p.cfg.RequestTimeout)
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout also covers reading the body, so it is only released once
+	// the caller closes it.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt may succeed when sent again: the
+// connection failed, or a proxy or a busy Ollama turned the request away.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewind returns a copy of a request with a fresh body, to send it again.
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("could not rewind the request body: %w", err)
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
 // --- Chat Structs ---
 
-// RequestOptions holds optional parameters for a generation request.
+// RequestOptions holds optional parameters for a generation request. Every field
+// is omitted when unset, so Ollama falls back to the model's defaults. The
+// validation tags reject values Ollama would reject or silently misinterpret.
 type RequestOptions struct {
-	Temperature   *float32 `json:"temperature,omitempty" example:"0.7"`
-	TopK          *int     `json:"top_k,omitempty" example:"40"`
-	TopP          *float32 `json:"top_p,omitempty" example:"0.9"`
+	Temperature   *float32 `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=2" example:"0.7"`
+	TopK          *int     `json:"top_k,omitempty" validate:"omitempty,gte=0" example:"40"`
+	TopP          *float32 `json:"top_p,omitempty" validate:"omitempty,gte=0,lte=1" example:"0.9"`
+	MinP          *float32 `json:"min_p,omitempty" validate:"omitempty,gte=0,lte=1" example:"0.05"`
 	System        *string  `json:"system,omitempty" example:"You are a senior database administrator."`
-	RepeatPenalty *float32 `json:"repeat_penalty,omitempty" example:"1.1"`
-	Seed          *int     `json:"seed,omitempty" example:"42"`
+	RepeatPenalty *float32 `json:"repeat_penalty,omitempty" validate:"omitempty,gte=0" example:"1.1"`
+	// RepeatLastN is how far back the model looks to prevent repetition; -1 means num_ctx.
+	RepeatLastN *int `json:"repeat_last_n,omitempty" validate:"omitempty,gte=-1" example:"64"`
+	Seed        *int `json:"seed,omitempty" example:"42"`
+	// Stop lists sequences at which the model stops generating.
+	Stop []string `json:"stop,omitempty" example:"###"`
+	// NumPredict caps the number of generated tokens; -1 means no limit and
+	// -2 fills the context.
+	NumPredict *int `json:"num_predict,omitempty" validate:"omitempty,gte=-2" example:"512"`
+	// NumCtx is the size of the context window in tokens. It is capped at 1M
+	// tokens, beyond what any current model supports, so that a typo cannot make
+	// Ollama try to allocate the memory for it.
+	NumCtx *int `json:"num_ctx,omitempty" validate:"omitempty,gte=1,lte=1048576" example:"4096"`
+	// Mirostat enables Mirostat sampling: 0 = off, 1 = Mirostat, 2 = Mirostat 2.0.
+	Mirostat    *int     `json:"mirostat,omitempty" validate:"omitempty,oneof=0 1 2" example:"0"`
+	MirostatEta *float32 `json:"mirostat_eta,omitempty" validate:"omitempty,gte=0" example:"0.1"`
+	MirostatTau *float32 `json:"mirostat_tau,omitempty" validate:"omitempty,gte=0" example:"5"`
+	// NumGPU is the number of layers offloaded to the GPU; -1 lets Ollama decide.
+	NumGPU *int `json:"num_gpu,omitempty" validate:"omitempty,gte=-1" example:"-1"`
+	// KeepAlive overrides the `keep_alive` setting for this request. It is sent
+	// as a top-level field of the Ollama request, not as a model option.
+	KeepAlive *KeepAlive `json:"keep_alive,omitempty" validate:"omitempty,keep_alive" example:"5m" swaggertype:"string"`
+	// Format constrains the output; "json" makes the model answer with valid JSON.
+	// Like KeepAlive, it is sent as a top-level field of the Ollama request.
+	Format *string `json:"format,omitempty" validate:"omitempty,oneof=json" example:"json"`
+}
+
+// KeepAlive controls how long Ollama keeps a model loaded after a request. It is
+// either a duration ("5m", "1h30m") or a number of seconds; "0" unloads the model
+// right away and a negative value keeps it loaded indefinitely.
+type KeepAlive string
+
+// Valid reports whether k is a duration or a whole number of seconds.
+func (k KeepAlive) Valid() bool {
+	if _, err := strconv.Atoi(string(k)); err == nil {
+		return true
+	}
+	_, err := time.ParseDuration(string(k))
+	return err == nil
+}
+
+// MarshalJSON encodes a number of seconds as a JSON number, because Ollama only
+// accepts units-less values (like -1) in numeric form.
+func (k KeepAlive) MarshalJSON() ([]byte, error) {
+	if n, err := strconv.Atoi(string(k)); err == nil {
+		return json.Marshal(n)
+	}
+	return json.Marshal(string(k))
 }
 
 type GenerateRequest struct {
-	Model    string          `json:"model"`
-	Prompt   string          `json:"prompt,omitempty"`
+	Model  string `json:"model"`
+	Prompt string `json:"prompt,omitempty"`
+	// Suffix is the text after the completion for fill-in-middle, e.g. the rest
+	// of a file in code completion. It requires a prompt and no messages.
+	Suffix   string          `json:"suffix,omitempty"`
 	Messages []Message       `json:"messages,omitempty"`
 	Stream   bool            `json:"stream"`
-	Context  json.RawMessage `json:"context,omitempty"`
 	Options  *RequestOptions `json:"options,omitempty"`
+	// KeepAlive is how long the model stays loaded after the request; nil uses Ollama's default.
+	KeepAlive *KeepAlive `json:"keep_alive,omitempty"`
+	// Format is "json" to force a JSON answer; empty leaves the output unconstrained.
+	Format string `json:"format,omitempty"`
 }
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// Images holds base64-encoded images for multimodal (vision) models.
+	Images []string `json:"images,omitempty"`
 }
 type GenerateResponse struct {
-	Model    string          `json:"model"`
-	Response string          `json:"response"`
-	Done     bool            `json:"done"`
-	Context  json.RawMessage `json:"context"`
+	Model    string `json:"model"`
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	// Stats are the timings and token counts the provider reports for the
+	// request; nil if it reports none.
+	Stats *GenerationStats `json:"-"`
 }
 
 // --- Model Management Structs ---
@@ -88,9 +352,50 @@ type ListModelsResponse struct {
 	Models []Model `json:"models"`
 }
 type Model struct {
-	Name       string `json:"name"`
-	ModifiedAt string `json:"modified_at"`
-	Size       int64  `json:"size"`
+	Name string `json:"name" example:"qwen3:8b"`
+	// Provider is the provider that serves the model, e.g. "ollama" or "openai".
+	// It is filled in by the provider registry.
+	Provider   string `json:"provider,omitempty" example:"ollama"`
+	ModifiedAt string `json:"modified_at" example:"2025-09-08T14:00:00.123456789+02:00"`
+	Size       int64  `json:"size" example:"5225388164"`
+	// Digest identifies the exact model version, e.g. to detect an updated tag.
+	Digest  string       `json:"digest" example:"500a1f067a9f782620b40bee6f7b0c89e17ae61f686b92c24933e4ca4b2b8b41"`
+	Details ModelDetails `json:"details"`
+	// Capabilities lists the features of the model, such as "completion",
+	// "vision", "tools" or "embedding". Ollama does not list them with the models;
+	// they are filled in by the model service.
+	Capabilities []string `json:"capabilities,omitempty" example:"completion,vision"`
+	// TokensPerSecond is the generation speed measured by the last benchmark of
+	// the model, if it was benchmarked. It is filled in by the model service.
+	TokensPerSecond *float64 `json:"tokens_per_second,omitempty" example:"42.5"`
+	// UpdateAvailable reports that the last update check found a newer version
+	// of the model in the registry.
+	UpdateAvailable bool `json:"update_available" example:"false"`
+}
+
+// ModelDetails describes the architecture and size of a model as reported by Ollama.
+type ModelDetails struct {
+	Format            string   `json:"format,omitempty" example:"gguf"`
+	Family            string   `json:"family,omitempty" example:"qwen3"`
+	Families          []string `json:"families,omitempty" example:"qwen3"`
+	ParameterSize     string   `json:"parameter_size,omitempty" example:"8.2B"`
+	QuantizationLevel string   `json:"quantization_level,omitempty" example:"Q4_K_M"`
+}
+
+// RunningModelsResponse lists the models currently loaded into memory.
+type RunningModelsResponse struct {
+	Models []RunningModel `json:"models"`
+}
+
+// RunningModel is a model loaded into memory. Size is its total memory use in
+// bytes, of which SizeVRAM is in GPU memory; a smaller SizeVRAM means the model
+// partly runs on the CPU.
+type RunningModel struct {
+	Name     string `json:"name" example:"qwen3:8b"`
+	Size     int64  `json:"size" example:"6654289920"`
+	SizeVRAM int64  `json:"size_vram" example:"6654289920"`
+	// ExpiresAt is when the model will be unloaded unless it is used again.
+	ExpiresAt time.Time `json:"expires_at" example:"2025-09-08T14:05:00Z"`
 }
 type PullModelRequest struct {
 	Name   string `json:"name" example:"mistral:7b"`
@@ -106,17 +411,81 @@ type PullStatus struct {
 type DeleteModelRequest struct {
 	Name string `json:"name" example:"mistral:7b"`
 }
+
+// CopyModelRequest copies a local model under a new name, e.g. to snapshot it
+// before experimenting with a custom Modelfile.
+type CopyModelRequest struct {
+	Source      string `json:"source" validate:"required" example:"qwen3:8b"`
+	Destination string `json:"destination" validate:"required,nefield=Source" example:"qwen3:8b-backup"`
+}
+
+// CreateModelRequest creates a custom model, either from a full Modelfile or,
+// with newer Ollama versions, from an existing model with its system prompt,
+// template and parameters overridden.
+type CreateModelRequest struct {
+	Name string `json:"name" validate:"required" example:"sql-expert"`
+	// Modelfile is the complete Modelfile of the new model.
+	Modelfile string `json:"modelfile,omitempty" validate:"required_without_all=From Files" example:"FROM qwen3:8b\nSYSTEM You are a senior database administrator."`
+	// From names the model the new model is based on.
+	From string `json:"from,omitempty" validate:"required_without_all=Modelfile Files" example:"qwen3:8b"`
+	// Files maps file names to the digests of uploaded blobs, e.g. to import a
+	// GGUF file as {"model.gguf": "sha256:..."}.
+	Files      map[string]string `json:"files,omitempty"`
+	System     string            `json:"system,omitempty" example:"You are a senior database administrator."`
+	Template   string            `json:"template,omitempty"`
+	Parameters map[string]any    `json:"parameters,omitempty"`
+	Quantize   string            `json:"quantize,omitempty" example:"q4_K_M"`
+	Stream     bool              `json:"stream"`
+}
 type ShowModelRequest struct {
 	Name string `json:"name" example:"qwen3:8b"`
 }
 type ModelInfo struct {
-	Modelfile  string `json:"modelfile"`
-	Parameters string `json:"parameters"`
-	Template   string `json:"template"`
+	Modelfile  string       `json:"modelfile"`
+	Parameters string       `json:"parameters"`
+	Template   string       `json:"template"`
+	Details    ModelDetails `json:"details"`
+	// Capabilities is only reported by newer versions of Ollama.
+	Capabilities []string `json:"capabilities,omitempty" example:"completion,vision"`
+}
+
+// --- Embedding Structs ---
+
+// EmbeddingsRequest asks for one embedding vector per input text.
+type EmbeddingsRequest struct {
+	Model string   `json:"model" example:"nomic-embed-text"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingsResponse holds the vectors in the same order as the request's input.
+type EmbeddingsResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float32 `json:"embeddings"`
 }
 
 // --- ollamaProvider methods ---
 
+// payloadLoggingEnabled reports whether payloads should be logged. Both the flag
+// and the DEBUG log level are required, so enabling the flag alone in production
+// does not leak conversation content into the logs.
+func (p *ollamaProvider) payloadLoggingEnabled(ctx context.Context) bool {
+	return p.cfg.LogPayloads && slog.Default().Enabled(ctx, slog.LevelDebug)
+}
+
+// logPayload logs an LLM payload at DEBUG level if payload logging is enabled,
+// truncating it to the configured length.
+func (p *ollamaProvider) logPayload(ctx context.Context, msg, method, payload string) {
+	if !p.payloadLoggingEnabled(ctx) {
+		return
+	}
+	if limit := p.cfg.PayloadLogMaxChars; limit > 0 {
+		if runes := []rune(payload); len(runes) > limit {
+			payload = fmt.Sprintf("%s... (%d more characters)", string(runes[:limit]), len(runes)-limit)
+		}
+	}
+	slog.DebugContext(ctx, msg, "method", method, "payload", payload)
+}
+
 func (p *ollamaProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
 	req.Stream = false
 	body, err := json.Marshal(req)
@@ -124,9 +493,12 @@ func (p *ollamaProvider) Generate(ctx context.Context, req *GenerateRequest) (*G
 		return nil, fmt.Errorf("could not marshal request: %w", err)
 	}
 
+	p.logPayload(ctx, "LLM request payload", "Generate", string(body))
+
 	endpoint := p.url + "/api/chat"
-	// Use /api/generate only if there's a single prompt and no messages.
-	if len(req.Messages) == 0 && req.Prompt != "" {
+	// Use /api/generate only if there's a single prompt and no messages; only
+	// it knows the suffix of a fill-in-middle request.
+	if len(req.Messages) == 0 && (req.Prompt != "" || req.Suffix != "") {
 		endpoint = p.url + "/api/generate"
 	}
 
@@ -135,7 +507,7 @@ func (p *ollamaProvider) Generate(ctx context.Context, req *GenerateRequest) (*G
 		return nil, fmt.Errorf("could not create http request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{retry: true})
 	if err != nil {
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
@@ -146,8 +518,7 @@ func (p *ollamaProvider) Generate(ctx context.Context, req *GenerateRequest) (*G
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, apiError(resp)
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -155,32 +526,40 @@ func (p *ollamaProvider) Generate(ctx context.Context, req *GenerateRequest) (*G
 		return nil, fmt.Errorf("could not read response body: %w", err)
 	}
 
-	// --- ROBUST RESPONSE PARSING ---
-
-	// Attempt to parse as a chat response first
-	type ollamaChatResponse struct {
-		Message Message `json:"message"`
-		// Other fields we don't need here...
+	var decoded ollamaResponse
+	if err := json.Unmarshal(bodyBytes, &decoded); err != nil {
+		return nil, fmt.Errorf("could not decode response from Ollama: %w: %s", err, logLine(bodyBytes))
 	}
-	var chatResp ollamaChatResponse
-	if err := json.Unmarshal(bodyBytes, &chatResp); err == nil && chatResp.Message.Content != "" {
-		return &GenerateResponse{
-			Response: chatResp.Message.Content,
-		}, nil
-	}
-
-	// If chat parsing fails, attempt to parse as a generate response
-	type ollamaGenerateResponse struct {
-		Response string `json:"response"`
+	answer := decoded.answer()
+	p.logPayload(ctx, "LLM response payload", "Generate", answer)
+	result := &GenerateResponse{
+		Model:    decoded.Model,
+		Response: answer,
+		Done:     decoded.Done,
 	}
-	var genResp ollamaGenerateResponse
-	if err := json.Unmarshal(bodyBytes, &genResp); err == nil {
-		return &GenerateResponse{
-			Response: genResp.Response,
-		}, nil
+	if decoded.GenerationStats != (GenerationStats{}) {
+		result.Stats = &decoded.GenerationStats
 	}
+	return result, nil
+}
+
+// ollamaResponse is a complete answer of /api/chat, which has the text in
+// message, or of /api/generate, which has it in response. Both endpoints report
+// the same statistics next to it.
+type ollamaResponse struct {
+	Model    string  `json:"model"`
+	Message  Message `json:"message"`
+	Response string  `json:"response"`
+	Done     bool    `json:"done"`
+	GenerationStats
+}
 
-	return nil, fmt.Errorf("could not decode response from Ollama: %s", string(bodyBytes))
+// answer returns the text of the answer, wherever the endpoint put it.
+func (r *ollamaResponse) answer() string {
+	if r.Message.Content != "" {
+		return r.Message.Content
+	}
+	return r.Response
 }
 
 func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
@@ -191,12 +570,14 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 		return fmt.Errorf("could not marshal request: %w", err)
 	}
 
+	p.logPayload(ctx, "LLM request payload", "GenerateStream", string(body))
+
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/chat", bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("could not create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{stream: true, retry: true})
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -207,8 +588,7 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+		return apiError(resp)
 	}
 
 	// This struct helps decode both streaming content and the final stats block.
@@ -216,16 +596,25 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 		Message            struct{ Content string } `json:"message"`
 		Model              string                   `json:"model"`
 		Done               bool                     `json:"done"`
-		Context            json.RawMessage          `json:"context"`
 		TotalDuration      int64                    `json:"total_duration"`
 		LoadDuration       int64                    `json:"load_duration"`
 		PromptEvalCount    int                      `json:"prompt_eval_count"`
 		PromptEvalDuration int64                    `json:"prompt_eval_duration"`
 		EvalCount          int                      `json:"eval_count"`
 		EvalDuration       int64                    `json:"eval_duration"`
+		DoneReason         string                   `json:"done_reason"`
+		// Error is set when Ollama fails after the stream started, e.g. because
+		// it ran out of memory; nothing follows it.
+		Error string `json:"error"`
+	}
+
+	// The full response is only assembled when it is going to be logged.
+	var assembled *strings.Builder
+	if p.payloadLoggingEnabled(ctx) {
+		assembled = &strings.Builder{}
 	}
 
-	scanner := bufio.NewScanner(resp.Body)
+	scanner, scanErr := newStreamScanner(ctx, resp.Body)
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		if len(line) == 0 {
@@ -233,9 +622,25 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 		}
 		var chunk ollamaStreamChunk
 		if err := json.Unmarshal(line, &chunk); err != nil {
-			slog.Warn("Failed to unmarshal stream chunk from Ollama", "error", err, "line", string(line))
-			ch <- StreamResponse{Error: "Failed to decode stream chunk"}
-			continue
+			// WHY: The rest of the stream cannot be trusted either, so it ends
+			// with a single error instead of one for every following line.
+			slog.Warn("Failed to unmarshal stream chunk from Ollama", "error", err, "line", logLine(line))
+			select {
+			case ch <- StreamResponse{Error: "Failed to decode stream chunk", Done: true}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		if chunk.Error != "" {
+			slog.Warn("Ollama reported an error mid-stream", "model", req.Model, "error", chunk.Error)
+			select {
+			case ch <- StreamResponse{Error: chunk.Error, Done: true}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
 		}
 
 		streamResp := StreamResponse{
@@ -243,9 +648,15 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 			Done:    chunk.Done,
 		}
 
+		if assembled != nil {
+			assembled.WriteString(chunk.Message.Content)
+		}
+
 		// If the stream is done, capture all the stats.
 		if chunk.Done {
-			streamResp.Context = chunk.Context
+			if assembled != nil {
+				p.logPayload(ctx, "LLM response payload", "GenerateStream", assembled.String())
+			}
 			streamResp.Stats = &GenerationStats{
 				TotalDuration:      chunk.TotalDuration,
 				LoadDuration:       chunk.LoadDuration,
@@ -253,6 +664,7 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 				PromptEvalDuration: chunk.PromptEvalDuration,
 				EvalCount:          chunk.EvalCount,
 				EvalDuration:       chunk.EvalDuration,
+				DoneReason:         chunk.DoneReason,
 			}
 		}
 
@@ -262,7 +674,7 @@ func (p *ollamaProvider) GenerateStream(ctx context.Context, req *GenerateReques
 			return ctx.Err()
 		}
 	}
-	return scanner.Err()
+	return scanErr()
 }
 
 func (p *ollamaProvider) ListModels(ctx context.Context) (*ListModelsResponse, error) {
@@ -270,7 +682,7 @@ func (p *ollamaProvider) ListModels(ctx context.Context) (*ListModelsResponse, e
 	if err != nil {
 		return nil, fmt.Errorf("could not create request: %w", err)
 	}
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{retry: true})
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -287,6 +699,37 @@ func (p *ollamaProvider) ListModels(ctx context.Context) (*ListModelsResponse, e
 	return &listResp, nil
 }
 
+// RunningModels returns the models Ollama currently holds in memory.
+func (p *ollamaProvider) RunningModels(ctx context.Context) (*RunningModelsResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.url+"/api/ps", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := p.do(httpReq, callOptions{retry: true})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in RunningModels", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var psResp RunningModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&psResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	if psResp.Models == nil {
+		psResp.Models = []RunningModel{}
+	}
+	return &psResp, nil
+}
+
 func (p *ollamaProvider) PullModel(ctx context.Context, req *PullModelRequest, ch chan<- PullStatus) error {
 	defer close(ch)
 	req.Stream = true
@@ -301,7 +744,7 @@ func (p *ollamaProvider) PullModel(ctx context.Context, req *PullModelRequest, c
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{stream: true, retry: true})
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -311,13 +754,93 @@ func (p *ollamaProvider) PullModel(ctx context.Context, req *PullModelRequest, c
 		}
 	}()
 
-	scanner := bufio.NewScanner(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("api returned non-200 status: %s", resp.Status)
+	}
+
+	scanner, scanErr := newStreamScanner(ctx, resp.Body)
 	for scanner.Scan() {
 		var status PullStatus
 		if err := json.Unmarshal(scanner.Bytes(), &status); err != nil {
-			slog.Warn("Failed to unmarshal pull status chunk from Ollama", "error", err, "line", string(scanner.Bytes()))
-			ch <- PullStatus{Error: "Failed to decode stream chunk"}
-			continue
+			slog.Warn("Failed to unmarshal pull status chunk from Ollama", "error", err, "line", logLine(scanner.Bytes()))
+			status = PullStatus{Error: "Failed to decode stream chunk"}
+		}
+		select {
+		case ch <- status:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// Ollama reports a failed pull, e.g. of an unknown model, as an `error`
+		// chunk and then ends the stream. A chunk that cannot be decoded ends
+		// it as well.
+		if status.Error != "" {
+			return errors.New(status.Error)
+		}
+	}
+	return scanErr()
+}
+
+// CreateModel creates a model and streams the progress to `ch`, which is closed
+// when the method returns. Any error, e.g. from an invalid Modelfile, is also
+// sent to `ch` as a status with `Error` set, so that it reaches the client.
+func (p *ollamaProvider) CreateModel(ctx context.Context, req *CreateModelRequest, ch chan<- PullStatus) (err error) {
+	defer close(ch)
+	defer func() {
+		if err != nil && ctx.Err() == nil {
+			select {
+			case ch <- PullStatus{Error: err.Error()}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	req.Stream = true
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("could not marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/create", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.do(httpReq, callOptions{stream: true})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in CreateModel", "error", err)
+		}
+	}()
+
+	// Ollama rejects an invalid Modelfile before streaming, with the reason in
+	// a JSON `error` field.
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("api returned non-200 status: %s", resp.Status)
+	}
+
+	scanner, scanErr := newStreamScanner(ctx, resp.Body)
+	for scanner.Scan() {
+		var status PullStatus
+		if err := json.Unmarshal(scanner.Bytes(), &status); err != nil {
+			slog.Warn("Failed to unmarshal create status chunk from Ollama", "error", err, "line", logLine(scanner.Bytes()))
+			// The deferred function sends the error as the last status.
+			return errors.New("failed to decode stream chunk")
 		}
 		select {
 		case ch <- status:
@@ -325,7 +848,7 @@ func (p *ollamaProvider) PullModel(ctx context.Context, req *PullModelRequest, c
 			return ctx.Err()
 		}
 	}
-	return scanner.Err()
+	return scanErr()
 }
 
 func (p *ollamaProvider) DeleteModel(ctx context.Context, req *DeleteModelRequest) error {
@@ -340,7 +863,7 @@ func (p *ollamaProvider) DeleteModel(ctx context.Context, req *DeleteModelReques
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{})
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -356,6 +879,42 @@ func (p *ollamaProvider) DeleteModel(ctx context.Context, req *DeleteModelReques
 	return nil
 }
 
+// CopyModel copies a local model. It returns ErrModelNotFound if the source
+// model does not exist.
+func (p *ollamaProvider) CopyModel(ctx context.Context, req *CopyModelRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("could not marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/copy", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.do(httpReq, callOptions{})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in CopyModel", "error", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrModelNotFound, req.Source)
+	default:
+		return fmt.Errorf("api returned non-200 status: %s", resp.Status)
+	}
+}
+
+// ShowModelInfo returns the Modelfile, details and capabilities of a model. It
+// returns ErrModelNotFound if the model does not exist.
 func (p *ollamaProvider) ShowModelInfo(ctx context.Context, req *ShowModelRequest) (*ModelInfo, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -367,7 +926,7 @@ func (p *ollamaProvider) ShowModelInfo(ctx context.Context, req *ShowModelReques
 		return nil, fmt.Errorf("could not create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.do(httpReq, callOptions{retry: true})
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -376,6 +935,9 @@ func (p *ollamaProvider) ShowModelInfo(ctx context.Context, req *ShowModelReques
 			slog.Error("Failed to close response body in ShowModelInfo", "error", err)
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
 
 	var info ModelInfo
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
@@ -383,3 +945,132 @@ func (p *ollamaProvider) ShowModelInfo(ctx context.Context, req *ShowModelReques
 	}
 	return &info, nil
 }
+
+// Version returns the version of the Ollama server, e.g. "0.6.2".
+func (p *ollamaProvider) Version(ctx context.Context) (string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/api/version", nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := p.do(httpReq, callOptions{retry: true})
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in Version", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var versionResp struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&versionResp); err != nil {
+		return "", fmt.Errorf("could not decode response: %w", err)
+	}
+	return versionResp.Version, nil
+}
+
+// CheckBlob reports whether Ollama has a blob, such as an uploaded GGUF file.
+func (p *ollamaProvider) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodHead, p.url+"/api/blobs/"+digest, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := p.do(httpReq, callOptions{retry: true})
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in CheckBlob", "error", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("api returned non-200 status: %s", resp.Status)
+	}
+}
+
+// PushBlob uploads a file as a blob, so that a model can be created from it. It
+// returns ErrInvalidBlob if the content does not match the digest.
+func (p *ollamaProvider) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/api/blobs/"+digest, r)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := p.do(httpReq, callOptions{stream: true})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in PushBlob", "error", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusBadRequest:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%w: %s", ErrInvalidBlob, apiErr.Error)
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+}
+
+// Embeddings computes embedding vectors for a batch of texts via Ollama's `/api/embed`.
+// It returns ErrModelNotFound if the model does not exist.
+func (p *ollamaProvider) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/embed", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.do(httpReq, callOptions{retry: true})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in Embeddings", "error", err)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, req.Model)
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var embResp EmbeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	if len(embResp.Embeddings) != len(req.Input) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(req.Input), len(embResp.Embeddings))
+	}
+	return &embResp, nil
+}
@@ -1,13 +1,22 @@
 package llm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	app_errors "flow-ai/backend/internal/errors"
 )
 
 // TestOllamaProvider is a unit test for our Ollama HTTP client implementation.
@@ -23,6 +32,7 @@ import (
 func TestOllamaProvider(t *testing.T) {
 	// These variables will capture the details of the HTTP request received by our mock server.
 	var capturedMethod, capturedPath string
+	var capturedBody []byte
 
 	// Create a new mock HTTP server.
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,18 +40,74 @@ func TestOllamaProvider(t *testing.T) {
 		// We capture the method and path of the incoming request for later assertions.
 		capturedMethod = r.Method
 		capturedPath = r.URL.Path
+		capturedBody, _ = io.ReadAll(r.Body)
 
 		// A simple router to handle different Ollama API endpoints.
 		switch r.URL.Path {
 		case "/api/delete":
 			// For a DELETE request, Ollama returns a 200 OK with no body on success.
 			w.WriteHeader(http.StatusOK)
+		case "/api/copy":
+			// Ollama answers 404 if the source model does not exist.
+			var req CopyModelRequest
+			_ = json.Unmarshal(capturedBody, &req)
+			if req.Source == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/api/create":
+			// Ollama validates the Modelfile before it starts streaming.
+			if strings.Contains(string(capturedBody), "INVALID") {
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"error": "command must be one of \"from\", \"license\", \"template\""}`))
+				assert.NoError(t, err)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"status": "using existing layer"}` + "\n" + `{"status": "success"}` + "\n"))
+			assert.NoError(t, err)
 		case "/api/show":
 			// For a "show" request, it returns a JSON object.
+			if strings.Contains(string(capturedBody), "missing") {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": "model 'missing' not found"}`))
+				return
+			}
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			_, err := w.Write([]byte(`{"modelfile": "FROM scratch"}`))
+			_, err := w.Write([]byte(`{"modelfile": "FROM scratch", "details": {"family": "gemma3"}, "capabilities": ["completion", "vision"]}`))
 			assert.NoError(t, err) // It's good practice to check errors even in test helpers.
+		case "/api/version":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"version": "0.6.2"}`))
+			assert.NoError(t, err)
+		case "/api/embed":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"model": "embed", "embeddings": [[0.1, 0.2], [0.3, 0.4]]}`))
+			assert.NoError(t, err)
+		case "/api/tags":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"models": [{"name": "qwen3:8b", "model": "qwen3:8b", "modified_at": "2025-09-08T14:00:00+02:00", "size": 5225388164, "digest": "500a1f06", "details": {"parent_model": "", "format": "gguf", "family": "qwen3", "families": ["qwen3"], "parameter_size": "8.2B", "quantization_level": "Q4_K_M"}}]}`))
+			assert.NoError(t, err)
+		case "/api/ps":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"models": [{"name": "qwen3:8b", "model": "qwen3:8b", "size": 6654289920, "size_vram": 5000000000, "digest": "abc", "expires_at": "2025-09-08T14:05:00.5+02:00"}]}`))
+			assert.NoError(t, err)
+		case "/api/generate":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"model": "m", "response": "ok", "done": true, "load_duration": 5000000, "eval_count": 12, "eval_duration": 300000000}`))
+			assert.NoError(t, err)
+		case "/api/chat":
+			// A streaming chat response consists of newline-delimited JSON chunks.
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"message": {"content": "A cat."}, "done": false}` + "\n" + `{"done": true}` + "\n"))
+			assert.NoError(t, err)
 		default:
 			// If our client tries to access an unknown endpoint, we return a 404.
 			w.WriteHeader(http.StatusNotFound)
@@ -52,7 +118,7 @@ func TestOllamaProvider(t *testing.T) {
 
 	// ARRANGE: Create an instance of our ollamaProvider, pointing it to the URL
 	// of our mock server instead of a real Ollama instance.
-	provider := NewOllamaProvider(server.URL)
+	provider := NewOllamaProvider(server.URL, OllamaConfig{})
 	ctx := context.Background()
 
 	t.Run("DeleteModel", func(t *testing.T) {
@@ -67,6 +133,54 @@ func TestOllamaProvider(t *testing.T) {
 		assert.Equal(t, "/api/delete", capturedPath)
 	})
 
+	t.Run("CopyModel", func(t *testing.T) {
+		// ACT
+		err := provider.CopyModel(ctx, &CopyModelRequest{Source: "qwen3:8b", Destination: "qwen3:8b-backup"})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, capturedMethod)
+		assert.Equal(t, "/api/copy", capturedPath)
+		assert.JSONEq(t, `{"source": "qwen3:8b", "destination": "qwen3:8b-backup"}`, string(capturedBody))
+	})
+
+	t.Run("CopyModel reports a missing source", func(t *testing.T) {
+		err := provider.CopyModel(ctx, &CopyModelRequest{Source: "missing", Destination: "copy"})
+
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("CreateModel streams progress", func(t *testing.T) {
+		// ARRANGE
+		ch := make(chan PullStatus, 4)
+
+		// ACT
+		err := provider.CreateModel(ctx, &CreateModelRequest{Name: "sql-expert", From: "qwen3:8b", System: "You are a DBA."}, ch)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "/api/create", capturedPath)
+		assert.JSONEq(t, `{"name": "sql-expert", "from": "qwen3:8b", "system": "You are a DBA.", "stream": true}`, string(capturedBody))
+		var statuses []string
+		for status := range ch {
+			statuses = append(statuses, status.Status)
+		}
+		assert.Equal(t, []string{"using existing layer", "success"}, statuses)
+	})
+
+	t.Run("CreateModel forwards Ollama's error to the stream", func(t *testing.T) {
+		ch := make(chan PullStatus, 4)
+
+		err := provider.CreateModel(ctx, &CreateModelRequest{Name: "broken", Modelfile: "INVALID qwen3:8b"}, ch)
+
+		require.Error(t, err)
+		status, ok := <-ch
+		require.True(t, ok, "the error must be sent before the channel is closed")
+		assert.Contains(t, status.Error, "command must be one of")
+		_, ok = <-ch
+		assert.False(t, ok)
+	})
+
 	t.Run("ShowModelInfo", func(t *testing.T) {
 		// ACT
 		info, err := provider.ShowModelInfo(ctx, &ShowModelRequest{Name: "test-model"})
@@ -77,8 +191,955 @@ func TestOllamaProvider(t *testing.T) {
 		require.NotNil(t, info)
 		// 2. Verify that the JSON response from the server was correctly parsed into the struct.
 		assert.Equal(t, "FROM scratch", info.Modelfile)
+		assert.Equal(t, "gemma3", info.Details.Family)
+		assert.Equal(t, []string{"completion", "vision"}, info.Capabilities)
 		// 3. Verify that the correct HTTP method and path were used.
 		assert.Equal(t, http.MethodPost, capturedMethod)
 		assert.Equal(t, "/api/show", capturedPath)
 	})
+
+	t.Run("ShowModelInfo reports a missing model", func(t *testing.T) {
+		_, err := provider.ShowModelInfo(ctx, &ShowModelRequest{Name: "missing"})
+
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("GenerateStream sends the whole history to the chat endpoint", func(t *testing.T) {
+		// ARRANGE: A follow-up turn.
+		req := &GenerateRequest{
+			Model: "m",
+			Messages: []Message{
+				{Role: "user", Content: "My name is Ada."},
+				{Role: "assistant", Content: "Hello, Ada!"},
+				{Role: "user", Content: "What is my name?"},
+			},
+		}
+		ch := make(chan StreamResponse, 4)
+
+		// ACT
+		err := provider.GenerateStream(ctx, req, ch)
+
+		// ASSERT: /api/chat keeps no state between requests, so the earlier turns
+		// must be in the request itself; there is no context to resume from.
+		require.NoError(t, err)
+		assert.Equal(t, "/api/chat", capturedPath)
+		assert.JSONEq(t, `{
+			"model": "m",
+			"messages": [
+				{"role": "user", "content": "My name is Ada."},
+				{"role": "assistant", "content": "Hello, Ada!"},
+				{"role": "user", "content": "What is my name?"}
+			],
+			"stream": true
+		}`, string(capturedBody))
+	})
+
+	t.Run("GenerateStream passes images through", func(t *testing.T) {
+		// ARRANGE
+		req := &GenerateRequest{
+			Model:    "llava",
+			Messages: []Message{{Role: "user", Content: "What is this?", Images: []string{"aGVsbG8="}}},
+		}
+		ch := make(chan StreamResponse, 4)
+
+		// ACT
+		err := provider.GenerateStream(ctx, req, ch)
+
+		// ASSERT: The images must be forwarded in Ollama's `images` field.
+		require.NoError(t, err)
+		var sent struct {
+			Messages []struct {
+				Images []string `json:"images"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		require.Len(t, sent.Messages, 1)
+		assert.Equal(t, []string{"aGVsbG8="}, sent.Messages[0].Images)
+		assert.Equal(t, "A cat.", (<-ch).Content)
+	})
+
+	t.Run("GenerateStream forwards stop sequences under options", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			options  *RequestOptions
+			expected []string
+		}{
+			{name: "Set", options: &RequestOptions{Stop: []string{"###", "END"}}, expected: []string{"###", "END"}},
+			{name: "Nil is omitted", options: &RequestOptions{}, expected: nil},
+			{name: "Empty is omitted", options: &RequestOptions{Stop: []string{}}, expected: nil},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ACT
+				ch := make(chan StreamResponse, 4)
+				err := provider.GenerateStream(ctx, &GenerateRequest{Model: "m", Options: tc.options}, ch)
+
+				// ASSERT: Inspect the raw JSON to make sure the key itself is absent when unset.
+				require.NoError(t, err)
+				var sent struct {
+					Options map[string]json.RawMessage `json:"options"`
+				}
+				require.NoError(t, json.Unmarshal(capturedBody, &sent))
+				raw, ok := sent.Options["stop"]
+				if tc.expected == nil {
+					assert.False(t, ok, "stop must be omitted from options")
+					return
+				}
+				require.True(t, ok)
+				var stop []string
+				require.NoError(t, json.Unmarshal(raw, &stop))
+				assert.Equal(t, tc.expected, stop)
+			})
+		}
+	})
+
+	t.Run("Options are passed through unchanged", func(t *testing.T) {
+		f32 := func(v float32) *float32 { return &v }
+		i := func(v int) *int { return &v }
+		options := &RequestOptions{
+			Temperature: f32(0.5),
+			Stop:        []string{"END"},
+			NumPredict:  i(128),
+			NumCtx:      i(8192),
+			MinP:        f32(0.25),
+			RepeatLastN: i(-1),
+			Mirostat:    i(2),
+			MirostatEta: f32(0.125),
+			MirostatTau: f32(4),
+			NumGPU:      i(0),
+		}
+		// WHY: Exact JSON comparison, so that unset options must be absent
+		// rather than sent as zero values. A zero num_gpu is explicitly set and kept.
+		expected := `{
+			"temperature": 0.5, "stop": ["END"], "num_predict": 128, "num_ctx": 8192,
+			"min_p": 0.25, "repeat_last_n": -1, "mirostat": 2, "mirostat_eta": 0.125,
+			"mirostat_tau": 4, "num_gpu": 0
+		}`
+		var sent struct {
+			Options json.RawMessage `json:"options"`
+		}
+
+		resp, err := provider.Generate(ctx, &GenerateRequest{Model: "m", Prompt: "hi", Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, &GenerationStats{LoadDuration: 5000000, EvalCount: 12, EvalDuration: 300000000}, resp.Stats)
+		assert.Equal(t, "/api/generate", capturedPath)
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		assert.JSONEq(t, expected, string(sent.Options))
+
+		ch := make(chan StreamResponse, 4)
+		require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m", Options: options}, ch))
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		assert.JSONEq(t, expected, string(sent.Options))
+	})
+
+	t.Run("Options are omitted when unset", func(t *testing.T) {
+		ch := make(chan StreamResponse, 4)
+		require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch))
+
+		// WHY: Without `options` Ollama uses the model's own defaults, e.g. its num_ctx.
+		var sent map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		assert.NotContains(t, sent, "options")
+	})
+
+	t.Run("GenerateStream sends format at the top level", func(t *testing.T) {
+		ch := make(chan StreamResponse, 4)
+		require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m", Format: "json"}, ch))
+
+		var sent map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		assert.JSONEq(t, `"json"`, string(sent["format"]))
+		assert.NotContains(t, sent, "options")
+
+		// Without a format the field is omitted, so the output is unconstrained.
+		require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m"}, make(chan StreamResponse, 4)))
+		sent = nil
+		require.NoError(t, json.Unmarshal(capturedBody, &sent))
+		assert.NotContains(t, sent, "format")
+	})
+
+	t.Run("GenerateStream sends keep_alive at the top level", func(t *testing.T) {
+		testCases := []struct {
+			keepAlive KeepAlive
+			expected  string
+		}{
+			{keepAlive: "5m", expected: `"5m"`},
+			{keepAlive: "0", expected: `0`},
+			// Ollama rejects "-1" as a string, so whole seconds are sent as a number.
+			{keepAlive: "-1", expected: `-1`},
+		}
+
+		for _, tc := range testCases {
+			t.Run(string(tc.keepAlive), func(t *testing.T) {
+				keepAlive := tc.keepAlive
+				ch := make(chan StreamResponse, 4)
+				require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m", KeepAlive: &keepAlive}, ch))
+
+				var sent map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(capturedBody, &sent))
+				assert.JSONEq(t, tc.expected, string(sent["keep_alive"]))
+			})
+		}
+	})
+
+	t.Run("ListModels", func(t *testing.T) {
+		// ACT
+		resp, err := provider.ListModels(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, capturedMethod)
+		assert.Equal(t, "/api/tags", capturedPath)
+		require.Len(t, resp.Models, 1)
+		assert.Equal(t, Model{
+			Name:       "qwen3:8b",
+			ModifiedAt: "2025-09-08T14:00:00+02:00",
+			Size:       5225388164,
+			Digest:     "500a1f06",
+			Details: ModelDetails{
+				Format:            "gguf",
+				Family:            "qwen3",
+				Families:          []string{"qwen3"},
+				ParameterSize:     "8.2B",
+				QuantizationLevel: "Q4_K_M",
+			},
+		}, resp.Models[0])
+	})
+
+	t.Run("RunningModels", func(t *testing.T) {
+		// ACT
+		resp, err := provider.RunningModels(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, capturedMethod)
+		assert.Equal(t, "/api/ps", capturedPath)
+		require.Len(t, resp.Models, 1)
+		m := resp.Models[0]
+		assert.Equal(t, "qwen3:8b", m.Name)
+		assert.Equal(t, int64(6654289920), m.Size)
+		assert.Equal(t, int64(5000000000), m.SizeVRAM)
+		assert.True(t, m.ExpiresAt.Equal(time.Date(2025, 9, 8, 12, 5, 0, 500000000, time.UTC)))
+	})
+
+	t.Run("RunningModels reports Ollama errors", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error": "boom"}`))
+		}))
+		defer failing.Close()
+
+		_, err := NewOllamaProvider(failing.URL, OllamaConfig{}).RunningModels(ctx)
+		assert.ErrorContains(t, err, "500")
+	})
+
+	t.Run("Embeddings", func(t *testing.T) {
+		// ACT
+		resp, err := provider.Embeddings(ctx, &EmbeddingsRequest{Model: "embed", Input: []string{"a", "b"}})
+
+		// ASSERT: The batch is sent in a single request and the vectors are decoded in order.
+		require.NoError(t, err)
+		assert.Equal(t, "/api/embed", capturedPath)
+		assert.JSONEq(t, `{"model": "embed", "input": ["a", "b"]}`, string(capturedBody))
+		assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, resp.Embeddings)
+	})
+
+	t.Run("Embeddings rejects a mismatched vector count", func(t *testing.T) {
+		_, err := provider.Embeddings(ctx, &EmbeddingsRequest{Model: "embed", Input: []string{"only one"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("Embeddings reports an unknown model", func(t *testing.T) {
+		missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "model \"embed\" not found, try pulling it first"}`))
+		}))
+		defer missing.Close()
+
+		_, err := NewOllamaProvider(missing.URL, OllamaConfig{}).Embeddings(ctx, &EmbeddingsRequest{Model: "embed", Input: []string{"a"}})
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("Embeddings reports Ollama errors", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "\"gemma3\" does not support embeddings"}`))
+		}))
+		defer failing.Close()
+
+		_, err := NewOllamaProvider(failing.URL, OllamaConfig{}).Embeddings(ctx, &EmbeddingsRequest{Model: "gemma3", Input: []string{"a"}})
+		assert.ErrorContains(t, err, "does not support embeddings")
+		assert.NotErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		version, err := provider.Version(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, "/api/version", capturedPath)
+		assert.Equal(t, "0.6.2", version)
+	})
+
+	t.Run("Version reports an unreachable Ollama", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		_, err := NewOllamaProvider(unreachable.URL, OllamaConfig{}).Version(ctx)
+		assert.Error(t, err)
+	})
+}
+
+// TestOllamaProvider_PayloadLogging verifies that full payloads are logged only
+// when the flag is set and the log level is DEBUG.
+//
+// WHY: Payloads contain entire conversations, so logging them by accident would
+// leak user content into the application logs.
+func TestOllamaProvider_PayloadLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"message":{"content":"Hello "},"done":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"message":{"content":"there"},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	// captureLogs runs a streaming generation with the given provider config and log level.
+	captureLogs := func(t *testing.T, cfg OllamaConfig, level slog.Level) string {
+		var buf bytes.Buffer
+		previous := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level})))
+		t.Cleanup(func() { slog.SetDefault(previous) })
+
+		provider := NewOllamaProvider(server.URL, cfg)
+		ch := make(chan StreamResponse, 10)
+		req := &GenerateRequest{Model: "m", Messages: []Message{{Role: "user", Content: "secret question"}}}
+		require.NoError(t, provider.GenerateStream(context.Background(), req, ch))
+		return buf.String()
+	}
+
+	t.Run("Logged when enabled at DEBUG", func(t *testing.T) {
+		logs := captureLogs(t, OllamaConfig{LogPayloads: true}, slog.LevelDebug)
+		assert.Contains(t, logs, "LLM request payload")
+		assert.Contains(t, logs, "secret question")
+		assert.Contains(t, logs, "Hello there")
+	})
+
+	t.Run("Not logged when the flag is off", func(t *testing.T) {
+		logs := captureLogs(t, OllamaConfig{}, slog.LevelDebug)
+		assert.NotContains(t, logs, "secret question")
+	})
+
+	t.Run("Not logged above DEBUG level", func(t *testing.T) {
+		logs := captureLogs(t, OllamaConfig{LogPayloads: true}, slog.LevelInfo)
+		assert.NotContains(t, logs, "secret question")
+	})
+
+	t.Run("Long payloads are truncated", func(t *testing.T) {
+		logs := captureLogs(t, OllamaConfig{LogPayloads: true, PayloadLogMaxChars: 5}, slog.LevelDebug)
+		assert.NotContains(t, logs, "secret question")
+		assert.Contains(t, logs, "more characters")
+	})
+}
+
+func TestKeepAlive_Valid(t *testing.T) {
+	for _, valid := range []KeepAlive{"5m", "1h30m", "0", "-1", "300"} {
+		assert.True(t, valid.Valid(), valid)
+	}
+	for _, invalid := range []KeepAlive{"", "five minutes", "5x", "1.5"} {
+		assert.False(t, invalid.Valid(), invalid)
+	}
+}
+
+// TestOllamaProvider_Headers verifies that the configured headers, e.g. the
+// credentials of an auth proxy, are sent with every request to Ollama.
+func TestOllamaProvider_Headers(t *testing.T) {
+	// ARRANGE: The server records the header of each endpoint and answers with
+	// a minimal valid response.
+	var mu sync.Mutex
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models": []}`))
+		case "/api/pull":
+			_, _ = w.Write([]byte(`{"status": "success"}` + "\n"))
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"modelfile": ""}`))
+		case "/api/generate", "/api/chat":
+			_, _ = w.Write([]byte(`{"response": "ok", "done": true}` + "\n"))
+		}
+	}))
+	defer server.Close()
+	provider := NewOllamaProvider(server.URL, OllamaConfig{Headers: map[string]string{"Authorization": "Bearer secret"}})
+	ctx := context.Background()
+
+	// ACT: Call every method that talks to Ollama.
+	_, err := provider.Generate(ctx, &GenerateRequest{Model: "m", Prompt: "hi"})
+	require.NoError(t, err)
+	require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}, make(chan StreamResponse, 10)))
+	_, err = provider.ListModels(ctx)
+	require.NoError(t, err)
+	require.NoError(t, provider.PullModel(ctx, &PullModelRequest{Name: "m"}, make(chan PullStatus, 10)))
+	require.NoError(t, provider.DeleteModel(ctx, &DeleteModelRequest{Name: "m"}))
+	_, err = provider.ShowModelInfo(ctx, &ShowModelRequest{Name: "m"})
+	require.NoError(t, err)
+
+	// ASSERT
+	for _, path := range []string{"/api/generate", "/api/chat", "/api/tags", "/api/pull", "/api/delete", "/api/show"} {
+		assert.Equal(t, "Bearer secret", received[path], path)
+	}
+}
+
+// TestOllamaProvider_ErrorStatus verifies that a missing model is told apart
+// from other errors reported by Ollama.
+// TestOllamaProvider_GenerateStats verifies that a complete answer is read with
+// its statistics from both /api/chat and /api/generate.
+func TestOllamaProvider_GenerateStats(t *testing.T) {
+	ctx := context.Background()
+	stats := `"done": true, "done_reason": "stop", "total_duration": 900, "load_duration": 100, "prompt_eval_count": 12, "prompt_eval_duration": 200, "eval_count": 34, "eval_duration": 500`
+	testCases := []struct {
+		name     string
+		path     string
+		request  *GenerateRequest
+		response string
+	}{
+		{"Chat endpoint", "/api/chat", &GenerateRequest{Model: "qwen3", Messages: []Message{{Role: "user", Content: "Hi"}}},
+			`{"model": "qwen3", "message": {"role": "assistant", "content": "Hello!"}, ` + stats + `}`},
+		{"Generate endpoint", "/api/generate", &GenerateRequest{Model: "qwen3", Prompt: "Hi"},
+			`{"model": "qwen3", "response": "Hello!", ` + stats + `}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.path, r.URL.Path)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+			provider := NewOllamaProvider(server.URL, OllamaConfig{})
+
+			// ACT
+			resp, err := provider.Generate(ctx, tc.request)
+
+			// ASSERT
+			require.NoError(t, err)
+			assert.Equal(t, "Hello!", resp.Response)
+			assert.Equal(t, "qwen3", resp.Model)
+			assert.True(t, resp.Done)
+			require.NotNil(t, resp.Stats)
+			assert.Equal(t, GenerationStats{
+				TotalDuration:      900,
+				LoadDuration:       100,
+				PromptEvalCount:    12,
+				PromptEvalDuration: 200,
+				EvalCount:          34,
+				EvalDuration:       500,
+				DoneReason:         "stop",
+			}, *resp.Stats)
+		})
+	}
+}
+
+// TestOllamaProvider_GenerateWithoutStats verifies that an answer without
+// statistics has no Stats instead of zero ones.
+func TestOllamaProvider_GenerateWithoutStats(t *testing.T) {
+	// ARRANGE
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"model": "qwen3", "response": "Hello!", "done": true}`))
+	}))
+	defer server.Close()
+	provider := NewOllamaProvider(server.URL, OllamaConfig{})
+
+	// ACT
+	resp, err := provider.Generate(context.Background(), &GenerateRequest{Model: "qwen3", Prompt: "Hi"})
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", resp.Response)
+	assert.Nil(t, resp.Stats)
+}
+
+// TestOllamaProvider_GenerateSuffix verifies that a fill-in-middle request is
+// sent to /api/generate with its suffix.
+func TestOllamaProvider_GenerateSuffix(t *testing.T) {
+	// ARRANGE
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"model": "qwen2.5-coder", "response": "    if n < 2:\n        return n\n", "done": true}`))
+	}))
+	defer server.Close()
+	provider := NewOllamaProvider(server.URL, OllamaConfig{})
+
+	// ACT
+	resp, err := provider.Generate(context.Background(), &GenerateRequest{
+		Model:  "qwen2.5-coder",
+		Prompt: "def fib(n):\n",
+		Suffix: "    return fib(n - 1) + fib(n - 2)\n",
+	})
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "/api/generate", path)
+	assert.Equal(t, "def fib(n):\n", body["prompt"])
+	assert.Equal(t, "    return fib(n - 1) + fib(n - 2)\n", body["suffix"])
+	assert.NotContains(t, body, "messages")
+	assert.Equal(t, "    if n < 2:\n        return n\n", resp.Response)
+}
+
+func TestOllamaProvider_ErrorStatus(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name        string
+		status      int
+		body        string
+		expectedErr error
+		message     string
+	}{
+		{"Missing model", http.StatusNotFound, `{"error": "model 'qwen9' not found, try pulling it first"}`, app_errors.ErrNotFound, "model 'qwen9' not found"},
+		{"Server error", http.StatusInternalServerError, `{"error": "llama runner process has terminated"}`, app_errors.ErrInternal, "llama runner process has terminated"},
+		// WHY: A 404 without Ollama's error body comes from something else, e.g. a
+		// proxy with a wrong path, and must not claim that the model is missing.
+		{"Not found without an error body", http.StatusNotFound, "404 page not found", app_errors.ErrInternal, "404 page not found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+			provider := NewOllamaProvider(server.URL, OllamaConfig{})
+
+			// ACT
+			_, generateErr := provider.Generate(ctx, &GenerateRequest{Model: "qwen9", Prompt: "Hi"})
+			streamErr := provider.GenerateStream(ctx, &GenerateRequest{Model: "qwen9"}, make(chan StreamResponse, 1))
+			_, showErr := provider.ShowModelInfo(ctx, &ShowModelRequest{Name: "qwen9"})
+
+			// ASSERT: Ollama's message is kept for the logs.
+			for _, err := range []error{generateErr, streamErr, showErr} {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorContains(t, err, tc.message)
+			}
+			if tc.expectedErr == app_errors.ErrNotFound {
+				assert.ErrorIs(t, generateErr, ErrModelNotFound)
+			}
+		})
+	}
+}
+
+// TestOllamaProvider_PullModelErrors verifies that a failed pull is returned as
+// an error, whether Ollama rejects the request or reports it in the stream.
+func TestOllamaProvider_PullModelErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid model name"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "pulling manifest"}` + "\n" + `{"error": "pull model manifest: file does not exist"}` + "\n"))
+	}))
+	defer server.Close()
+	provider := NewOllamaProvider(server.URL, OllamaConfig{})
+	ctx := context.Background()
+
+	t.Run("Rejected request", func(t *testing.T) {
+		err := provider.PullModel(ctx, &PullModelRequest{Name: "invalid"}, make(chan PullStatus, 10))
+
+		assert.EqualError(t, err, "invalid model name")
+	})
+
+	t.Run("Error in the stream", func(t *testing.T) {
+		ch := make(chan PullStatus, 10)
+
+		err := provider.PullModel(ctx, &PullModelRequest{Name: "missing"}, ch)
+
+		assert.EqualError(t, err, "pull model manifest: file does not exist")
+		var statuses []PullStatus
+		for status := range ch {
+			statuses = append(statuses, status)
+		}
+		assert.Equal(t, []PullStatus{{Status: "pulling manifest"}, {Error: "pull model manifest: file does not exist"}}, statuses)
+	})
+}
+
+// TestOllamaProvider_Unreachable verifies that a refused connection and a
+// connection that drops mid-stream are reported as ErrUnreachable, and that the
+// line cut off by the drop is not reported as a chunk that failed to decode.
+func TestOllamaProvider_Unreachable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Connection refused", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m"}, make(chan StreamResponse, 10))
+
+		assert.ErrorIs(t, err, ErrUnreachable)
+	})
+
+	t.Run("Connection dropped mid-stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"message": {"content": "Hel"}, "done": false}` + "\n" + `{"message": {"cont`))
+			w.(http.Flusher).Flush()
+			// WHY: Closing the hijacked connection ends the chunked body without
+			// its terminating chunk, as when Ollama crashes.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+		}))
+		defer server.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch)
+
+		assert.ErrorIs(t, err, ErrUnreachable)
+		var responses []StreamResponse
+		for resp := range ch {
+			responses = append(responses, resp)
+		}
+		assert.Equal(t, []StreamResponse{{Content: "Hel"}}, responses)
+	})
+}
+
+// TestOllamaProvider_Blobs verifies the existence check and upload of blobs,
+// which models are created from when importing GGUF files.
+func TestOllamaProvider_Blobs(t *testing.T) {
+	const present = "sha256:432f310a77f4650a88d0fd59ecdd7cebed8d684bafea53cbff0473542964f0c3"
+	const absent = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		digest := strings.TrimPrefix(r.URL.Path, "/api/blobs/")
+		switch {
+		case r.Method == http.MethodHead && digest == present:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && digest == present:
+			uploaded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "digest mismatch, expected \"` + digest + `\""}`))
+		}
+	}))
+	defer server.Close()
+	provider := NewOllamaProvider(server.URL, OllamaConfig{})
+	ctx := context.Background()
+
+	t.Run("Present blob", func(t *testing.T) {
+		exists, err := provider.CheckBlob(ctx, present)
+
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("Absent blob", func(t *testing.T) {
+		exists, err := provider.CheckBlob(ctx, absent)
+
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		err := provider.PushBlob(ctx, present, strings.NewReader("GGUF"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("GGUF"), uploaded)
+	})
+
+	t.Run("Upload with a wrong digest", func(t *testing.T) {
+		err := provider.PushBlob(ctx, absent, strings.NewReader("GGUF"))
+
+		assert.ErrorIs(t, err, ErrInvalidBlob)
+		assert.ErrorContains(t, err, "digest mismatch")
+	})
+}
+
+// TestOllamaProvider_Auth verifies that the configured credentials reach Ollama
+// on streamed and non-streamed requests alike.
+func TestOllamaProvider_Auth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      OllamaConfig
+		expected string
+	}{
+		{name: "API key", cfg: OllamaConfig{APIKey: "secret"}, expected: "Bearer secret"},
+		// "dXNlcjpwYXNz" is "user:pass" encoded in base64.
+		{name: "Basic auth", cfg: OllamaConfig{BasicAuth: "user:pass"}, expected: "Basic dXNlcjpwYXNz"},
+		{
+			name:     "API key replaces an Authorization header",
+			cfg:      OllamaConfig{APIKey: "secret", Headers: map[string]string{"Authorization": "Bearer other", "X-Team": "flow"}},
+			expected: "Bearer secret",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE
+			var mu sync.Mutex
+			received := map[string][]string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				received[r.URL.Path] = r.Header.Values("Authorization")
+				mu.Unlock()
+				switch r.URL.Path {
+				case "/api/pull":
+					_, _ = w.Write([]byte(`{"status": "success"}` + "\n"))
+				case "/api/chat":
+					_, _ = w.Write([]byte(`{"message": {"role": "assistant", "content": "ok"}, "done": true}` + "\n"))
+				}
+			}))
+			defer server.Close()
+			provider := NewOllamaProvider(server.URL, tc.cfg)
+			ctx := context.Background()
+
+			// ACT
+			require.NoError(t, provider.GenerateStream(ctx, &GenerateRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}, make(chan StreamResponse, 10)))
+			require.NoError(t, provider.PullModel(ctx, &PullModelRequest{Name: "m"}, make(chan PullStatus, 10)))
+			require.NoError(t, provider.DeleteModel(ctx, &DeleteModelRequest{Name: "m"}))
+
+			// ASSERT: Exactly one Authorization header is sent.
+			for _, path := range []string{"/api/chat", "/api/pull", "/api/delete"} {
+				assert.Equal(t, []string{tc.expected}, received[path], path)
+			}
+		})
+	}
+}
+
+// flakyServer fails the first `failures` requests, alternating between a reset
+// connection and a 503, and then serves the handler.
+func flakyServer(t *testing.T, failures int, handler http.HandlerFunc) (*httptest.Server, *int) {
+	t.Helper()
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+		switch {
+		case attempt > failures:
+			handler(w, r)
+		case attempt%2 == 1:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+		default:
+			// WHY: http.Transport itself resends a request whose reused
+			// connection is reset, which would skew the count of attempts.
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+// TestOllamaProvider_Retries verifies that requests that are safe to repeat are
+// retried on transient failures, and that others are not.
+func TestOllamaProvider_Retries(t *testing.T) {
+	ctx := context.Background()
+	cfg := OllamaConfig{RetryAttempts: 3, RetryBackoff: time.Millisecond}
+	tags := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models": [{"name": "m"}]}`))
+	}
+
+	t.Run("Retries an idempotent call until it succeeds", func(t *testing.T) {
+		server, attempts := flakyServer(t, 2, tags)
+
+		resp, err := NewOllamaProvider(server.URL, cfg).ListModels(ctx)
+
+		require.NoError(t, err)
+		assert.Len(t, resp.Models, 1)
+		assert.Equal(t, 3, *attempts)
+	})
+
+	t.Run("Gives up after the configured attempts", func(t *testing.T) {
+		server, attempts := flakyServer(t, 3, tags)
+
+		// WHY: The last failure is a reset connection, which is reported as such.
+		_, err := NewOllamaProvider(server.URL, OllamaConfig{RetryAttempts: 3}).ListModels(ctx)
+
+		assert.ErrorIs(t, err, ErrUnreachable)
+		assert.Equal(t, 3, *attempts)
+	})
+
+	t.Run("Retries establishing a stream and resends the body", func(t *testing.T) {
+		var received GenerateRequest
+		server, attempts := flakyServer(t, 2, func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			_, _ = w.Write([]byte(`{"message": {"content": "Hi"}, "done": true}` + "\n"))
+		})
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOllamaProvider(server.URL, cfg).GenerateStream(ctx, &GenerateRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}, ch)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, *attempts)
+		assert.Equal(t, "hi", received.Messages[0].Content)
+	})
+
+	t.Run("Does not retry a call that is not idempotent", func(t *testing.T) {
+		server, attempts := flakyServer(t, 2, func(w http.ResponseWriter, r *http.Request) {})
+
+		err := NewOllamaProvider(server.URL, cfg).DeleteModel(ctx, &DeleteModelRequest{Name: "m"})
+
+		assert.ErrorIs(t, err, ErrUnreachable)
+		assert.Equal(t, 1, *attempts)
+	})
+
+	t.Run("Stops retrying when the caller cancels", func(t *testing.T) {
+		server, attempts := flakyServer(t, 3, tags)
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := NewOllamaProvider(server.URL, OllamaConfig{RetryAttempts: 3, RetryBackoff: time.Hour}).ListModels(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, *attempts)
+	})
+}
+
+// TestOllamaProvider_Timeouts verifies that a hung Ollama fails a request that
+// is not streamed, while a slow stream is not cut off once data flows.
+func TestOllamaProvider_Timeouts(t *testing.T) {
+	ctx := context.Background()
+	cfg := OllamaConfig{RequestTimeout: 50 * time.Millisecond}
+
+	t.Run("A hung request times out", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		_, err := NewOllamaProvider(server.URL, cfg).ListModels(ctx)
+
+		assert.ErrorIs(t, err, ErrUnreachable)
+	})
+
+	t.Run("A slow stream is not cut off", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"message": {"content": "Hel"}, "done": false}` + "\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(4 * cfg.RequestTimeout)
+			_, _ = w.Write([]byte(`{"message": {"content": "lo"}, "done": true}` + "\n"))
+		}))
+		defer server.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOllamaProvider(server.URL, cfg).GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch)
+
+		require.NoError(t, err)
+		var content string
+		for resp := range ch {
+			content += resp.Content
+		}
+		assert.Equal(t, "Hello", content)
+	})
+}
+
+// TestOllamaProvider_StreamLines verifies that lines far longer than the default
+// scanner buffer are read, and that an undecodable line or an error reported by
+// Ollama ends the stream.
+func TestOllamaProvider_StreamLines(t *testing.T) {
+	ctx := context.Background()
+	collect := func(ch <-chan StreamResponse) []StreamResponse {
+		var responses []StreamResponse
+		for resp := range ch {
+			responses = append(responses, resp)
+		}
+		return responses
+	}
+
+	t.Run("A chunk over 1 MB", func(t *testing.T) {
+		// ARRANGE: A chunk far over the scanner's default limit of 64 KiB.
+		long := strings.Repeat("a", 2<<20)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"message": {"content": "` + long + `"}, "done": false}` + "\n"))
+			_, _ = w.Write([]byte(`{"message": {"content": ""}, "done": true, "eval_count": 1}` + "\n"))
+		}))
+		defer server.Close()
+		ch := make(chan StreamResponse, 10)
+
+		// ACT
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch)
+
+		// ASSERT
+		require.NoError(t, err)
+		responses := collect(ch)
+		require.Len(t, responses, 2)
+		assert.Equal(t, long, responses[0].Content)
+		final := responses[1]
+		assert.Empty(t, final.Error)
+		assert.True(t, final.Done)
+		require.NotNil(t, final.Stats)
+		assert.Equal(t, 1, final.Stats.EvalCount)
+	})
+
+	t.Run("An undecodable chunk ends the stream with a single error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"message": {"content": "Hi"}, "done": false}` + "\n" +
+				"not json\n" +
+				`{"message": {"content": " there"}, "done": false}` + "\n" +
+				"not json either\n" +
+				`{"message": {"content": ""}, "done": true}` + "\n"))
+		}))
+		defer server.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch)
+
+		require.NoError(t, err)
+		assert.Equal(t, []StreamResponse{
+			{Content: "Hi"},
+			{Error: "Failed to decode stream chunk", Done: true},
+		}, collect(ch))
+	})
+
+	t.Run("An error after content ends the stream with the error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"message": {"content": "Hel"}, "done": false}` + "\n" +
+				`{"error": "model runner has unexpectedly stopped"}` + "\n" +
+				`{"message": {"content": "lo"}, "done": false}` + "\n"))
+		}))
+		defer server.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m"}, ch)
+
+		require.NoError(t, err)
+		assert.Equal(t, []StreamResponse{
+			{Content: "Hel"},
+			{Error: "model runner has unexpectedly stopped", Done: true},
+		}, collect(ch))
+	})
+
+	t.Run("A pull status over 64 KB", func(t *testing.T) {
+		digest := "sha256:" + strings.Repeat("a", 100_000)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status": "pulling", "digest": "` + digest + `"}` + "\n" + `{"status": "success"}` + "\n"))
+		}))
+		defer server.Close()
+		ch := make(chan PullStatus, 10)
+
+		err := NewOllamaProvider(server.URL, OllamaConfig{}).PullModel(ctx, &PullModelRequest{Name: "m"}, ch)
+
+		require.NoError(t, err)
+		var statuses []PullStatus
+		for status := range ch {
+			statuses = append(statuses, status)
+		}
+		require.Len(t, statuses, 2)
+		assert.Equal(t, digest, statuses[0].Digest)
+	})
 }
@@ -0,0 +1,388 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIConfig holds the settings of the OpenAI-compatible provider.
+type OpenAIConfig struct {
+	// APIKey is sent as a bearer token; empty sends none, e.g. for a local
+	// llama.cpp server.
+	APIKey string
+	// Headers are added to every request, e.g. the attribution headers of OpenRouter.
+	Headers map[string]string
+}
+
+// openaiProvider talks to an API that implements OpenAI's chat completions, such
+// as OpenAI itself, OpenRouter or llama.cpp's server. Such APIs only generate and
+// list models; managing models is left to the service behind them.
+type openaiProvider struct {
+	unmanagedModels
+	client *http.Client
+	url    string
+	cfg    OpenAIConfig
+}
+
+// NewOpenAIProvider creates a provider for the API at baseURL, which includes the
+// version, e.g. "https://api.openai.com/v1".
+func NewOpenAIProvider(baseURL string, cfg OpenAIConfig) LLMProvider {
+	return &openaiProvider{
+		client: &http.Client{},
+		url:    strings.TrimSuffix(baseURL, "/"),
+		cfg:    cfg,
+	}
+}
+
+// --- OpenAI Structs ---
+
+type openaiChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openaiMessage       `json:"messages"`
+	Stream         bool                  `json:"stream"`
+	StreamOptions  *openaiStreamOptions  `json:"stream_options,omitempty"`
+	Temperature    *float32              `json:"temperature,omitempty"`
+	TopP           *float32              `json:"top_p,omitempty"`
+	Seed           *int                  `json:"seed,omitempty"`
+	Stop           []string              `json:"stop,omitempty"`
+	MaxTokens      *int                  `json:"max_tokens,omitempty"`
+	ResponseFormat *openaiResponseFormat `json:"response_format,omitempty"`
+}
+
+type openaiStreamOptions struct {
+	// IncludeUsage asks for a final chunk with the token counts.
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openaiResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// openaiMessage holds either a plain string or, for messages with images, a
+// list of content parts.
+type openaiMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type openaiContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openaiImageURL `json:"image_url,omitempty"`
+}
+
+type openaiImageURL struct {
+	URL string `json:"url"`
+}
+
+type openaiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type openaiError struct {
+	Message string `json:"message"`
+}
+
+type openaiChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		// FinishReason is "stop" or "length", like Ollama's done reasons.
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openaiUsage `json:"usage"`
+	// Error is set by some servers when a stream fails half-way.
+	Error *openaiError `json:"error"`
+}
+
+// --- openaiProvider methods ---
+
+// do sends a request with the API key and the configured headers.
+func (p *openaiProvider) do(req *http.Request) (*http.Response, error) {
+	if p.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	}
+	for key, value := range p.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := p.client.Do(req)
+	return resp, unreachable(req.Context(), err)
+}
+
+// post sends a JSON request and returns the response if its status is 200.
+func (p *openaiProvider) post(ctx context.Context, path string, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrModelNotFound, string(bodyBytes))
+		}
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return resp, nil
+}
+
+// chatRequest converts a request to a chat completion. Options without an
+// equivalent in the OpenAI API, such as top_k or num_ctx, are dropped.
+func chatRequest(req *GenerateRequest, stream bool) *openaiChatRequest {
+	chatReq := &openaiChatRequest{Model: req.Model, Stream: stream}
+	if stream {
+		chatReq.StreamOptions = &openaiStreamOptions{IncludeUsage: true}
+	}
+	for _, m := range req.Messages {
+		chatReq.Messages = append(chatReq.Messages, openaiMessageFrom(m))
+	}
+	if len(req.Messages) == 0 && req.Prompt != "" {
+		chatReq.Messages = []openaiMessage{{Role: "user", Content: req.Prompt}}
+	}
+	if opts := req.Options; opts != nil {
+		chatReq.Temperature, chatReq.TopP, chatReq.Seed, chatReq.Stop = opts.Temperature, opts.TopP, opts.Seed, opts.Stop
+		// Negative values mean "no limit" in Ollama, which is the default here.
+		if opts.NumPredict != nil && *opts.NumPredict > 0 {
+			chatReq.MaxTokens = opts.NumPredict
+		}
+	}
+	if req.Format == "json" {
+		chatReq.ResponseFormat = &openaiResponseFormat{Type: "json_object"}
+	}
+	return chatReq
+}
+
+// openaiMessageFrom converts a message, passing its images as data URLs.
+func openaiMessageFrom(m Message) openaiMessage {
+	if len(m.Images) == 0 {
+		return openaiMessage{Role: m.Role, Content: m.Content}
+	}
+	parts := []openaiContentPart{{Type: "text", Text: m.Content}}
+	for _, image := range m.Images {
+		parts = append(parts, openaiContentPart{Type: "image_url", ImageURL: &openaiImageURL{URL: imageDataURL(image)}})
+	}
+	return openaiMessage{Role: m.Role, Content: parts}
+}
+
+// imageDataURL wraps a base64-encoded image in a data URL.
+func imageDataURL(image string) string {
+	return "data:" + imageMediaType(image) + ";base64," + image
+}
+
+func (p *openaiProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	if req.Suffix != "" {
+		return nil, fmt.Errorf("%w: fill-in-middle completion", ErrUnsupported)
+	}
+	// A request without a prompt or messages only loads a model in Ollama. Such
+	// APIs load their models on their own, so there is nothing to do.
+	if len(req.Messages) == 0 && req.Prompt == "" {
+		return &GenerateResponse{Model: req.Model, Done: true}, nil
+	}
+
+	started := time.Now()
+	resp, err := p.post(ctx, "/chat/completions", chatRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in Generate", "error", err)
+		}
+	}()
+
+	var chatResp openaiChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("response contains no choices")
+	}
+	elapsed := time.Since(started).Nanoseconds()
+	stats := usageStats(chatResp.Usage, elapsed, 0, elapsed)
+	stats.DoneReason = chatResp.Choices[0].FinishReason
+	return &GenerateResponse{
+		Model:    chatResp.Model,
+		Response: chatResp.Choices[0].Message.Content,
+		Done:     true,
+		Stats:    stats,
+	}, nil
+}
+
+// GenerateStream streams a chat completion sent as server-sent events. The API
+// reports token counts but no timings, so the durations are measured here: the
+// prompt evaluation lasts until the first token arrives.
+func (p *openaiProvider) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
+	defer close(ch)
+	started := time.Now()
+	resp, err := p.post(ctx, "/chat/completions", chatRequest(req, true))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in GenerateStream", "error", err)
+		}
+	}()
+
+	send := func(streamResp StreamResponse) error {
+		select {
+		case ch <- streamResp:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var usage *openaiUsage
+	var doneReason string
+	var firstToken time.Time
+	err = readSSE(ctx, resp.Body, func(data string) (bool, error) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+		var chunk openaiChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			slog.Warn("Failed to unmarshal stream chunk from OpenAI-compatible API", "error", err, "line", data)
+			return false, send(StreamResponse{Error: "Failed to decode stream chunk"})
+		}
+		if chunk.Error != nil {
+			return false, fmt.Errorf("api reported an error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			return false, nil
+		}
+		if reason := chunk.Choices[0].FinishReason; reason != "" {
+			doneReason = reason
+		}
+		if chunk.Choices[0].Delta.Content == "" {
+			return false, nil
+		}
+		if firstToken.IsZero() {
+			firstToken = time.Now()
+		}
+		return false, send(StreamResponse{Content: chunk.Choices[0].Delta.Content})
+	})
+	if err != nil {
+		return err
+	}
+
+	finished := time.Now()
+	if firstToken.IsZero() {
+		firstToken = finished
+	}
+	stats := usageStats(usage, finished.Sub(started).Nanoseconds(), firstToken.Sub(started).Nanoseconds(), finished.Sub(firstToken).Nanoseconds())
+	stats.DoneReason = doneReason
+	return send(StreamResponse{Done: true, Stats: stats})
+}
+
+// usageStats maps the token counts of a completion to GenerationStats. Without
+// usage, e.g. from a server that ignores `stream_options`, the counts stay zero.
+func usageStats(usage *openaiUsage, total, promptEval, eval int64) *GenerationStats {
+	stats := &GenerationStats{TotalDuration: total, PromptEvalDuration: promptEval, EvalDuration: eval}
+	if usage != nil {
+		stats.PromptEvalCount = usage.PromptTokens
+		stats.EvalCount = usage.CompletionTokens
+	}
+	return stats
+}
+
+// ListModels lists the models the API serves. It reports no sizes or details.
+func (p *openaiProvider) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := p.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in ListModels", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("api returned non-200 status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var listResp struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Created int64  `json:"created"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+
+	models := make([]Model, 0, len(listResp.Data))
+	for _, m := range listResp.Data {
+		model := Model{Name: m.ID}
+		if m.Created > 0 {
+			model.ModifiedAt = time.Unix(m.Created, 0).UTC().Format(time.RFC3339Nano)
+		}
+		models = append(models, model)
+	}
+	return &ListModelsResponse{Models: models}, nil
+}
+
+func (p *openaiProvider) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	resp, err := p.post(ctx, "/embeddings", req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in Embeddings", "error", err)
+		}
+	}()
+
+	var embedResp struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	if len(embedResp.Data) != len(req.Input) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(req.Input), len(embedResp.Data))
+	}
+	// The vectors are matched to the input by their index, not their position.
+	embeddings := make([][]float32, len(req.Input))
+	for _, d := range embedResp.Data {
+		if d.Index < 0 || d.Index >= len(embeddings) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	return &EmbeddingsResponse{Model: embedResp.Model, Embeddings: embeddings}, nil
+}
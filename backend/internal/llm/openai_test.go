@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// TestOpenAIProvider uses a mock OpenAI-compatible server to verify that requests
+// are translated to chat completions and that streamed chunks, usage and the
+// `[DONE]` sentinel are handled.
+func TestOpenAIProvider(t *testing.T) {
+	ctx := context.Background()
+	var capturedPath, capturedAuth string
+	var capturedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		capturedAuth = r.Header.Get("Authorization")
+		capturedBody, _ = io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			var req openaiChatRequest
+			require.NoError(t, json.Unmarshal(capturedBody, &req))
+			if req.Model == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": {"message": "The model 'missing' does not exist"}}`))
+				return
+			}
+			if !req.Stream {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"model": "gpt-4o-mini", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello!"}}], "usage": {"prompt_tokens": 9, "completion_tokens": 3}}`))
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			// A keep-alive comment, a role-only chunk, two content chunks, the
+			// usage chunk without choices and the sentinel.
+			_, _ = w.Write([]byte(": keep-alive\n\n" +
+				`data: {"choices": [{"index": 0, "delta": {"role": "assistant"}}]}` + "\n\n" +
+				`data: {"choices": [{"index": 0, "delta": {"content": "Hel"}}]}` + "\n\n" +
+				`data: {"choices": [{"index": 0, "delta": {"content": "lo!"}, "finish_reason": "stop"}]}` + "\n\n" +
+				`data: {"choices": [], "usage": {"prompt_tokens": 9, "completion_tokens": 3}}` + "\n\n" +
+				"data: [DONE]\n\n"))
+		case "/v1/models":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "gpt-4o-mini", "object": "model", "created": 1721172741}]}`))
+		case "/v1/embeddings":
+			w.Header().Set("Content-Type", "application/json")
+			// The vectors are deliberately listed out of order.
+			_, _ = w.Write([]byte(`{"model": "text-embedding-3-small", "data": [{"index": 1, "embedding": [0.3, 0.4]}, {"index": 0, "embedding": [0.1, 0.2]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	provider := NewOpenAIProvider(server.URL+"/v1/", OpenAIConfig{APIKey: "sk-test"})
+
+	t.Run("GenerateStream", func(t *testing.T) {
+		// ARRANGE
+		temperature, numPredict := float32(0.2), 64
+		req := &GenerateRequest{
+			Model:    "gpt-4o-mini",
+			Messages: []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi"}},
+			Options:  &RequestOptions{Temperature: &temperature, NumPredict: &numPredict},
+		}
+		ch := make(chan StreamResponse, 10)
+
+		// ACT
+		err := provider.GenerateStream(ctx, req, ch)
+
+		// ASSERT: The request is a streamed chat completion with usage.
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/chat/completions", capturedPath)
+		assert.Equal(t, "Bearer sk-test", capturedAuth)
+		assert.JSONEq(t, `{
+			"model": "gpt-4o-mini",
+			"messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}],
+			"stream": true,
+			"stream_options": {"include_usage": true},
+			"temperature": 0.2,
+			"max_tokens": 64
+		}`, string(capturedBody))
+
+		var responses []StreamResponse
+		for resp := range ch {
+			responses = append(responses, resp)
+		}
+		require.Len(t, responses, 3)
+		assert.Equal(t, "Hel", responses[0].Content)
+		assert.Equal(t, "lo!", responses[1].Content)
+		final := responses[2]
+		assert.True(t, final.Done)
+		require.NotNil(t, final.Stats)
+		assert.Equal(t, 9, final.Stats.PromptEvalCount)
+		assert.Equal(t, 3, final.Stats.EvalCount)
+		assert.Positive(t, final.Stats.TotalDuration)
+		assert.Equal(t, "stop", final.Stats.DoneReason)
+	})
+
+	t.Run("GenerateStream reports an unknown model", func(t *testing.T) {
+		ch := make(chan StreamResponse, 10)
+		err := provider.GenerateStream(ctx, &GenerateRequest{Model: "missing", Messages: []Message{{Role: "user", Content: "Hi"}}}, ch)
+
+		assert.ErrorIs(t, err, ErrModelNotFound)
+		_, open := <-ch
+		assert.False(t, open, "the channel must be closed")
+	})
+
+	t.Run("GenerateStream reports an error chunk", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`data: {"choices": [{"delta": {"content": "Hel"}}]}` + "\n\n" + `data: {"error": {"message": "upstream overloaded"}}` + "\n\n"))
+		}))
+		defer failing.Close()
+		ch := make(chan StreamResponse, 10)
+
+		err := NewOpenAIProvider(failing.URL, OpenAIConfig{}).GenerateStream(ctx, &GenerateRequest{Model: "m", Prompt: "Hi"}, ch)
+
+		assert.ErrorContains(t, err, "upstream overloaded")
+	})
+
+	t.Run("Generate", func(t *testing.T) {
+		resp, err := provider.Generate(ctx, &GenerateRequest{Model: "gpt-4o-mini", Prompt: "Hi", Format: "json"})
+
+		// ASSERT: A bare prompt becomes a user message.
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"model": "gpt-4o-mini",
+			"messages": [{"role": "user", "content": "Hi"}],
+			"stream": false,
+			"response_format": {"type": "json_object"}
+		}`, string(capturedBody))
+		assert.Equal(t, "Hello!", resp.Response)
+		require.NotNil(t, resp.Stats)
+		assert.Equal(t, 3, resp.Stats.EvalCount)
+	})
+
+	t.Run("Generate passes images as data URLs", func(t *testing.T) {
+		// "iVBORw0KGgo" is the base64-encoded PNG signature.
+		msg := openaiMessageFrom(Message{Role: "user", Content: "What is this?", Images: []string{"iVBORw0KGgoAAAANSUhEUg=="}})
+
+		body, err := json.Marshal(msg)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role": "user", "content": [
+			{"type": "text", "text": "What is this?"},
+			{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg=="}}
+		]}`, string(body))
+	})
+
+	t.Run("ListModels", func(t *testing.T) {
+		resp, err := provider.ListModels(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, resp.Models, 1)
+		assert.Equal(t, "gpt-4o-mini", resp.Models[0].Name)
+		assert.Equal(t, "2024-07-16T23:32:21Z", resp.Models[0].ModifiedAt)
+	})
+
+	t.Run("Embeddings", func(t *testing.T) {
+		resp, err := provider.Embeddings(ctx, &EmbeddingsRequest{Model: "text-embedding-3-small", Input: []string{"a", "b"}})
+
+		// ASSERT: The vectors are ordered by their index.
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/embeddings", capturedPath)
+		assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, resp.Embeddings)
+	})
+
+	t.Run("Model management is not supported", func(t *testing.T) {
+		ch := make(chan PullStatus)
+		err := provider.PullModel(ctx, &PullModelRequest{Name: "qwen3:8b"}, ch)
+
+		assert.ErrorIs(t, err, ErrUnsupported)
+		// WHY: The API layer maps the error to a 501 through the application sentinel.
+		assert.ErrorIs(t, err, app_errors.ErrNotSupported)
+		_, open := <-ch
+		assert.False(t, open, "the channel must be closed")
+		assert.ErrorIs(t, provider.DeleteModel(ctx, &DeleteModelRequest{Name: "qwen3:8b"}), ErrUnsupported)
+	})
+}
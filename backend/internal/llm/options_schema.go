@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OptionSchema describes a generation option, so that clients can render an
+// input for it without knowing the options in advance.
+type OptionSchema struct {
+	// Name is the key of the option in `options`.
+	Name string `json:"name" example:"temperature"`
+	// Type is "number", "integer", "string" or "array" (of strings).
+	Type string `json:"type" example:"number"`
+	// Min and Max are the inclusive bounds of a numeric option; a missing bound
+	// means there is none.
+	Min *float64 `json:"min,omitempty" example:"0"`
+	Max *float64 `json:"max,omitempty" example:"2"`
+	// Enum lists the allowed values of an option that only takes a few.
+	Enum []any `json:"enum,omitempty" swaggertype:"array,string"`
+	// Default is the value Ollama uses if the option is not set, if it has a fixed one.
+	Default     any    `json:"default,omitempty" swaggertype:"string" example:"0.8"`
+	Description string `json:"description" example:"Randomness of the answer; higher values are more creative."`
+}
+
+// optionDocs holds what the struct tags of RequestOptions cannot tell: the
+// description of each option and Ollama's default value.
+var optionDocs = map[string]struct {
+	description  string
+	defaultValue any
+}{
+	"temperature":    {"Randomness of the answer; higher values are more creative.", 0.8},
+	"top_k":          {"Samples only from the k most likely tokens; lower values are more conservative.", 40},
+	"top_p":          {"Samples only from the most likely tokens whose probabilities add up to p.", 0.9},
+	"min_p":          {"Drops tokens less likely than this fraction of the most likely token.", 0.0},
+	"system":         {"Replaces the system prompt for this request.", nil},
+	"repeat_penalty": {"Penalizes repeated tokens; higher values repeat less.", 1.1},
+	"repeat_last_n":  {"How many tokens back repetitions are penalized; -1 means the whole context.", 64},
+	"seed":           {"Makes the answer reproducible for the same prompt and options.", nil},
+	"stop":           {"Sequences at which the model stops generating.", nil},
+	"num_predict":    {"Maximum number of generated tokens; -1 means no limit and -2 fills the context.", -1},
+	"num_ctx":        {"Size of the context window in tokens.", nil},
+	"mirostat":       {"Mirostat sampling: 0 = off, 1 = Mirostat, 2 = Mirostat 2.0.", 0},
+	"mirostat_eta":   {"Learning rate of Mirostat.", 0.1},
+	"mirostat_tau":   {"Target entropy of Mirostat; lower values are more focused.", 5.0},
+	"num_gpu":        {"Number of layers offloaded to the GPU; -1 lets Ollama decide.", -1},
+	"keep_alive":     {`How long the model stays loaded, e.g. "5m", or seconds; 0 unloads it and -1 keeps it loaded.`, nil},
+	"format":         {`"json" makes the model answer with valid JSON.`, nil},
+}
+
+// OptionsSchema describes every field of RequestOptions. The types and bounds
+// are derived from the struct and its validation tags, so they cannot drift
+// from what requests are validated against.
+var OptionsSchema = sync.OnceValue(func() []OptionSchema {
+	t := reflect.TypeFor[RequestOptions]()
+	schema := make([]OptionSchema, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		option := OptionSchema{Name: name, Type: schemaType(field.Type)}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "gte", "min":
+				option.Min = parseBound(value)
+			case "lte", "max":
+				option.Max = parseBound(value)
+			case "oneof":
+				for _, v := range strings.Fields(value) {
+					option.Enum = append(option.Enum, enumValue(option.Type, v))
+				}
+			}
+		}
+		docs := optionDocs[name]
+		option.Description, option.Default = docs.description, docs.defaultValue
+		schema = append(schema, option)
+	}
+	return schema
+})
+
+// schemaType maps the Go type of an option to its JSON type.
+func schemaType(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Slice:
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+func parseBound(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// enumValue converts an allowed value to the type of its option.
+func enumValue(optionType, value string) any {
+	if optionType == "integer" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return value
+}
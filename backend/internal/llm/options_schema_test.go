@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOptionsSchema verifies that the option schema is derived from the fields and
+// validation tags of RequestOptions.
+func TestOptionsSchema(t *testing.T) {
+	schema := OptionsSchema()
+	byName := make(map[string]OptionSchema, len(schema))
+	for _, option := range schema {
+		byName[option.Name] = option
+	}
+	bound := func(f float64) *float64 { return &f }
+
+	t.Run("Every option is described", func(t *testing.T) {
+		require.Len(t, schema, reflect.TypeFor[RequestOptions]().NumField())
+		for _, option := range schema {
+			assert.NotEmpty(t, option.Description, "option %q needs a description in optionDocs", option.Name)
+		}
+	})
+
+	t.Run("Bounds come from the validation tags", func(t *testing.T) {
+		assert.Equal(t, OptionSchema{
+			Name: "temperature", Type: "number", Min: bound(0), Max: bound(2), Default: 0.8,
+			Description: optionDocs["temperature"].description,
+		}, byName["temperature"])
+		assert.Equal(t, OptionSchema{
+			Name: "top_p", Type: "number", Min: bound(0), Max: bound(1), Default: 0.9,
+			Description: optionDocs["top_p"].description,
+		}, byName["top_p"])
+		assert.Equal(t, bound(-2), byName["num_predict"].Min)
+		assert.Nil(t, byName["num_predict"].Max)
+	})
+
+	t.Run("Types and allowed values", func(t *testing.T) {
+		assert.Equal(t, "integer", byName["mirostat"].Type)
+		assert.Equal(t, []any{0, 1, 2}, byName["mirostat"].Enum)
+		assert.Equal(t, "string", byName["format"].Type)
+		assert.Equal(t, []any{"json"}, byName["format"].Enum)
+		assert.Equal(t, "array", byName["stop"].Type)
+		assert.Equal(t, "string", byName["keep_alive"].Type)
+	})
+}
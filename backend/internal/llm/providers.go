@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// The names of the supported providers, which are also the prefixes that route
+// a model to them.
+const (
+	ProviderOllama    = "ollama"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	// ProviderFake answers with lorem ipsum, for development without a model.
+	ProviderFake = "fake"
+)
+
+// ProviderRegistry serves several providers at once. A model is routed by the
+// "provider/model" syntax, e.g. "openai/gpt-4o"; a model without the prefix of a
+// registered provider goes to the default provider, so Ollama tags such as
+// "hf.co/user/model:Q4_K_M" keep working.
+//
+// The registry is itself an LLMProvider, so the services do not need to know
+// how many providers there are.
+type ProviderRegistry struct {
+	defaultName string
+	providers   map[string]LLMProvider
+}
+
+// NewProviderRegistry creates a registry whose unprefixed models are served by
+// the given default provider.
+func NewProviderRegistry(defaultName string, defaultProvider LLMProvider) *ProviderRegistry {
+	return &ProviderRegistry{
+		defaultName: defaultName,
+		providers:   map[string]LLMProvider{defaultName: defaultProvider},
+	}
+}
+
+// Register adds a provider whose models are addressed as "name/model".
+func (r *ProviderRegistry) Register(name string, provider LLMProvider) {
+	r.providers[name] = provider
+}
+
+// Names returns the names of the registered providers, the default one first.
+func (r *ProviderRegistry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		if name != r.defaultName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{r.defaultName}, names...)
+}
+
+// Route returns the provider of a model and the model's name at that provider.
+func (r *ProviderRegistry) Route(model string) (provider LLMProvider, name string) {
+	if prefix, rest, ok := strings.Cut(model, "/"); ok {
+		if p, ok := r.providers[prefix]; ok {
+			return p, rest
+		}
+	}
+	return r.providers[r.defaultName], model
+}
+
+func (r *ProviderRegistry) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	provider, name := r.Route(req.Model)
+	routed := *req
+	routed.Model = name
+	return provider.Generate(ctx, &routed)
+}
+
+func (r *ProviderRegistry) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
+	provider, name := r.Route(req.Model)
+	routed := *req
+	routed.Model = name
+	return provider.GenerateStream(ctx, &routed, ch)
+}
+
+// ListModels lists the models of every provider. The models of the default
+// provider keep their names, the others are prefixed with their provider. A
+// failing additional provider is only logged, so that the local models stay
+// usable while a hosted API is down.
+func (r *ProviderRegistry) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+	result := &ListModelsResponse{Models: []Model{}}
+	for _, providerName := range r.Names() {
+		resp, err := r.providers[providerName].ListModels(ctx)
+		if err != nil {
+			if providerName == r.defaultName {
+				return nil, err
+			}
+			slog.Warn("Could not list the models of a provider", "provider", providerName, "error", err)
+			continue
+		}
+		for _, m := range resp.Models {
+			if providerName != r.defaultName {
+				m.Name = providerName + "/" + m.Name
+			}
+			m.Provider = providerName
+			result.Models = append(result.Models, m)
+		}
+	}
+	return result, nil
+}
+
+func (r *ProviderRegistry) PullModel(ctx context.Context, req *PullModelRequest, ch chan<- PullStatus) error {
+	provider, name := r.Route(req.Name)
+	return provider.PullModel(ctx, &PullModelRequest{Name: name}, ch)
+}
+
+func (r *ProviderRegistry) DeleteModel(ctx context.Context, req *DeleteModelRequest) error {
+	provider, name := r.Route(req.Name)
+	return provider.DeleteModel(ctx, &DeleteModelRequest{Name: name})
+}
+
+func (r *ProviderRegistry) ShowModelInfo(ctx context.Context, req *ShowModelRequest) (*ModelInfo, error) {
+	provider, name := r.Route(req.Name)
+	return provider.ShowModelInfo(ctx, &ShowModelRequest{Name: name})
+}
+
+func (r *ProviderRegistry) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	provider, name := r.Route(req.Model)
+	return provider.Embeddings(ctx, &EmbeddingsRequest{Model: name, Input: req.Input})
+}
+
+// RunningModels lists the models loaded by the default provider; hosted APIs do
+// not report loaded models.
+func (r *ProviderRegistry) RunningModels(ctx context.Context) (*RunningModelsResponse, error) {
+	return r.providers[r.defaultName].RunningModels(ctx)
+}
+
+// CopyModel copies a model within its provider; a copy to another provider is
+// a validation error.
+func (r *ProviderRegistry) CopyModel(ctx context.Context, req *CopyModelRequest) error {
+	provider, source := r.Route(req.Source)
+	destinationProvider, destination := r.Route(req.Destination)
+	if destinationProvider != provider {
+		return fmt.Errorf("%w: a model cannot be copied to another provider", app_errors.ErrValidation)
+	}
+	return provider.CopyModel(ctx, &CopyModelRequest{Source: source, Destination: destination})
+}
+
+func (r *ProviderRegistry) CreateModel(ctx context.Context, req *CreateModelRequest, ch chan<- PullStatus) error {
+	provider, name := r.Route(req.Name)
+	routed := *req
+	routed.Name = name
+	return provider.CreateModel(ctx, &routed, ch)
+}
+
+// CheckBlob checks a blob of the default provider; blobs only exist in Ollama.
+func (r *ProviderRegistry) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	return r.providers[r.defaultName].CheckBlob(ctx, digest)
+}
+
+// PushBlob uploads a blob to the default provider.
+func (r *ProviderRegistry) PushBlob(ctx context.Context, digest string, body io.Reader) error {
+	return r.providers[r.defaultName].PushBlob(ctx, digest, body)
+}
+
+// Version returns the version of the default provider.
+func (r *ProviderRegistry) Version(ctx context.Context) (string, error) {
+	return r.providers[r.defaultName].Version(ctx)
+}
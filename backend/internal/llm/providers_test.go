@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// recordingProvider records the model it was asked for and lists fixed models.
+type recordingProvider struct {
+	unmanagedModels
+	models    []Model
+	listErr   error
+	lastModel string
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	p.lastModel = req.Model
+	return &GenerateResponse{Model: req.Model, Done: true}, nil
+}
+
+func (p *recordingProvider) GenerateStream(ctx context.Context, req *GenerateRequest, ch chan<- StreamResponse) error {
+	p.lastModel = req.Model
+	close(ch)
+	return nil
+}
+
+func (p *recordingProvider) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+	if p.listErr != nil {
+		return nil, p.listErr
+	}
+	return &ListModelsResponse{Models: p.models}, nil
+}
+
+func (p *recordingProvider) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	p.lastModel = req.Model
+	return &EmbeddingsResponse{}, nil
+}
+
+// TestProviderRegistry verifies that models are routed by their provider prefix
+// and that the model lists of all providers are merged.
+func TestProviderRegistry(t *testing.T) {
+	ctx := context.Background()
+	newRegistry := func() (*ProviderRegistry, *recordingProvider, *recordingProvider) {
+		ollama := &recordingProvider{models: []Model{{Name: "qwen3:8b"}}}
+		openai := &recordingProvider{models: []Model{{Name: "gpt-4o"}}}
+		registry := NewProviderRegistry(ProviderOllama, ollama)
+		registry.Register(ProviderOpenAI, openai)
+		return registry, ollama, openai
+	}
+
+	t.Run("Routes prefixed models to their provider", func(t *testing.T) {
+		registry, ollama, openai := newRegistry()
+
+		_, err := registry.Generate(ctx, &GenerateRequest{Model: "openai/gpt-4o"})
+		require.NoError(t, err)
+		assert.Equal(t, "gpt-4o", openai.lastModel)
+
+		_, err = registry.Generate(ctx, &GenerateRequest{Model: "qwen3:8b"})
+		require.NoError(t, err)
+		assert.Equal(t, "qwen3:8b", ollama.lastModel)
+	})
+
+	t.Run("Leaves unknown prefixes to the default provider", func(t *testing.T) {
+		registry, ollama, _ := newRegistry()
+
+		// WHY: Ollama tags may contain slashes, e.g. models pulled from Hugging Face.
+		_, err := registry.Embeddings(ctx, &EmbeddingsRequest{Model: "hf.co/user/model:Q4_K_M"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "hf.co/user/model:Q4_K_M", ollama.lastModel)
+	})
+
+	t.Run("Lists the models of all providers", func(t *testing.T) {
+		registry, _, _ := newRegistry()
+
+		resp, err := registry.ListModels(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Model{
+			{Name: "qwen3:8b", Provider: ProviderOllama},
+			{Name: "openai/gpt-4o", Provider: ProviderOpenAI},
+		}, resp.Models)
+	})
+
+	t.Run("Skips an additional provider that cannot list its models", func(t *testing.T) {
+		registry, _, openai := newRegistry()
+		openai.listErr = errors.New("connection refused")
+
+		resp, err := registry.ListModels(ctx)
+
+		require.NoError(t, err)
+		assert.Len(t, resp.Models, 1)
+	})
+
+	t.Run("Fails if the default provider cannot list its models", func(t *testing.T) {
+		registry, ollama, _ := newRegistry()
+		ollama.listErr = errors.New("connection refused")
+
+		_, err := registry.ListModels(ctx)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects a copy to another provider", func(t *testing.T) {
+		registry, _, _ := newRegistry()
+
+		err := registry.CopyModel(ctx, &CopyModelRequest{Source: "qwen3:8b", Destination: "openai/qwen3:8b"})
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+}
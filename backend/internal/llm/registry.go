@@ -0,0 +1,319 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ModelRegistry searches the public library of models that can be pulled.
+type ModelRegistry interface {
+	SearchModels(ctx context.Context, query string) ([]RegistryModel, error)
+	// ManifestDigest returns the digest of the published manifest of a model tag,
+	// which equals the digest Ollama reports for the tag once it is pulled.
+	ManifestDigest(ctx context.Context, name string) (string, error)
+}
+
+// ErrRegistryUnavailable is returned when the model registry cannot be reached or
+// answers with an error.
+var ErrRegistryUnavailable = errors.New("model registry is unavailable")
+
+// ErrNotInRegistry is returned for a model that is not published in the registry,
+// e.g. a model created locally or pulled from another host.
+var ErrNotInRegistry = errors.New("model is not in the registry")
+
+// RegistryModel is a model of the public Ollama library.
+type RegistryModel struct {
+	Name        string `json:"name" example:"llama3.1"`
+	Description string `json:"description" example:"Llama 3.1 is a new state-of-the-art model from Meta."`
+	// Pulls is the approximate number of downloads, e.g. 93500000 for "93.5M".
+	Pulls int64 `json:"pulls" example:"93500000"`
+	// Tags are the sizes the model is published in; the name to pull is "<name>:<tag>".
+	Tags []string `json:"tags" example:"8b,70b,405b"`
+	// Capabilities lists features such as "tools", "vision" or "thinking".
+	Capabilities []string `json:"capabilities,omitempty" example:"tools"`
+}
+
+// RegistryConfig holds the settings of the registry client.
+type RegistryConfig struct {
+	// Timeout bounds a single search request; 0 means no timeout.
+	Timeout time.Duration
+	// CacheTTL is how long search results are reused; 0 disables the cache.
+	CacheTTL time.Duration
+	// ManifestURL is the registry that serves the manifests of the models, usually
+	// "https://registry.ollama.ai".
+	ManifestURL string
+}
+
+// maxRegistryCacheEntries bounds the number of cached searches, so that arbitrary
+// queries cannot grow the cache without limit.
+const maxRegistryCacheEntries = 256
+
+type registryCacheEntry struct {
+	models    []RegistryModel
+	expiresAt time.Time
+}
+
+// ollamaRegistry searches ollama.com. The library has no public API, so the
+// results are scraped from the HTML of its search page, which marks the relevant
+// elements with `x-test-*` attributes.
+type ollamaRegistry struct {
+	client *http.Client
+	url    string
+	cfg    RegistryConfig
+
+	mu    sync.Mutex
+	cache map[string]registryCacheEntry
+}
+
+// NewOllamaRegistry creates a client for the model library at url, usually
+// "https://ollama.com".
+func NewOllamaRegistry(url string, cfg RegistryConfig) ModelRegistry {
+	return &ollamaRegistry{
+		client: &http.Client{Timeout: cfg.Timeout},
+		url:    strings.TrimRight(url, "/"),
+		cfg:    cfg,
+		cache:  make(map[string]registryCacheEntry),
+	}
+}
+
+// SearchModels returns the library models matching the query, in the order of the
+// registry. An empty query lists the most popular models.
+func (r *ollamaRegistry) SearchModels(ctx context.Context, query string) ([]RegistryModel, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if models, ok := r.cached(query); ok {
+		return models, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url+"/search?q="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRegistryUnavailable, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in SearchModels", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: registry returned status %d", ErrRegistryUnavailable, resp.StatusCode)
+	}
+
+	models, err := parseRegistrySearch(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read search results: %s", ErrRegistryUnavailable, err)
+	}
+	r.store(query, models)
+	return models, nil
+}
+
+// ManifestDigest fetches the manifest of a model tag and returns its digest. A
+// name without a namespace is in the "library" namespace, and one without a tag
+// refers to "latest"; a name with a host is not in this registry.
+func (r *ollamaRegistry) ManifestDigest(ctx context.Context, name string) (string, error) {
+	namespace, model, tag, ok := splitModelName(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotInRegistry, name)
+	}
+	manifestURL := fmt.Sprintf("%s/v2/%s/%s/manifests/%s", strings.TrimRight(r.cfg.ManifestURL, "/"),
+		url.PathEscape(namespace), url.PathEscape(model), url.PathEscape(tag))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrRegistryUnavailable, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body in ManifestDigest", "error", err)
+		}
+	}()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotInRegistry, name)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%w: registry returned status %d", ErrRegistryUnavailable, resp.StatusCode)
+	}
+
+	// Ollama identifies a pulled tag by the SHA-256 of the manifest as it was
+	// downloaded, so the digest is computed the same way.
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("%w: could not read manifest: %s", ErrRegistryUnavailable, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// splitModelName splits a model name such as "user/model:tag" into its parts.
+// It reports false for a name with a host, such as "hf.co/user/model".
+func splitModelName(name string) (namespace, model, tag string, ok bool) {
+	model, tag = name, "latest"
+	// The tag follows the last colon, unless that colon belongs to a host's port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		model = name[:i]
+		if name[i+1:] != "" {
+			tag = name[i+1:]
+		}
+	}
+	namespace = "library"
+	parts := strings.Split(model, "/")
+	switch {
+	case len(parts) == 1:
+	case len(parts) == 2 && !strings.ContainsAny(parts[0], ".:"):
+		namespace, model = parts[0], parts[1]
+	default:
+		return "", "", "", false
+	}
+	if model == "" || namespace == "" {
+		return "", "", "", false
+	}
+	return namespace, model, tag, true
+}
+
+func (r *ollamaRegistry) cached(query string) ([]RegistryModel, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.cache[query]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.models, true
+}
+
+func (r *ollamaRegistry) store(query string, models []RegistryModel) {
+	if r.cfg.CacheTTL <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(r.cache) >= maxRegistryCacheEntries {
+		for key, entry := range r.cache {
+			if now.After(entry.expiresAt) {
+				delete(r.cache, key)
+			}
+		}
+		// Everything is still fresh; start over rather than tracking recency.
+		if len(r.cache) >= maxRegistryCacheEntries {
+			clear(r.cache)
+		}
+	}
+	r.cache[query] = registryCacheEntry{models: models, expiresAt: now.Add(r.cfg.CacheTTL)}
+}
+
+// parseRegistrySearch extracts the models from the HTML of the library search page.
+func parseRegistrySearch(body io.Reader) ([]RegistryModel, error) {
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	models := []RegistryModel{}
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && hasAttr(n, "x-test-model") {
+			if m := parseRegistryModel(n); m.Name != "" {
+				models = append(models, m)
+			}
+		}
+	}
+	return models, nil
+}
+
+// parseRegistryModel reads a single search result.
+func parseRegistryModel(n *html.Node) RegistryModel {
+	m := RegistryModel{Tags: []string{}}
+	for e := range n.Descendants() {
+		if e.Type != html.ElementNode {
+			continue
+		}
+		switch {
+		case hasAttr(e, "x-test-search-response-title"):
+			m.Name = nodeText(e)
+		case hasAttr(e, "x-test-size"):
+			m.Tags = append(m.Tags, nodeText(e))
+		case hasAttr(e, "x-test-capability"):
+			m.Capabilities = append(m.Capabilities, nodeText(e))
+		case hasAttr(e, "x-test-pull-count"):
+			m.Pulls = parsePullCount(nodeText(e))
+		case e.DataAtom == atom.P && m.Description == "" && !hasDescendantAttr(e, "x-test-pull-count"):
+			// The description is the first paragraph; a later one holds the stats.
+			m.Description = nodeText(e)
+		}
+	}
+	return m
+}
+
+// parsePullCount converts an abbreviated count such as "93.5M" to a number. An
+// unreadable count is 0.
+func parsePullCount(s string) int64 {
+	s = strings.TrimSpace(s)
+	multiplier := 1.0
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K', 'k':
+			multiplier = 1e3
+		case 'M', 'm':
+			multiplier = 1e6
+		case 'B', 'b':
+			multiplier = 1e9
+		}
+		if multiplier != 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(n * multiplier)
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDescendantAttr(n *html.Node, key string) bool {
+	for e := range n.Descendants() {
+		if e.Type == html.ElementNode && hasAttr(e, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeText returns the text content of n with whitespace collapsed.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for e := range n.Descendants() {
+		if e.Type == html.TextNode {
+			b.WriteString(e.Data)
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
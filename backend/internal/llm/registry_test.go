@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registrySearchPage is a trimmed copy of the ollama.com search page, keeping the
+// markup the parser relies on.
+const registrySearchPage = `<!DOCTYPE html>
+<html><body><main>
+<ul role="list">
+  <li x-test-model class="flex items-baseline border-b border-neutral-200 py-6">
+    <a href="/library/llama3.1" class="group w-full">
+      <div class="flex flex-col mb-1" title="llama3.1">
+        <h2 class="truncate text-xl font-medium"><span x-test-search-response-title>llama3.1</span></h2>
+        <p class="max-w-lg break-words text-neutral-800 text-md">Llama 3.1 is a new state-of-the-art model
+          from Meta available in 8B, 70B and 405B parameter sizes.</p>
+      </div>
+      <div class="flex flex-col">
+        <div class="flex flex-wrap space-x-2">
+          <span x-test-capability class="inline-flex">tools</span>
+          <span x-test-size class="inline-flex">8b</span>
+          <span x-test-size class="inline-flex">70b</span>
+          <span x-test-size class="inline-flex">405b</span>
+        </div>
+        <p class="my-1 space-x-5 text-[13px] font-medium text-neutral-500">
+          <span class="flex items-center"><span x-test-pull-count>93.5M</span><span class="hidden sm:flex">&nbsp;Pulls</span></span>
+          <span class="flex items-center"><span x-test-tag-count>93</span>&nbsp;Tags</span>
+          <span class="flex items-center">Updated&nbsp;<span x-test-updated>8 months ago</span></span>
+        </p>
+      </div>
+    </a>
+  </li>
+  <li x-test-model class="flex items-baseline border-b border-neutral-200 py-6">
+    <a href="/library/llama-guard3" class="group w-full">
+      <div class="flex flex-col mb-1" title="llama-guard3">
+        <h2><span x-test-search-response-title>llama-guard3</span></h2>
+      </div>
+      <div class="flex flex-col">
+        <div class="flex flex-wrap space-x-2">
+          <span x-test-size>1b</span>
+        </div>
+        <p><span x-test-pull-count>805</span>&nbsp;Pulls</p>
+      </div>
+    </a>
+  </li>
+</ul>
+</main></body></html>`
+
+// TestOllamaRegistry_SearchModels verifies that search results are scraped from
+// the library's HTML, cached, and that registry failures are reported as
+// `ErrRegistryUnavailable`.
+func TestOllamaRegistry_SearchModels(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Parses the search page", func(t *testing.T) {
+		// ARRANGE
+		var query string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/search", r.URL.Path)
+			query = r.URL.Query().Get("q")
+			_, err := w.Write([]byte(registrySearchPage))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+		registry := NewOllamaRegistry(server.URL, RegistryConfig{})
+
+		// ACT
+		models, err := registry.SearchModels(ctx, " Llama ")
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "llama", query)
+		assert.Equal(t, []RegistryModel{
+			{
+				Name:         "llama3.1",
+				Description:  "Llama 3.1 is a new state-of-the-art model from Meta available in 8B, 70B and 405B parameter sizes.",
+				Pulls:        93500000,
+				Tags:         []string{"8b", "70b", "405b"},
+				Capabilities: []string{"tools"},
+			},
+			{
+				// WHY: Without a description, the stats paragraph must not be taken for one.
+				Name:  "llama-guard3",
+				Pulls: 805,
+				Tags:  []string{"1b"},
+			},
+		}, models)
+	})
+
+	t.Run("Results are cached per query", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, err := w.Write([]byte(registrySearchPage))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+		registry := NewOllamaRegistry(server.URL, RegistryConfig{CacheTTL: time.Minute})
+
+		for _, q := range []string{"llama", "LLAMA", "qwen"} {
+			_, err := registry.SearchModels(ctx, q)
+			require.NoError(t, err)
+		}
+
+		assert.EqualValues(t, 2, requests.Load(), "the repeated query must be served from the cache")
+	})
+
+	t.Run("Registry errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		registry := NewOllamaRegistry(server.URL, RegistryConfig{CacheTTL: time.Minute})
+
+		_, err := registry.SearchModels(ctx, "llama")
+
+		assert.ErrorIs(t, err, ErrRegistryUnavailable)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		registry := NewOllamaRegistry(server.URL, RegistryConfig{Timeout: 20 * time.Millisecond})
+
+		_, err := registry.SearchModels(ctx, "llama")
+
+		assert.ErrorIs(t, err, ErrRegistryUnavailable)
+	})
+}
+
+// TestOllamaRegistry_ManifestDigest verifies that the digest of a model tag is the
+// SHA-256 of its manifest, which is how Ollama identifies a pulled tag.
+func TestOllamaRegistry_ManifestDigest(t *testing.T) {
+	ctx := context.Background()
+	manifest := `{"schemaVersion":2,"layers":[]}`
+	sum := sha256.Sum256([]byte(manifest))
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.Contains(r.URL.Path, "/missing/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/broken/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, err := w.Write([]byte(manifest))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	registry := NewOllamaRegistry("https://ollama.com", RegistryConfig{ManifestURL: server.URL})
+
+	t.Run("Names are resolved like Ollama does", func(t *testing.T) {
+		paths = nil
+		for _, name := range []string{"qwen3:8b", "qwen3", "user/model:q4"} {
+			digest, err := registry.ManifestDigest(ctx, name)
+			require.NoError(t, err)
+			assert.Equal(t, hex.EncodeToString(sum[:]), digest)
+		}
+		assert.Equal(t, []string{
+			"/v2/library/qwen3/manifests/8b",
+			"/v2/library/qwen3/manifests/latest",
+			"/v2/user/model/manifests/q4",
+		}, paths)
+	})
+
+	t.Run("Models of other hosts are not in the registry", func(t *testing.T) {
+		paths = nil
+		for _, name := range []string{"hf.co/user/model:q4", "localhost:5000/model:latest"} {
+			_, err := registry.ManifestDigest(ctx, name)
+			assert.ErrorIs(t, err, ErrNotInRegistry)
+		}
+		assert.Empty(t, paths, "no request may be sent for another host")
+	})
+
+	t.Run("Unknown model", func(t *testing.T) {
+		_, err := registry.ManifestDigest(ctx, "missing:latest")
+		assert.ErrorIs(t, err, ErrNotInRegistry)
+	})
+
+	t.Run("Registry errors", func(t *testing.T) {
+		_, err := registry.ManifestDigest(ctx, "broken:latest")
+		assert.ErrorIs(t, err, ErrRegistryUnavailable)
+	})
+}
+
+// TestParsePullCount verifies the conversion of abbreviated download counts.
+func TestParsePullCount(t *testing.T) {
+	testCases := map[string]int64{
+		"805":   805,
+		"1,234": 1234,
+		"12.5K": 12500,
+		"93.5M": 93500000,
+		"1.1B":  1100000000,
+		"":      0,
+		"many":  0,
+	}
+	for input, expected := range testCases {
+		assert.Equal(t, expected, parsePullCount(input), input)
+	}
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSFiles names the files of an HTTPS connection to Ollama, e.g. behind a
+// proxy with a certificate of a private CA.
+type TLSFiles struct {
+	// CAFile is a PEM bundle of the CAs that are trusted besides the system's roots.
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate and its key, for a
+	// server that requires mutual TLS. Both or neither must be set.
+	CertFile string
+	KeyFile  string
+	// SkipVerify accepts any server certificate. It is meant for testing only.
+	SkipVerify bool
+}
+
+// Load reads the files into a TLS configuration. It returns nil if nothing is
+// set, so that the defaults of net/http apply.
+func (f TLSFiles) Load() (*tls.Config, error) {
+	if f == (TLSFiles{}) {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: f.SkipVerify}
+
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s contains no PEM certificate", f.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return nil, errors.New("a client certificate and its key must be set together")
+	}
+	if f.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes a PEM block of the given type to a file in dir.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+// writeClientCert writes a self-signed client certificate and its key to dir.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "flow-ai"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+// TestOllamaProvider_TLS verifies that the provider trusts a server certificate
+// of a private CA only when it is configured, and presents a client certificate.
+func TestOllamaProvider_TLS(t *testing.T) {
+	ctx := context.Background()
+	var clientCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCerts = len(r.TLS.PeerCertificates)
+		_, _ = w.Write([]byte(`{"version": "0.6.2"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCert(t, dir)
+
+	t.Run("Rejects an unknown CA by default", func(t *testing.T) {
+		provider := NewOllamaProvider(server.URL, OllamaConfig{})
+
+		_, err := provider.Version(ctx)
+
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	for name, files := range map[string]TLSFiles{
+		"Trusts the configured CA":        {CAFile: caFile},
+		"Accepts any certificate if told": {SkipVerify: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig, err := files.Load()
+			require.NoError(t, err)
+			provider := NewOllamaProvider(server.URL, OllamaConfig{TLS: tlsConfig})
+
+			_, err = provider.Version(ctx)
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("Presents a client certificate", func(t *testing.T) {
+		tlsConfig, err := TLSFiles{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}.Load()
+		require.NoError(t, err)
+		provider := NewOllamaProvider(server.URL, OllamaConfig{TLS: tlsConfig})
+
+		_, err = provider.Version(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, clientCerts)
+	})
+}
+
+// TestTLSFiles_Load verifies that unusable files are reported when they are
+// loaded, not when Ollama is first called.
+func TestTLSFiles_Load(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeClientCert(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("hello"), 0o600))
+
+	t.Run("Nothing set", func(t *testing.T) {
+		tlsConfig, err := TLSFiles{}.Load()
+
+		require.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	for name, tc := range map[string]struct {
+		files    TLSFiles
+		expected string
+	}{
+		"Missing CA file":         {TLSFiles{CAFile: filepath.Join(dir, "missing.pem")}, "missing.pem"},
+		"CA file without PEM":     {TLSFiles{CAFile: notPEM}, "contains no PEM certificate"},
+		"Certificate without key": {TLSFiles{CertFile: certFile}, "must be set together"},
+		"Key file without PEM":    {TLSFiles{CertFile: certFile, KeyFile: notPEM}, "could not load client certificate"},
+		"Missing key file":        {TLSFiles{CertFile: certFile, KeyFile: keyFile + ".missing"}, "could not load client certificate"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.files.Load()
+
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
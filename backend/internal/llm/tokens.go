@@ -0,0 +1,29 @@
+package llm
+
+import "unicode/utf8"
+
+const (
+	// runesPerToken is the average number of characters a BPE-style tokenizer
+	// packs into a single token for typical English text.
+	runesPerToken = 4
+	// perMessageOverhead accounts for the role markers and separators that chat
+	// templates wrap around every message.
+	perMessageOverhead = 4
+)
+
+// EstimateTokens returns an APPROXIMATE token count for a list of chat messages.
+//
+// The estimate is tokenizer-agnostic: it assumes roughly four runes per token
+// plus a small fixed overhead per message. Real counts depend on the model's
+// tokenizer and chat template and can differ noticeably (especially for code
+// or non-Latin scripts), so the result should only be used for warnings and
+// rough budgeting, never for hard limits.
+func EstimateTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		runes := utf8.RuneCountInString(msg.Content)
+		// Round up so that any non-empty content costs at least one token.
+		total += (runes+runesPerToken-1)/runesPerToken + perMessageOverhead
+	}
+	return total
+}
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEstimateTokens verifies the rune-based token heuristic on known inputs.
+//
+// GOAL: The estimator is intentionally simple, so these cases pin down its exact
+// arithmetic (rounding up, per-message overhead, multi-byte runes) to catch
+// accidental changes.
+func TestEstimateTokens(t *testing.T) {
+	testCases := []struct {
+		name     string
+		messages []Message
+		expected int
+	}{
+		{
+			name:     "No messages",
+			messages: nil,
+			expected: 0,
+		},
+		{
+			name:     "Empty content only costs the overhead",
+			messages: []Message{{Role: "user", Content: ""}},
+			expected: 4,
+		},
+		{
+			name:     "Exact multiple of four runes",
+			messages: []Message{{Role: "user", Content: "abcdefgh"}},
+			expected: 2 + 4,
+		},
+		{
+			name:     "Partial token is rounded up",
+			messages: []Message{{Role: "user", Content: "Hello"}},
+			expected: 2 + 4,
+		},
+		{
+			// "Привіт" is 6 runes but 12 bytes; the estimate must count runes.
+			name:     "Multi-byte runes are counted once",
+			messages: []Message{{Role: "user", Content: "Привіт"}},
+			expected: 2 + 4,
+		},
+		{
+			name: "Multiple messages are summed",
+			messages: []Message{
+				{Role: "system", Content: "You are a helpful assistant."}, // 28 runes -> 7
+				{Role: "user", Content: "What is 2+2?"},                   // 12 runes -> 3
+			},
+			expected: 7 + 4 + 3 + 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, EstimateTokens(tc.messages))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnreachable is returned when the LLM backend cannot be connected to, or
+// the connection drops while a response is streamed.
+var ErrUnreachable = errors.New("LLM backend unreachable")
+
+// unreachable marks a transport error as ErrUnreachable. An error caused by the
+// caller cancelling the request is returned unchanged.
+func unreachable(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUnreachable, err)
+}
+
+// cancelOnClose releases the context of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// sleep waits for d and reports false if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// brokenStreamReader remembers the error that ended a response body early.
+type brokenStreamReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *brokenStreamReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// maxStreamLineBytes bounds a single line of a streamed response. A line can be
+// far longer than the scanner's default of 64 KiB, e.g. a chunk with a large
+// block of generated text; the buffer only grows as needed.
+const maxStreamLineBytes = 64 << 20
+
+// newStreamScanner returns a line scanner over a streamed response body. A last
+// line that was cut off by a broken connection is dropped instead of being
+// returned half-way, where it would only fail to decode. The returned function
+// reports the scanner's error, with a broken connection as ErrUnreachable.
+func newStreamScanner(ctx context.Context, body io.Reader) (*bufio.Scanner, func() error) {
+	r := &brokenStreamReader{r: body}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && r.err != nil && bytes.IndexByte(data, '\n') < 0 {
+			return len(data), nil, nil
+		}
+		return bufio.ScanLines(data, atEOF)
+	})
+	return scanner, func() error {
+		if r.err != nil {
+			return unreachable(ctx, r.err)
+		}
+		return scanner.Err()
+	}
+}
+
+// logLine shortens a line of a stream for a log message, as a line can be
+// megabytes long.
+func logLine(line []byte) string {
+	const maxLen = 200
+	if len(line) <= maxLen {
+		return string(line)
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", line[:maxLen], len(line)-maxLen)
+}
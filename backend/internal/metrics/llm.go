@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// instrumentedProvider records the calls of an LLMProvider. Calls that are not
+// about a model, such as Version or CheckBlob, are passed through unrecorded.
+type instrumentedProvider struct {
+	llm.LLMProvider
+	m *Metrics
+}
+
+// InstrumentLLM wraps next so that its model calls are recorded in m.
+func InstrumentLLM(next llm.LLMProvider, m *Metrics) llm.LLMProvider {
+	return &instrumentedProvider{LLMProvider: next, m: m}
+}
+
+// observe records a finished call.
+func (p *instrumentedProvider) observe(ctx context.Context, method, model string, started time.Time, err error) {
+	p.m.llmDuration.WithLabelValues(method, model).Observe(time.Since(started).Seconds())
+	p.m.llmRequests.WithLabelValues(method, model, outcome(ctx, err)).Inc()
+}
+
+func outcome(ctx context.Context, err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case ctx.Err() != nil:
+		return OutcomeCancelled
+	default:
+		return OutcomeError
+	}
+}
+
+// observeStats records the token counts of a finished generation.
+func (p *instrumentedProvider) observeStats(model string, stats *llm.GenerationStats) {
+	if stats == nil {
+		return
+	}
+	p.m.llmPromptTokens.WithLabelValues(model).Add(float64(stats.PromptEvalCount))
+	p.m.llmEvalTokens.WithLabelValues(model).Add(float64(stats.EvalCount))
+	p.m.llmEvalDuration.WithLabelValues(model).Add(time.Duration(stats.EvalDuration).Seconds())
+}
+
+func (p *instrumentedProvider) Generate(ctx context.Context, req *llm.GenerateRequest) (*llm.GenerateResponse, error) {
+	p.m.activeGenerations.Inc()
+	defer p.m.activeGenerations.Dec()
+	started := time.Now()
+	resp, err := p.LLMProvider.Generate(ctx, req)
+	p.observe(ctx, "generate", req.Model, started, err)
+	if err == nil {
+		p.observeStats(req.Model, resp.Stats)
+	}
+	return resp, err
+}
+
+// GenerateStream forwards the chunks of the wrapped stream to ch, timing the
+// first token on the way. A chunk that carries an error makes the call count as
+// failed, as providers report some failures only within the stream.
+func (p *instrumentedProvider) GenerateStream(ctx context.Context, req *llm.GenerateRequest, ch chan<- llm.StreamResponse) error {
+	p.m.activeGenerations.Inc()
+	defer p.m.activeGenerations.Dec()
+	started := time.Now()
+
+	inner := make(chan llm.StreamResponse)
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.LLMProvider.GenerateStream(ctx, req, inner) }()
+
+	firstToken := false
+	streamFailed := false
+	for chunk := range inner {
+		if !firstToken && chunk.Content != "" {
+			firstToken = true
+			p.m.llmFirstToken.WithLabelValues(req.Model).Observe(time.Since(started).Seconds())
+		}
+		if chunk.Error != "" {
+			streamFailed = true
+		}
+		if chunk.Done {
+			p.observeStats(req.Model, chunk.Stats)
+		}
+		// The wrapped provider stops on its own once ctx is cancelled; until
+		// then its chunks are drained, so that it never blocks.
+		select {
+		case ch <- chunk:
+		case <-ctx.Done():
+		}
+	}
+	close(ch)
+
+	err := <-errCh
+	result := err
+	if result == nil && streamFailed {
+		result = errStreamFailed
+	}
+	p.observe(ctx, "generate_stream", req.Model, started, result)
+	return err
+}
+
+// errStreamFailed stands for an error that was sent within a stream.
+var errStreamFailed = errors.New("stream failed")
+
+func (p *instrumentedProvider) Embeddings(ctx context.Context, req *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error) {
+	started := time.Now()
+	resp, err := p.LLMProvider.Embeddings(ctx, req)
+	p.observe(ctx, "embeddings", req.Model, started, err)
+	return resp, err
+}
+
+func (p *instrumentedProvider) ListModels(ctx context.Context) (*llm.ListModelsResponse, error) {
+	started := time.Now()
+	resp, err := p.LLMProvider.ListModels(ctx)
+	p.observe(ctx, "list_models", "", started, err)
+	return resp, err
+}
+
+func (p *instrumentedProvider) ShowModelInfo(ctx context.Context, req *llm.ShowModelRequest) (*llm.ModelInfo, error) {
+	started := time.Now()
+	resp, err := p.LLMProvider.ShowModelInfo(ctx, req)
+	p.observe(ctx, "show_model", req.Name, started, err)
+	return resp, err
+}
+
+func (p *instrumentedProvider) PullModel(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error {
+	started := time.Now()
+	err := p.LLMProvider.PullModel(ctx, req, ch)
+	p.observe(ctx, "pull_model", req.Name, started, err)
+	return err
+}
@@ -0,0 +1,120 @@
+// Package metrics exports Prometheus metrics of the LLM calls, the HTTP
+// requests and the database queries at /metrics.
+//
+// Tokens per second, e.g. in Grafana, are the rate of the generated tokens
+// divided by the rate of the generation time:
+//
+//	rate(flowai_llm_eval_tokens_total[5m]) / rate(flowai_llm_eval_duration_seconds_total[5m])
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric.
+const namespace = "flowai"
+
+// Outcomes of an LLM call, the values of the "outcome" label.
+const (
+	OutcomeSuccess   = "success"
+	OutcomeError     = "error"
+	OutcomeCancelled = "cancelled"
+)
+
+// Metrics holds the collectors of the application. Each instance has its own
+// registry, so that tests can create as many as they like.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	llmRequests       *prometheus.CounterVec
+	llmDuration       *prometheus.HistogramVec
+	llmFirstToken     *prometheus.HistogramVec
+	llmPromptTokens   *prometheus.CounterVec
+	llmEvalTokens     *prometheus.CounterVec
+	llmEvalDuration   *prometheus.CounterVec
+	activeGenerations prometheus.Gauge
+
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+}
+
+// New creates the collectors and registers them, together with the Go runtime
+// and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		llmRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_requests_total",
+			Help:      "LLM calls by method, model and outcome.",
+		}, []string{"method", "model", "outcome"}),
+		llmDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_request_duration_seconds",
+			Help:      "Duration of LLM calls; for a stream, until its last chunk.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"method", "model"}),
+		llmFirstToken: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_time_to_first_token_seconds",
+			Help:      "Time from the start of a streamed generation to its first token.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"model"}),
+		llmPromptTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_prompt_tokens_total",
+			Help:      "Prompt tokens evaluated by generations.",
+		}, []string{"model"}),
+		llmEvalTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_eval_tokens_total",
+			Help:      "Tokens generated by generations.",
+		}, []string{"model"}),
+		llmEvalDuration: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_eval_duration_seconds_total",
+			Help:      "Time spent generating tokens, as reported by the provider.",
+		}, []string{"model"}),
+		activeGenerations: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "llm_active_generations",
+			Help:      "Generations in progress.",
+		}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of database statements by their leading keyword.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}, []string{"statement"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.llmRequests, m.llmDuration, m.llmFirstToken,
+		m.llmPromptTokens, m.llmEvalTokens, m.llmEvalDuration, m.activeGenerations,
+		m.httpDuration, m.dbDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveQuery records the duration of a database statement. It matches
+// database.QueryObserver.
+func (m *Metrics) ObserveQuery(statement string, duration time.Duration) {
+	m.dbDuration.WithLabelValues(statement).Observe(duration.Seconds())
+}
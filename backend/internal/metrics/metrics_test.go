@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// failingProvider fails every generation.
+type failingProvider struct {
+	llm.LLMProvider
+}
+
+func (failingProvider) Generate(ctx context.Context, req *llm.GenerateRequest) (*llm.GenerateResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func (failingProvider) GenerateStream(ctx context.Context, req *llm.GenerateRequest, ch chan<- llm.StreamResponse) error {
+	defer close(ch)
+	ch <- llm.StreamResponse{Error: "model crashed", Done: true}
+	return nil
+}
+
+// TestInstrumentLLM verifies that generations are counted by outcome, and that
+// the token counts and the time to first token of a stream are recorded.
+func TestInstrumentLLM(t *testing.T) {
+	ctx := context.Background()
+	numPredict := 3
+	req := &llm.GenerateRequest{Model: "fake-chat:latest", Prompt: "one two", Options: &llm.RequestOptions{NumPredict: &numPredict}}
+
+	t.Run("Records a streamed generation", func(t *testing.T) {
+		// ARRANGE
+		m := New()
+		provider := InstrumentLLM(llm.NewFakeProvider(llm.FakeConfig{}), m)
+		ch := make(chan llm.StreamResponse)
+
+		// ACT
+		errCh := make(chan error, 1)
+		go func() { errCh <- provider.GenerateStream(ctx, req, ch) }()
+		var content string
+		for chunk := range ch {
+			content += chunk.Content
+		}
+
+		// ASSERT: The chunks pass through unchanged and ch is closed.
+		require.NoError(t, <-errCh)
+		assert.Equal(t, "Lorem ipsum dolor", content)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("generate_stream", "fake-chat:latest", OutcomeSuccess)))
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.llmPromptTokens.WithLabelValues("fake-chat:latest")))
+		assert.Equal(t, 3.0, testutil.ToFloat64(m.llmEvalTokens.WithLabelValues("fake-chat:latest")))
+		assert.Equal(t, 1, testutil.CollectAndCount(m.llmFirstToken))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.activeGenerations))
+	})
+
+	t.Run("Records failed generations", func(t *testing.T) {
+		// ARRANGE
+		m := New()
+		provider := InstrumentLLM(failingProvider{}, m)
+
+		// ACT
+		_, err := provider.Generate(ctx, req)
+		require.Error(t, err)
+		ch := make(chan llm.StreamResponse, 1)
+		require.NoError(t, provider.GenerateStream(ctx, req, ch))
+
+		// ASSERT: An error within the stream is a failure as well.
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("generate", "fake-chat:latest", OutcomeError)))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("generate_stream", "fake-chat:latest", OutcomeError)))
+		assert.Equal(t, 0, testutil.CollectAndCount(m.llmFirstToken))
+	})
+
+	t.Run("Records a cancelled stream", func(t *testing.T) {
+		// ARRANGE
+		m := New()
+		provider := InstrumentLLM(llm.NewFakeProvider(llm.FakeConfig{TokensPerSecond: 1000}), m)
+		ctx, cancel := context.WithCancel(ctx)
+		ch := make(chan llm.StreamResponse)
+
+		// ACT: The client reads one chunk and goes away.
+		errCh := make(chan error, 1)
+		go func() { errCh <- provider.GenerateStream(ctx, req, ch) }()
+		<-ch
+		cancel()
+
+		// ASSERT
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(5 * time.Second):
+			t.Fatal("GenerateStream did not return after the context was cancelled")
+		}
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("generate_stream", "fake-chat:latest", OutcomeCancelled)))
+	})
+}
+
+// TestMiddleware verifies that requests are recorded by their route pattern,
+// not by their path.
+func TestMiddleware(t *testing.T) {
+	// ARRANGE
+	m := New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/chats/{chatID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Handle("/metrics", m.Handler())
+
+	// ACT
+	for _, path := range []string{"/chats/1", "/chats/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// ASSERT
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration), "one series per route and status")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	body, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `flowai_http_request_duration_seconds_count{method="GET",route="/chats/{chatID}",status="418"} 2`)
+	assert.Contains(t, string(body), `flowai_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -12,32 +12,241 @@ type Chat struct {
 	CreatedAt time.Time `json:"created_at" example:"2025-09-08T14:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"2025-09-08T14:05:00Z"`
 	Model     string    `json:"model" example:"qwen:0.5b"`
+	// SystemPrompt overrides the global system prompt for this chat when set.
+	SystemPrompt string `json:"system_prompt,omitempty" example:"You are a senior Go developer."`
+	// CollectionID, if set, enables retrieval from the given document collection.
+	CollectionID string `json:"collection_id,omitempty" example:"0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"`
+	// Pinned chats are listed before all others.
+	Pinned bool `json:"pinned" example:"false"`
+	// Tags group chats by topic, sorted alphabetically. They are filled in chat listings.
+	Tags []string `json:"tags,omitempty" example:"work,golang"`
+	// UserID is the owner of the chat. In the current single-user model every chat
+	// belongs to the configured default user.
+	UserID string `json:"-"`
+}
+
+// ChatListOptions controls the ordering and filtering of a chat listing.
+// The zero value lists all chats, most recently updated first.
+type ChatListOptions struct {
+	// SortBy is one of "created_at", "updated_at" (default) or "title".
+	SortBy string
+	// Order is either "asc" or "desc" (default).
+	Order string
+	// Model, if set, limits the listing to chats that used the given model.
+	Model string
+	// Tag, if set, limits the listing to chats with the given tag.
+	Tag string
+}
+
+// ChatPruneOptions selects the chats removed by a retention run. Pinned chats
+// are never selected; a zero criterion is disabled.
+type ChatPruneOptions struct {
+	// UpdatedBefore selects chats that were not updated since this time.
+	UpdatedBefore time.Time
+	// KeepNewest selects all but the KeepNewest most recently updated unpinned chats.
+	KeepNewest int
+	// Limit caps the number of chats deleted in one batch.
+	Limit int
+}
+
+// CheckpointResult is the outcome of a WAL checkpoint, as reported by SQLite.
+type CheckpointResult struct {
+	// Busy is true if the checkpoint could not complete because of concurrent
+	// readers or writers; it is retried on the next run.
+	Busy bool `json:"busy" example:"false"`
+	// LogFrames is the number of frames in the WAL file before the checkpoint.
+	LogFrames int `json:"log_frames" example:"1532"`
+	// CheckpointedFrames is the number of frames written back to the database.
+	CheckpointedFrames int `json:"checkpointed_frames" example:"1532"`
+}
+
+// GlobalStats are aggregate counts over all chats, e.g. for an admin dashboard.
+type GlobalStats struct {
+	Chats    int `json:"chats" example:"42"`
+	Messages int `json:"messages" example:"1250"`
+	// ActiveMessages are on the current branch of their chat; InactiveMessages
+	// are on branches that were regenerated or edited away.
+	ActiveMessages   int `json:"active_messages" example:"1100"`
+	InactiveMessages int `json:"inactive_messages" example:"150"`
+	// MessagesByModel counts the messages of each model, the most used first.
+	MessagesByModel []ModelMessageCount `json:"messages_by_model"`
+}
+
+// ModelMessageCount is the number of messages generated by a model.
+type ModelMessageCount struct {
+	Model    string `json:"model" example:"qwen3:8b"`
+	Messages int    `json:"messages" example:"600"`
 }
 
 // Message stores a single message in a chat.
 type Message struct {
-	ID        string          `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
-	ParentID  *string         `json:"parent_id,omitempty" example:"f0e9d8c7-b6a5-4321-fedc-ba9876543210"`
+	ID       string  `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	ParentID *string `json:"parent_id,omitempty" example:"f0e9d8c7-b6a5-4321-fedc-ba9876543210"`
+	// ChatID is the chat the message belongs to. It is only set when a single
+	// message is looked up by its ID.
+	ChatID    string          `json:"-"`
 	Role      string          `json:"role" example:"assistant"`
 	Content   string          `json:"content" example:"The Roman Empire fell in 476 AD."`
 	Model     *string         `json:"model,omitempty" example:"qwen:0.5b"`
 	Timestamp time.Time       `json:"timestamp" example:"2025-09-08T14:05:00Z"`
 	IsActive  bool            `json:"is_active"`
 	Metadata  json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
-	Context   json.RawMessage `json:"-"`
+	// Attachments are references to binary files (e.g. images) sent with the message.
+	// Their content is served by a separate endpoint.
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
-// FullChat includes the chat metadata and all its messages.
+// Attachment is a binary file attached to a message, such as an image for a vision model.
+type Attachment struct {
+	ID        string    `json:"id" example:"9f8e7d6c-5b4a-3210-fedc-ba9876543210"`
+	MessageID string    `json:"-"`
+	MimeType  string    `json:"mime_type" example:"image/png"`
+	SizeBytes int64     `json:"size_bytes" example:"48213"`
+	CreatedAt time.Time `json:"created_at" example:"2025-09-08T14:05:00Z"`
+	// Data holds the raw content. It is only loaded when needed and never serialized.
+	Data []byte `json:"-"`
+}
+
+// IdempotencyRecord maps the idempotency key of a processed message request to
+// the assistant message it produced.
+type IdempotencyRecord struct {
+	Key       string
+	ChatID    string
+	MessageID string
+	CreatedAt time.Time
+}
+
+// FullChat includes the chat metadata and the most recent page of its active
+// messages in chronological order.
 type FullChat struct {
 	Chat
 	Messages []Message `json:"messages"`
+	// HasMore is true if older messages exist; fetch them with `GET /chats/{chatID}/messages`.
+	HasMore bool `json:"has_more"`
+}
+
+// ChatTree is a chat with all its messages, including inactive branches. Depending
+// on the request, the messages are either a flat list or nested under `roots`.
+type ChatTree struct {
+	Chat
+	Messages []Message     `json:"messages"`
+	Roots    []MessageNode `json:"roots,omitempty"`
+}
+
+// MessageNode is a message together with the replies branching off it, oldest first.
+type MessageNode struct {
+	Message
+	Children []MessageNode `json:"children"`
+}
+
+// ChatTreeOptions limits the part of a chat tree that is returned.
+type ChatTreeOptions struct {
+	// Nested returns the messages as a tree instead of a flat list.
+	Nested bool
+	// Since skips messages created before it. Messages whose parent is skipped
+	// become roots.
+	Since *time.Time
+	// Depth limits the number of levels below (and including) the roots; 0 means unlimited.
+	Depth int
+}
+
+// MessagePage is a page of a chat's active messages, newest first.
+type MessagePage struct {
+	Messages []Message `json:"messages"`
+	// HasMore is true if messages older than the last one of this page exist.
+	HasMore bool `json:"has_more"`
 }
 
 // StreamResponse is the structure for a single chunk in a streaming response.
 type StreamResponse struct {
-	ChatID  string          `json:"chat_id,omitempty"`
-	Content string          `json:"content" example:"Hello"`
-	Done    bool            `json:"done" example:"false"`
-	Context json.RawMessage `json:"context,omitempty" swaggertype:"object"`
-	Error   string          `json:"error,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	Content string `json:"content" example:"Hello"`
+	// Reasoning carries the model's <think> output, if the client asked to see it.
+	Reasoning string `json:"reasoning,omitempty" example:"The user greets me, so I greet back."`
+	Done      bool   `json:"done" example:"false"`
+	Error     string `json:"error,omitempty"`
+	// Warning reports a problem with a completed answer that did not stop the
+	// stream, e.g. invalid JSON in JSON mode.
+	Warning string `json:"warning,omitempty"`
+	// MessageID and ReplacedMessageID are set in the first event of a
+	// regeneration: the ID the new answer will be stored with, and the ID of the
+	// answer it replaces, which stays available as an inactive branch.
+	MessageID         string `json:"message_id,omitempty" example:"8f14e45f-ceea-467a-9b36-8a2f1c6d5e7b"`
+	ReplacedMessageID string `json:"replaced_message_id,omitempty" example:"1f0e3dad-9990-4c45-8f2b-4a3f5c6d7e8f"`
+	// Model tags the events of a comparison with the model whose answer they
+	// belong to, as the answers of several models are interleaved.
+	Model string `json:"model,omitempty" example:"qwen3:8b"`
+	// Phase is set by an event that reports the progress of an answer:
+	// "queued" while it waits for other generations to finish, "loading" while
+	// the model loads and reads the prompt, and "generating" once the first
+	// token has arrived.
+	Phase string `json:"phase,omitempty" example:"loading" enums:"queued,loading,generating"`
+}
+
+// The phases of a streamed answer, see StreamResponse.Phase.
+const (
+	PhaseQueued     = "queued"
+	PhaseLoading    = "loading"
+	PhaseGenerating = "generating"
+)
+
+// Collection groups documents that can be retrieved from during a chat.
+type Collection struct {
+	ID   string `json:"id" example:"0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"`
+	Name string `json:"name" example:"Project docs"`
+	// EmbeddingModel is the model used for all chunks of the collection; vectors
+	// from different models are not comparable.
+	EmbeddingModel string    `json:"embedding_model" example:"nomic-embed-text"`
+	CreatedAt      time.Time `json:"created_at" example:"2025-09-08T14:00:00Z"`
+}
+
+// Document is a text uploaded to a collection.
+type Document struct {
+	ID           string    `json:"id" example:"5e4d3c2b-1a0f-9e8d-7c6b-5a4f3e2d1c0b"`
+	CollectionID string    `json:"collection_id" example:"0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"`
+	Name         string    `json:"name" example:"README.md"`
+	ChunkCount   int       `json:"chunk_count" example:"12"`
+	CreatedAt    time.Time `json:"created_at" example:"2025-09-08T14:00:00Z"`
+}
+
+// Prompt is a reusable prompt template. Its body may contain `{{variable}}`
+// placeholders that are filled in when a message is created from it.
+type Prompt struct {
+	ID          string `json:"id" example:"7c6b5a4f-3e2d-1c0b-9a8f-7e6d5c4b3a29"`
+	Name        string `json:"name" example:"Code review"`
+	Description string `json:"description,omitempty" example:"Reviews a diff for bugs and style issues."`
+	Body        string `json:"body" example:"Review the following {{language}} code:"`
+	// Variables lists the placeholders of the body. It is derived, not stored.
+	Variables []string  `json:"variables" example:"language"`
+	CreatedAt time.Time `json:"created_at" example:"2025-09-08T14:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-09-08T14:05:00Z"`
+}
+
+// ModelDefaults are the stored default generation options of a model.
+type ModelDefaults struct {
+	Model string
+	// Options is the JSON of the options; it is decoded by the service layer.
+	Options   json.RawMessage
+	UpdatedAt time.Time
+}
+
+// ModelBenchmark is the result of benchmarking a model: the medians over all
+// runs of the generation speed, the time to load the model and the time until
+// the first token.
+type ModelBenchmark struct {
+	Model           string    `json:"model" example:"gemma3:4b"`
+	Runs            int       `json:"runs" example:"3"`
+	TokensPerSecond float64   `json:"tokens_per_second" example:"42.5"`
+	LoadMs          float64   `json:"load_ms" example:"12.3"`
+	FirstTokenMs    float64   `json:"first_token_ms" example:"85.1"`
+	CreatedAt       time.Time `json:"created_at" example:"2025-09-08T14:05:00Z"`
+}
+
+// DocumentChunk is a piece of a document together with its embedding vector.
+type DocumentChunk struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	Index      int       `json:"index"`
+	Content    string    `json:"content"`
+	Embedding  []float32 `json:"-"`
 }
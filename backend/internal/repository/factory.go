@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"flow-ai/backend/internal/database"
+)
+
+// The storage backends. BackendSQLite keeps everything in a SQLite file and is
+// the default. BackendMemory keeps everything in memory, so that a demo server
+// runs without a data volume; all data is lost when it stops.
+const (
+	BackendSQLite = "sqlite"
+	BackendMemory = "memory"
+)
+
+// StorageConfig selects and configures a storage backend.
+type StorageConfig struct {
+	// Backend names the implementation, "sqlite" or "memory"; empty selects SQLite.
+	Backend string
+	// DatabasePath is the file of the SQLite backend.
+	DatabasePath string
+	// Database holds the connection pool settings of the SQLite backend.
+	Database database.Config
+}
+
+// Storage is an opened storage backend.
+type Storage struct {
+	Repository Repository
+	// DB is the SQL database behind the repository. The settings, the
+	// migrations and the health checks use it directly; closing it closes the
+	// storage.
+	DB *sql.DB
+}
+
+// ValidateBackend reports whether a backend name is known, so that a typo can
+// fail the startup before anything is opened.
+func ValidateBackend(name string) error {
+	switch normalizeBackend(name) {
+	case BackendSQLite, BackendMemory:
+		return nil
+	default:
+		return fmt.Errorf("invalid storage backend %q: must be %q or %q", name, BackendSQLite, BackendMemory)
+	}
+}
+
+// OpenStorage connects to the configured backend, prepares its schema and
+// checks that it answers.
+func OpenStorage(cfg StorageConfig) (*Storage, error) {
+	if err := ValidateBackend(cfg.Backend); err != nil {
+		return nil, err
+	}
+	if normalizeBackend(cfg.Backend) == BackendMemory {
+		return openMemoryStorage(cfg.Database)
+	}
+	db, err := database.InitDB(cfg.DatabasePath, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("storage backend %q is not reachable: %w", BackendSQLite, err)
+	}
+	return &Storage{Repository: NewSQLiteRepository(db), DB: db}, nil
+}
+
+// openMemoryStorage opens the memory backend. The settings are kept in an
+// in-memory SQLite database, as the settings service works on SQL directly.
+func openMemoryStorage(dbConfig database.Config) (*Storage, error) {
+	// Every connection to ":memory:" opens a database of its own, so the pool is
+	// limited to one connection that is never closed.
+	db, err := database.InitDB(":memory:", database.Config{
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+		ObserveQuery: dbConfig.ObserveQuery,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{Repository: NewMemoryRepository(), DB: db}, nil
+}
+
+func normalizeBackend(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return BackendSQLite
+	}
+	return name
+}
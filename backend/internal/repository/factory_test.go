@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// TestOpenStorage verifies that the backend is selected by name, with SQLite as
+// the default, and that an unknown name is rejected before anything is opened.
+func TestOpenStorage(t *testing.T) {
+	for _, backend := range []string{"", "sqlite", " SQLite "} {
+		t.Run("Opens SQLite for "+backend, func(t *testing.T) {
+			// ARRANGE
+			path := filepath.Join(t.TempDir(), "test.db")
+
+			// ACT
+			storage, err := repository.OpenStorage(repository.StorageConfig{Backend: backend, DatabasePath: path})
+
+			// ASSERT: The schema is migrated and the repository works.
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = storage.DB.Close() })
+			chats, err := storage.Repository.GetChats(context.Background(), model.ChatListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, chats)
+		})
+	}
+
+	t.Run("Opens the memory backend", func(t *testing.T) {
+		// ARRANGE
+		path := filepath.Join(t.TempDir(), "test.db")
+
+		// ACT
+		storage, err := repository.OpenStorage(repository.StorageConfig{Backend: "memory", DatabasePath: path})
+
+		// ASSERT: The settings get a migrated in-memory database; no file is written.
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = storage.DB.Close() })
+		ctx := context.Background()
+		require.NoError(t, storage.Repository.CreateChat(ctx, &model.Chat{ID: "c1", Title: "Demo"}))
+		chat, err := storage.Repository.GetChat(ctx, "c1")
+		require.NoError(t, err)
+		assert.Equal(t, "Demo", chat.Title)
+		var settings int
+		require.NoError(t, storage.DB.QueryRow("SELECT COUNT(*) FROM settings").Scan(&settings))
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("Rejects an unknown backend", func(t *testing.T) {
+		// ARRANGE
+		dir := t.TempDir()
+
+		// ACT
+		storage, err := repository.OpenStorage(repository.StorageConfig{Backend: "redis", DatabasePath: filepath.Join(dir, "sub", "test.db")})
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid storage backend "redis"`)
+		assert.Nil(t, storage)
+		assert.NoDirExists(t, filepath.Join(dir, "sub"), "nothing is created for an unknown backend")
+	})
+}
@@ -0,0 +1,1039 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"flow-ai/backend/internal/model"
+)
+
+// memoryMessage is a stored message. `seq` numbers all messages in insertion
+// order, like the `seq` column of the SQLite schema.
+type memoryMessage struct {
+	msg model.Message
+	seq int64
+}
+
+// memoryRepository keeps everything in maps guarded by a single mutex. It
+// behaves like the SQLite repository, including the branch semantics of
+// regeneration, so that service tests and a demo server can run without a
+// database file.
+//
+// Transactions are journals of undo steps: the `*sql.Tx` returned by BeginTx
+// belongs to a private driver that runs no SQL, and rolling it back undoes the
+// writes made through the Tx methods. Transactions are not isolated from each
+// other; writes are visible as soon as they are made.
+type memoryRepository struct {
+	mu sync.Mutex
+
+	seq           int64
+	chats         map[string]*model.Chat
+	tags          map[string][]string
+	messages      map[string]*memoryMessage
+	attachments   map[string][]model.Attachment // by message ID
+	idempotency   map[string]model.IdempotencyRecord
+	collections   map[string]*model.Collection
+	documents     map[string]*model.Document
+	chunks        map[string][]model.DocumentChunk // by collection ID
+	prompts       map[string]*model.Prompt
+	modelDefaults map[string]model.ModelDefaults
+	benchmarks    map[string]model.ModelBenchmark
+
+	txDB *sql.DB
+	txs  map[*sql.Tx]*memoryTx
+}
+
+// NewMemoryRepository creates an empty repository that keeps its data in
+// memory. Everything is lost when the process exits.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		chats:         map[string]*model.Chat{},
+		tags:          map[string][]string{},
+		messages:      map[string]*memoryMessage{},
+		attachments:   map[string][]model.Attachment{},
+		idempotency:   map[string]model.IdempotencyRecord{},
+		collections:   map[string]*model.Collection{},
+		documents:     map[string]*model.Document{},
+		chunks:        map[string][]model.DocumentChunk{},
+		prompts:       map[string]*model.Prompt{},
+		modelDefaults: map[string]model.ModelDefaults{},
+		benchmarks:    map[string]model.ModelBenchmark{},
+		txDB:          sql.OpenDB(memoryConnector{}),
+		txs:           map[*sql.Tx]*memoryTx{},
+	}
+}
+
+// --- Transactions ---
+
+// memoryTxKey passes the journal of a starting transaction to the driver.
+type memoryTxKey struct{}
+
+// memoryTx is the journal of an open transaction.
+type memoryTx struct {
+	repo  *memoryRepository
+	sqlTx *sql.Tx
+	undo  []func()
+	done  bool
+}
+
+func (t *memoryTx) Commit() error {
+	t.repo.finishTx(t, false)
+	return nil
+}
+
+func (t *memoryTx) Rollback() error {
+	t.repo.finishTx(t, true)
+	return nil
+}
+
+// BeginTx starts a transaction whose writes are undone if it is rolled back.
+func (r *memoryRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	t := &memoryTx{repo: r}
+	tx, err := r.txDB.BeginTx(context.WithValue(ctx, memoryTxKey{}, t), nil)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// database/sql rolls the transaction back by itself if ctx is cancelled,
+	// which may already have happened.
+	if !t.done {
+		t.sqlTx = tx
+		r.txs[tx] = t
+	}
+	return tx, nil
+}
+
+func (r *memoryRepository) finishTx(t *memoryTx, rollback bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.done = true
+	if rollback {
+		for i := len(t.undo) - 1; i >= 0; i-- {
+			t.undo[i]()
+		}
+	}
+	t.undo = nil
+	if t.sqlTx != nil {
+		delete(r.txs, t.sqlTx)
+	}
+}
+
+// openTx returns the journal of tx, or `sql.ErrTxDone` if tx was committed,
+// rolled back or not started by this repository. The caller holds `r.mu`.
+func (r *memoryRepository) openTx(tx *sql.Tx) (*memoryTx, error) {
+	t, ok := r.txs[tx]
+	if !ok {
+		return nil, sql.ErrTxDone
+	}
+	return t, nil
+}
+
+// memoryConnector and memoryConn form the driver behind the transactions of
+// the memory repository. A connection only begins transactions; it runs no SQL.
+type memoryConnector struct{}
+
+func (memoryConnector) Connect(context.Context) (driver.Conn, error) { return memoryConn{}, nil }
+func (c memoryConnector) Driver() driver.Driver                      { return c }
+func (memoryConnector) Open(string) (driver.Conn, error)             { return memoryConn{}, nil }
+
+type memoryConn struct{}
+
+var errMemoryNoSQL = errors.New("repository: the memory backend does not run SQL")
+
+func (memoryConn) Prepare(string) (driver.Stmt, error) { return nil, errMemoryNoSQL }
+func (memoryConn) Close() error                        { return nil }
+func (memoryConn) Begin() (driver.Tx, error)           { return nil, errMemoryNoSQL }
+
+func (memoryConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	t, ok := ctx.Value(memoryTxKey{}).(*memoryTx)
+	if !ok {
+		return nil, errMemoryNoSQL
+	}
+	return t, nil
+}
+
+// --- Chat Methods ---
+
+func (r *memoryRepository) CreateChat(ctx context.Context, chat *model.Chat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.createChat(chat)
+}
+
+// createChat stores a copy of chat. The caller holds `r.mu`.
+func (r *memoryRepository) createChat(chat *model.Chat) error {
+	if _, ok := r.chats[chat.ID]; ok {
+		return fmt.Errorf("repository: chat %s already exists", chat.ID)
+	}
+	stored := *chat
+	stored.Pinned = false
+	stored.Tags = nil
+	r.chats[chat.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetChat(ctx context.Context, chatID string) (*model.Chat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chat, ok := r.chats[chatID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *chat
+	return &c, nil
+}
+
+func (r *memoryRepository) GetChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var chats []*model.Chat
+	for _, chat := range r.chats {
+		if opts.Model != "" && chat.Model != opts.Model && !r.chatUsedModel(chat.ID, opts.Model) {
+			continue
+		}
+		if opts.Tag != "" && !slices.Contains(r.tags[chat.ID], opts.Tag) {
+			continue
+		}
+		c := *chat
+		c.Tags = slices.Clone(r.tags[chat.ID])
+		chats = append(chats, &c)
+	}
+
+	compare := func(a, b *model.Chat) int {
+		switch opts.SortBy {
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case "title":
+			return cmp.Compare(a.Title, b.Title)
+		default:
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+	}
+	slices.SortFunc(chats, func(a, b *model.Chat) int {
+		// Pinned chats always come first; the requested order applies within each group.
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		c := compare(a, b)
+		if opts.Order != "asc" {
+			c = -c
+		}
+		return cmp.Or(c, cmp.Compare(a.ID, b.ID))
+	})
+	return chats, nil
+}
+
+// chatUsedModel reports whether any message of a chat was generated by
+// modelName. The caller holds `r.mu`.
+func (r *memoryRepository) chatUsedModel(chatID, modelName string) bool {
+	for _, m := range r.messages {
+		if m.msg.ChatID == chatID && m.msg.Model != nil && *m.msg.Model == modelName {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryRepository) UpdateChatTitle(ctx context.Context, chatID, newTitle string) error {
+	return r.updateChat(chatID, func(chat *model.Chat) {
+		chat.Title = newTitle
+		chat.UpdatedAt = time.Now().UTC()
+	})
+}
+
+func (r *memoryRepository) UpdateChatCollection(ctx context.Context, chatID, collectionID string) error {
+	return r.updateChat(chatID, func(chat *model.Chat) {
+		chat.CollectionID = collectionID
+		chat.UpdatedAt = time.Now().UTC()
+	})
+}
+
+// UpdateChatPinned pins or unpins a chat without changing its `updated_at`.
+func (r *memoryRepository) UpdateChatPinned(ctx context.Context, chatID string, pinned bool) error {
+	return r.updateChat(chatID, func(chat *model.Chat) {
+		chat.Pinned = pinned
+	})
+}
+
+func (r *memoryRepository) updateChat(chatID string, update func(chat *model.Chat)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chat, ok := r.chats[chatID]
+	if !ok {
+		return ErrNotFound
+	}
+	update(chat)
+	return nil
+}
+
+// SetChatTags replaces the tags of a chat.
+func (r *memoryRepository) SetChatTags(ctx context.Context, chatID string, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.chats[chatID]; !ok {
+		return ErrNotFound
+	}
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	if len(sorted) == 0 {
+		delete(r.tags, chatID)
+	} else {
+		r.tags[chatID] = slices.Compact(sorted)
+	}
+	return nil
+}
+
+// DeleteChat deletes a chat together with its messages, attachments and tags.
+func (r *memoryRepository) DeleteChat(ctx context.Context, chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.deleteChat(chatID) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryRepository) DeleteChats(ctx context.Context, chatIDs []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deleted := 0
+	for _, id := range chatIDs {
+		if r.deleteChat(id) {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deleteChat deletes a chat and its dependent records and reports whether the
+// chat existed. The caller holds `r.mu`.
+func (r *memoryRepository) deleteChat(chatID string) bool {
+	if _, ok := r.chats[chatID]; !ok {
+		return false
+	}
+	for id, m := range r.messages {
+		if m.msg.ChatID == chatID {
+			delete(r.messages, id)
+			delete(r.attachments, id)
+		}
+	}
+	delete(r.tags, chatID)
+	delete(r.chats, chatID)
+	return true
+}
+
+// PruneChats deletes one batch of unpinned chats selected by opts, oldest first.
+func (r *memoryRepository) PruneChats(ctx context.Context, opts model.ChatPruneOptions) (int, error) {
+	if (opts.UpdatedBefore.IsZero() && opts.KeepNewest <= 0) || opts.Limit <= 0 {
+		return 0, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unpinned []*model.Chat
+	for _, chat := range r.chats {
+		if !chat.Pinned {
+			unpinned = append(unpinned, chat)
+		}
+	}
+	// Newest first, so that the chats kept by KeepNewest are a prefix.
+	slices.SortFunc(unpinned, func(a, b *model.Chat) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	var selected []string
+	for i := len(unpinned) - 1; i >= 0 && len(selected) < opts.Limit; i-- {
+		chat := unpinned[i]
+		stale := !opts.UpdatedBefore.IsZero() && chat.UpdatedAt.Before(opts.UpdatedBefore)
+		surplus := opts.KeepNewest > 0 && i >= opts.KeepNewest
+		if stale || surplus {
+			selected = append(selected, chat.ID)
+		}
+	}
+	for _, id := range selected {
+		r.deleteChat(id)
+	}
+	return len(selected), nil
+}
+
+// --- Message Methods ---
+
+// AddMessage stores a message and updates the timestamp of its chat.
+func (r *memoryRepository) AddMessage(ctx context.Context, message *model.Message, chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.addMessage(message, chatID); err != nil {
+		return err
+	}
+	if chat, ok := r.chats[chatID]; ok {
+		chat.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// addMessage stores a new, active message and its attachments. The caller
+// holds `r.mu`.
+func (r *memoryRepository) addMessage(message *model.Message, chatID string) error {
+	if _, ok := r.messages[message.ID]; ok {
+		return fmt.Errorf("repository: message %s already exists", message.ID)
+	}
+	stored := *message
+	stored.ChatID = chatID
+	stored.IsActive = true
+	stored.Metadata = storedMetadata(message.Metadata)
+	stored.Attachments = nil
+	if message.ParentID != nil {
+		parentID := *message.ParentID
+		stored.ParentID = &parentID
+	}
+	if message.Model != nil {
+		modelName := *message.Model
+		stored.Model = &modelName
+	}
+	r.seq++
+	r.messages[message.ID] = &memoryMessage{msg: stored, seq: r.seq}
+
+	if len(message.Attachments) > 0 {
+		attachments := make([]model.Attachment, len(message.Attachments))
+		for i, a := range message.Attachments {
+			a.MessageID = message.ID
+			a.Data = slices.Clone(a.Data)
+			attachments[i] = a
+		}
+		r.attachments[message.ID] = attachments
+	}
+	return nil
+}
+
+// storedMetadata treats empty and "null" metadata as absent, like a NULL column.
+func storedMetadata(metadata json.RawMessage) json.RawMessage {
+	if len(metadata) == 0 || string(metadata) == "null" {
+		return nil
+	}
+	return slices.Clone(metadata)
+}
+
+func (r *memoryRepository) GetMessageByID(ctx context.Context, messageID string) (*model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getMessageByID(messageID)
+}
+
+func (r *memoryRepository) GetMessageByIDTx(ctx context.Context, tx *sql.Tx, messageID string) (*model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.openTx(tx); err != nil {
+		return nil, err
+	}
+	return r.getMessageByID(messageID)
+}
+
+// getMessageByID returns a copy of a stored message including its chat ID. The
+// caller holds `r.mu`.
+func (r *memoryRepository) getMessageByID(messageID string) (*model.Message, error) {
+	m, ok := r.messages[messageID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	msg := copyMessage(m.msg)
+	msg.ChatID = m.msg.ChatID
+	return &msg, nil
+}
+
+// copyMessage returns a copy of a stored message as the list methods return
+// it: without its chat ID and attachments.
+func copyMessage(stored model.Message) model.Message {
+	msg := stored
+	msg.ChatID = ""
+	msg.Metadata = slices.Clone(stored.Metadata)
+	return msg
+}
+
+// chatMessages returns the stored messages of a chat that match keep, in
+// insertion order. The caller holds `r.mu`.
+func (r *memoryRepository) chatMessages(chatID string, keep func(m *model.Message) bool) []*memoryMessage {
+	var messages []*memoryMessage
+	for _, m := range r.messages {
+		if m.msg.ChatID == chatID && keep(&m.msg) {
+			messages = append(messages, m)
+		}
+	}
+	slices.SortFunc(messages, func(a, b *memoryMessage) int { return cmp.Compare(a.seq, b.seq) })
+	return messages
+}
+
+func isActiveMessage(m *model.Message) bool { return m.IsActive }
+
+func (r *memoryRepository) GetActiveMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return copyMessages(r.chatMessages(chatID, isActiveMessage)), nil
+}
+
+func (r *memoryRepository) GetActiveMessagesByChatIDTx(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.openTx(tx); err != nil {
+		return nil, err
+	}
+	return copyMessages(r.chatMessages(chatID, isActiveMessage)), nil
+}
+
+func copyMessages(stored []*memoryMessage) []model.Message {
+	var messages []model.Message
+	for _, m := range stored {
+		messages = append(messages, copyMessage(m.msg))
+	}
+	return messages
+}
+
+// GetActiveMessagesPage returns up to `limit` active messages of a chat, newest
+// first, that were stored before the message with the ID `before` if it is set.
+func (r *memoryRepository) GetActiveMessagesPage(ctx context.Context, chatID string, limit int, before string) ([]model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.chatMessages(chatID, isActiveMessage)
+	if before != "" {
+		cursor, ok := r.messages[before]
+		if !ok || cursor.msg.ChatID != chatID {
+			return nil, ErrNotFound
+		}
+		stored = slices.DeleteFunc(stored, func(m *memoryMessage) bool { return m.seq >= cursor.seq })
+	}
+	slices.Reverse(stored)
+	if limit >= 0 && len(stored) > limit {
+		stored = stored[:limit]
+	}
+	return copyMessages(stored), nil
+}
+
+// GetAllMessagesByChatID returns every message of a chat, including inactive
+// branches, oldest first, created at or after `since` if it is set.
+func (r *memoryRepository) GetAllMessagesByChatID(ctx context.Context, chatID string, since *time.Time) ([]model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.chatMessages(chatID, func(m *model.Message) bool {
+		return since == nil || !m.Timestamp.Before(*since)
+	})
+	return copyMessages(stored), nil
+}
+
+func (r *memoryRepository) GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.chatMessages(chatID, isActiveMessage)
+	if len(stored) == 0 {
+		return nil, ErrNotFound
+	}
+	// Like the SQLite repository, only the ID is filled.
+	return &model.Message{ID: stored[len(stored)-1].msg.ID}, nil
+}
+
+// --- Attachment Methods ---
+
+// GetAttachmentRefsByChatID returns the metadata of all attachments in a chat,
+// without their content.
+func (r *memoryRepository) GetAttachmentRefsByChatID(ctx context.Context, chatID string) ([]model.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var refs []model.Attachment
+	for _, m := range r.chatMessages(chatID, func(*model.Message) bool { return true }) {
+		for _, a := range r.attachments[m.msg.ID] {
+			a.Data = nil
+			refs = append(refs, a)
+		}
+	}
+	sortAttachments(refs)
+	return refs, nil
+}
+
+func (r *memoryRepository) GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []string) ([]model.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getAttachmentsByMessageIDs(messageIDs), nil
+}
+
+func (r *memoryRepository) GetAttachmentsByMessageIDsTx(ctx context.Context, tx *sql.Tx, messageIDs []string) ([]model.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.openTx(tx); err != nil {
+		return nil, err
+	}
+	return r.getAttachmentsByMessageIDs(messageIDs), nil
+}
+
+// getAttachmentsByMessageIDs returns copies of the attachments of the given
+// messages. The caller holds `r.mu`.
+func (r *memoryRepository) getAttachmentsByMessageIDs(messageIDs []string) []model.Attachment {
+	var attachments []model.Attachment
+	for _, id := range messageIDs {
+		for _, a := range r.attachments[id] {
+			a.Data = slices.Clone(a.Data)
+			attachments = append(attachments, a)
+		}
+	}
+	sortAttachments(attachments)
+	return attachments
+}
+
+func sortAttachments(attachments []model.Attachment) {
+	slices.SortStableFunc(attachments, func(a, b model.Attachment) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+// GetAttachment returns a single attachment with its content. The attachment
+// must belong to the given message, which in turn must belong to the given chat.
+func (r *memoryRepository) GetAttachment(ctx context.Context, chatID, messageID, attachmentID string) (*model.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.messages[messageID]; !ok || m.msg.ChatID != chatID {
+		return nil, ErrNotFound
+	}
+	for _, a := range r.attachments[messageID] {
+		if a.ID == attachmentID {
+			a.Data = slices.Clone(a.Data)
+			return &a, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// --- Idempotency Methods ---
+
+func (r *memoryRepository) GetIdempotencyRecord(ctx context.Context, key string, notBefore time.Time) (*model.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.idempotency[key]
+	if !ok || rec.CreatedAt.Before(notBefore) {
+		return nil, ErrNotFound
+	}
+	return &rec, nil
+}
+
+func (r *memoryRepository) SaveIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := *record
+	rec.CreatedAt = rec.CreatedAt.UTC()
+	r.idempotency[rec.Key] = rec
+	return nil
+}
+
+func (r *memoryRepository) DeleteIdempotencyRecordsBefore(ctx context.Context, cutoff time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, rec := range r.idempotency {
+		if rec.CreatedAt.Before(cutoff) {
+			delete(r.idempotency, key)
+		}
+	}
+	return nil
+}
+
+// --- Document Methods ---
+
+func (r *memoryRepository) CreateCollection(ctx context.Context, collection *model.Collection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.collections[collection.ID]; ok {
+		return fmt.Errorf("repository: collection %s already exists", collection.ID)
+	}
+	c := *collection
+	r.collections[c.ID] = &c
+	return nil
+}
+
+func (r *memoryRepository) GetCollection(ctx context.Context, collectionID string) (*model.Collection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getCollection(collectionID)
+}
+
+func (r *memoryRepository) GetCollectionTx(ctx context.Context, tx *sql.Tx, collectionID string) (*model.Collection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.openTx(tx); err != nil {
+		return nil, err
+	}
+	return r.getCollection(collectionID)
+}
+
+// getCollection returns a copy of a stored collection. The caller holds `r.mu`.
+func (r *memoryRepository) getCollection(collectionID string) (*model.Collection, error) {
+	collection, ok := r.collections[collectionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *collection
+	return &c, nil
+}
+
+func (r *memoryRepository) GetCollections(ctx context.Context) ([]*model.Collection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var collections []*model.Collection
+	for _, collection := range r.collections {
+		c := *collection
+		collections = append(collections, &c)
+	}
+	slices.SortFunc(collections, func(a, b *model.Collection) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return collections, nil
+}
+
+// AddDocument stores a document and all of its chunks.
+func (r *memoryRepository) AddDocument(ctx context.Context, document *model.Document, chunks []model.DocumentChunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.documents[document.ID]; ok {
+		return fmt.Errorf("repository: document %s already exists", document.ID)
+	}
+	d := *document
+	r.documents[d.ID] = &d
+	for _, c := range chunks {
+		c.DocumentID = d.ID
+		c.Embedding = slices.Clone(c.Embedding)
+		r.chunks[d.CollectionID] = append(r.chunks[d.CollectionID], c)
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetDocuments(ctx context.Context, collectionID string) ([]*model.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var documents []*model.Document
+	for _, document := range r.documents {
+		if document.CollectionID == collectionID {
+			d := *document
+			documents = append(documents, &d)
+		}
+	}
+	slices.SortFunc(documents, func(a, b *model.Document) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return documents, nil
+}
+
+func (r *memoryRepository) GetChunksByCollectionID(ctx context.Context, collectionID string) ([]model.DocumentChunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getChunksByCollectionID(collectionID), nil
+}
+
+func (r *memoryRepository) GetChunksByCollectionIDTx(ctx context.Context, tx *sql.Tx, collectionID string) ([]model.DocumentChunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.openTx(tx); err != nil {
+		return nil, err
+	}
+	return r.getChunksByCollectionID(collectionID), nil
+}
+
+// getChunksByCollectionID returns copies of the chunks of a collection. The
+// caller holds `r.mu`.
+func (r *memoryRepository) getChunksByCollectionID(collectionID string) []model.DocumentChunk {
+	var chunks []model.DocumentChunk
+	for _, c := range r.chunks[collectionID] {
+		c.Embedding = slices.Clone(c.Embedding)
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+// --- Prompt Methods ---
+
+func (r *memoryRepository) CreatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.prompts[prompt.ID]; ok {
+		return fmt.Errorf("repository: prompt %s already exists", prompt.ID)
+	}
+	p := *prompt
+	// Variables are derived from the body, not stored.
+	p.Variables = nil
+	r.prompts[p.ID] = &p
+	return nil
+}
+
+func (r *memoryRepository) GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prompt, ok := r.prompts[promptID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	p := *prompt
+	return &p, nil
+}
+
+func (r *memoryRepository) GetPrompts(ctx context.Context) ([]*model.Prompt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var prompts []*model.Prompt
+	for _, prompt := range r.prompts {
+		p := *prompt
+		prompts = append(prompts, &p)
+	}
+	slices.SortFunc(prompts, func(a, b *model.Prompt) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return prompts, nil
+}
+
+// UpdatePrompt overwrites the name, description, body and update time of a prompt.
+func (r *memoryRepository) UpdatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.prompts[prompt.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Name = prompt.Name
+	stored.Description = prompt.Description
+	stored.Body = prompt.Body
+	stored.UpdatedAt = prompt.UpdatedAt
+	return nil
+}
+
+func (r *memoryRepository) DeletePrompt(ctx context.Context, promptID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.prompts[promptID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.prompts, promptID)
+	return nil
+}
+
+// --- Model Methods ---
+
+func (r *memoryRepository) GetModelDefaults(ctx context.Context, modelName string) (*model.ModelDefaults, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.modelDefaults[modelName]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	d.Options = slices.Clone(d.Options)
+	return &d, nil
+}
+
+// SaveModelDefaults creates or replaces the default options of a model.
+func (r *memoryRepository) SaveModelDefaults(ctx context.Context, defaults *model.ModelDefaults) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := *defaults
+	d.Options = slices.Clone(d.Options)
+	r.modelDefaults[d.Model] = d
+	return nil
+}
+
+// GetModelBenchmarks returns the last benchmark of every benchmarked model.
+func (r *memoryRepository) GetModelBenchmarks(ctx context.Context) ([]model.ModelBenchmark, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var benchmarks []model.ModelBenchmark
+	for _, name := range slices.Sorted(maps.Keys(r.benchmarks)) {
+		benchmarks = append(benchmarks, r.benchmarks[name])
+	}
+	return benchmarks, nil
+}
+
+// SaveModelBenchmark stores a benchmark, replacing the previous one of the model.
+func (r *memoryRepository) SaveModelBenchmark(ctx context.Context, benchmark *model.ModelBenchmark) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.benchmarks[benchmark.Model] = *benchmark
+	return nil
+}
+
+// --- Transactional Methods ---
+// Each write records how to undo it in the journal of the transaction.
+
+// CreateChatTx creates a chat within an existing transaction.
+func (r *memoryRepository) CreateChatTx(ctx context.Context, tx *sql.Tx, chat *model.Chat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	if err := r.createChat(chat); err != nil {
+		return err
+	}
+	t.undo = append(t.undo, func() { delete(r.chats, chat.ID) })
+	return nil
+}
+
+func (r *memoryRepository) AddMessageTx(ctx context.Context, tx *sql.Tx, message *model.Message, chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	if err := r.addMessage(message, chatID); err != nil {
+		return err
+	}
+	messageID := message.ID
+	t.undo = append(t.undo, func() {
+		delete(r.messages, messageID)
+		delete(r.attachments, messageID)
+	})
+	return nil
+}
+
+// UpdateMessageContentTx replaces the content and metadata of a message.
+func (r *memoryRepository) UpdateMessageContentTx(ctx context.Context, tx *sql.Tx, messageID, content string, metadata json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	m, ok := r.messages[messageID]
+	if !ok {
+		return ErrNotFound
+	}
+	oldContent, oldMetadata := m.msg.Content, m.msg.Metadata
+	m.msg.Content, m.msg.Metadata = content, storedMetadata(metadata)
+	t.undo = append(t.undo, func() { m.msg.Content, m.msg.Metadata = oldContent, oldMetadata })
+	return nil
+}
+
+// DeactivateBranchTx marks a message and all its descendants as inactive.
+func (r *memoryRepository) DeactivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	branch := []string{messageID}
+	for i := 0; i < len(branch); i++ {
+		for _, m := range r.children(branch[i]) {
+			branch = append(branch, m.msg.ID)
+		}
+	}
+	for _, id := range branch {
+		if m, ok := r.messages[id]; ok {
+			r.setActive(t, m, false)
+		}
+	}
+	return nil
+}
+
+// ActivateBranchTx marks a message as active, and with it the path through its
+// most recently added children.
+func (r *memoryRepository) ActivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	for id := messageID; id != ""; {
+		if m, ok := r.messages[id]; ok {
+			r.setActive(t, m, true)
+		}
+		children := r.children(id)
+		id = ""
+		if len(children) > 0 {
+			id = children[len(children)-1].msg.ID
+		}
+	}
+	return nil
+}
+
+// children returns the replies to a message in insertion order. The caller
+// holds `r.mu`.
+func (r *memoryRepository) children(messageID string) []*memoryMessage {
+	var children []*memoryMessage
+	for _, m := range r.messages {
+		if m.msg.ParentID != nil && *m.msg.ParentID == messageID {
+			children = append(children, m)
+		}
+	}
+	slices.SortFunc(children, func(a, b *memoryMessage) int { return cmp.Compare(a.seq, b.seq) })
+	return children
+}
+
+// setActive changes the active flag of a message and journals the change.
+func (r *memoryRepository) setActive(t *memoryTx, m *memoryMessage, active bool) {
+	old := m.msg.IsActive
+	m.msg.IsActive = active
+	t.undo = append(t.undo, func() { m.msg.IsActive = old })
+}
+
+func (r *memoryRepository) UpdateChatTimestampTx(ctx context.Context, tx *sql.Tx, chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.openTx(tx)
+	if err != nil {
+		return err
+	}
+	chat, ok := r.chats[chatID]
+	if !ok {
+		return nil
+	}
+	old := chat.UpdatedAt
+	chat.UpdatedAt = time.Now().UTC()
+	t.undo = append(t.undo, func() { chat.UpdatedAt = old })
+	return nil
+}
+
+// --- Maintenance Methods ---
+
+// GetGlobalStats counts chats and messages.
+func (r *memoryRepository) GetGlobalStats(ctx context.Context) (*model.GlobalStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := &model.GlobalStats{Chats: len(r.chats), Messages: len(r.messages), MessagesByModel: []model.ModelMessageCount{}}
+	byModel := map[string]int{}
+	for _, m := range r.messages {
+		if m.msg.IsActive {
+			stats.ActiveMessages++
+		}
+		if m.msg.Model != nil && *m.msg.Model != "" {
+			byModel[*m.msg.Model]++
+		}
+	}
+	stats.InactiveMessages = stats.Messages - stats.ActiveMessages
+	for name, count := range byModel {
+		stats.MessagesByModel = append(stats.MessagesByModel, model.ModelMessageCount{Model: name, Messages: count})
+	}
+	slices.SortFunc(stats.MessagesByModel, func(a, b model.ModelMessageCount) int {
+		return cmp.Or(cmp.Compare(b.Messages, a.Messages), cmp.Compare(a.Model, b.Model))
+	})
+	return stats, nil
+}
+
+// Checkpoint has nothing to write back; there is no write-ahead log.
+func (r *memoryRepository) Checkpoint(ctx context.Context) (*model.CheckpointResult, error) {
+	return &model.CheckpointResult{}, nil
+}
+
+// Optimize has no query planner to refresh.
+func (r *memoryRepository) Optimize(ctx context.Context) error {
+	return nil
+}
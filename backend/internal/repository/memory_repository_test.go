@@ -0,0 +1,231 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// forEachBackend runs a test against the SQLite and the memory repository.
+//
+// WHY: The memory repository stands in for SQLite in service tests and demos,
+// so it is held to the same observable behavior rather than to a spec of its own.
+func forEachBackend(t *testing.T, test func(t *testing.T, repo repository.Repository)) {
+	t.Run("sqlite", func(t *testing.T) {
+		repo, _ := setupRepository(t)
+		test(t, repo)
+	})
+	t.Run("memory", func(t *testing.T) {
+		test(t, repository.NewMemoryRepository())
+	})
+}
+
+// messageIDs returns the IDs of messages in order.
+func messageIDs(messages []model.Message) []string {
+	var ids []string
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+// seedConversation creates a chat with a question, an answer to it and a follow-up.
+func seedConversation(t *testing.T, repo repository.Repository) string {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: now, UpdatedAt: now}))
+	parent := func(id string) *string { return &id }
+	for _, msg := range []*model.Message{
+		{ID: "u1", Role: "user", Content: "Hi", Timestamp: now},
+		{ID: "a1", ParentID: parent("u1"), Role: "assistant", Content: "Hello", Timestamp: now},
+		{ID: "u2", ParentID: parent("a1"), Role: "user", Content: "More", Timestamp: now},
+	} {
+		require.NoError(t, repo.AddMessage(ctx, msg, "chat1"))
+	}
+	return "chat1"
+}
+
+// TestRepository_Branches verifies the branch semantics that regeneration relies
+// on: deactivating a message hides it and its descendants, and activating it
+// again restores the path through its most recent replies.
+func TestRepository_Branches(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo repository.Repository) {
+		ctx := context.Background()
+		chatID := seedConversation(t, repo)
+		u1 := "u1"
+
+		// ACT: Regenerate the answer.
+		tx, err := repo.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeactivateBranchTx(ctx, tx, "a1"))
+		history, err := repo.GetActiveMessagesByChatIDTx(ctx, tx, chatID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1"}, messageIDs(history))
+		require.NoError(t, repo.AddMessageTx(ctx, tx, &model.Message{ID: "a2", ParentID: &u1, Role: "assistant", Content: "Hey", Timestamp: time.Now().UTC()}, chatID))
+		require.NoError(t, tx.Commit())
+
+		// ASSERT
+		active, err := repo.GetActiveMessagesByChatID(ctx, chatID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a2"}, messageIDs(active))
+		all, err := repo.GetAllMessagesByChatID(ctx, chatID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "u2", "a2"}, messageIDs(all))
+		last, err := repo.GetLastActiveMessage(ctx, chatID)
+		require.NoError(t, err)
+		assert.Equal(t, "a2", last.ID)
+
+		// ACT: Switch back to the first answer.
+		tx, err = repo.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeactivateBranchTx(ctx, tx, "a2"))
+		require.NoError(t, repo.ActivateBranchTx(ctx, tx, "a1"))
+		require.NoError(t, tx.Commit())
+
+		// ASSERT: The follow-up of the first answer is back as well.
+		active, err = repo.GetActiveMessagesByChatID(ctx, chatID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "u2"}, messageIDs(active))
+		msg, err := repo.GetMessageByID(ctx, "a2")
+		require.NoError(t, err)
+		assert.False(t, msg.IsActive)
+		assert.Equal(t, chatID, msg.ChatID)
+	})
+}
+
+// TestRepository_Rollback verifies that a rolled back transaction leaves no
+// trace, and that a finished transaction cannot be used any more.
+func TestRepository_Rollback(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo repository.Repository) {
+		ctx := context.Background()
+		chatID := seedConversation(t, repo)
+		before, err := repo.GetChat(ctx, chatID)
+		require.NoError(t, err)
+		u1 := "u1"
+
+		// ACT
+		tx, err := repo.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateChatTx(ctx, tx, &model.Chat{ID: "chat2", Title: "New"}))
+		require.NoError(t, repo.DeactivateBranchTx(ctx, tx, "a1"))
+		require.NoError(t, repo.AddMessageTx(ctx, tx, &model.Message{ID: "a2", ParentID: &u1, Role: "assistant", Content: "Hey", Timestamp: time.Now().UTC()}, chatID))
+		require.NoError(t, repo.UpdateMessageContentTx(ctx, tx, "u1", "Changed", nil))
+		require.NoError(t, repo.UpdateChatTimestampTx(ctx, tx, chatID))
+		require.NoError(t, tx.Rollback())
+
+		// ASSERT
+		_, err = repo.GetChat(ctx, "chat2")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		active, err := repo.GetActiveMessagesByChatID(ctx, chatID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "u2"}, messageIDs(active))
+		assert.Equal(t, "Hi", active[0].Content)
+		_, err = repo.GetMessageByID(ctx, "a2")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		after, err := repo.GetChat(ctx, chatID)
+		require.NoError(t, err)
+		assert.True(t, before.UpdatedAt.Equal(after.UpdatedAt))
+
+		assert.ErrorIs(t, repo.DeactivateBranchTx(ctx, tx, "a1"), sql.ErrTxDone)
+	})
+}
+
+// TestRepository_MessagePages verifies that paging through messages stored
+// within the same instant neither skips nor repeats any of them.
+//
+// WHY: A user message and a quick answer can share a timestamp, so the cursor
+// of a page must not be a timestamp.
+func TestRepository_MessagePages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo repository.Repository) {
+		ctx := context.Background()
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "q1", Role: "user", Content: "Hi", Timestamp: now}, "chat1"))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", Role: "assistant", Content: "Hello!", Timestamp: now}, "chat1"))
+
+		first, err := repo.GetActiveMessagesPage(ctx, "chat1", 1, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a1"}, messageIDs(first))
+		second, err := repo.GetActiveMessagesPage(ctx, "chat1", 1, first[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"q1"}, messageIDs(second))
+		last, err := repo.GetActiveMessagesPage(ctx, "chat1", 1, second[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, last)
+
+		_, err = repo.GetActiveMessagesPage(ctx, "chat1", 1, "missing")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// TestMemoryRepository_CancelledContext verifies that cancelling the context of a
+// transaction rolls it back, as database/sql does for a real database.
+func TestMemoryRepository_CancelledContext(t *testing.T) {
+	// ARRANGE
+	repo := repository.NewMemoryRepository()
+	chatID := seedConversation(t, repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeactivateBranchTx(ctx, tx, "a1"))
+
+	// ACT
+	cancel()
+
+	// ASSERT
+	assert.Eventually(t, func() bool {
+		active, err := repo.GetActiveMessagesByChatID(context.Background(), chatID)
+		return err == nil && len(active) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Error(t, tx.Commit())
+}
+
+// TestRepository_ListAndPrune verifies the listing order, the filters and
+// retention on both backends.
+func TestRepository_ListAndPrune(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo repository.Repository) {
+		ctx := context.Background()
+		base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		for i, id := range []string{"old", "middle", "new"} {
+			ts := base.Add(time.Duration(i) * time.Hour)
+			require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, Model: "m1", CreatedAt: ts, UpdatedAt: ts}))
+		}
+		assistantModel := "m2"
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", Role: "assistant", Model: &assistantModel, Timestamp: base}, "old"))
+		require.NoError(t, repo.UpdateChatPinned(ctx, "middle", true))
+		require.NoError(t, repo.SetChatTags(ctx, "new", []string{"work", "go"}))
+		chatIDs := func(opts model.ChatListOptions) []string {
+			chats, err := repo.GetChats(ctx, opts)
+			require.NoError(t, err)
+			var ids []string
+			for _, c := range chats {
+				ids = append(ids, c.ID)
+			}
+			return ids
+		}
+
+		// ASSERT: Adding a message made "old" the most recently updated chat.
+		assert.Equal(t, []string{"middle", "old", "new"}, chatIDs(model.ChatListOptions{}))
+		assert.Equal(t, []string{"middle", "new", "old"}, chatIDs(model.ChatListOptions{SortBy: "title", Order: "asc"}))
+		assert.Equal(t, []string{"old"}, chatIDs(model.ChatListOptions{Model: "m2"}))
+		assert.Equal(t, []string{"new"}, chatIDs(model.ChatListOptions{Tag: "go"}))
+		chats, err := repo.GetChats(ctx, model.ChatListOptions{Tag: "go"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "work"}, chats[0].Tags)
+
+		// ACT: Keep only the newest unpinned chat.
+		deleted, err := repo.PruneChats(ctx, model.ChatPruneOptions{KeepNewest: 1, Limit: 10})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+		assert.Equal(t, []string{"middle", "old"}, chatIDs(model.ChatListOptions{}))
+	})
+}
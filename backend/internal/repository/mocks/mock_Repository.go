@@ -7,7 +7,9 @@ package mocks
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"flow-ai/backend/internal/model"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -102,6 +104,69 @@ func (_c *MockRepository_ActivateBranchTx_Call) RunAndReturn(run func(ctx contex
 	return _c
 }
 
+// AddDocument provides a mock function for the type MockRepository
+func (_mock *MockRepository) AddDocument(ctx context.Context, document *model.Document, chunks []model.DocumentChunk) error {
+	ret := _mock.Called(ctx, document, chunks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDocument")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Document, []model.DocumentChunk) error); ok {
+		r0 = returnFunc(ctx, document, chunks)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_AddDocument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddDocument'
+type MockRepository_AddDocument_Call struct {
+	*mock.Call
+}
+
+// AddDocument is a helper method to define mock.On call
+//   - ctx context.Context
+//   - document *model.Document
+//   - chunks []model.DocumentChunk
+func (_e *MockRepository_Expecter) AddDocument(ctx interface{}, document interface{}, chunks interface{}) *MockRepository_AddDocument_Call {
+	return &MockRepository_AddDocument_Call{Call: _e.mock.On("AddDocument", ctx, document, chunks)}
+}
+
+func (_c *MockRepository_AddDocument_Call) Run(run func(ctx context.Context, document *model.Document, chunks []model.DocumentChunk)) *MockRepository_AddDocument_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *model.Document
+		if args[1] != nil {
+			arg1 = args[1].(*model.Document)
+		}
+		var arg2 []model.DocumentChunk
+		if args[2] != nil {
+			arg2 = args[2].([]model.DocumentChunk)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_AddDocument_Call) Return(err error) *MockRepository_AddDocument_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_AddDocument_Call) RunAndReturn(run func(ctx context.Context, document *model.Document, chunks []model.DocumentChunk) error) *MockRepository_AddDocument_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddMessage provides a mock function for the type MockRepository
 func (_mock *MockRepository) AddMessage(ctx context.Context, message *model.Message, chatID string) error {
 	ret := _mock.Called(ctx, message, chatID)
@@ -296,6 +361,68 @@ func (_c *MockRepository_BeginTx_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// Checkpoint provides a mock function for the type MockRepository
+func (_mock *MockRepository) Checkpoint(ctx context.Context) (*model.CheckpointResult, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checkpoint")
+	}
+
+	var r0 *model.CheckpointResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*model.CheckpointResult, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *model.CheckpointResult); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CheckpointResult)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_Checkpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checkpoint'
+type MockRepository_Checkpoint_Call struct {
+	*mock.Call
+}
+
+// Checkpoint is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Checkpoint(ctx interface{}) *MockRepository_Checkpoint_Call {
+	return &MockRepository_Checkpoint_Call{Call: _e.mock.On("Checkpoint", ctx)}
+}
+
+func (_c *MockRepository_Checkpoint_Call) Run(run func(ctx context.Context)) *MockRepository_Checkpoint_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_Checkpoint_Call) Return(checkpointResult *model.CheckpointResult, err error) *MockRepository_Checkpoint_Call {
+	_c.Call.Return(checkpointResult, err)
+	return _c
+}
+
+func (_c *MockRepository_Checkpoint_Call) RunAndReturn(run func(ctx context.Context) (*model.CheckpointResult, error)) *MockRepository_Checkpoint_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateChat provides a mock function for the type MockRepository
 func (_mock *MockRepository) CreateChat(ctx context.Context, chat *model.Chat) error {
 	ret := _mock.Called(ctx, chat)
@@ -353,37 +480,37 @@ func (_c *MockRepository_CreateChat_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
-// DeactivateBranchTx provides a mock function for the type MockRepository
-func (_mock *MockRepository) DeactivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error {
-	ret := _mock.Called(ctx, tx, messageID)
+// CreateChatTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) CreateChatTx(ctx context.Context, tx *sql.Tx, chat *model.Chat) error {
+	ret := _mock.Called(ctx, tx, chat)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeactivateBranchTx")
+		panic("no return value specified for CreateChatTx")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) error); ok {
-		r0 = returnFunc(ctx, tx, messageID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, *model.Chat) error); ok {
+		r0 = returnFunc(ctx, tx, chat)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockRepository_DeactivateBranchTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateBranchTx'
-type MockRepository_DeactivateBranchTx_Call struct {
+// MockRepository_CreateChatTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateChatTx'
+type MockRepository_CreateChatTx_Call struct {
 	*mock.Call
 }
 
-// DeactivateBranchTx is a helper method to define mock.On call
+// CreateChatTx is a helper method to define mock.On call
 //   - ctx context.Context
 //   - tx *sql.Tx
-//   - messageID string
-func (_e *MockRepository_Expecter) DeactivateBranchTx(ctx interface{}, tx interface{}, messageID interface{}) *MockRepository_DeactivateBranchTx_Call {
-	return &MockRepository_DeactivateBranchTx_Call{Call: _e.mock.On("DeactivateBranchTx", ctx, tx, messageID)}
+//   - chat *model.Chat
+func (_e *MockRepository_Expecter) CreateChatTx(ctx interface{}, tx interface{}, chat interface{}) *MockRepository_CreateChatTx_Call {
+	return &MockRepository_CreateChatTx_Call{Call: _e.mock.On("CreateChatTx", ctx, tx, chat)}
 }
 
-func (_c *MockRepository_DeactivateBranchTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, messageID string)) *MockRepository_DeactivateBranchTx_Call {
+func (_c *MockRepository_CreateChatTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, chat *model.Chat)) *MockRepository_CreateChatTx_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -393,9 +520,9 @@ func (_c *MockRepository_DeactivateBranchTx_Call) Run(run func(ctx context.Conte
 		if args[1] != nil {
 			arg1 = args[1].(*sql.Tx)
 		}
-		var arg2 string
+		var arg2 *model.Chat
 		if args[2] != nil {
-			arg2 = args[2].(string)
+			arg2 = args[2].(*model.Chat)
 		}
 		run(
 			arg0,
@@ -406,54 +533,54 @@ func (_c *MockRepository_DeactivateBranchTx_Call) Run(run func(ctx context.Conte
 	return _c
 }
 
-func (_c *MockRepository_DeactivateBranchTx_Call) Return(err error) *MockRepository_DeactivateBranchTx_Call {
+func (_c *MockRepository_CreateChatTx_Call) Return(err error) *MockRepository_CreateChatTx_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_DeactivateBranchTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, messageID string) error) *MockRepository_DeactivateBranchTx_Call {
+func (_c *MockRepository_CreateChatTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, chat *model.Chat) error) *MockRepository_CreateChatTx_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteChat provides a mock function for the type MockRepository
-func (_mock *MockRepository) DeleteChat(ctx context.Context, chatID string) error {
-	ret := _mock.Called(ctx, chatID)
+// CreateCollection provides a mock function for the type MockRepository
+func (_mock *MockRepository) CreateCollection(ctx context.Context, collection *model.Collection) error {
+	ret := _mock.Called(ctx, collection)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteChat")
+		panic("no return value specified for CreateCollection")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = returnFunc(ctx, chatID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Collection) error); ok {
+		r0 = returnFunc(ctx, collection)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockRepository_DeleteChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChat'
-type MockRepository_DeleteChat_Call struct {
+// MockRepository_CreateCollection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateCollection'
+type MockRepository_CreateCollection_Call struct {
 	*mock.Call
 }
 
-// DeleteChat is a helper method to define mock.On call
+// CreateCollection is a helper method to define mock.On call
 //   - ctx context.Context
-//   - chatID string
-func (_e *MockRepository_Expecter) DeleteChat(ctx interface{}, chatID interface{}) *MockRepository_DeleteChat_Call {
-	return &MockRepository_DeleteChat_Call{Call: _e.mock.On("DeleteChat", ctx, chatID)}
+//   - collection *model.Collection
+func (_e *MockRepository_Expecter) CreateCollection(ctx interface{}, collection interface{}) *MockRepository_CreateCollection_Call {
+	return &MockRepository_CreateCollection_Call{Call: _e.mock.On("CreateCollection", ctx, collection)}
 }
 
-func (_c *MockRepository_DeleteChat_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_DeleteChat_Call {
+func (_c *MockRepository_CreateCollection_Call) Run(run func(ctx context.Context, collection *model.Collection)) *MockRepository_CreateCollection_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *model.Collection
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*model.Collection)
 		}
 		run(
 			arg0,
@@ -463,65 +590,54 @@ func (_c *MockRepository_DeleteChat_Call) Run(run func(ctx context.Context, chat
 	return _c
 }
 
-func (_c *MockRepository_DeleteChat_Call) Return(err error) *MockRepository_DeleteChat_Call {
+func (_c *MockRepository_CreateCollection_Call) Return(err error) *MockRepository_CreateCollection_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_DeleteChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) error) *MockRepository_DeleteChat_Call {
+func (_c *MockRepository_CreateCollection_Call) RunAndReturn(run func(ctx context.Context, collection *model.Collection) error) *MockRepository_CreateCollection_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetActiveMessagesByChatID provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetActiveMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error) {
-	ret := _mock.Called(ctx, chatID)
+// CreatePrompt provides a mock function for the type MockRepository
+func (_mock *MockRepository) CreatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	ret := _mock.Called(ctx, prompt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetActiveMessagesByChatID")
+		panic("no return value specified for CreatePrompt")
 	}
 
-	var r0 []model.Message
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.Message, error)); ok {
-		return returnFunc(ctx, chatID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.Message); ok {
-		r0 = returnFunc(ctx, chatID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Message)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, chatID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Prompt) error); ok {
+		r0 = returnFunc(ctx, prompt)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetActiveMessagesByChatID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveMessagesByChatID'
-type MockRepository_GetActiveMessagesByChatID_Call struct {
+// MockRepository_CreatePrompt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreatePrompt'
+type MockRepository_CreatePrompt_Call struct {
 	*mock.Call
 }
 
-// GetActiveMessagesByChatID is a helper method to define mock.On call
+// CreatePrompt is a helper method to define mock.On call
 //   - ctx context.Context
-//   - chatID string
-func (_e *MockRepository_Expecter) GetActiveMessagesByChatID(ctx interface{}, chatID interface{}) *MockRepository_GetActiveMessagesByChatID_Call {
-	return &MockRepository_GetActiveMessagesByChatID_Call{Call: _e.mock.On("GetActiveMessagesByChatID", ctx, chatID)}
+//   - prompt *model.Prompt
+func (_e *MockRepository_Expecter) CreatePrompt(ctx interface{}, prompt interface{}) *MockRepository_CreatePrompt_Call {
+	return &MockRepository_CreatePrompt_Call{Call: _e.mock.On("CreatePrompt", ctx, prompt)}
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatID_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetActiveMessagesByChatID_Call {
+func (_c *MockRepository_CreatePrompt_Call) Run(run func(ctx context.Context, prompt *model.Prompt)) *MockRepository_CreatePrompt_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *model.Prompt
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*model.Prompt)
 		}
 		run(
 			arg0,
@@ -531,58 +647,47 @@ func (_c *MockRepository_GetActiveMessagesByChatID_Call) Run(run func(ctx contex
 	return _c
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatID_Call) Return(messages []model.Message, err error) *MockRepository_GetActiveMessagesByChatID_Call {
-	_c.Call.Return(messages, err)
+func (_c *MockRepository_CreatePrompt_Call) Return(err error) *MockRepository_CreatePrompt_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatID_Call) RunAndReturn(run func(ctx context.Context, chatID string) ([]model.Message, error)) *MockRepository_GetActiveMessagesByChatID_Call {
+func (_c *MockRepository_CreatePrompt_Call) RunAndReturn(run func(ctx context.Context, prompt *model.Prompt) error) *MockRepository_CreatePrompt_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetActiveMessagesByChatIDTx provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetActiveMessagesByChatIDTx(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error) {
-	ret := _mock.Called(ctx, tx, chatID)
+// DeactivateBranchTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeactivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error {
+	ret := _mock.Called(ctx, tx, messageID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetActiveMessagesByChatIDTx")
+		panic("no return value specified for DeactivateBranchTx")
 	}
 
-	var r0 []model.Message
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) ([]model.Message, error)); ok {
-		return returnFunc(ctx, tx, chatID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) []model.Message); ok {
-		r0 = returnFunc(ctx, tx, chatID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Message)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string) error); ok {
-		r1 = returnFunc(ctx, tx, chatID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) error); ok {
+		r0 = returnFunc(ctx, tx, messageID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetActiveMessagesByChatIDTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveMessagesByChatIDTx'
-type MockRepository_GetActiveMessagesByChatIDTx_Call struct {
+// MockRepository_DeactivateBranchTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateBranchTx'
+type MockRepository_DeactivateBranchTx_Call struct {
 	*mock.Call
 }
 
-// GetActiveMessagesByChatIDTx is a helper method to define mock.On call
+// DeactivateBranchTx is a helper method to define mock.On call
 //   - ctx context.Context
 //   - tx *sql.Tx
-//   - chatID string
-func (_e *MockRepository_Expecter) GetActiveMessagesByChatIDTx(ctx interface{}, tx interface{}, chatID interface{}) *MockRepository_GetActiveMessagesByChatIDTx_Call {
-	return &MockRepository_GetActiveMessagesByChatIDTx_Call{Call: _e.mock.On("GetActiveMessagesByChatIDTx", ctx, tx, chatID)}
+//   - messageID string
+func (_e *MockRepository_Expecter) DeactivateBranchTx(ctx interface{}, tx interface{}, messageID interface{}) *MockRepository_DeactivateBranchTx_Call {
+	return &MockRepository_DeactivateBranchTx_Call{Call: _e.mock.On("DeactivateBranchTx", ctx, tx, messageID)}
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, chatID string)) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+func (_c *MockRepository_DeactivateBranchTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, messageID string)) *MockRepository_DeactivateBranchTx_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -605,57 +710,46 @@ func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) Run(run func(ctx cont
 	return _c
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) Return(messages []model.Message, err error) *MockRepository_GetActiveMessagesByChatIDTx_Call {
-	_c.Call.Return(messages, err)
+func (_c *MockRepository_DeactivateBranchTx_Call) Return(err error) *MockRepository_DeactivateBranchTx_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error)) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+func (_c *MockRepository_DeactivateBranchTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, messageID string) error) *MockRepository_DeactivateBranchTx_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetChat provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetChat(ctx context.Context, chatID string) (*model.Chat, error) {
+// DeleteChat provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeleteChat(ctx context.Context, chatID string) error {
 	ret := _mock.Called(ctx, chatID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetChat")
+		panic("no return value specified for DeleteChat")
 	}
 
-	var r0 *model.Chat
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Chat, error)); ok {
-		return returnFunc(ctx, chatID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Chat); ok {
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
 		r0 = returnFunc(ctx, chatID)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Chat)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, chatID)
-	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChat'
-type MockRepository_GetChat_Call struct {
+// MockRepository_DeleteChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChat'
+type MockRepository_DeleteChat_Call struct {
 	*mock.Call
 }
 
-// GetChat is a helper method to define mock.On call
+// DeleteChat is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockRepository_Expecter) GetChat(ctx interface{}, chatID interface{}) *MockRepository_GetChat_Call {
-	return &MockRepository_GetChat_Call{Call: _e.mock.On("GetChat", ctx, chatID)}
+func (_e *MockRepository_Expecter) DeleteChat(ctx interface{}, chatID interface{}) *MockRepository_DeleteChat_Call {
+	return &MockRepository_DeleteChat_Call{Call: _e.mock.On("DeleteChat", ctx, chatID)}
 }
 
-func (_c *MockRepository_GetChat_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetChat_Call {
+func (_c *MockRepository_DeleteChat_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_DeleteChat_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -673,119 +767,2257 @@ func (_c *MockRepository_GetChat_Call) Run(run func(ctx context.Context, chatID
 	return _c
 }
 
-func (_c *MockRepository_GetChat_Call) Return(chat *model.Chat, err error) *MockRepository_GetChat_Call {
-	_c.Call.Return(chat, err)
+func (_c *MockRepository_DeleteChat_Call) Return(err error) *MockRepository_DeleteChat_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.Chat, error)) *MockRepository_GetChat_Call {
+func (_c *MockRepository_DeleteChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) error) *MockRepository_DeleteChat_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetChats provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetChats(ctx context.Context) ([]*model.Chat, error) {
-	ret := _mock.Called(ctx)
+// DeleteChats provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeleteChats(ctx context.Context, chatIDs []string) (int, error) {
+	ret := _mock.Called(ctx, chatIDs)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetChats")
+		panic("no return value specified for DeleteChats")
 	}
 
-	var r0 []*model.Chat
+	var r0 int
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*model.Chat, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (int, error)); ok {
+		return returnFunc(ctx, chatIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) int); ok {
+		r0 = returnFunc(ctx, chatIDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, chatIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_DeleteChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChats'
+type MockRepository_DeleteChats_Call struct {
+	*mock.Call
+}
+
+// DeleteChats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatIDs []string
+func (_e *MockRepository_Expecter) DeleteChats(ctx interface{}, chatIDs interface{}) *MockRepository_DeleteChats_Call {
+	return &MockRepository_DeleteChats_Call{Call: _e.mock.On("DeleteChats", ctx, chatIDs)}
+}
+
+func (_c *MockRepository_DeleteChats_Call) Run(run func(ctx context.Context, chatIDs []string)) *MockRepository_DeleteChats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_DeleteChats_Call) Return(n int, err error) *MockRepository_DeleteChats_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockRepository_DeleteChats_Call) RunAndReturn(run func(ctx context.Context, chatIDs []string) (int, error)) *MockRepository_DeleteChats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteIdempotencyRecordsBefore provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeleteIdempotencyRecordsBefore(ctx context.Context, cutoff time.Time) error {
+	ret := _mock.Called(ctx, cutoff)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdempotencyRecordsBefore")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = returnFunc(ctx, cutoff)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_DeleteIdempotencyRecordsBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteIdempotencyRecordsBefore'
+type MockRepository_DeleteIdempotencyRecordsBefore_Call struct {
+	*mock.Call
+}
+
+// DeleteIdempotencyRecordsBefore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cutoff time.Time
+func (_e *MockRepository_Expecter) DeleteIdempotencyRecordsBefore(ctx interface{}, cutoff interface{}) *MockRepository_DeleteIdempotencyRecordsBefore_Call {
+	return &MockRepository_DeleteIdempotencyRecordsBefore_Call{Call: _e.mock.On("DeleteIdempotencyRecordsBefore", ctx, cutoff)}
+}
+
+func (_c *MockRepository_DeleteIdempotencyRecordsBefore_Call) Run(run func(ctx context.Context, cutoff time.Time)) *MockRepository_DeleteIdempotencyRecordsBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_DeleteIdempotencyRecordsBefore_Call) Return(err error) *MockRepository_DeleteIdempotencyRecordsBefore_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_DeleteIdempotencyRecordsBefore_Call) RunAndReturn(run func(ctx context.Context, cutoff time.Time) error) *MockRepository_DeleteIdempotencyRecordsBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeletePrompt provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeletePrompt(ctx context.Context, promptID string) error {
+	ret := _mock.Called(ctx, promptID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePrompt")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, promptID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_DeletePrompt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeletePrompt'
+type MockRepository_DeletePrompt_Call struct {
+	*mock.Call
+}
+
+// DeletePrompt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - promptID string
+func (_e *MockRepository_Expecter) DeletePrompt(ctx interface{}, promptID interface{}) *MockRepository_DeletePrompt_Call {
+	return &MockRepository_DeletePrompt_Call{Call: _e.mock.On("DeletePrompt", ctx, promptID)}
+}
+
+func (_c *MockRepository_DeletePrompt_Call) Run(run func(ctx context.Context, promptID string)) *MockRepository_DeletePrompt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_DeletePrompt_Call) Return(err error) *MockRepository_DeletePrompt_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_DeletePrompt_Call) RunAndReturn(run func(ctx context.Context, promptID string) error) *MockRepository_DeletePrompt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActiveMessagesByChatID provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetActiveMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveMessagesByChatID")
+	}
+
+	var r0 []model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.Message, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.Message); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetActiveMessagesByChatID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveMessagesByChatID'
+type MockRepository_GetActiveMessagesByChatID_Call struct {
+	*mock.Call
+}
+
+// GetActiveMessagesByChatID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockRepository_Expecter) GetActiveMessagesByChatID(ctx interface{}, chatID interface{}) *MockRepository_GetActiveMessagesByChatID_Call {
+	return &MockRepository_GetActiveMessagesByChatID_Call{Call: _e.mock.On("GetActiveMessagesByChatID", ctx, chatID)}
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatID_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetActiveMessagesByChatID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatID_Call) Return(messages []model.Message, err error) *MockRepository_GetActiveMessagesByChatID_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatID_Call) RunAndReturn(run func(ctx context.Context, chatID string) ([]model.Message, error)) *MockRepository_GetActiveMessagesByChatID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActiveMessagesByChatIDTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetActiveMessagesByChatIDTx(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error) {
+	ret := _mock.Called(ctx, tx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveMessagesByChatIDTx")
+	}
+
+	var r0 []model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) ([]model.Message, error)); ok {
+		return returnFunc(ctx, tx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) []model.Message); ok {
+		r0 = returnFunc(ctx, tx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string) error); ok {
+		r1 = returnFunc(ctx, tx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetActiveMessagesByChatIDTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveMessagesByChatIDTx'
+type MockRepository_GetActiveMessagesByChatIDTx_Call struct {
+	*mock.Call
+}
+
+// GetActiveMessagesByChatIDTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tx *sql.Tx
+//   - chatID string
+func (_e *MockRepository_Expecter) GetActiveMessagesByChatIDTx(ctx interface{}, tx interface{}, chatID interface{}) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+	return &MockRepository_GetActiveMessagesByChatIDTx_Call{Call: _e.mock.On("GetActiveMessagesByChatIDTx", ctx, tx, chatID)}
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, chatID string)) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) Return(messages []model.Message, err error) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesByChatIDTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error)) *MockRepository_GetActiveMessagesByChatIDTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActiveMessagesPage provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetActiveMessagesPage(ctx context.Context, chatID string, limit int, before string) ([]model.Message, error) {
+	ret := _mock.Called(ctx, chatID, limit, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveMessagesPage")
+	}
+
+	var r0 []model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) ([]model.Message, error)); ok {
+		return returnFunc(ctx, chatID, limit, before)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) []model.Message); ok {
+		r0 = returnFunc(ctx, chatID, limit, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = returnFunc(ctx, chatID, limit, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetActiveMessagesPage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveMessagesPage'
+type MockRepository_GetActiveMessagesPage_Call struct {
+	*mock.Call
+}
+
+// GetActiveMessagesPage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - limit int
+//   - before string
+func (_e *MockRepository_Expecter) GetActiveMessagesPage(ctx interface{}, chatID interface{}, limit interface{}, before interface{}) *MockRepository_GetActiveMessagesPage_Call {
+	return &MockRepository_GetActiveMessagesPage_Call{Call: _e.mock.On("GetActiveMessagesPage", ctx, chatID, limit, before)}
+}
+
+func (_c *MockRepository_GetActiveMessagesPage_Call) Run(run func(ctx context.Context, chatID string, limit int, before string)) *MockRepository_GetActiveMessagesPage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesPage_Call) Return(messages []model.Message, err error) *MockRepository_GetActiveMessagesPage_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockRepository_GetActiveMessagesPage_Call) RunAndReturn(run func(ctx context.Context, chatID string, limit int, before string) ([]model.Message, error)) *MockRepository_GetActiveMessagesPage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllMessagesByChatID provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetAllMessagesByChatID(ctx context.Context, chatID string, since *time.Time) ([]model.Message, error) {
+	ret := _mock.Called(ctx, chatID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllMessagesByChatID")
+	}
+
+	var r0 []model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *time.Time) ([]model.Message, error)); ok {
+		return returnFunc(ctx, chatID, since)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *time.Time) []model.Message); ok {
+		r0 = returnFunc(ctx, chatID, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *time.Time) error); ok {
+		r1 = returnFunc(ctx, chatID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetAllMessagesByChatID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllMessagesByChatID'
+type MockRepository_GetAllMessagesByChatID_Call struct {
+	*mock.Call
+}
+
+// GetAllMessagesByChatID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - since *time.Time
+func (_e *MockRepository_Expecter) GetAllMessagesByChatID(ctx interface{}, chatID interface{}, since interface{}) *MockRepository_GetAllMessagesByChatID_Call {
+	return &MockRepository_GetAllMessagesByChatID_Call{Call: _e.mock.On("GetAllMessagesByChatID", ctx, chatID, since)}
+}
+
+func (_c *MockRepository_GetAllMessagesByChatID_Call) Run(run func(ctx context.Context, chatID string, since *time.Time)) *MockRepository_GetAllMessagesByChatID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *time.Time
+		if args[2] != nil {
+			arg2 = args[2].(*time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetAllMessagesByChatID_Call) Return(messages []model.Message, err error) *MockRepository_GetAllMessagesByChatID_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockRepository_GetAllMessagesByChatID_Call) RunAndReturn(run func(ctx context.Context, chatID string, since *time.Time) ([]model.Message, error)) *MockRepository_GetAllMessagesByChatID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachment provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetAttachment(ctx context.Context, chatID string, messageID string, attachmentID string) (*model.Attachment, error) {
+	ret := _mock.Called(ctx, chatID, messageID, attachmentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachment")
+	}
+
+	var r0 *model.Attachment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*model.Attachment, error)); ok {
+		return returnFunc(ctx, chatID, messageID, attachmentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *model.Attachment); ok {
+		r0 = returnFunc(ctx, chatID, messageID, attachmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Attachment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, chatID, messageID, attachmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachment'
+type MockRepository_GetAttachment_Call struct {
+	*mock.Call
+}
+
+// GetAttachment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+//   - messageID string
+//   - attachmentID string
+func (_e *MockRepository_Expecter) GetAttachment(ctx interface{}, chatID interface{}, messageID interface{}, attachmentID interface{}) *MockRepository_GetAttachment_Call {
+	return &MockRepository_GetAttachment_Call{Call: _e.mock.On("GetAttachment", ctx, chatID, messageID, attachmentID)}
+}
+
+func (_c *MockRepository_GetAttachment_Call) Run(run func(ctx context.Context, chatID string, messageID string, attachmentID string)) *MockRepository_GetAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetAttachment_Call) Return(attachment *model.Attachment, err error) *MockRepository_GetAttachment_Call {
+	_c.Call.Return(attachment, err)
+	return _c
+}
+
+func (_c *MockRepository_GetAttachment_Call) RunAndReturn(run func(ctx context.Context, chatID string, messageID string, attachmentID string) (*model.Attachment, error)) *MockRepository_GetAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachmentRefsByChatID provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetAttachmentRefsByChatID(ctx context.Context, chatID string) ([]model.Attachment, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachmentRefsByChatID")
+	}
+
+	var r0 []model.Attachment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.Attachment, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.Attachment); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Attachment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetAttachmentRefsByChatID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachmentRefsByChatID'
+type MockRepository_GetAttachmentRefsByChatID_Call struct {
+	*mock.Call
+}
+
+// GetAttachmentRefsByChatID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockRepository_Expecter) GetAttachmentRefsByChatID(ctx interface{}, chatID interface{}) *MockRepository_GetAttachmentRefsByChatID_Call {
+	return &MockRepository_GetAttachmentRefsByChatID_Call{Call: _e.mock.On("GetAttachmentRefsByChatID", ctx, chatID)}
+}
+
+func (_c *MockRepository_GetAttachmentRefsByChatID_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetAttachmentRefsByChatID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentRefsByChatID_Call) Return(attachments []model.Attachment, err error) *MockRepository_GetAttachmentRefsByChatID_Call {
+	_c.Call.Return(attachments, err)
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentRefsByChatID_Call) RunAndReturn(run func(ctx context.Context, chatID string) ([]model.Attachment, error)) *MockRepository_GetAttachmentRefsByChatID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachmentsByMessageIDs provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []string) ([]model.Attachment, error) {
+	ret := _mock.Called(ctx, messageIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachmentsByMessageIDs")
+	}
+
+	var r0 []model.Attachment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]model.Attachment, error)); ok {
+		return returnFunc(ctx, messageIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []model.Attachment); ok {
+		r0 = returnFunc(ctx, messageIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Attachment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, messageIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetAttachmentsByMessageIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachmentsByMessageIDs'
+type MockRepository_GetAttachmentsByMessageIDs_Call struct {
+	*mock.Call
+}
+
+// GetAttachmentsByMessageIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - messageIDs []string
+func (_e *MockRepository_Expecter) GetAttachmentsByMessageIDs(ctx interface{}, messageIDs interface{}) *MockRepository_GetAttachmentsByMessageIDs_Call {
+	return &MockRepository_GetAttachmentsByMessageIDs_Call{Call: _e.mock.On("GetAttachmentsByMessageIDs", ctx, messageIDs)}
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDs_Call) Run(run func(ctx context.Context, messageIDs []string)) *MockRepository_GetAttachmentsByMessageIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDs_Call) Return(attachments []model.Attachment, err error) *MockRepository_GetAttachmentsByMessageIDs_Call {
+	_c.Call.Return(attachments, err)
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDs_Call) RunAndReturn(run func(ctx context.Context, messageIDs []string) ([]model.Attachment, error)) *MockRepository_GetAttachmentsByMessageIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachmentsByMessageIDsTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetAttachmentsByMessageIDsTx(ctx context.Context, tx *sql.Tx, messageIDs []string) ([]model.Attachment, error) {
+	ret := _mock.Called(ctx, tx, messageIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachmentsByMessageIDsTx")
+	}
+
+	var r0 []model.Attachment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, []string) ([]model.Attachment, error)); ok {
+		return returnFunc(ctx, tx, messageIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, []string) []model.Attachment); ok {
+		r0 = returnFunc(ctx, tx, messageIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Attachment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, []string) error); ok {
+		r1 = returnFunc(ctx, tx, messageIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetAttachmentsByMessageIDsTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachmentsByMessageIDsTx'
+type MockRepository_GetAttachmentsByMessageIDsTx_Call struct {
+	*mock.Call
+}
+
+// GetAttachmentsByMessageIDsTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tx *sql.Tx
+//   - messageIDs []string
+func (_e *MockRepository_Expecter) GetAttachmentsByMessageIDsTx(ctx interface{}, tx interface{}, messageIDs interface{}) *MockRepository_GetAttachmentsByMessageIDsTx_Call {
+	return &MockRepository_GetAttachmentsByMessageIDsTx_Call{Call: _e.mock.On("GetAttachmentsByMessageIDsTx", ctx, tx, messageIDs)}
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDsTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, messageIDs []string)) *MockRepository_GetAttachmentsByMessageIDsTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDsTx_Call) Return(attachments []model.Attachment, err error) *MockRepository_GetAttachmentsByMessageIDsTx_Call {
+	_c.Call.Return(attachments, err)
+	return _c
+}
+
+func (_c *MockRepository_GetAttachmentsByMessageIDsTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, messageIDs []string) ([]model.Attachment, error)) *MockRepository_GetAttachmentsByMessageIDsTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChat provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetChat(ctx context.Context, chatID string) (*model.Chat, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChat")
+	}
+
+	var r0 *model.Chat
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Chat, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Chat); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Chat)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChat'
+type MockRepository_GetChat_Call struct {
+	*mock.Call
+}
+
+// GetChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockRepository_Expecter) GetChat(ctx interface{}, chatID interface{}) *MockRepository_GetChat_Call {
+	return &MockRepository_GetChat_Call{Call: _e.mock.On("GetChat", ctx, chatID)}
+}
+
+func (_c *MockRepository_GetChat_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetChat_Call) Return(chat *model.Chat, err error) *MockRepository_GetChat_Call {
+	_c.Call.Return(chat, err)
+	return _c
+}
+
+func (_c *MockRepository_GetChat_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.Chat, error)) *MockRepository_GetChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChats provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error) {
+	ret := _mock.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChats")
+	}
+
+	var r0 []*model.Chat
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatListOptions) ([]*model.Chat, error)); ok {
+		return returnFunc(ctx, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatListOptions) []*model.Chat); ok {
+		r0 = returnFunc(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Chat)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ChatListOptions) error); ok {
+		r1 = returnFunc(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChats'
+type MockRepository_GetChats_Call struct {
+	*mock.Call
+}
+
+// GetChats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts model.ChatListOptions
+func (_e *MockRepository_Expecter) GetChats(ctx interface{}, opts interface{}) *MockRepository_GetChats_Call {
+	return &MockRepository_GetChats_Call{Call: _e.mock.On("GetChats", ctx, opts)}
+}
+
+func (_c *MockRepository_GetChats_Call) Run(run func(ctx context.Context, opts model.ChatListOptions)) *MockRepository_GetChats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 model.ChatListOptions
+		if args[1] != nil {
+			arg1 = args[1].(model.ChatListOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetChats_Call) Return(chats []*model.Chat, err error) *MockRepository_GetChats_Call {
+	_c.Call.Return(chats, err)
+	return _c
+}
+
+func (_c *MockRepository_GetChats_Call) RunAndReturn(run func(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error)) *MockRepository_GetChats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChunksByCollectionID provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetChunksByCollectionID(ctx context.Context, collectionID string) ([]model.DocumentChunk, error) {
+	ret := _mock.Called(ctx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChunksByCollectionID")
+	}
+
+	var r0 []model.DocumentChunk
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.DocumentChunk, error)); ok {
+		return returnFunc(ctx, collectionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.DocumentChunk); ok {
+		r0 = returnFunc(ctx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.DocumentChunk)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetChunksByCollectionID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChunksByCollectionID'
+type MockRepository_GetChunksByCollectionID_Call struct {
+	*mock.Call
+}
+
+// GetChunksByCollectionID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - collectionID string
+func (_e *MockRepository_Expecter) GetChunksByCollectionID(ctx interface{}, collectionID interface{}) *MockRepository_GetChunksByCollectionID_Call {
+	return &MockRepository_GetChunksByCollectionID_Call{Call: _e.mock.On("GetChunksByCollectionID", ctx, collectionID)}
+}
+
+func (_c *MockRepository_GetChunksByCollectionID_Call) Run(run func(ctx context.Context, collectionID string)) *MockRepository_GetChunksByCollectionID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetChunksByCollectionID_Call) Return(documentChunks []model.DocumentChunk, err error) *MockRepository_GetChunksByCollectionID_Call {
+	_c.Call.Return(documentChunks, err)
+	return _c
+}
+
+func (_c *MockRepository_GetChunksByCollectionID_Call) RunAndReturn(run func(ctx context.Context, collectionID string) ([]model.DocumentChunk, error)) *MockRepository_GetChunksByCollectionID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChunksByCollectionIDTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetChunksByCollectionIDTx(ctx context.Context, tx *sql.Tx, collectionID string) ([]model.DocumentChunk, error) {
+	ret := _mock.Called(ctx, tx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChunksByCollectionIDTx")
+	}
+
+	var r0 []model.DocumentChunk
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) ([]model.DocumentChunk, error)); ok {
+		return returnFunc(ctx, tx, collectionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) []model.DocumentChunk); ok {
+		r0 = returnFunc(ctx, tx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.DocumentChunk)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string) error); ok {
+		r1 = returnFunc(ctx, tx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetChunksByCollectionIDTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChunksByCollectionIDTx'
+type MockRepository_GetChunksByCollectionIDTx_Call struct {
+	*mock.Call
+}
+
+// GetChunksByCollectionIDTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tx *sql.Tx
+//   - collectionID string
+func (_e *MockRepository_Expecter) GetChunksByCollectionIDTx(ctx interface{}, tx interface{}, collectionID interface{}) *MockRepository_GetChunksByCollectionIDTx_Call {
+	return &MockRepository_GetChunksByCollectionIDTx_Call{Call: _e.mock.On("GetChunksByCollectionIDTx", ctx, tx, collectionID)}
+}
+
+func (_c *MockRepository_GetChunksByCollectionIDTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, collectionID string)) *MockRepository_GetChunksByCollectionIDTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetChunksByCollectionIDTx_Call) Return(documentChunks []model.DocumentChunk, err error) *MockRepository_GetChunksByCollectionIDTx_Call {
+	_c.Call.Return(documentChunks, err)
+	return _c
+}
+
+func (_c *MockRepository_GetChunksByCollectionIDTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, collectionID string) ([]model.DocumentChunk, error)) *MockRepository_GetChunksByCollectionIDTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCollection provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetCollection(ctx context.Context, collectionID string) (*model.Collection, error) {
+	ret := _mock.Called(ctx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollection")
+	}
+
+	var r0 *model.Collection
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Collection, error)); ok {
+		return returnFunc(ctx, collectionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Collection); ok {
+		r0 = returnFunc(ctx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Collection)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetCollection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCollection'
+type MockRepository_GetCollection_Call struct {
+	*mock.Call
+}
+
+// GetCollection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - collectionID string
+func (_e *MockRepository_Expecter) GetCollection(ctx interface{}, collectionID interface{}) *MockRepository_GetCollection_Call {
+	return &MockRepository_GetCollection_Call{Call: _e.mock.On("GetCollection", ctx, collectionID)}
+}
+
+func (_c *MockRepository_GetCollection_Call) Run(run func(ctx context.Context, collectionID string)) *MockRepository_GetCollection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetCollection_Call) Return(collection *model.Collection, err error) *MockRepository_GetCollection_Call {
+	_c.Call.Return(collection, err)
+	return _c
+}
+
+func (_c *MockRepository_GetCollection_Call) RunAndReturn(run func(ctx context.Context, collectionID string) (*model.Collection, error)) *MockRepository_GetCollection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCollectionTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetCollectionTx(ctx context.Context, tx *sql.Tx, collectionID string) (*model.Collection, error) {
+	ret := _mock.Called(ctx, tx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollectionTx")
+	}
+
+	var r0 *model.Collection
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) (*model.Collection, error)); ok {
+		return returnFunc(ctx, tx, collectionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) *model.Collection); ok {
+		r0 = returnFunc(ctx, tx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Collection)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string) error); ok {
+		r1 = returnFunc(ctx, tx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetCollectionTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCollectionTx'
+type MockRepository_GetCollectionTx_Call struct {
+	*mock.Call
+}
+
+// GetCollectionTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tx *sql.Tx
+//   - collectionID string
+func (_e *MockRepository_Expecter) GetCollectionTx(ctx interface{}, tx interface{}, collectionID interface{}) *MockRepository_GetCollectionTx_Call {
+	return &MockRepository_GetCollectionTx_Call{Call: _e.mock.On("GetCollectionTx", ctx, tx, collectionID)}
+}
+
+func (_c *MockRepository_GetCollectionTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, collectionID string)) *MockRepository_GetCollectionTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetCollectionTx_Call) Return(collection *model.Collection, err error) *MockRepository_GetCollectionTx_Call {
+	_c.Call.Return(collection, err)
+	return _c
+}
+
+func (_c *MockRepository_GetCollectionTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, collectionID string) (*model.Collection, error)) *MockRepository_GetCollectionTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCollections provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetCollections(ctx context.Context) ([]*model.Collection, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollections")
+	}
+
+	var r0 []*model.Collection
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*model.Collection, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*model.Collection); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Collection)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetCollections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCollections'
+type MockRepository_GetCollections_Call struct {
+	*mock.Call
+}
+
+// GetCollections is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) GetCollections(ctx interface{}) *MockRepository_GetCollections_Call {
+	return &MockRepository_GetCollections_Call{Call: _e.mock.On("GetCollections", ctx)}
+}
+
+func (_c *MockRepository_GetCollections_Call) Run(run func(ctx context.Context)) *MockRepository_GetCollections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetCollections_Call) Return(collections []*model.Collection, err error) *MockRepository_GetCollections_Call {
+	_c.Call.Return(collections, err)
+	return _c
+}
+
+func (_c *MockRepository_GetCollections_Call) RunAndReturn(run func(ctx context.Context) ([]*model.Collection, error)) *MockRepository_GetCollections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDocuments provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetDocuments(ctx context.Context, collectionID string) ([]*model.Document, error) {
+	ret := _mock.Called(ctx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocuments")
+	}
+
+	var r0 []*model.Document
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]*model.Document, error)); ok {
+		return returnFunc(ctx, collectionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []*model.Document); ok {
+		r0 = returnFunc(ctx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Document)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetDocuments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDocuments'
+type MockRepository_GetDocuments_Call struct {
+	*mock.Call
+}
+
+// GetDocuments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - collectionID string
+func (_e *MockRepository_Expecter) GetDocuments(ctx interface{}, collectionID interface{}) *MockRepository_GetDocuments_Call {
+	return &MockRepository_GetDocuments_Call{Call: _e.mock.On("GetDocuments", ctx, collectionID)}
+}
+
+func (_c *MockRepository_GetDocuments_Call) Run(run func(ctx context.Context, collectionID string)) *MockRepository_GetDocuments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetDocuments_Call) Return(documents []*model.Document, err error) *MockRepository_GetDocuments_Call {
+	_c.Call.Return(documents, err)
+	return _c
+}
+
+func (_c *MockRepository_GetDocuments_Call) RunAndReturn(run func(ctx context.Context, collectionID string) ([]*model.Document, error)) *MockRepository_GetDocuments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGlobalStats provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetGlobalStats(ctx context.Context) (*model.GlobalStats, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGlobalStats")
+	}
+
+	var r0 *model.GlobalStats
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*model.GlobalStats, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *model.GlobalStats); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GlobalStats)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetGlobalStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGlobalStats'
+type MockRepository_GetGlobalStats_Call struct {
+	*mock.Call
+}
+
+// GetGlobalStats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) GetGlobalStats(ctx interface{}) *MockRepository_GetGlobalStats_Call {
+	return &MockRepository_GetGlobalStats_Call{Call: _e.mock.On("GetGlobalStats", ctx)}
+}
+
+func (_c *MockRepository_GetGlobalStats_Call) Run(run func(ctx context.Context)) *MockRepository_GetGlobalStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetGlobalStats_Call) Return(globalStats *model.GlobalStats, err error) *MockRepository_GetGlobalStats_Call {
+	_c.Call.Return(globalStats, err)
+	return _c
+}
+
+func (_c *MockRepository_GetGlobalStats_Call) RunAndReturn(run func(ctx context.Context) (*model.GlobalStats, error)) *MockRepository_GetGlobalStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetIdempotencyRecord provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetIdempotencyRecord(ctx context.Context, key string, notBefore time.Time) (*model.IdempotencyRecord, error) {
+	ret := _mock.Called(ctx, key, notBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotencyRecord")
+	}
+
+	var r0 *model.IdempotencyRecord
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (*model.IdempotencyRecord, error)); ok {
+		return returnFunc(ctx, key, notBefore)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) *model.IdempotencyRecord); ok {
+		r0 = returnFunc(ctx, key, notBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotencyRecord)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, key, notBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetIdempotencyRecord_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIdempotencyRecord'
+type MockRepository_GetIdempotencyRecord_Call struct {
+	*mock.Call
+}
+
+// GetIdempotencyRecord is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - notBefore time.Time
+func (_e *MockRepository_Expecter) GetIdempotencyRecord(ctx interface{}, key interface{}, notBefore interface{}) *MockRepository_GetIdempotencyRecord_Call {
+	return &MockRepository_GetIdempotencyRecord_Call{Call: _e.mock.On("GetIdempotencyRecord", ctx, key, notBefore)}
+}
+
+func (_c *MockRepository_GetIdempotencyRecord_Call) Run(run func(ctx context.Context, key string, notBefore time.Time)) *MockRepository_GetIdempotencyRecord_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetIdempotencyRecord_Call) Return(idempotencyRecord *model.IdempotencyRecord, err error) *MockRepository_GetIdempotencyRecord_Call {
+	_c.Call.Return(idempotencyRecord, err)
+	return _c
+}
+
+func (_c *MockRepository_GetIdempotencyRecord_Call) RunAndReturn(run func(ctx context.Context, key string, notBefore time.Time) (*model.IdempotencyRecord, error)) *MockRepository_GetIdempotencyRecord_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLastActiveMessage provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error) {
+	ret := _mock.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastActiveMessage")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Message, error)); ok {
+		return returnFunc(ctx, chatID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Message); ok {
+		r0 = returnFunc(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetLastActiveMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastActiveMessage'
+type MockRepository_GetLastActiveMessage_Call struct {
+	*mock.Call
+}
+
+// GetLastActiveMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID string
+func (_e *MockRepository_Expecter) GetLastActiveMessage(ctx interface{}, chatID interface{}) *MockRepository_GetLastActiveMessage_Call {
+	return &MockRepository_GetLastActiveMessage_Call{Call: _e.mock.On("GetLastActiveMessage", ctx, chatID)}
+}
+
+func (_c *MockRepository_GetLastActiveMessage_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetLastActiveMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetLastActiveMessage_Call) Return(message *model.Message, err error) *MockRepository_GetLastActiveMessage_Call {
+	_c.Call.Return(message, err)
+	return _c
+}
+
+func (_c *MockRepository_GetLastActiveMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.Message, error)) *MockRepository_GetLastActiveMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessageByID provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetMessageByID(ctx context.Context, messageID string) (*model.Message, error) {
+	ret := _mock.Called(ctx, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageByID")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Message, error)); ok {
+		return returnFunc(ctx, messageID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Message); ok {
+		r0 = returnFunc(ctx, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetMessageByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageByID'
+type MockRepository_GetMessageByID_Call struct {
+	*mock.Call
+}
+
+// GetMessageByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - messageID string
+func (_e *MockRepository_Expecter) GetMessageByID(ctx interface{}, messageID interface{}) *MockRepository_GetMessageByID_Call {
+	return &MockRepository_GetMessageByID_Call{Call: _e.mock.On("GetMessageByID", ctx, messageID)}
+}
+
+func (_c *MockRepository_GetMessageByID_Call) Run(run func(ctx context.Context, messageID string)) *MockRepository_GetMessageByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetMessageByID_Call) Return(message *model.Message, err error) *MockRepository_GetMessageByID_Call {
+	_c.Call.Return(message, err)
+	return _c
+}
+
+func (_c *MockRepository_GetMessageByID_Call) RunAndReturn(run func(ctx context.Context, messageID string) (*model.Message, error)) *MockRepository_GetMessageByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessageByIDTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetMessageByIDTx(ctx context.Context, tx *sql.Tx, messageID string) (*model.Message, error) {
+	ret := _mock.Called(ctx, tx, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageByIDTx")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) (*model.Message, error)); ok {
+		return returnFunc(ctx, tx, messageID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) *model.Message); ok {
+		r0 = returnFunc(ctx, tx, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string) error); ok {
+		r1 = returnFunc(ctx, tx, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetMessageByIDTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageByIDTx'
+type MockRepository_GetMessageByIDTx_Call struct {
+	*mock.Call
+}
+
+// GetMessageByIDTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tx *sql.Tx
+//   - messageID string
+func (_e *MockRepository_Expecter) GetMessageByIDTx(ctx interface{}, tx interface{}, messageID interface{}) *MockRepository_GetMessageByIDTx_Call {
+	return &MockRepository_GetMessageByIDTx_Call{Call: _e.mock.On("GetMessageByIDTx", ctx, tx, messageID)}
+}
+
+func (_c *MockRepository_GetMessageByIDTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, messageID string)) *MockRepository_GetMessageByIDTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetMessageByIDTx_Call) Return(message *model.Message, err error) *MockRepository_GetMessageByIDTx_Call {
+	_c.Call.Return(message, err)
+	return _c
+}
+
+func (_c *MockRepository_GetMessageByIDTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, messageID string) (*model.Message, error)) *MockRepository_GetMessageByIDTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetModelBenchmarks provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetModelBenchmarks(ctx context.Context) ([]model.ModelBenchmark, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModelBenchmarks")
+	}
+
+	var r0 []model.ModelBenchmark
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.ModelBenchmark, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.ModelBenchmark); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ModelBenchmark)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetModelBenchmarks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetModelBenchmarks'
+type MockRepository_GetModelBenchmarks_Call struct {
+	*mock.Call
+}
+
+// GetModelBenchmarks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) GetModelBenchmarks(ctx interface{}) *MockRepository_GetModelBenchmarks_Call {
+	return &MockRepository_GetModelBenchmarks_Call{Call: _e.mock.On("GetModelBenchmarks", ctx)}
+}
+
+func (_c *MockRepository_GetModelBenchmarks_Call) Run(run func(ctx context.Context)) *MockRepository_GetModelBenchmarks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetModelBenchmarks_Call) Return(modelBenchmarks []model.ModelBenchmark, err error) *MockRepository_GetModelBenchmarks_Call {
+	_c.Call.Return(modelBenchmarks, err)
+	return _c
+}
+
+func (_c *MockRepository_GetModelBenchmarks_Call) RunAndReturn(run func(ctx context.Context) ([]model.ModelBenchmark, error)) *MockRepository_GetModelBenchmarks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetModelDefaults provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetModelDefaults(ctx context.Context, modelName string) (*model.ModelDefaults, error) {
+	ret := _mock.Called(ctx, modelName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModelDefaults")
+	}
+
+	var r0 *model.ModelDefaults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.ModelDefaults, error)); ok {
+		return returnFunc(ctx, modelName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.ModelDefaults); ok {
+		r0 = returnFunc(ctx, modelName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ModelDefaults)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, modelName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetModelDefaults_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetModelDefaults'
+type MockRepository_GetModelDefaults_Call struct {
+	*mock.Call
+}
+
+// GetModelDefaults is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelName string
+func (_e *MockRepository_Expecter) GetModelDefaults(ctx interface{}, modelName interface{}) *MockRepository_GetModelDefaults_Call {
+	return &MockRepository_GetModelDefaults_Call{Call: _e.mock.On("GetModelDefaults", ctx, modelName)}
+}
+
+func (_c *MockRepository_GetModelDefaults_Call) Run(run func(ctx context.Context, modelName string)) *MockRepository_GetModelDefaults_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetModelDefaults_Call) Return(modelDefaults *model.ModelDefaults, err error) *MockRepository_GetModelDefaults_Call {
+	_c.Call.Return(modelDefaults, err)
+	return _c
+}
+
+func (_c *MockRepository_GetModelDefaults_Call) RunAndReturn(run func(ctx context.Context, modelName string) (*model.ModelDefaults, error)) *MockRepository_GetModelDefaults_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPrompt provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error) {
+	ret := _mock.Called(ctx, promptID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPrompt")
+	}
+
+	var r0 *model.Prompt
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Prompt, error)); ok {
+		return returnFunc(ctx, promptID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Prompt); ok {
+		r0 = returnFunc(ctx, promptID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Prompt)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, promptID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetPrompt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPrompt'
+type MockRepository_GetPrompt_Call struct {
+	*mock.Call
+}
+
+// GetPrompt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - promptID string
+func (_e *MockRepository_Expecter) GetPrompt(ctx interface{}, promptID interface{}) *MockRepository_GetPrompt_Call {
+	return &MockRepository_GetPrompt_Call{Call: _e.mock.On("GetPrompt", ctx, promptID)}
+}
+
+func (_c *MockRepository_GetPrompt_Call) Run(run func(ctx context.Context, promptID string)) *MockRepository_GetPrompt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetPrompt_Call) Return(prompt *model.Prompt, err error) *MockRepository_GetPrompt_Call {
+	_c.Call.Return(prompt, err)
+	return _c
+}
+
+func (_c *MockRepository_GetPrompt_Call) RunAndReturn(run func(ctx context.Context, promptID string) (*model.Prompt, error)) *MockRepository_GetPrompt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPrompts provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetPrompts(ctx context.Context) ([]*model.Prompt, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPrompts")
+	}
+
+	var r0 []*model.Prompt
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*model.Prompt, error)); ok {
 		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) []*model.Chat); ok {
-		r0 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*model.Prompt); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Prompt)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetPrompts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPrompts'
+type MockRepository_GetPrompts_Call struct {
+	*mock.Call
+}
+
+// GetPrompts is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) GetPrompts(ctx interface{}) *MockRepository_GetPrompts_Call {
+	return &MockRepository_GetPrompts_Call{Call: _e.mock.On("GetPrompts", ctx)}
+}
+
+func (_c *MockRepository_GetPrompts_Call) Run(run func(ctx context.Context)) *MockRepository_GetPrompts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetPrompts_Call) Return(prompts []*model.Prompt, err error) *MockRepository_GetPrompts_Call {
+	_c.Call.Return(prompts, err)
+	return _c
+}
+
+func (_c *MockRepository_GetPrompts_Call) RunAndReturn(run func(ctx context.Context) ([]*model.Prompt, error)) *MockRepository_GetPrompts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Optimize provides a mock function for the type MockRepository
+func (_mock *MockRepository) Optimize(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Optimize")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_Optimize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Optimize'
+type MockRepository_Optimize_Call struct {
+	*mock.Call
+}
+
+// Optimize is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Optimize(ctx interface{}) *MockRepository_Optimize_Call {
+	return &MockRepository_Optimize_Call{Call: _e.mock.On("Optimize", ctx)}
+}
+
+func (_c *MockRepository_Optimize_Call) Run(run func(ctx context.Context)) *MockRepository_Optimize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_Optimize_Call) Return(err error) *MockRepository_Optimize_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_Optimize_Call) RunAndReturn(run func(ctx context.Context) error) *MockRepository_Optimize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PruneChats provides a mock function for the type MockRepository
+func (_mock *MockRepository) PruneChats(ctx context.Context, opts model.ChatPruneOptions) (int, error) {
+	ret := _mock.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneChats")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatPruneOptions) (int, error)); ok {
+		return returnFunc(ctx, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ChatPruneOptions) int); ok {
+		r0 = returnFunc(ctx, opts)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ChatPruneOptions) error); ok {
+		r1 = returnFunc(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_PruneChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PruneChats'
+type MockRepository_PruneChats_Call struct {
+	*mock.Call
+}
+
+// PruneChats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts model.ChatPruneOptions
+func (_e *MockRepository_Expecter) PruneChats(ctx interface{}, opts interface{}) *MockRepository_PruneChats_Call {
+	return &MockRepository_PruneChats_Call{Call: _e.mock.On("PruneChats", ctx, opts)}
+}
+
+func (_c *MockRepository_PruneChats_Call) Run(run func(ctx context.Context, opts model.ChatPruneOptions)) *MockRepository_PruneChats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 model.ChatPruneOptions
+		if args[1] != nil {
+			arg1 = args[1].(model.ChatPruneOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_PruneChats_Call) Return(n int, err error) *MockRepository_PruneChats_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockRepository_PruneChats_Call) RunAndReturn(run func(ctx context.Context, opts model.ChatPruneOptions) (int, error)) *MockRepository_PruneChats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveIdempotencyRecord provides a mock function for the type MockRepository
+func (_mock *MockRepository) SaveIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error {
+	ret := _mock.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveIdempotencyRecord")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.IdempotencyRecord) error); ok {
+		r0 = returnFunc(ctx, record)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model.Chat)
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_SaveIdempotencyRecord_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveIdempotencyRecord'
+type MockRepository_SaveIdempotencyRecord_Call struct {
+	*mock.Call
+}
+
+// SaveIdempotencyRecord is a helper method to define mock.On call
+//   - ctx context.Context
+//   - record *model.IdempotencyRecord
+func (_e *MockRepository_Expecter) SaveIdempotencyRecord(ctx interface{}, record interface{}) *MockRepository_SaveIdempotencyRecord_Call {
+	return &MockRepository_SaveIdempotencyRecord_Call{Call: _e.mock.On("SaveIdempotencyRecord", ctx, record)}
+}
+
+func (_c *MockRepository_SaveIdempotencyRecord_Call) Run(run func(ctx context.Context, record *model.IdempotencyRecord)) *MockRepository_SaveIdempotencyRecord_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *model.IdempotencyRecord
+		if args[1] != nil {
+			arg1 = args[1].(*model.IdempotencyRecord)
 		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_SaveIdempotencyRecord_Call) Return(err error) *MockRepository_SaveIdempotencyRecord_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_SaveIdempotencyRecord_Call) RunAndReturn(run func(ctx context.Context, record *model.IdempotencyRecord) error) *MockRepository_SaveIdempotencyRecord_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveModelBenchmark provides a mock function for the type MockRepository
+func (_mock *MockRepository) SaveModelBenchmark(ctx context.Context, benchmark *model.ModelBenchmark) error {
+	ret := _mock.Called(ctx, benchmark)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveModelBenchmark")
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.ModelBenchmark) error); ok {
+		r0 = returnFunc(ctx, benchmark)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetChats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChats'
-type MockRepository_GetChats_Call struct {
+// MockRepository_SaveModelBenchmark_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveModelBenchmark'
+type MockRepository_SaveModelBenchmark_Call struct {
 	*mock.Call
 }
 
-// GetChats is a helper method to define mock.On call
+// SaveModelBenchmark is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockRepository_Expecter) GetChats(ctx interface{}) *MockRepository_GetChats_Call {
-	return &MockRepository_GetChats_Call{Call: _e.mock.On("GetChats", ctx)}
+//   - benchmark *model.ModelBenchmark
+func (_e *MockRepository_Expecter) SaveModelBenchmark(ctx interface{}, benchmark interface{}) *MockRepository_SaveModelBenchmark_Call {
+	return &MockRepository_SaveModelBenchmark_Call{Call: _e.mock.On("SaveModelBenchmark", ctx, benchmark)}
 }
 
-func (_c *MockRepository_GetChats_Call) Run(run func(ctx context.Context)) *MockRepository_GetChats_Call {
+func (_c *MockRepository_SaveModelBenchmark_Call) Run(run func(ctx context.Context, benchmark *model.ModelBenchmark)) *MockRepository_SaveModelBenchmark_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
+		var arg1 *model.ModelBenchmark
+		if args[1] != nil {
+			arg1 = args[1].(*model.ModelBenchmark)
+		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRepository_GetChats_Call) Return(chats []*model.Chat, err error) *MockRepository_GetChats_Call {
-	_c.Call.Return(chats, err)
+func (_c *MockRepository_SaveModelBenchmark_Call) Return(err error) *MockRepository_SaveModelBenchmark_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetChats_Call) RunAndReturn(run func(ctx context.Context) ([]*model.Chat, error)) *MockRepository_GetChats_Call {
+func (_c *MockRepository_SaveModelBenchmark_Call) RunAndReturn(run func(ctx context.Context, benchmark *model.ModelBenchmark) error) *MockRepository_SaveModelBenchmark_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetLastActiveMessage provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error) {
-	ret := _mock.Called(ctx, chatID)
+// SaveModelDefaults provides a mock function for the type MockRepository
+func (_mock *MockRepository) SaveModelDefaults(ctx context.Context, defaults *model.ModelDefaults) error {
+	ret := _mock.Called(ctx, defaults)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetLastActiveMessage")
+		panic("no return value specified for SaveModelDefaults")
 	}
 
-	var r0 *model.Message
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Message, error)); ok {
-		return returnFunc(ctx, chatID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Message); ok {
-		r0 = returnFunc(ctx, chatID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.ModelDefaults) error); ok {
+		r0 = returnFunc(ctx, defaults)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Message)
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_SaveModelDefaults_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveModelDefaults'
+type MockRepository_SaveModelDefaults_Call struct {
+	*mock.Call
+}
+
+// SaveModelDefaults is a helper method to define mock.On call
+//   - ctx context.Context
+//   - defaults *model.ModelDefaults
+func (_e *MockRepository_Expecter) SaveModelDefaults(ctx interface{}, defaults interface{}) *MockRepository_SaveModelDefaults_Call {
+	return &MockRepository_SaveModelDefaults_Call{Call: _e.mock.On("SaveModelDefaults", ctx, defaults)}
+}
+
+func (_c *MockRepository_SaveModelDefaults_Call) Run(run func(ctx context.Context, defaults *model.ModelDefaults)) *MockRepository_SaveModelDefaults_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
 		}
+		var arg1 *model.ModelDefaults
+		if args[1] != nil {
+			arg1 = args[1].(*model.ModelDefaults)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_SaveModelDefaults_Call) Return(err error) *MockRepository_SaveModelDefaults_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_SaveModelDefaults_Call) RunAndReturn(run func(ctx context.Context, defaults *model.ModelDefaults) error) *MockRepository_SaveModelDefaults_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetChatTags provides a mock function for the type MockRepository
+func (_mock *MockRepository) SetChatTags(ctx context.Context, chatID string, tags []string) error {
+	ret := _mock.Called(ctx, chatID, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetChatTags")
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, chatID)
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = returnFunc(ctx, chatID, tags)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetLastActiveMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastActiveMessage'
-type MockRepository_GetLastActiveMessage_Call struct {
+// MockRepository_SetChatTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetChatTags'
+type MockRepository_SetChatTags_Call struct {
 	*mock.Call
 }
 
-// GetLastActiveMessage is a helper method to define mock.On call
+// SetChatTags is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockRepository_Expecter) GetLastActiveMessage(ctx interface{}, chatID interface{}) *MockRepository_GetLastActiveMessage_Call {
-	return &MockRepository_GetLastActiveMessage_Call{Call: _e.mock.On("GetLastActiveMessage", ctx, chatID)}
+//   - tags []string
+func (_e *MockRepository_Expecter) SetChatTags(ctx interface{}, chatID interface{}, tags interface{}) *MockRepository_SetChatTags_Call {
+	return &MockRepository_SetChatTags_Call{Call: _e.mock.On("SetChatTags", ctx, chatID, tags)}
 }
 
-func (_c *MockRepository_GetLastActiveMessage_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetLastActiveMessage_Call {
+func (_c *MockRepository_SetChatTags_Call) Run(run func(ctx context.Context, chatID string, tags []string)) *MockRepository_SetChatTags_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -795,65 +3027,60 @@ func (_c *MockRepository_GetLastActiveMessage_Call) Run(run func(ctx context.Con
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRepository_GetLastActiveMessage_Call) Return(message *model.Message, err error) *MockRepository_GetLastActiveMessage_Call {
-	_c.Call.Return(message, err)
+func (_c *MockRepository_SetChatTags_Call) Return(err error) *MockRepository_SetChatTags_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetLastActiveMessage_Call) RunAndReturn(run func(ctx context.Context, chatID string) (*model.Message, error)) *MockRepository_GetLastActiveMessage_Call {
+func (_c *MockRepository_SetChatTags_Call) RunAndReturn(run func(ctx context.Context, chatID string, tags []string) error) *MockRepository_SetChatTags_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetMessageByID provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetMessageByID(ctx context.Context, messageID string) (*model.Message, error) {
-	ret := _mock.Called(ctx, messageID)
+// UpdateChatCollection provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateChatCollection(ctx context.Context, chatID string, collectionID string) error {
+	ret := _mock.Called(ctx, chatID, collectionID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessageByID")
+		panic("no return value specified for UpdateChatCollection")
 	}
 
-	var r0 *model.Message
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Message, error)); ok {
-		return returnFunc(ctx, messageID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Message); ok {
-		r0 = returnFunc(ctx, messageID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Message)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, messageID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, chatID, collectionID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetMessageByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageByID'
-type MockRepository_GetMessageByID_Call struct {
+// MockRepository_UpdateChatCollection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateChatCollection'
+type MockRepository_UpdateChatCollection_Call struct {
 	*mock.Call
 }
 
-// GetMessageByID is a helper method to define mock.On call
+// UpdateChatCollection is a helper method to define mock.On call
 //   - ctx context.Context
-//   - messageID string
-func (_e *MockRepository_Expecter) GetMessageByID(ctx interface{}, messageID interface{}) *MockRepository_GetMessageByID_Call {
-	return &MockRepository_GetMessageByID_Call{Call: _e.mock.On("GetMessageByID", ctx, messageID)}
+//   - chatID string
+//   - collectionID string
+func (_e *MockRepository_Expecter) UpdateChatCollection(ctx interface{}, chatID interface{}, collectionID interface{}) *MockRepository_UpdateChatCollection_Call {
+	return &MockRepository_UpdateChatCollection_Call{Call: _e.mock.On("UpdateChatCollection", ctx, chatID, collectionID)}
 }
 
-func (_c *MockRepository_GetMessageByID_Call) Run(run func(ctx context.Context, messageID string)) *MockRepository_GetMessageByID_Call {
+func (_c *MockRepository_UpdateChatCollection_Call) Run(run func(ctx context.Context, chatID string, collectionID string)) *MockRepository_UpdateChatCollection_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -863,65 +3090,60 @@ func (_c *MockRepository_GetMessageByID_Call) Run(run func(ctx context.Context,
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRepository_GetMessageByID_Call) Return(message *model.Message, err error) *MockRepository_GetMessageByID_Call {
-	_c.Call.Return(message, err)
+func (_c *MockRepository_UpdateChatCollection_Call) Return(err error) *MockRepository_UpdateChatCollection_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetMessageByID_Call) RunAndReturn(run func(ctx context.Context, messageID string) (*model.Message, error)) *MockRepository_GetMessageByID_Call {
+func (_c *MockRepository_UpdateChatCollection_Call) RunAndReturn(run func(ctx context.Context, chatID string, collectionID string) error) *MockRepository_UpdateChatCollection_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetMessagesByChatID provides a mock function for the type MockRepository
-func (_mock *MockRepository) GetMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error) {
-	ret := _mock.Called(ctx, chatID)
+// UpdateChatPinned provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateChatPinned(ctx context.Context, chatID string, pinned bool) error {
+	ret := _mock.Called(ctx, chatID, pinned)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessagesByChatID")
+		panic("no return value specified for UpdateChatPinned")
 	}
 
-	var r0 []model.Message
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.Message, error)); ok {
-		return returnFunc(ctx, chatID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.Message); ok {
-		r0 = returnFunc(ctx, chatID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Message)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, chatID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = returnFunc(ctx, chatID, pinned)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockRepository_GetMessagesByChatID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessagesByChatID'
-type MockRepository_GetMessagesByChatID_Call struct {
+// MockRepository_UpdateChatPinned_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateChatPinned'
+type MockRepository_UpdateChatPinned_Call struct {
 	*mock.Call
 }
 
-// GetMessagesByChatID is a helper method to define mock.On call
+// UpdateChatPinned is a helper method to define mock.On call
 //   - ctx context.Context
 //   - chatID string
-func (_e *MockRepository_Expecter) GetMessagesByChatID(ctx interface{}, chatID interface{}) *MockRepository_GetMessagesByChatID_Call {
-	return &MockRepository_GetMessagesByChatID_Call{Call: _e.mock.On("GetMessagesByChatID", ctx, chatID)}
+//   - pinned bool
+func (_e *MockRepository_Expecter) UpdateChatPinned(ctx interface{}, chatID interface{}, pinned interface{}) *MockRepository_UpdateChatPinned_Call {
+	return &MockRepository_UpdateChatPinned_Call{Call: _e.mock.On("UpdateChatPinned", ctx, chatID, pinned)}
 }
 
-func (_c *MockRepository_GetMessagesByChatID_Call) Run(run func(ctx context.Context, chatID string)) *MockRepository_GetMessagesByChatID_Call {
+func (_c *MockRepository_UpdateChatPinned_Call) Run(run func(ctx context.Context, chatID string, pinned bool)) *MockRepository_UpdateChatPinned_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -931,20 +3153,25 @@ func (_c *MockRepository_GetMessagesByChatID_Call) Run(run func(ctx context.Cont
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRepository_GetMessagesByChatID_Call) Return(messages []model.Message, err error) *MockRepository_GetMessagesByChatID_Call {
-	_c.Call.Return(messages, err)
+func (_c *MockRepository_UpdateChatPinned_Call) Return(err error) *MockRepository_UpdateChatPinned_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_GetMessagesByChatID_Call) RunAndReturn(run func(ctx context.Context, chatID string) ([]model.Message, error)) *MockRepository_GetMessagesByChatID_Call {
+func (_c *MockRepository_UpdateChatPinned_Call) RunAndReturn(run func(ctx context.Context, chatID string, pinned bool) error) *MockRepository_UpdateChatPinned_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1075,65 +3302,134 @@ func (_c *MockRepository_UpdateChatTitle_Call) RunAndReturn(run func(ctx context
 	return _c
 }
 
-// UpdateMessageContext provides a mock function for the type MockRepository
-func (_mock *MockRepository) UpdateMessageContext(ctx context.Context, messageID string, ollamaContext []byte) error {
-	ret := _mock.Called(ctx, messageID, ollamaContext)
+// UpdateMessageContentTx provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateMessageContentTx(ctx context.Context, tx *sql.Tx, messageID string, content string, metadata json.RawMessage) error {
+	ret := _mock.Called(ctx, tx, messageID, content, metadata)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateMessageContext")
+		panic("no return value specified for UpdateMessageContentTx")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
-		r0 = returnFunc(ctx, messageID, ollamaContext)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string, json.RawMessage) error); ok {
+		r0 = returnFunc(ctx, tx, messageID, content, metadata)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockRepository_UpdateMessageContext_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessageContext'
-type MockRepository_UpdateMessageContext_Call struct {
+// MockRepository_UpdateMessageContentTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessageContentTx'
+type MockRepository_UpdateMessageContentTx_Call struct {
 	*mock.Call
 }
 
-// UpdateMessageContext is a helper method to define mock.On call
+// UpdateMessageContentTx is a helper method to define mock.On call
 //   - ctx context.Context
+//   - tx *sql.Tx
 //   - messageID string
-//   - ollamaContext []byte
-func (_e *MockRepository_Expecter) UpdateMessageContext(ctx interface{}, messageID interface{}, ollamaContext interface{}) *MockRepository_UpdateMessageContext_Call {
-	return &MockRepository_UpdateMessageContext_Call{Call: _e.mock.On("UpdateMessageContext", ctx, messageID, ollamaContext)}
+//   - content string
+//   - metadata json.RawMessage
+func (_e *MockRepository_Expecter) UpdateMessageContentTx(ctx interface{}, tx interface{}, messageID interface{}, content interface{}, metadata interface{}) *MockRepository_UpdateMessageContentTx_Call {
+	return &MockRepository_UpdateMessageContentTx_Call{Call: _e.mock.On("UpdateMessageContentTx", ctx, tx, messageID, content, metadata)}
 }
 
-func (_c *MockRepository_UpdateMessageContext_Call) Run(run func(ctx context.Context, messageID string, ollamaContext []byte)) *MockRepository_UpdateMessageContext_Call {
+func (_c *MockRepository_UpdateMessageContentTx_Call) Run(run func(ctx context.Context, tx *sql.Tx, messageID string, content string, metadata json.RawMessage)) *MockRepository_UpdateMessageContentTx_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *sql.Tx
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*sql.Tx)
 		}
-		var arg2 []byte
+		var arg2 string
 		if args[2] != nil {
-			arg2 = args[2].([]byte)
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 json.RawMessage
+		if args[4] != nil {
+			arg4 = args[4].(json.RawMessage)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateMessageContentTx_Call) Return(err error) *MockRepository_UpdateMessageContentTx_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdateMessageContentTx_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, messageID string, content string, metadata json.RawMessage) error) *MockRepository_UpdateMessageContentTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePrompt provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	ret := _mock.Called(ctx, prompt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePrompt")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Prompt) error); ok {
+		r0 = returnFunc(ctx, prompt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdatePrompt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePrompt'
+type MockRepository_UpdatePrompt_Call struct {
+	*mock.Call
+}
+
+// UpdatePrompt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prompt *model.Prompt
+func (_e *MockRepository_Expecter) UpdatePrompt(ctx interface{}, prompt interface{}) *MockRepository_UpdatePrompt_Call {
+	return &MockRepository_UpdatePrompt_Call{Call: _e.mock.On("UpdatePrompt", ctx, prompt)}
+}
+
+func (_c *MockRepository_UpdatePrompt_Call) Run(run func(ctx context.Context, prompt *model.Prompt)) *MockRepository_UpdatePrompt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *model.Prompt
+		if args[1] != nil {
+			arg1 = args[1].(*model.Prompt)
+		}
+		run(
+			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRepository_UpdateMessageContext_Call) Return(err error) *MockRepository_UpdateMessageContext_Call {
+func (_c *MockRepository_UpdatePrompt_Call) Return(err error) *MockRepository_UpdatePrompt_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRepository_UpdateMessageContext_Call) RunAndReturn(run func(ctx context.Context, messageID string, ollamaContext []byte) error) *MockRepository_UpdateMessageContext_Call {
+func (_c *MockRepository_UpdatePrompt_Call) RunAndReturn(run func(ctx context.Context, prompt *model.Prompt) error) *MockRepository_UpdatePrompt_Call {
 	_c.Call.Return(run)
 	return _c
 }
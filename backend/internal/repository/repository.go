@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"flow-ai/backend/internal/model"
+	"time"
 )
 
 // Repository defines the interface for data storage operations.
@@ -14,22 +16,73 @@ type Repository interface {
 
 	CreateChat(ctx context.Context, chat *model.Chat) error
 	GetChat(ctx context.Context, chatID string) (*model.Chat, error)
-	GetChats(ctx context.Context) ([]*model.Chat, error)
+	GetChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error)
 	UpdateChatTitle(ctx context.Context, chatID, newTitle string) error
+	UpdateChatCollection(ctx context.Context, chatID, collectionID string) error
+	UpdateChatPinned(ctx context.Context, chatID string, pinned bool) error
+	SetChatTags(ctx context.Context, chatID string, tags []string) error
 	DeleteChat(ctx context.Context, chatID string) error
+	DeleteChats(ctx context.Context, chatIDs []string) (int, error)
+	PruneChats(ctx context.Context, opts model.ChatPruneOptions) (int, error)
 
 	// Message operations
 	AddMessage(ctx context.Context, message *model.Message, chatID string) error
 	GetMessageByID(ctx context.Context, messageID string) (*model.Message, error)
 	GetActiveMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error)
-	GetMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error)
+	GetActiveMessagesPage(ctx context.Context, chatID string, limit int, before string) ([]model.Message, error)
+	GetAllMessagesByChatID(ctx context.Context, chatID string, since *time.Time) ([]model.Message, error)
 	GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error)
-	UpdateMessageContext(ctx context.Context, messageID string, ollamaContext []byte) error
+
+	// Attachment operations. Attachments are written together with their message by `AddMessage`.
+	GetAttachmentRefsByChatID(ctx context.Context, chatID string) ([]model.Attachment, error)
+	GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []string) ([]model.Attachment, error)
+	GetAttachment(ctx context.Context, chatID, messageID, attachmentID string) (*model.Attachment, error)
+
+	// Idempotency keys of processed message requests
+	GetIdempotencyRecord(ctx context.Context, key string, notBefore time.Time) (*model.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error
+	DeleteIdempotencyRecordsBefore(ctx context.Context, cutoff time.Time) error
+
+	// Document collection operations (retrieval-augmented generation)
+	CreateCollection(ctx context.Context, collection *model.Collection) error
+	GetCollection(ctx context.Context, collectionID string) (*model.Collection, error)
+	GetCollections(ctx context.Context) ([]*model.Collection, error)
+	AddDocument(ctx context.Context, document *model.Document, chunks []model.DocumentChunk) error
+	GetDocuments(ctx context.Context, collectionID string) ([]*model.Document, error)
+	GetChunksByCollectionID(ctx context.Context, collectionID string) ([]model.DocumentChunk, error)
+
+	// Prompt template operations
+	CreatePrompt(ctx context.Context, prompt *model.Prompt) error
+	GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error)
+	GetPrompts(ctx context.Context) ([]*model.Prompt, error)
+	UpdatePrompt(ctx context.Context, prompt *model.Prompt) error
+	DeletePrompt(ctx context.Context, promptID string) error
+
+	// Model default options
+	GetModelDefaults(ctx context.Context, modelName string) (*model.ModelDefaults, error)
+	SaveModelDefaults(ctx context.Context, defaults *model.ModelDefaults) error
+
+	// Model benchmarks
+	GetModelBenchmarks(ctx context.Context) ([]model.ModelBenchmark, error)
+	SaveModelBenchmark(ctx context.Context, benchmark *model.ModelBenchmark) error
+
+	// Aggregate statistics
+	GetGlobalStats(ctx context.Context) (*model.GlobalStats, error)
+
+	// Database maintenance
+	Checkpoint(ctx context.Context) (*model.CheckpointResult, error)
+	Optimize(ctx context.Context) error
 
 	// Transactional operations
+	CreateChatTx(ctx context.Context, tx *sql.Tx, chat *model.Chat) error
 	AddMessageTx(ctx context.Context, tx *sql.Tx, message *model.Message, chatID string) error
 	DeactivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error
+	UpdateMessageContentTx(ctx context.Context, tx *sql.Tx, messageID, content string, metadata json.RawMessage) error
 	ActivateBranchTx(ctx context.Context, tx *sql.Tx, messageID string) error
 	UpdateChatTimestampTx(ctx context.Context, tx *sql.Tx, chatID string) error
 	GetActiveMessagesByChatIDTx(ctx context.Context, tx *sql.Tx, chatID string) ([]model.Message, error)
+	GetMessageByIDTx(ctx context.Context, tx *sql.Tx, messageID string) (*model.Message, error)
+	GetAttachmentsByMessageIDsTx(ctx context.Context, tx *sql.Tx, messageIDs []string) ([]model.Attachment, error)
+	GetCollectionTx(ctx context.Context, tx *sql.Tx, collectionID string) (*model.Collection, error)
+	GetChunksByCollectionIDTx(ctx context.Context, tx *sql.Tx, collectionID string) ([]model.DocumentChunk, error)
 }
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"flow-ai/backend/internal/model"
@@ -36,17 +39,18 @@ func (r *sqliteRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
 
 // --- Chat Methods ---
 
+const insertChatQuery = "INSERT INTO chats (id, user_id, title, model, system_prompt, collection_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
 func (r *sqliteRepository) CreateChat(ctx context.Context, chat *model.Chat) error {
-	query := "INSERT INTO chats (id, title, model, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"
-	_, err := r.db.ExecContext(ctx, query, chat.ID, chat.Title, chat.Model, chat.CreatedAt, chat.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, insertChatQuery, chat.ID, chat.UserID, chat.Title, chat.Model, chat.SystemPrompt, chat.CollectionID, chat.CreatedAt, chat.UpdatedAt)
 	return err
 }
 
 func (r *sqliteRepository) GetChat(ctx context.Context, chatID string) (*model.Chat, error) {
-	query := "SELECT id, title, model, created_at, updated_at FROM chats WHERE id = ?"
+	query := "SELECT id, user_id, title, model, system_prompt, collection_id, pinned, created_at, updated_at FROM chats WHERE id = ?"
 	row := r.db.QueryRowContext(ctx, query, chatID)
 	var chat model.Chat
-	err := row.Scan(&chat.ID, &chat.Title, &chat.Model, &chat.CreatedAt, &chat.UpdatedAt)
+	err := row.Scan(&chat.ID, &chat.UserID, &chat.Title, &chat.Model, &chat.SystemPrompt, &chat.CollectionID, &chat.Pinned, &chat.CreatedAt, &chat.UpdatedAt)
 	if err != nil {
 		// Abstract away the driver-specific error.
 		if errors.Is(err, sql.ErrNoRows) {
@@ -57,11 +61,43 @@ func (r *sqliteRepository) GetChat(ctx context.Context, chatID string) (*model.C
 	return &chat, nil
 }
 
-func (r *sqliteRepository) GetChats(ctx context.Context) ([]*model.Chat, error) {
-	// In the current single-user model, this fetches all chats without filtering.
-	// The query is intentionally simple.
-	query := "SELECT id, title, model, created_at, updated_at FROM chats ORDER BY updated_at DESC"
-	rows, err := r.db.QueryContext(ctx, query)
+// chatSortColumns maps the public sort keys to trusted column names. Only values
+// from this map are ever concatenated into SQL, which rules out injection via `sort`.
+var chatSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+}
+
+func (r *sqliteRepository) GetChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error) {
+	// In the current single-user model, this fetches all chats without user filtering.
+	sortColumn, ok := chatSortColumns[opts.SortBy]
+	if !ok {
+		sortColumn = "updated_at"
+	}
+	direction := "DESC"
+	if opts.Order == "asc" {
+		direction = "ASC"
+	}
+
+	query := "SELECT id, user_id, title, model, system_prompt, collection_id, pinned, created_at, updated_at FROM chats"
+	var conditions []string
+	var args []interface{}
+	if opts.Model != "" {
+		// A chat "used" a model if it was created with it or any of its messages was generated by it.
+		conditions = append(conditions, "(model = ? OR id IN (SELECT chat_id FROM messages WHERE model = ?))")
+		args = append(args, opts.Model, opts.Model)
+	}
+	if opts.Tag != "" {
+		conditions = append(conditions, "id IN (SELECT chat_id FROM chat_tags WHERE tag = ?)")
+		args = append(args, opts.Tag)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Pinned chats always come first; the requested order applies within each group.
+	query += " ORDER BY pinned DESC, " + sortColumn + " " + direction
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,11 +111,17 @@ func (r *sqliteRepository) GetChats(ctx context.Context) ([]*model.Chat, error)
 	var chats []*model.Chat
 	for rows.Next() {
 		var chat model.Chat
-		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &chat.CreatedAt, &chat.UpdatedAt); err != nil {
+		if err := rows.Scan(&chat.ID, &chat.UserID, &chat.Title, &chat.Model, &chat.SystemPrompt, &chat.CollectionID, &chat.Pinned, &chat.CreatedAt, &chat.UpdatedAt); err != nil {
 			return nil, err
 		}
 		chats = append(chats, &chat)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.attachChatTags(ctx, chats); err != nil {
+		return nil, err
+	}
 	return chats, nil
 }
 
@@ -101,9 +143,10 @@ func (r *sqliteRepository) UpdateChatTitle(ctx context.Context, chatID, newTitle
 	return nil
 }
 
-func (r *sqliteRepository) DeleteChat(ctx context.Context, chatID string) error {
-	query := "DELETE FROM chats WHERE id = ?"
-	res, err := r.db.ExecContext(ctx, query, chatID)
+// UpdateChatCollection sets (or, with an empty ID, clears) the collection a chat retrieves from.
+func (r *sqliteRepository) UpdateChatCollection(ctx context.Context, chatID, collectionID string) error {
+	query := "UPDATE chats SET collection_id = ?, updated_at = ? WHERE id = ?"
+	res, err := r.db.ExecContext(ctx, query, collectionID, time.Now().UTC(), chatID)
 	if err != nil {
 		return err
 	}
@@ -117,6 +160,231 @@ func (r *sqliteRepository) DeleteChat(ctx context.Context, chatID string) error
 	return nil
 }
 
+// UpdateChatPinned pins or unpins a chat. Pinning is not an activity, so the
+// chat's `updated_at` is left unchanged.
+func (r *sqliteRepository) UpdateChatPinned(ctx context.Context, chatID string, pinned bool) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE chats SET pinned = ? WHERE id = ?", pinned, chatID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetChatTags replaces the tags of a chat.
+func (r *sqliteRepository) SetChatTags(ctx context.Context, chatID string, tags []string) error {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback SetChatTags transaction", "error", err)
+		}
+	}()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM chats WHERE id = ?", chatID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM chat_tags WHERE chat_id = ?", chatID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO chat_tags (chat_id, tag) VALUES (?, ?)", chatID, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// attachChatTags fills the tags of the given chats with a single query.
+func (r *sqliteRepository) attachChatTags(ctx context.Context, chats []*model.Chat) error {
+	if len(chats) == 0 {
+		return nil
+	}
+	byID := make(map[string]*model.Chat, len(chats))
+	args := make([]interface{}, len(chats))
+	for i, chat := range chats {
+		byID[chat.ID] = chat
+		args[i] = chat.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chats)), ",")
+	query := "SELECT chat_id, tag FROM chat_tags WHERE chat_id IN (" + placeholders + ") ORDER BY tag ASC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in attachChatTags", "error", err)
+		}
+	}()
+
+	for rows.Next() {
+		var chatID, tag string
+		if err := rows.Scan(&chatID, &tag); err != nil {
+			return err
+		}
+		chat := byID[chatID]
+		chat.Tags = append(chat.Tags, tag)
+	}
+	return rows.Err()
+}
+
+// DeleteChat deletes a chat together with its messages, attachments and tags,
+// like `DeleteChats`, but fails with `ErrNotFound` if the chat does not exist.
+func (r *sqliteRepository) DeleteChat(ctx context.Context, chatID string) error {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback DeleteChat transaction", "error", err)
+		}
+	}()
+
+	deleted, err := deleteChatsTx(ctx, tx, []interface{}{chatID})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// PruneChats deletes one batch of chats selected by opts, oldest first, together
+// with their messages, attachments and tags. It returns the number of deleted chats.
+//
+// WHY: Callers delete in small batches until this returns less than `opts.Limit`,
+// so that each write transaction, and the lock it holds on the database, stays short.
+func (r *sqliteRepository) PruneChats(ctx context.Context, opts model.ChatPruneOptions) (int, error) {
+	var conditions []string
+	var args []interface{}
+	if !opts.UpdatedBefore.IsZero() {
+		conditions = append(conditions, "updated_at < ?")
+		args = append(args, opts.UpdatedBefore.UTC())
+	}
+	if opts.KeepNewest > 0 {
+		conditions = append(conditions, "id NOT IN (SELECT id FROM chats WHERE pinned = 0 ORDER BY updated_at DESC LIMIT ?)")
+		args = append(args, opts.KeepNewest)
+	}
+	if len(conditions) == 0 || opts.Limit <= 0 {
+		return 0, nil
+	}
+
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback PruneChats transaction", "error", err)
+		}
+	}()
+
+	query := "SELECT id FROM chats WHERE pinned = 0 AND (" + strings.Join(conditions, " OR ") + ") ORDER BY updated_at ASC LIMIT ?"
+	rows, err := tx.QueryContext(ctx, query, append(args, opts.Limit)...)
+	if err != nil {
+		return 0, err
+	}
+	var chatIDs []interface{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		chatIDs = append(chatIDs, id)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(chatIDs) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := deleteChatsTx(ctx, tx, chatIDs)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// DeleteChats deletes the given chats together with their messages, attachments
+// and tags in a single transaction. IDs that don't exist are skipped; the number
+// of deleted chats is returned.
+func (r *sqliteRepository) DeleteChats(ctx context.Context, chatIDs []string) (int, error) {
+	if len(chatIDs) == 0 {
+		return 0, nil
+	}
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback DeleteChats transaction", "error", err)
+		}
+	}()
+
+	args := make([]interface{}, len(chatIDs))
+	for i, id := range chatIDs {
+		args[i] = id
+	}
+	deleted, err := deleteChatsTx(ctx, tx, args)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// deleteChatsTx deletes chats and their dependent rows and returns the number of
+// deleted chats. Foreign keys are not enforced, so dependent rows are deleted explicitly.
+func deleteChatsTx(ctx context.Context, tx *sql.Tx, chatIDs []interface{}) (int, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chatIDs)), ",")
+	statements := []string{
+		"DELETE FROM message_attachments WHERE message_id IN (SELECT id FROM messages WHERE chat_id IN (" + placeholders + "))",
+		"DELETE FROM messages WHERE chat_id IN (" + placeholders + ")",
+		"DELETE FROM chat_tags WHERE chat_id IN (" + placeholders + ")",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt, chatIDs...); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM chats WHERE id IN ("+placeholders+")", chatIDs...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted), nil
+}
+
 // --- Message Methods ---
 
 // AddMessage wraps the core logic in a transaction to ensure atomicity.
@@ -145,18 +413,26 @@ func (r *sqliteRepository) AddMessage(ctx context.Context, message *model.Messag
 }
 
 func (r *sqliteRepository) GetMessageByID(ctx context.Context, messageID string) (*model.Message, error) {
+	return r.getMessageByID(ctx, r.db, messageID)
+}
+
+func (r *sqliteRepository) GetMessageByIDTx(ctx context.Context, tx *sql.Tx, messageID string) (*model.Message, error) {
+	return r.getMessageByID(ctx, tx, messageID)
+}
+
+func (r *sqliteRepository) getMessageByID(ctx context.Context, q queryable, messageID string) (*model.Message, error) {
 	query := `
-		SELECT id, chat_id, parent_id, role, content, model, timestamp, metadata, context, is_active
+		SELECT id, chat_id, parent_id, role, content, model, timestamp, metadata, is_active
 		FROM messages
 		WHERE id = ?
 	`
-	row := r.db.QueryRowContext(ctx, query, messageID)
+	row := q.QueryRowContext(ctx, query, messageID)
 	var msg model.Message
 	var chatID string
-	var metadata, context, parentID, modelName sql.NullString
+	var metadata, parentID, modelName sql.NullString
 	var isActive bool
 
-	err := row.Scan(&msg.ID, &chatID, &parentID, &msg.Role, &msg.Content, &modelName, &msg.Timestamp, &metadata, &context, &isActive)
+	err := row.Scan(&msg.ID, &chatID, &parentID, &msg.Role, &msg.Content, &modelName, &msg.Timestamp, &metadata, &isActive)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -165,6 +441,7 @@ func (r *sqliteRepository) GetMessageByID(ctx context.Context, messageID string)
 	}
 
 	msg.IsActive = isActive
+	msg.ChatID = chatID
 
 	// Safely assign values from nullable columns to the struct fields.
 	if parentID.Valid {
@@ -176,10 +453,6 @@ func (r *sqliteRepository) GetMessageByID(ctx context.Context, messageID string)
 	if metadata.Valid {
 		msg.Metadata = json.RawMessage(metadata.String)
 	}
-	if context.Valid {
-		msg.Context = json.RawMessage(context.String)
-	}
-
 	return &msg, nil
 }
 
@@ -194,10 +467,10 @@ func (r *sqliteRepository) GetActiveMessagesByChatIDTx(ctx context.Context, tx *
 // getActiveMessagesByChatID is a private helper that can run on either a `*sql.DB` or `*sql.Tx`.
 func (r *sqliteRepository) getActiveMessagesByChatID(ctx context.Context, q queryable, chatID string) ([]model.Message, error) {
 	query := `
-		SELECT id, parent_id, role, content, model, timestamp, metadata, context, is_active
+		SELECT id, parent_id, role, content, model, timestamp, metadata, is_active
 		FROM messages
 		WHERE chat_id = ? AND is_active = TRUE
-		ORDER BY timestamp ASC
+		ORDER BY seq ASC
 	`
 	rows, err := q.QueryContext(ctx, query, chatID)
 	if err != nil {
@@ -208,14 +481,60 @@ func (r *sqliteRepository) getActiveMessagesByChatID(ctx context.Context, q quer
 			slog.Error("Failed to close rows in getActiveMessagesByChatID", "error", err)
 		}
 	}()
+	return scanMessages(rows)
+}
+
+// GetActiveMessagesPage returns up to `limit` active messages of a chat, newest first.
+// If `before` is set, only messages stored before the message with that ID are
+// returned, so the ID of the last message of a page is the cursor for the next one.
+// An unknown cursor fails with `ErrNotFound`.
+//
+// WHY: The cursor is resolved to the message's `seq` rather than compared by
+// timestamp, as messages stored within the same instant would otherwise be
+// skipped at a page boundary.
+func (r *sqliteRepository) GetActiveMessagesPage(ctx context.Context, chatID string, limit int, before string) ([]model.Message, error) {
+	query := `
+		SELECT id, parent_id, role, content, model, timestamp, metadata, is_active
+		FROM messages
+		WHERE chat_id = ? AND is_active = TRUE`
+	args := []any{chatID}
+	if before != "" {
+		var seq int64
+		err := r.db.QueryRowContext(ctx, "SELECT seq FROM messages WHERE id = ? AND chat_id = ?", before, chatID).Scan(&seq)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		query += " AND seq < ?"
+		args = append(args, seq)
+	}
+	query += " ORDER BY seq DESC LIMIT ?"
+	args = append(args, limit)
 
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetActiveMessagesPage", "error", err)
+		}
+	}()
+	return scanMessages(rows)
+}
+
+// scanMessages reads message rows selected with the column list shared by the
+// message list queries.
+func scanMessages(rows *sql.Rows) ([]model.Message, error) {
 	var messages []model.Message
 	for rows.Next() {
 		var msg model.Message
-		var metadata, context, parentID, modelName sql.NullString
+		var metadata, parentID, modelName sql.NullString
 		var isActive bool
 
-		if err := rows.Scan(&msg.ID, &parentID, &msg.Role, &msg.Content, &modelName, &msg.Timestamp, &metadata, &context, &isActive); err != nil {
+		if err := rows.Scan(&msg.ID, &parentID, &msg.Role, &msg.Content, &modelName, &msg.Timestamp, &metadata, &isActive); err != nil {
 			return nil, err
 		}
 		msg.IsActive = isActive
@@ -229,90 +548,481 @@ func (r *sqliteRepository) getActiveMessagesByChatID(ctx context.Context, q quer
 		if metadata.Valid {
 			msg.Metadata = json.RawMessage(metadata.String)
 		}
-		if context.Valid {
-			msg.Context = json.RawMessage(context.String)
-		}
 
 		messages = append(messages, msg)
 	}
-	return messages, nil
+	return messages, rows.Err()
 }
 
-func (r *sqliteRepository) GetMessagesByChatID(ctx context.Context, chatID string) ([]model.Message, error) {
+// GetAllMessagesByChatID returns every message of a chat, including inactive
+// branches, oldest first. If `since` is set, only messages created at or after it
+// are returned.
+func (r *sqliteRepository) GetAllMessagesByChatID(ctx context.Context, chatID string, since *time.Time) ([]model.Message, error) {
 	query := `
-		SELECT id, parent_id, role, content, model, timestamp, metadata, context, is_active
+		SELECT id, parent_id, role, content, model, timestamp, metadata, is_active
 		FROM messages
-		WHERE chat_id = ?
-		ORDER BY timestamp ASC
+		WHERE chat_id = ?`
+	args := []any{chatID}
+	if since != nil {
+		query += " AND timestamp >= ?"
+		args = append(args, since.UTC())
+	}
+	query += " ORDER BY seq ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetAllMessagesByChatID", "error", err)
+		}
+	}()
+	return scanMessages(rows)
+}
+
+func (r *sqliteRepository) GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error) {
+	query := `
+		SELECT id
+		FROM messages
+		WHERE chat_id = ? AND is_active = TRUE
+		ORDER BY seq DESC LIMIT 1
+	`
+	row := r.db.QueryRowContext(ctx, query, chatID)
+
+	var msg model.Message
+	err := row.Scan(&msg.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// In this specific case, returning `ErrNotFound` is more semantically
+			// correct than `nil, nil` for the service layer to interpret.
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+// --- Attachment Methods ---
+
+// GetAttachmentRefsByChatID returns the metadata of all attachments in a chat,
+// without loading their content.
+func (r *sqliteRepository) GetAttachmentRefsByChatID(ctx context.Context, chatID string) ([]model.Attachment, error) {
+	query := `
+		SELECT a.id, a.message_id, a.mime_type, a.size_bytes, a.created_at
+		FROM message_attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE m.chat_id = ?
+		ORDER BY a.created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query, chatID)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		_ = rows.Close()
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetAttachmentRefsByChatID", "error", err)
+		}
 	}()
 
-	var messages []model.Message
+	var attachments []model.Attachment
 	for rows.Next() {
-		var msg model.Message
-		var metadata, context, parentID, modelName sql.NullString
-		var isActive bool
+		var a model.Attachment
+		if err := rows.Scan(&a.ID, &a.MessageID, &a.MimeType, &a.SizeBytes, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// GetAttachmentsByMessageIDs returns the attachments of the given messages, including their content.
+func (r *sqliteRepository) GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []string) ([]model.Attachment, error) {
+	return r.getAttachmentsByMessageIDs(ctx, r.db, messageIDs)
+}
+
+func (r *sqliteRepository) GetAttachmentsByMessageIDsTx(ctx context.Context, tx *sql.Tx, messageIDs []string) ([]model.Attachment, error) {
+	return r.getAttachmentsByMessageIDs(ctx, tx, messageIDs)
+}
+
+func (r *sqliteRepository) getAttachmentsByMessageIDs(ctx context.Context, q queryable, messageIDs []string) ([]model.Attachment, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(messageIDs)), ",")
+	query := `
+		SELECT id, message_id, mime_type, size_bytes, data, created_at
+		FROM message_attachments
+		WHERE message_id IN (` + placeholders + `)
+		ORDER BY created_at ASC
+	`
+	args := make([]interface{}, len(messageIDs))
+	for i, id := range messageIDs {
+		args[i] = id
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in getAttachmentsByMessageIDs", "error", err)
+		}
+	}()
 
-		if err := rows.Scan(&msg.ID, &parentID, &msg.Role, &msg.Content, &modelName, &msg.Timestamp, &metadata, &context, &isActive); err != nil {
+	var attachments []model.Attachment
+	for rows.Next() {
+		var a model.Attachment
+		if err := rows.Scan(&a.ID, &a.MessageID, &a.MimeType, &a.SizeBytes, &a.Data, &a.CreatedAt); err != nil {
 			return nil, err
 		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
 
-		if parentID.Valid {
-			msg.ParentID = &parentID.String
+// GetAttachment returns a single attachment with its content. The attachment must
+// belong to the given message, which in turn must belong to the given chat.
+func (r *sqliteRepository) GetAttachment(ctx context.Context, chatID, messageID, attachmentID string) (*model.Attachment, error) {
+	query := `
+		SELECT a.id, a.message_id, a.mime_type, a.size_bytes, a.data, a.created_at
+		FROM message_attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE a.id = ? AND a.message_id = ? AND m.chat_id = ?
+	`
+	var a model.Attachment
+	err := r.db.QueryRowContext(ctx, query, attachmentID, messageID, chatID).
+		Scan(&a.ID, &a.MessageID, &a.MimeType, &a.SizeBytes, &a.Data, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
 		}
-		if modelName.Valid {
-			msg.Model = &modelName.String
+		return nil, err
+	}
+	return &a, nil
+}
+
+// --- Document Methods ---
+
+// GetIdempotencyRecord returns the record of a key created at or after `notBefore`.
+// Older records are expired and reported as `ErrNotFound`.
+func (r *sqliteRepository) GetIdempotencyRecord(ctx context.Context, key string, notBefore time.Time) (*model.IdempotencyRecord, error) {
+	query := "SELECT key, chat_id, message_id, created_at FROM idempotency_keys WHERE key = ? AND created_at >= ?"
+	var rec model.IdempotencyRecord
+	err := r.db.QueryRowContext(ctx, query, key, notBefore.UTC()).Scan(&rec.Key, &rec.ChatID, &rec.MessageID, &rec.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
 		}
-		if metadata.Valid {
-			msg.Metadata = json.RawMessage(metadata.String)
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// SaveIdempotencyRecord stores a record, replacing an expired record with the same key.
+func (r *sqliteRepository) SaveIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error {
+	query := "INSERT OR REPLACE INTO idempotency_keys (key, chat_id, message_id, created_at) VALUES (?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, record.Key, record.ChatID, record.MessageID, record.CreatedAt.UTC())
+	return err
+}
+
+// DeleteIdempotencyRecordsBefore removes expired records.
+func (r *sqliteRepository) DeleteIdempotencyRecordsBefore(ctx context.Context, cutoff time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < ?", cutoff.UTC())
+	return err
+}
+
+func (r *sqliteRepository) CreateCollection(ctx context.Context, collection *model.Collection) error {
+	query := "INSERT INTO collections (id, name, embedding_model, created_at) VALUES (?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, collection.ID, collection.Name, collection.EmbeddingModel, collection.CreatedAt)
+	return err
+}
+
+func (r *sqliteRepository) GetCollection(ctx context.Context, collectionID string) (*model.Collection, error) {
+	return r.getCollection(ctx, r.db, collectionID)
+}
+
+func (r *sqliteRepository) GetCollectionTx(ctx context.Context, tx *sql.Tx, collectionID string) (*model.Collection, error) {
+	return r.getCollection(ctx, tx, collectionID)
+}
+
+func (r *sqliteRepository) getCollection(ctx context.Context, q queryable, collectionID string) (*model.Collection, error) {
+	query := "SELECT id, name, embedding_model, created_at FROM collections WHERE id = ?"
+	var c model.Collection
+	err := q.QueryRowContext(ctx, query, collectionID).Scan(&c.ID, &c.Name, &c.EmbeddingModel, &c.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
 		}
-		if context.Valid {
-			msg.Context = json.RawMessage(context.String)
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *sqliteRepository) GetCollections(ctx context.Context) ([]*model.Collection, error) {
+	query := "SELECT id, name, embedding_model, created_at FROM collections ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetCollections", "error", err)
 		}
-		msg.IsActive = isActive
+	}()
 
-		messages = append(messages, msg)
+	var collections []*model.Collection
+	for rows.Next() {
+		var c model.Collection
+		if err := rows.Scan(&c.ID, &c.Name, &c.EmbeddingModel, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		collections = append(collections, &c)
 	}
-	return messages, nil
+	return collections, rows.Err()
 }
 
-func (r *sqliteRepository) GetLastActiveMessage(ctx context.Context, chatID string) (*model.Message, error) {
-	query := `
-		SELECT id, context
-		FROM messages
-		WHERE chat_id = ? AND is_active = TRUE
-		ORDER BY timestamp DESC LIMIT 1
+// AddDocument stores a document and all of its chunks in a single transaction.
+func (r *sqliteRepository) AddDocument(ctx context.Context, document *model.Document, chunks []model.DocumentChunk) error {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback AddDocument transaction", "error", err)
+		}
+	}()
+
+	docQuery := "INSERT INTO documents (id, collection_id, name, chunk_count, created_at) VALUES (?, ?, ?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, docQuery, document.ID, document.CollectionID, document.Name, document.ChunkCount, document.CreatedAt); err != nil {
+		return err
+	}
+
+	chunkQuery := `
+		INSERT INTO document_chunks (id, document_id, collection_id, chunk_index, content, embedding)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
-	row := r.db.QueryRowContext(ctx, query, chatID)
+	for _, c := range chunks {
+		if _, err := tx.ExecContext(ctx, chunkQuery, c.ID, document.ID, document.CollectionID, c.Index, c.Content, encodeEmbedding(c.Embedding)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
 
-	var msg model.Message
-	var context sql.NullString
-	err := row.Scan(&msg.ID, &context)
+func (r *sqliteRepository) GetDocuments(ctx context.Context, collectionID string) ([]*model.Document, error) {
+	query := "SELECT id, collection_id, name, chunk_count, created_at FROM documents WHERE collection_id = ? ORDER BY created_at ASC"
+	rows, err := r.db.QueryContext(ctx, query, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetDocuments", "error", err)
+		}
+	}()
+
+	var documents []*model.Document
+	for rows.Next() {
+		var d model.Document
+		if err := rows.Scan(&d.ID, &d.CollectionID, &d.Name, &d.ChunkCount, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		documents = append(documents, &d)
+	}
+	return documents, rows.Err()
+}
+
+// GetChunksByCollectionID loads every chunk of a collection including its embedding.
+// Similarity ranking is done by the caller; a brute-force scan is fast enough for
+// the collection sizes of a single-user, local deployment.
+func (r *sqliteRepository) GetChunksByCollectionID(ctx context.Context, collectionID string) ([]model.DocumentChunk, error) {
+	return r.getChunksByCollectionID(ctx, r.db, collectionID)
+}
+
+func (r *sqliteRepository) GetChunksByCollectionIDTx(ctx context.Context, tx *sql.Tx, collectionID string) ([]model.DocumentChunk, error) {
+	return r.getChunksByCollectionID(ctx, tx, collectionID)
+}
+
+func (r *sqliteRepository) getChunksByCollectionID(ctx context.Context, q queryable, collectionID string) ([]model.DocumentChunk, error) {
+	query := "SELECT id, document_id, chunk_index, content, embedding FROM document_chunks WHERE collection_id = ?"
+	rows, err := q.QueryContext(ctx, query, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in getChunksByCollectionID", "error", err)
+		}
+	}()
+
+	var chunks []model.DocumentChunk
+	for rows.Next() {
+		var c model.DocumentChunk
+		var embedding []byte
+		if err := rows.Scan(&c.ID, &c.DocumentID, &c.Index, &c.Content, &embedding); err != nil {
+			return nil, err
+		}
+		c.Embedding = decodeEmbedding(embedding)
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// encodeEmbedding serializes a vector as little-endian float32 values.
+func encodeEmbedding(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// decodeEmbedding is the inverse of `encodeEmbedding`.
+func decodeEmbedding(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+// --- Prompt Methods ---
+
+func (r *sqliteRepository) CreatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	query := "INSERT INTO prompts (id, name, description, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, prompt.ID, prompt.Name, prompt.Description, prompt.Body, prompt.CreatedAt, prompt.UpdatedAt)
+	return err
+}
+
+func (r *sqliteRepository) GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error) {
+	query := "SELECT id, name, description, body, created_at, updated_at FROM prompts WHERE id = ?"
+	var p model.Prompt
+	err := r.db.QueryRowContext(ctx, query, promptID).Scan(&p.ID, &p.Name, &p.Description, &p.Body, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			// In this specific case, returning `ErrNotFound` is more semantically
-			// correct than `nil, nil` for the service layer to interpret.
 			return nil, ErrNotFound
 		}
 		return nil, err
 	}
+	return &p, nil
+}
 
-	if context.Valid {
-		msg.Context = json.RawMessage(context.String)
+func (r *sqliteRepository) GetPrompts(ctx context.Context) ([]*model.Prompt, error) {
+	query := "SELECT id, name, description, body, created_at, updated_at FROM prompts ORDER BY name ASC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetPrompts", "error", err)
+		}
+	}()
 
-	return &msg, nil
+	var prompts []*model.Prompt
+	for rows.Next() {
+		var p model.Prompt
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Body, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, &p)
+	}
+	return prompts, rows.Err()
 }
 
-func (r *sqliteRepository) UpdateMessageContext(ctx context.Context, messageID string, ollamaContext []byte) error {
-	query := "UPDATE messages SET context = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, ollamaContext, messageID)
+// UpdatePrompt overwrites the name, description, body and update time of a prompt.
+func (r *sqliteRepository) UpdatePrompt(ctx context.Context, prompt *model.Prompt) error {
+	query := "UPDATE prompts SET name = ?, description = ?, body = ?, updated_at = ? WHERE id = ?"
+	res, err := r.db.ExecContext(ctx, query, prompt.Name, prompt.Description, prompt.Body, prompt.UpdatedAt, prompt.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteRepository) DeletePrompt(ctx context.Context, promptID string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM prompts WHERE id = ?", promptID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetModelDefaults(ctx context.Context, modelName string) (*model.ModelDefaults, error) {
+	query := "SELECT model, options, updated_at FROM model_defaults WHERE model = ?"
+	var d model.ModelDefaults
+	var options string
+	err := r.db.QueryRowContext(ctx, query, modelName).Scan(&d.Model, &options, &d.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	d.Options = json.RawMessage(options)
+	return &d, nil
+}
+
+// SaveModelDefaults creates or replaces the default options of a model.
+func (r *sqliteRepository) SaveModelDefaults(ctx context.Context, defaults *model.ModelDefaults) error {
+	query := `
+		INSERT INTO model_defaults (model, options, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(model) DO UPDATE SET options = excluded.options, updated_at = excluded.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, defaults.Model, string(defaults.Options), defaults.UpdatedAt)
+	return err
+}
+
+// GetModelBenchmarks returns the last benchmark of every benchmarked model.
+func (r *sqliteRepository) GetModelBenchmarks(ctx context.Context) ([]model.ModelBenchmark, error) {
+	query := "SELECT model, runs, tokens_per_second, load_ms, first_token_ms, created_at FROM model_benchmarks ORDER BY model"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetModelBenchmarks", "error", err)
+		}
+	}()
+
+	var benchmarks []model.ModelBenchmark
+	for rows.Next() {
+		var b model.ModelBenchmark
+		if err := rows.Scan(&b.Model, &b.Runs, &b.TokensPerSecond, &b.LoadMs, &b.FirstTokenMs, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, b)
+	}
+	return benchmarks, rows.Err()
+}
+
+// SaveModelBenchmark stores a benchmark, replacing the previous one of the model.
+func (r *sqliteRepository) SaveModelBenchmark(ctx context.Context, benchmark *model.ModelBenchmark) error {
+	query := `
+		INSERT INTO model_benchmarks (model, runs, tokens_per_second, load_ms, first_token_ms, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(model) DO UPDATE SET
+			runs = excluded.runs, tokens_per_second = excluded.tokens_per_second,
+			load_ms = excluded.load_ms, first_token_ms = excluded.first_token_ms, created_at = excluded.created_at
+	`
+	_, err := r.db.ExecContext(ctx, query, benchmark.Model, benchmark.Runs, benchmark.TokensPerSecond, benchmark.LoadMs, benchmark.FirstTokenMs, benchmark.CreatedAt)
 	return err
 }
 
@@ -320,6 +1030,12 @@ func (r *sqliteRepository) UpdateMessageContext(ctx context.Context, messageID s
 // These methods expect to be passed an existing transaction `*sql.Tx` and do not commit or rollback.
 // This allows them to be composed into larger atomic operations.
 
+// CreateChatTx creates a chat within an existing transaction.
+func (r *sqliteRepository) CreateChatTx(ctx context.Context, tx *sql.Tx, chat *model.Chat) error {
+	_, err := tx.ExecContext(ctx, insertChatQuery, chat.ID, chat.UserID, chat.Title, chat.Model, chat.SystemPrompt, chat.CollectionID, chat.CreatedAt, chat.UpdatedAt)
+	return err
+}
+
 func (r *sqliteRepository) AddMessageTx(ctx context.Context, tx *sql.Tx, message *model.Message, chatID string) error {
 	// Handle empty or "null" JSON from the model layer gracefully.
 	var metadata sql.NullString
@@ -328,9 +1044,11 @@ func (r *sqliteRepository) AddMessageTx(ctx context.Context, tx *sql.Tx, message
 		metadata.Valid = true
 	}
 
+	// seq numbers the messages of a chat in insertion order, so that messages
+	// with the same timestamp keep a stable order.
 	insertMsgQuery := `
-		INSERT INTO messages (id, chat_id, parent_id, role, content, model, timestamp, metadata, context, is_active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (id, chat_id, parent_id, role, content, model, timestamp, metadata, is_active, seq)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM messages WHERE chat_id = ?))
 	`
 	_, err := tx.ExecContext(ctx, insertMsgQuery,
 		message.ID,
@@ -341,10 +1059,45 @@ func (r *sqliteRepository) AddMessageTx(ctx context.Context, tx *sql.Tx, message
 		message.Model,
 		message.Timestamp,
 		metadata,
-		message.Context,
 		true, // New messages are always active.
+		chatID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	insertAttachmentQuery := `
+		INSERT INTO message_attachments (id, message_id, mime_type, size_bytes, data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, a := range message.Attachments {
+		if _, err := tx.ExecContext(ctx, insertAttachmentQuery, a.ID, message.ID, a.MimeType, a.SizeBytes, a.Data, a.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateMessageContentTx replaces the content and metadata of a message, e.g.
+// when an answer is continued.
+func (r *sqliteRepository) UpdateMessageContentTx(ctx context.Context, tx *sql.Tx, messageID, content string, metadata json.RawMessage) error {
+	var storedMetadata sql.NullString
+	if len(metadata) > 0 && string(metadata) != "null" {
+		storedMetadata = sql.NullString{String: string(metadata), Valid: true}
+	}
+	query := "UPDATE messages SET content = ?, metadata = ? WHERE id = ?"
+	res, err := tx.ExecContext(ctx, query, content, storedMetadata, messageID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 // DeactivateBranchTx performs a recursive update to mark a message and all its
@@ -374,7 +1127,7 @@ func (r *sqliteRepository) ActivateBranchTx(ctx context.Context, tx *sql.Tx, mes
 	// We'll pick the child that was most recently updated or just the first child.
 	// For now, let's just pick one child to make it active.
 	var nextChildID string
-	childQuery := "SELECT id FROM messages WHERE parent_id = ? ORDER BY timestamp DESC LIMIT 1"
+	childQuery := "SELECT id FROM messages WHERE parent_id = ? ORDER BY seq DESC LIMIT 1"
 	err := tx.QueryRowContext(ctx, childQuery, messageID).Scan(&nextChildID)
 	if err == nil {
 		return r.ActivateBranchTx(ctx, tx, nextChildID)
@@ -390,3 +1143,73 @@ func (r *sqliteRepository) UpdateChatTimestampTx(ctx context.Context, tx *sql.Tx
 	_, err := tx.ExecContext(ctx, query, time.Now().UTC(), chatID)
 	return err
 }
+
+// --- Maintenance Methods ---
+
+// GetGlobalStats counts chats and messages in the database without loading them.
+// The queries run in one read transaction, so the counts are consistent.
+func (r *sqliteRepository) GetGlobalStats(ctx context.Context) (*model.GlobalStats, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stats := &model.GlobalStats{MessagesByModel: []model.ModelMessageCount{}}
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM chats").Scan(&stats.Chats); err != nil {
+		return nil, err
+	}
+	query := "SELECT COUNT(*), COALESCE(SUM(is_active), 0) FROM messages"
+	if err := tx.QueryRowContext(ctx, query).Scan(&stats.Messages, &stats.ActiveMessages); err != nil {
+		return nil, err
+	}
+	stats.InactiveMessages = stats.Messages - stats.ActiveMessages
+
+	// Only assistant messages record the model they were generated by.
+	query = `
+		SELECT model, COUNT(*) FROM messages
+		WHERE model IS NOT NULL AND model != ''
+		GROUP BY model
+		ORDER BY COUNT(*) DESC, model
+	`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("Failed to close rows in GetGlobalStats", "error", err)
+		}
+	}()
+	for rows.Next() {
+		var count model.ModelMessageCount
+		if err := rows.Scan(&count.Model, &count.Messages); err != nil {
+			return nil, err
+		}
+		stats.MessagesByModel = append(stats.MessagesByModel, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Checkpoint writes the WAL file back into the database and truncates it. Without
+// it, the WAL file only shrinks when no connection is open, which never happens
+// while the server is running.
+func (r *sqliteRepository) Checkpoint(ctx context.Context) (*model.CheckpointResult, error) {
+	var busy int
+	result := &model.CheckpointResult{}
+	err := r.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &result.LogFrames, &result.CheckpointedFrames)
+	if err != nil {
+		return nil, err
+	}
+	result.Busy = busy != 0
+	return result, nil
+}
+
+// Optimize lets SQLite refresh the query planner statistics where they are stale.
+func (r *sqliteRepository) Optimize(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "PRAGMA optimize")
+	return err
+}
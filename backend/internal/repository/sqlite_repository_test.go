@@ -0,0 +1,627 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/database"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// setupRepository creates a repository backed by a fresh, fully migrated SQLite
+// database in a temporary directory.
+//
+// WHY: Repository methods are thin wrappers around SQL, so mocking the database
+// would only test the mock. A real (but throwaway) database verifies the queries
+// against the actual schema produced by the migrations.
+func setupRepository(t *testing.T) (repository.Repository, *sql.DB) {
+	db, err := database.InitDB(filepath.Join(t.TempDir(), "test.db"), database.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return repository.NewSQLiteRepository(db), db
+}
+
+// seedChat creates a chat with a single assistant message.
+func seedChat(t *testing.T, repo repository.Repository) (chatID, messageID string) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	chat := &model.Chat{ID: "chat1", Title: "Test", Model: "m1", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, repo.CreateChat(ctx, chat))
+	msg := &model.Message{ID: "msg1", Role: "assistant", Content: "Hi", Timestamp: now}
+	require.NoError(t, repo.AddMessage(ctx, msg, chat.ID))
+	return chat.ID, msg.ID
+}
+
+// TestSQLiteRepository_UpdateMessageContentTx verifies that the content and
+// metadata of a message are replaced in place.
+func TestSQLiteRepository_UpdateMessageContentTx(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	chatID, messageID := seedChat(t, repo)
+
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateMessageContentTx(ctx, tx, messageID, "Hi there", json.RawMessage(`{"eval_count": 2}`)))
+	assert.ErrorIs(t, repo.UpdateMessageContentTx(ctx, tx, "missing", "x", nil), repository.ErrNotFound)
+	require.NoError(t, tx.Commit())
+
+	messages, err := repo.GetActiveMessagesByChatID(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Hi there", messages[0].Content)
+	assert.JSONEq(t, `{"eval_count": 2}`, string(messages[0].Metadata))
+}
+
+// TestSQLiteRepository_Documents verifies that documents and their chunk embeddings
+// survive a round-trip through the database.
+func TestSQLiteRepository_Documents(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Now().UTC()
+
+	// ARRANGE
+	collection := &model.Collection{ID: "col1", Name: "Docs", EmbeddingModel: "embed-model", CreatedAt: now}
+	require.NoError(t, repo.CreateCollection(ctx, collection))
+	doc := &model.Document{ID: "doc1", CollectionID: "col1", Name: "notes.txt", ChunkCount: 2, CreatedAt: now}
+	chunks := []model.DocumentChunk{
+		{ID: "c1", Index: 0, Content: "first", Embedding: []float32{0.5, -1.25, 3}},
+		{ID: "c2", Index: 1, Content: "second", Embedding: []float32{0, 1, 0}},
+	}
+
+	// ACT
+	require.NoError(t, repo.AddDocument(ctx, doc, chunks))
+
+	// ASSERT
+	docs, err := repo.GetDocuments(ctx, "col1")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "notes.txt", docs[0].Name)
+	assert.Equal(t, 2, docs[0].ChunkCount)
+
+	stored, err := repo.GetChunksByCollectionID(ctx, "col1")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "doc1", stored[0].DocumentID)
+	assert.Equal(t, []float32{0.5, -1.25, 3}, stored[0].Embedding)
+	assert.Equal(t, "second", stored[1].Content)
+
+	_, err = repo.GetCollection(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_UpdateChatCollection verifies binding a chat to a collection.
+func TestSQLiteRepository_UpdateChatCollection(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	chatID, _ := seedChat(t, repo)
+
+	require.NoError(t, repo.UpdateChatCollection(ctx, chatID, "col1"))
+	chat, err := repo.GetChat(ctx, chatID)
+	require.NoError(t, err)
+	assert.Equal(t, "col1", chat.CollectionID)
+
+	assert.ErrorIs(t, repo.UpdateChatCollection(ctx, "missing", "col1"), repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_PinnedChatsFirst verifies that pinned chats are listed
+// before all others, regardless of their update time and of the requested order.
+func TestSQLiteRepository_PinnedChatsFirst(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// "old" is the least recently updated chat, so it would normally come last.
+	for i, id := range []string{"old", "middle", "new"} {
+		ts := base.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, CreatedAt: ts, UpdatedAt: ts}))
+	}
+	require.NoError(t, repo.UpdateChatPinned(ctx, "old", true))
+
+	ids := func(chats []*model.Chat) []string {
+		var out []string
+		for _, c := range chats {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	chats, err := repo.GetChats(ctx, model.ChatListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old", "new", "middle"}, ids(chats))
+	assert.True(t, chats[0].Pinned)
+	assert.False(t, chats[1].Pinned)
+	// Pinning does not count as activity.
+	assert.True(t, chats[0].UpdatedAt.Equal(base))
+
+	chats, err = repo.GetChats(ctx, model.ChatListOptions{SortBy: "title", Order: "asc"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old", "middle", "new"}, ids(chats))
+
+	require.NoError(t, repo.UpdateChatPinned(ctx, "old", false))
+	chats, err = repo.GetChats(ctx, model.ChatListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new", "middle", "old"}, ids(chats))
+
+	assert.ErrorIs(t, repo.UpdateChatPinned(ctx, "missing", true), repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_PruneChats verifies that retention deletes unpinned chats,
+// oldest first and in batches, together with their messages and tags.
+func TestSQLiteRepository_PruneChats(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// setup creates chats "c0" (oldest) to "c4" (newest); "c0" is pinned and "c1"
+	// has a message and a tag.
+	setup := func(t *testing.T) (repository.Repository, *sql.DB) {
+		repo, db := setupRepository(t)
+		for i := 0; i < 5; i++ {
+			ts := base.AddDate(0, 0, i)
+			id := fmt.Sprintf("c%d", i)
+			require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, CreatedAt: ts, UpdatedAt: ts}))
+		}
+		require.NoError(t, repo.UpdateChatPinned(ctx, "c0", true))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "m1", Role: "user", Content: "Hi", Timestamp: base}, "c1"))
+		require.NoError(t, repo.SetChatTags(ctx, "c1", []string{"work"}))
+		// Adding the message counted as activity; move the chat back in time.
+		_, err := db.Exec("UPDATE chats SET updated_at = ? WHERE id = 'c1'", base.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		return repo, db
+	}
+	remaining := func(t *testing.T, repo repository.Repository) []string {
+		chats, err := repo.GetChats(ctx, model.ChatListOptions{SortBy: "title", Order: "asc"})
+		require.NoError(t, err)
+		var ids []string
+		for _, c := range chats {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	t.Run("By age, in batches", func(t *testing.T) {
+		repo, db := setup(t)
+		opts := model.ChatPruneOptions{UpdatedBefore: base.AddDate(0, 0, 3), Limit: 1}
+
+		n, err := repo.PruneChats(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"c0", "c2", "c3", "c4"}, remaining(t, repo), "the oldest unpinned chat goes first")
+
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM messages WHERE chat_id = 'c1'").Scan(&count))
+		assert.Zero(t, count)
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM chat_tags WHERE chat_id = 'c1'").Scan(&count))
+		assert.Zero(t, count)
+
+		n, err = repo.PruneChats(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		n, err = repo.PruneChats(ctx, opts)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Equal(t, []string{"c0", "c3", "c4"}, remaining(t, repo))
+	})
+
+	t.Run("By count", func(t *testing.T) {
+		repo, _ := setup(t)
+
+		n, err := repo.PruneChats(ctx, model.ChatPruneOptions{KeepNewest: 2, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		// The pinned chat neither is deleted nor counts towards the limit.
+		assert.Equal(t, []string{"c0", "c3", "c4"}, remaining(t, repo))
+	})
+
+	t.Run("No criteria", func(t *testing.T) {
+		repo, _ := setup(t)
+
+		n, err := repo.PruneChats(ctx, model.ChatPruneOptions{Limit: 10})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Len(t, remaining(t, repo), 5)
+	})
+}
+
+// TestSQLiteRepository_DeleteChats verifies that a bulk delete counts only the
+// chats that existed and that missing IDs don't abort the batch.
+func TestSQLiteRepository_DeleteChats(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+	now := time.Now().UTC()
+	for _, id := range []string{"keep", "a", "b"} {
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, CreatedAt: now, UpdatedAt: now}))
+	}
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "m1", Role: "user", Content: "Hi", Timestamp: now}, "a"))
+	require.NoError(t, repo.UpdateChatPinned(ctx, "b", true))
+
+	deleted, err := repo.DeleteChats(ctx, []string{"a", "missing", "b"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	chats, err := repo.GetChats(ctx, model.ChatListOptions{})
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, "keep", chats[0].ID)
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM messages WHERE chat_id = 'a'").Scan(&count))
+	assert.Zero(t, count)
+
+	deleted, err = repo.DeleteChats(ctx, []string{"missing"})
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+// TestSQLiteRepository_DeleteChat verifies that deleting a single chat also
+// deletes its messages, attachments and tags, like a bulk delete.
+func TestSQLiteRepository_DeleteChat(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+	chatID, _ := seedChat(t, repo)
+	attachment := model.Attachment{ID: "att1", MimeType: "image/png", Data: []byte{1}}
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "msg2", Role: "user", Content: "Look", Timestamp: time.Now().UTC(), Attachments: []model.Attachment{attachment}}, chatID))
+	require.NoError(t, repo.SetChatTags(ctx, chatID, []string{"work"}))
+
+	require.NoError(t, repo.DeleteChat(ctx, chatID))
+
+	for _, table := range []string{"messages", "message_attachments", "chat_tags"} {
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+		assert.Zero(t, count, table)
+	}
+	assert.ErrorIs(t, repo.DeleteChat(ctx, chatID), repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_ChatTags verifies replacing tags, filtering listings by tag
+// and that listings carry each chat's tags.
+func TestSQLiteRepository_ChatTags(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Now().UTC()
+	for _, id := range []string{"chat1", "chat2"} {
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, CreatedAt: now, UpdatedAt: now}))
+	}
+
+	require.NoError(t, repo.SetChatTags(ctx, "chat1", []string{"old"}))
+	// Setting tags replaces the previous ones.
+	require.NoError(t, repo.SetChatTags(ctx, "chat1", []string{"golang", "work"}))
+	require.NoError(t, repo.SetChatTags(ctx, "chat2", []string{"work"}))
+
+	t.Run("Listing includes tags", func(t *testing.T) {
+		chats, err := repo.GetChats(ctx, model.ChatListOptions{SortBy: "title", Order: "asc"})
+		require.NoError(t, err)
+		require.Len(t, chats, 2)
+		assert.Equal(t, []string{"golang", "work"}, chats[0].Tags)
+		assert.Equal(t, []string{"work"}, chats[1].Tags)
+	})
+
+	t.Run("Listing filters by tag", func(t *testing.T) {
+		chats, err := repo.GetChats(ctx, model.ChatListOptions{Tag: "golang"})
+		require.NoError(t, err)
+		require.Len(t, chats, 1)
+		assert.Equal(t, "chat1", chats[0].ID)
+
+		chats, err = repo.GetChats(ctx, model.ChatListOptions{Tag: "old"})
+		require.NoError(t, err)
+		assert.Empty(t, chats)
+	})
+
+	t.Run("Empty list removes all tags", func(t *testing.T) {
+		require.NoError(t, repo.SetChatTags(ctx, "chat2", nil))
+		chats, err := repo.GetChats(ctx, model.ChatListOptions{Tag: "work"})
+		require.NoError(t, err)
+		require.Len(t, chats, 1)
+		assert.Equal(t, "chat1", chats[0].ID)
+	})
+
+	t.Run("Unknown chat", func(t *testing.T) {
+		assert.ErrorIs(t, repo.SetChatTags(ctx, "missing", []string{"work"}), repository.ErrNotFound)
+	})
+}
+
+// TestSQLiteRepository_GetActiveMessagesPage verifies the pagination window: pages
+// are newest first, respect the limit, exclude inactive messages and continue
+// strictly before the cursor message.
+func TestSQLiteRepository_GetActiveMessagesPage(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: base, UpdatedAt: base}))
+	for i := range 5 {
+		msg := &model.Message{ID: fmt.Sprintf("msg%d", i), Role: "user", Content: "text", Timestamp: base.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, repo.AddMessage(ctx, msg, "chat1"))
+	}
+	// An inactive branch must never show up in a page.
+	inactive := &model.Message{ID: "old-branch", Role: "assistant", Content: "text", Timestamp: base.Add(10 * time.Minute)}
+	require.NoError(t, repo.AddMessage(ctx, inactive, "chat1"))
+	_, err := db.Exec("UPDATE messages SET is_active = FALSE WHERE id = ?", inactive.ID)
+	require.NoError(t, err)
+
+	ids := func(messages []model.Message) []string {
+		var out []string
+		for _, m := range messages {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	t.Run("First page is the newest messages", func(t *testing.T) {
+		page, err := repo.GetActiveMessagesPage(ctx, "chat1", 2, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"msg4", "msg3"}, ids(page))
+	})
+
+	t.Run("Cursor excludes the boundary message", func(t *testing.T) {
+		page, err := repo.GetActiveMessagesPage(ctx, "chat1", 10, "msg3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"msg2", "msg1", "msg0"}, ids(page))
+	})
+
+	t.Run("Past the oldest message is empty", func(t *testing.T) {
+		page, err := repo.GetActiveMessagesPage(ctx, "chat1", 10, "msg0")
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+}
+
+// TestSQLiteRepository_GetAllMessagesByChatID verifies that inactive branches are
+// included and that the `since` filter is applied.
+func TestSQLiteRepository_GetAllMessagesByChatID(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: base, UpdatedAt: base}))
+	for i := range 3 {
+		msg := &model.Message{ID: fmt.Sprintf("msg%d", i), Role: "user", Content: "text", Timestamp: base.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, repo.AddMessage(ctx, msg, "chat1"))
+	}
+	_, err := db.Exec("UPDATE messages SET is_active = FALSE WHERE id = ?", "msg1")
+	require.NoError(t, err)
+
+	t.Run("Includes inactive messages, oldest first", func(t *testing.T) {
+		messages, err := repo.GetAllMessagesByChatID(ctx, "chat1", nil)
+		require.NoError(t, err)
+		require.Len(t, messages, 3)
+		assert.Equal(t, "msg0", messages[0].ID)
+		assert.Equal(t, "msg1", messages[1].ID)
+		assert.False(t, messages[1].IsActive)
+		assert.Equal(t, "msg2", messages[2].ID)
+	})
+
+	t.Run("Since includes the boundary message", func(t *testing.T) {
+		since := base.Add(time.Minute)
+		messages, err := repo.GetAllMessagesByChatID(ctx, "chat1", &since)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, "msg1", messages[0].ID)
+		assert.Equal(t, "msg2", messages[1].ID)
+	})
+}
+
+// TestSQLiteRepository_MessageOrderWithSameTimestamp verifies that messages with
+// the same timestamp are listed in the order they were added.
+//
+// WHY: A user message and a quick answer can be stored within the same
+// timestamp; ordering by timestamp alone would shuffle them.
+func TestSQLiteRepository_MessageOrderWithSameTimestamp(t *testing.T) {
+	// ARRANGE: IDs that sort against the insertion order, so that neither the
+	// primary key nor the timestamp can produce the expected order by accident.
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: now, UpdatedAt: now}))
+	inserted := []string{"msg-c", "msg-b", "msg-a", "msg-e", "msg-d"}
+	for _, id := range inserted {
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: id, Role: "user", Content: "text", Timestamp: now}, "chat1"))
+	}
+	ids := func(messages []model.Message) []string {
+		var out []string
+		for _, m := range messages {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	// ACT & ASSERT: Every listing agrees on the insertion order.
+	active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	assert.Equal(t, inserted, ids(active))
+
+	all, err := repo.GetAllMessagesByChatID(ctx, "chat1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, inserted, ids(all))
+
+	page, err := repo.GetActiveMessagesPage(ctx, "chat1", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"msg-d", "msg-e"}, ids(page))
+
+	last, err := repo.GetLastActiveMessage(ctx, "chat1")
+	require.NoError(t, err)
+	assert.Equal(t, "msg-d", last.ID)
+}
+
+// TestSQLiteRepository_IdempotencyRecords verifies storing, expiry and pruning of idempotency keys.
+func TestSQLiteRepository_IdempotencyRecords(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Now().UTC()
+
+	require.NoError(t, repo.SaveIdempotencyRecord(ctx, &model.IdempotencyRecord{Key: "fresh", ChatID: "c1", MessageID: "m1", CreatedAt: now}))
+	require.NoError(t, repo.SaveIdempotencyRecord(ctx, &model.IdempotencyRecord{Key: "old", ChatID: "c1", MessageID: "m0", CreatedAt: now.Add(-48 * time.Hour)}))
+	cutoff := now.Add(-24 * time.Hour)
+
+	rec, err := repo.GetIdempotencyRecord(ctx, "fresh", cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, "m1", rec.MessageID)
+
+	// An expired key is not returned, even before it is pruned.
+	_, err = repo.GetIdempotencyRecord(ctx, "old", cutoff)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, repo.DeleteIdempotencyRecordsBefore(ctx, cutoff))
+	_, err = repo.GetIdempotencyRecord(ctx, "old", time.Time{})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.GetIdempotencyRecord(ctx, "fresh", time.Time{})
+	assert.NoError(t, err)
+}
+
+// TestSQLiteRepository_Prompts verifies the prompt template CRUD queries.
+func TestSQLiteRepository_Prompts(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreatePrompt(ctx, &model.Prompt{ID: "p2", Name: "Translate", Body: "To {{lang}}", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, repo.CreatePrompt(ctx, &model.Prompt{ID: "p1", Name: "Review", Description: "Code review", Body: "Review", CreatedAt: now, UpdatedAt: now}))
+
+	prompts, err := repo.GetPrompts(ctx)
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Equal(t, "Review", prompts[0].Name, "prompts are ordered by name")
+
+	later := now.Add(time.Hour)
+	require.NoError(t, repo.UpdatePrompt(ctx, &model.Prompt{ID: "p2", Name: "Translate", Body: "Into {{lang}}", UpdatedAt: later}))
+	prompt, err := repo.GetPrompt(ctx, "p2")
+	require.NoError(t, err)
+	assert.Equal(t, "Into {{lang}}", prompt.Body)
+	assert.True(t, prompt.CreatedAt.Equal(now))
+	assert.True(t, prompt.UpdatedAt.Equal(later))
+
+	require.NoError(t, repo.DeletePrompt(ctx, "p2"))
+	_, err = repo.GetPrompt(ctx, "p2")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorIs(t, repo.DeletePrompt(ctx, "p2"), repository.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdatePrompt(ctx, &model.Prompt{ID: "p2"}), repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_ModelDefaults verifies that model defaults are stored,
+// replaced on a second save, and reported missing for other models.
+func TestSQLiteRepository_ModelDefaults(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.SaveModelDefaults(ctx, &model.ModelDefaults{Model: "qwen3:14b", Options: []byte(`{"temperature":0.2}`), UpdatedAt: now}))
+	later := now.Add(time.Hour)
+	require.NoError(t, repo.SaveModelDefaults(ctx, &model.ModelDefaults{Model: "qwen3:14b", Options: []byte(`{"num_ctx":8192}`), UpdatedAt: later}))
+
+	defaults, err := repo.GetModelDefaults(ctx, "qwen3:14b")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"num_ctx":8192}`, string(defaults.Options))
+	assert.True(t, defaults.UpdatedAt.Equal(later))
+
+	_, err = repo.GetModelDefaults(ctx, "llama3:8b")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+// TestSQLiteRepository_ModelBenchmarks verifies that only the last benchmark of
+// each model is kept.
+func TestSQLiteRepository_ModelBenchmarks(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := setupRepository(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.SaveModelBenchmark(ctx, &model.ModelBenchmark{Model: "qwen3:8b", Runs: 3, TokensPerSecond: 20, CreatedAt: now}))
+	require.NoError(t, repo.SaveModelBenchmark(ctx, &model.ModelBenchmark{Model: "gemma3:4b", Runs: 3, TokensPerSecond: 40, LoadMs: 12.5, FirstTokenMs: 80, CreatedAt: now}))
+	require.NoError(t, repo.SaveModelBenchmark(ctx, &model.ModelBenchmark{Model: "qwen3:8b", Runs: 5, TokensPerSecond: 22, CreatedAt: now.Add(time.Hour)}))
+
+	benchmarks, err := repo.GetModelBenchmarks(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 2)
+	assert.Equal(t, "gemma3:4b", benchmarks[0].Model)
+	assert.Equal(t, 12.5, benchmarks[0].LoadMs)
+	assert.Equal(t, 5, benchmarks[1].Runs)
+	assert.Equal(t, 22.0, benchmarks[1].TokensPerSecond)
+	assert.True(t, benchmarks[1].CreatedAt.Equal(now.Add(time.Hour)))
+}
+
+// TestSQLiteRepository_GetGlobalStats verifies the counts of chats and messages,
+// including inactive branches and messages per model.
+func TestSQLiteRepository_GetGlobalStats(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+
+	t.Run("Empty database", func(t *testing.T) {
+		stats, err := repo.GetGlobalStats(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.GlobalStats{MessagesByModel: []model.ModelMessageCount{}}, stats)
+	})
+
+	t.Run("Seeded database", func(t *testing.T) {
+		// ARRANGE: Two chats; one assistant answer was regenerated with another
+		// model, which left the first answer on an inactive branch.
+		now := time.Now().UTC()
+		qwen, gemma := "qwen3:8b", "gemma3:4b"
+		for _, id := range []string{"c1", "c2"} {
+			require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: id, Title: id, CreatedAt: now, UpdatedAt: now}))
+		}
+		messages := []struct {
+			chatID string
+			msg    model.Message
+		}{
+			{"c1", model.Message{ID: "m1", Role: "user", Content: "Hi"}},
+			{"c1", model.Message{ID: "m2", Role: "assistant", Content: "Hello", Model: &qwen}},
+			{"c1", model.Message{ID: "m3", Role: "assistant", Content: "Hey", Model: &gemma}},
+			{"c2", model.Message{ID: "m4", Role: "user", Content: "Hi"}},
+			{"c2", model.Message{ID: "m5", Role: "assistant", Content: "Hello", Model: &qwen}},
+		}
+		for _, m := range messages {
+			m.msg.Timestamp = now
+			require.NoError(t, repo.AddMessage(ctx, &m.msg, m.chatID))
+		}
+		_, err := db.Exec("UPDATE messages SET is_active = FALSE WHERE id = 'm2'")
+		require.NoError(t, err)
+
+		// ACT
+		stats, err := repo.GetGlobalStats(ctx)
+
+		// ASSERT: User messages have no model and are not counted per model.
+		require.NoError(t, err)
+		assert.Equal(t, &model.GlobalStats{
+			Chats:            2,
+			Messages:         5,
+			ActiveMessages:   4,
+			InactiveMessages: 1,
+			MessagesByModel: []model.ModelMessageCount{
+				{Model: "qwen3:8b", Messages: 2},
+				{Model: "gemma3:4b", Messages: 1},
+			},
+		}, stats)
+	})
+}
+
+// TestSQLiteRepository_Checkpoint verifies that the WAL file is checkpointed and
+// truncated, and that the database can be optimized.
+func TestSQLiteRepository_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	repo, db := setupRepository(t)
+
+	// ARRANGE: The database runs in WAL mode and has pending writes in the WAL file.
+	var journalMode string
+	require.NoError(t, db.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	require.Equal(t, "wal", journalMode)
+	seedChat(t, repo)
+
+	// ACT
+	result, err := repo.Checkpoint(ctx)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.False(t, result.Busy)
+	assert.Equal(t, result.LogFrames, result.CheckpointedFrames)
+	assert.NoError(t, repo.Optimize(ctx))
+
+	// The data is still there after the WAL file was truncated.
+	chat, err := repo.GetChat(ctx, "chat1")
+	require.NoError(t, err)
+	assert.Equal(t, "Test", chat.Title)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+const (
+	backupPrefix = "flow-"
+	backupSuffix = ".db"
+	// backupTimeLayout names the snapshots so that they sort chronologically.
+	backupTimeLayout = "20060102-150405.000"
+)
+
+// Backup describes a database snapshot on disk.
+type Backup struct {
+	Name      string    `json:"name" example:"flow-20250908-140000.000.db"`
+	Path      string    `json:"path" example:"/data/backups/flow-20250908-140000.000.db"`
+	SizeBytes int64     `json:"size_bytes" example:"1048576"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupServiceConfig holds the static configuration of the BackupService.
+type BackupServiceConfig struct {
+	// Dir is the directory the snapshots are written to; empty disables backups.
+	Dir string
+	// Keep is the number of snapshots kept; older ones are deleted after each
+	// backup. 0 keeps all of them.
+	Keep int
+}
+
+// BackupService writes consistent snapshots of the SQLite database while the
+// server keeps running.
+type BackupService struct {
+	db  *sql.DB
+	cfg BackupServiceConfig
+	// mu is held for the duration of a backup; a second one is rejected rather
+	// than queued, as it would only copy the same data again.
+	mu sync.Mutex
+}
+
+// NewBackupService creates a new instance of BackupService.
+func NewBackupService(db *sql.DB, cfg BackupServiceConfig) *BackupService {
+	return &BackupService{db: db, cfg: cfg}
+}
+
+// Backup writes a snapshot of the database with `VACUUM INTO` and prunes the
+// snapshots beyond the configured count.
+func (s *BackupService) Backup(ctx context.Context) (*Backup, error) {
+	if s.cfg.Dir == "" {
+		return nil, fmt.Errorf("%w: backups are disabled; set BACKUP_DIR", app_errors.ErrNotSupported)
+	}
+	if !s.mu.TryLock() {
+		return nil, fmt.Errorf("%w: a backup is already running", app_errors.ErrConflict)
+	}
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("could not create the backup directory: %w", err)
+	}
+	createdAt := time.Now().UTC()
+	name := backupPrefix + createdAt.Format(backupTimeLayout) + backupSuffix
+	path := filepath.Join(s.cfg.Dir, name)
+	// The snapshot is written under a temporary name, so that a failed backup is
+	// never listed as a usable one.
+	partial := path + ".partial"
+	startedAt := time.Now()
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", partial); err != nil {
+		_ = os.Remove(partial)
+		return nil, fmt.Errorf("could not write the backup: %w", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		_ = os.Remove(partial)
+		return nil, fmt.Errorf("could not write the backup: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the backup: %w", err)
+	}
+	slog.Info("Database backup written", "path", path, "size_bytes", info.Size(), "duration", time.Since(startedAt))
+
+	if err := s.prune(ctx); err != nil {
+		// The new snapshot is fine; the old ones are retried with the next backup.
+		slog.Warn("Could not delete old backups", "error", err)
+	}
+	return &Backup{Name: name, Path: path, SizeBytes: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// ListBackups returns the snapshots in the backup directory, newest first.
+func (s *BackupService) ListBackups(ctx context.Context) ([]Backup, error) {
+	if s.cfg.Dir == "" {
+		return []Backup{}, nil
+	}
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list the backups: %w", err)
+	}
+
+	backups := []Backup{}
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), backupPrefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, backupSuffix)
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// The file was deleted since the directory was read.
+			continue
+		}
+		backups = append(backups, Backup{
+			Name:      entry.Name(),
+			Path:      filepath.Join(s.cfg.Dir, entry.Name()),
+			SizeBytes: info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	slices.SortFunc(backups, func(a, b Backup) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return backups, nil
+}
+
+// prune deletes the oldest snapshots beyond the configured count.
+func (s *BackupService) prune(ctx context.Context) error {
+	if s.cfg.Keep <= 0 {
+		return nil
+	}
+	backups, err := s.ListBackups(ctx)
+	if err != nil {
+		return err
+	}
+	if len(backups) <= s.cfg.Keep {
+		return nil
+	}
+	var errs []error
+	for _, backup := range backups[s.cfg.Keep:] {
+		if err := os.Remove(backup.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("Old database backup deleted", "path", backup.Path)
+	}
+	return errors.Join(errs...)
+}
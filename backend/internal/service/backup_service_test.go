@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/database"
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+	"flow-ai/backend/internal/service"
+)
+
+// setupBackupService creates a `BackupService` on a SQLite database with one chat.
+func setupBackupService(t *testing.T, cfg service.BackupServiceConfig) *service.BackupService {
+	db, err := database.InitDB(filepath.Join(t.TempDir(), "flow.db"), database.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	now := time.Now().UTC()
+	require.NoError(t, repository.NewSQLiteRepository(db).CreateChat(context.Background(), &model.Chat{ID: "chat1", Title: "Trip", CreatedAt: now, UpdatedAt: now}))
+	return service.NewBackupService(db, cfg)
+}
+
+// TestBackupService_Backup verifies that a backup is a usable copy of the
+// database, and that only the newest snapshots are kept.
+func TestBackupService_Backup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Writes a snapshot that can be opened", func(t *testing.T) {
+		// ARRANGE
+		dir := filepath.Join(t.TempDir(), "backups")
+		backupService := setupBackupService(t, service.BackupServiceConfig{Dir: dir})
+
+		// ACT
+		backup, err := backupService.Backup(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, backup.Name), backup.Path)
+		info, err := os.Stat(backup.Path)
+		require.NoError(t, err)
+		assert.Equal(t, info.Size(), backup.SizeBytes)
+		snapshot, err := database.InitDB(backup.Path, database.Config{})
+		require.NoError(t, err)
+		defer snapshot.Close()
+		chat, err := repository.NewSQLiteRepository(snapshot).GetChat(ctx, "chat1")
+		require.NoError(t, err)
+		assert.Equal(t, "Trip", chat.Title)
+	})
+
+	t.Run("Prunes the oldest snapshots", func(t *testing.T) {
+		// ARRANGE
+		dir := t.TempDir()
+		backupService := setupBackupService(t, service.BackupServiceConfig{Dir: dir, Keep: 2})
+		var names []string
+		for range 3 {
+			backup, err := backupService.Backup(ctx)
+			require.NoError(t, err)
+			names = append(names, backup.Name)
+			// WHY: The snapshots are named after the millisecond they were taken.
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		// ACT
+		backups, err := backupService.ListBackups(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		assert.Equal(t, names[2], backups[0].Name)
+		assert.Equal(t, names[1], backups[1].Name)
+		_, err = os.Stat(filepath.Join(dir, names[0]))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Rejects a concurrent backup", func(t *testing.T) {
+		// ARRANGE
+		dir := t.TempDir()
+		backupService := setupBackupService(t, service.BackupServiceConfig{Dir: dir})
+		unlock := backupService.LockBackups()
+		defer unlock()
+
+		// ACT
+		_, err := backupService.Backup(ctx)
+
+		// ASSERT
+		assert.ErrorIs(t, err, app_errors.ErrConflict)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Fails when backups are disabled", func(t *testing.T) {
+		backupService := setupBackupService(t, service.BackupServiceConfig{})
+
+		_, err := backupService.Backup(ctx)
+
+		assert.ErrorIs(t, err, app_errors.ErrNotSupported)
+	})
+}
+
+// TestBackupService_ListBackups verifies that only snapshots are listed, and that
+// a missing directory is an empty list.
+func TestBackupService_ListBackups(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No backup directory yet", func(t *testing.T) {
+		backupService := setupBackupService(t, service.BackupServiceConfig{Dir: filepath.Join(t.TempDir(), "missing")})
+
+		backups, err := backupService.ListBackups(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, backups)
+	})
+
+	t.Run("Ignores other files", func(t *testing.T) {
+		// ARRANGE: A failed backup leaves no partial file behind, but other tools might.
+		dir := t.TempDir()
+		for _, name := range []string{"notes.txt", "flow-latest.db", "flow-20250908-140000.000.db.partial"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "flow-20250908-140000.000.db"), []byte("snapshot"), 0o600))
+		backupService := setupBackupService(t, service.BackupServiceConfig{Dir: dir})
+
+		// ACT
+		backups, err := backupService.ListBackups(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Equal(t, "flow-20250908-140000.000.db", backups[0].Name)
+		assert.EqualValues(t, 8, backups[0].SizeBytes)
+		assert.Equal(t, time.Date(2025, 9, 8, 14, 0, 0, 0, time.UTC), backups[0].CreatedAt)
+	})
+}
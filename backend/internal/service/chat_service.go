@@ -3,13 +3,19 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/llm"
@@ -17,6 +23,7 @@ import (
 	"flow-ai/backend/internal/repository"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/semaphore"
 )
 
 // ChatService encapsulates the core business logic for chat operations.
@@ -25,16 +32,159 @@ type ChatService struct {
 	repo            repository.Repository
 	llm             llm.LLMProvider
 	settingsService *SettingsService
+	// documents provides retrieval for chats bound to a collection. It may be nil,
+	// in which case retrieval is disabled.
+	documents *DocumentService
+	cfg       ChatServiceConfig
+
+	// inFlightKeys holds the idempotency keys of requests that are still being
+	// processed, so that a resend arriving mid-stream is not generated twice.
+	inFlightMu   sync.Mutex
+	inFlightKeys map[string]struct{}
+
+	// responses caches the answers to deterministic requests; nil disables caching.
+	responses *responseCache
+
+	// generations limits the number of concurrent generations; nil is no limit.
+	generations *semaphore.Weighted
+
+	// titleJobs tracks the background title generations, which run with
+	// titleCtx until Close cancels it.
+	titleJobs   sync.WaitGroup
+	titleCtx    context.Context
+	cancelTitle context.CancelFunc
 }
 
+// ChatServiceConfig holds the static, deployment-level options of the ChatService.
+// Zero values are replaced with sensible defaults by `NewChatService`.
+type ChatServiceConfig struct {
+	// DefaultUserID is assigned as the owner of every new chat.
+	DefaultUserID string
+	// TitlePreviewLength is the maximum number of runes of the first message used
+	// as the temporary title of a new chat.
+	TitlePreviewLength int
+	// TitleMaxLength caps the length (in runes) of generated titles.
+	TitleMaxLength int
+	// TitleRetryBackoff is the delay before the first retry of a failed title
+	// generation; it doubles with every further attempt.
+	TitleRetryBackoff time.Duration
+	// TitleTimeout bounds each attempt to generate a title, so that a hung
+	// support model does not keep the job alive forever.
+	TitleTimeout time.Duration
+	// MaxImageBytes is the maximum decoded size of a single image attached to a message.
+	MaxImageBytes int64
+	// IdempotencyTTL is how long the result of a request with an idempotency key is
+	// returned for repeats of that request.
+	IdempotencyTTL time.Duration
+	// ContentFilter checks user content before generation; nil allows everything.
+	ContentFilter ContentFilter
+	// FilterResponses also checks the model's complete answer. The final chunk of
+	// the stream is then held back until the check passes, and a blocked answer is
+	// replaced by a stream error and not saved.
+	FilterResponses bool
+	// ResponseCacheSize is the number of answers to deterministic requests (a
+	// fixed seed and temperature 0) that are kept in memory and replayed for
+	// identical requests; 0 disables the cache.
+	ResponseCacheSize int
+	// MaxConcurrentGenerations is the number of answers that are generated at
+	// once; 0 is no limit. A single GPU slows to a crawl beyond a few.
+	MaxConcurrentGenerations int
+	// GenerationQueueTimeout is how long a generation waits for a free slot
+	// once MaxConcurrentGenerations are running, with a "queued" event sent to
+	// the client, before it fails; 0 rejects it at once.
+	GenerationQueueTimeout time.Duration
+}
+
+const (
+	defaultUserID             = "default-user"
+	defaultTitlePreviewLength = 50
+	defaultTitleMaxLength     = 60
+	defaultTitleRetryBackoff  = 2 * time.Second
+	defaultTitleTimeout       = 30 * time.Second
+	defaultMaxImageBytes      = 10 << 20 // 10 MiB
+	defaultIdempotencyTTL     = 24 * time.Hour
+	// maxChatTitleLength matches the limit of manually set titles.
+	maxChatTitleLength = 100
+	// titleGenerationAttempts bounds how often title generation is tried before
+	// the chat keeps its placeholder title.
+	titleGenerationAttempts = 3
+	// defaultMessagePageSize is the number of messages returned by `GetFullChat` and
+	// by `GetChatMessages` when no limit is given; maxMessagePageSize caps the limit.
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 200
+	// maxTagLength and maxTagsPerChat bound the tags of a chat.
+	maxTagLength   = 32
+	maxTagsPerChat = 20
+)
+
+// tagPattern matches valid chat tags; see validateTag.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
 // CreateMessageRequest is the DTO for creating a new message. Includes validation tags.
 type CreateMessageRequest struct {
 	ChatID       string              `json:"chat_id,omitempty" example:"4b3b5a34-571f-47e3-abd1-a7dbee9d92fe"`
-	Content      string              `json:"content" validate:"required,min=1" example:"What is the difference between SQL and NoSQL databases?"`
+	Content      string              `json:"content" validate:"required_without=PromptID" example:"What is the difference between SQL and NoSQL databases?"`
 	Model        string              `json:"model,omitempty" example:"qwen3:8b"`
 	SystemPrompt string              `json:"system_prompt,omitempty"`
 	SupportModel string              `json:"support_model,omitempty"`
 	Options      *llm.RequestOptions `json:"options,omitempty"`
+	// Images are base64-encoded images for vision models (e.g. llava).
+	Images []string `json:"images,omitempty"`
+	// PromptID names a prompt template that is rendered with `Variables` to form
+	// the message. Any `Content` is appended to the rendered prompt.
+	PromptID  string            `json:"prompt_id,omitempty" example:"7c6b5a4f-3e2d-1c0b-9a8f-7e6d5c4b3a29"`
+	Variables map[string]string `json:"variables,omitempty"`
+	// ShowReasoning overrides the `show_reasoning` setting for this request.
+	ShowReasoning *bool `json:"show_reasoning,omitempty"`
+	// ResponseFormat is "json" to make the model answer with valid JSON. It takes
+	// precedence over `options.format`.
+	ResponseFormat string `json:"response_format,omitempty" validate:"omitempty,oneof=json" example:"json"`
+	// IdempotencyKey is taken from the `Idempotency-Key` header. A repeated request
+	// with the same key returns the original answer instead of generating a new one.
+	IdempotencyKey string `json:"-" validate:"max=255"`
+	// DryRun streams the answer without persisting anything: neither the chat of
+	// a new conversation, nor the messages, nor a generated title. It is meant
+	// for previewing a prompt. The idempotency key is ignored.
+	DryRun bool `json:"dry_run,omitempty" example:"false"`
+}
+
+// CreateChatRequest is the DTO for creating an empty chat before the first message.
+// All fields are optional; omitted values fall back to the global settings.
+type CreateChatRequest struct {
+	Title        string `json:"title,omitempty" validate:"max=100" example:"Code review session"`
+	Model        string `json:"model,omitempty" example:"qwen3:8b"`
+	SystemPrompt string `json:"system_prompt,omitempty" example:"You are a meticulous code reviewer."`
+	// CollectionID enables retrieval from a document collection for this chat.
+	CollectionID string `json:"collection_id,omitempty" example:"0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"`
+}
+
+// DeleteChatsRequest is the DTO for deleting several chats at once.
+type DeleteChatsRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=1000,dive,required" example:"3f2b8c1e-5d4a-4b6f-9e7d-1a2b3c4d5e6f"`
+}
+
+// UpdateChatPinnedRequest is the DTO for pinning or unpinning a chat.
+type UpdateChatPinnedRequest struct {
+	Pinned bool `json:"pinned" example:"true"`
+}
+
+// UpdateChatTagsRequest is the DTO for replacing the tags of a chat.
+type UpdateChatTagsRequest struct {
+	Tags []string `json:"tags" example:"work,golang"`
+}
+
+// UpdateChatCollectionRequest is the DTO for binding a chat to a document collection.
+// An empty ID disables retrieval for the chat.
+type UpdateChatCollectionRequest struct {
+	CollectionID string `json:"collection_id" example:"0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"`
+}
+
+// AddMessageRequest is the DTO for inserting a message verbatim, without generating a
+// response (e.g. to import transcripts or seed few-shot examples).
+type AddMessageRequest struct {
+	Role    string `json:"role" validate:"required,oneof=user assistant system" example:"assistant"`
+	Content string `json:"content" validate:"required" example:"Paris is the capital of France."`
+	Model   string `json:"model,omitempty" example:"qwen3:8b"`
 }
 
 // RegenerateMessageRequest is the DTO for regenerating a message.
@@ -44,11 +194,101 @@ type RegenerateMessageRequest struct {
 	SystemPrompt string `json:"system_prompt,omitempty"`
 	// Allows overriding generation parameters, e.g., for a more creative response.
 	Options *llm.RequestOptions `json:"options,omitempty"`
+	// ShowReasoning overrides the `show_reasoning` setting for this request.
+	ShowReasoning *bool `json:"show_reasoning,omitempty"`
+}
+
+// CompareMessageRequest names the models that answer a prompt side by side.
+type CompareMessageRequest struct {
+	Models []string `json:"models" validate:"required,min=2,max=4,unique,dive,required" example:"qwen3:8b,gemma3:4b"`
+}
+
+// ContinueMessageRequest holds the options for continuing an assistant message
+// that stopped early, e.g. because it reached `num_predict`.
+type ContinueMessageRequest struct {
+	ChatID string `json:"chat_id,omitempty"` // Included for client-side context.
+	// Allows overriding generation parameters, e.g. a larger `num_predict`.
+	Options *llm.RequestOptions `json:"options,omitempty"`
+	// ShowReasoning overrides the `show_reasoning` setting for this request.
+	ShowReasoning *bool `json:"show_reasoning,omitempty"`
+}
+
+// TokenEstimate is the result of an approximate prompt-size calculation.
+type TokenEstimate struct {
+	// EstimatedTokens is a tokenizer-agnostic approximation; see llm.EstimateTokens.
+	EstimatedTokens int `json:"estimated_tokens" example:"512"`
+	// MessageCount is the number of messages (including the system prompt) that were counted.
+	MessageCount int `json:"message_count" example:"5"`
+	// LastPromptEvalCount is the real prompt token count Ollama reported for the
+	// latest assistant reply in the chat, if known. It can be used to calibrate the estimate.
+	LastPromptEvalCount int `json:"last_prompt_eval_count,omitempty" example:"480"`
 }
 
+// defaultChatTitle is the placeholder title for chats created without one.
+// It is replaced by a generated title after the first exchange.
+const defaultChatTitle = "New Chat"
+
 // NewChatService creates a new instance of ChatService.
-func NewChatService(repo repository.Repository, llm llm.LLMProvider, settingsService *SettingsService) *ChatService {
-	return &ChatService{repo: repo, llm: llm, settingsService: settingsService}
+func NewChatService(repo repository.Repository, llm llm.LLMProvider, settingsService *SettingsService, documents *DocumentService, cfg ChatServiceConfig) *ChatService {
+	if cfg.DefaultUserID == "" {
+		cfg.DefaultUserID = defaultUserID
+	}
+	if cfg.TitlePreviewLength <= 0 {
+		cfg.TitlePreviewLength = defaultTitlePreviewLength
+	}
+	if cfg.TitleMaxLength <= 0 {
+		cfg.TitleMaxLength = defaultTitleMaxLength
+	}
+	if cfg.TitleRetryBackoff <= 0 {
+		cfg.TitleRetryBackoff = defaultTitleRetryBackoff
+	}
+	if cfg.TitleTimeout <= 0 {
+		cfg.TitleTimeout = defaultTitleTimeout
+	}
+	if cfg.MaxImageBytes <= 0 {
+		cfg.MaxImageBytes = defaultMaxImageBytes
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = defaultIdempotencyTTL
+	}
+	if cfg.ContentFilter == nil {
+		cfg.ContentFilter = NoopContentFilter{}
+	}
+	s := &ChatService{
+		repo:            repo,
+		llm:             llm,
+		settingsService: settingsService,
+		documents:       documents,
+		cfg:             cfg,
+		inFlightKeys:    make(map[string]struct{}),
+	}
+	if cfg.ResponseCacheSize > 0 {
+		s.responses = newResponseCache(cfg.ResponseCacheSize)
+	}
+	if cfg.MaxConcurrentGenerations > 0 {
+		s.generations = semaphore.NewWeighted(int64(cfg.MaxConcurrentGenerations))
+	}
+	s.titleCtx, s.cancelTitle = context.WithCancel(context.Background())
+	return s
+}
+
+// Close waits for the background title generations until ctx is done and
+// then cancels the ones still running. It is called on shutdown, before the
+// database is closed.
+func (s *ChatService) Close(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.titleJobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Info("Cancelling title generations still in progress")
+		s.cancelTitle()
+		<-done
+	}
+	s.cancelTitle()
 }
 
 func (s *ChatService) UpdateChatTitle(ctx context.Context, chatID, newTitle string) error {
@@ -61,6 +301,80 @@ func (s *ChatService) UpdateChatTitle(ctx context.Context, chatID, newTitle stri
 	return err
 }
 
+// RegenerateTitleResponse is the result of regenerating the title of a chat.
+type RegenerateTitleResponse struct {
+	Title string `json:"title" example:"History of the Roman Empire"`
+	// Regenerated is false if the support model could not produce a title, in
+	// which case Title is the chat's existing title.
+	Regenerated bool `json:"regenerated" example:"true"`
+}
+
+// RegenerateTitle generates a new title for a chat from its first exchange using
+// the support model, e.g. after the conversation was edited. If the support model
+// is not configured, not available or does not answer within TitleTimeout, the
+// existing title is kept and returned.
+func (s *ChatService) RegenerateTitle(ctx context.Context, chatID string) (*RegenerateTitleResponse, error) {
+	chat, err := s.repo.GetChat(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return nil, fmt.Errorf("could not get chat: %w", err)
+	}
+	messages, err := s.repo.GetActiveMessagesByChatID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get messages: %w", err)
+	}
+	userQuery, assistantResponse, ok := firstExchange(messages)
+	if !ok {
+		return nil, fmt.Errorf("%w: chat has no answered message to generate a title from", app_errors.ErrValidation)
+	}
+
+	currentSettings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load settings: %w", err)
+	}
+	kept := &RegenerateTitleResponse{Title: chat.Title}
+	supportModel := currentSettings.ResolveModel(currentSettings.SupportModel)
+	if supportModel == "" {
+		slog.Warn("No support model configured, keeping the chat title", "chat_id", chatID)
+		return kept, nil
+	}
+	if err := s.ensureModelAvailable(ctx, supportModel); err != nil {
+		slog.Warn("Support model is not available, keeping the chat title", "chat_id", chatID, "model", supportModel)
+		return kept, nil
+	}
+	titleCtx, cancel := context.WithTimeout(ctx, s.cfg.TitleTimeout)
+	defer cancel()
+	title, err := s.suggestTitle(titleCtx, chatID, supportModel, currentSettings.TitlePromptTemplate, userQuery, assistantResponse)
+	if err != nil {
+		slog.Warn("Could not regenerate title, keeping the chat title", "chat_id", chatID, "error", err)
+		return kept, nil
+	}
+	if err := s.repo.UpdateChatTitle(ctx, chatID, title); err != nil {
+		return nil, fmt.Errorf("could not update chat title: %w", err)
+	}
+	slog.Info("Regenerated title", "chat_id", chatID, "title", title)
+	return &RegenerateTitleResponse{Title: title, Regenerated: true}, nil
+}
+
+// firstExchange returns the first user message of the active branch and the
+// assistant answer that follows it.
+func firstExchange(messages []model.Message) (userQuery, assistantResponse string, ok bool) {
+	for i, m := range messages {
+		if m.Role != "user" {
+			continue
+		}
+		for _, answer := range messages[i+1:] {
+			if answer.Role == "assistant" {
+				return m.Content, answer.Content, true
+			}
+		}
+		return "", "", false
+	}
+	return "", "", false
+}
+
 func (s *ChatService) DeleteChat(ctx context.Context, chatID string) error {
 	slog.Info("Deleting chat", "chat_id", chatID)
 	err := s.repo.DeleteChat(ctx, chatID)
@@ -70,11 +384,121 @@ func (s *ChatService) DeleteChat(ctx context.Context, chatID string) error {
 	return err
 }
 
-// ListChats retrieves all chat sessions.
+// DeleteChats deletes several chats at once and returns how many were deleted.
+// IDs of chats that don't exist are skipped rather than failing the batch.
+func (s *ChatService) DeleteChats(ctx context.Context, chatIDs []string) (int, error) {
+	deleted, err := s.repo.DeleteChats(ctx, chatIDs)
+	if err != nil {
+		return 0, fmt.Errorf("could not delete chats: %w", err)
+	}
+	slog.Info("Deleted chats", "requested", len(chatIDs), "deleted", deleted)
+	return deleted, nil
+}
+
+// GetGlobalStats returns the number of chats and messages over all chats.
+func (s *ChatService) GetGlobalStats(ctx context.Context) (*model.GlobalStats, error) {
+	stats, err := s.repo.GetGlobalStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not count chats and messages: %w", err)
+	}
+	return stats, nil
+}
+
+// CloneChat copies a chat and its active messages into a new, independent chat
+// titled "Copy of ...". Inactive branches are not copied. Messages and attachments
+// get new IDs; their order and parent links are preserved.
+func (s *ChatService) CloneChat(ctx context.Context, chatID string) (*model.Chat, error) {
+	original, err := s.repo.GetChat(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return nil, fmt.Errorf("could not get chat: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback clone transaction", "error", err)
+		}
+	}()
+
+	messages, err := s.repo.GetActiveMessagesByChatIDTx(ctx, tx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get messages: %w", err)
+	}
+	messageIDs := make([]string, len(messages))
+	byID := make(map[string]*model.Message, len(messages))
+	for i := range messages {
+		messageIDs[i] = messages[i].ID
+		byID[messages[i].ID] = &messages[i]
+	}
+	attachments, err := s.repo.GetAttachmentsByMessageIDsTx(ctx, tx, messageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not get attachments: %w", err)
+	}
+	for _, a := range attachments {
+		if msg := byID[a.MessageID]; msg != nil {
+			msg.Attachments = append(msg.Attachments, a)
+		}
+	}
+
+	now := time.Now().UTC()
+	clone := &model.Chat{
+		ID:           uuid.NewString(),
+		UserID:       original.UserID,
+		Title:        truncate("Copy of "+original.Title, maxChatTitleLength),
+		Model:        original.Model,
+		SystemPrompt: original.SystemPrompt,
+		CollectionID: original.CollectionID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.CreateChatTx(ctx, tx, clone); err != nil {
+		return nil, fmt.Errorf("could not create chat: %w", err)
+	}
+
+	// Messages are ordered by `seq`, the order they were stored in, so a parent is
+	// always copied before its children.
+	newIDs := make(map[string]string, len(messages))
+	for _, msg := range messages {
+		newIDs[msg.ID] = uuid.NewString()
+		msg.ID = newIDs[msg.ID]
+		if msg.ParentID != nil {
+			if newParentID, ok := newIDs[*msg.ParentID]; ok {
+				msg.ParentID = &newParentID
+			} else {
+				msg.ParentID = nil
+			}
+		}
+		for i := range msg.Attachments {
+			msg.Attachments[i].ID = uuid.NewString()
+		}
+		if err := s.repo.AddMessageTx(ctx, tx, &msg, clone.ID); err != nil {
+			return nil, fmt.Errorf("could not copy message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit clone: %w", err)
+	}
+	slog.Info("Cloned chat", "chat_id", chatID, "clone_id", clone.ID, "messages", len(messages))
+	return clone, nil
+}
+
+// ListChats retrieves all chat sessions, ordered and filtered according to `opts`.
 // In the current single-user model, this is a direct passthrough to the repository.
-// Future multi-user implementations would introduce filtering/pagination logic here.
-func (s *ChatService) ListChats(ctx context.Context) ([]*model.Chat, error) {
-	return s.repo.GetChats(ctx)
+// Future multi-user implementations would introduce user filtering/pagination logic here.
+func (s *ChatService) ListChats(ctx context.Context, opts model.ChatListOptions) ([]*model.Chat, error) {
+	if opts.Tag != "" {
+		if err := validateTag(opts.Tag); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetChats(ctx, opts)
 }
 
 func (s *ChatService) GetFullChat(ctx context.Context, chatID string) (*model.FullChat, error) {
@@ -86,15 +510,115 @@ func (s *ChatService) GetFullChat(ctx context.Context, chatID string) (*model.Fu
 		return nil, fmt.Errorf("could not get chat: %w", err)
 	}
 
-	messages, err := s.repo.GetActiveMessagesByChatID(ctx, chatID)
+	page, err := s.getMessagePage(ctx, chatID, defaultMessagePageSize, "")
+	if err != nil {
+		return nil, err
+	}
+	// The page is newest first; the full chat view reads top to bottom.
+	slices.Reverse(page.Messages)
+
+	return &model.FullChat{Chat: *chat, Messages: page.Messages, HasMore: page.HasMore}, nil
+}
+
+// GetChatMessages returns a page of a chat's active messages, newest first. Pass the
+// ID of the oldest message received so far as `before` to get the next page.
+// A non-positive limit selects the default page size.
+func (s *ChatService) GetChatMessages(ctx context.Context, chatID string, limit int, before string) (*model.MessagePage, error) {
+	if limit > maxMessagePageSize {
+		return nil, fmt.Errorf("%w: limit must not exceed %d", app_errors.ErrValidation, maxMessagePageSize)
+	}
+	if limit <= 0 {
+		limit = defaultMessagePageSize
+	}
+	if _, err := s.repo.GetChat(ctx, chatID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return nil, fmt.Errorf("could not get chat: %w", err)
+	}
+	return s.getMessagePage(ctx, chatID, limit, before)
+}
+
+// getMessagePage loads a page of active messages with their attachment references.
+// One extra row is requested to find out whether older messages exist.
+func (s *ChatService) getMessagePage(ctx context.Context, chatID string, limit int, before string) (*model.MessagePage, error) {
+	messages, err := s.repo.GetActiveMessagesPage(ctx, chatID, limit+1, before)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: before must be the ID of a message of the chat", app_errors.ErrValidation)
+		}
 		return nil, fmt.Errorf("could not get messages: %w", err)
 	}
+	page := &model.MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.HasMore = true
+	}
+	if page.Messages == nil {
+		page.Messages = []model.Message{}
+	}
+	if err := s.attachAttachmentRefs(ctx, chatID, page.Messages); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// attachAttachmentRefs fills in the attachment references of the given messages.
+func (s *ChatService) attachAttachmentRefs(ctx context.Context, chatID string, messages []model.Message) error {
+	refs, err := s.repo.GetAttachmentRefsByChatID(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("could not get attachments: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	byMessage := make(map[string][]model.Attachment)
+	for _, ref := range refs {
+		byMessage[ref.MessageID] = append(byMessage[ref.MessageID], ref)
+	}
+	for i := range messages {
+		messages[i].Attachments = byMessage[messages[i].ID]
+	}
+	return nil
+}
+
+// GetMessage returns a single message of a chat with its attachment references.
+// A message of another chat is reported as not found.
+func (s *ChatService) GetMessage(ctx context.Context, chatID, messageID string) (*model.Message, error) {
+	msg, err := s.repo.GetMessageByID(ctx, messageID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && msg.ChatID != chatID) {
+		return nil, fmt.Errorf("%w: message with id %s in chat %s", app_errors.ErrNotFound, messageID, chatID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get message: %w", err)
+	}
 
-	return &model.FullChat{Chat: *chat, Messages: messages}, nil
+	messages := []model.Message{*msg}
+	if err := s.attachAttachmentRefs(ctx, chatID, messages); err != nil {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+// GetAttachment returns an attachment of a message, including its content.
+func (s *ChatService) GetAttachment(ctx context.Context, chatID, messageID, attachmentID string) (*model.Attachment, error) {
+	attachment, err := s.repo.GetAttachment(ctx, chatID, messageID, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: attachment with id %s", app_errors.ErrNotFound, attachmentID)
+		}
+		return nil, fmt.Errorf("could not get attachment: %w", err)
+	}
+	return attachment, nil
 }
 
-func (s *ChatService) GetChatTree(ctx context.Context, chatID string) (*model.FullChat, error) {
+// GetChatTree returns every message of a chat, including deactivated branches, for
+// visualizing the conversation tree.
+func (s *ChatService) GetChatTree(ctx context.Context, chatID string, opts model.ChatTreeOptions) (*model.ChatTree, error) {
+	if opts.Depth < 0 {
+		return nil, fmt.Errorf("%w: depth must not be negative", app_errors.ErrValidation)
+	}
+
 	chat, err := s.repo.GetChat(ctx, chatID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -103,12 +627,226 @@ func (s *ChatService) GetChatTree(ctx context.Context, chatID string) (*model.Fu
 		return nil, fmt.Errorf("could not get chat: %w", err)
 	}
 
-	messages, err := s.repo.GetMessagesByChatID(ctx, chatID)
+	messages, err := s.repo.GetAllMessagesByChatID(ctx, chatID, opts.Since)
 	if err != nil {
 		return nil, fmt.Errorf("could not get messages: %w", err)
 	}
 
-	return &model.FullChat{Chat: *chat, Messages: messages}, nil
+	roots := buildMessageTree(messages, opts.Depth)
+	if opts.Nested {
+		return &model.ChatTree{Chat: *chat, Roots: roots}, nil
+	}
+	if opts.Depth > 0 {
+		messages = flattenMessageTree(roots, nil)
+	}
+	if messages == nil {
+		messages = []model.Message{}
+	}
+	return &model.ChatTree{Chat: *chat, Messages: messages}, nil
+}
+
+// buildMessageTree nests messages under their parents. Messages whose parent is
+// not part of the list are roots. With a positive depth, deeper levels are cut off.
+// The input is expected oldest first, which keeps siblings in creation order.
+func buildMessageTree(messages []model.Message, depth int) []model.MessageNode {
+	present := make(map[string]bool, len(messages))
+	for _, m := range messages {
+		present[m.ID] = true
+	}
+	children := make(map[string][]model.Message)
+	var roots []model.Message
+	for _, m := range messages {
+		if m.ParentID == nil || !present[*m.ParentID] {
+			roots = append(roots, m)
+			continue
+		}
+		children[*m.ParentID] = append(children[*m.ParentID], m)
+	}
+
+	var build func(level []model.Message, remaining int) []model.MessageNode
+	build = func(level []model.Message, remaining int) []model.MessageNode {
+		nodes := make([]model.MessageNode, 0, len(level))
+		for _, m := range level {
+			node := model.MessageNode{Message: m, Children: []model.MessageNode{}}
+			if remaining != 1 {
+				node.Children = build(children[m.ID], remaining-1)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(roots, depth)
+}
+
+// flattenMessageTree appends the messages of the tree to `out` in depth-first order.
+func flattenMessageTree(nodes []model.MessageNode, out []model.Message) []model.Message {
+	for _, n := range nodes {
+		out = append(out, n.Message)
+		out = flattenMessageTree(n.Children, out)
+	}
+	return out
+}
+
+// CreateChat creates an empty chat so that it can be configured before the first
+// prompt. Subsequent messages reference the returned chat ID.
+func (s *ChatService) CreateChat(ctx context.Context, req *CreateChatRequest) (*model.Chat, error) {
+	currentSettings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load settings: %w", err)
+	}
+
+	// An alias is stored as given, so that the chat follows it when it is changed.
+	modelToUse := req.Model
+	if modelToUse == "" {
+		modelToUse = currentSettings.MainModel
+	} else if err := s.ensureModelAvailable(ctx, currentSettings.ResolveModel(modelToUse)); err != nil {
+		return nil, err
+	}
+	if modelToUse == "" {
+		return nil, fmt.Errorf("%w: no main model is configured or available, please pull a model first", app_errors.ErrValidation)
+	}
+
+	if req.CollectionID != "" {
+		if err := s.ensureCollectionExists(ctx, req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = defaultChatTitle
+	}
+
+	now := time.Now().UTC()
+	chat := &model.Chat{
+		ID:           uuid.NewString(),
+		UserID:       s.cfg.DefaultUserID,
+		Title:        title,
+		Model:        modelToUse,
+		SystemPrompt: req.SystemPrompt,
+		CollectionID: req.CollectionID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.CreateChat(ctx, chat); err != nil {
+		return nil, fmt.Errorf("could not create chat: %w", err)
+	}
+	slog.Info("Created empty chat", "chat_id", chat.ID, "model", chat.Model)
+	return chat, nil
+}
+
+// AddRawMessage appends a message with an arbitrary role to the chat's active branch
+// without calling the LLM. The message becomes part of the history of later turns.
+func (s *ChatService) AddRawMessage(ctx context.Context, chatID string, req *AddMessageRequest) (*model.Message, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, fmt.Errorf("%w: content must not be empty", app_errors.ErrValidation)
+	}
+
+	if _, err := s.repo.GetChat(ctx, chatID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return nil, fmt.Errorf("could not load chat: %w", err)
+	}
+
+	var parentID *string
+	lastMessage, err := s.repo.GetLastActiveMessage(ctx, chatID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("could not get last message: %w", err)
+	}
+	if lastMessage != nil {
+		parentID = &lastMessage.ID
+	}
+
+	message := &model.Message{
+		ID:        uuid.NewString(),
+		ParentID:  parentID,
+		Role:      req.Role,
+		Content:   req.Content,
+		Timestamp: time.Now().UTC(),
+		IsActive:  true,
+	}
+	if req.Model != "" {
+		message.Model = &req.Model
+	}
+	if err := s.repo.AddMessage(ctx, message, chatID); err != nil {
+		return nil, fmt.Errorf("could not add message: %w", err)
+	}
+	slog.Info("Inserted raw message", "chat_id", chatID, "message_id", message.ID, "role", message.Role)
+	return message, nil
+}
+
+// SetChatCollection binds a chat to a document collection, enabling retrieval for
+// its messages. An empty collection ID disables retrieval.
+func (s *ChatService) SetChatCollection(ctx context.Context, chatID, collectionID string) error {
+	if collectionID != "" {
+		if err := s.ensureCollectionExists(ctx, collectionID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.UpdateChatCollection(ctx, chatID, collectionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return fmt.Errorf("could not update chat collection: %w", err)
+	}
+	return nil
+}
+
+// SetChatPinned pins or unpins a chat. Pinned chats are listed first.
+func (s *ChatService) SetChatPinned(ctx context.Context, chatID string, pinned bool) error {
+	if err := s.repo.UpdateChatPinned(ctx, chatID, pinned); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return fmt.Errorf("could not update chat pin: %w", err)
+	}
+	return nil
+}
+
+// SetChatTags replaces the tags of a chat and returns them normalized: duplicates
+// removed and sorted alphabetically. An empty list removes all tags.
+func (s *ChatService) SetChatTags(ctx context.Context, chatID string, tags []string) ([]string, error) {
+	if len(tags) > maxTagsPerChat {
+		return nil, fmt.Errorf("%w: a chat can have at most %d tags", app_errors.ErrValidation, maxTagsPerChat)
+	}
+	for _, tag := range tags {
+		if err := validateTag(tag); err != nil {
+			return nil, err
+		}
+	}
+	normalized := slices.Compact(slices.Sorted(slices.Values(tags)))
+	if normalized == nil {
+		normalized = []string{}
+	}
+
+	if err := s.repo.SetChatTags(ctx, chatID, normalized); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, chatID)
+		}
+		return nil, fmt.Errorf("could not set chat tags: %w", err)
+	}
+	return normalized, nil
+}
+
+// validateTag returns an `ErrValidation` unless the tag is lowercase letters,
+// digits, `-` or `_`, starting with a letter or digit, and at most maxTagLength long.
+func validateTag(tag string) error {
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("%w: invalid tag '%s', tags must be 1-%d lowercase letters, digits, '-' or '_' without spaces", app_errors.ErrValidation, tag, maxTagLength)
+	}
+	return nil
+}
+
+// ensureCollectionExists returns an `ErrValidation` if the collection does not exist.
+func (s *ChatService) ensureCollectionExists(ctx context.Context, collectionID string) error {
+	if _, err := s.repo.GetCollection(ctx, collectionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: collection '%s' does not exist", app_errors.ErrValidation, collectionID)
+		}
+		return fmt.Errorf("could not get collection: %w", err)
+	}
+	return nil
 }
 
 func (s *ChatService) SwitchBranch(ctx context.Context, chatID string, targetMessageID string) error {
@@ -124,7 +862,7 @@ func (s *ChatService) SwitchBranch(ctx context.Context, chatID string, targetMes
 		}
 	}()
 
-	msg, err := s.repo.GetMessageByID(ctx, targetMessageID)
+	msg, err := s.repo.GetMessageByIDTx(ctx, tx, targetMessageID)
 	if err != nil {
 		return err
 	}
@@ -174,26 +912,24 @@ func (s *ChatService) SwitchBranch(ctx context.Context, chatID string, targetMes
 }
 
 // resolveModels determines the final models and system prompt to use for a request,
-// layering request-specific overrides on top of global settings.
-func (s *ChatService) resolveModels(ctx context.Context, req *CreateMessageRequest, currentSettings *Settings) (mainModel, supportModel, systemPrompt string, err error) {
-	mainModel = req.Model
+// layering request-specific overrides on top of the chat's own configuration (if
+// any) and finally the global settings.
+func (s *ChatService) resolveModels(ctx context.Context, req *CreateMessageRequest, currentSettings *Settings, chat *model.Chat) (mainModel, supportModel, systemPrompt string, err error) {
+	// Precedence: the request's model, then the chat's, then the settings default.
+	// Any of them may be a model alias. Alias names cannot contain a colon, while
+	// local models are always listed with a tag, so a tag is never taken for an alias.
+	// A "provider/model" name, such as "openai/gpt-4o", is routed to that provider
+	// by the LLM provider registry.
+	mainModel = currentSettings.ResolveModel(req.Model)
 	if mainModel == "" {
-		mainModel = currentSettings.MainModel
-	} else {
-		// If a model is specified in the request, validate that it's available.
-		availableModels, err := s.llm.ListModels(ctx)
-		if err != nil {
-			// Non-critical error; proceed without validation but log a warning.
-			slog.Warn("Could not list models to validate request-specific model", "model", mainModel, "error", err)
+		if chat != nil && chat.Model != "" {
+			mainModel = currentSettings.ResolveModel(chat.Model)
 		} else {
-			modelNames := make([]string, len(availableModels.Models))
-			for i, m := range availableModels.Models {
-				modelNames[i] = m.Name
-			}
-			if !slices.Contains(modelNames, mainModel) {
-				return "", "", "", fmt.Errorf("%w: model '%s' specified in request is not available", app_errors.ErrValidation, mainModel)
-			}
+			mainModel = currentSettings.ResolveModel(currentSettings.MainModel)
 		}
+	} else if err := s.ensureModelAvailable(ctx, mainModel); err != nil {
+		// If a model is specified in the request, validate that it's available.
+		return "", "", "", err
 	}
 
 	if mainModel == "" {
@@ -204,17 +940,157 @@ func (s *ChatService) resolveModels(ctx context.Context, req *CreateMessageReque
 	if supportModel == "" {
 		supportModel = currentSettings.SupportModel
 	}
+	supportModel = currentSettings.ResolveModel(supportModel)
 
-	systemPrompt = req.SystemPrompt
+	var userID string
+	if chat != nil {
+		userID = chat.UserID
+	}
+	systemPrompt, err = s.renderSystemPromptFor(resolveSystemPrompt(req.SystemPrompt, req.Options, currentSettings, chat), currentSettings, userID, mainModel)
+	if err != nil {
+		return "", "", "", err
+	}
+	return mainModel, supportModel, systemPrompt, nil
+}
+
+// ensureModelAvailable returns an `ErrValidation` if the model is not available locally.
+// If the model list cannot be fetched, validation is skipped with a warning.
+func (s *ChatService) ensureModelAvailable(ctx context.Context, modelName string) error {
+	availableModels, err := s.llm.ListModels(ctx)
+	if err != nil {
+		// Non-critical error; proceed without validation but log a warning.
+		slog.Warn("Could not list models to validate request-specific model", "model", modelName, "error", err)
+		return nil
+	}
+	modelNames := make([]string, len(availableModels.Models))
+	for i, m := range availableModels.Models {
+		modelNames[i] = m.Name
+	}
+	if !slices.Contains(modelNames, modelName) {
+		return fmt.Errorf("%w: model '%s' specified in request is not available", app_errors.ErrValidation, modelName)
+	}
+	return nil
+}
+
+// resolveSystemPrompt picks the system prompt for a request, falling back to the
+// chat's own prompt and then to the global setting.
+func resolveSystemPrompt(requested string, options *llm.RequestOptions, currentSettings *Settings, chat *model.Chat) string {
+	systemPrompt := requested
+	if systemPrompt == "" && chat != nil {
+		systemPrompt = chat.SystemPrompt
+	}
 	if systemPrompt == "" {
 		systemPrompt = currentSettings.SystemPrompt
 	}
-	// `req.Options.System` is an alternative way to set the system prompt, often used by LLM clients.
-	if req.Options != nil && req.Options.System != nil {
-		systemPrompt = *req.Options.System
+	// `options.System` is an alternative way to set the system prompt, often used by LLM clients.
+	if options != nil && options.System != nil {
+		systemPrompt = *options.System
 	}
+	return systemPrompt
+}
 
-	return mainModel, supportModel, systemPrompt, nil
+// resolveChatModel picks the model for a generation in the same order as
+// `resolveModels`: the requested model, then the chat's, then the settings
+// default. Aliases are resolved. Unlike `resolveModels`, it does not check that
+// the model is available.
+func resolveChatModel(requested string, currentSettings *Settings, chat *model.Chat) string {
+	if requested == "" && chat != nil {
+		requested = chat.Model
+	}
+	if requested == "" {
+		requested = currentSettings.MainModel
+	}
+	return currentSettings.ResolveModel(requested)
+}
+
+// EstimateTokens approximates the prompt size that sending `req` would produce,
+// counting the system prompt, the chat's active history and the new message.
+// Nothing is persisted and the LLM is not called.
+func (s *ChatService) EstimateTokens(ctx context.Context, req *CreateMessageRequest) (*TokenEstimate, error) {
+	if err := s.applyPromptTemplate(ctx, req); err != nil {
+		return nil, err
+	}
+	currentSettings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load settings: %w", err)
+	}
+
+	var chat *model.Chat
+	var history []model.Message
+	if req.ChatID != "" {
+		if chat, err = s.repo.GetChat(ctx, req.ChatID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("%w: chat with id %s", app_errors.ErrNotFound, req.ChatID)
+			}
+			return nil, fmt.Errorf("could not get chat: %w", err)
+		}
+		history, err = s.repo.GetActiveMessagesByChatID(ctx, req.ChatID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get messages: %w", err)
+		}
+	}
+
+	modelName := req.Model
+	userID := ""
+	if chat != nil {
+		userID = chat.UserID
+		if modelName == "" {
+			modelName = chat.Model
+		}
+	}
+	if modelName == "" {
+		modelName = currentSettings.MainModel
+	}
+	modelName = currentSettings.ResolveModel(modelName)
+	systemPrompt, err := s.renderSystemPromptFor(resolveSystemPrompt(req.SystemPrompt, req.Options, currentSettings, chat), currentSettings, userID, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	llmMessages := []llm.Message{{Role: "system", Content: systemPrompt}}
+	estimate := &TokenEstimate{}
+	for _, msg := range history {
+		llmMessages = append(llmMessages, llm.Message{Role: msg.Role, Content: msg.Content})
+		// Remember the most recent real count reported by Ollama for calibration.
+		if msg.Role == "assistant" && len(msg.Metadata) > 0 {
+			var stats llm.GenerationStats
+			if err := json.Unmarshal(msg.Metadata, &stats); err == nil && stats.PromptEvalCount > 0 {
+				estimate.LastPromptEvalCount = stats.PromptEvalCount
+			}
+		}
+	}
+	llmMessages = append(llmMessages, llm.Message{Role: "user", Content: req.Content})
+
+	estimate.EstimatedTokens = llm.EstimateTokens(llmMessages)
+	estimate.MessageCount = len(llmMessages)
+	return estimate, nil
+}
+
+// applyPromptTemplate replaces the content of a request that names a prompt
+// template with the rendered template, followed by the original content.
+func (s *ChatService) applyPromptTemplate(ctx context.Context, req *CreateMessageRequest) error {
+	if req.PromptID == "" {
+		return nil
+	}
+	prompt, err := s.repo.GetPrompt(ctx, req.PromptID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: prompt '%s' does not exist", app_errors.ErrValidation, req.PromptID)
+		}
+		return fmt.Errorf("could not get prompt: %w", err)
+	}
+	rendered, err := renderPromptTemplate(prompt.Body, req.Variables)
+	if err != nil {
+		return err
+	}
+	if req.Content != "" {
+		rendered += "\n\n" + req.Content
+	}
+	if strings.TrimSpace(rendered) == "" {
+		return fmt.Errorf("%w: the rendered prompt is empty", app_errors.ErrValidation)
+	}
+	req.Content = rendered
+	return nil
 }
 
 // HandleNewMessage is the main entry point for processing a new user message.
@@ -227,6 +1103,19 @@ func (s *ChatService) HandleNewMessage(
 ) {
 	defer close(streamChan)
 
+	// A dry run leaves nothing behind that a repeated request could replay.
+	if req.IdempotencyKey != "" && !req.DryRun {
+		if !s.acquireIdempotencyKey(req.IdempotencyKey) {
+			streamChan <- model.StreamResponse{Error: "A request with this idempotency key is already being processed"}
+			return
+		}
+		defer s.releaseIdempotencyKey(req.IdempotencyKey)
+
+		if s.replayIdempotentRequest(ctx, req.IdempotencyKey, streamChan) {
+			return
+		}
+	}
+
 	currentSettings, err := s.settingsService.Get(ctx)
 	if err != nil {
 		slog.Error("Could not get settings for new message", "error", err)
@@ -234,20 +1123,63 @@ func (s *ChatService) HandleNewMessage(
 		return
 	}
 
-	modelToUse, supportModelToUse, systemPromptToUse, err := s.resolveModels(ctx, req, currentSettings)
+	if err := s.applyPromptTemplate(ctx, req); err != nil {
+		streamChan <- model.StreamResponse{Error: err.Error()}
+		return
+	}
+
+	// A blocked prompt is rejected before anything is persisted or sent to the model.
+	if err := s.cfg.ContentFilter.Check(ctx, req.Content); err != nil {
+		slog.Warn("Blocked a message by the content filter", "chat_id", req.ChatID, "error", err)
+		streamChan <- model.StreamResponse{ChatID: req.ChatID, Error: err.Error()}
+		return
+	}
+
+	// Images are validated before anything is persisted, so that an oversized
+	// upload does not leave an empty chat behind.
+	attachments, err := s.decodeImages(req.Images)
 	if err != nil {
 		streamChan <- model.StreamResponse{Error: err.Error()}
 		return
 	}
 
+	// A rejected generation must not leave a chat or a message behind either.
+	release, ok := s.acquireGenerationSlot(ctx, req.ChatID, streamChan)
+	if !ok {
+		return
+	}
+	defer release()
+
 	isNewChat := req.ChatID == ""
 	chatID := req.ChatID
 
-	if isNewChat {
+	// An existing chat may carry its own model and system prompt (e.g. when it was
+	// pre-created via `CreateChat`), so it must be loaded before resolving models.
+	var existingChat *model.Chat
+	if !isNewChat {
+		existingChat, err = s.repo.GetChat(ctx, chatID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				streamChan <- model.StreamResponse{Error: "Chat not found"}
+				return
+			}
+			slog.Error("Error loading chat for new message", "chat_id", chatID, "error", err)
+			streamChan <- model.StreamResponse{Error: "Could not load chat"}
+			return
+		}
+	}
+
+	modelToUse, supportModelToUse, systemPromptToUse, err := s.resolveModels(ctx, req, currentSettings, existingChat)
+	if err != nil {
+		streamChan <- model.StreamResponse{Error: err.Error()}
+		return
+	}
+
+	if isNewChat && !req.DryRun {
 		chatID = uuid.NewString()
 		// For new chats, use a truncated version of the first message as a temporary title.
-		// The chat is created without any user association in this single-user model.
-		chat := &model.Chat{ID: chatID, Title: truncate(req.Content, 50), Model: modelToUse, CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC()}
+		// In this single-user model every chat belongs to the configured default user.
+		chat := &model.Chat{ID: chatID, UserID: s.cfg.DefaultUserID, Title: truncate(req.Content, s.cfg.TitlePreviewLength), Model: modelToUse, CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC()}
 		if err := s.repo.CreateChat(ctx, chat); err != nil {
 			slog.Error("Error creating chat", "error", err)
 			streamChan <- model.StreamResponse{Error: "Could not create chat"}
@@ -255,74 +1187,149 @@ func (s *ChatService) HandleNewMessage(
 		}
 	}
 
-	lastMessage, err := s.repo.GetLastActiveMessage(ctx, chatID)
-	// This is not a fatal error; it just means there's no previous context to send.
-	if err != nil && !errors.Is(err, repository.ErrNotFound) {
-		slog.Warn("Error getting last message for chat", "chat_id", chatID, "error", err)
+	// The chat of a dry run for a new conversation does not exist.
+	chatExists := !isNewChat || !req.DryRun
+
+	var lastMessage *model.Message
+	if chatExists {
+		lastMessage, err = s.repo.GetLastActiveMessage(ctx, chatID)
+		// This is not a fatal error; it just means there's no previous context to send.
+		// It is also the normal case for the first message of a pre-created, empty chat.
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			slog.Warn("Error getting last message for chat", "chat_id", chatID, "error", err)
+		}
 	}
 
+	// A pre-created chat that still has its placeholder title gets a generated
+	// title after its first exchange, just like a brand-new chat.
+	needsTitle := isNewChat || (existingChat != nil && lastMessage == nil && existingChat.Title == defaultChatTitle)
+
 	var parentID *string
-	var ollamaContext json.RawMessage
 	if lastMessage != nil {
 		parentID = &lastMessage.ID
-		ollamaContext = lastMessage.Context
 	}
 
-	userMessage := &model.Message{ID: uuid.NewString(), ParentID: parentID, Role: "user", Content: req.Content, Timestamp: time.Now().UTC()}
-	if err := s.repo.AddMessage(ctx, userMessage, chatID); err != nil {
-		// Log the error but don't stop; we can still try to get a response from the LLM.
-		slog.Error("Error adding user message", "chat_id", chatID, "error", err)
+	userMessage := &model.Message{ID: uuid.NewString(), ParentID: parentID, Role: "user", Content: req.Content, Timestamp: time.Now().UTC(), Attachments: attachments}
+	for i := range userMessage.Attachments {
+		userMessage.Attachments[i].MessageID = userMessage.ID
+	}
+	if !req.DryRun {
+		if err := s.repo.AddMessage(ctx, userMessage, chatID); err != nil {
+			// Log the error but don't stop; we can still try to get a response from the LLM.
+			slog.Error("Error adding user message", "chat_id", chatID, "error", err)
+		}
 	}
 
-	history, err := s.repo.GetActiveMessagesByChatID(ctx, chatID)
-	if err != nil {
-		slog.Warn("Error getting message history for chat", "chat_id", chatID, "error", err)
+	var history []model.Message
+	if chatExists {
+		history, err = s.repo.GetActiveMessagesByChatID(ctx, chatID)
+		if err != nil {
+			slog.Warn("Error getting message history for chat", "chat_id", chatID, "error", err)
+		}
+	}
+	if req.DryRun {
+		history = append(history, *userMessage)
 	}
 
 	// Construct the payload for the LLM provider, including the system prompt and history.
-	llmMessages := []llm.Message{{Role: "system", Content: systemPromptToUse}}
-	for _, msg := range history {
-		llmMessages = append(llmMessages, llm.Message{Role: msg.Role, Content: msg.Content})
+	llmMessages, err := s.buildLLMMessages(ctx, nil, systemPromptToUse, history)
+	if err != nil {
+		slog.Error("Error loading attachments for chat", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "Could not load message attachments"}
+		return
+	}
+	if req.DryRun {
+		// The images of the unsaved message cannot be loaded from the database.
+		last := &llmMessages[len(llmMessages)-1]
+		for _, a := range attachments {
+			last.Images = append(last.Images, base64.StdEncoding.EncodeToString(a.Data))
+		}
 	}
 
+	if existingChat != nil {
+		s.augmentWithDocuments(ctx, nil, existingChat.CollectionID, llmMessages)
+	}
+
+	// The whole active history is sent with every turn; it is what carries the
+	// conversation, as the chat endpoint keeps no state between requests.
 	llmReq := &llm.GenerateRequest{
 		Model:    modelToUse,
 		Messages: llmMessages,
-		Context:  ollamaContext, // Pass the context from the previous turn for stateful conversation.
-		Options:  req.Options,
 	}
+	options := mergeOptions(currentSettings.DefaultOptions, modelDefaultOptions(ctx, s.repo, modelToUse), req.Options)
+	llmReq.Options, llmReq.KeepAlive = resolveKeepAlive(options, currentSettings)
+	llmReq.Options, llmReq.Format = resolveFormat(llmReq.Options, req.ResponseFormat)
 
-	var fullResponse strings.Builder
-	var finalContext json.RawMessage
+	var fullResponse, rawResponse strings.Builder
 	var finalStats *llm.GenerationStats
-	llmStreamChan := make(chan llm.StreamResponse)
-	// The actual LLM call is run in a goroutine to allow this function to process the stream.
-	go func() {
-		if err := s.llm.GenerateStream(ctx, llmReq, llmStreamChan); err != nil {
-			slog.Error("LLM stream generation failed", "error", err)
-		}
-	}()
-
-	// Consume from the LLM stream and forward to the client.
+	var completed bool
+	// The client is told that the model is loading until the first token arrives,
+	// which can take a while for a model that is not in memory or a long prompt.
+	streamChan <- model.StreamResponse{ChatID: chatID, Phase: model.PhaseLoading}
+	startedAt := time.Now()
+	var timeToFirstToken time.Duration
+	cacheKey, llmStreamChan := s.startGeneration(ctx, llmReq)
+
+	// Consume from the LLM stream and forward to the client. Reasoning is split
+	// from the answer and only forwarded if requested.
+	showReasoning := resolveShowReasoning(req.ShowReasoning, currentSettings)
+	var splitter reasoningSplitter
+	var fullReasoning strings.Builder
+	var streamFailed bool
+	var generationError string
+	var heldChunk *heldBackChunk
 	for chunk := range llmStreamChan {
-		streamChan <- model.StreamResponse{ChatID: chatID, Content: chunk.Content, Done: chunk.Done, Error: chunk.Error}
 		if chunk.Error != "" {
+			streamChan <- model.StreamResponse{ChatID: chatID, Error: chunk.Error}
+			streamFailed = true
+			generationError = chunk.Error
 			break // Stop processing on LLM error.
 		}
-		fullResponse.WriteString(chunk.Content)
+		if chunk.Content != "" && timeToFirstToken == 0 {
+			timeToFirstToken = time.Since(startedAt)
+			streamChan <- model.StreamResponse{ChatID: chatID, Phase: model.PhaseGenerating}
+		}
+		rawResponse.WriteString(chunk.Content)
+		content, reasoning := splitter.Push(chunk.Content)
 		if chunk.Done {
-			finalContext = chunk.Context
-			finalStats = chunk.Stats
+			restContent, restReasoning := splitter.Flush()
+			content, reasoning = content+restContent, reasoning+restReasoning
+			finalStats = withTimeToFirstToken(chunk.Stats, timeToFirstToken)
+			completed = true
 		}
+		fullResponse.WriteString(content)
+		fullReasoning.WriteString(reasoning)
+		response := model.StreamResponse{ChatID: chatID, Content: content, Done: chunk.Done}
+		if chunk.Done && (s.cfg.FilterResponses || llmReq.Format != "") {
+			heldChunk = &heldBackChunk{response: response, reasoning: reasoning}
+			continue
+		}
+		forwardChunk(streamChan, response, reasoning, showReasoning)
 	}
+	restContent, restReasoning := splitter.Flush()
+	fullResponse.WriteString(restContent)
+	fullReasoning.WriteString(restReasoning)
 	slog.Debug("Finished streaming response from LLM.")
 
-	var metadata json.RawMessage
-	if finalStats != nil {
-		metadata, _ = json.Marshal(finalStats)
+	if !streamFailed && completed {
+		warnOnInvalidFormat(chatID, llmReq.Format, fullResponse.String(), streamChan)
+	}
+	if !streamFailed && !s.releaseFilteredResponse(ctx, chatID, fullResponse.String(), heldChunk, streamChan, showReasoning) {
+		return
+	}
+	// Only complete answers are cached; an interrupted stream has no final chunk.
+	if cacheKey != "" && completed {
+		s.responses.add(cachedResponse{key: cacheKey, content: rawResponse.String(), stats: finalStats})
 	}
 
-	// Persist the complete assistant message to the database.
+	if req.DryRun {
+		return
+	}
+
+	metadata := buildAssistantMetadata(finalStats, fullReasoning.String(), llmReq.Options, generationError)
+
+	// Persist the assistant message to the database. A failed answer is kept up
+	// to the failure, with the error in its metadata.
 	assistantMessage := &model.Message{
 		ID:        uuid.NewString(),
 		ParentID:  &userMessage.ID,
@@ -338,17 +1345,274 @@ func (s *ChatService) HandleNewMessage(
 		return
 	}
 
-	if finalContext != nil {
-		if err := s.repo.UpdateMessageContext(ctx, assistantMessage.ID, finalContext); err != nil {
-			slog.Warn("Error setting Ollama context for message", "message_id", assistantMessage.ID, "error", err)
+	// A failed or incomplete generation is not recorded, so that retrying with the
+	// same key generates the answer again instead of replaying a truncated one.
+	if req.IdempotencyKey != "" && completed {
+		s.saveIdempotencyRecord(ctx, req.IdempotencyKey, chatID, assistantMessage.ID)
+	}
+
+	// If it was the first exchange of the chat, spawn a background task to generate a better title.
+	if needsTitle {
+		s.startTitleJob(chatID, supportModelToUse, currentSettings.TitlePromptTemplate, userMessage.Content, assistantMessage.Content)
+	}
+}
+
+// tooManyGenerationsMessage is the stream error of a generation that found no
+// free slot, the equivalent of a 429 in a stream that has already started.
+const tooManyGenerationsMessage = "Too many answers are being generated; try again later"
+
+// acquireGenerationSlot takes one of the MaxConcurrentGenerations slots. While
+// all are taken, it sends a "queued" event and waits up to GenerationQueueTimeout.
+// If no slot becomes free, an error is sent and ok is false; otherwise the
+// caller must call release once its generation is over.
+func (s *ChatService) acquireGenerationSlot(ctx context.Context, chatID string, streamChan chan<- model.StreamResponse) (release func(), ok bool) {
+	if s.generations == nil {
+		return func() {}, true
+	}
+	release = func() { s.generations.Release(1) }
+	if s.generations.TryAcquire(1) {
+		return release, true
+	}
+	if s.cfg.GenerationQueueTimeout <= 0 {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: tooManyGenerationsMessage}
+		return nil, false
+	}
+
+	streamChan <- model.StreamResponse{ChatID: chatID, Phase: model.PhaseQueued}
+	waitCtx, cancel := context.WithTimeout(ctx, s.cfg.GenerationQueueTimeout)
+	defer cancel()
+	if err := s.generations.Acquire(waitCtx, 1); err != nil {
+		// A client that went away does not need to be told.
+		if ctx.Err() == nil {
+			slog.Warn("A generation waited too long for a free slot", "chat_id", chatID, "timeout", s.cfg.GenerationQueueTimeout)
+			streamChan <- model.StreamResponse{ChatID: chatID, Error: tooManyGenerationsMessage}
 		}
+		return nil, false
 	}
+	return release, true
+}
+
+// startGeneration streams the answer to llmReq. An answer to a deterministic
+// request that is in the response cache is replayed as a single chunk instead
+// of calling the LLM. The returned key is non-empty if the answer should be
+// added to the cache once it is complete.
+func (s *ChatService) startGeneration(ctx context.Context, llmReq *llm.GenerateRequest) (string, <-chan llm.StreamResponse) {
+	llmStreamChan := make(chan llm.StreamResponse)
 
-	// If it was a new chat, spawn a background task to generate a better title.
-	if isNewChat {
-		// #nosec G118 -- This is an intentional background task that should not be tied to the request's context.
-		// If the user disconnects, we still want the title generation to complete.
-		go s.generateTitle(context.Background(), chatID, supportModelToUse, userMessage.Content, assistantMessage.Content)
+	var cacheKey string
+	if s.responses != nil {
+		cacheKey = responseCacheKey(llmReq)
+	}
+	if cacheKey != "" {
+		if cached, ok := s.responses.get(cacheKey); ok {
+			slog.Debug("Serving a deterministic request from the response cache", "model", llmReq.Model)
+			go func() {
+				defer close(llmStreamChan)
+				select {
+				case llmStreamChan <- llm.StreamResponse{Content: cached.content, Done: true, Stats: cached.stats}:
+				case <-ctx.Done():
+				}
+			}()
+			return "", llmStreamChan
+		}
+	}
+
+	// The actual LLM call is run in a goroutine to allow the caller to process the
+	// stream. The provider closes its own channel, so its chunks are forwarded to
+	// be able to report a failed generation as a final error chunk.
+	providerChan := make(chan llm.StreamResponse)
+	go func() {
+		defer close(llmStreamChan)
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.llm.GenerateStream(ctx, llmReq, providerChan) }()
+		for chunk := range providerChan {
+			select {
+			case llmStreamChan <- chunk:
+			case <-ctx.Done():
+			}
+		}
+		err := <-errCh
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		slog.Error("LLM stream generation failed", "model", llmReq.Model, "error", err)
+		select {
+		case llmStreamChan <- llm.StreamResponse{Error: generationErrorMessage(err)}:
+		case <-ctx.Done():
+		}
+	}()
+	return cacheKey, llmStreamChan
+}
+
+// generationErrorMessage turns a failed generation into a message for the
+// client. An unreachable backend and a missing model are named as such, so that
+// they are not mistaken for a problem with the conversation; other errors may
+// contain internals.
+func generationErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, llm.ErrUnreachable):
+		return llm.ErrUnreachable.Error()
+	case errors.Is(err, llm.ErrModelNotFound):
+		return "The model is not available; pull it first"
+	default:
+		return "The model failed to generate a response"
+	}
+}
+
+// withTimeToFirstToken returns a copy of the stats with the time to the first
+// token; the original may be shared with the response cache.
+func withTimeToFirstToken(stats *llm.GenerationStats, timeToFirstToken time.Duration) *llm.GenerationStats {
+	if stats == nil {
+		return nil
+	}
+	withTTFT := *stats
+	withTTFT.TimeToFirstToken = timeToFirstToken.Nanoseconds()
+	return &withTTFT
+}
+
+// resolveShowReasoning decides whether reasoning is streamed to the client: the
+// request's flag wins over the global setting.
+func resolveShowReasoning(requested *bool, settings *Settings) bool {
+	if requested != nil {
+		return *requested
+	}
+	return settings.ShowReasoning
+}
+
+// resolveKeepAlive lifts a per-request `keep_alive` out of the options, since
+// Ollama expects it at the top level of the request, and falls back to the
+// `keep_alive` setting. The returned options are a copy when they are changed.
+func resolveKeepAlive(opts *llm.RequestOptions, settings *Settings) (*llm.RequestOptions, *llm.KeepAlive) {
+	if opts != nil && opts.KeepAlive != nil {
+		keepAlive := opts.KeepAlive
+		stripped := *opts
+		stripped.KeepAlive = nil
+		return &stripped, keepAlive
+	}
+	if settings.KeepAlive != "" {
+		keepAlive := llm.KeepAlive(settings.KeepAlive)
+		return opts, &keepAlive
+	}
+	return opts, nil
+}
+
+// resolveFormat lifts `format` out of the model options, because Ollama expects
+// it at the top level of the request. `responseFormat` from the message request
+// takes precedence. The caller's options are not modified.
+func resolveFormat(opts *llm.RequestOptions, responseFormat string) (*llm.RequestOptions, string) {
+	if opts != nil && opts.Format != nil {
+		format := *opts.Format
+		stripped := *opts
+		stripped.Format = nil
+		opts = &stripped
+		if responseFormat == "" {
+			responseFormat = format
+		}
+	}
+	return opts, responseFormat
+}
+
+// warnOnInvalidFormat sends a stream warning if a response generated in JSON mode
+// is not valid JSON. Ollama constrains the output, but a truncated answer (e.g.
+// by `num_predict`) can still be incomplete.
+func warnOnInvalidFormat(chatID, format, response string, streamChan chan<- model.StreamResponse) {
+	if format != "json" || json.Valid([]byte(response)) {
+		return
+	}
+	slog.Warn("Model response in JSON mode is not valid JSON", "chat_id", chatID)
+	streamChan <- model.StreamResponse{ChatID: chatID, Warning: "The response is not valid JSON"}
+}
+
+// heldBackChunk is the final chunk of a stream, held back while the complete
+// response is checked by the content filter or for its format.
+type heldBackChunk struct {
+	response  model.StreamResponse
+	reasoning string
+}
+
+// releaseFilteredResponse checks a complete response if response filtering is
+// enabled. On success it forwards the held-back final chunk and returns true; a
+// blocked response is reported as a stream error instead.
+func (s *ChatService) releaseFilteredResponse(ctx context.Context, chatID, response string, held *heldBackChunk, streamChan chan<- model.StreamResponse, showReasoning bool) bool {
+	if s.cfg.FilterResponses {
+		if err := s.cfg.ContentFilter.Check(ctx, response); err != nil {
+			slog.Warn("Blocked a model response by the content filter", "chat_id", chatID, "error", err)
+			streamChan <- model.StreamResponse{ChatID: chatID, Error: err.Error()}
+			return false
+		}
+	}
+	if held != nil {
+		forwardChunk(streamChan, held.response, held.reasoning, showReasoning)
+	}
+	return true
+}
+
+// forwardChunk sends a chunk to the client, attaching the reasoning only if it is
+// to be shown. Chunks left without any payload are not sent.
+func forwardChunk(streamChan chan<- model.StreamResponse, chunk model.StreamResponse, reasoning string, showReasoning bool) {
+	if showReasoning {
+		chunk.Reasoning = reasoning
+	}
+	if chunk.Content == "" && chunk.Reasoning == "" && !chunk.Done {
+		return
+	}
+	streamChan <- chunk
+}
+
+// acquireIdempotencyKey marks a key as in flight. It returns false if another
+// request with the same key is still being processed.
+func (s *ChatService) acquireIdempotencyKey(key string) bool {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	if _, busy := s.inFlightKeys[key]; busy {
+		return false
+	}
+	s.inFlightKeys[key] = struct{}{}
+	return true
+}
+
+func (s *ChatService) releaseIdempotencyKey(key string) {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	delete(s.inFlightKeys, key)
+}
+
+// replayIdempotentRequest streams the stored answer of an already processed request
+// with the same key. It returns false if the key is unknown or expired, in which
+// case the request is processed normally.
+func (s *ChatService) replayIdempotentRequest(ctx context.Context, key string, streamChan chan<- model.StreamResponse) bool {
+	record, err := s.repo.GetIdempotencyRecord(ctx, key, time.Now().UTC().Add(-s.cfg.IdempotencyTTL))
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			slog.Warn("Could not look up idempotency key, processing request", "error", err)
+		}
+		return false
+	}
+
+	slog.Info("Replaying response for repeated request", "chat_id", record.ChatID, "message_id", record.MessageID)
+	message, err := s.repo.GetMessageByID(ctx, record.MessageID)
+	if err != nil {
+		slog.Error("Could not load message of idempotent request", "message_id", record.MessageID, "error", err)
+		streamChan <- model.StreamResponse{ChatID: record.ChatID, Error: "Could not load the original response"}
+		return true
+	}
+	streamChan <- model.StreamResponse{ChatID: record.ChatID, Content: message.Content, Done: true}
+	return true
+}
+
+// saveIdempotencyRecord remembers the answer of a request and prunes expired keys.
+// Failures are only logged: the answer has already been delivered.
+func (s *ChatService) saveIdempotencyRecord(ctx context.Context, key, chatID, messageID string) {
+	now := time.Now().UTC()
+	record := &model.IdempotencyRecord{Key: key, ChatID: chatID, MessageID: messageID, CreatedAt: now}
+	if err := s.repo.SaveIdempotencyRecord(ctx, record); err != nil {
+		slog.Warn("Could not save idempotency key", "chat_id", chatID, "error", err)
+		return
+	}
+	if err := s.repo.DeleteIdempotencyRecordsBefore(ctx, now.Add(-s.cfg.IdempotencyTTL)); err != nil {
+		slog.Warn("Could not prune expired idempotency keys", "error", err)
 	}
 }
 
@@ -369,17 +1633,39 @@ func (s *ChatService) RegenerateMessage(
 		return
 	}
 
-	modelToUse := req.Model
-	if modelToUse == "" {
-		modelToUse = currentSettings.MainModel
+	// A chat may carry its own model and system prompt, which a regenerated
+	// answer uses just like a new one, unless the request overrides them.
+	chat, err := s.repo.GetChat(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			streamChan <- model.StreamResponse{Error: "Chat not found"}
+			return
+		}
+		slog.Error("Error loading chat for regeneration", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load chat"}
+		return
 	}
-	systemPromptToUse := req.SystemPrompt
-	if systemPromptToUse == "" {
-		systemPromptToUse = currentSettings.SystemPrompt
+
+	modelToUse := resolveChatModel(req.Model, currentSettings, chat)
+	systemPromptToUse, err := s.renderSystemPromptFor(resolveSystemPrompt(req.SystemPrompt, req.Options, currentSettings, chat), currentSettings, chat.UserID, modelToUse)
+	if err != nil {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: err.Error()}
+		return
+	}
+	modelDefaults := modelDefaultOptions(ctx, s.repo, modelToUse)
+
+	// The slot is taken before the transaction, so that a queued regeneration
+	// does not hold the database lock while it waits.
+	release, ok := s.acquireGenerationSlot(ctx, chatID, streamChan)
+	if !ok {
+		return
 	}
+	defer release()
 
 	// The entire regeneration process is performed within a single database transaction
-	// to ensure data consistency.
+	// to ensure data consistency. Everything it reads from then on is read within the
+	// transaction, as a read on another connection would wait forever for a pool of
+	// one connection.
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
 		slog.Error("Regenerate failed to begin transaction", "error", err)
@@ -392,8 +1678,8 @@ func (s *ChatService) RegenerateMessage(
 		}
 	}()
 
-	originalMsg, err := s.repo.GetMessageByID(ctx, originalAssistantMessageID)
-	if err != nil || originalMsg.Role != "assistant" || originalMsg.ParentID == nil {
+	originalMsg, err := s.repo.GetMessageByIDTx(ctx, tx, originalAssistantMessageID)
+	if err != nil || originalMsg.ChatID != chatID || originalMsg.Role != "assistant" || originalMsg.ParentID == nil {
 		streamChan <- model.StreamResponse{Error: "Original message not found or invalid"}
 		return
 	}
@@ -413,21 +1699,29 @@ func (s *ChatService) RegenerateMessage(
 		return
 	}
 
-	llmMessages := []llm.Message{{Role: "system", Content: systemPromptToUse}}
-	for _, msg := range history {
-		llmMessages = append(llmMessages, llm.Message{Role: msg.Role, Content: msg.Content})
+	llmMessages, err := s.buildLLMMessages(ctx, tx, systemPromptToUse, history)
+	if err != nil {
+		slog.Error("Regenerate failed to load attachments", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load message attachments"}
+		return
 	}
+	s.augmentWithDocuments(ctx, tx, chat.CollectionID, llmMessages)
 
 	llmReq := &llm.GenerateRequest{
 		Model:    modelToUse,
 		Messages: llmMessages,
-		Options:  req.Options,
 	}
-	slog.Debug("Ollama regeneration request payload", "payload", llmReq)
+	options := mergeOptions(currentSettings.DefaultOptions, modelDefaults, req.Options)
+	llmReq.Options, llmReq.KeepAlive = resolveKeepAlive(options, currentSettings)
+	llmReq.Options, llmReq.Format = resolveFormat(llmReq.Options, "")
+
+	// The first event tells the client which message the new answer replaces, so
+	// that it can update the branches before any content arrives.
+	newMessageID := uuid.NewString()
+	streamChan <- model.StreamResponse{ChatID: chatID, MessageID: newMessageID, ReplacedMessageID: originalAssistantMessageID}
 
 	// --- Streaming logic (similar to HandleNewMessage) ---
 	var fullResponse strings.Builder
-	var finalContext json.RawMessage
 	var finalStats *llm.GenerationStats
 	llmStreamChan := make(chan llm.StreamResponse)
 	go func() {
@@ -436,28 +1730,65 @@ func (s *ChatService) RegenerateMessage(
 		}
 	}()
 
+	showReasoning := resolveShowReasoning(req.ShowReasoning, currentSettings)
+	var splitter reasoningSplitter
+	var fullReasoning strings.Builder
+	var heldChunk *heldBackChunk
+	var completed bool
 	for chunk := range llmStreamChan {
-		streamChan <- model.StreamResponse{ChatID: chatID, Content: chunk.Content, Done: chunk.Done, Error: chunk.Error}
 		if chunk.Error != "" {
+			streamChan <- model.StreamResponse{ChatID: chatID, Error: chunk.Error}
 			return // The transaction will be rolled back by the defer statement.
 		}
-		fullResponse.WriteString(chunk.Content)
+		if ctx.Err() != nil {
+			break
+		}
+		content, reasoning := splitter.Push(chunk.Content)
 		if chunk.Done {
-			finalContext = chunk.Context
+			restContent, restReasoning := splitter.Flush()
+			content, reasoning = content+restContent, reasoning+restReasoning
 			finalStats = chunk.Stats
+			completed = true
+		}
+		fullResponse.WriteString(content)
+		fullReasoning.WriteString(reasoning)
+		response := model.StreamResponse{ChatID: chatID, Content: content, Done: chunk.Done}
+		if chunk.Done && (s.cfg.FilterResponses || llmReq.Format != "") {
+			heldChunk = &heldBackChunk{response: response, reasoning: reasoning}
+			continue
 		}
+		forwardChunk(streamChan, response, reasoning, showReasoning)
 	}
+	restContent, restReasoning := splitter.Flush()
+	fullResponse.WriteString(restContent)
+	fullReasoning.WriteString(restReasoning)
 	slog.Debug("Finished streaming regenerated response from LLM.")
-	// --- End of streaming logic ---
 
-	var metadata json.RawMessage
-	if finalStats != nil {
-		metadata, _ = json.Marshal(finalStats)
+	// A partial answer must not replace the original one. If the client went away
+	// or the stream ended without its final chunk, the transaction is rolled back
+	// by the defer statement, which reactivates the original branch.
+	if err := ctx.Err(); err != nil {
+		slog.Info("Regeneration was cancelled, keeping the original answer", "chat_id", chatID, "error", err)
+		return
+	}
+	if !completed {
+		slog.Warn("Regeneration stream ended early, keeping the original answer", "chat_id", chatID)
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "The model stopped before completing the answer"}
+		return
 	}
 
+	warnOnInvalidFormat(chatID, llmReq.Format, fullResponse.String(), streamChan)
+	// A blocked answer rolls the transaction back, so the original branch stays active.
+	if !s.releaseFilteredResponse(ctx, chatID, fullResponse.String(), heldChunk, streamChan, showReasoning) {
+		return
+	}
+	// --- End of streaming logic ---
+
+	metadata := buildAssistantMetadata(finalStats, fullReasoning.String(), llmReq.Options, "")
+
 	// Create the new assistant message, linking it to the same parent as the original.
 	newAssistantMessage := &model.Message{
-		ID:        uuid.NewString(),
+		ID:        newMessageID,
 		ParentID:  originalMsg.ParentID,
 		Role:      "assistant",
 		Content:   fullResponse.String(),
@@ -480,39 +1811,509 @@ func (s *ChatService) RegenerateMessage(
 		slog.Error("Failed to commit regeneration transaction", "error", err)
 		return
 	}
+}
+
+// GenerateAlternatives answers a user message of the active branch with each of
+// the given models at once, e.g. to compare them. Every event is tagged with its
+// model, and the first one of each model carries the ID its answer will be
+// stored with. Each complete answer is stored as a branch of the user message;
+// the answer of the first model becomes the active branch and replaces the
+// previous answer, which stays available like after a regeneration. A model
+// that fails reports an error event without affecting the others.
+func (s *ChatService) GenerateAlternatives(
+	ctx context.Context,
+	chatID string,
+	parentUserMessageID string,
+	models []string,
+	streamChan chan<- model.StreamResponse,
+) {
+	defer close(streamChan)
+
+	if len(models) == 0 {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "At least one model is required"}
+		return
+	}
+	currentSettings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		slog.Error("Could not get settings for comparison", "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load application settings"}
+		return
+	}
 
-	if finalContext != nil {
-		// Context update happens outside the transaction as it's not critical for consistency.
-		if err := s.repo.UpdateMessageContext(ctx, newAssistantMessage.ID, finalContext); err != nil {
-			slog.Warn("Error setting Ollama context for new message", "message_id", newAssistantMessage.ID, "error", err)
+	chat, err := s.repo.GetChat(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			streamChan <- model.StreamResponse{Error: "Chat not found"}
+			return
+		}
+		slog.Error("Error loading chat for comparison", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load chat"}
+		return
+	}
+	history, err := s.repo.GetActiveMessagesByChatID(ctx, chatID)
+	if err != nil {
+		slog.Error("Comparison failed to get history", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "Could not retrieve message history"}
+		return
+	}
+	// The answers see the conversation up to the user message, not what followed it.
+	parent := slices.IndexFunc(history, func(m model.Message) bool { return m.ID == parentUserMessageID })
+	if parent < 0 || history[parent].Role != "user" {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "Message not found or not a user message on the active branch"}
+		return
+	}
+	history = history[:parent+1]
+
+	systemPrompt := resolveSystemPrompt("", nil, currentSettings, chat)
+
+	answers := make([]*model.Message, len(models))
+	var wg sync.WaitGroup
+	for i, name := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answers[i] = s.generateAlternative(ctx, chat, currentSettings.ResolveModel(name), systemPrompt, history, currentSettings, streamChan)
+		}()
+	}
+	wg.Wait()
+
+	// As with a regeneration, answers cut short by a client that went away are not stored.
+	if err := ctx.Err(); err != nil {
+		slog.Info("Comparison was cancelled, keeping the original answer", "chat_id", chatID, "error", err)
+		return
+	}
+	if err := s.saveAlternatives(ctx, chatID, parentUserMessageID, answers); err != nil {
+		slog.Error("Failed to save the compared answers", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "Could not save the answers"}
+	}
+}
+
+// generateAlternative streams the answer of one model to the history, tagging
+// every event with the model. It returns the answer to store, or nil if the
+// answer failed or is incomplete.
+func (s *ChatService) generateAlternative(ctx context.Context, chat *model.Chat, modelName, systemPrompt string, history []model.Message, settings *Settings, streamChan chan<- model.StreamResponse) *model.Message {
+	chatID := chat.ID
+	out := make(chan model.StreamResponse)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for event := range out {
+			event.Model = modelName
+			streamChan <- event
+		}
+	}()
+	defer func() {
+		close(out)
+		<-forwarded
+	}()
+
+	release, ok := s.acquireGenerationSlot(ctx, chatID, out)
+	if !ok {
+		return nil
+	}
+	defer release()
+
+	renderedPrompt, err := s.renderSystemPromptFor(systemPrompt, settings, chat.UserID, modelName)
+	if err != nil {
+		out <- model.StreamResponse{ChatID: chatID, Error: err.Error()}
+		return nil
+	}
+	llmMessages, err := s.buildLLMMessages(ctx, nil, renderedPrompt, history)
+	if err != nil {
+		slog.Error("Comparison failed to load attachments", "chat_id", chatID, "error", err)
+		out <- model.StreamResponse{ChatID: chatID, Error: "Could not load message attachments"}
+		return nil
+	}
+	s.augmentWithDocuments(ctx, nil, chat.CollectionID, llmMessages)
+	llmReq := &llm.GenerateRequest{Model: modelName, Messages: llmMessages}
+	options := mergeOptions(settings.DefaultOptions, modelDefaultOptions(ctx, s.repo, modelName), nil)
+	llmReq.Options, llmReq.KeepAlive = resolveKeepAlive(options, settings)
+	llmReq.Options, llmReq.Format = resolveFormat(llmReq.Options, "")
+
+	messageID := uuid.NewString()
+	out <- model.StreamResponse{ChatID: chatID, MessageID: messageID, Phase: model.PhaseLoading}
+	startedAt := time.Now()
+	var timeToFirstToken time.Duration
+	cacheKey, llmStreamChan := s.startGeneration(ctx, llmReq)
+
+	showReasoning := resolveShowReasoning(nil, settings)
+	var fullResponse, rawResponse, fullReasoning strings.Builder
+	var splitter reasoningSplitter
+	var finalStats *llm.GenerationStats
+	var heldChunk *heldBackChunk
+	var completed bool
+	for chunk := range llmStreamChan {
+		if chunk.Error != "" {
+			out <- model.StreamResponse{ChatID: chatID, Error: chunk.Error}
+			return nil
+		}
+		if chunk.Content != "" && timeToFirstToken == 0 {
+			timeToFirstToken = time.Since(startedAt)
+			out <- model.StreamResponse{ChatID: chatID, Phase: model.PhaseGenerating}
+		}
+		rawResponse.WriteString(chunk.Content)
+		content, reasoning := splitter.Push(chunk.Content)
+		if chunk.Done {
+			restContent, restReasoning := splitter.Flush()
+			content, reasoning = content+restContent, reasoning+restReasoning
+			finalStats = withTimeToFirstToken(chunk.Stats, timeToFirstToken)
+			completed = true
+		}
+		fullResponse.WriteString(content)
+		fullReasoning.WriteString(reasoning)
+		response := model.StreamResponse{ChatID: chatID, Content: content, Done: chunk.Done}
+		if chunk.Done && (s.cfg.FilterResponses || llmReq.Format != "") {
+			heldChunk = &heldBackChunk{response: response, reasoning: reasoning}
+			continue
+		}
+		forwardChunk(out, response, reasoning, showReasoning)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	if !completed {
+		slog.Warn("Comparison stream ended early", "chat_id", chatID, "model", modelName)
+		out <- model.StreamResponse{ChatID: chatID, Error: "The model stopped before completing the answer"}
+		return nil
+	}
+	warnOnInvalidFormat(chatID, llmReq.Format, fullResponse.String(), out)
+	if !s.releaseFilteredResponse(ctx, chatID, fullResponse.String(), heldChunk, out, showReasoning) {
+		return nil
+	}
+	if cacheKey != "" {
+		s.responses.add(cachedResponse{key: cacheKey, content: rawResponse.String(), stats: finalStats})
+	}
+
+	parentID := history[len(history)-1].ID
+	return &model.Message{
+		ID:        messageID,
+		ParentID:  &parentID,
+		Role:      "assistant",
+		Content:   fullResponse.String(),
+		Model:     &modelName,
+		Timestamp: time.Now().UTC(),
+		Metadata:  buildAssistantMetadata(finalStats, fullReasoning.String(), llmReq.Options, ""),
+	}
+}
+
+// saveAlternatives stores the answers of a comparison as branches of the user
+// message in one transaction. The first answer replaces the active branch; the
+// others are stored inactive. Missing (nil) answers are skipped.
+func (s *ChatService) saveAlternatives(ctx context.Context, chatID, parentID string, answers []*model.Message) error {
+	var stored []*model.Message
+	for _, answer := range answers {
+		if answer != nil {
+			stored = append(stored, answer)
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback comparison transaction", "error", err)
+		}
+	}()
+
+	active, err := s.repo.GetActiveMessagesByChatIDTx(ctx, tx, chatID)
+	if err != nil {
+		return err
+	}
+	for _, m := range active {
+		if m.ParentID != nil && *m.ParentID == parentID {
+			if err := s.repo.DeactivateBranchTx(ctx, tx, m.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for i, answer := range stored {
+		if err := s.repo.AddMessageTx(ctx, tx, answer, chatID); err != nil {
+			return err
+		}
+		if i > 0 {
+			if err := s.repo.DeactivateBranchTx(ctx, tx, answer.ID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.repo.UpdateChatTimestampTx(ctx, tx, chatID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ContinueMessage continues the latest assistant message of a chat where it
+// stopped. The history is sent with the partial answer as the last message, which
+// the model extends, and the new content is appended to the same message instead
+// of creating a new one. Only the new content is streamed.
+func (s *ChatService) ContinueMessage(
+	ctx context.Context,
+	chatID string,
+	messageID string,
+	req *ContinueMessageRequest,
+	streamChan chan<- model.StreamResponse,
+) {
+	defer close(streamChan)
+
+	currentSettings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		slog.Error("Could not get settings for continuation", "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load application settings"}
+		return
+	}
+
+	release, ok := s.acquireGenerationSlot(ctx, chatID, streamChan)
+	if !ok {
+		return
+	}
+	defer release()
+
+	chat, err := s.repo.GetChat(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			streamChan <- model.StreamResponse{Error: "Chat not found"}
+			return
+		}
+		slog.Error("Error loading chat for continuation", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load chat"}
+		return
+	}
+
+	history, err := s.repo.GetActiveMessagesByChatID(ctx, chatID)
+	if err != nil {
+		slog.Error("Continue failed to get history", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not retrieve message history"}
+		return
+	}
+	// WHY: Continuing an earlier answer would make the messages after it refer to
+	// content they never saw.
+	if len(history) == 0 || history[len(history)-1].ID != messageID || history[len(history)-1].Role != "assistant" {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "Only the latest assistant message of a chat can be continued"}
+		return
+	}
+	original := history[len(history)-1]
+
+	// The model that wrote the answer continues it; answers stored without a
+	// model fall back to the chat's model and then to the settings.
+	var originalModel string
+	if original.Model != nil {
+		originalModel = *original.Model
+	}
+	modelToUse := resolveChatModel(originalModel, currentSettings, chat)
+	systemPromptToUse, err := s.renderSystemPromptFor(resolveSystemPrompt("", nil, currentSettings, chat), currentSettings, chat.UserID, modelToUse)
+	if err != nil {
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: err.Error()}
+		return
+	}
+
+	llmMessages, err := s.buildLLMMessages(ctx, nil, systemPromptToUse, history)
+	if err != nil {
+		slog.Error("Continue failed to load attachments", "chat_id", chatID, "error", err)
+		streamChan <- model.StreamResponse{Error: "Could not load message attachments"}
+		return
+	}
+	s.augmentWithDocuments(ctx, nil, chat.CollectionID, llmMessages)
+	llmReq := &llm.GenerateRequest{
+		Model:    modelToUse,
+		Messages: llmMessages,
+	}
+	options := mergeOptions(currentSettings.DefaultOptions, modelDefaultOptions(ctx, s.repo, modelToUse), req.Options)
+	llmReq.Options, llmReq.KeepAlive = resolveKeepAlive(options, currentSettings)
+	llmReq.Options, llmReq.Format = resolveFormat(llmReq.Options, "")
+
+	streamChan <- model.StreamResponse{ChatID: chatID, MessageID: messageID}
+
+	var newContent strings.Builder
+	var finalStats *llm.GenerationStats
+	_, llmStreamChan := s.startGeneration(ctx, llmReq)
+
+	showReasoning := resolveShowReasoning(req.ShowReasoning, currentSettings)
+	var splitter reasoningSplitter
+	var newReasoning strings.Builder
+	var heldChunk *heldBackChunk
+	var completed bool
+	for chunk := range llmStreamChan {
+		if chunk.Error != "" {
+			streamChan <- model.StreamResponse{ChatID: chatID, Error: chunk.Error}
+			return // The original message is kept unchanged.
+		}
+		content, reasoning := splitter.Push(chunk.Content)
+		if chunk.Done {
+			restContent, restReasoning := splitter.Flush()
+			content, reasoning = content+restContent, reasoning+restReasoning
+			finalStats = chunk.Stats
+			completed = true
+		}
+		newContent.WriteString(content)
+		newReasoning.WriteString(reasoning)
+		response := model.StreamResponse{ChatID: chatID, MessageID: messageID, Content: content, Done: chunk.Done}
+		if chunk.Done && (s.cfg.FilterResponses || llmReq.Format != "") {
+			heldChunk = &heldBackChunk{response: response, reasoning: reasoning}
+			continue
 		}
+		forwardChunk(streamChan, response, reasoning, showReasoning)
+	}
+
+	if err := ctx.Err(); err != nil {
+		slog.Info("Continuation was cancelled, keeping the original answer", "chat_id", chatID, "error", err)
+		return
+	}
+	if !completed {
+		slog.Warn("Continuation stream ended early, keeping the original answer", "chat_id", chatID)
+		streamChan <- model.StreamResponse{ChatID: chatID, Error: "The model stopped before completing the answer"}
+		return
+	}
+	// The format and the filter apply to the whole answer, not only the new part.
+	content := original.Content + newContent.String()
+	warnOnInvalidFormat(chatID, llmReq.Format, content, streamChan)
+	if !s.releaseFilteredResponse(ctx, chatID, content, heldChunk, streamChan, showReasoning) {
+		return
+	}
+
+	var originalMetadata assistantMetadata
+	if len(original.Metadata) > 0 {
+		if err := json.Unmarshal(original.Metadata, &originalMetadata); err != nil {
+			slog.Warn("Could not read the metadata of the continued message", "message_id", messageID, "error", err)
+		}
+	}
+	metadata := buildAssistantMetadata(finalStats, originalMetadata.Reasoning+newReasoning.String(), llmReq.Options, "")
+
+	// The message is updated within a transaction, so that the chat timestamp is
+	// only bumped together with the new content. It is only opened now, so that
+	// it is not held while the answer is streamed.
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		slog.Error("Continue failed to begin transaction", "error", err)
+		return
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.Error("Failed to rollback continuation transaction", "error", err)
+		}
+	}()
+	if err := s.repo.UpdateMessageContentTx(ctx, tx, messageID, content, metadata); err != nil {
+		slog.Error("Failed to save continued message", "chat_id", chatID, "error", err)
+		return
+	}
+	if err := s.repo.UpdateChatTimestampTx(ctx, tx, chatID); err != nil {
+		slog.Error("Failed to update chat timestamp after continuation", "chat_id", chatID, "error", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit continuation transaction", "error", err)
+		return
+	}
+}
+
+// startTitleJob generates the title of a chat in the background. The job is
+// not tied to the request, so that the title is still generated if the user
+// disconnects, but it is tracked, so that Close can wait for or cancel it.
+func (s *ChatService) startTitleJob(chatID, supportModel, promptTemplate, userQuery, assistantResponse string) {
+	s.titleJobs.Add(1)
+	go func() {
+		defer s.titleJobs.Done()
+		s.generateTitleWithRetry(s.titleCtx, chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+	}()
+}
+
+// generateTitleWithRetry runs `generateTitle` up to `titleGenerationAttempts`
+// times with exponential backoff, so that a temporarily unavailable support
+// model does not leave the chat with its placeholder title forever. The retries
+// use a simpler prompt, which small models get wrong less often. If every
+// attempt fails, the chat is titled with its truncated first message.
+func (s *ChatService) generateTitleWithRetry(ctx context.Context, chatID, supportModel, promptTemplate, userQuery, assistantResponse string) {
+	backoff := s.cfg.TitleRetryBackoff
+	for attempt := 1; ; attempt++ {
+		_, err := s.generateTitle(ctx, chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			slog.Info("Title generation was cancelled", "chat_id", chatID)
+			return
+		}
+		if attempt == titleGenerationAttempts {
+			slog.Warn("Giving up on title generation, using the first message", "chat_id", chatID, "attempts", attempt, "error", err)
+			s.setFallbackTitle(ctx, chatID, userQuery)
+			return
+		}
+		slog.Warn("Title generation failed, retrying", "chat_id", chatID, "attempt", attempt, "retry_in", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		promptTemplate = simpleTitlePromptTemplate
 	}
 }
 
-// generateTitle is a fire-and-forget background task to generate a chat title using an LLM.
-func (s *ChatService) generateTitle(ctx context.Context, chatID, supportModel, userQuery, assistantResponse string) {
+// setFallbackTitle titles a chat with its truncated first message, the title a
+// new chat starts with, e.g. for a chat that was created empty.
+func (s *ChatService) setFallbackTitle(ctx context.Context, chatID, userQuery string) {
+	title := sanitizeTitle(truncate(strings.TrimSpace(userQuery), s.cfg.TitlePreviewLength), s.cfg.TitleMaxLength)
+	if title == "" {
+		return
+	}
+	if err := s.repo.UpdateChatTitle(ctx, chatID, title); err != nil {
+		slog.Error("Could not set the fallback title", "chat_id", chatID, "error", err)
+	}
+}
+
+// generateTitle asks the support model for a short title, saves it on the chat
+// and returns it. The attempt is bounded by TitleTimeout, so that a hung support
+// model cannot hold the job forever; the call to the model is cancelled then.
+func (s *ChatService) generateTitle(ctx context.Context, chatID, supportModel, promptTemplate, userQuery, assistantResponse string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.TitleTimeout)
+	defer cancel()
+
+	newTitle, err := s.suggestTitle(ctx, chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.Warn("Title generation timed out", "chat_id", chatID, "model", supportModel, "timeout", s.cfg.TitleTimeout)
+		return "", fmt.Errorf("title generation timed out after %s: %w", s.cfg.TitleTimeout, ctx.Err())
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.UpdateChatTitle(ctx, chatID, newTitle); err != nil {
+		return "", fmt.Errorf("could not update chat title: %w", err)
+	}
+	slog.Info("Successfully updated title", "chat_id", chatID, "title", newTitle)
+	return newTitle, nil
+}
+
+// suggestTitle asks the support model for a short title of the exchange. The
+// prompt is rendered from promptTemplate; empty uses the default prompt.
+func (s *ChatService) suggestTitle(ctx context.Context, chatID, supportModel, promptTemplate, userQuery, assistantResponse string) (string, error) {
 	slog.Info("Generating title", "chat_id", chatID)
 
-	// A specific, structured prompt to coax the model into returning clean JSON.
-	prompt := fmt.Sprintf(
-		`Analyze the following conversation and generate a short, concise title (5 words max).
-		Respond with ONLY a JSON object in the format {"title": "your generated title"}. Do not add any other text or explanations.
-		
-		CONVERSATION:
-		User: %s
-		Assistant: %s`,
-		truncate(userQuery, 150),
-		truncate(assistantResponse, 200),
-	)
+	data := titlePromptData{User: truncate(userQuery, 150), Assistant: truncate(assistantResponse, 200)}
+	prompt, err := renderTitlePrompt(promptTemplate, data)
+	if err != nil {
+		// Templates are validated when saved, so this only happens for settings
+		// written by other means; a title is still better than none.
+		slog.Warn("Could not render title prompt template, using the default", "chat_id", chatID, "error", err)
+		prompt, _ = renderTitlePrompt("", data)
+	}
 
 	messages := []llm.Message{{Role: "user", Content: prompt}}
 	req := &llm.GenerateRequest{Model: supportModel, Messages: messages}
 	resp, err := s.llm.Generate(ctx, req)
 	if err != nil {
-		slog.Warn("Failed to generate title", "chat_id", chatID, "error", err)
-		return
+		return "", fmt.Errorf("could not generate title: %w", err)
 	}
 	slog.Debug("Raw title response from LLM", "chat_id", chatID, "response", resp.Response)
+	if resp.Stats != nil {
+		slog.Info("Title generation token usage", "chat_id", chatID, "model", supportModel,
+			"prompt_tokens", resp.Stats.PromptEvalCount, "eval_tokens", resp.Stats.EvalCount)
+	}
 
 	// The response from the LLM is often noisy; attempt to extract a valid JSON object.
 	jsonString := extractJSON(resp.Response)
@@ -534,13 +2335,158 @@ func (s *ChatService) generateTitle(ctx context.Context, chatID, supportModel, u
 		newTitle = cleanRawTitle(resp.Response)
 	}
 
-	if trimmedTitle := strings.TrimSpace(newTitle); trimmedTitle != "" {
-		if err := s.repo.UpdateChatTitle(ctx, chatID, trimmedTitle); err != nil {
-			slog.Warn("Failed to update chat with new title", "chat_id", chatID, "error", err)
-		} else {
-			slog.Info("Successfully updated title", "chat_id", chatID, "title", trimmedTitle)
+	newTitle = sanitizeTitle(newTitle, s.cfg.TitleMaxLength)
+	if newTitle == "" {
+		return "", errors.New("model returned an empty title")
+	}
+	return newTitle, nil
+}
+
+// titleQuotePairs lists the quote characters models like to wrap titles in.
+var titleQuotePairs = [][2]string{
+	{`"`, `"`}, {"'", "'"}, {"`", "`"}, {"“", "”"}, {"‘", "’"}, {"«", "»"},
+}
+
+// sanitizeTitle strips surrounding quotes from a generated title and caps it at
+// `maxLen` runes, cutting at the last word boundary where possible.
+func sanitizeTitle(title string, maxLen int) string {
+	title = strings.TrimSpace(title)
+	for stripped := true; stripped; {
+		stripped = false
+		for _, q := range titleQuotePairs {
+			if len(title) >= len(q[0])+len(q[1]) && strings.HasPrefix(title, q[0]) && strings.HasSuffix(title, q[1]) {
+				title = strings.TrimSpace(title[len(q[0]) : len(title)-len(q[1])])
+				stripped = true
+			}
+		}
+	}
+
+	if utf8.RuneCountInString(title) <= maxLen {
+		return title
+	}
+	capped := truncate(title, maxLen)
+	// Only cut back to a word boundary if the limit fell inside a word.
+	if next := []rune(title)[maxLen]; !unicode.IsSpace(next) {
+		if i := strings.LastIndexFunc(capped, unicode.IsSpace); i > 0 {
+			capped = capped[:i]
+		}
+	}
+	return strings.TrimRightFunc(capped, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",.;:-–—", r)
+	})
+}
+
+// augmentWithDocuments prepends the document chunks most relevant to the latest
+// user message of the LLM payload to that message, which is also the query. It
+// is the last message of a new answer, but is followed by the partial answer of
+// a continuation. The stored message is not changed. Retrieval failures are
+// logged and the message is sent without extra context. A caller that holds a
+// transaction passes it as tx, so that the collection is read within it; nil
+// reads outside of a transaction.
+func (s *ChatService) augmentWithDocuments(ctx context.Context, tx *sql.Tx, collectionID string, llmMessages []llm.Message) {
+	if s.documents == nil || collectionID == "" {
+		return
+	}
+	last := len(llmMessages) - 1
+	for last >= 0 && llmMessages[last].Role != "user" {
+		last--
+	}
+	if last < 0 {
+		return
+	}
+
+	var chunks []model.DocumentChunk
+	var err error
+	if tx != nil {
+		chunks, err = s.documents.RetrieveTx(ctx, tx, collectionID, llmMessages[last].Content)
+	} else {
+		chunks, err = s.documents.Retrieve(ctx, collectionID, llmMessages[last].Content)
+	}
+	if err != nil {
+		slog.Warn("Document retrieval failed, continuing without context", "collection_id", collectionID, "error", err)
+		return
+	}
+	if len(chunks) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Use the following excerpts from the user's documents to answer the question, if they are relevant.\n\n")
+	for i, c := range chunks {
+		fmt.Fprintf(&b, "[%d] %s\n\n", i+1, c.Content)
+	}
+	b.WriteString("Question: ")
+	b.WriteString(llmMessages[last].Content)
+	llmMessages[last].Content = b.String()
+	slog.Debug("Augmented prompt with document context", "collection_id", collectionID, "chunks", len(chunks))
+}
+
+// buildLLMMessages converts the stored history into the provider's message format,
+// prefixed with the system prompt. Images attached to history messages are loaded
+// and passed along base64-encoded; like in `augmentWithDocuments`, they are read
+// within tx unless it is nil.
+func (s *ChatService) buildLLMMessages(ctx context.Context, tx *sql.Tx, systemPrompt string, history []model.Message) ([]llm.Message, error) {
+	messageIDs := make([]string, len(history))
+	for i, msg := range history {
+		messageIDs[i] = msg.ID
+	}
+	var attachments []model.Attachment
+	var err error
+	if tx != nil {
+		attachments, err = s.repo.GetAttachmentsByMessageIDsTx(ctx, tx, messageIDs)
+	} else {
+		attachments, err = s.repo.GetAttachmentsByMessageIDs(ctx, messageIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	images := make(map[string][]string)
+	for _, a := range attachments {
+		images[a.MessageID] = append(images[a.MessageID], base64.StdEncoding.EncodeToString(a.Data))
+	}
+
+	llmMessages := []llm.Message{{Role: "system", Content: systemPrompt}}
+	for _, msg := range history {
+		llmMessages = append(llmMessages, llm.Message{Role: msg.Role, Content: msg.Content, Images: images[msg.ID]})
+	}
+	return llmMessages, nil
+}
+
+// decodeImages validates base64-encoded images from a request and turns them into
+// attachments. Images larger than the configured limit are rejected with `ErrValidation`.
+func (s *ChatService) decodeImages(images []string) ([]model.Attachment, error) {
+	attachments := make([]model.Attachment, 0, len(images))
+	for i, encoded := range images {
+		// Accept data URLs ("data:image/png;base64,...") as sent by browsers.
+		if strings.HasPrefix(encoded, "data:") {
+			if _, after, found := strings.Cut(encoded, ","); found {
+				encoded = after
+			}
+		}
+		// Check the size before decoding to avoid allocating huge buffers.
+		if int64(base64.StdEncoding.DecodedLen(len(encoded))) > s.cfg.MaxImageBytes+2 {
+			return nil, fmt.Errorf("%w: image %d exceeds the maximum size of %d bytes", app_errors.ErrValidation, i+1, s.cfg.MaxImageBytes)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: image %d is not valid base64", app_errors.ErrValidation, i+1)
+		}
+		if int64(len(data)) > s.cfg.MaxImageBytes {
+			return nil, fmt.Errorf("%w: image %d exceeds the maximum size of %d bytes", app_errors.ErrValidation, i+1, s.cfg.MaxImageBytes)
+		}
+		mimeType := http.DetectContentType(data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			return nil, fmt.Errorf("%w: image %d has unsupported content type %s", app_errors.ErrValidation, i+1, mimeType)
 		}
+		attachments = append(attachments, model.Attachment{
+			ID:        uuid.NewString(),
+			MimeType:  mimeType,
+			SizeBytes: int64(len(data)),
+			CreatedAt: time.Now().UTC(),
+			Data:      data,
+		})
 	}
+	return attachments, nil
 }
 
 // extractJSON is a best-effort attempt to find a JSON object within a string.
@@ -558,7 +2504,7 @@ func extractJSON(s string) string {
 
 // cleanRawTitle removes common noise (like markdown code blocks) from LLM responses.
 func cleanRawTitle(s string) string {
-	s = strings.Split(s, "<think>")[0] // Some models add reasoning in <think> tags.
+	s = stripReasoning(s) // Some models add reasoning in <think> tags.
 	s = strings.TrimPrefix(s, "```json")
 	s = strings.TrimSuffix(s, "```")
 	return strings.TrimSpace(s)
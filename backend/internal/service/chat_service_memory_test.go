@@ -0,0 +1,599 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/database"
+	"flow-ai/backend/internal/llm"
+	mock_llm "flow-ai/backend/internal/llm/mocks"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+	"flow-ai/backend/internal/service"
+)
+
+// fakeAnswer is the complete answer of the fake provider.
+const fakeAnswer = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod " +
+	"tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis " +
+	"nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis " +
+	"aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat " +
+	"nulla pariatur."
+
+// setupMemoryChatService creates a `ChatService` on the memory storage backend
+// and the given LLM provider.
+//
+// WHY: The mock-based tests pin down which repository calls are made; these
+// tests check what a conversation looks like afterwards, e.g. which branch is
+// active after a regeneration, without choreographing every call. The settings
+// are initialized with the fake provider, so that a mocked provider needs no
+// expectations for the model discovery.
+func setupMemoryChatService(t *testing.T, provider llm.LLMProvider, cfg service.ChatServiceConfig) (*service.ChatService, repository.Repository) {
+	storage, err := repository.OpenStorage(repository.StorageConfig{Backend: repository.BackendMemory})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.DB.Close() })
+
+	settingsService := service.NewSettingsService(storage.DB, llm.NewFakeProvider(llm.FakeConfig{}), nil)
+	_, err = settingsService.InitAndGet(context.Background(), "You are a helpful assistant.")
+	require.NoError(t, err)
+	documentService := service.NewDocumentService(storage.Repository, provider, service.DocumentServiceConfig{})
+	chatService := service.NewChatService(storage.Repository, provider, settingsService, documentService, cfg)
+	t.Cleanup(func() { chatService.Close(context.Background()) })
+	return chatService, storage.Repository
+}
+
+// collectStream runs a streaming call and returns its events.
+func collectStream(run func(ch chan<- model.StreamResponse)) []model.StreamResponse {
+	ch := make(chan model.StreamResponse)
+	go run(ch)
+	var events []model.StreamResponse
+	for event := range ch {
+		events = append(events, event)
+	}
+	return events
+}
+
+// streamedContent joins the content of the events and fails on an error event.
+func streamedContent(t *testing.T, events []model.StreamResponse) string {
+	t.Helper()
+	var content string
+	for _, event := range events {
+		require.Empty(t, event.Error)
+		content += event.Content
+	}
+	return content
+}
+
+// roles returns the roles of messages in order.
+func roles(messages []model.Message) []string {
+	var out []string
+	for _, m := range messages {
+		out = append(out, m.Role)
+	}
+	return out
+}
+
+// TestChatService_Memory_Conversation verifies that a new conversation and a
+// follow-up are stored as one active thread, and that the chat gets a title.
+func TestChatService_Memory_Conversation(t *testing.T) {
+	ctx := context.Background()
+	chatService, repo := setupMemoryChatService(t, llm.NewFakeProvider(llm.FakeConfig{}), service.ChatServiceConfig{})
+
+	// ACT: Start a conversation.
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{Content: "Hello there", Model: "fake-chat:latest"}, ch)
+	})
+
+	// ASSERT
+	assert.Equal(t, fakeAnswer, streamedContent(t, events))
+	chatID := events[0].ChatID
+	require.NotEmpty(t, chatID)
+	messages, err := repo.GetActiveMessagesByChatID(ctx, chatID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user", "assistant"}, roles(messages))
+	assert.Equal(t, "Hello there", messages[0].Content)
+	assert.Nil(t, messages[0].ParentID)
+	assert.Equal(t, fakeAnswer, messages[1].Content)
+	assert.Equal(t, messages[0].ID, *messages[1].ParentID)
+	assert.Equal(t, "fake-chat:latest", *messages[1].Model)
+
+	// ACT: Follow up in the same chat.
+	events = collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: chatID, Content: "Tell me more"}, ch)
+	})
+
+	// ASSERT: The follow-up continues the thread.
+	assert.Equal(t, fakeAnswer, streamedContent(t, events))
+	messages, err = repo.GetActiveMessagesByChatID(ctx, chatID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user", "assistant", "user", "assistant"}, roles(messages))
+	for i := 1; i < len(messages); i++ {
+		assert.Equal(t, messages[i-1].ID, *messages[i].ParentID)
+	}
+
+	// ASSERT: The title job replaced the preview title.
+	chatService.Close(ctx)
+	chat, err := repo.GetChat(ctx, chatID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "Hello there", chat.Title)
+	assert.NotEmpty(t, chat.Title)
+}
+
+// TestChatService_Memory_RegenerateMessage verifies that a regeneration makes the
+// new answer the active branch while the original one stays in the tree, and
+// that switching back restores it.
+func TestChatService_Memory_RegenerateMessage(t *testing.T) {
+	ctx := context.Background()
+	chatService, repo := setupMemoryChatService(t, llm.NewFakeProvider(llm.FakeConfig{}), service.ChatServiceConfig{})
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{Content: "Hello there", Model: "fake-chat:latest"}, ch)
+	})
+	streamedContent(t, events)
+	chatID := events[0].ChatID
+	original, err := repo.GetLastActiveMessage(ctx, chatID)
+	require.NoError(t, err)
+
+	// ACT
+	numPredict := 2
+	events = collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, chatID, original.ID, &service.RegenerateMessageRequest{Options: &llm.RequestOptions{NumPredict: &numPredict}}, ch)
+	})
+
+	// ASSERT: The first event announces the new answer.
+	assert.Equal(t, "Lorem ipsum", streamedContent(t, events))
+	newID := events[0].MessageID
+	require.NotEmpty(t, newID)
+	assert.Equal(t, original.ID, events[0].ReplacedMessageID)
+	messages, err := repo.GetActiveMessagesByChatID(ctx, chatID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user", "assistant"}, roles(messages))
+	assert.Equal(t, newID, messages[1].ID)
+	assert.Equal(t, "Lorem ipsum", messages[1].Content)
+	assert.Equal(t, messages[0].ID, *messages[1].ParentID)
+	all, err := repo.GetAllMessagesByChatID(ctx, chatID, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 3, "the original answer is kept as an inactive branch")
+
+	// ACT: Switch back to the original answer.
+	require.NoError(t, chatService.SwitchBranch(ctx, chatID, original.ID))
+
+	// ASSERT
+	messages, err = repo.GetActiveMessagesByChatID(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, original.ID, messages[1].ID)
+	assert.Equal(t, fakeAnswer, messages[1].Content)
+}
+
+// TestChatService_Memory_RegenerateMessage_Blocked verifies that a regenerated
+// answer that is blocked rolls the transaction back, so that the original answer
+// stays active.
+func TestChatService_Memory_RegenerateMessage_Blocked(t *testing.T) {
+	// ARRANGE: Every answer of the fake provider contains "lorem".
+	ctx := context.Background()
+	chatService, repo := setupMemoryChatService(t, llm.NewFakeProvider(llm.FakeConfig{}), service.ChatServiceConfig{
+		ContentFilter:   service.NewBannedSubstringFilter([]string{"lorem"}),
+		FilterResponses: true,
+	})
+	seedAnsweredChat(t, repo)
+
+	// ACT
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Model: "fake-chat:latest"}, ch)
+	})
+
+	// ASSERT
+	require.NotEmpty(t, events)
+	assert.NotEmpty(t, events[len(events)-1].Error)
+	messages, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "a1", messages[1].ID)
+	all, err := repo.GetAllMessagesByChatID(ctx, "chat1", nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2, "the blocked answer is not stored")
+}
+
+// TestChatService_Memory_RegenerateMessage_OtherChat verifies that an answer of
+// another chat is rejected instead of being replaced by an answer to this chat.
+func TestChatService_Memory_RegenerateMessage_OtherChat(t *testing.T) {
+	ctx := context.Background()
+	chatService, repo := setupMemoryChatService(t, llm.NewFakeProvider(llm.FakeConfig{}), service.ChatServiceConfig{})
+	seedAnsweredChat(t, repo)
+	now := time.Now().UTC()
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat2", Title: "Other", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "u2", Role: "user", Content: "Hi", Timestamp: now}, "chat2"))
+
+	// ACT: The answer of chat1 is regenerated as if it belonged to chat2.
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, "chat2", "a1", &service.RegenerateMessageRequest{Model: "fake-chat:latest"}, ch)
+	})
+
+	// ASSERT
+	require.Len(t, events, 1)
+	assert.Equal(t, "Original message not found or invalid", events[0].Error)
+	messages, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "a1", messages[1].ID)
+	other, err := repo.GetAllMessagesByChatID(ctx, "chat2", nil)
+	require.NoError(t, err)
+	assert.Len(t, other, 1)
+}
+
+// seedAnsweredChat stores a chat with a question and its answer.
+func seedAnsweredChat(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: now, UpdatedAt: now}))
+	question := &model.Message{ID: "u1", Role: "user", Content: "Hello there", Timestamp: now}
+	require.NoError(t, repo.AddMessage(ctx, question, "chat1"))
+	answer := &model.Message{ID: "a1", ParentID: &question.ID, Role: "assistant", Content: "Hi!", Timestamp: now}
+	require.NoError(t, repo.AddMessage(ctx, answer, "chat1"))
+}
+
+// TestChatService_ChatConfiguration verifies that regenerating or continuing an
+// answer uses the chat's own model and system prompt, like a new message does.
+func TestChatService_ChatConfiguration(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*service.ChatService, repository.Repository, *llm.GenerateRequest) {
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		now := time.Now().UTC()
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", Model: "chat-model", SystemPrompt: "You are a pirate.", CreatedAt: now, UpdatedAt: now}))
+		question := &model.Message{ID: "u1", Role: "user", Content: "Hello there", Timestamp: now}
+		require.NoError(t, repo.AddMessage(ctx, question, "chat1"))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &question.ID, Role: "assistant", Content: "Hi!", Timestamp: now}, "chat1"))
+
+		sent := &llm.GenerateRequest{}
+		provider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				*sent = *args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Arr!", Done: true}
+				close(outChan)
+			}).Once()
+		return chatService, repo, sent
+	}
+
+	t.Run("Regeneration", func(t *testing.T) {
+		chatService, _, sent := setup(t)
+
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{}, ch)
+		}))
+
+		assert.Equal(t, "chat-model", sent.Model)
+		assert.Equal(t, llm.Message{Role: "system", Content: "You are a pirate."}, sent.Messages[0])
+	})
+
+	t.Run("Regeneration with overrides", func(t *testing.T) {
+		chatService, _, sent := setup(t)
+
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Model: "other-model", SystemPrompt: "Be brief."}, ch)
+		}))
+
+		assert.Equal(t, "other-model", sent.Model)
+		assert.Equal(t, "Be brief.", sent.Messages[0].Content)
+	})
+
+	t.Run("Continuation", func(t *testing.T) {
+		chatService, repo, sent := setup(t)
+
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "a1", &service.ContinueMessageRequest{}, ch)
+		}))
+
+		// The seeded answer has no model, so the chat's model continues it.
+		assert.Equal(t, "chat-model", sent.Model)
+		assert.Equal(t, llm.Message{Role: "system", Content: "You are a pirate."}, sent.Messages[0])
+		answer, err := repo.GetMessageByID(ctx, "a1")
+		require.NoError(t, err)
+		assert.Equal(t, "Hi!Arr!", answer.Content)
+	})
+}
+
+// TestChatService_DocumentRetrieval_AllPaths verifies that the answers of a chat
+// bound to a collection see the retrieved documents when they are regenerated,
+// continued or compared, not only when they are first generated.
+func TestChatService_DocumentRetrieval_AllPaths(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*service.ChatService, *string) {
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		now := time.Now().UTC()
+		require.NoError(t, repo.CreateCollection(ctx, &model.Collection{ID: "col1", Name: "Docs", EmbeddingModel: "embed-model", CreatedAt: now}))
+		require.NoError(t, repo.AddDocument(ctx, &model.Document{ID: "d1", CollectionID: "col1", Name: "notes.txt", ChunkCount: 2, CreatedAt: now}, []model.DocumentChunk{
+			{ID: "c1", Index: 0, Content: "Unrelated text", Embedding: []float32{0, 1}},
+			{ID: "c2", Index: 1, Content: "The answer is 42", Embedding: []float32{1, 0}},
+		}))
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Docs", CollectionID: "col1", CreatedAt: now, UpdatedAt: now}))
+		u1 := "u1"
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: u1, Role: "user", Content: "What is the answer?", Timestamp: now}, "chat1"))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &u1, Role: "assistant", Content: "It is", Timestamp: now}, "chat1"))
+
+		provider.On("Embeddings", mock.Anything, mock.MatchedBy(func(r *llm.EmbeddingsRequest) bool {
+			return r.Input[0] == "What is the answer?"
+		})).Return(&llm.EmbeddingsResponse{Embeddings: [][]float32{{1, 0}}}, nil).Once()
+		var question string
+		provider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				req := args.Get(1).(*llm.GenerateRequest)
+				for _, m := range req.Messages {
+					if m.Role == "user" {
+						question = m.Content
+					}
+				}
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: " 42.", Done: true}
+				close(outChan)
+			}).Once()
+		return chatService, &question
+	}
+	assertAugmented := func(t *testing.T, question string) {
+		assert.Contains(t, question, "[1] The answer is 42")
+		assert.True(t, strings.HasSuffix(question, "Question: What is the answer?"))
+	}
+
+	t.Run("Regeneration", func(t *testing.T) {
+		chatService, question := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{}, ch)
+		}))
+		assertAugmented(t, *question)
+	})
+
+	t.Run("Continuation", func(t *testing.T) {
+		chatService, question := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "a1", &service.ContinueMessageRequest{}, ch)
+		}))
+		assertAugmented(t, *question)
+	})
+
+	t.Run("Comparison", func(t *testing.T) {
+		chatService, question := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.GenerateAlternatives(ctx, "chat1", "u1", []string{"model-a"}, ch)
+		}))
+		assertAugmented(t, *question)
+	})
+}
+
+// TestChatService_SystemPromptTemplate_UserID verifies that a regenerated,
+// continued or compared answer renders `{{.UserID}}` with the owner of the chat,
+// like a new message does.
+func TestChatService_SystemPromptTemplate_UserID(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*service.ChatService, *string) {
+		storage, err := repository.OpenStorage(repository.StorageConfig{Backend: repository.BackendMemory})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = storage.DB.Close() })
+		settingsService := service.NewSettingsService(storage.DB, llm.NewFakeProvider(llm.FakeConfig{}), nil)
+		settings, err := settingsService.InitAndGet(ctx, "You are a helpful assistant.")
+		require.NoError(t, err)
+		settings.EnablePromptTemplates = true
+		require.NoError(t, settingsService.Save(ctx, settings))
+
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService := service.NewChatService(storage.Repository, provider, settingsService, nil, service.ChatServiceConfig{})
+		t.Cleanup(func() { chatService.Close(ctx) })
+		now := time.Now().UTC()
+		require.NoError(t, storage.Repository.CreateChat(ctx, &model.Chat{ID: "chat1", UserID: "user-7", Title: "Test", SystemPrompt: "You talk to {{.UserID}}.", CreatedAt: now, UpdatedAt: now}))
+		u1 := "u1"
+		require.NoError(t, storage.Repository.AddMessage(ctx, &model.Message{ID: u1, Role: "user", Content: "Hello there", Timestamp: now}, "chat1"))
+		require.NoError(t, storage.Repository.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &u1, Role: "assistant", Content: "Hi!", Timestamp: now}, "chat1"))
+
+		var systemPrompt string
+		provider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				systemPrompt = args.Get(1).(*llm.GenerateRequest).Messages[0].Content
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hello", Done: true}
+				close(outChan)
+			}).Once()
+		return chatService, &systemPrompt
+	}
+
+	t.Run("Regeneration", func(t *testing.T) {
+		chatService, systemPrompt := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{}, ch)
+		}))
+		assert.Equal(t, "You talk to user-7.", *systemPrompt)
+	})
+
+	t.Run("Continuation", func(t *testing.T) {
+		chatService, systemPrompt := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "a1", &service.ContinueMessageRequest{}, ch)
+		}))
+		assert.Equal(t, "You talk to user-7.", *systemPrompt)
+	})
+
+	t.Run("Comparison", func(t *testing.T) {
+		chatService, systemPrompt := setup(t)
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.GenerateAlternatives(ctx, "chat1", "u1", []string{"model-a"}, ch)
+		}))
+		assert.Equal(t, "You talk to user-7.", *systemPrompt)
+	})
+}
+
+// expectStream makes the mocked provider stream the given chunks for a model.
+func expectStream(provider *mock_llm.MockLLMProvider, modelName string, chunks ...llm.StreamResponse) {
+	provider.On("GenerateStream", mock.Anything, mock.MatchedBy(func(r *llm.GenerateRequest) bool {
+		return r.Model == modelName
+	}), mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			outChan := args.Get(2).(chan<- llm.StreamResponse)
+			for _, chunk := range chunks {
+				outChan <- chunk
+			}
+			close(outChan)
+		}).Once()
+}
+
+// TestChatService_GenerateAlternatives verifies that each model's answer is
+// streamed tagged with the model and stored as a branch of the user message,
+// with the first model's answer active.
+func TestChatService_GenerateAlternatives(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores one branch per model", func(t *testing.T) {
+		// ARRANGE
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		seedAnsweredChat(t, repo)
+		expectStream(provider, "model-a", llm.StreamResponse{Content: "Answer "}, llm.StreamResponse{Content: "A", Done: true})
+		expectStream(provider, "model-b", llm.StreamResponse{Content: "Answer B", Done: true})
+
+		// ACT
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.GenerateAlternatives(ctx, "chat1", "u1", []string{"model-a", "model-b"}, ch)
+		})
+
+		// ASSERT: The events of each model are tagged and announce its message ID.
+		content := map[string]string{}
+		messageIDs := map[string]string{}
+		for _, event := range events {
+			require.Empty(t, event.Error)
+			require.NotEmpty(t, event.Model)
+			content[event.Model] += event.Content
+			if event.MessageID != "" {
+				messageIDs[event.Model] = event.MessageID
+			}
+		}
+		assert.Equal(t, map[string]string{"model-a": "Answer A", "model-b": "Answer B"}, content)
+
+		// ASSERT: Both answers are branches of the question; the first one is active.
+		all, err := repo.GetAllMessagesByChatID(ctx, "chat1", nil)
+		require.NoError(t, err)
+		require.Len(t, all, 4)
+		for _, m := range all[2:] {
+			assert.Equal(t, "u1", *m.ParentID)
+			assert.Equal(t, messageIDs[*m.Model], m.ID)
+			assert.Equal(t, content[*m.Model], m.Content)
+		}
+		active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		assert.Equal(t, messageIDs["model-a"], active[1].ID)
+		original, err := repo.GetMessageByID(ctx, "a1")
+		require.NoError(t, err)
+		assert.False(t, original.IsActive, "the previous answer stays as an inactive branch")
+	})
+
+	t.Run("A failed model does not stop the others", func(t *testing.T) {
+		// ARRANGE
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		seedAnsweredChat(t, repo)
+		expectStream(provider, "model-a", llm.StreamResponse{Error: "model crashed", Done: true})
+		expectStream(provider, "model-b", llm.StreamResponse{Content: "Answer B", Done: true})
+
+		// ACT
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.GenerateAlternatives(ctx, "chat1", "u1", []string{"model-a", "model-b"}, ch)
+		})
+
+		// ASSERT
+		var failed []string
+		for _, event := range events {
+			if event.Error != "" {
+				failed = append(failed, event.Model)
+			}
+		}
+		assert.Equal(t, []string{"model-a"}, failed)
+		active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		assert.Equal(t, "Answer B", active[1].Content)
+		assert.Equal(t, "model-b", *active[1].Model)
+	})
+
+	t.Run("Rejects a message that is not a user message", func(t *testing.T) {
+		// ARRANGE
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		seedAnsweredChat(t, repo)
+
+		// ACT
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.GenerateAlternatives(ctx, "chat1", "a1", []string{"model-a", "model-b"}, ch)
+		})
+
+		// ASSERT
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0].Error, "not a user message")
+		provider.AssertNotCalled(t, "GenerateStream", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatService_SingleConnectionPool verifies that the operations that hold a
+// transaction read within it, so that they also work with a SQLite pool of a
+// single connection instead of waiting for a second one forever.
+func TestChatService_SingleConnectionPool(t *testing.T) {
+	storage, err := repository.OpenStorage(repository.StorageConfig{
+		DatabasePath: filepath.Join(t.TempDir(), "flow.db"),
+		Database:     database.Config{MaxOpenConns: 1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.DB.Close() })
+	repo := storage.Repository
+
+	provider := mock_llm.NewMockLLMProvider(t)
+	settingsService := service.NewSettingsService(storage.DB, llm.NewFakeProvider(llm.FakeConfig{}), nil)
+	_, err = settingsService.InitAndGet(context.Background(), "You are a helpful assistant.")
+	require.NoError(t, err)
+	documentService := service.NewDocumentService(repo, provider, service.DocumentServiceConfig{})
+	chatService := service.NewChatService(repo, provider, settingsService, documentService, service.ChatServiceConfig{})
+	t.Cleanup(func() { chatService.Close(context.Background()) })
+
+	// A deadlock fails the test instead of hanging it.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	require.NoError(t, repo.CreateCollection(ctx, &model.Collection{ID: "docs", Name: "Docs", EmbeddingModel: "embedder", CreatedAt: now}))
+	require.NoError(t, repo.AddDocument(ctx,
+		&model.Document{ID: "doc1", CollectionID: "docs", Name: "notes.txt", ChunkCount: 1, CreatedAt: now},
+		[]model.DocumentChunk{{ID: "chunk1", DocumentID: "doc1", Content: "The sky is green.", Embedding: []float32{1, 0}}}))
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CollectionID: "docs", CreatedAt: now, UpdatedAt: now}))
+	question := &model.Message{ID: "u1", Role: "user", Content: "What color is the sky?", Timestamp: now}
+	require.NoError(t, repo.AddMessage(ctx, question, "chat1"))
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &question.ID, Role: "assistant", Content: "Blue.", Timestamp: now}, "chat1"))
+
+	provider.On("Embeddings", mock.Anything, mock.Anything).
+		Return(&llm.EmbeddingsResponse{Embeddings: [][]float32{{1, 0}}}, nil)
+	expectStream(provider, "qwen3:8b", llm.StreamResponse{Content: "Green.", Done: true})
+
+	// ACT & ASSERT: Regenerating reads the answer, the history, its attachments
+	// and the documents while it holds its transaction.
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Model: "qwen3:8b"}, ch)
+	})
+	assert.Equal(t, "Green.", streamedContent(t, events))
+
+	// Switching back reads the target message and cloning reads the attachments
+	// within their transactions.
+	require.NoError(t, chatService.SwitchBranch(ctx, "chat1", "a1"))
+	active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", active[len(active)-1].ID)
+	clone, err := chatService.CloneChat(ctx, "chat1")
+	require.NoError(t, err)
+	cloned, err := repo.GetActiveMessagesByChatID(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Len(t, cloned, 2)
+}
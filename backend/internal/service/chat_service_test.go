@@ -3,14 +3,25 @@ package service_test
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"flow-ai/backend/internal/database"
+	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/llm"
 	mock_llm "flow-ai/backend/internal/llm/mocks"
 	"flow-ai/backend/internal/model"
@@ -28,6 +39,9 @@ type Mocks struct {
 	llm    *mock_llm.MockLLMProvider
 	db     *sql.DB
 	mockDB sqlmock.Sqlmock
+	// modelDefaults makes every model have no default options. Tests of the
+	// defaults call its `Unset` before setting their own expectation.
+	modelDefaults *mock.Call
 }
 
 // setupChatService is a test fixture that creates a `ChatService` instance
@@ -43,6 +57,12 @@ type Mocks struct {
 //     `ChatService` and a real `SettingsService` while still controlling the
 //     underlying database calls.
 func setupChatService(t *testing.T) (*service.ChatService, Mocks) {
+	return setupChatServiceWithConfig(t, service.ChatServiceConfig{})
+}
+
+// setupChatServiceWithConfig is like `setupChatService` but allows overriding the
+// static service configuration (e.g. the title preview length).
+func setupChatServiceWithConfig(t *testing.T, cfg service.ChatServiceConfig) (*service.ChatService, Mocks) {
 	db, mockDB, err := sqlmock.New()
 	require.NoError(t, err)
 
@@ -52,9 +72,11 @@ func setupChatService(t *testing.T) (*service.ChatService, Mocks) {
 		db:     db,
 		mockDB: mockDB,
 	}
+	mocks.modelDefaults = mocks.repo.On("GetModelDefaults", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound).Maybe()
 
-	settingsService := service.NewSettingsService(mocks.db, mocks.llm)
-	chatService := service.NewChatService(mocks.repo, mocks.llm, settingsService)
+	settingsService := service.NewSettingsService(mocks.db, mocks.llm, nil)
+	documentService := service.NewDocumentService(mocks.repo, mocks.llm, service.DocumentServiceConfig{})
+	chatService := service.NewChatService(mocks.repo, mocks.llm, settingsService, documentService, cfg)
 
 	return chatService, mocks
 }
@@ -104,10 +126,11 @@ func TestChatService_ListChats(t *testing.T) {
 	defer func() { _ = mocks.db.Close() }()
 
 	expectedChats := []*model.Chat{{ID: "chat1"}}
-	mocks.repo.On("GetChats", ctx).Return(expectedChats, nil).Once()
+	opts := model.ChatListOptions{SortBy: "title", Order: "asc"}
+	mocks.repo.On("GetChats", ctx, opts).Return(expectedChats, nil).Once()
 
 	// ACT
-	chats, err := chatService.ListChats(ctx)
+	chats, err := chatService.ListChats(ctx, opts)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -121,7 +144,7 @@ func TestChatService_GetFullChat(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		// GOAL: Verify that the service correctly calls both `GetChat` and
-		// `GetActiveMessagesByChatID` and assembles the results.
+		// `GetActiveMessagesPage` and assembles the results.
 		// ARRANGE
 		chatService, mocks := setupChatService(t)
 		defer func() { _ = mocks.db.Close() }()
@@ -130,7 +153,8 @@ func TestChatService_GetFullChat(t *testing.T) {
 		messages := []model.Message{{ID: "msg1"}}
 
 		mocks.repo.On("GetChat", ctx, chatID).Return(chat, nil).Once()
-		mocks.repo.On("GetActiveMessagesByChatID", ctx, chatID).Return(messages, nil).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, chatID, 51, "").Return(messages, nil).Once()
+		mocks.repo.On("GetAttachmentRefsByChatID", ctx, chatID).Return(nil, nil).Once()
 
 		// ACT
 		fullChat, err := chatService.GetFullChat(ctx, chatID)
@@ -139,6 +163,51 @@ func TestChatService_GetFullChat(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, chat, &fullChat.Chat)
 		assert.Equal(t, messages, fullChat.Messages)
+		assert.False(t, fullChat.HasMore)
+	})
+
+	t.Run("Success - Only the latest page is returned, oldest first", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: The repository returns one message more than the page size, newest first.
+		newestFirst := make([]model.Message, 51)
+		for i := range newestFirst {
+			newestFirst[i] = model.Message{ID: fmt.Sprintf("msg%d", 100-i)}
+		}
+		mocks.repo.On("GetChat", ctx, chatID).Return(&model.Chat{ID: chatID}, nil).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, chatID, 51, "").Return(newestFirst, nil).Once()
+		mocks.repo.On("GetAttachmentRefsByChatID", ctx, chatID).Return(nil, nil).Once()
+
+		// ACT
+		fullChat, err := chatService.GetFullChat(ctx, chatID)
+
+		// ASSERT: The extra message is dropped and signals that older messages exist.
+		require.NoError(t, err)
+		require.Len(t, fullChat.Messages, 50)
+		assert.True(t, fullChat.HasMore)
+		assert.Equal(t, "msg51", fullChat.Messages[0].ID)
+		assert.Equal(t, "msg100", fullChat.Messages[49].ID)
+	})
+
+	t.Run("Success - Attachment references are included", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.repo.On("GetChat", ctx, chatID).Return(&model.Chat{ID: chatID}, nil).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, chatID, 51, "").Return([]model.Message{{ID: "msg2"}, {ID: "msg1"}}, nil).Once()
+		mocks.repo.On("GetAttachmentRefsByChatID", ctx, chatID).
+			Return([]model.Attachment{{ID: "att1", MessageID: "msg2", MimeType: "image/png", SizeBytes: 10}}, nil).Once()
+
+		// ACT
+		fullChat, err := chatService.GetFullChat(ctx, chatID)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Empty(t, fullChat.Messages[0].Attachments)
+		require.Len(t, fullChat.Messages[1].Attachments, 1)
+		assert.Equal(t, "att1", fullChat.Messages[1].Attachments[0].ID)
 	})
 
 	t.Run("Failure - GetChat returns error", func(t *testing.T) {
@@ -147,25 +216,234 @@ func TestChatService_GetFullChat(t *testing.T) {
 		defer func() { _ = mocks.db.Close() }()
 
 		mocks.repo.On("GetChat", ctx, chatID).Return(nil, errors.New("db error")).Once()
-		// We should NOT expect a call to `GetActiveMessagesByChatID` if the first call fails.
+		// We should NOT expect a call to `GetActiveMessagesPage` if the first call fails.
 
 		_, err := chatService.GetFullChat(ctx, chatID)
 		assert.Error(t, err)
 	})
 
-	t.Run("Failure - GetActiveMessagesByChatID returns error", func(t *testing.T) {
+	t.Run("Failure - GetActiveMessagesPage returns error", func(t *testing.T) {
 		// GOAL: Verify that an error from the second repository call is also handled.
 		chatService, mocks := setupChatService(t)
 		defer func() { _ = mocks.db.Close() }()
 
 		mocks.repo.On("GetChat", ctx, chatID).Return(&model.Chat{}, nil).Once()
-		mocks.repo.On("GetActiveMessagesByChatID", ctx, chatID).Return(nil, errors.New("db error")).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, chatID, 51, "").Return(nil, errors.New("db error")).Once()
 
 		_, err := chatService.GetFullChat(ctx, chatID)
 		assert.Error(t, err)
 	})
 }
 
+// TestChatService_GetChatMessages tests paging through a chat's messages.
+func TestChatService_GetChatMessages(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Success - Cursor and limit are passed through", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, "chat1", 3, "m4").
+			Return([]model.Message{{ID: "m3"}, {ID: "m2"}}, nil).Once()
+		mocks.repo.On("GetAttachmentRefsByChatID", ctx, "chat1").Return(nil, nil).Once()
+
+		// ACT
+		page, err := chatService.GetChatMessages(ctx, "chat1", 2, "m4")
+
+		// ASSERT: Newest first, and no further page.
+		require.NoError(t, err)
+		assert.Equal(t, []model.Message{{ID: "m3"}, {ID: "m2"}}, page.Messages)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("Failure - Limit too large", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		_, err := chatService.GetChatMessages(ctx, "chat1", 1000, "")
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetChat", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.GetChatMessages(ctx, "missing", 0, "")
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+
+	t.Run("Failure - Unknown cursor", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesPage", ctx, "chat1", 51, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.GetChatMessages(ctx, "chat1", 0, "missing")
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+}
+
+// TestChatService_SetChatTags verifies tag validation and normalization.
+func TestChatService_SetChatTags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Duplicates removed and sorted", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("SetChatTags", ctx, "chat1", []string{"golang", "work"}).Return(nil).Once()
+
+		tags, err := chatService.SetChatTags(ctx, "chat1", []string{"work", "golang", "work"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"golang", "work"}, tags)
+	})
+
+	for _, tag := range []string{"Work", "two words", "", "-leading", strings.Repeat("a", 33)} {
+		t.Run("Failure - Invalid tag "+tag, func(t *testing.T) {
+			chatService, mocks := setupChatService(t)
+			defer func() { _ = mocks.db.Close() }()
+
+			_, err := chatService.SetChatTags(ctx, "chat1", []string{tag})
+			assert.ErrorIs(t, err, app_errors.ErrValidation)
+		})
+	}
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("SetChatTags", ctx, "missing", []string{"work"}).Return(repository.ErrNotFound).Once()
+
+		_, err := chatService.SetChatTags(ctx, "missing", []string{"work"})
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+
+	t.Run("Failure - Invalid tag filter when listing", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		_, err := chatService.ListChats(ctx, model.ChatListOptions{Tag: "Not Valid"})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+}
+
+// TestChatService_GetChatTree verifies how the messages of a chat are nested,
+// including branches deactivated by regeneration.
+func TestChatService_GetChatTree(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ptr := func(s string) *string { return &s }
+	// A user message that was answered three times: two regenerations left
+	// inactive answers behind, followed by a new user turn on the active answer.
+	messages := []model.Message{
+		{ID: "u1", Role: "user", Timestamp: base, IsActive: true},
+		{ID: "a1", ParentID: ptr("u1"), Role: "assistant", Timestamp: base.Add(1 * time.Minute)},
+		{ID: "a2", ParentID: ptr("u1"), Role: "assistant", Timestamp: base.Add(2 * time.Minute)},
+		{ID: "a3", ParentID: ptr("u1"), Role: "assistant", Timestamp: base.Add(3 * time.Minute), IsActive: true},
+		{ID: "u2", ParentID: ptr("a3"), Role: "user", Timestamp: base.Add(4 * time.Minute), IsActive: true},
+	}
+
+	ids := func(nodes []model.MessageNode) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.ID)
+		}
+		return out
+	}
+
+	t.Run("Success - Nested tree after regenerations", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetAllMessagesByChatID", ctx, "chat1", (*time.Time)(nil)).Return(messages, nil).Once()
+
+		// ACT
+		tree, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{Nested: true})
+
+		// ASSERT: All three answers hang off the user message, in creation order.
+		require.NoError(t, err)
+		assert.Nil(t, tree.Messages)
+		require.Equal(t, []string{"u1"}, ids(tree.Roots))
+		answers := tree.Roots[0].Children
+		assert.Equal(t, []string{"a1", "a2", "a3"}, ids(answers))
+		assert.False(t, answers[0].IsActive)
+		assert.False(t, answers[1].IsActive)
+		assert.Empty(t, answers[0].Children)
+		assert.Equal(t, []string{"u2"}, ids(answers[2].Children))
+	})
+
+	t.Run("Success - Depth cuts off deeper levels", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Twice()
+		mocks.repo.On("GetAllMessagesByChatID", ctx, "chat1", (*time.Time)(nil)).Return(messages, nil).Twice()
+
+		nested, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{Nested: true, Depth: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a1", "a2", "a3"}, ids(nested.Roots[0].Children))
+		assert.Empty(t, nested.Roots[0].Children[2].Children)
+
+		// WHY: The flat list applies the same cut, in depth-first order.
+		flat, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{Depth: 2})
+		require.NoError(t, err)
+		assert.Equal(t, messages[:4], flat.Messages)
+	})
+
+	t.Run("Success - Messages with a filtered-out parent become roots", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		since := base.Add(3 * time.Minute)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetAllMessagesByChatID", ctx, "chat1", &since).Return(messages[3:], nil).Once()
+
+		tree, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{Nested: true, Since: &since})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a3"}, ids(tree.Roots))
+		assert.Equal(t, []string{"u2"}, ids(tree.Roots[0].Children))
+	})
+
+	t.Run("Success - Flat list by default", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetAllMessagesByChatID", ctx, "chat1", (*time.Time)(nil)).Return(messages, nil).Once()
+
+		tree, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, messages, tree.Messages)
+		assert.Nil(t, tree.Roots)
+	})
+
+	t.Run("Success - An empty chat has an empty list", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetAllMessagesByChatID", ctx, "chat1", (*time.Time)(nil)).Return(nil, nil).Once()
+
+		tree, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{})
+		require.NoError(t, err)
+		body, err := json.Marshal(tree)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"messages":[]`)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetChat", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.GetChatTree(ctx, "missing", model.ChatTreeOptions{})
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
 // TestChatService_HandleNewMessage_NewChat focuses on the complex logic for creating a new chat.
 func TestChatService_HandleNewMessage_NewChat(t *testing.T) {
 	ctx := context.Background()
@@ -193,8 +471,8 @@ func TestChatService_HandleNewMessage_NewChat(t *testing.T) {
 		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), mock.AnythingOfType("string")).Return(nil).Twice()
 		// 5. Message history is fetched for the LLM context.
 		mocks.repo.On("GetActiveMessagesByChatID", ctx, mock.AnythingOfType("string")).Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
 		// 6. The final LLM context is saved to the assistant's message.
-		mocks.repo.On("UpdateMessageContext", ctx, mock.Anything, mock.Anything).Return(nil).Once()
 		// 7. A title is generated and updated in the background (optional calls).
 		mocks.repo.On("UpdateChatTitle", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
 		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "Test"}`}, nil).Maybe()
@@ -205,7 +483,7 @@ func TestChatService_HandleNewMessage_NewChat(t *testing.T) {
 			Run(func(args mock.Arguments) {
 				outChan := args.Get(2).(chan<- llm.StreamResponse)
 				outChan <- llm.StreamResponse{Content: "response"}
-				outChan <- llm.StreamResponse{Done: true, Context: []byte(`"context"`)}
+				outChan <- llm.StreamResponse{Done: true}
 				close(outChan)
 			}).Once()
 
@@ -213,8 +491,11 @@ func TestChatService_HandleNewMessage_NewChat(t *testing.T) {
 		chatService.HandleNewMessage(ctx, req, streamChan)
 
 		// ASSERT: Check the output channel and verify all mock expectations were met.
-		assert.Len(t, streamChan, 2)
-		<-streamChan
+		// The phase events precede the content.
+		assert.Len(t, streamChan, 4)
+		assert.Equal(t, model.PhaseLoading, (<-streamChan).Phase)
+		assert.Equal(t, model.PhaseGenerating, (<-streamChan).Phase)
+		assert.Equal(t, "response", (<-streamChan).Content)
 		finalChunk := <-streamChan
 		assert.True(t, finalChunk.Done)
 
@@ -263,3 +544,2549 @@ func TestChatService_HandleNewMessage_NewChat(t *testing.T) {
 		require.NoError(t, mocks.mockDB.ExpectationsWereMet())
 	})
 }
+
+// TestChatService_CreateChat verifies the defaults applied when creating an empty chat.
+func TestChatService_CreateChat(t *testing.T) {
+	ctx := context.Background()
+	settingsRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("system_prompt", "system").
+			AddRow("main_model", "default-model").
+			AddRow("support_model", "support-model")
+	}
+
+	t.Run("Success - Defaults are applied", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(settingsRows())
+		mocks.repo.On("CreateChat", ctx, mock.MatchedBy(func(c *model.Chat) bool {
+			return c.ID != "" && c.Title == "New Chat" && c.Model == "default-model" && c.SystemPrompt == ""
+		})).Return(nil).Once()
+
+		// ACT
+		chat, err := chatService.CreateChat(ctx, &service.CreateChatRequest{})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "New Chat", chat.Title)
+		require.NoError(t, mocks.mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Success - Explicit options are stored", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(settingsRows())
+		mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "custom"}}}, nil).Once()
+		mocks.repo.On("CreateChat", ctx, mock.MatchedBy(func(c *model.Chat) bool {
+			return c.Title == "Review" && c.Model == "custom" && c.SystemPrompt == "Be strict."
+		})).Return(nil).Once()
+
+		// ACT
+		chat, err := chatService.CreateChat(ctx, &service.CreateChatRequest{Title: "Review", Model: "custom", SystemPrompt: "Be strict."})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "custom", chat.Model)
+	})
+
+	t.Run("Success - Model alias is validated and stored as given", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(settingsRows().AddRow("model_aliases", `{"fast": "custom:1b"}`))
+		mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "custom:1b"}}}, nil).Once()
+		mocks.repo.On("CreateChat", ctx, mock.MatchedBy(func(c *model.Chat) bool {
+			return c.Model == "fast"
+		})).Return(nil).Once()
+
+		// ACT
+		chat, err := chatService.CreateChat(ctx, &service.CreateChatRequest{Model: "fast"})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "fast", chat.Model)
+	})
+
+	t.Run("Failure - Model is not available", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(settingsRows())
+		mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "other"}}}, nil).Once()
+
+		// ACT
+		_, err := chatService.CreateChat(ctx, &service.CreateChatRequest{Model: "missing"})
+
+		// ASSERT: The repository must not be touched.
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		mocks.repo.AssertNotCalled(t, "CreateChat", mock.Anything, mock.Anything)
+	})
+}
+
+// TestChatService_HandleNewMessage_PreCreatedChat verifies that the first message
+// of an empty, pre-created chat uses the chat's own model and system prompt.
+func TestChatService_HandleNewMessage_PreCreatedChat(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Chat configuration is used", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 5)
+
+		// ARRANGE
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("system_prompt", "global prompt").
+			AddRow("main_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		chat := &model.Chat{ID: "chat1", Title: "New Chat", Model: "chat-model", SystemPrompt: "chat prompt"}
+		mocks.repo.On("GetChat", ctx, "chat1").Return(chat, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "Test"}`}, nil).Maybe()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.MatchedBy(func(r *llm.GenerateRequest) bool {
+			return r.Model == "chat-model" && r.Messages[0].Content == "chat prompt"
+		}), mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello"}, streamChan)
+
+		// ASSERT
+		assert.Equal(t, model.PhaseLoading, (<-streamChan).Phase)
+		finalChunk := <-streamChan
+		assert.True(t, finalChunk.Done)
+		assert.Empty(t, finalChunk.Error)
+		mocks.repo.AssertNotCalled(t, "CreateChat", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success - Model alias is resolved", func(t *testing.T) {
+		// GOAL: A chat created with an alias follows it, so the model is resolved per message.
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 5)
+
+		// ARRANGE
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("model_aliases", `{"smart": "qwen3:14b"}`)
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		chat := &model.Chat{ID: "chat1", Title: "New Chat", Model: "smart"}
+		mocks.repo.On("GetChat", ctx, "chat1").Return(chat, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "Test"}`}, nil).Maybe()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.MatchedBy(func(r *llm.GenerateRequest) bool {
+			return r.Model == "qwen3:14b"
+		}), mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello"}, streamChan)
+
+		// ASSERT
+		assert.Equal(t, model.PhaseLoading, (<-streamChan).Phase)
+		finalChunk := <-streamChan
+		assert.True(t, finalChunk.Done)
+		assert.Empty(t, finalChunk.Error)
+	})
+
+	t.Run("Failure - Chat does not exist", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 1)
+
+		// ARRANGE
+		rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "missing", Content: "Hello"}, streamChan)
+
+		// ASSERT
+		errChunk := <-streamChan
+		assert.Equal(t, "Chat not found", errChunk.Error)
+	})
+}
+
+// TestChatService_HandleNewMessage_FollowUp verifies that a follow-up turn sends
+// the whole active history, which is what carries the conversation.
+func TestChatService_HandleNewMessage_FollowUp(t *testing.T) {
+	ctx := context.Background()
+	chatService, mocks := setupChatService(t)
+	defer func() { _ = mocks.db.Close() }()
+	streamChan := make(chan model.StreamResponse, 5)
+
+	// ARRANGE
+	rows := sqlmock.NewRows([]string{"key", "value"}).
+		AddRow("system_prompt", "Be brief.").
+		AddRow("main_model", "m1")
+	mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+	mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Names"}, nil).Once()
+	mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "a1"}, nil).Once()
+	mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+	mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{
+		{ID: "u1", Role: "user", Content: "My name is Ada."},
+		{ID: "a1", Role: "assistant", Content: "Hello, Ada!"},
+		{ID: "u2", Role: "user", Content: "What is my name?"},
+	}, nil).Once()
+	mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+	var sent *llm.GenerateRequest
+	mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*llm.GenerateRequest)
+			outChan := args.Get(2).(chan<- llm.StreamResponse)
+			outChan <- llm.StreamResponse{Content: "Ada.", Done: true}
+			close(outChan)
+		}).Once()
+
+	// ACT
+	chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "What is my name?"}, streamChan)
+
+	// ASSERT
+	require.NotNil(t, sent)
+	assert.Equal(t, []llm.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Hello, Ada!"},
+		{Role: "user", Content: "What is my name?"},
+	}, sent.Messages)
+	mocks.llm.AssertExpectations(t)
+}
+
+// TestChatService_HandleNewMessage_DryRun verifies that a dry run streams the
+// answer without writing anything: no chat, no messages and no title.
+func TestChatService_HandleNewMessage_DryRun(t *testing.T) {
+	ctx := context.Background()
+	settingsRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("system_prompt", "Be brief.").
+			AddRow("main_model", "m1").
+			AddRow("support_model", "s1")
+	}
+	// answer streams a fixed answer and records the request.
+	answer := func(mocks Mocks, sent **llm.GenerateRequest) {
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				*sent = args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Ada.", Done: true}
+				close(outChan)
+			}).Once()
+	}
+	// assertNoWrites fails if anything was persisted or a title was generated.
+	// WHY: The mocks are strict, so an unexpected write would already fail; the
+	// explicit checks document what a dry run must not do.
+	assertNoWrites := func(t *testing.T, chatService *service.ChatService, mocks Mocks) {
+		chatService.Close(ctx)
+		mocks.repo.AssertNotCalled(t, "CreateChat", mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "AddMessage", mock.Anything, mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+		mocks.llm.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything)
+		require.NoError(t, mocks.mockDB.ExpectationsWereMet())
+	}
+
+	t.Run("New chat is not created", func(t *testing.T) {
+		// ARRANGE
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 5)
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(settingsRows())
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		var sent *llm.GenerateRequest
+		answer(mocks, &sent)
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{Content: "Hi, I am Ada.", DryRun: true}, streamChan)
+
+		// ASSERT
+		var content string
+		for chunk := range streamChan {
+			assert.Empty(t, chunk.Error)
+			assert.Empty(t, chunk.ChatID, "there is no chat to refer to")
+			content += chunk.Content
+		}
+		assert.Equal(t, "Ada.", content)
+		require.NotNil(t, sent)
+		assert.Equal(t, []llm.Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi, I am Ada."}}, sent.Messages)
+		assertNoWrites(t, chatService, mocks)
+	})
+
+	t.Run("Existing chat is read but not changed", func(t *testing.T) {
+		// ARRANGE
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 5)
+		encoded := base64.StdEncoding.EncodeToString(pngHeader)
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(settingsRows())
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "New Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		var sent *llm.GenerateRequest
+		answer(mocks, &sent)
+
+		// ACT: The pre-created chat would get a title after a real first message.
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "What is this?", Images: []string{encoded}, DryRun: true}, streamChan)
+
+		// ASSERT: The image of the unsaved message is sent along.
+		for chunk := range streamChan {
+			assert.Empty(t, chunk.Error)
+		}
+		require.NotNil(t, sent)
+		require.Len(t, sent.Messages, 2)
+		assert.Equal(t, []string{encoded}, sent.Messages[1].Images)
+		assertNoWrites(t, chatService, mocks)
+	})
+}
+
+// TestChatService_HandleNewMessage_ConcurrencyLimit verifies that a generation
+// beyond MaxConcurrentGenerations is rejected, or queued until a slot is free.
+func TestChatService_HandleNewMessage_ConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name         string
+		queueTimeout time.Duration
+		// expectedSecond are the phases and errors of the second request's events.
+		expectedSecond []string
+		generations    int
+	}{
+		{
+			name:           "Rejected without a queue",
+			expectedSecond: []string{"error: Too many answers are being generated; try again later"},
+			generations:    1,
+		},
+		{
+			name:           "Queued until the first generation is done",
+			queueTimeout:   5 * time.Second,
+			expectedSecond: []string{"phase: queued", "phase: loading", "phase: generating", "content: Ada."},
+			generations:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE: The first generation blocks until it is unblocked.
+			chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{
+				MaxConcurrentGenerations: 1,
+				GenerationQueueTimeout:   tc.queueTimeout,
+			})
+			defer func() { _ = mocks.db.Close() }()
+			for range 2 {
+				mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+					WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "m1"))
+			}
+			mocks.repo.On("GetChat", mock.Anything, "chat1").Return(&model.Chat{ID: "chat1", Title: "Names"}, nil)
+			mocks.repo.On("GetLastActiveMessage", mock.Anything, "chat1").Return(&model.Message{ID: "a1"}, nil)
+			mocks.repo.On("AddMessage", mock.Anything, mock.AnythingOfType("*model.Message"), "chat1").Return(nil)
+			mocks.repo.On("GetActiveMessagesByChatID", mock.Anything, "chat1").Return([]model.Message{{ID: "u1", Role: "user", Content: "Hi"}}, nil)
+			mocks.repo.On("GetAttachmentsByMessageIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			started := make(chan struct{})
+			unblock := make(chan struct{})
+			var calls atomic.Int32
+			mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+				Return(nil).
+				Run(func(args mock.Arguments) {
+					if calls.Add(1) == 1 {
+						close(started)
+						<-unblock
+					}
+					outChan := args.Get(2).(chan<- llm.StreamResponse)
+					outChan <- llm.StreamResponse{Content: "Ada.", Done: true}
+					close(outChan)
+				}).Times(tc.generations)
+
+			firstDone := make(chan struct{})
+			go func() {
+				defer close(firstDone)
+				first := make(chan model.StreamResponse)
+				go chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, first)
+				for range first {
+				}
+			}()
+			<-started
+
+			// ACT
+			second := make(chan model.StreamResponse)
+			go chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, second)
+			var events []string
+			for event := range second {
+				switch {
+				case event.Phase != "":
+					events = append(events, "phase: "+event.Phase)
+					if event.Phase == model.PhaseQueued {
+						close(unblock)
+					}
+				case event.Error != "":
+					events = append(events, "error: "+event.Error)
+				case event.Content != "":
+					events = append(events, "content: "+event.Content)
+				}
+			}
+			if tc.queueTimeout == 0 {
+				close(unblock)
+			}
+			<-firstDone
+
+			// ASSERT
+			assert.Equal(t, tc.expectedSecond, events)
+			mocks.llm.AssertExpectations(t)
+		})
+	}
+}
+
+// TestChatService_HandleNewMessage_TitlePreview verifies that the temporary title of
+// a new chat respects the configured preview length.
+//
+// WHY: The preview is cut by runes, not bytes; slicing multi-byte text by bytes
+// would produce invalid UTF-8 in the stored title.
+func TestChatService_HandleNewMessage_TitlePreview(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name          string
+		cfg           service.ChatServiceConfig
+		content       string
+		expectedTitle string
+		expectedUser  string
+	}{
+		{
+			name:          "Configured length with multi-byte runes",
+			cfg:           service.ChatServiceConfig{DefaultUserID: "alice", TitlePreviewLength: 5},
+			content:       "Привіт, світе!",
+			expectedTitle: "Приві",
+			expectedUser:  "alice",
+		},
+		{
+			name:          "Defaults are applied for the zero config",
+			cfg:           service.ChatServiceConfig{},
+			content:       strings.Repeat("ж", 60),
+			expectedTitle: strings.Repeat("ж", 50),
+			expectedUser:  "default-user",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chatService, mocks := setupChatServiceWithConfig(t, tc.cfg)
+			defer func() { _ = mocks.db.Close() }()
+			streamChan := make(chan model.StreamResponse, 1)
+
+			// ARRANGE: Abort right after the chat is created; only its fields matter here.
+			rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "test-model")
+			mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+			var created *model.Chat
+			mocks.repo.On("CreateChat", ctx, mock.AnythingOfType("*model.Chat")).
+				Run(func(args mock.Arguments) { created = args.Get(1).(*model.Chat) }).
+				Return(errors.New("stop")).Once()
+
+			// ACT
+			chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{Content: tc.content}, streamChan)
+
+			// ASSERT
+			require.NotNil(t, created)
+			assert.Equal(t, tc.expectedTitle, created.Title)
+			assert.True(t, utf8.ValidString(created.Title))
+			assert.Equal(t, tc.expectedUser, created.UserID)
+		})
+	}
+}
+
+// TestChatService_AddRawMessage verifies that messages are appended to the active
+// branch without involving the LLM.
+func TestChatService_AddRawMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Appended after the last active message", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.MatchedBy(func(m *model.Message) bool {
+			return m.ParentID != nil && *m.ParentID == "prev" && m.Role == "system" && m.Content == "Be brief." && m.Model == nil
+		}), "chat1").Return(nil).Once()
+
+		// ACT
+		msg, err := chatService.AddRawMessage(ctx, "chat1", &service.AddMessageRequest{Role: "system", Content: "Be brief."})
+
+		// ASSERT: The LLM mock has no expectations, so any call to it would fail the test.
+		require.NoError(t, err)
+		assert.Equal(t, "system", msg.Role)
+	})
+
+	t.Run("Success - First message of an empty chat has no parent", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("AddMessage", ctx, mock.MatchedBy(func(m *model.Message) bool {
+			return m.ParentID == nil && m.Model != nil && *m.Model == "m1"
+		}), "chat1").Return(nil).Once()
+
+		_, err := chatService.AddRawMessage(ctx, "chat1", &service.AddMessageRequest{Role: "assistant", Content: "Hi", Model: "m1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Failure - Whitespace-only content", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		_, err := chatService.AddRawMessage(ctx, "chat1", &service.AddMessageRequest{Role: "user", Content: "   "})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetChat", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.AddRawMessage(ctx, "missing", &service.AddMessageRequest{Role: "user", Content: "hi"})
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// TestSanitizeTitle pins down the quote-stripping and length-cap rules for generated titles.
+func TestSanitizeTitle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		maxLen   int
+		expected string
+	}{
+		{name: "Plain title is kept", input: "Roman Empire History", maxLen: 60, expected: "Roman Empire History"},
+		{name: "Double quotes are stripped", input: `"Roman Empire History"`, maxLen: 60, expected: "Roman Empire History"},
+		{name: "Nested and typographic quotes are stripped", input: ` “'Go Generics'” `, maxLen: 60, expected: "Go Generics"},
+		{name: "Guillemets are stripped", input: "«Історія Риму»", maxLen: 60, expected: "Історія Риму"},
+		{name: "Inner quotes are kept", input: `The "Go" Language`, maxLen: 60, expected: `The "Go" Language`},
+		{name: "Cut at word boundary", input: "Understanding distributed consensus algorithms", maxLen: 20, expected: "Understanding"},
+		{name: "Limit on a boundary keeps the whole word", input: "Go generics explained", maxLen: 11, expected: "Go generics"},
+		{name: "Trailing punctuation is trimmed", input: "Databases, indexes, and more", maxLen: 19, expected: "Databases, indexes"},
+		{name: "Single long word is hard-cut", input: "Supercalifragilistic", maxLen: 5, expected: "Super"},
+		{name: "Multi-byte runes are counted once", input: "Привіт світе як справи", maxLen: 12, expected: "Привіт світе"},
+		{name: "Only quotes yields empty title", input: `""`, maxLen: 60, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, service.SanitizeTitle(tc.input, tc.maxLen))
+		})
+	}
+}
+
+// TestChatService_GenerateTitle verifies that a generated title is cleaned up before it is saved.
+func TestChatService_GenerateTitle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Title is sanitized and capped", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{TitleMaxLength: 15})
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: The attempt runs with its own deadline, so the context is not
+		// the one passed in.
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "\"Roman Empire and its fall\""}`}, nil).Once()
+		mocks.repo.On("UpdateChatTitle", mock.Anything, "chat1", "Roman Empire").Return(nil).Once()
+
+		// ACT
+		title, err := chatService.GenerateTitle(ctx, "chat1", "support", "", "q", "a")
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "Roman Empire", title)
+	})
+
+	t.Run("Success - Custom prompt template", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: A German prompt; the support model must receive it rendered.
+		template := `Gib diesem Gespräch einen kurzen Titel als JSON {"title": "..."}.\nNutzer: {{.User}}\nAssistent: {{.Assistant}}`
+		mocks.llm.On("Generate", mock.Anything, mock.MatchedBy(func(req *llm.GenerateRequest) bool {
+			return len(req.Messages) == 1 && req.Messages[0].Content ==
+				`Gib diesem Gespräch einen kurzen Titel als JSON {"title": "..."}.\nNutzer: Wie backe ich Brot?\nAssistent: Mit Mehl und Hefe.`
+		})).Return(&llm.GenerateResponse{Response: `{"title": "Brot backen"}`}, nil).Once()
+		mocks.repo.On("UpdateChatTitle", mock.Anything, "chat1", "Brot backen").Return(nil).Once()
+
+		// ACT
+		title, err := chatService.GenerateTitle(ctx, "chat1", "support", template, "Wie backe ich Brot?", "Mit Mehl und Hefe.")
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "Brot backen", title)
+	})
+
+	t.Run("Failure - Empty title is an error", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "  "}`}, nil).Once()
+
+		_, err := chatService.GenerateTitle(ctx, "chat1", "support", "", "q", "a")
+		assert.Error(t, err)
+		mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Times out a hung support model without a title", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{TitleTimeout: 20 * time.Millisecond})
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: The support model blocks until its call is cancelled.
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).
+			Return(nil, context.Canceled).
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).Once()
+
+		// ACT
+		startedAt := time.Now()
+		_, err := chatService.GenerateTitle(ctx, "chat1", "support", "", "q", "a")
+
+		// ASSERT
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(startedAt), 5*time.Second)
+		mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Retry - Succeeds after transient failures", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{TitleRetryBackoff: time.Millisecond})
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: The support model fails twice before it answers. Each attempt
+		// gets its own deadline, so the context is not the one passed in.
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(nil, errors.New("model loading")).Twice()
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(&llm.GenerateResponse{Response: `{"title": "Test"}`}, nil).Once()
+		mocks.repo.On("UpdateChatTitle", mock.Anything, "chat1", "Test").Return(nil).Once()
+
+		// ACT
+		chatService.GenerateTitleWithRetry(ctx, "chat1", "support", "", "q", "a")
+
+		// ASSERT
+		mocks.llm.AssertNumberOfCalls(t, "Generate", 3)
+	})
+
+	t.Run("Retry - Falls back to the first message after the attempt limit", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{TitleRetryBackoff: time.Millisecond})
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+		mocks.repo.On("UpdateChatTitle", ctx, "chat1", "What is Go?").Return(nil).Once()
+
+		chatService.GenerateTitleWithRetry(ctx, "chat1", "support", "", "  What is Go?", "a")
+
+		mocks.llm.AssertNumberOfCalls(t, "Generate", 3)
+	})
+
+	t.Run("Retry - Times out a hung support model and retries with a simpler prompt", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{
+			TitleRetryBackoff: time.Millisecond,
+			TitleTimeout:      20 * time.Millisecond,
+		})
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE: The support model never answers; it only notices its deadline.
+		var prompts []string
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).
+			Return(nil, context.DeadlineExceeded).
+			Run(func(args mock.Arguments) {
+				prompts = append(prompts, args.Get(1).(*llm.GenerateRequest).Messages[0].Content)
+				<-args.Get(0).(context.Context).Done()
+			})
+		mocks.repo.On("UpdateChatTitle", ctx, "chat1", "q").Return(nil).Once()
+
+		// ACT
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			chatService.GenerateTitleWithRetry(ctx, "chat1", "support", "", "q", "a")
+		}()
+
+		// ASSERT
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("title generation did not give up on the hung support model")
+		}
+		require.Len(t, prompts, 3)
+		assert.Contains(t, prompts[0], `{"title"`)
+		assert.Contains(t, prompts[1], "Answer with the title only.")
+		assert.Contains(t, prompts[2], "Answer with the title only.")
+	})
+}
+
+// TestChatService_Close verifies that Close waits for a title generation in
+// progress and cancels it once its context is done, without a fallback title.
+//
+// WHY: On shutdown the database is closed right after Close returns.
+func TestChatService_Close(t *testing.T) {
+	chatService, mocks := setupChatService(t)
+	defer func() { _ = mocks.db.Close() }()
+
+	// ARRANGE: The support model hangs until the job is cancelled.
+	started := make(chan struct{})
+	mocks.llm.On("Generate", mock.Anything, mock.Anything).
+		Return(nil, context.Canceled).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-args.Get(0).(context.Context).Done()
+		}).Once()
+	chatService.StartTitleJob("chat1", "support", "", "q", "a")
+	<-started
+
+	// ACT
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		chatService.Close(ctx)
+	}()
+
+	// ASSERT
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not cancel the title generation")
+	}
+	mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestChatService_RegenerateTitle verifies that a title is generated on demand from
+// the first exchange of a chat, and that the existing title is kept when the
+// support model cannot provide one.
+func TestChatService_RegenerateTitle(t *testing.T) {
+	ctx := context.Background()
+	exchange := []model.Message{
+		{ID: "m1", Role: "user", Content: "When did Rome fall?"},
+		{ID: "m2", Role: "assistant", Content: "In 476 AD."},
+		{ID: "m3", Role: "user", Content: "Why?"},
+	}
+
+	// setup returns a service whose chat has the given messages and whose support
+	// model is "support".
+	setup := func(t *testing.T, messages []model.Message) (*service.ChatService, Mocks) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Old title"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return(messages, nil).Once()
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "main").
+			AddRow("support_model", "support")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		return chatService, mocks
+	}
+
+	testCases := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{name: "JSON response", response: `Sure! {"title": "Fall of Rome"}`, expected: "Fall of Rome"},
+		{name: "Raw response", response: `"Fall of Rome"`, expected: "Fall of Rome"},
+	}
+	for _, tc := range testCases {
+		t.Run("Success - "+tc.name, func(t *testing.T) {
+			// ARRANGE
+			chatService, mocks := setup(t, exchange)
+			mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "support"}}}, nil).Once()
+			var prompt string
+			mocks.llm.On("Generate", mock.Anything, mock.MatchedBy(func(req *llm.GenerateRequest) bool { return req.Model == "support" })).
+				Run(func(args mock.Arguments) { prompt = args.Get(1).(*llm.GenerateRequest).Messages[0].Content }).
+				Return(&llm.GenerateResponse{Response: tc.response}, nil).Once()
+			mocks.repo.On("UpdateChatTitle", ctx, "chat1", tc.expected).Return(nil).Once()
+
+			// ACT
+			resp, err := chatService.RegenerateTitle(ctx, "chat1")
+
+			// ASSERT
+			require.NoError(t, err)
+			assert.Equal(t, &service.RegenerateTitleResponse{Title: tc.expected, Regenerated: true}, resp)
+			assert.Contains(t, prompt, "When did Rome fall?")
+			assert.Contains(t, prompt, "In 476 AD.")
+		})
+	}
+
+	t.Run("Support model unavailable keeps the title", func(t *testing.T) {
+		chatService, mocks := setup(t, exchange)
+		mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "main"}}}, nil).Once()
+
+		resp, err := chatService.RegenerateTitle(ctx, "chat1")
+
+		require.NoError(t, err)
+		assert.Equal(t, &service.RegenerateTitleResponse{Title: "Old title"}, resp)
+		mocks.llm.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Hung support model keeps the title", func(t *testing.T) {
+		// ARRANGE: The support model blocks until its call is cancelled.
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{TitleTimeout: 20 * time.Millisecond})
+		t.Cleanup(func() { _ = mocks.db.Close() })
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Old title"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return(exchange, nil).Once()
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "main").
+			AddRow("support_model", "support")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.llm.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "support"}}}, nil).Once()
+		mocks.llm.On("Generate", mock.Anything, mock.Anything).
+			Return(nil, context.Canceled).
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).Once()
+
+		// ACT
+		startedAt := time.Now()
+		resp, err := chatService.RegenerateTitle(ctx, "chat1")
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, &service.RegenerateTitleResponse{Title: "Old title"}, resp)
+		assert.Less(t, time.Since(startedAt), 5*time.Second)
+		mocks.repo.AssertNotCalled(t, "UpdateChatTitle", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Chat without an answer", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Old title"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return(exchange[:1], nil).Once()
+
+		_, err := chatService.RegenerateTitle(ctx, "chat1")
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+		mocks.repo.On("GetChat", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.RegenerateTitle(ctx, "missing")
+
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// pngHeader is the smallest payload that `http.DetectContentType` recognizes as a PNG image.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// TestChatService_HandleNewMessage_Images verifies that images are stored as
+// attachments and forwarded to the LLM, and that invalid images are rejected.
+func TestChatService_HandleNewMessage_Images(t *testing.T) {
+	ctx := context.Background()
+	encoded := base64.StdEncoding.EncodeToString(pngHeader)
+
+	t.Run("Success - Image is stored and sent to the LLM", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		streamChan := make(chan model.StreamResponse, 5)
+
+		// ARRANGE: The history returned after saving contains the user message, whose
+		// attachment must then be loaded and passed to the LLM as base64.
+		rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "llava")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Vision"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		var userMsg *model.Message
+		mocks.repo.On("AddMessage", ctx, mock.MatchedBy(func(m *model.Message) bool { return m.Role == "user" }), "chat1").
+			Run(func(args mock.Arguments) { userMsg = args.Get(1).(*model.Message) }).
+			Return(nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.MatchedBy(func(m *model.Message) bool { return m.Role == "assistant" }), "chat1").Return(nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").
+			Return(func(context.Context, string) []model.Message { return []model.Message{*userMsg} }, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).
+			Return(func(context.Context, []string) []model.Attachment { return userMsg.Attachments }, nil).Once()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.MatchedBy(func(r *llm.GenerateRequest) bool {
+			return len(r.Messages) == 2 && len(r.Messages[1].Images) == 1 && r.Messages[1].Images[0] == encoded
+		}), mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "What is this?", Images: []string{"data:image/png;base64," + encoded}}, streamChan)
+
+		// ASSERT
+		finalChunk := <-streamChan
+		assert.Empty(t, finalChunk.Error)
+		require.Len(t, userMsg.Attachments, 1)
+		assert.Equal(t, "image/png", userMsg.Attachments[0].MimeType)
+		assert.Equal(t, int64(len(pngHeader)), userMsg.Attachments[0].SizeBytes)
+	})
+
+	testCases := []struct {
+		name          string
+		cfg           service.ChatServiceConfig
+		image         string
+		expectedError string
+	}{
+		{
+			name:          "Failure - Image exceeds the size limit",
+			cfg:           service.ChatServiceConfig{MaxImageBytes: 8},
+			image:         encoded,
+			expectedError: "exceeds the maximum size",
+		},
+		{
+			name:          "Failure - Invalid base64",
+			image:         "not base64!",
+			expectedError: "not valid base64",
+		},
+		{
+			name:          "Failure - Not an image",
+			image:         base64.StdEncoding.EncodeToString([]byte("plain text")),
+			expectedError: "unsupported content type",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chatService, mocks := setupChatServiceWithConfig(t, tc.cfg)
+			defer func() { _ = mocks.db.Close() }()
+			streamChan := make(chan model.StreamResponse, 1)
+
+			// ARRANGE
+			rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "llava")
+			mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+
+			// ACT
+			chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{Content: "What is this?", Images: []string{tc.image}}, streamChan)
+
+			// ASSERT: The error is reported before any chat is created.
+			errChunk := <-streamChan
+			assert.Contains(t, errChunk.Error, tc.expectedError)
+			mocks.repo.AssertNotCalled(t, "CreateChat", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestChatService_GetMessage verifies that a message is only returned for its own
+// chat and comes with its attachment references.
+func TestChatService_GetMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		// ARRANGE
+		mocks.repo.On("GetMessageByID", ctx, "msg1").Return(&model.Message{ID: "msg1", ChatID: "chat1", Role: "user"}, nil).Once()
+		mocks.repo.On("GetAttachmentRefsByChatID", ctx, "chat1").Return([]model.Attachment{
+			{ID: "att1", MessageID: "msg1", MimeType: "image/png"},
+			{ID: "att2", MessageID: "msg2", MimeType: "image/png"},
+		}, nil).Once()
+
+		// ACT
+		msg, err := chatService.GetMessage(ctx, "chat1", "msg1")
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "msg1", msg.ID)
+		require.Len(t, msg.Attachments, 1)
+		assert.Equal(t, "att1", msg.Attachments[0].ID)
+	})
+
+	t.Run("Failure - Message of another chat", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetMessageByID", ctx, "msg1").Return(&model.Message{ID: "msg1", ChatID: "chat1"}, nil).Once()
+
+		_, err := chatService.GetMessage(ctx, "chat2", "msg1")
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+
+	t.Run("Failure - Not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetMessageByID", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.GetMessage(ctx, "chat1", "missing")
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// TestChatService_GetAttachment verifies the mapping of repository errors.
+func TestChatService_GetAttachment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		expected := &model.Attachment{ID: "att1", MimeType: "image/png", Data: pngHeader}
+		mocks.repo.On("GetAttachment", ctx, "chat1", "msg1", "att1").Return(expected, nil).Once()
+
+		attachment, err := chatService.GetAttachment(ctx, "chat1", "msg1", "att1")
+		require.NoError(t, err)
+		assert.Equal(t, expected, attachment)
+	})
+
+	t.Run("Failure - Not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+
+		mocks.repo.On("GetAttachment", ctx, "chat1", "msg1", "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.GetAttachment(ctx, "chat1", "msg1", "missing")
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// TestChatService_HandleNewMessage_DocumentRetrieval verifies that chats bound to a
+// collection get the most relevant document chunks added to the prompt.
+func TestChatService_HandleNewMessage_DocumentRetrieval(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, embedErr error) (*service.ChatService, Mocks, *string, *string) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		chat := &model.Chat{ID: "chat1", Title: "Docs", Model: "chat-model", CollectionID: "col1"}
+		mocks.repo.On("GetChat", ctx, "chat1").Return(chat, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(nil, repository.ErrNotFound).Once()
+		var sentContent, storedContent string
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				if msg := args.Get(1).(*model.Message); msg.Role == "user" {
+					storedContent = msg.Content
+				}
+			}).Twice()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("GetCollection", ctx, "col1").
+			Return(&model.Collection{ID: "col1", EmbeddingModel: "embed-model"}, nil).Once()
+		mocks.repo.On("GetChunksByCollectionID", ctx, "col1").Return([]model.DocumentChunk{
+			{ID: "c1", Content: "Unrelated text", Embedding: []float32{0, 1}},
+			{ID: "c2", Content: "The answer is 42", Embedding: []float32{1, 0}},
+		}, nil).Once()
+		if embedErr != nil {
+			mocks.llm.On("Embeddings", mock.Anything, mock.Anything).Return(nil, embedErr).Once()
+		} else {
+			mocks.llm.On("Embeddings", mock.Anything, mock.Anything).
+				Return(&llm.EmbeddingsResponse{Embeddings: [][]float32{{1, 0}}}, nil).Once()
+		}
+
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").
+			Return([]model.Message{{ID: "u1", Role: "user", Content: "What is the answer?"}}, nil).Once()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				req := args.Get(1).(*llm.GenerateRequest)
+				sentContent = req.Messages[len(req.Messages)-1].Content
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+		return chatService, mocks, &sentContent, &storedContent
+	}
+
+	t.Run("Success - Best matching chunk is added to the prompt", func(t *testing.T) {
+		chatService, _, sentContent, storedContent := setup(t, nil)
+		streamChan := make(chan model.StreamResponse, 5)
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "What is the answer?"}, streamChan)
+
+		// ASSERT: The best chunk comes first and the question is kept at the end.
+		finalChunk := <-streamChan
+		assert.Empty(t, finalChunk.Error)
+		assert.Contains(t, *sentContent, "[1] The answer is 42")
+		assert.True(t, strings.HasSuffix(*sentContent, "Question: What is the answer?"))
+		assert.Equal(t, "What is the answer?", *storedContent, "the stored message must not include the excerpts")
+	})
+
+	t.Run("Success - Retrieval failure does not block the message", func(t *testing.T) {
+		chatService, _, sentContent, _ := setup(t, errors.New("embedding model missing"))
+		streamChan := make(chan model.StreamResponse, 5)
+
+		// ACT
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "What is the answer?"}, streamChan)
+
+		// ASSERT: The message is sent unchanged.
+		finalChunk := <-streamChan
+		assert.Empty(t, finalChunk.Error)
+		assert.Equal(t, "What is the answer?", *sentContent)
+	})
+}
+
+// TestChatService_SetChatCollection tests binding a chat to a document collection.
+func TestChatService_SetChatCollection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetCollection", ctx, "col1").Return(&model.Collection{ID: "col1"}, nil).Once()
+		mocks.repo.On("UpdateChatCollection", ctx, "chat1", "col1").Return(nil).Once()
+
+		err := chatService.SetChatCollection(ctx, "chat1", "col1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Empty ID disables retrieval", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("UpdateChatCollection", ctx, "chat1", "").Return(nil).Once()
+
+		err := chatService.SetChatCollection(ctx, "chat1", "")
+		assert.NoError(t, err)
+		mocks.repo.AssertNotCalled(t, "GetCollection", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Unknown collection", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetCollection", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		err := chatService.SetChatCollection(ctx, "chat1", "missing")
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+
+	t.Run("Failure - Chat not found", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("UpdateChatCollection", ctx, "missing", "").Return(repository.ErrNotFound).Once()
+
+		err := chatService.SetChatCollection(ctx, "missing", "")
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// TestChatService_HandleNewMessage_Idempotency verifies that a request resent with
+// the same idempotency key returns the original answer without generating again.
+func TestChatService_HandleNewMessage_Idempotency(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Same key twice creates one answer", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		req := &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello", IdempotencyKey: "key-1"}
+
+		// ARRANGE: The first request is processed normally and its answer recorded.
+		rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat", Model: "m"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		var assistantID string
+		var assistantMessages int
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				if msg := args.Get(1).(*model.Message); msg.Role == "assistant" {
+					assistantID = msg.ID
+					assistantMessages++
+				}
+			}).Twice()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hi there", Done: true}
+				close(outChan)
+			}).Once()
+		mocks.repo.On("GetIdempotencyRecord", ctx, "key-1", mock.AnythingOfType("time.Time")).Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("SaveIdempotencyRecord", ctx, mock.MatchedBy(func(r *model.IdempotencyRecord) bool {
+			return r.Key == "key-1" && r.ChatID == "chat1" && r.MessageID == assistantID
+		})).Return(nil).Once()
+		mocks.repo.On("DeleteIdempotencyRecordsBefore", ctx, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		// ACT 1
+		first := make(chan model.StreamResponse, 5)
+		chatService.HandleNewMessage(ctx, req, first)
+		for range first {
+		}
+
+		// ARRANGE 2: The repeat finds the recorded answer.
+		mocks.repo.On("GetIdempotencyRecord", ctx, "key-1", mock.AnythingOfType("time.Time")).
+			Return(&model.IdempotencyRecord{Key: "key-1", ChatID: "chat1", MessageID: assistantID}, nil).Once()
+		mocks.repo.On("GetMessageByID", ctx, assistantID).
+			Return(&model.Message{ID: assistantID, Role: "assistant", Content: "Hi there"}, nil).Once()
+
+		// ACT 2
+		second := make(chan model.StreamResponse, 5)
+		chatService.HandleNewMessage(ctx, req, second)
+
+		// ASSERT: The original answer is replayed and nothing new is generated or stored.
+		replay := <-second
+		assert.Equal(t, "chat1", replay.ChatID)
+		assert.Equal(t, "Hi there", replay.Content)
+		assert.True(t, replay.Done)
+		assert.Equal(t, 1, assistantMessages)
+		mocks.llm.AssertNumberOfCalls(t, "GenerateStream", 1)
+	})
+
+	t.Run("Success - An incomplete answer is not recorded", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		req := &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello", IdempotencyKey: "key-3"}
+
+		// ARRANGE: The stream ends without its final chunk.
+		rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetIdempotencyRecord", ctx, "key-3", mock.AnythingOfType("time.Time")).Return(nil, repository.ErrNotFound).Once()
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat", Model: "m"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hi th"}
+				close(outChan)
+			}).Once()
+
+		// ACT
+		streamChan := make(chan model.StreamResponse, 5)
+		chatService.HandleNewMessage(ctx, req, streamChan)
+		for range streamChan {
+		}
+
+		// ASSERT: A retry with the same key generates the answer again.
+		mocks.repo.AssertNotCalled(t, "SaveIdempotencyRecord", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure - Concurrent request with the same key is rejected", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		req := &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello", IdempotencyKey: "key-2"}
+
+		// ARRANGE: The first request blocks in the idempotency lookup until released.
+		release := make(chan struct{})
+		entered := make(chan struct{})
+		mocks.repo.On("GetIdempotencyRecord", ctx, "key-2", mock.AnythingOfType("time.Time")).
+			Return(nil, repository.ErrNotFound).
+			Run(func(mock.Arguments) {
+				close(entered)
+				<-release
+			}).Once()
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnError(errors.New("stop here"))
+
+		first := make(chan model.StreamResponse, 1)
+		go chatService.HandleNewMessage(ctx, req, first)
+		<-entered
+
+		// ACT
+		second := make(chan model.StreamResponse, 1)
+		chatService.HandleNewMessage(ctx, req, second)
+
+		// ASSERT
+		assert.Contains(t, (<-second).Error, "already being processed")
+		close(release)
+		for range first {
+		}
+	})
+}
+
+// TestChatService_HandleNewMessage_KeepAlive verifies that a per-request
+// `keep_alive` overrides the setting and is sent at the top level of the request.
+func TestChatService_HandleNewMessage_KeepAlive(t *testing.T) {
+	ctx := context.Background()
+
+	run := func(t *testing.T, setting string, options *llm.RequestOptions) *llm.GenerateRequest {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("support_model", "global-model").
+			AddRow("keep_alive", setting)
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		var sent *llm.GenerateRequest
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				sent = args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hello", Done: true}
+				close(outChan)
+			}).Once()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi", Options: options}, streamChan)
+		for range streamChan {
+		}
+		require.NotNil(t, sent)
+		return sent
+	}
+
+	t.Run("Unset uses Ollama's default", func(t *testing.T) {
+		sent := run(t, "", nil)
+		assert.Nil(t, sent.KeepAlive)
+	})
+
+	t.Run("Setting is used by default", func(t *testing.T) {
+		sent := run(t, "10m", nil)
+		require.NotNil(t, sent.KeepAlive)
+		assert.Equal(t, llm.KeepAlive("10m"), *sent.KeepAlive)
+	})
+
+	t.Run("Request overrides the setting", func(t *testing.T) {
+		keepAlive := llm.KeepAlive("0")
+		temperature := float32(0.5)
+		options := &llm.RequestOptions{KeepAlive: &keepAlive, Temperature: &temperature}
+
+		sent := run(t, "10m", options)
+
+		require.NotNil(t, sent.KeepAlive)
+		assert.Equal(t, llm.KeepAlive("0"), *sent.KeepAlive)
+		// WHY: Ollama ignores `keep_alive` inside the model options.
+		require.NotNil(t, sent.Options)
+		assert.Nil(t, sent.Options.KeepAlive)
+		assert.Equal(t, &temperature, sent.Options.Temperature)
+		assert.NotNil(t, options.KeepAlive, "the caller's options must not be modified")
+	})
+}
+
+// TestChatService_HandleNewMessage_JSONMode verifies that JSON mode is sent to
+// Ollama as the top-level `format`, and that an answer that is not valid JSON is
+// reported with a warning before the final chunk.
+func TestChatService_HandleNewMessage_JSONMode(t *testing.T) {
+	ctx := context.Background()
+
+	// run sends one message and returns the request sent to the LLM and the
+	// chunks streamed to the client.
+	run := func(t *testing.T, req *service.CreateMessageRequest, answer string) (*llm.GenerateRequest, []model.StreamResponse) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("support_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		var sent *llm.GenerateRequest
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				sent = args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: answer}
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+
+		req.ChatID, req.Content = "chat1", "List three colors as JSON."
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, req, streamChan)
+		var chunks []model.StreamResponse
+		for chunk := range streamChan {
+			// Phase events are covered by TestChatService_HandleNewMessage_Phases.
+			if chunk.Phase == "" {
+				chunks = append(chunks, chunk)
+			}
+		}
+		require.NotNil(t, sent)
+		return sent, chunks
+	}
+
+	t.Run("Valid JSON has no warning", func(t *testing.T) {
+		sent, chunks := run(t, &service.CreateMessageRequest{ResponseFormat: "json"}, `{"colors": ["red", "green", "blue"]}`)
+
+		assert.Equal(t, "json", sent.Format)
+		for _, chunk := range chunks {
+			assert.Empty(t, chunk.Warning)
+		}
+		assert.True(t, chunks[len(chunks)-1].Done)
+	})
+
+	t.Run("Invalid JSON is reported before the final chunk", func(t *testing.T) {
+		_, chunks := run(t, &service.CreateMessageRequest{ResponseFormat: "json"}, `{"colors": ["red", "gre`)
+
+		require.Len(t, chunks, 3)
+		assert.Equal(t, `{"colors": ["red", "gre`, chunks[0].Content)
+		assert.Equal(t, "The response is not valid JSON", chunks[1].Warning)
+		assert.True(t, chunks[2].Done)
+	})
+
+	t.Run("Format in options is lifted to the top level", func(t *testing.T) {
+		format := "json"
+		temperature := float32(0.2)
+		options := &llm.RequestOptions{Format: &format, Temperature: &temperature}
+
+		sent, _ := run(t, &service.CreateMessageRequest{Options: options}, `[]`)
+
+		assert.Equal(t, "json", sent.Format)
+		require.NotNil(t, sent.Options)
+		assert.Nil(t, sent.Options.Format)
+		assert.Equal(t, &temperature, sent.Options.Temperature)
+		assert.NotNil(t, options.Format, "the caller's options must not be modified")
+	})
+
+	t.Run("Text answers are not validated", func(t *testing.T) {
+		sent, chunks := run(t, &service.CreateMessageRequest{}, `Red, green and blue.`)
+
+		assert.Empty(t, sent.Format)
+		for _, chunk := range chunks {
+			assert.Empty(t, chunk.Warning)
+		}
+	})
+}
+
+// TestChatService_HandleNewMessage_Phases verifies that a `loading` event is sent
+// before the first content, `generating` when the first token arrives, and that
+// the time to the first token is stored with the generation stats.
+func TestChatService_HandleNewMessage_Phases(t *testing.T) {
+	ctx := context.Background()
+	chatService, mocks := setupChatService(t)
+	defer func() { _ = mocks.db.Close() }()
+
+	// ARRANGE
+	rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+	mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+	mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+	mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+	mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+	mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+	var stored *model.Message
+	mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			if msg := args.Get(1).(*model.Message); msg.Role == "assistant" {
+				stored = msg
+			}
+		}).Twice()
+	mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			outChan := args.Get(2).(chan<- llm.StreamResponse)
+			// WHY: The delay stands in for loading the model and reading the prompt.
+			time.Sleep(5 * time.Millisecond)
+			outChan <- llm.StreamResponse{Content: "Hel"}
+			outChan <- llm.StreamResponse{Content: "lo"}
+			outChan <- llm.StreamResponse{Done: true, Stats: &llm.GenerationStats{EvalCount: 2}}
+			close(outChan)
+		}).Once()
+	streamChan := make(chan model.StreamResponse, 10)
+
+	// ACT
+	chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, streamChan)
+	var chunks []model.StreamResponse
+	for chunk := range streamChan {
+		chunks = append(chunks, chunk)
+	}
+
+	// ASSERT
+	require.Len(t, chunks, 5)
+	assert.Equal(t, model.PhaseLoading, chunks[0].Phase)
+	assert.Empty(t, chunks[0].Content)
+	assert.Equal(t, model.PhaseGenerating, chunks[1].Phase)
+	assert.Equal(t, "Hel", chunks[2].Content)
+	assert.Empty(t, chunks[2].Phase, "the phase only changes once")
+	assert.Equal(t, "lo", chunks[3].Content)
+	assert.True(t, chunks[4].Done)
+
+	require.NotNil(t, stored)
+	var metadata struct {
+		EvalCount        int   `json:"eval_count"`
+		TimeToFirstToken int64 `json:"time_to_first_token"`
+	}
+	require.NoError(t, json.Unmarshal(stored.Metadata, &metadata))
+	assert.Equal(t, 2, metadata.EvalCount)
+	assert.GreaterOrEqual(t, metadata.TimeToFirstToken, (5 * time.Millisecond).Nanoseconds())
+}
+
+// TestChatService_HandleNewMessage_Unreachable verifies that a backend that cannot
+// be reached, or a model it does not have, ends the stream with a readable error
+// instead of closing it silently.
+func TestChatService_HandleNewMessage_Unreachable(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name          string
+		err           error
+		expectedError string
+	}{
+		{"Unreachable backend", fmt.Errorf("request failed: %w: dial tcp 127.0.0.1:11434: connect: connection refused", llm.ErrUnreachable), "LLM backend unreachable"},
+		{"Missing model", fmt.Errorf("%w: model 'global-model' not found", llm.ErrModelNotFound), "The model is not available; pull it first"},
+		{"Other error", errors.New("ollama returned status 500: llama runner process has terminated"), "The model failed to generate a response"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chatService, mocks := setupChatService(t)
+			defer func() { _ = mocks.db.Close() }()
+
+			// ARRANGE
+			rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+			mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+			mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+			mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+			mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+			mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+			mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil)
+			mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.err).
+				Run(func(args mock.Arguments) {
+					close(args.Get(2).(chan<- llm.StreamResponse))
+				}).Once()
+			streamChan := make(chan model.StreamResponse, 10)
+
+			// ACT
+			chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, streamChan)
+			var chunks []model.StreamResponse
+			for chunk := range streamChan {
+				chunks = append(chunks, chunk)
+			}
+
+			// ASSERT
+			require.Len(t, chunks, 2)
+			assert.Equal(t, model.PhaseLoading, chunks[0].Phase)
+			assert.Equal(t, tc.expectedError, chunks[1].Error)
+		})
+	}
+}
+
+// TestChatService_HandleNewMessage_MidStreamError verifies that an error reported
+// by the model after the answer started reaches the client, and that the partial
+// answer is stored with the error.
+func TestChatService_HandleNewMessage_MidStreamError(t *testing.T) {
+	ctx := context.Background()
+	chatService, mocks := setupChatService(t)
+	defer func() { _ = mocks.db.Close() }()
+
+	// ARRANGE
+	rows := sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model")
+	mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+	mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+	mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+	mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+	mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+	var saved []*model.Message
+	mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+		Run(func(args mock.Arguments) { saved = append(saved, args.Get(1).(*model.Message)) }).
+		Return(nil)
+	mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			ch := args.Get(2).(chan<- llm.StreamResponse)
+			ch <- llm.StreamResponse{Content: "Partial"}
+			ch <- llm.StreamResponse{Error: "model runner has unexpectedly stopped", Done: true}
+			close(ch)
+		}).Once()
+	streamChan := make(chan model.StreamResponse, 10)
+
+	// ACT
+	chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, streamChan)
+	var chunks []model.StreamResponse
+	for chunk := range streamChan {
+		chunks = append(chunks, chunk)
+	}
+
+	// ASSERT: The error is the last event, without a done event before it.
+	require.NotEmpty(t, chunks)
+	last := chunks[len(chunks)-1]
+	assert.Equal(t, "model runner has unexpectedly stopped", last.Error)
+	for _, chunk := range chunks {
+		assert.False(t, chunk.Done)
+	}
+	require.Len(t, saved, 2)
+	assistant := saved[1]
+	assert.Equal(t, "Partial", assistant.Content)
+	assert.JSONEq(t, `{"error": "model runner has unexpectedly stopped"}`, string(assistant.Metadata))
+}
+
+// TestChatService_HandleNewMessage_SystemPromptTemplate verifies that the system
+// prompt is rendered with the current date when prompt templates are enabled, and
+// that an unknown variable is rejected instead of being left blank.
+func TestChatService_HandleNewMessage_SystemPromptTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	// run sends one message with the given system prompt and returns the system
+	// message sent to the LLM, or the streamed error.
+	run := func(t *testing.T, enabled bool, systemPrompt string) (string, string) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("support_model", "global-model").
+			AddRow("system_prompt", systemPrompt).
+			AddRow("enable_prompt_templates", strconv.FormatBool(enabled))
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", UserID: "user-7", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Maybe()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Maybe()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Maybe()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Maybe()
+		var sent *llm.GenerateRequest
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				sent = args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hello", Done: true}
+				close(outChan)
+			}).Maybe()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi"}, streamChan)
+		var streamErr string
+		for resp := range streamChan {
+			if resp.Error != "" {
+				streamErr = resp.Error
+			}
+		}
+		if sent == nil {
+			return "", streamErr
+		}
+		require.NotEmpty(t, sent.Messages)
+		assert.Equal(t, "system", sent.Messages[0].Role)
+		return sent.Messages[0].Content, streamErr
+	}
+
+	t.Run("Renders the date when enabled", func(t *testing.T) {
+		before := time.Now().Format(time.DateOnly)
+		prompt, streamErr := run(t, true, "Today is {{.Date}}. You are {{.Model}}, talking to {{.UserID}}.")
+		after := time.Now().Format(time.DateOnly)
+
+		assert.Empty(t, streamErr)
+		// WHY: the test may run across midnight.
+		assert.Contains(t, []string{
+			"Today is " + before + ". You are global-model, talking to user-7.",
+			"Today is " + after + ". You are global-model, talking to user-7.",
+		}, prompt)
+	})
+
+	t.Run("Leaves the prompt untouched when disabled", func(t *testing.T) {
+		prompt, streamErr := run(t, false, "Today is {{.Date}}.")
+
+		assert.Empty(t, streamErr)
+		assert.Equal(t, "Today is {{.Date}}.", prompt)
+	})
+
+	t.Run("Rejects unknown variables", func(t *testing.T) {
+		prompt, streamErr := run(t, true, "Hello {{.Nickname}}.")
+
+		// GOAL: the message must not be generated with a half-rendered prompt.
+		assert.Empty(t, prompt)
+		assert.Contains(t, streamErr, app_errors.ErrValidation.Error())
+		assert.Contains(t, streamErr, "Nickname")
+	})
+}
+
+// TestChatService_HandleNewMessage_ResponseCache verifies that the answer to a
+// deterministic request is replayed for an identical request without calling
+// the LLM, and that other requests are always generated.
+func TestChatService_HandleNewMessage_ResponseCache(t *testing.T) {
+	ctx := context.Background()
+	seed, zero, warm := 42, float32(0), float32(0.7)
+	deterministic := &llm.RequestOptions{Seed: &seed, Temperature: &zero}
+
+	// send runs one exchange; `generated` reports whether the LLM was called.
+	send := func(t *testing.T, chatService *service.ChatService, mocks Mocks, content string, options *llm.RequestOptions, generated bool) string {
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("support_model", "global-model")
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{{ID: "u1", Role: "user", Content: content}}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		if generated {
+			mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+				Return(nil).
+				Run(func(args mock.Arguments) {
+					outChan := args.Get(2).(chan<- llm.StreamResponse)
+					outChan <- llm.StreamResponse{Content: "<think>Hmm.</think>Answer to "}
+					outChan <- llm.StreamResponse{Content: content, Done: true, Stats: &llm.GenerationStats{EvalCount: 3}}
+					close(outChan)
+				}).Once()
+		}
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: content, Options: options}, streamChan)
+		var answer string
+		for chunk := range streamChan {
+			require.Empty(t, chunk.Error)
+			answer += chunk.Content
+		}
+		return answer
+	}
+
+	t.Run("Identical deterministic request is served from the cache", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{ResponseCacheSize: 10})
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		first := send(t, chatService, mocks, "Hi", deterministic, true)
+		// ASSERT: The mock allows a single GenerateStream call, so a second
+		// call to the LLM would fail the test.
+		second := send(t, chatService, mocks, "Hi", deterministic, false)
+
+		assert.Equal(t, "Answer to Hi", first)
+		assert.Equal(t, first, second, "reasoning must be split from the cached answer, too")
+	})
+
+	t.Run("Non-deterministic requests are not cached", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{ResponseCacheSize: 10})
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		for _, options := range []*llm.RequestOptions{nil, {Seed: &seed}, {Seed: &seed, Temperature: &warm}, {Temperature: &zero}} {
+			send(t, chatService, mocks, "Hi", options, true)
+			send(t, chatService, mocks, "Hi", options, true)
+		}
+	})
+
+	t.Run("Least recently used answers are evicted", func(t *testing.T) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{ResponseCacheSize: 1})
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		send(t, chatService, mocks, "Hi", deterministic, true)
+		send(t, chatService, mocks, "Bye", deterministic, true)
+		send(t, chatService, mocks, "Hi", deterministic, true)
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		send(t, chatService, mocks, "Hi", deterministic, true)
+		send(t, chatService, mocks, "Hi", deterministic, true)
+	})
+}
+
+// TestChatService_HandleNewMessage_Reasoning verifies that <think> output is kept out
+// of the answer, stored in the metadata and only streamed when requested.
+func TestChatService_HandleNewMessage_Reasoning(t *testing.T) {
+	ctx := context.Background()
+
+	run := func(t *testing.T, showSetting string, showRequest *bool) ([]model.StreamResponse, *model.Message) {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		rows := sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("show_reasoning", showSetting)
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		var stored *model.Message
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				if msg := args.Get(1).(*model.Message); msg.Role == "assistant" {
+					stored = msg
+				}
+			}).Twice()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "<thi"}
+				outChan <- llm.StreamResponse{Content: "nk>Consider it.</th"}
+				outChan <- llm.StreamResponse{Content: "ink>\n\nAnswer"}
+				outChan <- llm.StreamResponse{Content: "!", Done: true, Stats: &llm.GenerationStats{EvalCount: 7}}
+				close(outChan)
+			}).Once()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi", ShowReasoning: showRequest}, streamChan)
+		var chunks []model.StreamResponse
+		for chunk := range streamChan {
+			chunks = append(chunks, chunk)
+		}
+		return chunks, stored
+	}
+
+	collect := func(chunks []model.StreamResponse) (content, reasoning string) {
+		for _, c := range chunks {
+			content += c.Content
+			reasoning += c.Reasoning
+		}
+		return content, reasoning
+	}
+
+	t.Run("Hidden by default, but stored", func(t *testing.T) {
+		chunks, stored := run(t, "false", nil)
+
+		content, reasoning := collect(chunks)
+		assert.Equal(t, "Answer!", content)
+		assert.Empty(t, reasoning)
+		assert.True(t, chunks[len(chunks)-1].Done)
+
+		require.NotNil(t, stored)
+		assert.Equal(t, "Answer!", stored.Content)
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(stored.Metadata, &metadata))
+		assert.Equal(t, "Consider it.", metadata["reasoning"])
+		assert.EqualValues(t, 7, metadata["eval_count"])
+	})
+
+	t.Run("Shown when enabled in the settings", func(t *testing.T) {
+		chunks, _ := run(t, "true", nil)
+		_, reasoning := collect(chunks)
+		assert.Equal(t, "Consider it.", reasoning)
+	})
+
+	t.Run("Request flag overrides the settings", func(t *testing.T) {
+		hide := false
+		chunks, _ := run(t, "true", &hide)
+		_, reasoning := collect(chunks)
+		assert.Empty(t, reasoning)
+	})
+}
+
+// TestChatService_HandleNewMessage_ContentFilter verifies that blocked prompts never
+// reach the model and that blocked answers are neither confirmed nor saved.
+func TestChatService_HandleNewMessage_ContentFilter(t *testing.T) {
+	ctx := context.Background()
+	filter := service.NewBannedSubstringFilter([]string{"forbidden"})
+
+	setup := func(t *testing.T, filterResponses bool) (*service.ChatService, Mocks) {
+		chatService, mocks := setupChatServiceWithConfig(t, service.ChatServiceConfig{ContentFilter: filter, FilterResponses: filterResponses})
+		t.Cleanup(func() { _ = mocks.db.Close() })
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model"))
+		return chatService, mocks
+	}
+	// expectGeneration sets up an exchange in "chat1" in which the model answers
+	// with the given text, and returns the messages saved by it.
+	expectGeneration := func(mocks Mocks, answer string) *[]*model.Message {
+		var saved []*model.Message
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+			Return(nil).
+			Run(func(args mock.Arguments) { saved = append(saved, args.Get(1).(*model.Message)) })
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: answer}
+				outChan <- llm.StreamResponse{Done: true}
+				close(outChan)
+			}).Once()
+		return &saved
+	}
+	collect := func(streamChan chan model.StreamResponse) []model.StreamResponse {
+		var chunks []model.StreamResponse
+		for chunk := range streamChan {
+			if chunk.Phase == "" {
+				chunks = append(chunks, chunk)
+			}
+		}
+		return chunks
+	}
+
+	t.Run("Allowed content is generated", func(t *testing.T) {
+		chatService, mocks := setup(t, true)
+		saved := expectGeneration(mocks, "A harmless answer")
+
+		streamChan := make(chan model.StreamResponse, 10)
+		go chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello"}, streamChan)
+		chunks := collect(streamChan)
+
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "A harmless answer", chunks[0].Content)
+		assert.True(t, chunks[1].Done)
+		assert.Len(t, *saved, 2)
+	})
+
+	t.Run("Blocked prompt skips the model", func(t *testing.T) {
+		chatService, mocks := setup(t, false)
+
+		streamChan := make(chan model.StreamResponse, 10)
+		go chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Something FORBIDDEN"}, streamChan)
+		chunks := collect(streamChan)
+
+		require.Len(t, chunks, 1)
+		assert.Contains(t, chunks[0].Error, "content policy")
+		mocks.llm.AssertNotCalled(t, "GenerateStream", mock.Anything, mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "AddMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Blocked answer is not saved", func(t *testing.T) {
+		chatService, mocks := setup(t, true)
+		saved := expectGeneration(mocks, "A forbidden answer")
+
+		streamChan := make(chan model.StreamResponse, 10)
+		go chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hello"}, streamChan)
+		chunks := collect(streamChan)
+
+		last := chunks[len(chunks)-1]
+		assert.Contains(t, last.Error, "content policy")
+		for _, c := range chunks {
+			assert.False(t, c.Done, "the final chunk must be held back")
+		}
+		require.Len(t, *saved, 1)
+		assert.Equal(t, "user", (*saved)[0].Role)
+	})
+}
+
+// TestChatService_RegenerateMessage_Reasoning verifies that a regenerated answer is
+// split the same way as a new one: reasoning goes to the metadata and, when
+// requested, to the separate `reasoning` field of the stream.
+//
+// WHY: The regeneration tests run on the memory backend, as a mocked `BeginTx`
+// would have to return a live `*sql.Tx`, which the race detector flags when the
+// mock formats it.
+func TestChatService_RegenerateMessage_Reasoning(t *testing.T) {
+	ctx := context.Background()
+	provider := mock_llm.NewMockLLMProvider(t)
+	chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+
+	// ARRANGE
+	seedAnsweredChat(t, repo)
+	expectStream(provider, "m1",
+		llm.StreamResponse{Content: "<think>Try again</thi"},
+		llm.StreamResponse{Content: "nk>Better answer", Done: true})
+
+	// ACT
+	show := true
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Model: "m1", ShowReasoning: &show}, ch)
+	})
+	var reasoning string
+	for _, event := range events {
+		reasoning += event.Reasoning
+	}
+
+	// ASSERT
+	assert.Equal(t, "Better answer", streamedContent(t, events))
+	assert.Equal(t, "Try again", reasoning)
+	active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	stored := active[1]
+	assert.NotEqual(t, "a1", stored.ID)
+	assert.Equal(t, "Better answer", stored.Content)
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(stored.Metadata, &metadata))
+	assert.Equal(t, "Try again", metadata["reasoning"])
+}
+
+// TestChatService_RegenerateMessage_AnnouncesBranch verifies that the first event of
+// a regeneration carries the ID of the new answer and of the answer it replaces,
+// before any content is streamed.
+func TestChatService_RegenerateMessage_AnnouncesBranch(t *testing.T) {
+	ctx := context.Background()
+	provider := mock_llm.NewMockLLMProvider(t)
+	chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+
+	// ARRANGE
+	seedAnsweredChat(t, repo)
+	expectStream(provider, "m1", llm.StreamResponse{Content: "Better answer", Done: true})
+
+	// ACT
+	events := collectStream(func(ch chan<- model.StreamResponse) {
+		chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Model: "m1"}, ch)
+	})
+
+	// ASSERT: The first event only announces the new branch.
+	require.Len(t, events, 2)
+	first := events[0]
+	assert.Equal(t, "chat1", first.ChatID)
+	assert.Equal(t, "a1", first.ReplacedMessageID)
+	assert.NotEmpty(t, first.MessageID)
+	assert.Empty(t, first.Content)
+	assert.False(t, first.Done)
+	// WHY: The announced ID must be the one the answer is stored with.
+	active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, first.MessageID, active[1].ID)
+	assert.Equal(t, "Better answer", events[1].Content)
+	assert.Empty(t, events[1].MessageID)
+}
+
+// TestChatService_ContinueMessage verifies that the partial answer is sent as the
+// last message and that the new content is appended to the same message.
+func TestChatService_ContinueMessage(t *testing.T) {
+	ctx := context.Background()
+
+	// setup stores a chat whose answer stopped early and makes the provider
+	// continue it with the given chunks. The returned request is filled in once
+	// the provider is called.
+	setup := func(t *testing.T, chunks ...llm.StreamResponse) (*service.ChatService, repository.Repository, *llm.GenerateRequest) {
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+		now := time.Now().UTC()
+		answerModel, u1 := "qwen3:8b", "user1"
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Test", CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: u1, Role: "user", Content: "Count to five", Timestamp: now}, "chat1"))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "answer", ParentID: &u1, Role: "assistant", Content: "1, 2, 3", Model: &answerModel, Timestamp: now, Metadata: json.RawMessage(`{"reasoning": "Easy."}`)}, "chat1"))
+
+		sent := &llm.GenerateRequest{}
+		provider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				*sent = *args.Get(1).(*llm.GenerateRequest)
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				for _, chunk := range chunks {
+					outChan <- chunk
+				}
+				close(outChan)
+			}).Maybe()
+		return chatService, repo, sent
+	}
+
+	t.Run("Appends to the latest answer", func(t *testing.T) {
+		chatService, repo, sent := setup(t,
+			llm.StreamResponse{Content: ", 4"},
+			llm.StreamResponse{Content: ", 5", Done: true, Stats: &llm.GenerationStats{EvalCount: 4}})
+
+		// ACT
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "answer", &service.ContinueMessageRequest{}, ch)
+		})
+
+		// ASSERT: The model that wrote the answer continues it from the partial answer.
+		assert.Equal(t, "qwen3:8b", sent.Model)
+		last := sent.Messages[len(sent.Messages)-1]
+		assert.Equal(t, llm.Message{Role: "assistant", Content: "1, 2, 3"}, last)
+		// Only the new content is streamed, but the whole answer is stored.
+		assert.Equal(t, ", 4, 5", streamedContent(t, events))
+		stored, err := repo.GetMessageByID(ctx, "answer")
+		require.NoError(t, err)
+		assert.Equal(t, "1, 2, 3, 4, 5", stored.Content)
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(stored.Metadata, &metadata))
+		assert.Equal(t, "Easy.", metadata["reasoning"])
+		assert.EqualValues(t, 4, metadata["eval_count"])
+	})
+
+	t.Run("Warns about invalid JSON before the final chunk", func(t *testing.T) {
+		chatService, _, _ := setup(t, llm.StreamResponse{Content: ", 4, 5", Done: true})
+
+		// ACT
+		format := "json"
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "answer", &service.ContinueMessageRequest{Options: &llm.RequestOptions{Format: &format}}, ch)
+		})
+
+		// ASSERT: The client learns about the invalid answer before it is done.
+		require.GreaterOrEqual(t, len(events), 2)
+		assert.Equal(t, "The response is not valid JSON", events[len(events)-2].Warning)
+		assert.True(t, events[len(events)-1].Done)
+	})
+
+	t.Run("Rejects a message that is not the latest answer", func(t *testing.T) {
+		chatService, _, _ := setup(t)
+
+		// ACT: The user message is the second to last one.
+		events := collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.ContinueMessage(ctx, "chat1", "user1", &service.ContinueMessageRequest{}, ch)
+		})
+
+		// ASSERT
+		require.Len(t, events, 1)
+		assert.Equal(t, "Only the latest assistant message of a chat can be continued", events[0].Error)
+	})
+}
+
+// TestChatService_RecordsGenerationOptions verifies that the options sent to the
+// model are stored in the metadata of the new assistant message.
+func TestChatService_RecordsGenerationOptions(t *testing.T) {
+	ctx := context.Background()
+	temperature := float32(1.3)
+	numCtx := 8192
+	options := &llm.RequestOptions{Temperature: &temperature, NumCtx: &numCtx}
+
+	streamAnswer := func(args mock.Arguments) {
+		outChan := args.Get(2).(chan<- llm.StreamResponse)
+		outChan <- llm.StreamResponse{Content: "Answer", Done: true, Stats: &llm.GenerationStats{EvalCount: 3}}
+		close(outChan)
+	}
+	decode := func(t *testing.T, msg *model.Message) map[string]any {
+		require.NotNil(t, msg)
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(msg.Metadata, &metadata))
+		return metadata
+	}
+
+	newMessage := func(t *testing.T, options *llm.RequestOptions) *model.Message {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "global-model"))
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		var stored *model.Message
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				if msg := args.Get(1).(*model.Message); msg.Role == "assistant" {
+					stored = msg
+				}
+			}).Twice()
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(streamAnswer).Once()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi", Options: options}, streamChan)
+		for range streamChan {
+		}
+		return stored
+	}
+
+	t.Run("New message", func(t *testing.T) {
+		metadata := decode(t, newMessage(t, options))
+
+		assert.EqualValues(t, 3, metadata["eval_count"])
+		assert.Equal(t, map[string]any{"temperature": 1.3, "num_ctx": float64(8192)}, metadata["options"])
+	})
+
+	t.Run("New message without options", func(t *testing.T) {
+		// WHY: Nil options must be left out, not stored as `null`.
+		metadata := decode(t, newMessage(t, nil))
+
+		assert.EqualValues(t, 3, metadata["eval_count"])
+		assert.NotContains(t, metadata, "options")
+	})
+
+	t.Run("Regeneration records the override", func(t *testing.T) {
+		provider := mock_llm.NewMockLLMProvider(t)
+		chatService, repo := setupMemoryChatService(t, provider, service.ChatServiceConfig{})
+
+		// ARRANGE
+		seedAnsweredChat(t, repo)
+		provider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(streamAnswer).Once()
+
+		// ACT
+		streamedContent(t, collectStream(func(ch chan<- model.StreamResponse) {
+			chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{Options: options}, ch)
+		}))
+
+		// ASSERT
+		active, err := repo.GetActiveMessagesByChatID(ctx, "chat1")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		stored := &active[1]
+		metadata := decode(t, stored)
+		assert.Equal(t, map[string]any{"temperature": 1.3, "num_ctx": float64(8192)}, metadata["options"])
+		assert.Equal(t, "u1", *stored.ParentID)
+	})
+}
+
+// TestMergeOptions verifies the precedence of the option layers: request over
+// model defaults over global defaults.
+func TestMergeOptions(t *testing.T) {
+	f := func(v float32) *float32 { return &v }
+	i := func(v int) *int { return &v }
+
+	t.Run("Each option comes from the highest layer that sets it", func(t *testing.T) {
+		global := &llm.RequestOptions{Temperature: f(0.1), NumCtx: i(4096), Stop: []string{"END"}}
+		modelDefaults := &llm.RequestOptions{Temperature: f(0.5), TopK: i(20)}
+		request := &llm.RequestOptions{Temperature: f(0.9)}
+
+		merged := service.MergeOptions(global, modelDefaults, request)
+
+		assert.Equal(t, &llm.RequestOptions{Temperature: f(0.9), NumCtx: i(4096), TopK: i(20), Stop: []string{"END"}}, merged)
+		// WHY: The layers are shared between requests and must not be modified.
+		assert.Equal(t, f(0.1), global.Temperature)
+		assert.Nil(t, global.TopK)
+	})
+
+	t.Run("Missing layers are skipped", func(t *testing.T) {
+		merged := service.MergeOptions(nil, &llm.RequestOptions{TopK: i(20)}, nil)
+
+		assert.Equal(t, &llm.RequestOptions{TopK: i(20)}, merged)
+	})
+
+	t.Run("No layers", func(t *testing.T) {
+		assert.Nil(t, service.MergeOptions(nil, nil, nil))
+	})
+}
+
+// TestChatService_DefaultOptions verifies that the `default_options` setting
+// applies to messages that do not set an option, and that the options of a
+// request take precedence.
+func TestChatService_DefaultOptions(t *testing.T) {
+	ctx := context.Background()
+
+	generate := func(t *testing.T, options *llm.RequestOptions) *llm.RequestOptions {
+		chatService, mocks := setupChatService(t)
+		t.Cleanup(func() { _ = mocks.db.Close() })
+
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+				AddRow("main_model", "global-model").
+				AddRow("default_options", `{"temperature":0.7,"num_ctx":4096}`))
+		mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+		mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+		mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+		mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+		mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+		var sent *llm.RequestOptions
+		mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*llm.GenerateRequest).Options
+			outChan := args.Get(2).(chan<- llm.StreamResponse)
+			outChan <- llm.StreamResponse{Content: "Answer", Done: true}
+			close(outChan)
+		}).Once()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi", Options: options}, streamChan)
+		for range streamChan {
+		}
+		require.NotNil(t, sent)
+		return sent
+	}
+
+	t.Run("Defaults apply when the request omits options", func(t *testing.T) {
+		sent := generate(t, nil)
+
+		assert.Equal(t, float32(0.7), *sent.Temperature)
+		assert.Equal(t, 4096, *sent.NumCtx)
+	})
+
+	t.Run("Request options override the defaults", func(t *testing.T) {
+		temperature := float32(0.2)
+		sent := generate(t, &llm.RequestOptions{Temperature: &temperature})
+
+		assert.Equal(t, float32(0.2), *sent.Temperature)
+		assert.Equal(t, 4096, *sent.NumCtx, "options the request does not set keep their default")
+	})
+}
+
+// TestChatService_ModelDefaults verifies that a new message is generated with the
+// stored defaults of its model, merged with the global defaults and the options
+// of the request.
+func TestChatService_ModelDefaults(t *testing.T) {
+	ctx := context.Background()
+	chatService, mocks := setupChatService(t)
+	defer func() { _ = mocks.db.Close() }()
+
+	// ARRANGE: Global defaults set num_ctx and a temperature, the model's defaults
+	// override the temperature and add top_k, the request overrides top_k.
+	mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "global-model").
+			AddRow("default_options", `{"temperature":0.1,"num_ctx":4096}`))
+	mocks.modelDefaults.Unset()
+	mocks.repo.On("GetModelDefaults", ctx, "global-model").
+		Return(&model.ModelDefaults{Model: "global-model", Options: json.RawMessage(`{"temperature":0.5,"top_k":20}`)}, nil).Once()
+	mocks.repo.On("GetChat", ctx, "chat1").Return(&model.Chat{ID: "chat1", Title: "Chat"}, nil).Once()
+	mocks.repo.On("GetLastActiveMessage", ctx, "chat1").Return(&model.Message{ID: "prev"}, nil).Once()
+	mocks.repo.On("GetActiveMessagesByChatID", ctx, "chat1").Return([]model.Message{}, nil).Once()
+	mocks.repo.On("GetAttachmentsByMessageIDs", ctx, mock.Anything).Return(nil, nil).Once()
+	mocks.repo.On("AddMessage", ctx, mock.AnythingOfType("*model.Message"), "chat1").Return(nil).Twice()
+	var sent *llm.RequestOptions
+	mocks.llm.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(*llm.GenerateRequest).Options
+		outChan := args.Get(2).(chan<- llm.StreamResponse)
+		outChan <- llm.StreamResponse{Content: "Answer", Done: true}
+		close(outChan)
+	}).Once()
+
+	// ACT
+	topK := 40
+	streamChan := make(chan model.StreamResponse, 10)
+	chatService.HandleNewMessage(ctx, &service.CreateMessageRequest{ChatID: "chat1", Content: "Hi", Options: &llm.RequestOptions{TopK: &topK}}, streamChan)
+	for range streamChan {
+	}
+
+	// ASSERT
+	require.NotNil(t, sent)
+	require.NotNil(t, sent.Temperature)
+	assert.Equal(t, float32(0.5), *sent.Temperature)
+	assert.Equal(t, 4096, *sent.NumCtx)
+	assert.Equal(t, 40, *sent.TopK)
+}
+
+// TestChatService_EstimateTokens_PromptTemplate verifies that a message created
+// from a prompt template uses the rendered template as its content.
+func TestChatService_EstimateTokens_PromptTemplate(t *testing.T) {
+	ctx := context.Background()
+	prompt := &model.Prompt{ID: "p1", Body: "Translate to {{lang}}:"}
+
+	t.Run("Success - Rendered prompt is followed by the content", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetPrompt", ctx, "p1").Return(prompt, nil).Once()
+		mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+				AddRow("system_prompt", "").
+				AddRow("main_model", "test-model").
+				AddRow("support_model", "support-model"))
+
+		req := &service.CreateMessageRequest{PromptID: "p1", Variables: map[string]string{"lang": "German"}, Content: "Good morning"}
+		_, err := chatService.EstimateTokens(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Translate to German:\n\nGood morning", req.Content)
+	})
+
+	t.Run("Failure - Missing variable", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetPrompt", ctx, "p1").Return(prompt, nil).Once()
+
+		_, err := chatService.EstimateTokens(ctx, &service.CreateMessageRequest{PromptID: "p1"})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+
+	t.Run("Failure - Unknown prompt", func(t *testing.T) {
+		chatService, mocks := setupChatService(t)
+		defer func() { _ = mocks.db.Close() }()
+		mocks.repo.On("GetPrompt", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := chatService.EstimateTokens(ctx, &service.CreateMessageRequest{PromptID: "missing", Content: "hi"})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+}
+
+// TestChatService_CloneChat verifies that a clone copies the active branch of a
+// chat and is independent of the original.
+//
+// WHY: Unlike most service tests, this one runs against a real SQLite database,
+// since independence is a property of the stored rows rather than of the calls made.
+func TestChatService_CloneChat(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.InitDB(filepath.Join(t.TempDir(), "clone.db"), database.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	repo := repository.NewSQLiteRepository(db)
+	chatService := service.NewChatService(repo, mock_llm.NewMockLLMProvider(t), nil, nil, service.ChatServiceConfig{})
+
+	// ARRANGE: "q1" has an active answer "a1" and an inactive, regenerated "a1-old".
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "orig", Title: "Trip", Model: "m1", SystemPrompt: "Be brief.", CreatedAt: base, UpdatedAt: base}))
+	q1, a1Old, a1 := "q1", "a1-old", "a1"
+	messages := []*model.Message{
+		{ID: q1, Role: "user", Content: "Where to?", Timestamp: base,
+			Attachments: []model.Attachment{{ID: "img", MimeType: "image/png", SizeBytes: 3, Data: []byte("png"), CreatedAt: base}}},
+		{ID: a1Old, ParentID: &q1, Role: "assistant", Content: "Rome.", Timestamp: base.Add(time.Second)},
+		{ID: a1, ParentID: &q1, Role: "assistant", Content: "Paris.", Timestamp: base.Add(2 * time.Second), Metadata: json.RawMessage(`{"eval_count":3}`)},
+		{ID: "q2", ParentID: &a1, Role: "user", Content: "Why?", Timestamp: base.Add(3 * time.Second)},
+	}
+	for _, msg := range messages {
+		require.NoError(t, repo.AddMessage(ctx, msg, "orig"))
+	}
+	_, err = db.Exec("UPDATE messages SET is_active = FALSE WHERE id = ?", a1Old)
+	require.NoError(t, err)
+
+	// ACT
+	clone, err := chatService.CloneChat(ctx, "orig")
+
+	// ASSERT: Only the active branch is copied, with new IDs and the same links.
+	require.NoError(t, err)
+	assert.NotEqual(t, "orig", clone.ID)
+	assert.Equal(t, "Copy of Trip", clone.Title)
+	assert.Equal(t, "m1", clone.Model)
+	assert.Equal(t, "Be brief.", clone.SystemPrompt)
+
+	copied, err := repo.GetAllMessagesByChatID(ctx, clone.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, copied, 3)
+	assert.Equal(t, []string{"Where to?", "Paris.", "Why?"}, []string{copied[0].Content, copied[1].Content, copied[2].Content})
+	assert.Nil(t, copied[0].ParentID)
+	assert.Equal(t, copied[0].ID, *copied[1].ParentID)
+	assert.Equal(t, copied[1].ID, *copied[2].ParentID)
+	assert.JSONEq(t, `{"eval_count":3}`, string(copied[1].Metadata))
+	for _, msg := range copied {
+		assert.NotContains(t, []string{q1, a1, "q2"}, msg.ID)
+	}
+	attachments, err := repo.GetAttachmentsByMessageIDs(ctx, []string{copied[0].ID})
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.NotEqual(t, "img", attachments[0].ID)
+	assert.Equal(t, []byte("png"), attachments[0].Data)
+
+	// ACT: Edit and then delete the clone.
+	require.NoError(t, chatService.UpdateChatTitle(ctx, clone.ID, "Edited"))
+	lastID := copied[2].ID
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "extra", ParentID: &lastID, Role: "assistant", Content: "Because.", Timestamp: time.Now().UTC()}, clone.ID))
+	deleted, err := chatService.DeleteChats(ctx, []string{clone.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	// ASSERT: The original is untouched.
+	original, err := repo.GetChat(ctx, "orig")
+	require.NoError(t, err)
+	assert.Equal(t, "Trip", original.Title)
+	all, err := repo.GetAllMessagesByChatID(ctx, "orig", nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+	attachments, err = repo.GetAttachmentsByMessageIDs(ctx, []string{q1})
+	require.NoError(t, err)
+	assert.Len(t, attachments, 1)
+
+	_, err = chatService.CloneChat(ctx, "missing")
+	assert.ErrorIs(t, err, app_errors.ErrNotFound)
+}
+
+// TestChatService_GetChatTree_AfterRegeneration verifies on a real database
+// that the tree of a regenerated chat holds both answers as siblings, with only
+// the new one active.
+func TestChatService_GetChatTree_AfterRegeneration(t *testing.T) {
+	// ARRANGE: A chat with one exchange.
+	ctx := context.Background()
+	db, err := database.InitDB(filepath.Join(t.TempDir(), "tree.db"), database.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec("INSERT INTO settings (key, value) VALUES ('main_model', 'm1'), ('support_model', 'm1')")
+	require.NoError(t, err)
+	repo := repository.NewSQLiteRepository(db)
+	llmProvider := mock_llm.NewMockLLMProvider(t)
+	chatService := service.NewChatService(repo, llmProvider, service.NewSettingsService(db, llmProvider, nil), nil, service.ChatServiceConfig{})
+	now := time.Now().UTC()
+	q1 := "q1"
+	require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Chat", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: q1, Role: "user", Content: "Hi", Timestamp: now}, "chat1"))
+	require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &q1, Role: "assistant", Content: "Hello!", Timestamp: now}, "chat1"))
+	llmProvider.On("ListModels", mock.Anything).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "m1"}}}, nil).Maybe()
+	llmProvider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			outChan := args.Get(2).(chan<- llm.StreamResponse)
+			outChan <- llm.StreamResponse{Content: "Hi there!", Done: true}
+			close(outChan)
+		}).Once()
+
+	// ACT
+	streamChan := make(chan model.StreamResponse, 10)
+	chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{}, streamChan)
+	var newID string
+	for event := range streamChan {
+		require.Empty(t, event.Error)
+		if event.MessageID != "" {
+			newID = event.MessageID
+		}
+	}
+	tree, err := chatService.GetChatTree(ctx, "chat1", model.ChatTreeOptions{Nested: true})
+
+	// ASSERT
+	require.NoError(t, err)
+	require.Len(t, tree.Roots, 1)
+	assert.Equal(t, "q1", tree.Roots[0].ID)
+	answers := tree.Roots[0].Children
+	require.Len(t, answers, 2)
+	assert.Equal(t, "a1", answers[0].ID)
+	assert.False(t, answers[0].IsActive, "the original answer is an inactive branch")
+	assert.Equal(t, newID, answers[1].ID)
+	assert.True(t, answers[1].IsActive)
+	assert.Equal(t, "Hi there!", answers[1].Content)
+}
+
+// TestChatService_RegenerateMessage_Interrupted verifies that an interrupted
+// regeneration leaves the chat as it was: the original answer stays active and
+// no partial answer is stored.
+func TestChatService_RegenerateMessage_Interrupted(t *testing.T) {
+	setup := func(t *testing.T) (*service.ChatService, repository.Repository, *mock_llm.MockLLMProvider) {
+		ctx := context.Background()
+		db, err := database.InitDB(filepath.Join(t.TempDir(), "regenerate.db"), database.Config{})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		_, err = db.Exec("INSERT INTO settings (key, value) VALUES ('main_model', 'm1'), ('support_model', 'm1')")
+		require.NoError(t, err)
+
+		repo := repository.NewSQLiteRepository(db)
+		llmProvider := mock_llm.NewMockLLMProvider(t)
+		chatService := service.NewChatService(repo, llmProvider, service.NewSettingsService(db, llmProvider, nil), nil, service.ChatServiceConfig{})
+
+		now := time.Now().UTC()
+		q1 := "q1"
+		require.NoError(t, repo.CreateChat(ctx, &model.Chat{ID: "chat1", Title: "Chat", CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: q1, Role: "user", Content: "Hi", Timestamp: now}, "chat1"))
+		require.NoError(t, repo.AddMessage(ctx, &model.Message{ID: "a1", ParentID: &q1, Role: "assistant", Content: "Hello!", Timestamp: now}, "chat1"))
+		return chatService, repo, llmProvider
+	}
+
+	assertOriginalBranch := func(t *testing.T, repo repository.Repository) {
+		active, err := repo.GetActiveMessagesByChatID(context.Background(), "chat1")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		assert.Equal(t, "a1", active[1].ID, "the original answer must be active again")
+		all, err := repo.GetAllMessagesByChatID(context.Background(), "chat1", nil)
+		require.NoError(t, err)
+		assert.Len(t, all, 2, "no partial answer may be stored")
+	}
+
+	t.Run("Client disconnects mid-stream", func(t *testing.T) {
+		chatService, repo, llmProvider := setup(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// ARRANGE: The client goes away after the first chunk; the provider then
+		// ends the stream without a final chunk, like an aborted HTTP request.
+		llmProvider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hel"}
+				cancel()
+				close(outChan)
+			}).Once()
+
+		// ACT
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.RegenerateMessage(ctx, "chat1", "a1", &service.RegenerateMessageRequest{}, streamChan)
+		for range streamChan {
+		}
+
+		// ASSERT
+		assertOriginalBranch(t, repo)
+	})
+
+	t.Run("Stream ends without a final chunk", func(t *testing.T) {
+		chatService, repo, llmProvider := setup(t)
+		llmProvider.On("GenerateStream", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				outChan := args.Get(2).(chan<- llm.StreamResponse)
+				outChan <- llm.StreamResponse{Content: "Hel"}
+				close(outChan)
+			}).Once()
+
+		streamChan := make(chan model.StreamResponse, 10)
+		chatService.RegenerateMessage(context.Background(), "chat1", "a1", &service.RegenerateMessageRequest{}, streamChan)
+		var lastErr string
+		for chunk := range streamChan {
+			lastErr = chunk.Error
+		}
+
+		assert.NotEmpty(t, lastErr, "the client must learn that the answer is incomplete")
+		assertOriginalBranch(t, repo)
+	})
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// ContentFilter checks text against the content policy of a deployment. It is
+// applied to the user's content before generation and, if enabled, to the
+// model's final response.
+type ContentFilter interface {
+	// Check returns an error if the text must not be processed. The error is
+	// shown to the client, so it should not repeat the offending text.
+	Check(ctx context.Context, text string) error
+}
+
+// NoopContentFilter allows all content. It is the default filter.
+type NoopContentFilter struct{}
+
+// Check implements ContentFilter.
+func (NoopContentFilter) Check(context.Context, string) error { return nil }
+
+// BannedSubstringFilter blocks text that contains any of a list of substrings,
+// ignoring case.
+type BannedSubstringFilter struct {
+	substrings []string
+}
+
+// NewBannedSubstringFilter creates a filter for the given substrings. Blank
+// entries are ignored.
+func NewBannedSubstringFilter(substrings []string) *BannedSubstringFilter {
+	f := &BannedSubstringFilter{}
+	for _, s := range substrings {
+		if s = strings.TrimSpace(s); s != "" {
+			f.substrings = append(f.substrings, strings.ToLower(s))
+		}
+	}
+	return f
+}
+
+// Check implements ContentFilter.
+func (f *BannedSubstringFilter) Check(_ context.Context, text string) error {
+	lower := strings.ToLower(text)
+	for _, s := range f.substrings {
+		if strings.Contains(lower, s) {
+			return fmt.Errorf("%w: content is not allowed by the content policy", app_errors.ErrValidation)
+		}
+	}
+	return nil
+}
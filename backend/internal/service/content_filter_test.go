@@ -0,0 +1,22 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/service"
+)
+
+// TestBannedSubstringFilter verifies case-insensitive matching and that blank
+// entries don't block everything.
+func TestBannedSubstringFilter(t *testing.T) {
+	ctx := context.Background()
+	filter := service.NewBannedSubstringFilter([]string{" Secret Project ", "", "  "})
+
+	assert.NoError(t, filter.Check(ctx, "Tell me about the weather"))
+	assert.ErrorIs(t, filter.Check(ctx, "What is the SECRET project about?"), app_errors.ErrValidation)
+	assert.NoError(t, service.NoopContentFilter{}.Check(ctx, "anything"))
+}
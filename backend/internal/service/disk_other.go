@@ -0,0 +1,10 @@
+//go:build !unix
+
+package service
+
+import "errors"
+
+// freeDiskSpace is not implemented on this platform.
+func freeDiskSpace(string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
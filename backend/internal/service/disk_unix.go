@@ -0,0 +1,16 @@
+//go:build unix
+
+package service
+
+import "syscall"
+
+// freeDiskSpace returns the number of bytes available to unprivileged users on
+// the file system that contains path.
+func freeDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	// The type of Bsize differs between platforms.
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
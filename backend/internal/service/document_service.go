@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// DocumentService implements retrieval-augmented generation (RAG): it splits
+// uploaded documents into chunks, embeds them and finds the chunks most similar
+// to a query.
+type DocumentService struct {
+	repo repository.Repository
+	llm  llm.LLMProvider
+	cfg  DocumentServiceConfig
+}
+
+// DocumentServiceConfig holds the static options of the DocumentService.
+// Zero values are replaced with sensible defaults by `NewDocumentService`.
+type DocumentServiceConfig struct {
+	// EmbeddingModel is used for new collections that don't specify their own.
+	EmbeddingModel string
+	// ChunkSize is the maximum length of a chunk in runes.
+	ChunkSize int
+	// ChunkOverlap is the number of runes shared by consecutive chunks, so that
+	// a sentence cut at a chunk border is still found as a whole.
+	ChunkOverlap int
+	// TopK is the number of chunks retrieved per query.
+	TopK int
+}
+
+const (
+	defaultEmbeddingModel = "nomic-embed-text"
+	defaultChunkSize      = 1000
+	defaultChunkOverlap   = 200
+	defaultRetrievalTopK  = 4
+	// embeddingBatchSize bounds the number of chunks embedded per provider call.
+	embeddingBatchSize = 32
+)
+
+// CreateCollectionRequest is the DTO for creating a document collection.
+type CreateCollectionRequest struct {
+	Name           string `json:"name" validate:"required,max=100" example:"Project docs"`
+	EmbeddingModel string `json:"embedding_model,omitempty" example:"nomic-embed-text"`
+}
+
+// UploadDocumentRequest is the DTO for adding a plain-text document to a collection.
+type UploadDocumentRequest struct {
+	Name    string `json:"name" validate:"required,max=255" example:"README.md"`
+	Content string `json:"content" validate:"required" example:"Flow AI is a self-hosted chat UI for Ollama."`
+}
+
+// NewDocumentService creates a new instance of DocumentService.
+func NewDocumentService(repo repository.Repository, llm llm.LLMProvider, cfg DocumentServiceConfig) *DocumentService {
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = defaultEmbeddingModel
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = defaultChunkSize
+	}
+	if cfg.ChunkOverlap < 0 || cfg.ChunkOverlap >= cfg.ChunkSize {
+		cfg.ChunkOverlap = min(defaultChunkOverlap, cfg.ChunkSize/2)
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = defaultRetrievalTopK
+	}
+	return &DocumentService{repo: repo, llm: llm, cfg: cfg}
+}
+
+// CreateCollection creates an empty document collection.
+func (s *DocumentService) CreateCollection(ctx context.Context, req *CreateCollectionRequest) (*model.Collection, error) {
+	embeddingModel := req.EmbeddingModel
+	if embeddingModel == "" {
+		embeddingModel = s.cfg.EmbeddingModel
+	}
+	collection := &model.Collection{
+		ID:             uuid.NewString(),
+		Name:           strings.TrimSpace(req.Name),
+		EmbeddingModel: embeddingModel,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.repo.CreateCollection(ctx, collection); err != nil {
+		return nil, fmt.Errorf("could not create collection: %w", err)
+	}
+	return collection, nil
+}
+
+// ListCollections returns all document collections.
+func (s *DocumentService) ListCollections(ctx context.Context) ([]*model.Collection, error) {
+	return s.repo.GetCollections(ctx)
+}
+
+// ListDocuments returns the documents of a collection.
+func (s *DocumentService) ListDocuments(ctx context.Context, collectionID string) ([]*model.Document, error) {
+	if _, err := s.getCollection(ctx, collectionID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDocuments(ctx, collectionID)
+}
+
+// UploadDocument chunks a document, embeds every chunk with the collection's
+// embedding model and stores the result.
+func (s *DocumentService) UploadDocument(ctx context.Context, collectionID string, req *UploadDocumentRequest) (*model.Document, error) {
+	collection, err := s.getCollection(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := chunkText(req.Content, s.cfg.ChunkSize, s.cfg.ChunkOverlap)
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("%w: document has no text content", app_errors.ErrValidation)
+	}
+
+	chunks := make([]model.DocumentChunk, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		batch := texts[start:min(start+embeddingBatchSize, len(texts))]
+		resp, err := s.llm.Embeddings(ctx, &llm.EmbeddingsRequest{Model: collection.EmbeddingModel, Input: batch})
+		if err != nil {
+			return nil, fmt.Errorf("could not embed document: %w", err)
+		}
+		for i, text := range batch {
+			chunks = append(chunks, model.DocumentChunk{
+				ID:        uuid.NewString(),
+				Index:     start + i,
+				Content:   text,
+				Embedding: resp.Embeddings[i],
+			})
+		}
+	}
+
+	document := &model.Document{
+		ID:           uuid.NewString(),
+		CollectionID: collectionID,
+		Name:         req.Name,
+		ChunkCount:   len(chunks),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.repo.AddDocument(ctx, document, chunks); err != nil {
+		return nil, fmt.Errorf("could not store document: %w", err)
+	}
+	slog.Info("Indexed document", "collection_id", collectionID, "document_id", document.ID, "chunks", len(chunks))
+	return document, nil
+}
+
+// Retrieve returns the chunks of a collection most similar to the query, best match first.
+func (s *DocumentService) Retrieve(ctx context.Context, collectionID, query string) ([]model.DocumentChunk, error) {
+	collection, err := s.getCollection(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := s.repo.GetChunksByCollectionID(ctx, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load chunks: %w", err)
+	}
+	return s.rank(ctx, collection, chunks, query)
+}
+
+// RetrieveTx is like Retrieve, but reads the collection within the open
+// transaction tx, so that a caller holding a transaction does not need a
+// second connection.
+func (s *DocumentService) RetrieveTx(ctx context.Context, tx *sql.Tx, collectionID, query string) ([]model.DocumentChunk, error) {
+	collection, err := s.repo.GetCollectionTx(ctx, tx, collectionID)
+	if err != nil {
+		return nil, collectionError(collectionID, err)
+	}
+
+	chunks, err := s.repo.GetChunksByCollectionIDTx(ctx, tx, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load chunks: %w", err)
+	}
+	return s.rank(ctx, collection, chunks, query)
+}
+
+// rank returns the `TopK` chunks most similar to the query, best match first.
+func (s *DocumentService) rank(ctx context.Context, collection *model.Collection, chunks []model.DocumentChunk, query string) ([]model.DocumentChunk, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	resp, err := s.llm.Embeddings(ctx, &llm.EmbeddingsRequest{Model: collection.EmbeddingModel, Input: []string{query}})
+	if err != nil {
+		return nil, fmt.Errorf("could not embed query: %w", err)
+	}
+	queryVector := resp.Embeddings[0]
+
+	scores := make([]float64, len(chunks))
+	for i, c := range chunks {
+		scores[i] = cosineSimilarity(queryVector, c.Embedding)
+	}
+	indices := make([]int, len(chunks))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool { return scores[indices[a]] > scores[indices[b]] })
+
+	k := min(s.cfg.TopK, len(chunks))
+	top := make([]model.DocumentChunk, k)
+	for i := range k {
+		top[i] = chunks[indices[i]]
+	}
+	return top, nil
+}
+
+func (s *DocumentService) getCollection(ctx context.Context, collectionID string) (*model.Collection, error) {
+	collection, err := s.repo.GetCollection(ctx, collectionID)
+	if err != nil {
+		return nil, collectionError(collectionID, err)
+	}
+	return collection, nil
+}
+
+// collectionError wraps an error of loading a collection, reporting a missing
+// one as `ErrNotFound`.
+func collectionError(collectionID string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: collection with id %s", app_errors.ErrNotFound, collectionID)
+	}
+	return fmt.Errorf("could not get collection: %w", err)
+}
+
+// chunkText splits text into chunks of at most `size` runes that overlap by
+// `overlap` runes. Chunk borders are moved back to whitespace where possible so
+// that words are not cut in half.
+func chunkText(text string, size, overlap int) []string {
+	runes := []rune(text)
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := min(start+size, len(runes))
+		if end < len(runes) {
+			// Look for a word boundary in the second half of the window.
+			for i := end; i > start+size/2; i-- {
+				if unicode.IsSpace(runes[i]) {
+					end = i
+					break
+				}
+			}
+		}
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+		start = max(end-overlap, start+1)
+	}
+	return chunks
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0 if
+// they are not comparable.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
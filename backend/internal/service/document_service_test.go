@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+	mock_llm "flow-ai/backend/internal/llm/mocks"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+	mock_repo "flow-ai/backend/internal/repository/mocks"
+	"flow-ai/backend/internal/service"
+)
+
+func setupDocumentService(t *testing.T, cfg service.DocumentServiceConfig) (*service.DocumentService, *mock_repo.MockRepository, *mock_llm.MockLLMProvider) {
+	repo := mock_repo.NewMockRepository(t)
+	provider := mock_llm.NewMockLLMProvider(t)
+	return service.NewDocumentService(repo, provider, cfg), repo, provider
+}
+
+// TestChunkText verifies that documents are split into overlapping chunks without
+// cutting words in half.
+func TestChunkText(t *testing.T) {
+	t.Run("Short text is a single chunk", func(t *testing.T) {
+		assert.Equal(t, []string{"hello world"}, service.ChunkText("  hello world  ", 100, 10))
+	})
+
+	t.Run("Whitespace-only text has no chunks", func(t *testing.T) {
+		assert.Empty(t, service.ChunkText(" \n\t ", 100, 10))
+	})
+
+	t.Run("Long text is split at word boundaries with overlap", func(t *testing.T) {
+		text := strings.Repeat("word ", 50)
+		chunks := service.ChunkText(text, 40, 10)
+
+		require.Greater(t, len(chunks), 1)
+		for _, c := range chunks {
+			assert.LessOrEqual(t, utf8.RuneCountInString(c), 40)
+			for _, w := range strings.Fields(c) {
+				assert.Equal(t, "word", w, "words must not be cut")
+			}
+		}
+	})
+}
+
+// TestDocumentService_UploadDocument tests chunking, embedding and storing a document.
+func TestDocumentService_UploadDocument(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Chunks are embedded with the collection's model", func(t *testing.T) {
+		svc, repo, provider := setupDocumentService(t, service.DocumentServiceConfig{ChunkSize: 20, ChunkOverlap: 5})
+		repo.On("GetCollection", ctx, "col1").Return(&model.Collection{ID: "col1", EmbeddingModel: "embed-model"}, nil).Once()
+		provider.On("Embeddings", ctx, mock.MatchedBy(func(r *llm.EmbeddingsRequest) bool {
+			return r.Model == "embed-model"
+		})).Return(func(_ context.Context, r *llm.EmbeddingsRequest) (*llm.EmbeddingsResponse, error) {
+			vectors := make([][]float32, len(r.Input))
+			for i := range vectors {
+				vectors[i] = []float32{float32(i), 1}
+			}
+			return &llm.EmbeddingsResponse{Embeddings: vectors}, nil
+		}).Once()
+		var stored []model.DocumentChunk
+		repo.On("AddDocument", ctx, mock.AnythingOfType("*model.Document"), mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) { stored = args.Get(2).([]model.DocumentChunk) }).Once()
+
+		// ACT
+		doc, err := svc.UploadDocument(ctx, "col1", &service.UploadDocumentRequest{
+			Name:    "notes.txt",
+			Content: "The quick brown fox jumps over the lazy dog again and again.",
+		})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "col1", doc.CollectionID)
+		assert.Equal(t, len(stored), doc.ChunkCount)
+		require.Greater(t, len(stored), 1)
+		for i, c := range stored {
+			assert.Equal(t, i, c.Index)
+			assert.Equal(t, []float32{float32(i), 1}, c.Embedding)
+		}
+	})
+
+	t.Run("Failure - Collection not found", func(t *testing.T) {
+		svc, repo, _ := setupDocumentService(t, service.DocumentServiceConfig{})
+		repo.On("GetCollection", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := svc.UploadDocument(ctx, "missing", &service.UploadDocumentRequest{Name: "a", Content: "b"})
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
+
+// TestDocumentService_Retrieve verifies that chunks are ranked by similarity to the query.
+func TestDocumentService_Retrieve(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, provider := setupDocumentService(t, service.DocumentServiceConfig{TopK: 2})
+	repo.On("GetCollection", ctx, "col1").Return(&model.Collection{ID: "col1", EmbeddingModel: "embed-model"}, nil).Once()
+	repo.On("GetChunksByCollectionID", ctx, "col1").Return([]model.DocumentChunk{
+		{ID: "far", Embedding: []float32{-1, 0}},
+		{ID: "close", Embedding: []float32{0.9, 0.1}},
+		{ID: "exact", Embedding: []float32{2, 0}},
+	}, nil).Once()
+	provider.On("Embeddings", ctx, &llm.EmbeddingsRequest{Model: "embed-model", Input: []string{"query"}}).
+		Return(&llm.EmbeddingsResponse{Embeddings: [][]float32{{1, 0}}}, nil).Once()
+
+	// ACT
+	chunks, err := svc.Retrieve(ctx, "col1", "query")
+
+	// ASSERT: Only the top two are returned, best first.
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "exact", chunks[0].ID)
+	assert.Equal(t, "close", chunks[1].ID)
+}
@@ -0,0 +1,52 @@
+package service
+
+import "context"
+
+// PullSubscribers exposes the number of callers attached to the in-flight pull
+// of a model to the black-box tests in `service_test`.
+func (s *ModelService) PullSubscribers(name string) int {
+	s.pulls.mu.Lock()
+	defer s.pulls.mu.Unlock()
+
+	job, ok := s.pulls.jobs[name]
+	if !ok {
+		return 0
+	}
+	return len(job.subscribers)
+}
+
+// SanitizeTitle exposes `sanitizeTitle` to the black-box tests.
+var SanitizeTitle = sanitizeTitle
+
+// GenerateTitle exposes a single title generation attempt to the black-box tests.
+func (s *ChatService) GenerateTitle(ctx context.Context, chatID, supportModel, promptTemplate, userQuery, assistantResponse string) (string, error) {
+	return s.generateTitle(ctx, chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+}
+
+// GenerateTitleWithRetry exposes the retrying title generation to the black-box tests.
+func (s *ChatService) GenerateTitleWithRetry(ctx context.Context, chatID, supportModel, promptTemplate, userQuery, assistantResponse string) {
+	s.generateTitleWithRetry(ctx, chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+}
+
+// StartTitleJob exposes the tracked background title generation to the black-box tests.
+func (s *ChatService) StartTitleJob(chatID, supportModel, promptTemplate, userQuery, assistantResponse string) {
+	s.startTitleJob(chatID, supportModel, promptTemplate, userQuery, assistantResponse)
+}
+
+// ChunkText exposes `chunkText` to the black-box tests.
+var ChunkText = chunkText
+
+// ReasoningSplitter exposes the streaming <think> parser to the black-box tests.
+type ReasoningSplitter = reasoningSplitter
+
+// RenderPromptTemplate exposes `renderPromptTemplate` to the black-box tests.
+var RenderPromptTemplate = renderPromptTemplate
+
+// MergeOptions exposes `mergeOptions` to the black-box tests.
+var MergeOptions = mergeOptions
+
+// LockBackups holds the lock of a running backup until the returned function is called.
+func (s *BackupService) LockBackups() (unlock func()) {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// healthCheckTimeout bounds each dependency check, so that a hanging dependency
+// is reported as down instead of hanging the request.
+const healthCheckTimeout = 3 * time.Second
+
+// Values of Health.Status.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// DependencyHealth is the result of checking a single dependency.
+type DependencyHealth struct {
+	Healthy bool `json:"healthy" example:"true"`
+	// LatencyMs is the time the check took in milliseconds.
+	LatencyMs float64 `json:"latency_ms" example:"2.4"`
+	// Error is why the check failed.
+	Error string `json:"error,omitempty" example:"LLM backend unreachable: dial tcp 127.0.0.1:11434: connect: connection refused"`
+}
+
+// Health reports whether the backend can reach its dependencies.
+type Health struct {
+	// Status is "ok" if all dependencies are healthy and "degraded" otherwise.
+	Status   string           `json:"status" example:"ok" enums:"ok,degraded"`
+	Database DependencyHealth `json:"database"`
+	LLM      DependencyHealth `json:"llm"`
+}
+
+// HealthService checks the database and the LLM provider, on request and in
+// the background.
+type HealthService struct {
+	db  *sql.DB
+	llm llm.LLMProvider
+
+	mu sync.Mutex
+	// llmHealthy is the state of the LLM provider at the last background check;
+	// nil before the first one.
+	llmHealthy *bool
+}
+
+// NewHealthService creates a new instance of HealthService.
+func NewHealthService(db *sql.DB, llmProvider llm.LLMProvider) *HealthService {
+	return &HealthService{db: db, llm: llmProvider}
+}
+
+// Check pings the database and lists the models of the LLM provider. A
+// dependency that is down is a status, not an error.
+func (s *HealthService) Check(ctx context.Context) *Health {
+	health := &Health{
+		Database: checkDependency(ctx, s.db.PingContext),
+		LLM:      s.checkLLM(ctx),
+	}
+	health.Status = HealthOK
+	if !health.Database.Healthy || !health.LLM.Healthy {
+		health.Status = HealthDegraded
+	}
+	return health
+}
+
+func (s *HealthService) checkLLM(ctx context.Context) DependencyHealth {
+	return checkDependency(ctx, func(ctx context.Context) error {
+		_, err := s.llm.ListModels(ctx)
+		return err
+	})
+}
+
+func checkDependency(ctx context.Context, check func(ctx context.Context) error) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	started := time.Now()
+	err := check(ctx)
+	result := DependencyHealth{Healthy: err == nil, LatencyMs: milliseconds(time.Since(started).Nanoseconds())}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Watch checks the LLM provider every interval until ctx is cancelled and logs
+// when it becomes unreachable or reachable again; 0 disables the watcher.
+func (s *HealthService) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.watchOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *HealthService) watchOnce(ctx context.Context) {
+	result := s.checkLLM(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.mu.Lock()
+	previous := s.llmHealthy
+	s.llmHealthy = &result.Healthy
+	s.mu.Unlock()
+
+	switch {
+	case !result.Healthy && (previous == nil || *previous):
+		slog.Warn("LLM provider is unreachable", "error", result.Error)
+	case result.Healthy && previous != nil && !*previous:
+		slog.Info("LLM provider is reachable again", "latency_ms", result.LatencyMs)
+	}
+}
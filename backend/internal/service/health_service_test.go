@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/llm"
+	mock_llm "flow-ai/backend/internal/llm/mocks"
+	"flow-ai/backend/internal/service"
+)
+
+// TestHealthService_Check verifies that each dependency is reported on its own
+// and that a single failing dependency degrades the overall status.
+func TestHealthService_Check(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (sqlmock.Sqlmock, *mock_llm.MockLLMProvider, *service.HealthService) {
+		db, mockDB, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		llmProvider := mock_llm.NewMockLLMProvider(t)
+		return mockDB, llmProvider, service.NewHealthService(db, llmProvider)
+	}
+
+	t.Run("Success - All dependencies are healthy", func(t *testing.T) {
+		// ARRANGE
+		mockDB, llmProvider, healthService := setup(t)
+		mockDB.ExpectPing()
+		llmProvider.On("ListModels", mock.Anything).Return(&llm.ListModelsResponse{}, nil).Once()
+
+		// ACT
+		health := healthService.Check(ctx)
+
+		// ASSERT
+		assert.Equal(t, service.HealthOK, health.Status)
+		assert.True(t, health.Database.Healthy)
+		assert.True(t, health.LLM.Healthy)
+		assert.Empty(t, health.LLM.Error)
+		require.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Degraded - The LLM provider is unreachable", func(t *testing.T) {
+		mockDB, llmProvider, healthService := setup(t)
+		mockDB.ExpectPing()
+		llmProvider.On("ListModels", mock.Anything).
+			Return(nil, fmt.Errorf("%w: connection refused", llm.ErrUnreachable)).Once()
+
+		health := healthService.Check(ctx)
+
+		assert.Equal(t, service.HealthDegraded, health.Status)
+		assert.True(t, health.Database.Healthy)
+		assert.False(t, health.LLM.Healthy)
+		assert.Equal(t, "LLM backend unreachable: connection refused", health.LLM.Error)
+	})
+
+	t.Run("Degraded - The database is down", func(t *testing.T) {
+		mockDB, llmProvider, healthService := setup(t)
+		mockDB.ExpectPing().WillReturnError(errors.New("disk I/O error"))
+		llmProvider.On("ListModels", mock.Anything).Return(&llm.ListModelsResponse{}, nil).Once()
+
+		health := healthService.Check(ctx)
+
+		assert.Equal(t, service.HealthDegraded, health.Status)
+		assert.False(t, health.Database.Healthy)
+		assert.Equal(t, "disk I/O error", health.Database.Error)
+		assert.True(t, health.LLM.Healthy)
+	})
+}
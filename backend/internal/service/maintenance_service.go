@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// MaintenanceResult describes a database maintenance run.
+type MaintenanceResult struct {
+	Checkpoint model.CheckpointResult `json:"checkpoint"`
+	// Duration is the time the run took, e.g. "12.5ms".
+	Duration string `json:"duration" example:"12.5ms"`
+}
+
+// MaintenanceService runs database maintenance tasks on demand.
+type MaintenanceService struct {
+	repo repository.Repository
+}
+
+// NewMaintenanceService creates a new instance of MaintenanceService.
+func NewMaintenanceService(repo repository.Repository) *MaintenanceService {
+	return &MaintenanceService{repo: repo}
+}
+
+// Run checkpoints and truncates the WAL file, then refreshes the query planner
+// statistics.
+func (s *MaintenanceService) Run(ctx context.Context) (*MaintenanceResult, error) {
+	startedAt := time.Now()
+	checkpoint, err := s.repo.Checkpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not checkpoint the database: %w", err)
+	}
+	if err := s.repo.Optimize(ctx); err != nil {
+		return nil, fmt.Errorf("could not optimize the database: %w", err)
+	}
+
+	duration := time.Since(startedAt)
+	slog.Info("Database maintenance finished", "busy", checkpoint.Busy, "log_frames", checkpoint.LogFrames, "checkpointed_frames", checkpoint.CheckpointedFrames, "duration", duration)
+	return &MaintenanceResult{Checkpoint: *checkpoint, Duration: duration.String()}, nil
+}
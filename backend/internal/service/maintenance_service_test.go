@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/model"
+	mock_repo "flow-ai/backend/internal/repository/mocks"
+	"flow-ai/backend/internal/service"
+)
+
+// TestMaintenanceService_Run verifies that a maintenance run checkpoints and then
+// optimizes the database, and stops at the first failure.
+func TestMaintenanceService_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		// ARRANGE
+		repo := mock_repo.NewMockRepository(t)
+		repo.On("Checkpoint", ctx).Return(&model.CheckpointResult{LogFrames: 42, CheckpointedFrames: 42}, nil).Once()
+		repo.On("Optimize", ctx).Return(nil).Once()
+
+		// ACT
+		result, err := service.NewMaintenanceService(repo).Run(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, model.CheckpointResult{LogFrames: 42, CheckpointedFrames: 42}, result.Checkpoint)
+		assert.NotEmpty(t, result.Duration)
+	})
+
+	t.Run("Failure - Checkpoint fails", func(t *testing.T) {
+		repo := mock_repo.NewMockRepository(t)
+		repo.On("Checkpoint", ctx).Return(nil, errors.New("database is locked")).Once()
+
+		_, err := service.NewMaintenanceService(repo).Run(ctx)
+
+		assert.ErrorContains(t, err, "database is locked")
+		repo.AssertNotCalled(t, "Optimize", ctx)
+	})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// modelAliasesKey is the settings key that holds the model aliases as a JSON object.
+const modelAliasesKey = "model_aliases"
+
+// aliasNamePattern restricts alias names to short identifiers such as "fast" or
+// "smart-v2". A colon is not allowed, so that an alias never looks like a model tag.
+var aliasNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// ModelAlias maps a short name to a local model, so that clients can refer to
+// "fast" or "smart" and the underlying model can be swapped in one place.
+type ModelAlias struct {
+	Name string `json:"name" example:"smart"`
+	// Model is the model tag, or another alias, the name resolves to.
+	Model string `json:"model" validate:"required" example:"qwen3:14b"`
+}
+
+// resolveAlias follows a chain of aliases and returns the model it ends at. A
+// name that is not an alias is returned unchanged. Loops are rejected when aliases
+// are saved; should one exist anyway, resolution stops before it repeats.
+func resolveAlias(aliases map[string]string, name string) string {
+	seen := map[string]bool{}
+	for !seen[name] {
+		target, ok := aliases[name]
+		if !ok {
+			return name
+		}
+		seen[name] = true
+		name = target
+	}
+	return name
+}
+
+// ListModelAliases returns all model aliases, sorted by name.
+func (s *SettingsService) ListModelAliases(ctx context.Context) ([]ModelAlias, error) {
+	aliases, err := s.getAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load model aliases: %w", err)
+	}
+	out := make([]ModelAlias, 0, len(aliases))
+	for name, target := range aliases {
+		out = append(out, ModelAlias{Name: name, Model: target})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// SetModelAlias creates or updates an alias. The alias may point to another
+// alias, but the chain must not loop and must end at a local model.
+func (s *SettingsService) SetModelAlias(ctx context.Context, alias *ModelAlias) error {
+	if !aliasNamePattern.MatchString(alias.Name) {
+		return fmt.Errorf("%w: alias name must be 1-64 letters, digits, '.', '_' or '-'", app_errors.ErrValidation)
+	}
+
+	s.aliasMu.Lock()
+	defer s.aliasMu.Unlock()
+
+	aliases, err := s.getAliases(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load model aliases: %w", err)
+	}
+	aliases[alias.Name] = alias.Model
+	target := resolveAlias(aliases, alias.Name)
+	if _, isAlias := aliases[target]; isAlias {
+		// Resolution only ends at an alias if the chain loops.
+		return fmt.Errorf("%w: alias '%s' would create a loop", app_errors.ErrValidation, alias.Name)
+	}
+
+	modelNames, err := s.modelNames(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(modelNames, target) {
+		return fmt.Errorf("%w: model '%s' is not available in Ollama", app_errors.ErrValidation, target)
+	}
+
+	return s.saveAliases(ctx, aliases)
+}
+
+// DeleteModelAlias removes an alias. An alias that is still used by the settings
+// or by another alias cannot be deleted.
+func (s *SettingsService) DeleteModelAlias(ctx context.Context, name string) error {
+	s.aliasMu.Lock()
+	defer s.aliasMu.Unlock()
+
+	aliases, err := s.getAliases(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load model aliases: %w", err)
+	}
+	if _, ok := aliases[name]; !ok {
+		return fmt.Errorf("%w: model alias '%s' not found", app_errors.ErrNotFound, name)
+	}
+	for other, target := range aliases {
+		if target == name {
+			return fmt.Errorf("%w: alias '%s' is used by alias '%s'", app_errors.ErrConflict, name, other)
+		}
+	}
+	settings, err := s.getFromDB(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load settings: %w", err)
+	}
+	if settings.MainModel == name || settings.SupportModel == name {
+		return fmt.Errorf("%w: alias '%s' is used by the settings", app_errors.ErrConflict, name)
+	}
+
+	delete(aliases, name)
+	return s.saveAliases(ctx, aliases)
+}
+
+// getAliases loads the model aliases. A missing key means there are none.
+func (s *SettingsService) getAliases(ctx context.Context) (map[string]string, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM settings WHERE key = ?", modelAliasesKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseAliases(value), nil
+}
+
+func (s *SettingsService) saveAliases(ctx context.Context, aliases map[string]string) error {
+	value, err := json.Marshal(aliases)
+	if err != nil {
+		return fmt.Errorf("could not encode model aliases: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, "INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", modelAliasesKey, string(value))
+	if err != nil {
+		return fmt.Errorf("could not save model aliases: %w", err)
+	}
+	return nil
+}
+
+// parseAliases decodes the stored aliases. Malformed data is logged and ignored,
+// so that it cannot make the settings unreadable.
+func parseAliases(value string) map[string]string {
+	aliases := map[string]string{}
+	if value == "" {
+		return aliases
+	}
+	if err := json.Unmarshal([]byte(value), &aliases); err != nil {
+		slog.Error("Ignoring malformed model aliases", "error", err)
+		return map[string]string{}
+	}
+	return aliases
+}
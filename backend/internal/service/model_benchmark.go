@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/model"
+)
+
+// benchmarkPrompt is the fixed prompt of every benchmark run, so that results of
+// different models are comparable.
+const benchmarkPrompt = "Explain in a few sentences why the sky is blue."
+
+// defaultBenchmarkRuns is the number of runs if the request does not set it.
+const defaultBenchmarkRuns = 3
+
+// BenchmarkRequest is the DTO for benchmarking a model.
+type BenchmarkRequest struct {
+	// Runs is the number of generations; the result is the median over all of
+	// them. Defaults to 3.
+	Runs int `json:"runs" validate:"omitempty,min=1,max=10" example:"3"`
+}
+
+// Benchmark measures how fast a local model generates, by running a fixed short
+// prompt several times. Only one benchmark runs at a time, so that benchmarks do
+// not skew each other; a request waits for a running one to finish. The result
+// is stored as the model's last benchmark.
+func (s *ModelService) Benchmark(ctx context.Context, modelName string, req *BenchmarkRequest) (*model.ModelBenchmark, error) {
+	runs := req.Runs
+	if runs == 0 {
+		runs = defaultBenchmarkRuns
+	}
+
+	available, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list models: %w", err)
+	}
+	if !slices.ContainsFunc(available.Models, func(m llm.Model) bool { return m.Name == modelName }) {
+		return nil, fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, modelName)
+	}
+
+	select {
+	case s.benchmarkSlot <- struct{}{}:
+		defer func() { <-s.benchmarkSlot }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// A fixed seed, no randomness and a bounded answer keep the runs alike.
+	seed, temperature, numPredict := 42, float32(0), 128
+	options := &llm.RequestOptions{Seed: &seed, Temperature: &temperature, NumPredict: &numPredict}
+
+	tokensPerSecond := make([]float64, 0, runs)
+	loadMs := make([]float64, 0, runs)
+	firstTokenMs := make([]float64, 0, runs)
+	for i := range runs {
+		resp, err := s.llm.Generate(ctx, &llm.GenerateRequest{Model: modelName, Prompt: benchmarkPrompt, Options: options})
+		if err != nil {
+			return nil, fmt.Errorf("benchmark run %d failed: %w", i+1, err)
+		}
+		stats := resp.Stats
+		if stats == nil || stats.EvalCount == 0 || stats.EvalDuration <= 0 {
+			return nil, fmt.Errorf("benchmark run %d: the model reported no generation statistics", i+1)
+		}
+		tokensPerSecond = append(tokensPerSecond, float64(stats.EvalCount)/time.Duration(stats.EvalDuration).Seconds())
+		loadMs = append(loadMs, milliseconds(stats.LoadDuration))
+		// The first token is generated right after the model is loaded and the
+		// prompt is evaluated.
+		firstTokenMs = append(firstTokenMs, milliseconds(stats.LoadDuration+stats.PromptEvalDuration))
+	}
+
+	result := &model.ModelBenchmark{
+		Model:           modelName,
+		Runs:            runs,
+		TokensPerSecond: median(tokensPerSecond),
+		LoadMs:          median(loadMs),
+		FirstTokenMs:    median(firstTokenMs),
+		CreatedAt:       time.Now().UTC(),
+	}
+	if err := s.repo.SaveModelBenchmark(ctx, result); err != nil {
+		return nil, fmt.Errorf("could not save benchmark: %w", err)
+	}
+	slog.Info("Model benchmarked", "model", modelName, "runs", runs, "tokens_per_second", result.TokensPerSecond)
+	return result, nil
+}
+
+// benchmarkSpeeds returns the generation speed of every benchmarked model. The
+// speeds only add to the model list, so a failure is logged and ignored.
+func (s *ModelService) benchmarkSpeeds(ctx context.Context) map[string]float64 {
+	benchmarks, err := s.repo.GetModelBenchmarks(ctx)
+	if err != nil {
+		slog.Warn("Could not load model benchmarks", "error", err)
+		return nil
+	}
+	speeds := make(map[string]float64, len(benchmarks))
+	for _, b := range benchmarks {
+		speeds[b.Model] = b.TokensPerSecond
+	}
+	return speeds
+}
+
+func milliseconds(nanoseconds int64) float64 {
+	return float64(nanoseconds) / float64(time.Millisecond)
+}
+
+// median returns the median of a non-empty slice, which it sorts.
+func median(values []float64) float64 {
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
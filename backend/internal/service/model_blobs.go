@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+)
+
+// blobDigestPattern matches the digest Ollama identifies blobs by.
+var blobDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+func validateBlobDigest(digest string) error {
+	if !blobDigestPattern.MatchString(digest) {
+		return fmt.Errorf("%w: digest '%s' must be \"sha256:\" followed by 64 lowercase hex digits", app_errors.ErrValidation, digest)
+	}
+	return nil
+}
+
+// CheckBlob reports whether a file has been uploaded to Ollama as a blob.
+func (s *ModelService) CheckBlob(ctx context.Context, digest string) (bool, error) {
+	if err := validateBlobDigest(digest); err != nil {
+		return false, err
+	}
+	return s.llm.CheckBlob(ctx, digest)
+}
+
+// PushBlob uploads a file, such as a GGUF model, as a blob that a model can then
+// be created from. The content must match the digest.
+func (s *ModelService) PushBlob(ctx context.Context, digest string, r io.Reader) error {
+	if err := validateBlobDigest(digest); err != nil {
+		return err
+	}
+	if err := s.llm.PushBlob(ctx, digest, r); err != nil {
+		if errors.Is(err, llm.ErrInvalidBlob) {
+			return fmt.Errorf("%w: %s", app_errors.ErrValidation, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkFiles verifies that the blobs of a model to be created have been
+// uploaded, since Ollama would otherwise fail late with a less helpful error.
+func (s *ModelService) checkFiles(ctx context.Context, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		digest := files[name]
+		if err := validateBlobDigest(digest); err != nil {
+			return err
+		}
+		exists, err := s.llm.CheckBlob(ctx, digest)
+		if err != nil {
+			return fmt.Errorf("could not check blob of file '%s': %w", name, err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: file '%s' has not been uploaded, push blob %s first", app_errors.ErrValidation, name, digest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// Capabilities of a model, named as Ollama reports them.
+const (
+	capabilityCompletion = "completion"
+	capabilityVision     = "vision"
+	capabilityTools      = "tools"
+	capabilityEmbedding  = "embedding"
+)
+
+// capabilityCache remembers the capabilities of each local model, so that listing
+// the models does not ask Ollama about every one of them each time. An entry is
+// only valid for the digest it was looked up for, which catches a model that was
+// updated outside of this service.
+type capabilityCache struct {
+	mu      sync.Mutex
+	entries map[string]capabilityEntry
+}
+
+type capabilityEntry struct {
+	digest       string
+	capabilities []string
+}
+
+func newCapabilityCache() *capabilityCache {
+	return &capabilityCache{entries: make(map[string]capabilityEntry)}
+}
+
+func (c *capabilityCache) get(m llm.Model) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[m.Name]
+	if !ok || entry.digest != m.Digest {
+		return nil, false
+	}
+	return entry.capabilities, true
+}
+
+func (c *capabilityCache) put(m llm.Model, capabilities []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[m.Name] = capabilityEntry{digest: m.Digest, capabilities: capabilities}
+}
+
+// invalidate drops the entry of a model. A name without a tag also drops the
+// ":latest" tag it refers to.
+func (c *capabilityCache) invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, name)
+	if !strings.Contains(name, ":") {
+		delete(c.entries, name+":latest")
+	}
+}
+
+// capabilities returns the capabilities of a local model. A failed lookup is
+// logged and not cached, so that the next listing tries again.
+func (s *ModelService) capabilities(ctx context.Context, m llm.Model) []string {
+	if capabilities, ok := s.capabilityCache.get(m); ok {
+		return capabilities
+	}
+	info, err := s.llm.ShowModelInfo(ctx, &llm.ShowModelRequest{Name: m.Name})
+	if errors.Is(err, llm.ErrUnsupported) {
+		// The provider cannot describe its models; asking again will not help.
+		s.capabilityCache.put(m, nil)
+		return nil
+	}
+	if err != nil {
+		slog.Warn("Could not look up model capabilities", "model", m.Name, "error", err)
+		return nil
+	}
+	capabilities := info.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = inferCapabilities(info)
+	}
+	s.capabilityCache.put(m, capabilities)
+	return capabilities
+}
+
+// inferCapabilities guesses the capabilities of a model for versions of Ollama
+// that do not report them: embedding models are BERT-based, vision models carry
+// a CLIP projector, and models that support tools render them in their template.
+func inferCapabilities(info *llm.ModelInfo) []string {
+	families := append([]string{info.Details.Family}, info.Details.Families...)
+	if slices.ContainsFunc(families, func(f string) bool { return strings.HasSuffix(f, "bert") }) {
+		return []string{capabilityEmbedding}
+	}
+	capabilities := []string{capabilityCompletion}
+	if slices.ContainsFunc(families, func(f string) bool { return f == "clip" || f == "mllama" }) {
+		capabilities = append(capabilities, capabilityVision)
+	}
+	if strings.Contains(info.Template, ".Tools") {
+		capabilities = append(capabilities, capabilityTools)
+	}
+	return capabilities
+}
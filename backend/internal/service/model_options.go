@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// ModelDefaults are the default generation options of a model. They apply to every
+// request for the model and are overridden by the options of the request itself.
+type ModelDefaults struct {
+	Model string `json:"model" example:"qwen3:14b"`
+	// Options is empty if no defaults are stored for the model.
+	Options   llm.RequestOptions `json:"options"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty" example:"2025-09-08T14:05:00Z"`
+}
+
+// mergeOptions combines the layers of generation options: the options of the
+// request take precedence over the model's defaults, which take precedence over
+// the global defaults. Each option is taken from the highest layer that sets it.
+// It returns nil if no layer is given, so that Ollama's own defaults apply.
+func mergeOptions(global, modelDefaults, request *llm.RequestOptions) *llm.RequestOptions {
+	var merged *llm.RequestOptions
+	for _, layer := range []*llm.RequestOptions{global, modelDefaults, request} {
+		if layer == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &llm.RequestOptions{}
+		}
+		// Every option is a pointer or a slice, where nil means "not set".
+		dst := reflect.ValueOf(merged).Elem()
+		src := reflect.ValueOf(layer).Elem()
+		for i := range src.NumField() {
+			if field := src.Field(i); !field.IsNil() {
+				dst.Field(i).Set(field)
+			}
+		}
+	}
+	return merged
+}
+
+// GetDefaults returns the default options of a model. A model without stored
+// defaults has empty options.
+func (s *ModelService) GetDefaults(ctx context.Context, modelName string) (*ModelDefaults, error) {
+	stored, err := s.repo.GetModelDefaults(ctx, modelName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &ModelDefaults{Model: modelName}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get model defaults: %w", err)
+	}
+	defaults := &ModelDefaults{Model: modelName, UpdatedAt: &stored.UpdatedAt}
+	if err := json.Unmarshal(stored.Options, &defaults.Options); err != nil {
+		return nil, fmt.Errorf("could not decode model defaults: %w", err)
+	}
+	return defaults, nil
+}
+
+// SetDefaults replaces the default options of a local model. Empty options remove
+// all defaults.
+func (s *ModelService) SetDefaults(ctx context.Context, modelName string, opts *llm.RequestOptions) (*ModelDefaults, error) {
+	available, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list models: %w", err)
+	}
+	if !slices.ContainsFunc(available.Models, func(m llm.Model) bool { return m.Name == modelName }) {
+		return nil, fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, modelName)
+	}
+
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode model defaults: %w", err)
+	}
+	now := time.Now().UTC()
+	if err := s.repo.SaveModelDefaults(ctx, &model.ModelDefaults{Model: modelName, Options: encoded, UpdatedAt: now}); err != nil {
+		return nil, fmt.Errorf("could not save model defaults: %w", err)
+	}
+	slog.Info("Model defaults updated", "model", modelName)
+	return &ModelDefaults{Model: modelName, Options: *opts, UpdatedAt: &now}, nil
+}
+
+// modelDefaultOptions loads the default options of a model for a generation
+// request. Defaults are a convenience, so a failure is logged and generation
+// proceeds without them.
+func modelDefaultOptions(ctx context.Context, repo repository.Repository, modelName string) *llm.RequestOptions {
+	stored, err := repo.GetModelDefaults(ctx, modelName)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			slog.Warn("Could not load model defaults", "model", modelName, "error", err)
+		}
+		return nil
+	}
+	var opts llm.RequestOptions
+	if err := json.Unmarshal(stored.Options, &opts); err != nil {
+		slog.Warn("Ignoring malformed model defaults", "model", modelName, "error", err)
+		return nil
+	}
+	return &opts
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// preloadTimeout bounds how long loading a model may take. Large models on slow
+// disks take minutes to load.
+const preloadTimeout = 10 * time.Minute
+
+// States of a model preload.
+const (
+	PreloadLoading = "loading"
+	PreloadLoaded  = "loaded"
+	PreloadFailed  = "failed"
+)
+
+// PreloadStatus is the state of the last model preload.
+type PreloadStatus struct {
+	Model string `json:"model" example:"qwen3:8b"`
+	// State is "loading", "loaded" or "failed".
+	State     string    `json:"state" example:"loaded"`
+	Error     string    `json:"error,omitempty" example:"model requires more system memory"`
+	StartedAt time.Time `json:"started_at" example:"2025-09-08T14:05:00Z"`
+	// FinishedAt is unset while the model is loading.
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2025-09-08T14:05:12Z"`
+}
+
+// ModelPreloader loads a model into memory in the background, so that the first
+// chat with it does not wait for the model to load. A failed preload is logged
+// and reported in its status; the model is then loaded by the first request as usual.
+type ModelPreloader struct {
+	llm llm.LLMProvider
+
+	mu     sync.Mutex
+	status *PreloadStatus
+}
+
+// NewModelPreloader creates a new ModelPreloader.
+func NewModelPreloader(llmProvider llm.LLMProvider) *ModelPreloader {
+	return &ModelPreloader{llm: llmProvider}
+}
+
+// Preload starts loading a model and returns right away. keepAlive is how long
+// the model stays loaded; empty uses Ollama's default. The returned channel is
+// closed once the preload has finished.
+func (p *ModelPreloader) Preload(modelName, keepAlive string) <-chan struct{} {
+	started := time.Now().UTC()
+	loading := &PreloadStatus{Model: modelName, State: PreloadLoading, StartedAt: started}
+	p.mu.Lock()
+	p.status = loading
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), preloadTimeout)
+		defer cancel()
+
+		// A request without a prompt or messages only loads the model.
+		req := &llm.GenerateRequest{Model: modelName}
+		if keepAlive != "" {
+			k := llm.KeepAlive(keepAlive)
+			req.KeepAlive = &k
+		}
+		_, err := p.llm.Generate(ctx, req)
+
+		finished := time.Now().UTC()
+		status := &PreloadStatus{Model: modelName, State: PreloadLoaded, StartedAt: started, FinishedAt: &finished}
+		if err != nil {
+			slog.Warn("Could not preload model", "model", modelName, "error", err)
+			status.State = PreloadFailed
+			status.Error = err.Error()
+		} else {
+			slog.Info("Model preloaded", "model", modelName, "duration", finished.Sub(started))
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		// A newer preload, e.g. of another model, supersedes this one.
+		if p.status == loading {
+			p.status = status
+		}
+	}()
+	return done
+}
+
+// Status returns the state of the last preload, or nil if no model was preloaded.
+func (p *ModelPreloader) Status() *PreloadStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status == nil {
+		return nil
+	}
+	status := *p.status
+	return &status
+}
@@ -2,35 +2,354 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/llm"
+	"flow-ai/backend/internal/repository"
 )
 
 // ModelService handles the business logic for model management.
 type ModelService struct {
-	llm llm.LLMProvider
+	repo     repository.Repository
+	llm      llm.LLMProvider
+	registry llm.ModelRegistry
+	cfg      ModelServiceConfig
+	pulls    *pullRegistry
+
+	capabilityCache *capabilityCache
+	// benchmarkSlot holds a token while a benchmark runs, so that only one runs at a time.
+	benchmarkSlot chan struct{}
+
+	updatesMu sync.Mutex
+	updates   ModelUpdates
+}
+
+// ModelServiceConfig holds the static configuration of the ModelService.
+type ModelServiceConfig struct {
+	// OllamaURL is the configured address of Ollama, which is reported with its status.
+	OllamaURL string
+	// ModelsPath is a path on the volume that holds Ollama's models. If set, the
+	// free space of that volume is included in the storage summary.
+	ModelsPath string
+	// CancelOnDisconnect cancels a model pull once none of the callers streaming
+	// it is left. By default the pull finishes in the background.
+	CancelOnDisconnect bool
+	// UpdateCheckInterval is the time between two checks for model updates; 0
+	// disables the checks.
+	UpdateCheckInterval time.Duration
+	// Preloader is the preloader of the main model, whose state is reported with
+	// the running models. It may be nil.
+	Preloader *ModelPreloader
 }
 
 // NewModelService creates a new ModelService.
-func NewModelService(llmProvider llm.LLMProvider) *ModelService {
-	return &ModelService{llm: llmProvider}
+func NewModelService(repo repository.Repository, llmProvider llm.LLMProvider, registry llm.ModelRegistry, cfg ModelServiceConfig) *ModelService {
+	return &ModelService{
+		repo:            repo,
+		llm:             llmProvider,
+		registry:        registry,
+		cfg:             cfg,
+		pulls:           newPullRegistry(cfg.CancelOnDisconnect),
+		capabilityCache: newCapabilityCache(),
+		benchmarkSlot:   make(chan struct{}, 1),
+		updates:         ModelUpdates{Models: []ModelUpdate{}},
+	}
 }
 
-// List returns a list of all locally available models.
+// List returns a list of all locally available models with their capabilities,
+// the speed of their last benchmark and whether an update is available. The capabilities of a model are looked
+// up once and cached until the model is pulled, deleted, copied or created again.
 func (s *ModelService) List(ctx context.Context) (*llm.ListModelsResponse, error) {
-	return s.llm.ListModels(ctx)
+	list, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	speeds := s.benchmarkSpeeds(ctx)
+	for i := range list.Models {
+		list.Models[i].Capabilities = s.capabilities(ctx, list.Models[i])
+		if speed, ok := speeds[list.Models[i].Name]; ok {
+			list.Models[i].TokensPerSecond = &speed
+		}
+		list.Models[i].UpdateAvailable = s.updateAvailable(list.Models[i])
+	}
+	return list, nil
+}
+
+// SearchModelsRequest is the DTO for searching the public model library.
+type SearchModelsRequest struct {
+	// Query is matched against model names and descriptions; empty lists the
+	// most popular models.
+	Query string `validate:"max=100"`
+}
+
+// Search looks up models to pull in the public Ollama library. It returns
+// `ErrUnavailable` if the library cannot be reached.
+func (s *ModelService) Search(ctx context.Context, req *SearchModelsRequest) ([]llm.RegistryModel, error) {
+	models, err := s.registry.SearchModels(ctx, req.Query)
+	if err != nil {
+		if errors.Is(err, llm.ErrRegistryUnavailable) {
+			return nil, fmt.Errorf("%w: %s", app_errors.ErrUnavailable, err)
+		}
+		return nil, fmt.Errorf("could not search models: %w", err)
+	}
+	return models, nil
+}
+
+// ModelStorage summarizes the disk space used by local models.
+type ModelStorage struct {
+	// TotalBytes is the sum of the model sizes. Models that share layers are
+	// counted in full, so the actual usage on disk can be lower.
+	TotalBytes int64 `json:"total_bytes" example:"14753165312"`
+	// FreeBytes is the free space on the volume of the models. It is omitted if
+	// OLLAMA_MODELS_PATH is not configured or the space cannot be determined.
+	FreeBytes *uint64 `json:"free_bytes,omitempty" example:"52613349376"`
+	// Models lists the local models, largest first.
+	Models []ModelStorageEntry `json:"models"`
+}
+
+// ModelStorageEntry is the disk usage of a single model.
+type ModelStorageEntry struct {
+	Name string `json:"name" example:"qwen3:14b"`
+	Size int64  `json:"size" example:"9276198565"`
+}
+
+// Storage returns the disk usage of the local models, largest first, and the
+// free space left for new ones if it is known.
+func (s *ModelService) Storage(ctx context.Context) (*ModelStorage, error) {
+	list, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list models: %w", err)
+	}
+
+	storage := &ModelStorage{Models: make([]ModelStorageEntry, 0, len(list.Models))}
+	for _, m := range list.Models {
+		storage.TotalBytes += m.Size
+		storage.Models = append(storage.Models, ModelStorageEntry{Name: m.Name, Size: m.Size})
+	}
+	sort.SliceStable(storage.Models, func(i, j int) bool {
+		if storage.Models[i].Size != storage.Models[j].Size {
+			return storage.Models[i].Size > storage.Models[j].Size
+		}
+		return storage.Models[i].Name < storage.Models[j].Name
+	})
+
+	if s.cfg.ModelsPath != "" {
+		free, err := freeDiskSpace(s.cfg.ModelsPath)
+		if err != nil {
+			slog.Warn("Could not determine free disk space for models", "path", s.cfg.ModelsPath, "error", err)
+		} else {
+			storage.FreeBytes = &free
+		}
+	}
+	return storage, nil
+}
+
+// RunningModels lists the models currently loaded into memory, along with the
+// state of the last preload of the main model.
+type RunningModels struct {
+	Models []llm.RunningModel `json:"models"`
+	// Preload is unset if the main model was not preloaded.
+	Preload *PreloadStatus `json:"preload,omitempty"`
+}
+
+// ListRunning returns the models currently loaded into memory.
+func (s *ModelService) ListRunning(ctx context.Context) (*RunningModels, error) {
+	running, err := s.llm.RunningModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &RunningModels{Models: running.Models}
+	if s.cfg.Preloader != nil {
+		result.Preload = s.cfg.Preloader.Status()
+	}
+	return result, nil
 }
 
-// Pull downloads a model from a registry. It streams the progress.
+// Pull downloads a model from a registry and streams the progress to `ch`, which
+// is closed when the method returns.
+//
+// Concurrent pulls of the same model share a single provider download. A caller
+// that attaches to a running pull immediately receives the latest known status.
+// Cancelling `ctx` only detaches this caller: the download keeps running in the
+// background until it completes, `CancelPull` is called or the service is closed,
+// and a later call for the same model reattaches to it. With `CancelOnDisconnect`
+// the download is cancelled instead once the last caller has detached.
 func (s *ModelService) Pull(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error {
-	return s.llm.PullModel(ctx, req, ch)
+	defer close(ch)
+
+	sub := make(chan llm.PullStatus, pullSubscriberBuffer)
+	job, initial := s.pulls.subscribe(req, sub, s.pullModel)
+
+	var lastSent *llm.PullStatus
+	send := func(status llm.PullStatus) bool {
+		select {
+		case ch <- status:
+			lastSent = &status
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if initial != nil && !send(*initial) {
+		s.pulls.unsubscribe(job, sub)
+		return ctx.Err()
+	}
+
+	for {
+		select {
+		case status := <-sub:
+			if !send(status) {
+				s.pulls.unsubscribe(job, sub)
+				return ctx.Err()
+			}
+		case <-job.done:
+			// Flush whatever is still buffered, then make sure the final status was
+			// delivered even if intermediate updates were dropped for this caller.
+			for len(sub) > 0 {
+				if !send(<-sub) {
+					return ctx.Err()
+				}
+			}
+			if last := s.pulls.lastStatus(job); last != nil && (lastSent == nil || *lastSent != *last) {
+				if !send(*last) {
+					return ctx.Err()
+				}
+			}
+			return job.err
+		case <-ctx.Done():
+			s.pulls.unsubscribe(job, sub)
+			return ctx.Err()
+		}
+	}
+}
+
+// pullModel runs the download of a pull job. A pulled model may be a new version
+// with other capabilities, so they are looked up again, and a pending update of
+// the model is installed now.
+func (s *ModelService) pullModel(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error {
+	defer s.capabilityCache.invalidate(req.Name)
+	if err := s.llm.PullModel(ctx, req, ch); err != nil {
+		return err
+	}
+	s.forgetUpdate(req.Name)
+	return nil
+}
+
+// Close cancels every in-flight model pull and waits for the downloads to stop.
+// It is called on server shutdown.
+func (s *ModelService) Close() {
+	s.pulls.close()
+}
+
+// ListPulls returns the models that are currently being pulled with their latest
+// progress, so that a reconnecting client can resume showing it.
+func (s *ModelService) ListPulls(ctx context.Context) []PullJobStatus {
+	return s.pulls.list()
+}
+
+// CancelPullRequest is the DTO for aborting an in-flight model pull.
+type CancelPullRequest struct {
+	Name string `json:"name" validate:"required" example:"llama3:70b"`
+}
+
+// CancelPull aborts the in-flight pull of a model, including the download in
+// Ollama. Every client streaming that pull receives a final status of
+// "cancelled". It returns `ErrNotFound` if the model is not being pulled.
+func (s *ModelService) CancelPull(ctx context.Context, req *CancelPullRequest) error {
+	if !s.pulls.cancelPull(req.Name) {
+		return fmt.Errorf("%w: no pull in progress for model '%s'", app_errors.ErrNotFound, req.Name)
+	}
+	return nil
 }
 
 // Delete removes a local model.
 func (s *ModelService) Delete(ctx context.Context, req *llm.DeleteModelRequest) error {
+	defer s.capabilityCache.invalidate(req.Name)
 	return s.llm.DeleteModel(ctx, req)
 }
 
+// Create creates a custom model and streams the progress to `ch`, which is
+// closed when the method returns. The blobs of its `files` must have been
+// uploaded; a missing one is reported through the stream, like Ollama's errors.
+func (s *ModelService) Create(ctx context.Context, req *llm.CreateModelRequest, ch chan<- llm.PullStatus) error {
+	if err := s.checkFiles(ctx, req.Files); err != nil {
+		ch <- llm.PullStatus{Error: err.Error()}
+		close(ch)
+		return err
+	}
+	defer s.capabilityCache.invalidate(req.Name)
+	return s.llm.CreateModel(ctx, req, ch)
+}
+
+// Copy copies a local model under a new name.
+func (s *ModelService) Copy(ctx context.Context, req *llm.CopyModelRequest) error {
+	defer s.capabilityCache.invalidate(req.Destination)
+	if err := s.llm.CopyModel(ctx, req); err != nil {
+		if errors.Is(err, llm.ErrModelNotFound) {
+			return fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, req.Source)
+		}
+		return err
+	}
+	return nil
+}
+
 // Show retrieves detailed information about a model.
 func (s *ModelService) Show(ctx context.Context, req *llm.ShowModelRequest) (*llm.ModelInfo, error) {
-	return s.llm.ShowModelInfo(ctx, req)
+	info, err := s.llm.ShowModelInfo(ctx, req)
+	if errors.Is(err, llm.ErrModelNotFound) {
+		return nil, fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, req.Name)
+	}
+	return info, err
+}
+
+// EmbedRequest is the DTO for computing embedding vectors.
+type EmbedRequest struct {
+	Model string `json:"model" validate:"required" example:"nomic-embed-text"`
+	// Input is a batch of texts, each of which gets its own vector.
+	Input []string `json:"input" validate:"required,min=1,dive,required" example:"Why is the sky blue?"`
+}
+
+// Embed computes an embedding vector for each text of the input, in the same order.
+func (s *ModelService) Embed(ctx context.Context, req *EmbedRequest) (*llm.EmbeddingsResponse, error) {
+	resp, err := s.llm.Embeddings(ctx, &llm.EmbeddingsRequest{Model: req.Model, Input: req.Input})
+	if errors.Is(err, llm.ErrModelNotFound) {
+		return nil, fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, req.Model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not compute embeddings: %w", err)
+	}
+	return resp, nil
+}
+
+// CompleteRequest is the DTO for a fill-in-middle completion, e.g. of code
+// between the prompt and the suffix.
+type CompleteRequest struct {
+	Model  string `json:"model" validate:"required" example:"qwen2.5-coder:7b"`
+	Prompt string `json:"prompt" validate:"required" example:"def fib(n):"`
+	// Suffix is the text after the completion; empty completes the prompt.
+	Suffix  string              `json:"suffix,omitempty" example:"    return fib(n - 1) + fib(n - 2)"`
+	Options *llm.RequestOptions `json:"options,omitempty"`
+}
+
+// Complete generates the text between the prompt and the suffix of a request.
+// The model must support fill-in-middle for a suffix, which Ollama rejects
+// otherwise. The options of the request take precedence over the model's
+// defaults.
+func (s *ModelService) Complete(ctx context.Context, req *CompleteRequest) (*llm.GenerateResponse, error) {
+	options := mergeOptions(nil, modelDefaultOptions(ctx, s.repo, req.Model), req.Options)
+	resp, err := s.llm.Generate(ctx, &llm.GenerateRequest{Model: req.Model, Prompt: req.Prompt, Suffix: req.Suffix, Options: options})
+	if errors.Is(err, llm.ErrModelNotFound) {
+		return nil, fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, req.Model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not complete: %w", err)
+	}
+	return resp, nil
 }
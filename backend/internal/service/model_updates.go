@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/llm"
+)
+
+// ModelUpdate is the result of checking an installed model for a newer version.
+type ModelUpdate struct {
+	Model string `json:"model" example:"qwen3:8b"`
+	// LocalDigest is the digest of the installed version when it was checked.
+	LocalDigest string `json:"local_digest" example:"500a1f067a9f782620b40bee6f7b0c89e17ae61f686b92c24933e4ca4b2b8b41"`
+	// RemoteDigest is the digest of the version published in the registry.
+	RemoteDigest    string `json:"remote_digest,omitempty" example:"2bada8a7450677000f678be90653b85d364de7db25eb5ea54136ada5f3933730"`
+	UpdateAvailable bool   `json:"update_available" example:"true"`
+	// Error is why the model could not be checked, e.g. because it was created
+	// locally and is not in the registry.
+	Error string `json:"error,omitempty"`
+}
+
+// ModelUpdates is the result of the last update check.
+type ModelUpdates struct {
+	// CheckedAt is omitted before the first check.
+	CheckedAt *time.Time    `json:"checked_at,omitempty" example:"2025-09-08T14:00:00Z"`
+	Models    []ModelUpdate `json:"models"`
+}
+
+// StartUpdateChecks checks the installed models for updates in the background
+// until ctx is cancelled. The first check happens right away.
+func (s *ModelService) StartUpdateChecks(ctx context.Context) {
+	if s.cfg.UpdateCheckInterval <= 0 {
+		slog.Info("Model update checks are disabled.")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.UpdateCheckInterval)
+		defer ticker.Stop()
+		for {
+			// A failed check, e.g. while offline, is retried at the next tick.
+			if _, err := s.CheckUpdates(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn("Model update check failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckUpdates compares the digest of every installed model with the digest
+// published in the registry and remembers the result. A model that cannot be
+// checked is reported with its error rather than failing the whole check.
+func (s *ModelService) CheckUpdates(ctx context.Context) (*ModelUpdates, error) {
+	list, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list models: %w", err)
+	}
+
+	updates := make([]ModelUpdate, 0, len(list.Models))
+	for _, m := range list.Models {
+		// The models of a hosted API are not in Ollama's registry.
+		if m.Provider != "" && m.Provider != llm.ProviderOllama {
+			continue
+		}
+		update := ModelUpdate{Model: m.Name, LocalDigest: m.Digest}
+		remote, err := s.registry.ManifestDigest(ctx, m.Name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			update.Error = err.Error()
+		} else {
+			update.RemoteDigest = remote
+			update.UpdateAvailable = remote != m.Digest
+		}
+		updates = append(updates, update)
+	}
+
+	checkedAt := time.Now().UTC()
+	s.updatesMu.Lock()
+	s.updates = ModelUpdates{CheckedAt: &checkedAt, Models: updates}
+	result := s.copyUpdates()
+	s.updatesMu.Unlock()
+
+	slog.Info("Checked models for updates", "models", len(updates), "updates", countUpdates(updates))
+	return result, nil
+}
+
+// Updates returns the result of the last update check.
+func (s *ModelService) Updates(ctx context.Context) *ModelUpdates {
+	s.updatesMu.Lock()
+	defer s.updatesMu.Unlock()
+	return s.copyUpdates()
+}
+
+// Update pulls the latest version of an installed model. It streams the progress
+// like Pull.
+func (s *ModelService) Update(ctx context.Context, modelName string, ch chan<- llm.PullStatus) error {
+	available, err := s.llm.ListModels(ctx)
+	if err != nil {
+		close(ch)
+		return fmt.Errorf("could not list models: %w", err)
+	}
+	if !slices.ContainsFunc(available.Models, func(m llm.Model) bool { return m.Name == modelName }) {
+		close(ch)
+		return fmt.Errorf("%w: model '%s'", app_errors.ErrNotFound, modelName)
+	}
+	return s.Pull(ctx, &llm.PullModelRequest{Name: modelName}, ch)
+}
+
+// updateAvailable reports whether the last check found an update for the
+// installed version of a model.
+func (s *ModelService) updateAvailable(m llm.Model) bool {
+	s.updatesMu.Lock()
+	defer s.updatesMu.Unlock()
+	for _, update := range s.updates.Models {
+		if update.Model == m.Name {
+			return update.UpdateAvailable && update.LocalDigest == m.Digest
+		}
+	}
+	return false
+}
+
+// forgetUpdate drops the check result of a model that was pulled again. A name
+// without a tag also drops the ":latest" tag it refers to.
+func (s *ModelService) forgetUpdate(name string) {
+	s.updatesMu.Lock()
+	defer s.updatesMu.Unlock()
+	s.updates.Models = slices.DeleteFunc(s.updates.Models, func(u ModelUpdate) bool {
+		return u.Model == name || (!strings.Contains(name, ":") && u.Model == name+":latest")
+	})
+}
+
+// copyUpdates returns a copy of the last check result. The caller must hold updatesMu.
+func (s *ModelService) copyUpdates() *ModelUpdates {
+	models := make([]ModelUpdate, len(s.updates.Models))
+	copy(models, s.updates.Models)
+	return &ModelUpdates{CheckedAt: s.updates.CheckedAt, Models: models}
+}
+
+func countUpdates(updates []ModelUpdate) int {
+	n := 0
+	for _, u := range updates {
+		if u.UpdateAvailable {
+			n++
+		}
+	}
+	return n
+}
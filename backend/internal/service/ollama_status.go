@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// ollamaPingTimeout bounds the connectivity check, so that an unreachable Ollama
+// is reported quickly instead of hanging the request.
+const ollamaPingTimeout = 3 * time.Second
+
+// OllamaStatus reports whether the backend can reach Ollama.
+type OllamaStatus struct {
+	// URL is the configured address of Ollama, without any credentials.
+	URL       string `json:"url" example:"http://ollama:11434"`
+	Connected bool   `json:"connected" example:"true"`
+	// Version is the version of the Ollama server; it is empty if Ollama is unreachable.
+	Version string `json:"version,omitempty" example:"0.6.2"`
+	// LatencyMs is the round trip time of the version request in milliseconds.
+	LatencyMs float64 `json:"latency_ms" example:"1.8"`
+	// Error is why Ollama could not be reached.
+	Error string `json:"error,omitempty" example:"dial tcp: lookup ollama: no such host"`
+}
+
+// OllamaStatus asks Ollama for its version to check that it is reachable. An
+// unreachable Ollama is a status, not an error.
+func (s *ModelService) OllamaStatus(ctx context.Context) *OllamaStatus {
+	status := &OllamaStatus{URL: redactURL(s.cfg.OllamaURL)}
+
+	ctx, cancel := context.WithTimeout(ctx, ollamaPingTimeout)
+	defer cancel()
+	started := time.Now()
+	version, err := s.llm.Version(ctx)
+	status.LatencyMs = milliseconds(time.Since(started).Nanoseconds())
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Connected, status.Version = true, version
+	return status
+}
+
+// redactURL hides the password of a URL with credentials.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
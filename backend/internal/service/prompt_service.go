@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// PromptService manages the library of reusable prompt templates.
+type PromptService struct {
+	repo repository.Repository
+}
+
+// SavePromptRequest is the DTO for creating or replacing a prompt template.
+type SavePromptRequest struct {
+	Name        string `json:"name" validate:"required,max=100" example:"Code review"`
+	Description string `json:"description,omitempty" validate:"max=500" example:"Reviews a diff for bugs and style issues."`
+	Body        string `json:"body" validate:"required" example:"Review the following {{language}} code:"`
+}
+
+// NewPromptService creates a new instance of PromptService.
+func NewPromptService(repo repository.Repository) *PromptService {
+	return &PromptService{repo: repo}
+}
+
+// CreatePrompt stores a new prompt template after checking its syntax.
+func (s *PromptService) CreatePrompt(ctx context.Context, req *SavePromptRequest) (*model.Prompt, error) {
+	variables, err := parsePromptTemplate(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	prompt := &model.Prompt{
+		ID:          uuid.NewString(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Body:        req.Body,
+		Variables:   variables,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreatePrompt(ctx, prompt); err != nil {
+		return nil, fmt.Errorf("could not create prompt: %w", err)
+	}
+	return prompt, nil
+}
+
+// ListPrompts returns all prompt templates, ordered by name.
+func (s *PromptService) ListPrompts(ctx context.Context) ([]*model.Prompt, error) {
+	prompts, err := s.repo.GetPrompts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list prompts: %w", err)
+	}
+	for _, p := range prompts {
+		p.Variables = promptVariables(p.Body)
+	}
+	return prompts, nil
+}
+
+// GetPrompt returns a single prompt template.
+func (s *PromptService) GetPrompt(ctx context.Context, promptID string) (*model.Prompt, error) {
+	prompt, err := s.repo.GetPrompt(ctx, promptID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: prompt with id %s", app_errors.ErrNotFound, promptID)
+		}
+		return nil, fmt.Errorf("could not get prompt: %w", err)
+	}
+	prompt.Variables = promptVariables(prompt.Body)
+	return prompt, nil
+}
+
+// UpdatePrompt replaces the name, description and body of a prompt template.
+func (s *PromptService) UpdatePrompt(ctx context.Context, promptID string, req *SavePromptRequest) (*model.Prompt, error) {
+	variables, err := parsePromptTemplate(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	prompt.Name = strings.TrimSpace(req.Name)
+	prompt.Description = req.Description
+	prompt.Body = req.Body
+	prompt.Variables = variables
+	prompt.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdatePrompt(ctx, prompt); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: prompt with id %s", app_errors.ErrNotFound, promptID)
+		}
+		return nil, fmt.Errorf("could not update prompt: %w", err)
+	}
+	return prompt, nil
+}
+
+// DeletePrompt removes a prompt template.
+func (s *PromptService) DeletePrompt(ctx context.Context, promptID string) error {
+	if err := s.repo.DeletePrompt(ctx, promptID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: prompt with id %s", app_errors.ErrNotFound, promptID)
+		}
+		return fmt.Errorf("could not delete prompt: %w", err)
+	}
+	return nil
+}
+
+// templateBuiltins are the functions predefined by text/template. They are not
+// placeholders, so e.g. `{{printf "%q" name}}` only requires `name`.
+var templateBuiltins = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true,
+	"len": true, "not": true, "or": true, "print": true, "printf": true, "println": true,
+	"urlquery": true, "eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// parsePromptTemplate checks the syntax of a prompt body and returns its
+// placeholders in alphabetical order.
+//
+// WHY: Placeholders are written `{{variable}}`, which text/template reads as a
+// function call. Parsing with SkipFuncCheck accepts the body before the variables
+// are known, and the identifiers left in the tree are exactly the placeholders.
+func parsePromptTemplate(body string) ([]string, error) {
+	tree := parse.New("prompt")
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(body, "", "", map[string]*parse.Tree{}); err != nil {
+		return nil, fmt.Errorf("%w: invalid prompt template: %s", app_errors.ErrValidation, err)
+	}
+	seen := make(map[string]bool)
+	collectPlaceholders(tree.Root, seen)
+	variables := make([]string, 0, len(seen))
+	for name := range seen {
+		variables = append(variables, name)
+	}
+	slices.Sort(variables)
+	return variables, nil
+}
+
+// promptVariables returns the placeholders of a stored prompt body, which was
+// validated when it was saved.
+func promptVariables(body string) []string {
+	variables, _ := parsePromptTemplate(body)
+	if variables == nil {
+		return []string{}
+	}
+	return variables
+}
+
+func collectPlaceholders(node parse.Node, seen map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectPlaceholders(child, seen)
+		}
+	case *parse.ActionNode:
+		collectPlaceholders(n.Pipe, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectPlaceholders(cmd, seen)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectPlaceholders(arg, seen)
+		}
+	case *parse.IdentifierNode:
+		if !templateBuiltins[n.Ident] {
+			seen[n.Ident] = true
+		}
+	case *parse.IfNode:
+		collectBranchPlaceholders(&n.BranchNode, seen)
+	case *parse.RangeNode:
+		collectBranchPlaceholders(&n.BranchNode, seen)
+	case *parse.WithNode:
+		collectBranchPlaceholders(&n.BranchNode, seen)
+	}
+}
+
+func collectBranchPlaceholders(n *parse.BranchNode, seen map[string]bool) {
+	collectPlaceholders(n.Pipe, seen)
+	collectPlaceholders(n.List, seen)
+	collectPlaceholders(n.ElseList, seen)
+}
+
+// renderPromptTemplate fills the placeholders of a prompt body. Missing variables
+// and rendering errors are returned as `ErrValidation`; extra variables are ignored.
+func renderPromptTemplate(body string, variables map[string]string) (string, error) {
+	placeholders, err := parsePromptTemplate(body)
+	if err != nil {
+		return "", err
+	}
+
+	funcs := template.FuncMap{}
+	var missing []string
+	for _, name := range placeholders {
+		value, ok := variables[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		funcs[name] = func() string { return value }
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: missing prompt variables: %s", app_errors.ErrValidation, strings.Join(missing, ", "))
+	}
+
+	tmpl, err := template.New("prompt").Funcs(funcs).Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid prompt template: %s", app_errors.ErrValidation, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return "", fmt.Errorf("%w: could not render prompt: %s", app_errors.ErrValidation, err)
+	}
+	return out.String(), nil
+}
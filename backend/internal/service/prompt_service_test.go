@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	app_errors "flow-ai/backend/internal/errors"
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+	mock_repo "flow-ai/backend/internal/repository/mocks"
+	"flow-ai/backend/internal/service"
+)
+
+func setupPromptService(t *testing.T) (*service.PromptService, *mock_repo.MockRepository) {
+	repo := mock_repo.NewMockRepository(t)
+	return service.NewPromptService(repo), repo
+}
+
+// TestRenderPromptTemplate verifies how `{{variable}}` placeholders are filled.
+func TestRenderPromptTemplate(t *testing.T) {
+	t.Run("Placeholders are replaced", func(t *testing.T) {
+		out, err := service.RenderPromptTemplate("Translate to {{lang}}:\n{{ text }}", map[string]string{"lang": "German", "text": "Hello", "unused": "x"})
+		require.NoError(t, err)
+		assert.Equal(t, "Translate to German:\nHello", out)
+	})
+
+	t.Run("Values are not evaluated as templates", func(t *testing.T) {
+		out, err := service.RenderPromptTemplate("Say {{text}}", map[string]string{"text": "{{lang}}"})
+		require.NoError(t, err)
+		assert.Equal(t, "Say {{lang}}", out)
+	})
+
+	t.Run("Builtins can be combined with placeholders", func(t *testing.T) {
+		out, err := service.RenderPromptTemplate(`{{if name}}Hi {{printf "%q" name}}{{end}}`, map[string]string{"name": "Ann"})
+		require.NoError(t, err)
+		assert.Equal(t, `Hi "Ann"`, out)
+	})
+
+	t.Run("Missing variables are a validation error", func(t *testing.T) {
+		_, err := service.RenderPromptTemplate("{{a}} and {{b}}", map[string]string{"a": "x"})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		assert.ErrorContains(t, err, "b")
+	})
+
+	t.Run("Syntax errors are a validation error", func(t *testing.T) {
+		_, err := service.RenderPromptTemplate("{{if}", nil)
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+	})
+}
+
+// TestPromptService_CreatePrompt verifies that templates are checked and their
+// variables reported before they are stored.
+func TestPromptService_CreatePrompt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - Variables are listed", func(t *testing.T) {
+		svc, repo := setupPromptService(t)
+		repo.On("CreatePrompt", ctx, mock.AnythingOfType("*model.Prompt")).Return(nil).Once()
+
+		prompt, err := svc.CreatePrompt(ctx, &service.SavePromptRequest{Name: " Review ", Body: "Review this {{language}} code for {{focus}}; {{language}} only."})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Review", prompt.Name)
+		assert.Equal(t, []string{"focus", "language"}, prompt.Variables)
+		assert.NotEmpty(t, prompt.ID)
+	})
+
+	t.Run("Failure - Invalid template", func(t *testing.T) {
+		svc, repo := setupPromptService(t)
+
+		_, err := svc.CreatePrompt(ctx, &service.SavePromptRequest{Name: "Broken", Body: "{{end}}"})
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		repo.AssertNotCalled(t, "CreatePrompt", mock.Anything, mock.Anything)
+	})
+}
+
+// TestPromptService_UpdatePrompt verifies that an update keeps the creation time
+// and maps a missing prompt to ErrNotFound.
+func TestPromptService_UpdatePrompt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		svc, repo := setupPromptService(t)
+		existing := &model.Prompt{ID: "p1", Name: "Old", Body: "old"}
+		repo.On("GetPrompt", ctx, "p1").Return(existing, nil).Once()
+		repo.On("UpdatePrompt", ctx, mock.MatchedBy(func(p *model.Prompt) bool {
+			return p.ID == "p1" && p.Name == "New" && p.Body == "Hi {{name}}"
+		})).Return(nil).Once()
+
+		prompt, err := svc.UpdatePrompt(ctx, "p1", &service.SavePromptRequest{Name: "New", Body: "Hi {{name}}"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name"}, prompt.Variables)
+	})
+
+	t.Run("Failure - Not found", func(t *testing.T) {
+		svc, repo := setupPromptService(t)
+		repo.On("GetPrompt", ctx, "missing").Return(nil, repository.ErrNotFound).Once()
+
+		_, err := svc.UpdatePrompt(ctx, "missing", &service.SavePromptRequest{Name: "New", Body: "text"})
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// pullSubscriberBuffer is the number of progress updates buffered per subscriber.
+// A subscriber that falls further behind misses intermediate updates, but it is
+// always delivered the final status of the pull (see `ModelService.Pull`).
+const pullSubscriberBuffer = 32
+
+// pullJob is a single in-flight download of a model, shared by every client
+// pulling the same model at the same time.
+type pullJob struct {
+	name      string
+	startedAt time.Time
+	cancel    context.CancelFunc
+	// done is closed once the provider call has returned; `err` is only valid after that.
+	done chan struct{}
+	err  error
+
+	// The fields below are guarded by the registry's mutex.
+	subscribers map[chan llm.PullStatus]struct{}
+	last        *llm.PullStatus
+	// cancelled is set when the pull was aborted explicitly via `cancelPull`.
+	cancelled bool
+}
+
+// PullCancelledStatus is the final status of a pull aborted via `CancelPull`.
+const PullCancelledStatus = "cancelled"
+
+// pullRegistry deduplicates concurrent pulls of the same model. The first caller
+// starts the provider download; later callers attach to it and receive the same
+// progress updates.
+//
+// WHY: Without it, two browser tabs pulling the same model open two parallel
+// downloads in Ollama, wasting bandwidth and producing confusing progress.
+//
+// Downloads run in the background under the registry's own context, so they
+// outlive the requests that started them and are only stopped by `cancelPull`
+// or `close`, or, if `cancelWhenIdle` is set, when their last subscriber leaves.
+type pullRegistry struct {
+	// ctx is the parent of every download; `stop` cancels it on shutdown.
+	ctx  context.Context
+	stop context.CancelFunc
+	// wg tracks the goroutines running downloads, so that `close` can wait for them.
+	wg sync.WaitGroup
+	// cancelWhenIdle cancels a download once it has no subscribers left.
+	cancelWhenIdle bool
+
+	mu   sync.Mutex
+	jobs map[string]*pullJob
+}
+
+func newPullRegistry(cancelWhenIdle bool) *pullRegistry {
+	ctx, stop := context.WithCancel(context.Background())
+	return &pullRegistry{ctx: ctx, stop: stop, cancelWhenIdle: cancelWhenIdle, jobs: make(map[string]*pullJob)}
+}
+
+// subscribe attaches `sub` to the in-flight pull of `req.Name`, starting a new
+// download via `start` if none is running. It returns the job and a copy of
+// the latest known status (nil if no progress was reported yet).
+func (r *pullRegistry) subscribe(req *llm.PullModelRequest, sub chan llm.PullStatus, start func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error) (*pullJob, *llm.PullStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job, ok := r.jobs[req.Name]; ok {
+		job.subscribers[sub] = struct{}{}
+		slog.Info("Attached to in-flight model pull", "model", req.Name, "subscribers", len(job.subscribers))
+		if job.last == nil {
+			return job, nil
+		}
+		last := *job.last
+		return job, &last
+	}
+
+	// The download is owned by the registry rather than by the first request, so
+	// that it keeps going when its subscribers leave, e.g. on a page reload.
+	ctx, cancel := context.WithCancel(r.ctx)
+	job := &pullJob{
+		name:        req.Name,
+		startedAt:   time.Now().UTC(),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		subscribers: map[chan llm.PullStatus]struct{}{sub: {}},
+	}
+	r.jobs[req.Name] = job
+	r.wg.Add(1)
+	go r.run(ctx, job, req, start)
+	return job, nil
+}
+
+// unsubscribe detaches `sub` from the job. The download keeps running without
+// subscribers, so that a client can reattach to it later, unless the registry
+// cancels idle downloads.
+func (r *pullRegistry) unsubscribe(job *pullJob, sub chan llm.PullStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(job.subscribers, sub)
+	if len(job.subscribers) > 0 {
+		return
+	}
+	if !r.cancelWhenIdle {
+		slog.Info("All subscribers left, model pull continues in the background", "model", job.name)
+		return
+	}
+	slog.Info("All subscribers left, cancelling model pull", "model", job.name)
+	// A pull of the same model started while this one winds down gets a new job.
+	if r.jobs[job.name] == job {
+		delete(r.jobs, job.name)
+	}
+	job.cancel()
+}
+
+// cancelPull aborts the in-flight pull of `name` for all of its subscribers. It
+// returns false if no such pull is running.
+func (r *pullRegistry) cancelPull(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return false
+	}
+	slog.Info("Cancelling model pull on request", "model", name, "subscribers", len(job.subscribers))
+	job.cancelled = true
+	job.cancel()
+	return true
+}
+
+// close cancels every in-flight download and waits until their goroutines have
+// returned. Pulls started afterwards fail right away.
+func (r *pullRegistry) close() {
+	r.stop()
+	r.wg.Wait()
+}
+
+// run executes the provider download and fans its progress out to all subscribers.
+func (r *pullRegistry) run(ctx context.Context, job *pullJob, req *llm.PullModelRequest, start func(ctx context.Context, req *llm.PullModelRequest, ch chan<- llm.PullStatus) error) {
+	defer r.wg.Done()
+
+	providerChan := make(chan llm.PullStatus)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- start(ctx, req, providerChan)
+	}()
+
+	for {
+		select {
+		case status, ok := <-providerChan:
+			if !ok {
+				// The provider closed its channel; keep waiting for its return value.
+				providerChan = nil
+				continue
+			}
+			r.broadcast(job, status)
+		case err := <-errChan:
+			// `providerChan` is unbuffered, so every status sent before the provider
+			// returned has already been broadcast.
+			r.finish(job, err)
+			return
+		}
+	}
+}
+
+// broadcast records the status as the latest one and forwards it to every subscriber
+// without blocking; a full subscriber buffer drops the update for that subscriber only.
+func (r *pullRegistry) broadcast(job *pullJob, status llm.PullStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.last = &status
+	for sub := range job.subscribers {
+		select {
+		case sub <- status:
+		default:
+			slog.Debug("Pull subscriber is lagging, dropping progress update", "model", job.name)
+		}
+	}
+}
+
+func (r *pullRegistry) finish(job *pullJob, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.jobs[job.name] == job {
+		delete(r.jobs, job.name)
+	}
+	job.err = err
+	if job.cancelled {
+		// Subscribers are always delivered the last status, so this tells them
+		// why the stream ended.
+		job.last = &llm.PullStatus{Status: PullCancelledStatus}
+	}
+	job.cancel()
+	close(job.done)
+}
+
+// PullJobStatus describes an in-flight model pull.
+type PullJobStatus struct {
+	Name      string    `json:"name" example:"llama3:70b"`
+	StartedAt time.Time `json:"started_at" example:"2025-09-08T14:00:00Z"`
+	// Subscribers is the number of clients currently streaming the pull's progress.
+	Subscribers int `json:"subscribers" example:"1"`
+	// Status, Total and Completed are taken from the latest progress update; they
+	// are empty until Ollama reported the first one.
+	Status    string `json:"status,omitempty" example:"pulling 6a0746a1ec1a"`
+	Total     int64  `json:"total,omitempty" example:"39969745184"`
+	Completed int64  `json:"completed,omitempty" example:"1073741824"`
+}
+
+// list returns the status of every in-flight pull, oldest first.
+func (r *pullRegistry) list() []PullJobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]PullJobStatus, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		status := PullJobStatus{Name: job.name, StartedAt: job.startedAt, Subscribers: len(job.subscribers)}
+		if job.last != nil {
+			status.Status, status.Total, status.Completed = job.last.Status, job.last.Total, job.last.Completed
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if !statuses[i].StartedAt.Equal(statuses[j].StartedAt) {
+			return statuses[i].StartedAt.Before(statuses[j].StartedAt)
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// lastStatus returns a copy of the latest status reported for the job.
+func (r *pullRegistry) lastStatus(job *pullJob) *llm.PullStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job.last == nil {
+		return nil
+	}
+	last := *job.last
+	return &last
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"flow-ai/backend/internal/llm"
+)
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// reasoningSplitter separates `<think>…</think>` reasoning blocks, as emitted by
+// models like qwen3 and deepseek-r1, from the visible answer of a streamed response.
+//
+// WHY: Tags can be split across stream chunks (e.g. "<thi" + "nk>"), so a chunk
+// cannot be classified on its own. Text that may be the beginning of a tag is held
+// back until the next chunk decides it.
+type reasoningSplitter struct {
+	inThink bool
+	// pending holds a trailing fragment that could be the start of a tag.
+	pending string
+	// trimLeading drops the whitespace models put right after a tag, so the
+	// answer does not start with the blank lines that followed `</think>`.
+	trimLeading bool
+}
+
+// Push consumes the next chunk of a stream and returns its answer and reasoning parts.
+func (p *reasoningSplitter) Push(chunk string) (content, reasoning string) {
+	var contentBuf, reasoningBuf strings.Builder
+	buf := p.pending + chunk
+	p.pending = ""
+
+	for buf != "" {
+		tag := thinkOpenTag
+		if p.inThink {
+			tag = thinkCloseTag
+		}
+
+		text := buf
+		if i := strings.Index(buf, tag); i >= 0 {
+			text, buf = buf[:i], buf[i+len(tag):]
+		} else {
+			// Hold back a suffix that could still turn into the tag.
+			keep := partialTagSuffix(buf, tag)
+			text, p.pending, buf = buf[:len(buf)-keep], buf[len(buf)-keep:], ""
+			tag = ""
+		}
+
+		if p.inThink {
+			reasoningBuf.WriteString(p.trim(text))
+		} else {
+			contentBuf.WriteString(p.trim(text))
+		}
+		if tag != "" {
+			p.inThink = !p.inThink
+			p.trimLeading = true
+		}
+	}
+	return contentBuf.String(), reasoningBuf.String()
+}
+
+// Flush returns any text held back at the end of the stream.
+func (p *reasoningSplitter) Flush() (content, reasoning string) {
+	rest := p.trim(p.pending)
+	p.pending = ""
+	if p.inThink {
+		return "", rest
+	}
+	return rest, ""
+}
+
+func (p *reasoningSplitter) trim(text string) string {
+	if !p.trimLeading {
+		return text
+	}
+	text = strings.TrimLeft(text, " \t\r\n")
+	if text != "" {
+		p.trimLeading = false
+	}
+	return text
+}
+
+// partialTagSuffix returns the length of the longest suffix of s that is a proper
+// prefix of tag.
+func partialTagSuffix(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// stripReasoning removes all reasoning blocks from a complete response.
+func stripReasoning(s string) string {
+	var p reasoningSplitter
+	content, _ := p.Push(s)
+	rest, _ := p.Flush()
+	return content + rest
+}
+
+// assistantMetadata is stored in the metadata column of assistant messages.
+// The generation stats are embedded so that their fields stay at the top level.
+type assistantMetadata struct {
+	*llm.GenerationStats
+	Reasoning string `json:"reasoning,omitempty"`
+	// Options are the generation options sent to the model, so that old answers
+	// can be traced back to the settings that produced them.
+	Options *llm.RequestOptions `json:"options,omitempty"`
+	// Error is why the generation failed; the content is then the part of the
+	// answer streamed before the failure.
+	Error string `json:"error,omitempty"`
+}
+
+// buildAssistantMetadata returns the metadata of an assistant message, or nil if
+// there is nothing to store.
+func buildAssistantMetadata(stats *llm.GenerationStats, reasoning string, options *llm.RequestOptions, generationError string) json.RawMessage {
+	if stats == nil && reasoning == "" && options == nil && generationError == "" {
+		return nil
+	}
+	metadata, _ := json.Marshal(assistantMetadata{GenerationStats: stats, Reasoning: reasoning, Options: options, Error: generationError})
+	return metadata
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"flow-ai/backend/internal/service"
+)
+
+// splitAll feeds the chunks through a fresh splitter and returns the combined parts.
+func splitAll(chunks ...string) (content, reasoning string) {
+	var p service.ReasoningSplitter
+	for _, chunk := range chunks {
+		c, r := p.Push(chunk)
+		content += c
+		reasoning += r
+	}
+	c, r := p.Flush()
+	return content + c, reasoning + r
+}
+
+// TestReasoningSplitter verifies that <think> blocks are separated from the answer.
+func TestReasoningSplitter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		wantContent   string
+		wantReasoning string
+	}{
+		{"No reasoning", "Just an answer.", "Just an answer.", ""},
+		{"Leading reasoning", "<think>\nLet me think.\n</think>\n\nThe answer.", "The answer.", "Let me think.\n"},
+		{"Unclosed reasoning", "<think>Still thinking", "", "Still thinking"},
+		{"Angle bracket that is not a tag", "a < b and <thin ice>", "a < b and <thin ice>", ""},
+		{"Text ending in a partial tag", "Ends with <thi", "Ends with <thi", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, reasoning := splitAll(tc.input)
+			assert.Equal(t, tc.wantContent, content)
+			assert.Equal(t, tc.wantReasoning, reasoning)
+		})
+	}
+}
+
+// TestReasoningSplitter_ChunkBoundaries verifies that the result does not depend on
+// where the stream is cut, including cuts in the middle of a tag.
+func TestReasoningSplitter_ChunkBoundaries(t *testing.T) {
+	input := "<think>plan</think>Hello <b>world</b>"
+	wantContent, wantReasoning := splitAll(input)
+	assert.Equal(t, "Hello <b>world</b>", wantContent)
+	assert.Equal(t, "plan", wantReasoning)
+
+	// Every two-way split.
+	for i := 1; i < len(input); i++ {
+		content, reasoning := splitAll(input[:i], input[i:])
+		assert.Equal(t, wantContent, content, "split at %d", i)
+		assert.Equal(t, wantReasoning, reasoning, "split at %d", i)
+	}
+
+	// One byte per chunk.
+	chunks := make([]string, len(input))
+	for i := range input {
+		chunks[i] = input[i : i+1]
+	}
+	content, reasoning := splitAll(chunks...)
+	assert.Equal(t, wantContent, content)
+	assert.Equal(t, wantReasoning, reasoning)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"flow-ai/backend/internal/llm"
+)
+
+// responseCache is a size-bounded LRU cache of the answers to deterministic
+// generation requests. It is safe for concurrent use.
+type responseCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // Front is the most recently used entry.
+	items map[string]*list.Element
+}
+
+// cachedResponse is a complete answer as the LLM produced it, including any
+// reasoning, so that it can be replayed through the normal stream handling.
+type cachedResponse struct {
+	key     string
+	content string
+	stats   *llm.GenerationStats
+}
+
+func newResponseCache(size int) *responseCache {
+	return &responseCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *responseCache) get(key string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(cachedResponse), true
+}
+
+func (c *responseCache) add(resp cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[resp.key]; ok {
+		elem.Value = resp
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[resp.key] = c.order.PushFront(resp)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(cachedResponse).key)
+	}
+}
+
+// responseCacheKey returns the cache key of req, or "" if the answer is not
+// deterministic and must not be cached. Only a fixed seed with temperature 0
+// makes Ollama reproduce an answer.
+func responseCacheKey(req *llm.GenerateRequest) string {
+	opts := req.Options
+	if opts == nil || opts.Seed == nil || opts.Temperature == nil || *opts.Temperature != 0 {
+		return ""
+	}
+	// `keep_alive` does not influence the answer, so it is not part of the key.
+	payload, err := json.Marshal(struct {
+		Model    string              `json:"model"`
+		Messages []llm.Message       `json:"messages"`
+		Options  *llm.RequestOptions `json:"options"`
+		Format   string              `json:"format,omitempty"`
+	}{req.Model, req.Messages, opts, req.Format})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
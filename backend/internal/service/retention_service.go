@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/repository"
+)
+
+// RetentionServiceConfig holds the static configuration of the retention janitor.
+type RetentionServiceConfig struct {
+	// Interval is the time between two runs; 0 disables the janitor.
+	Interval time.Duration
+	// BatchSize is the number of chats deleted per transaction.
+	BatchSize int
+}
+
+// RetentionStatus describes the most recent retention run.
+type RetentionStatus struct {
+	// Enabled reports whether the background janitor is running.
+	Enabled bool `json:"enabled" example:"true"`
+	// Interval is the time between two runs, e.g. "1h0m0s".
+	Interval string `json:"interval" example:"1h0m0s"`
+	// LastRunAt is the start of the last run; it is omitted before the first run.
+	LastRunAt *time.Time `json:"last_run_at,omitempty" example:"2025-09-08T14:00:00Z"`
+	// DeletedChats is the number of chats deleted by the last run.
+	DeletedChats int `json:"deleted_chats" example:"12"`
+	// LastError is the error that ended the last run early, if any.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// RetentionService periodically deletes old chats according to the
+// `retention_days` and `retention_max_chats` settings. Pinned chats are never deleted.
+type RetentionService struct {
+	repo            repository.Repository
+	settingsService *SettingsService
+	cfg             RetentionServiceConfig
+
+	mu     sync.Mutex
+	status RetentionStatus
+}
+
+// NewRetentionService creates a new instance of RetentionService.
+func NewRetentionService(repo repository.Repository, settingsService *SettingsService, cfg RetentionServiceConfig) *RetentionService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &RetentionService{
+		repo:            repo,
+		settingsService: settingsService,
+		cfg:             cfg,
+		status:          RetentionStatus{Enabled: cfg.Interval > 0, Interval: cfg.Interval.String()},
+	}
+}
+
+// Start runs the janitor in the background until ctx is cancelled. The first run
+// happens right away, so that a long-stopped instance is cleaned up on startup.
+func (s *RetentionService) Start(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		slog.Info("Chat retention janitor is disabled.")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("Chat retention run failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce applies the retention settings once and returns the number of deleted chats.
+func (s *RetentionService) RunOnce(ctx context.Context) (int, error) {
+	startedAt := time.Now().UTC()
+	deleted, err := s.prune(ctx, startedAt)
+
+	s.mu.Lock()
+	s.status.LastRunAt = &startedAt
+	s.status.DeletedChats = deleted
+	s.status.LastError = ""
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if deleted > 0 {
+		slog.Info("Pruned old chats", "deleted_chats", deleted, "duration", time.Since(startedAt))
+	}
+	return deleted, err
+}
+
+// Status returns a snapshot of the most recent run.
+func (s *RetentionService) Status() RetentionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *RetentionService) prune(ctx context.Context, now time.Time) (int, error) {
+	settings, err := s.settingsService.Get(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not load settings: %w", err)
+	}
+	opts := model.ChatPruneOptions{KeepNewest: settings.RetentionMaxChats, Limit: s.cfg.BatchSize}
+	if settings.RetentionDays > 0 {
+		opts.UpdatedBefore = now.AddDate(0, 0, -settings.RetentionDays)
+	}
+	if opts.UpdatedBefore.IsZero() && opts.KeepNewest == 0 {
+		return 0, nil
+	}
+
+	total := 0
+	for {
+		n, err := s.repo.PruneChats(ctx, opts)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("could not prune chats: %w", err)
+		}
+		if n < opts.Limit {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"flow-ai/backend/internal/model"
+	"flow-ai/backend/internal/service"
+)
+
+// setupRetentionService creates a `RetentionService` that reads the given
+// retention settings from a mocked settings table.
+func setupRetentionService(t *testing.T, retentionDays, maxChats string, batchSize int) (*service.RetentionService, Mocks) {
+	_, mocks := setupChatService(t)
+	t.Cleanup(func() { _ = mocks.db.Close() })
+	mocks.mockDB.ExpectQuery("SELECT key, value FROM settings").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("main_model", "m").
+			AddRow("support_model", "m").
+			AddRow("retention_days", retentionDays).
+			AddRow("retention_max_chats", maxChats))
+
+	settingsService := service.NewSettingsService(mocks.db, mocks.llm, nil)
+	svc := service.NewRetentionService(mocks.repo, settingsService, service.RetentionServiceConfig{Interval: time.Hour, BatchSize: batchSize})
+	return svc, mocks
+}
+
+// TestRetentionService_RunOnce verifies how the retention settings are turned into
+// batched deletions and how the outcome is reported.
+func TestRetentionService_RunOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Deletes in batches until a batch is not full", func(t *testing.T) {
+		svc, mocks := setupRetentionService(t, "30", "100", 2)
+		before := time.Now().UTC()
+		matchOpts := mock.MatchedBy(func(opts model.ChatPruneOptions) bool {
+			cutoff := before.AddDate(0, 0, -30)
+			return opts.KeepNewest == 100 && opts.Limit == 2 &&
+				!opts.UpdatedBefore.Before(cutoff) && opts.UpdatedBefore.Sub(cutoff) < time.Minute
+		})
+		mocks.repo.On("PruneChats", ctx, matchOpts).Return(2, nil).Twice()
+		mocks.repo.On("PruneChats", ctx, matchOpts).Return(1, nil).Once()
+
+		deleted, err := svc.RunOnce(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, deleted)
+		status := svc.Status()
+		assert.True(t, status.Enabled)
+		assert.Equal(t, 5, status.DeletedChats)
+		require.NotNil(t, status.LastRunAt)
+		assert.Empty(t, status.LastError)
+	})
+
+	t.Run("Disabled policy deletes nothing", func(t *testing.T) {
+		svc, mocks := setupRetentionService(t, "0", "", 100)
+
+		deleted, err := svc.RunOnce(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		mocks.repo.AssertNotCalled(t, "PruneChats", mock.Anything, mock.Anything)
+		assert.NotNil(t, svc.Status().LastRunAt)
+	})
+
+	t.Run("Errors are reported in the status", func(t *testing.T) {
+		svc, mocks := setupRetentionService(t, "", "10", 100)
+		mocks.repo.On("PruneChats", ctx, model.ChatPruneOptions{KeepNewest: 10, Limit: 100}).Return(0, errors.New("database is locked")).Once()
+
+		_, err := svc.RunOnce(ctx)
+
+		assert.Error(t, err)
+		assert.Contains(t, svc.Status().LastError, "database is locked")
+	})
+}
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"slices"
 	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	app_errors "flow-ai/backend/internal/errors"
@@ -21,18 +24,118 @@ type Settings struct {
 	MainModel string `json:"main_model" validate:"required" example:"qwen3:8b"`
 	// A model for background tasks like title generation. Can be the same as the main model.
 	SupportModel string `json:"support_model" example:"gemma3:4b"`
+	// ShowReasoning streams the <think> reasoning of models that emit it to the client.
+	ShowReasoning bool `json:"show_reasoning" example:"false"`
+	// RetentionDays deletes unpinned chats not updated for this many days; 0 keeps them forever.
+	RetentionDays int `json:"retention_days" validate:"gte=0" example:"90"`
+	// RetentionMaxChats deletes the oldest unpinned chats beyond this count; 0 means no limit.
+	RetentionMaxChats int `json:"retention_max_chats" validate:"gte=0" example:"500"`
+	// KeepAlive is how long Ollama keeps a model loaded after a request, e.g. "5m",
+	// "0" or "-1"; empty uses Ollama's default.
+	KeepAlive string `json:"keep_alive" validate:"omitempty,keep_alive" example:"5m"`
+	// EnablePromptTemplates renders system prompts as templates, so that they can
+	// use variables such as {{.Date}}, {{.Time}}, {{.UserID}} and {{.Model}}.
+	EnablePromptTemplates bool `json:"enable_prompt_templates" example:"false"`
+	// DefaultOptions are the generation options of every request, e.g. a lower
+	// temperature. The defaults of a model and the options of a request take precedence.
+	DefaultOptions *llm.RequestOptions `json:"default_options,omitempty"`
+	// PreloadMainModel loads the main model into memory at startup and whenever
+	// the main model changes, so that the first chat does not wait for it to load.
+	PreloadMainModel bool `json:"preload_main_model" example:"false"`
+	// TitlePromptTemplate is the text/template of the prompt that asks the support
+	// model for a chat title, with the first exchange as {{.User}} and
+	// {{.Assistant}}, e.g. to get titles in another language. Empty uses the
+	// built-in English prompt.
+	TitlePromptTemplate string `json:"title_prompt_template" example:"Give this conversation a title of at most five German words. Answer with JSON: {\"title\": \"...\"}\n\nUser: {{.User}}\nAssistant: {{.Assistant}}"`
+	// ModelAliases maps alias names to models. They are managed through the
+	// model alias endpoints, so they are neither returned nor saved with the settings.
+	ModelAliases map[string]string `json:"-"`
+}
+
+// SettingsPatch is a partial update of the settings: only the fields that are
+// set are changed. Each field matches the field of Settings with the same JSON
+// name; `"default_options": null` removes the default options.
+type SettingsPatch struct {
+	SystemPrompt          *string             `json:"system_prompt,omitempty"`
+	MainModel             *string             `json:"main_model,omitempty" example:"qwen3:8b"`
+	SupportModel          *string             `json:"support_model,omitempty" example:"gemma3:4b"`
+	ShowReasoning         *bool               `json:"show_reasoning,omitempty"`
+	RetentionDays         *int                `json:"retention_days,omitempty"`
+	RetentionMaxChats     *int                `json:"retention_max_chats,omitempty"`
+	KeepAlive             *string             `json:"keep_alive,omitempty"`
+	EnablePromptTemplates *bool               `json:"enable_prompt_templates,omitempty"`
+	DefaultOptions        *llm.RequestOptions `json:"default_options,omitempty"`
+	PreloadMainModel      *bool               `json:"preload_main_model,omitempty"`
+	TitlePromptTemplate   *string             `json:"title_prompt_template,omitempty"`
+
+	// clearDefaultOptions is set by an explicit null for default_options.
+	clearDefaultOptions bool
+}
+
+// UnmarshalJSON tells an explicit `"default_options": null` apart from a
+// missing field, which both leave DefaultOptions nil.
+func (p *SettingsPatch) UnmarshalJSON(data []byte) error {
+	type plain SettingsPatch
+	var raw struct {
+		DefaultOptions json.RawMessage `json:"default_options"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	p.clearDefaultOptions = string(raw.DefaultOptions) == "null"
+	return nil
+}
+
+// Apply changes the fields of settings that are set in the patch.
+func (p *SettingsPatch) Apply(settings *Settings) {
+	setIfPresent(&settings.SystemPrompt, p.SystemPrompt)
+	setIfPresent(&settings.MainModel, p.MainModel)
+	setIfPresent(&settings.SupportModel, p.SupportModel)
+	setIfPresent(&settings.ShowReasoning, p.ShowReasoning)
+	setIfPresent(&settings.RetentionDays, p.RetentionDays)
+	setIfPresent(&settings.RetentionMaxChats, p.RetentionMaxChats)
+	setIfPresent(&settings.KeepAlive, p.KeepAlive)
+	setIfPresent(&settings.EnablePromptTemplates, p.EnablePromptTemplates)
+	setIfPresent(&settings.PreloadMainModel, p.PreloadMainModel)
+	setIfPresent(&settings.TitlePromptTemplate, p.TitlePromptTemplate)
+	switch {
+	case p.DefaultOptions != nil:
+		settings.DefaultOptions = p.DefaultOptions
+	case p.clearDefaultOptions:
+		settings.DefaultOptions = nil
+	}
+}
+
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// ResolveModel returns the model that name refers to, following model aliases.
+func (s *Settings) ResolveModel(name string) string {
+	return resolveAlias(s.ModelAliases, name)
 }
 
 // SettingsService provides methods for managing application settings.
 // It includes logic for smart initialization and self-healing.
 type SettingsService struct {
-	db  *sql.DB
-	llm llm.LLMProvider
+	db        *sql.DB
+	llm       llm.LLMProvider
+	preloader *ModelPreloader
+
+	// aliasMu serializes changes to the model aliases, which are read, modified
+	// and written back as a whole.
+	aliasMu sync.Mutex
 }
 
-// NewSettingsService creates a new instance of SettingsService.
-func NewSettingsService(db *sql.DB, llmProvider llm.LLMProvider) *SettingsService {
-	return &SettingsService{db: db, llm: llmProvider}
+// NewSettingsService creates a new instance of SettingsService. The preloader
+// loads the main model if the settings ask for it; nil disables preloading.
+func NewSettingsService(db *sql.DB, llmProvider llm.LLMProvider, preloader *ModelPreloader) *SettingsService {
+	return &SettingsService{db: db, llm: llmProvider, preloader: preloader}
 }
 
 // InitAndGet performs a "smart initialization" on the first application run.
@@ -109,27 +212,134 @@ func (s *SettingsService) Get(ctx context.Context) (*Settings, error) {
 	return settings, nil
 }
 
-// Save validates the provided settings against available Ollama models and persists them.
+// Refresh runs the model discovery of Get on demand, e.g. right after the first
+// model was pulled, and returns the settings. An empty main model is set to
+// the most recently modified model and an empty support model to the main
+// model. Unlike Get, it fails if the models cannot be listed or the discovered
+// models cannot be saved.
+func (s *SettingsService) Refresh(ctx context.Context) (*Settings, error) {
+	settings, err := s.getFromDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve settings from DB: %w", err)
+	}
+
+	needsUpdate := false
+	if settings.MainModel == "" {
+		discoveredModel, err := s.latestModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if discoveredModel != "" {
+			slog.Info("Discovered and set main model", "model", discoveredModel)
+			settings.MainModel = discoveredModel
+			needsUpdate = true
+		}
+	}
+	if settings.SupportModel == "" && settings.MainModel != "" {
+		settings.SupportModel = settings.MainModel
+		needsUpdate = true
+	}
+
+	if needsUpdate {
+		if err := s.saveToDB(ctx, settings); err != nil {
+			return nil, fmt.Errorf("could not save discovered models: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// Save validates the provided settings against the models of their providers and persists them.
+// A model may also be given as a model alias that resolves to an available model.
 func (s *SettingsService) Save(ctx context.Context, settings *Settings) error {
-	availableModels, err := s.llm.ListModels(ctx)
+	if err := validateTitlePromptTemplate(settings.TitlePromptTemplate); err != nil {
+		return err
+	}
+
+	modelNames, err := s.modelNames(ctx)
 	if err != nil {
-		return fmt.Errorf("could not list models from Ollama for validation: %w", err)
+		return err
 	}
 
-	modelNames := make([]string, len(availableModels.Models))
-	for i, m := range availableModels.Models {
-		modelNames[i] = m.Name
+	// Aliases are only loaded if a name is not a local model, as a tag takes
+	// precedence over an alias of the same name.
+	var aliases map[string]string
+	available := func(name string) (bool, error) {
+		if slices.Contains(modelNames, name) {
+			return true, nil
+		}
+		if aliases == nil {
+			if aliases, err = s.getAliases(ctx); err != nil {
+				return false, fmt.Errorf("could not load model aliases: %w", err)
+			}
+		}
+		return slices.Contains(modelNames, resolveAlias(aliases, name)), nil
 	}
 
 	// Ensure the selected models actually exist locally.
-	if settings.MainModel != "" && !slices.Contains(modelNames, settings.MainModel) {
-		return fmt.Errorf("%w: main model '%s' is not available in Ollama", app_errors.ErrValidation, settings.MainModel)
+	if settings.MainModel != "" {
+		if ok, err := available(settings.MainModel); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("%w: main model '%s' is not available", app_errors.ErrValidation, settings.MainModel)
+		}
+	}
+	if settings.SupportModel != "" {
+		if ok, err := available(settings.SupportModel); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("%w: support model '%s' is not available", app_errors.ErrValidation, settings.SupportModel)
+		}
+	}
+
+	if s.preloader == nil || !settings.PreloadMainModel {
+		return s.saveToDB(ctx, settings)
+	}
+
+	// The previous settings tell whether the main model changed. They only
+	// decide about preloading, so a failure to read them is ignored.
+	previous, _ := s.getFromDB(ctx)
+	if err := s.saveToDB(ctx, settings); err != nil {
+		return err
+	}
+	// The main model is loaded again only if it changed or preloading was just enabled.
+	if previous == nil || previous.MainModel != settings.MainModel || !previous.PreloadMainModel {
+		mainModel := settings.MainModel
+		if !slices.Contains(modelNames, mainModel) {
+			mainModel = resolveAlias(aliases, mainModel)
+		}
+		s.preload(mainModel, settings.KeepAlive)
 	}
-	if settings.SupportModel != "" && !slices.Contains(modelNames, settings.SupportModel) {
-		return fmt.Errorf("%w: support model '%s' is not available in Ollama", app_errors.ErrValidation, settings.SupportModel)
+	return nil
+}
+
+// PreloadMainModel starts loading the main model in the background if the
+// settings enable preloading. A failure is only logged, and its state is shown
+// with the running models.
+func (s *SettingsService) PreloadMainModel(settings *Settings) {
+	if settings.PreloadMainModel {
+		s.preload(settings.ResolveModel(settings.MainModel), settings.KeepAlive)
+	}
+}
+
+func (s *SettingsService) preload(modelName, keepAlive string) {
+	if s.preloader == nil || modelName == "" {
+		return
 	}
+	slog.Info("Preloading main model", "model", modelName)
+	s.preloader.Preload(modelName, keepAlive)
+}
 
-	return s.saveToDB(ctx, settings)
+// modelNames returns the names of the local models.
+func (s *SettingsService) modelNames(ctx context.Context) ([]string, error) {
+	availableModels, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list models from Ollama for validation: %w", err)
+	}
+	modelNames := make([]string, len(availableModels.Models))
+	for i, m := range availableModels.Models {
+		modelNames[i] = m.Name
+	}
+	return modelNames, nil
 }
 
 // getFromDB is a private helper for retrieving settings from the key-value table.
@@ -163,6 +373,18 @@ func (s *SettingsService) getFromDB(ctx context.Context) (*Settings, error) {
 		SystemPrompt: settingsMap["system_prompt"],
 		MainModel:    settingsMap["main_model"],
 		SupportModel: settingsMap["support_model"],
+		// A missing key (settings saved by an older version) means false.
+		ShowReasoning: settingsMap["show_reasoning"] == "true",
+		// Missing or malformed retention values disable the policy.
+		RetentionDays:     atoiOrZero(settingsMap["retention_days"]),
+		RetentionMaxChats: atoiOrZero(settingsMap["retention_max_chats"]),
+		KeepAlive:         settingsMap["keep_alive"],
+		// Templating is opt-in, so a missing key means false.
+		EnablePromptTemplates: settingsMap["enable_prompt_templates"] == "true",
+		DefaultOptions:        parseDefaultOptions(settingsMap["default_options"]),
+		PreloadMainModel:      settingsMap["preload_main_model"] == "true",
+		TitlePromptTemplate:   settingsMap["title_prompt_template"],
+		ModelAliases:          parseAliases(settingsMap[modelAliasesKey]),
 	}, nil
 }
 
@@ -178,10 +400,27 @@ func (s *SettingsService) saveToDB(ctx context.Context, settings *Settings) erro
 		}
 	}()
 
+	var defaultOptions string
+	if settings.DefaultOptions != nil {
+		encoded, err := json.Marshal(settings.DefaultOptions)
+		if err != nil {
+			return fmt.Errorf("could not encode default options: %w", err)
+		}
+		defaultOptions = string(encoded)
+	}
+
 	settingsMap := map[string]string{
-		"system_prompt": settings.SystemPrompt,
-		"main_model":    settings.MainModel,
-		"support_model": settings.SupportModel,
+		"system_prompt":           settings.SystemPrompt,
+		"main_model":              settings.MainModel,
+		"support_model":           settings.SupportModel,
+		"show_reasoning":          strconv.FormatBool(settings.ShowReasoning),
+		"retention_days":          strconv.Itoa(settings.RetentionDays),
+		"retention_max_chats":     strconv.Itoa(settings.RetentionMaxChats),
+		"keep_alive":              settings.KeepAlive,
+		"enable_prompt_templates": strconv.FormatBool(settings.EnablePromptTemplates),
+		"default_options":         defaultOptions,
+		"preload_main_model":      strconv.FormatBool(settings.PreloadMainModel),
+		"title_prompt_template":   settings.TitlePromptTemplate,
 	}
 
 	// ADD THIS BLOCK TO MAKE THE ORDER DETERMINISTIC
@@ -211,17 +450,49 @@ func (s *SettingsService) saveToDB(ctx context.Context, settings *Settings) erro
 	return tx.Commit()
 }
 
+// parseDefaultOptions decodes the stored default options; empty or malformed
+// data means there are none.
+func parseDefaultOptions(value string) *llm.RequestOptions {
+	if value == "" {
+		return nil
+	}
+	var opts llm.RequestOptions
+	if err := json.Unmarshal([]byte(value), &opts); err != nil {
+		slog.Error("Ignoring malformed default options", "error", err)
+		return nil
+	}
+	return &opts
+}
+
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 // findLatestModel discovers available Ollama models and returns the name of the
-// most recently modified one.
+// most recently modified one. A failure is logged and yields no model.
 func (s *SettingsService) findLatestModel(ctx context.Context) string {
-	models, err := s.llm.ListModels(ctx)
+	name, err := s.latestModel(ctx)
 	if err != nil {
 		slog.Warn("Could not get model list from Ollama during discovery.", "error", err)
 		return ""
 	}
+	return name
+}
+
+// latestModel returns the name of the most recently modified model, or an
+// empty name if there is none.
+func (s *SettingsService) latestModel(ctx context.Context) (string, error) {
+	models, err := s.llm.ListModels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not list models for discovery: %w", err)
+	}
 
 	if models == nil || len(models.Models) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// Sort models by modification date, descending, to find the newest one.
@@ -231,5 +502,5 @@ func (s *SettingsService) findLatestModel(ctx context.Context) string {
 		t2, _ := time.Parse(time.RFC3339, models.Models[j].ModifiedAt)
 		return t1.After(t2)
 	})
-	return models.Models[0].Name
+	return models.Models[0].Name, nil
 }
@@ -4,14 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"flow-ai/backend/internal/database"
+	app_errors "flow-ai/backend/internal/errors"
 	"flow-ai/backend/internal/llm"
 	"flow-ai/backend/internal/llm/mocks"
 	"flow-ai/backend/internal/service"
@@ -31,7 +35,7 @@ func setupSettingsService(t *testing.T) (*service.SettingsService, *sql.DB, sqlm
 	require.NoError(t, err)
 
 	mockLLM := mocks.NewMockLLMProvider(t)
-	settingsService := service.NewSettingsService(db, mockLLM)
+	settingsService := service.NewSettingsService(db, mockLLM, nil)
 
 	return settingsService, db, mockDB, mockLLM
 }
@@ -49,7 +53,8 @@ func TestSettingsService_Get(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"key", "value"}).
 			AddRow("system_prompt", "test prompt").
 			AddRow("main_model", "test-model").
-			AddRow("support_model", "support-model")
+			AddRow("support_model", "support-model").
+			AddRow("default_options", `{"temperature": 0.7}`)
 
 		// We expect a specific SQL query to be executed.
 		mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
@@ -62,6 +67,8 @@ func TestSettingsService_Get(t *testing.T) {
 		assert.Equal(t, "test prompt", settings.SystemPrompt)
 		assert.Equal(t, "test-model", settings.MainModel)
 		assert.Equal(t, "support-model", settings.SupportModel)
+		require.NotNil(t, settings.DefaultOptions)
+		assert.Equal(t, float32(0.7), *settings.DefaultOptions.Temperature)
 
 		// `ExpectationsWereMet` verifies that all expected SQL queries were executed.
 		assert.NoError(t, mockDB.ExpectationsWereMet())
@@ -90,9 +97,17 @@ func TestSettingsService_Get(t *testing.T) {
 		// Note the deterministic order of inserts due to our code change.
 		mockDB.ExpectBegin()
 		prep := mockDB.ExpectPrepare("INSERT INTO settings")
+		prep.ExpectExec().WithArgs("default_options", "").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("enable_prompt_templates", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("keep_alive", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("main_model", "discovered-model").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("preload_main_model", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_days", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_max_chats", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("show_reasoning", "false").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("support_model", "discovered-model").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("system_prompt", "test prompt").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("title_prompt_template", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		mockDB.ExpectCommit()
 
 		// ACT
@@ -124,6 +139,56 @@ func TestSettingsService_Get(t *testing.T) {
 	})
 }
 
+// TestSettingsService_Refresh verifies that the discovery fills in empty models
+// and persists them, and that a failure to list the models is returned.
+func TestSettingsService_Refresh(t *testing.T) {
+	ctx := context.Background()
+	// WHY: A real database shows that the discovered models are persisted, not
+	// just returned.
+	setup := func(t *testing.T) (*service.SettingsService, *mocks.MockLLMProvider) {
+		db, err := database.InitDB(filepath.Join(t.TempDir(), "test.db"), database.Config{})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mockLLM := mocks.NewMockLLMProvider(t)
+		settingsService := service.NewSettingsService(db, mockLLM, nil)
+		// The first run finds no models, as before the first pull.
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{}, nil).Once()
+		_, err = settingsService.InitAndGet(ctx, "prompt")
+		require.NoError(t, err)
+		return settingsService, mockLLM
+	}
+
+	t.Run("Success - Persists the most recent model", func(t *testing.T) {
+		// ARRANGE
+		settingsService, mockLLM := setup(t)
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{
+			{Name: "old", ModifiedAt: "2025-01-01T00:00:00Z"},
+			{Name: "new", ModifiedAt: "2025-06-01T00:00:00Z"},
+		}}, nil).Once()
+
+		// ACT
+		settings, err := settingsService.Refresh(ctx)
+
+		// ASSERT: Get finds the models set without discovering again.
+		require.NoError(t, err)
+		assert.Equal(t, "new", settings.MainModel)
+		assert.Equal(t, "new", settings.SupportModel)
+		stored, err := settingsService.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "new", stored.MainModel)
+		assert.Equal(t, "new", stored.SupportModel)
+	})
+
+	t.Run("Failure - The models cannot be listed", func(t *testing.T) {
+		settingsService, mockLLM := setup(t)
+		mockLLM.On("ListModels", ctx).Return(nil, errors.New("connection refused")).Once()
+
+		_, err := settingsService.Refresh(ctx)
+
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
+
 // TestSettingsService_InitAndGet tests the first-run initialization logic.
 func TestSettingsService_InitAndGet(t *testing.T) {
 	ctx := context.Background()
@@ -157,9 +222,17 @@ func TestSettingsService_InitAndGet(t *testing.T) {
 		// 3. Expect the service to save the newly created default settings.
 		mockDB.ExpectBegin()
 		prep := mockDB.ExpectPrepare("INSERT INTO settings")
+		prep.ExpectExec().WithArgs("default_options", "").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("enable_prompt_templates", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("keep_alive", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("main_model", "discovered-model").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("preload_main_model", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_days", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_max_chats", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("show_reasoning", "false").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("support_model", "discovered-model").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("system_prompt", "default prompt").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("title_prompt_template", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		mockDB.ExpectCommit()
 
 		settings, err := settingsService.InitAndGet(ctx, "default prompt")
@@ -180,9 +253,17 @@ func TestSettingsService_InitAndGet(t *testing.T) {
 
 		mockDB.ExpectBegin()
 		prep := mockDB.ExpectPrepare("INSERT INTO settings")
+		prep.ExpectExec().WithArgs("default_options", "").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("enable_prompt_templates", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("keep_alive", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("main_model", "").WillReturnResult(sqlmock.NewResult(1, 1)) // Expect empty strings
+		prep.ExpectExec().WithArgs("preload_main_model", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_days", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_max_chats", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("show_reasoning", "false").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("support_model", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("system_prompt", "default").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("title_prompt_template", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		mockDB.ExpectCommit()
 
 		settings, err := settingsService.InitAndGet(ctx, "default")
@@ -217,9 +298,17 @@ func TestSettingsService_Save(t *testing.T) {
 		// `regexp.QuoteMeta` is used because the query string contains special characters like `(?)`
 		// that would otherwise be interpreted as a regex. This ensures we match the exact SQL string.
 		prep := mockDB.ExpectPrepare(regexp.QuoteMeta("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value"))
+		prep.ExpectExec().WithArgs("default_options", "").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("enable_prompt_templates", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("keep_alive", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("main_model", "model1").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("preload_main_model", "false").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_days", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("retention_max_chats", "0").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("show_reasoning", "false").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("support_model", "model2").WillReturnResult(sqlmock.NewResult(1, 1))
 		prep.ExpectExec().WithArgs("system_prompt", "new prompt").WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs("title_prompt_template", "").WillReturnResult(sqlmock.NewResult(1, 1))
 		mockDB.ExpectCommit()
 
 		err := settingsService.Save(ctx, settingsToSave)
@@ -228,6 +317,20 @@ func TestSettingsService_Save(t *testing.T) {
 		mockLLM.AssertExpectations(t)
 	})
 
+	t.Run("Failure - Invalid title prompt template", func(t *testing.T) {
+		// GOAL: A template that does not parse, or uses a field other than .User
+		// and .Assistant, is rejected before anything is looked up or written.
+		for _, template := range []string{"Title for {{.User", "Title for {{.Message}}"} {
+			settingsService, db, mockDB, _ := setupSettingsService(t)
+
+			err := settingsService.Save(ctx, &service.Settings{MainModel: "model1", TitlePromptTemplate: template})
+
+			assert.ErrorIs(t, err, app_errors.ErrValidation, template)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+			_ = db.Close()
+		}
+	})
+
 	t.Run("Failure - Main model not available", func(t *testing.T) {
 		// GOAL: Verify that the service rejects settings if the specified model does not exist.
 		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
@@ -237,15 +340,83 @@ func TestSettingsService_Save(t *testing.T) {
 			Models: []llm.Model{{Name: "another-model"}}, // "model1" is missing
 		}, nil).Once()
 
+		// "model1" could still be an alias, so the aliases are looked up.
+		mockDB.ExpectQuery(regexp.QuoteMeta("SELECT value FROM settings WHERE key = ?")).
+			WithArgs("model_aliases").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
 		err := settingsService.Save(ctx, settingsToSave)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "main model 'model1' is not available")
 
-		// Crucially, no DB calls should be made if validation fails.
+		// Crucially, nothing should be written if validation fails.
 		assert.NoError(t, mockDB.ExpectationsWereMet())
 		mockLLM.AssertExpectations(t)
 	})
 
+	t.Run("Success - Default options are stored as JSON", func(t *testing.T) {
+		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "model1"}}}, nil).Once()
+		mockDB.ExpectBegin()
+		prep := mockDB.ExpectPrepare("INSERT INTO settings")
+		prep.ExpectExec().WithArgs("default_options", `{"temperature":0.7,"num_ctx":8192}`).WillReturnResult(sqlmock.NewResult(1, 1))
+		for range 10 {
+			prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mockDB.ExpectCommit()
+
+		temperature := float32(0.7)
+		numCtx := 8192
+		err := settingsService.Save(ctx, &service.Settings{
+			MainModel:      "model1",
+			DefaultOptions: &llm.RequestOptions{Temperature: &temperature, NumCtx: &numCtx},
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Success - Models given as aliases", func(t *testing.T) {
+		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{
+			Models: []llm.Model{{Name: "qwen3:14b"}},
+		}, nil).Once()
+		mockDB.ExpectQuery(regexp.QuoteMeta("SELECT value FROM settings WHERE key = ?")).
+			WithArgs("model_aliases").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(`{"smart": "qwen3:14b", "fast": "smart"}`))
+		mockDB.ExpectBegin()
+		prep := mockDB.ExpectPrepare(regexp.QuoteMeta("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value"))
+		for range 11 {
+			prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mockDB.ExpectCommit()
+
+		// WHY: The aliases are loaded once, even though both models need them.
+		err := settingsService.Save(ctx, &service.Settings{MainModel: "smart", SupportModel: "fast"})
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Failure - Alias points at a missing model", func(t *testing.T) {
+		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{
+			Models: []llm.Model{{Name: "qwen3:14b"}},
+		}, nil).Once()
+		mockDB.ExpectQuery(regexp.QuoteMeta("SELECT value FROM settings WHERE key = ?")).
+			WithArgs("model_aliases").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(`{"smart": "deleted:70b"}`))
+
+		err := settingsService.Save(ctx, &service.Settings{MainModel: "smart"})
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
 	t.Run("Failure - LLM provider returns error", func(t *testing.T) {
 		// GOAL: Verify that errors from the LLM provider are handled gracefully.
 		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
@@ -260,3 +431,199 @@ func TestSettingsService_Save(t *testing.T) {
 		mockLLM.AssertExpectations(t)
 	})
 }
+
+// TestSettingsService_ModelAliases tests the management of model aliases, which
+// are stored as a JSON object under a single settings key.
+// TestSettingsService_PreloadMainModel tests that the main model is preloaded when
+// preloading is enabled and the main model changes, and only then.
+func TestSettingsService_PreloadMainModel(t *testing.T) {
+	ctx := context.Background()
+	upsert := regexp.QuoteMeta("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value")
+	setup := func(t *testing.T, previous map[string]string) (*service.SettingsService, *mocks.MockLLMProvider, sqlmock.Sqlmock) {
+		db, mockDB, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mockLLM := mocks.NewMockLLMProvider(t)
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "old"}, {Name: "new"}}}, nil).Once()
+
+		rows := sqlmock.NewRows([]string{"key", "value"})
+		for key, value := range previous {
+			rows.AddRow(key, value)
+		}
+		mockDB.ExpectQuery("SELECT key, value FROM settings").WillReturnRows(rows)
+		mockDB.ExpectBegin()
+		prep := mockDB.ExpectPrepare(upsert)
+		for range 11 {
+			prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mockDB.ExpectCommit()
+		return service.NewSettingsService(db, mockLLM, service.NewModelPreloader(mockLLM)), mockLLM, mockDB
+	}
+
+	t.Run("A changed main model is preloaded", func(t *testing.T) {
+		// ARRANGE
+		settingsService, mockLLM, mockDB := setup(t, map[string]string{"main_model": "old", "preload_main_model": "true"})
+		loaded := make(chan struct{})
+		keepAlive := llm.KeepAlive("-1")
+		mockLLM.On("Generate", mock.Anything, &llm.GenerateRequest{Model: "new", KeepAlive: &keepAlive}).
+			Run(func(mock.Arguments) { close(loaded) }).
+			Return(&llm.GenerateResponse{}, nil).Once()
+
+		// ACT
+		err := settingsService.Save(ctx, &service.Settings{MainModel: "new", KeepAlive: "-1", PreloadMainModel: true})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+		select {
+		case <-loaded:
+		case <-time.After(time.Second):
+			t.Fatal("the main model was not preloaded")
+		}
+	})
+
+	t.Run("Enabling preloading preloads the main model", func(t *testing.T) {
+		settingsService, mockLLM, mockDB := setup(t, map[string]string{"main_model": "old", "preload_main_model": "false"})
+		loaded := make(chan struct{})
+		mockLLM.On("Generate", mock.Anything, &llm.GenerateRequest{Model: "old"}).
+			Run(func(mock.Arguments) { close(loaded) }).
+			Return(&llm.GenerateResponse{}, nil).Once()
+
+		err := settingsService.Save(ctx, &service.Settings{MainModel: "old", PreloadMainModel: true})
+
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+		select {
+		case <-loaded:
+		case <-time.After(time.Second):
+			t.Fatal("the main model was not preloaded")
+		}
+	})
+
+	t.Run("An unchanged main model is not loaded again", func(t *testing.T) {
+		settingsService, _, mockDB := setup(t, map[string]string{"main_model": "old", "preload_main_model": "true"})
+
+		// WHY: The mock fails the test on any call to Generate.
+		err := settingsService.Save(ctx, &service.Settings{MainModel: "old", SystemPrompt: "changed", PreloadMainModel: true})
+
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+}
+
+func TestSettingsService_ModelAliases(t *testing.T) {
+	ctx := context.Background()
+	selectAliases := regexp.QuoteMeta("SELECT value FROM settings WHERE key = ?")
+	upsert := regexp.QuoteMeta("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value")
+	aliasRows := func(value string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"value"}).AddRow(value)
+	}
+
+	t.Run("List - Sorted by name", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "qwen3:14b", "fast": "gemma3:4b"}`))
+
+		aliases, err := settingsService.ListModelAliases(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []service.ModelAlias{{Name: "fast", Model: "gemma3:4b"}, {Name: "smart", Model: "qwen3:14b"}}, aliases)
+	})
+
+	t.Run("Set - Chained alias", func(t *testing.T) {
+		// ARRANGE
+		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "qwen3:14b"}`))
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "qwen3:14b"}}}, nil).Once()
+		mockDB.ExpectExec(upsert).WithArgs("model_aliases", `{"best":"smart","smart":"qwen3:14b"}`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// ACT
+		err := settingsService.SetModelAlias(ctx, &service.ModelAlias{Name: "best", Model: "smart"})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Set - Loop is rejected", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "fast"}`))
+
+		err := settingsService.SetModelAlias(ctx, &service.ModelAlias{Name: "fast", Model: "smart"})
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		assert.Contains(t, err.Error(), "loop")
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Set - Missing model is rejected", func(t *testing.T) {
+		settingsService, db, mockDB, mockLLM := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+		mockLLM.On("ListModels", ctx).Return(&llm.ListModelsResponse{Models: []llm.Model{{Name: "gemma3:4b"}}}, nil).Once()
+
+		err := settingsService.SetModelAlias(ctx, &service.ModelAlias{Name: "smart", Model: "qwen3:14b"})
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Set - Name that looks like a tag is rejected", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+
+		err := settingsService.SetModelAlias(ctx, &service.ModelAlias{Name: "qwen3:latest", Model: "qwen3:14b"})
+
+		assert.ErrorIs(t, err, app_errors.ErrValidation)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Delete - Success", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "qwen3:14b", "fast": "gemma3:4b"}`))
+		mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "smart"))
+		mockDB.ExpectExec(upsert).WithArgs("model_aliases", `{"smart":"qwen3:14b"}`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := settingsService.DeleteModelAlias(ctx, "fast")
+
+		require.NoError(t, err)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Delete - Alias used by the settings", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "qwen3:14b"}`))
+		mockDB.ExpectQuery("SELECT key, value FROM settings").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("main_model", "smart"))
+
+		err := settingsService.DeleteModelAlias(ctx, "smart")
+
+		assert.ErrorIs(t, err, app_errors.ErrConflict)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("Delete - Alias used by another alias", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(aliasRows(`{"smart": "qwen3:14b", "best": "smart"}`))
+
+		err := settingsService.DeleteModelAlias(ctx, "smart")
+
+		assert.ErrorIs(t, err, app_errors.ErrConflict)
+	})
+
+	t.Run("Delete - Not found", func(t *testing.T) {
+		settingsService, db, mockDB, _ := setupSettingsService(t)
+		defer func() { _ = db.Close() }()
+		mockDB.ExpectQuery(selectAliases).WithArgs("model_aliases").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		err := settingsService.DeleteModelAlias(ctx, "smart")
+
+		assert.ErrorIs(t, err, app_errors.ErrNotFound)
+	})
+}
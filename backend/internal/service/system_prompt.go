@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// systemPromptData is the data available to a system prompt when the
+// `enable_prompt_templates` setting is on, e.g. "Today is {{.Date}}." It only
+// holds values that are safe to show to the model.
+type systemPromptData struct {
+	// Date is the current date, e.g. "2025-09-08".
+	Date string
+	// Time is the current time of day, e.g. "14:05".
+	Time string
+	// Weekday is the current day of the week, e.g. "Monday".
+	Weekday string
+	// DateTime is the current time in RFC 3339 format.
+	DateTime string
+	// UserID is the owner of the chat.
+	UserID string
+	// Model is the model that answers the message.
+	Model string
+}
+
+func newSystemPromptData(now time.Time, userID, modelName string) systemPromptData {
+	return systemPromptData{
+		Date:     now.Format(time.DateOnly),
+		Time:     now.Format("15:04"),
+		Weekday:  now.Weekday().String(),
+		DateTime: now.Format(time.RFC3339),
+		UserID:   userID,
+		Model:    modelName,
+	}
+}
+
+// renderSystemPrompt executes the system prompt as a text/template. A reference
+// to an unknown variable fails with `ErrValidation` instead of leaving a blank.
+func renderSystemPrompt(prompt string, data systemPromptData) (string, error) {
+	// Prompts without actions are the common case and need no parsing.
+	if !strings.Contains(prompt, "{{") {
+		return prompt, nil
+	}
+	tmpl, err := template.New("system_prompt").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid system prompt template: %s", app_errors.ErrValidation, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: could not render system prompt: %s", app_errors.ErrValidation, err)
+	}
+	return out.String(), nil
+}
+
+// renderSystemPromptFor renders the system prompt if prompt templates are enabled
+// in the settings and returns it unchanged otherwise.
+func (s *ChatService) renderSystemPromptFor(prompt string, settings *Settings, userID, modelName string) (string, error) {
+	if !settings.EnablePromptTemplates {
+		return prompt, nil
+	}
+	if userID == "" {
+		userID = s.cfg.DefaultUserID
+	}
+	return renderSystemPrompt(prompt, newSystemPromptData(time.Now(), userID, modelName))
+}
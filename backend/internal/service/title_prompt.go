@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	app_errors "flow-ai/backend/internal/errors"
+)
+
+// defaultTitlePromptTemplate asks for the title as JSON, which is the most
+// reliable way to get a title without any chatter around it.
+const defaultTitlePromptTemplate = `Analyze the following conversation and generate a short, concise title (5 words max).
+Respond with ONLY a JSON object in the format {"title": "your generated title"}. Do not add any other text or explanations.
+
+CONVERSATION:
+User: {{.User}}
+Assistant: {{.Assistant}}`
+
+// simpleTitlePromptTemplate is used to retry a failed title generation. It asks
+// for the bare title of the user message alone, which a small model answers
+// more reliably than the JSON of the default prompt.
+const simpleTitlePromptTemplate = `Write a title of at most five words for the following message. Answer with the title only.
+
+{{.User}}`
+
+// titlePromptData is the data available to the `title_prompt_template` setting.
+type titlePromptData struct {
+	// User is the first user message, truncated.
+	User string
+	// Assistant is the answer to it, truncated.
+	Assistant string
+}
+
+// renderTitlePrompt executes a title prompt template; empty uses the default.
+func renderTitlePrompt(promptTemplate string, data titlePromptData) (string, error) {
+	if promptTemplate == "" {
+		promptTemplate = defaultTitlePromptTemplate
+	}
+	tmpl, err := template.New("title_prompt").Parse(promptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid title prompt template: %s", app_errors.ErrValidation, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: could not render title prompt: %s", app_errors.ErrValidation, err)
+	}
+	return out.String(), nil
+}
+
+// validateTitlePromptTemplate renders a template with sample data, which also
+// catches references to fields other than .User and .Assistant.
+func validateTitlePromptTemplate(promptTemplate string) error {
+	_, err := renderTitlePrompt(promptTemplate, titlePromptData{User: "Hello", Assistant: "Hi!"})
+	return err
+}
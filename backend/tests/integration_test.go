@@ -19,20 +19,25 @@ import (
 
 	"flow-ai/backend/internal/api"
 	"flow-ai/backend/internal/config"
-	"flow-ai/backend/internal/database"
 	"flow-ai/backend/internal/llm"
 	"flow-ai/backend/internal/repository"
 	"flow-ai/backend/internal/service"
 )
 
 const (
-	baseAPIURL        = "http://localhost:8000/api/v1"
 	ollamaInternalURL = "http://ollama:11434"
 	testModel         = "gemma3:270m-it-qat"
 	testDBPath        = "/tmp/flow-ai-test.db"
 )
 
-var testServer *http.Server
+var (
+	testServer *http.Server
+	// baseAPIURL is derived from the configured server port in setupTestServer.
+	baseAPIURL string
+	// useFakeProvider is set when the tests run against the fake provider
+	// instead of Ollama.
+	useFakeProvider bool
+)
 
 // TestMain sets up the entire test environment, including an in-process HTTP server.
 func TestMain(m *testing.M) {
@@ -93,25 +98,65 @@ func setupTestServer() error {
 	}
 	// --- End of Correction ---
 
-	db, err := database.InitDB(cfg.DatabasePath)
+	addr, err := cfg.ListenAddr()
 	if err != nil {
-		return fmt.Errorf("failed to init test DB: %w", err)
+		return fmt.Errorf("invalid test server address: %w", err)
 	}
+	baseAPIURL = fmt.Sprintf("http://localhost:%d/api/v1", cfg.AppPort)
 
-	repo := repository.NewSQLiteRepository(db)
-	// Use the URL from our test config
-	ollamaProvider := llm.NewOllamaProvider(cfg.OllamaURL)
-	settingsService := service.NewSettingsService(db, ollamaProvider)
+	// STORAGE_BACKEND selects the backend the tests run against, as in the app.
+	storage, err := repository.OpenStorage(repository.StorageConfig{
+		Backend:      cfg.StorageBackend,
+		DatabasePath: cfg.DatabasePath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open test storage: %w", err)
+	}
+	db, repo := storage.DB, storage.Repository
+	// With LLM_PROVIDER=fake the tests run without Ollama, e.g. in CI; the
+	// model is "pulled" instantly and answers with lorem ipsum.
+	var llmProvider llm.LLMProvider
+	if cfg.LLMProvider == llm.ProviderFake {
+		useFakeProvider = true
+		llmProvider = llm.NewFakeProvider(llm.FakeConfig{})
+	} else {
+		// Use the URL from our test config
+		llmProvider = llm.NewOllamaProvider(cfg.OllamaURL, llm.OllamaConfig{
+			LogPayloads:        cfg.LogLLMPayloads,
+			PayloadLogMaxChars: cfg.LLMPayloadLogMaxChars,
+			Headers:            cfg.OllamaHeaders,
+		})
+	}
+	settingsService := service.NewSettingsService(db, llmProvider, nil)
 	// Use the prompt from our test config
 	_, _ = settingsService.InitAndGet(context.Background(), cfg.InitialSystemPrompt)
-	chatService := service.NewChatService(repo, ollamaProvider, settingsService)
-	modelService := service.NewModelService(ollamaProvider)
-	chatHandler := api.NewChatHandler(chatService, settingsService)
+	documentService := service.NewDocumentService(repo, llmProvider, service.DocumentServiceConfig{
+		EmbeddingModel: cfg.EmbeddingModel,
+		ChunkSize:      cfg.RAGChunkSize,
+		ChunkOverlap:   cfg.RAGChunkOverlap,
+		TopK:           cfg.RAGTopK,
+	})
+	chatService := service.NewChatService(repo, llmProvider, settingsService, documentService, service.ChatServiceConfig{
+		DefaultUserID:      cfg.DefaultUserID,
+		TitlePreviewLength: cfg.TitlePreviewLength,
+		TitleMaxLength:     cfg.TitleMaxLength,
+		MaxImageBytes:      cfg.MaxImageBytes,
+		IdempotencyTTL:     cfg.IdempotencyTTL,
+	})
+	modelService := service.NewModelService(repo, llmProvider, llm.NewOllamaRegistry("https://ollama.com", llm.RegistryConfig{}), service.ModelServiceConfig{})
+	chatHandler := api.NewChatHandler(chatService, settingsService, api.ChatHandlerConfig{
+		HeartbeatInterval: cfg.SSEHeartbeatInterval,
+	})
 	modelHandler := api.NewModelHandler(modelService)
-	router := api.NewRouter(chatHandler, modelHandler)
+	documentHandler := api.NewDocumentHandler(documentService)
+	promptHandler := api.NewPromptHandler(service.NewPromptService(repo))
+	// The retention janitor is not started, so tests don't lose chats to it.
+	adminHandler := api.NewAdminHandler(service.NewRetentionService(repo, settingsService, service.RetentionServiceConfig{}), service.NewMaintenanceService(repo), service.NewBackupService(db, service.BackupServiceConfig{}), api.AdminHandlerConfig{})
+	systemHandler := api.NewSystemHandler(service.NewHealthService(db, llmProvider))
+	router := api.NewRouter(chatHandler, modelHandler, documentHandler, promptHandler, adminHandler, systemHandler, nil, 0, cfg.EnableCompression, "")
 
 	testServer = &http.Server{
-		Addr:    ":8000",
+		Addr:    addr,
 		Handler: router,
 	}
 	return nil
@@ -419,7 +464,9 @@ func waitForServices() error {
 
 	services := map[string]func(*http.Client) bool{
 		"Backend": backendCheck,
-		"Ollama":  ollamaCheck,
+	}
+	if !useFakeProvider {
+		services["Ollama"] = ollamaCheck
 	}
 
 	client := &http.Client{Timeout: 2 * time.Second}